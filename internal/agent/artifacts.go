@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// ErrInvalidArtifactName is returned for artifact names that would leave the
+// task's artifact directory.
+var ErrInvalidArtifactName = errors.New("invalid artifact name")
+
+// artifactsRoot is the directory holding the artifact directories of a
+// project's tasks. Agents are given access to it with --add-dir, which keeps
+// the CLI arguments the same for every task a pod runs.
+func (r *Runtime) artifactsRoot(project string) string {
+	return filepath.Join(r.cfg.Load().Store.DataDir, "artifacts", project)
+}
+
+// ArtifactDir returns the directory the agent running a task saves the
+// task's artifacts to.
+func (r *Runtime) ArtifactDir(project, taskName string) string {
+	return filepath.Join(r.artifactsRoot(project), taskName)
+}
+
+// artifactsPrompt is appended to a task's prompt to tell the agent where its
+// artifacts go.
+func artifactsPrompt(dir string) string {
+	return fmt.Sprintf("\n\nSave any files you produce for the user (reports, patches, generated code) in %s; "+
+		"they are kept as the task's artifacts.", dir)
+}
+
+// ListArtifacts returns the files in a task's artifact directory, sorted by
+// name. A task that saved none has no artifacts.
+func (r *Runtime) ListArtifacts(project, taskName string) ([]v1alpha1.Artifact, error) {
+	dir := r.ArtifactDir(project, taskName)
+	artifacts := []v1alpha1.Artifact{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, v1alpha1.Artifact{
+			Name:    filepath.ToSlash(name),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing artifacts: %w", err)
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Name < artifacts[j].Name })
+	return artifacts, nil
+}
+
+// OpenArtifact opens one of a task's artifacts by the name ListArtifacts
+// reports. The error wraps fs.ErrNotExist if there is no such artifact.
+func (r *Runtime) OpenArtifact(project, taskName, name string) (*os.File, error) {
+	name = filepath.FromSlash(name)
+	if !filepath.IsLocal(name) {
+		return nil, ErrInvalidArtifactName
+	}
+	f, err := os.Open(filepath.Join(r.ArtifactDir(project, taskName), name))
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil, fmt.Errorf("artifact %s: %w", name, fs.ErrNotExist)
+	}
+	return f, nil
+}
+
+// RemoveArtifacts deletes a task's artifacts.
+func (r *Runtime) RemoveArtifacts(project, taskName string) error {
+	return os.RemoveAll(r.ArtifactDir(project, taskName))
+}
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klubi/orca/internal/config"
+)
+
+func TestArtifacts(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Store.DataDir = t.TempDir()
+	r := NewRuntime(nil, nil, cfg, nil)
+
+	if got, err := r.ListArtifacts("proj", "task"); err != nil || len(got) != 0 {
+		t.Fatalf("task without artifacts: got %+v, %v", got, err)
+	}
+
+	dir := r.ArtifactDir("proj", "task")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"report.md": "# Report", "sub/fix.diff": "diff"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := r.ListArtifacts("proj", "task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name != "report.md" || got[1].Name != "sub/fix.diff" || got[0].Size != 8 {
+		t.Fatalf("unexpected artifacts %+v", got)
+	}
+
+	f, err := r.OpenArtifact("proj", "task", "sub/fix.diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "diff" {
+		t.Errorf("read %q", data)
+	}
+
+	if _, err := r.OpenArtifact("proj", "task", "../task/report.md"); !errors.Is(err, ErrInvalidArtifactName) {
+		t.Errorf("escaping name: got %v", err)
+	}
+	if _, err := r.OpenArtifact("proj", "task", "sub"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("directory: got %v", err)
+	}
+	if _, err := r.OpenArtifact("proj", "task", "missing.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing: got %v", err)
+	}
+
+	if err := r.RemoveArtifacts("proj", "task"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := r.ListArtifacts("proj", "task"); len(got) != 0 {
+		t.Errorf("artifacts left after removal: %+v", got)
+	}
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// resolveEnv builds the "KEY=VALUE" environment for a pod's agent process.
+// EnvFrom sources are applied first so that explicit Env entries win on
+// conflicting names. Referenced Secrets and ConfigMaps must live in the pod's
+// project.
+func (r *Runtime) resolveEnv(pod *v1alpha1.AgentPod) ([]string, error) {
+	vars := make(map[string]string)
+	var order []string
+	set := func(name, value string) {
+		if _, ok := vars[name]; !ok {
+			order = append(order, name)
+		}
+		vars[name] = value
+	}
+
+	for _, src := range pod.Spec.EnvFrom {
+		var (
+			data map[string]string
+			err  error
+		)
+		switch {
+		case src.SecretRef != nil:
+			data, err = r.secretData(pod.Metadata.Project, src.SecretRef.Name)
+		case src.ConfigMapRef != nil:
+			data, err = r.configMapData(pod.Metadata.Project, src.ConfigMapRef.Name)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range data {
+			set(src.Prefix+k, v)
+		}
+	}
+
+	for _, ev := range pod.Spec.Env {
+		if ev.Name == "" {
+			return nil, fmt.Errorf("env var with empty name")
+		}
+		var (
+			source, name, key string
+			data              map[string]string
+			err               error
+		)
+		switch {
+		case ev.ValueFrom != nil && ev.ValueFrom.SecretKeyRef != nil:
+			source, name, key = "secret", ev.ValueFrom.SecretKeyRef.Name, ev.ValueFrom.SecretKeyRef.Key
+			data, err = r.secretData(pod.Metadata.Project, name)
+		case ev.ValueFrom != nil && ev.ValueFrom.ConfigMapKeyRef != nil:
+			source, name, key = "configmap", ev.ValueFrom.ConfigMapKeyRef.Name, ev.ValueFrom.ConfigMapKeyRef.Key
+			data, err = r.configMapData(pod.Metadata.Project, name)
+		default:
+			set(ev.Name, ev.Value)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		v, ok := data[key]
+		if !ok {
+			return nil, fmt.Errorf("env %s: key %q not found in %s %q", ev.Name, key, source, name)
+		}
+		set(ev.Name, v)
+	}
+
+	env := make([]string, 0, len(order))
+	for _, name := range order {
+		env = append(env, name+"="+vars[name])
+	}
+	return env, nil
+}
+
+// secretData loads a Secret and returns its decoded values.
+func (r *Runtime) secretData(project, name string) (map[string]string, error) {
+	var sec v1alpha1.Secret
+	key := store.ResourceKey(v1alpha1.KindSecret, project, name)
+	if err := r.store.Get(key, &sec); err != nil {
+		if err == store.ErrNotFound {
+			return nil, fmt.Errorf("secret %q not found in project %q", name, project)
+		}
+		return nil, fmt.Errorf("getting secret %q: %w", name, err)
+	}
+
+	data := make(map[string]string, len(sec.Data)+len(sec.StringData))
+	for k, v := range sec.Data {
+		raw, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("secret %q key %q: invalid base64: %w", name, k, err)
+		}
+		data[k] = string(raw)
+	}
+	for k, v := range sec.StringData {
+		data[k] = v
+	}
+	return data, nil
+}
+
+// configMapData loads a ConfigMap and returns its values.
+func (r *Runtime) configMapData(project, name string) (map[string]string, error) {
+	var cm v1alpha1.ConfigMap
+	key := store.ResourceKey(v1alpha1.KindConfigMap, project, name)
+	if err := r.store.Get(key, &cm); err != nil {
+		if err == store.ErrNotFound {
+			return nil, fmt.Errorf("configmap %q not found in project %q", name, project)
+		}
+		return nil, fmt.Errorf("getting configmap %q: %w", name, err)
+	}
+	return cm.Data, nil
+}
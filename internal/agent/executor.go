@@ -1,15 +1,18 @@
 package agent
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 )
 
 // Executor wraps the local Claude CLI and provides
@@ -18,6 +21,10 @@ import (
 type Executor struct {
 	cliBin string // path to the claude binary
 	logger *zap.Logger
+
+	mu        sync.Mutex
+	warm      map[string]*warmPool // pre-started sessions by WarmKey
+	selfCheck *SelfCheckResult     // last SelfCheck outcome, nil until run
 }
 
 // NewExecutor creates a new Executor that calls the Claude CLI.
@@ -29,6 +36,7 @@ func NewExecutor(cliBin string, logger *zap.Logger) *Executor {
 	return &Executor{
 		cliBin: cliBin,
 		logger: logger,
+		warm:   make(map[string]*warmPool),
 	}
 }
 
@@ -38,6 +46,30 @@ type ExecutionRequest struct {
 	SystemPrompt string
 	Prompt       string
 	MaxTokens    int
+	// MaxTurns limits agentic turns (0 = CLI default).
+	MaxTurns int
+	// PermissionMode is passed as --permission-mode when non-empty.
+	PermissionMode string
+	// AllowedTools restricts the tools the agent may use (empty = CLI default).
+	AllowedTools []string
+	// DisallowedTools lists tools the agent must not use.
+	DisallowedTools []string
+	// WarmKey identifies the warm session pool this request may draw from
+	// (typically the pod key). Empty disables warm sessions.
+	WarmKey string
+	// AddDirs lists directories outside the working directory the agent may
+	// access (--add-dir).
+	AddDirs []string
+	// MCPConfig is the JSON MCP server configuration passed as --mcp-config
+	// when non-empty.
+	MCPConfig string
+	// Env holds extra "KEY=VALUE" entries added to the CLI process environment.
+	Env []string
+	// ResumeSession continues the CLI session with this ID (--resume).
+	ResumeSession string
+	// OnStep, when set, is called with each step as the CLI reports it,
+	// before the call completes.
+	OnStep func(v1alpha1.TaskStep)
 }
 
 // ExecutionResult holds the response from a Claude CLI call.
@@ -46,10 +78,15 @@ type ExecutionResult struct {
 	TokensIn  int
 	TokensOut int
 	CostUSD   float64
-	Error     error
+	// SessionID identifies the CLI session, for resuming it later.
+	SessionID string
+	// Steps is the turn-by-turn trace reported by the CLI.
+	Steps []v1alpha1.TaskStep
+	Error error
 }
 
-// cliResponse maps the JSON output of `claude -p --output-format json`.
+// cliResponse maps the JSON output of `claude -p --output-format json`, which
+// is also the final "result" event of the stream-json format.
 type cliResponse struct {
 	Type       string  `json:"type"`
 	Subtype    string  `json:"subtype"`
@@ -58,44 +95,57 @@ type cliResponse struct {
 	DurationMs int     `json:"duration_ms"`
 	NumTurns   int     `json:"num_turns"`
 	TotalCost  float64 `json:"total_cost_usd"`
+	SessionID  string  `json:"session_id"`
 	Usage      struct {
 		InputTokens  int `json:"input_tokens"`
 		OutputTokens int `json:"output_tokens"`
 	} `json:"usage"`
 }
 
-// Execute sends a prompt to the Claude CLI in print mode and returns the result.
+// Execute sends a prompt to the Claude CLI in print mode and returns the
+// result together with the trace of intermediate steps.
+// The prompt is written to the process's stdin, which lets a pre-started warm
+// session (see Warm) serve the request when one matches.
 func (e *Executor) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
-	args := []string{
-		"-p", req.Prompt,
-		"--output-format", "json",
-	}
+	ctx, span := tracer.Start(ctx, "Executor.Execute", trace.WithAttributes(
+		attribute.String("orca.model", req.Model),
+		attribute.Int("orca.max_turns", req.MaxTurns),
+		attribute.Bool("orca.resume", req.ResumeSession != ""),
+	))
+	defer span.End()
 
-	// Model mapping: map orca shortnames to claude CLI model flags.
-	if model := resolveModel(req.Model); model != "" {
-		args = append(args, "--model", model)
-	}
-
-	if req.SystemPrompt != "" {
-		args = append(args, "--system-prompt", req.SystemPrompt)
+	proc := e.takeWarm(req)
+	if proc == nil {
+		var err error
+		proc, err = e.spawn(req)
+		if err != nil {
+			return nil, fmt.Errorf("starting claude CLI: %w", err)
+		}
 	}
 
+	span.SetAttributes(attribute.Bool("orca.warm", proc.warm))
 	e.logger.Debug("executing claude CLI",
 		zap.String("bin", e.cliBin),
 		zap.String("model", req.Model),
+		zap.Int("maxTurns", req.MaxTurns),
 		zap.Int("promptLen", len(req.Prompt)),
+		zap.Bool("warm", proc.warm),
 	)
 
-	cmd := exec.CommandContext(ctx, e.cliBin, args...)
-
-	// Unset CLAUDECODE env var to allow nested invocation.
-	cmd.Env = filterEnv(os.Environ(), "CLAUDECODE")
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	var onLine func([]byte)
+	if req.OnStep != nil {
+		live := newStreamParser()
+		onLine = func(line []byte) {
+			// Malformed lines are reported by the final parse below.
+			steps, _ := live.feed(line)
+			for _, step := range steps {
+				req.OnStep(step)
+			}
+		}
+	}
 
-	if err := cmd.Run(); err != nil {
+	stdout, stderr, err := proc.run(ctx, req.Prompt, onLine)
+	if err != nil {
 		errMsg := stderr.String()
 		if errMsg == "" {
 			errMsg = err.Error()
@@ -104,12 +154,13 @@ func (e *Executor) Execute(ctx context.Context, req ExecutionRequest) (*Executio
 			zap.Error(err),
 			zap.String("stderr", errMsg),
 		)
+		span.SetStatus(codes.Error, errMsg)
 		return nil, fmt.Errorf("claude CLI error: %s", strings.TrimSpace(errMsg))
 	}
 
-	// Parse JSON response.
-	var resp cliResponse
-	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+	// Parse the event stream into the final response and the step trace.
+	resp, steps, err := parseStream(stdout.Bytes())
+	if err != nil {
 		e.logger.Error("failed to parse claude CLI output",
 			zap.Error(err),
 			zap.String("raw", stdout.String()),
@@ -126,6 +177,8 @@ func (e *Executor) Execute(ctx context.Context, req ExecutionRequest) (*Executio
 		TokensIn:  resp.Usage.InputTokens,
 		TokensOut: resp.Usage.OutputTokens,
 		CostUSD:   resp.TotalCost,
+		SessionID: resp.SessionID,
+		Steps:     steps,
 	}
 
 	e.logger.Debug("claude CLI call completed",
@@ -133,11 +186,61 @@ func (e *Executor) Execute(ctx context.Context, req ExecutionRequest) (*Executio
 		zap.Int("tokensOut", result.TokensOut),
 		zap.Float64("costUSD", result.CostUSD),
 		zap.Int("durationMs", resp.DurationMs),
+		zap.Int("steps", len(steps)),
 	)
 
 	return result, nil
 }
 
+// buildArgs returns the CLI arguments for a request. The prompt itself is not
+// included; it is delivered on stdin.
+func buildArgs(req ExecutionRequest) []string {
+	args := []string{
+		"-p",
+		"--output-format", "stream-json",
+		"--verbose", // required by the CLI for stream-json in print mode
+	}
+
+	// Model mapping: map orca shortnames to claude CLI model flags.
+	if model := resolveModel(req.Model); model != "" {
+		args = append(args, "--model", model)
+	}
+
+	if req.SystemPrompt != "" {
+		args = append(args, "--system-prompt", req.SystemPrompt)
+	}
+
+	if req.ResumeSession != "" {
+		args = append(args, "--resume", req.ResumeSession)
+	}
+
+	if req.MaxTurns > 0 {
+		args = append(args, "--max-turns", strconv.Itoa(req.MaxTurns))
+	}
+
+	if req.PermissionMode != "" {
+		args = append(args, "--permission-mode", req.PermissionMode)
+	}
+
+	if len(req.AllowedTools) > 0 {
+		args = append(args, "--allowedTools", strings.Join(req.AllowedTools, ","))
+	}
+
+	if len(req.DisallowedTools) > 0 {
+		args = append(args, "--disallowedTools", strings.Join(req.DisallowedTools, ","))
+	}
+
+	for _, dir := range req.AddDirs {
+		args = append(args, "--add-dir", dir)
+	}
+
+	if req.MCPConfig != "" {
+		args = append(args, "--mcp-config", req.MCPConfig)
+	}
+
+	return args
+}
+
 // resolveModel maps orca's human-friendly model shortnames to
 // Claude CLI --model flag values.
 func resolveModel(model string) string {
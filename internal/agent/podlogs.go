@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// podLogCapacity bounds the entries kept per pod; the oldest are dropped.
+const podLogCapacity = 1000
+
+// LogOptions selects which of a pod's log entries to return.
+type LogOptions struct {
+	// Since drops entries older than this time when non-zero.
+	Since time.Time
+	// Tail keeps only the last Tail entries when positive.
+	Tail int
+}
+
+// logFollowBuffer is how many entries a follower may lag behind before new
+// entries are dropped for it.
+const logFollowBuffer = 256
+
+// podLogs keeps the recent log entries of each pod in memory, keyed by the
+// pod's store key, and fans new entries out to followers.
+type podLogs struct {
+	mu        sync.Mutex
+	entries   map[string][]v1alpha1.LogEntry
+	followers map[string]map[chan v1alpha1.LogEntry]struct{}
+}
+
+func newPodLogs() *podLogs {
+	return &podLogs{
+		entries:   make(map[string][]v1alpha1.LogEntry),
+		followers: make(map[string]map[chan v1alpha1.LogEntry]struct{}),
+	}
+}
+
+func (l *podLogs) append(key string, entry v1alpha1.LogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := append(l.entries[key], entry)
+	if len(entries) > podLogCapacity {
+		entries = append([]v1alpha1.LogEntry(nil), entries[len(entries)-podLogCapacity:]...)
+	}
+	l.entries[key] = entries
+
+	for ch := range l.followers[key] {
+		select {
+		case ch <- entry:
+		default:
+			// The follower is not keeping up; drop rather than block the
+			// runtime.
+		}
+	}
+}
+
+func (l *podLogs) list(key string, opts LogOptions) []v1alpha1.LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.listLocked(key, opts)
+}
+
+func (l *podLogs) listLocked(key string, opts LogOptions) []v1alpha1.LogEntry {
+	entries := l.entries[key]
+	if !opts.Since.IsZero() {
+		i := 0
+		for i < len(entries) && entries[i].Timestamp.Before(opts.Since) {
+			i++
+		}
+		entries = entries[i:]
+	}
+	if opts.Tail > 0 && len(entries) > opts.Tail {
+		entries = entries[len(entries)-opts.Tail:]
+	}
+	return append([]v1alpha1.LogEntry{}, entries...)
+}
+
+// follow returns the entries selected by opts and a channel receiving every
+// entry appended afterwards, with nothing lost or repeated in between. stop
+// must be called to release the channel.
+func (l *podLogs) follow(key string, opts LogOptions) (backlog []v1alpha1.LogEntry, ch <-chan v1alpha1.LogEntry, stop func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c := make(chan v1alpha1.LogEntry, logFollowBuffer)
+	if l.followers[key] == nil {
+		l.followers[key] = make(map[chan v1alpha1.LogEntry]struct{})
+	}
+	l.followers[key][c] = struct{}{}
+
+	stop = func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.followers[key], c)
+		if len(l.followers[key]) == 0 {
+			delete(l.followers, key)
+		}
+	}
+	return l.listLocked(key, opts), c, stop
+}
+
+// podLog records a log line for the pod.
+func (r *Runtime) podLog(project, podName, level, format string, args ...interface{}) {
+	r.logs.append(store.ResourceKey(v1alpha1.KindAgentPod, project, podName), v1alpha1.LogEntry{
+		Timestamp: time.Now(),
+		PodName:   podName,
+		Level:     level,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// Logs returns the pod's recent log entries, oldest first.
+func (r *Runtime) Logs(project, podName string, opts LogOptions) []v1alpha1.LogEntry {
+	return r.logs.list(store.ResourceKey(v1alpha1.KindAgentPod, project, podName), opts)
+}
+
+// FollowLogs is like Logs but also returns a channel of the entries recorded
+// from now on. Call stop once done following.
+func (r *Runtime) FollowLogs(project, podName string, opts LogOptions) (backlog []v1alpha1.LogEntry, entries <-chan v1alpha1.LogEntry, stop func()) {
+	return r.logs.follow(store.ResourceKey(v1alpha1.KindAgentPod, project, podName), opts)
+}
@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestPodLogsTailAndSince(t *testing.T) {
+	l := newPodLogs()
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		l.append("pod", v1alpha1.LogEntry{
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Message:   fmt.Sprintf("line %d", i),
+		})
+	}
+
+	if got := l.list("pod", LogOptions{}); len(got) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(got))
+	}
+
+	got := l.list("pod", LogOptions{Tail: 2})
+	if len(got) != 2 || got[0].Message != "line 3" || got[1].Message != "line 4" {
+		t.Errorf("tail=2 returned %+v", got)
+	}
+
+	got = l.list("pod", LogOptions{Since: base.Add(2 * time.Minute)})
+	if len(got) != 3 || got[0].Message != "line 2" {
+		t.Errorf("since returned %+v", got)
+	}
+
+	got = l.list("pod", LogOptions{Since: base.Add(2 * time.Minute), Tail: 1})
+	if len(got) != 1 || got[0].Message != "line 4" {
+		t.Errorf("since+tail returned %+v", got)
+	}
+
+	if got := l.list("other", LogOptions{}); len(got) != 0 {
+		t.Errorf("expected no entries for unknown pod, got %d", len(got))
+	}
+}
+
+func TestPodLogsCapacity(t *testing.T) {
+	l := newPodLogs()
+	for i := 0; i < podLogCapacity+10; i++ {
+		l.append("pod", v1alpha1.LogEntry{Message: fmt.Sprintf("line %d", i)})
+	}
+
+	got := l.list("pod", LogOptions{})
+	if len(got) != podLogCapacity {
+		t.Fatalf("expected %d entries, got %d", podLogCapacity, len(got))
+	}
+	if got[0].Message != "line 10" {
+		t.Errorf("oldest entry = %q, want line 10", got[0].Message)
+	}
+}
+
+func TestPodLogsFollow(t *testing.T) {
+	l := newPodLogs()
+	l.append("pod", v1alpha1.LogEntry{Message: "old 1"})
+	l.append("pod", v1alpha1.LogEntry{Message: "old 2"})
+
+	backlog, ch, stop := l.follow("pod", LogOptions{Tail: 1})
+	if len(backlog) != 1 || backlog[0].Message != "old 2" {
+		t.Fatalf("backlog = %+v", backlog)
+	}
+
+	l.append("other", v1alpha1.LogEntry{Message: "elsewhere"})
+	l.append("pod", v1alpha1.LogEntry{Message: "new"})
+	select {
+	case e := <-ch:
+		if e.Message != "new" {
+			t.Errorf("followed entry = %q, want new", e.Message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no entry received")
+	}
+
+	stop()
+	l.append("pod", v1alpha1.LogEntry{Message: "after stop"})
+	select {
+	case e := <-ch:
+		t.Errorf("received %q after stop", e.Message)
+	default:
+	}
+	if len(l.followers) != 0 {
+		t.Errorf("followers not released: %d", len(l.followers))
+	}
+}
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// renderPrompt returns the prompt to send for task. When PromptTemplate is
+// set it is rendered as a Go text/template with Params as data; referencing a
+// parameter that was not supplied is an error.
+func renderPrompt(task *v1alpha1.DevTask) (string, error) {
+	if task.Spec.PromptTemplate == "" {
+		return task.Spec.Prompt, nil
+	}
+
+	tmpl, err := template.New(task.Metadata.Name).
+		Option("missingkey=error").
+		Parse(task.Spec.PromptTemplate)
+	if err != nil {
+		return "", fmt.Errorf("parsing prompt template: %w", err)
+	}
+
+	params := task.Spec.Params
+	if params == nil {
+		params = map[string]string{}
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, params); err != nil {
+		return "", fmt.Errorf("rendering prompt template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"testing"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	task := &v1alpha1.DevTask{
+		Spec: v1alpha1.DevTaskSpec{
+			Prompt:         "ignored",
+			PromptTemplate: "Review {{.file}} for {{.focus}} issues",
+			Params:         map[string]string{"file": "auth.go", "focus": "security"},
+		},
+	}
+	got, err := renderPrompt(task)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Review auth.go for security issues" {
+		t.Errorf("unexpected prompt %q", got)
+	}
+
+	delete(task.Spec.Params, "focus")
+	if _, err := renderPrompt(task); err == nil {
+		t.Error("expected error for missing param, got nil")
+	}
+
+	plain := &v1alpha1.DevTask{Spec: v1alpha1.DevTaskSpec{Prompt: "just do it"}}
+	if got, _ := renderPrompt(plain); got != "just do it" {
+		t.Errorf("expected plain prompt, got %q", got)
+	}
+}
@@ -0,0 +1,289 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// Backend executes requests against a model provider. The Executor is the
+// backend of claude-cli providers and of pods without a provider.
+type Backend interface {
+	Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error)
+}
+
+// BackendFactory creates the backend of a ModelProvider. apiKey is the value
+// of the provider's credentials secret, or empty.
+type BackendFactory func(spec v1alpha1.ModelProviderSpec, apiKey string) (Backend, error)
+
+// Registry resolves ModelProviders to their backends by provider type.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[v1alpha1.ProviderType]BackendFactory
+}
+
+// NewRegistry creates a Registry with the built-in provider types. Every
+// claude-cli provider shares cli, and so its warm sessions; the HTTP
+// providers send their requests with client.
+func NewRegistry(cli *Executor, client *http.Client) *Registry {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	g := &Registry{factories: make(map[v1alpha1.ProviderType]BackendFactory)}
+	g.Register(v1alpha1.ProviderClaudeCLI, func(v1alpha1.ModelProviderSpec, string) (Backend, error) {
+		return cli, nil
+	})
+	g.Register(v1alpha1.ProviderAnthropicAPI, func(spec v1alpha1.ModelProviderSpec, apiKey string) (Backend, error) {
+		return &anthropicBackend{client: client, endpoint: endpointOr(spec.Endpoint, "https://api.anthropic.com"), apiKey: apiKey}, nil
+	})
+	g.Register(v1alpha1.ProviderOpenAI, func(spec v1alpha1.ModelProviderSpec, apiKey string) (Backend, error) {
+		return &openAIBackend{client: client, endpoint: endpointOr(spec.Endpoint, "https://api.openai.com/v1"), apiKey: apiKey}, nil
+	})
+	g.Register(v1alpha1.ProviderOllama, func(spec v1alpha1.ModelProviderSpec, apiKey string) (Backend, error) {
+		return &openAIBackend{client: client, endpoint: endpointOr(spec.Endpoint, "http://localhost:11434/v1"), apiKey: apiKey}, nil
+	})
+	return g
+}
+
+// Register makes providers of type t use the backends created by f,
+// replacing any previous factory for t.
+func (g *Registry) Register(t v1alpha1.ProviderType, f BackendFactory) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.factories[t] = f
+}
+
+// Backend returns the backend for a provider with the given spec.
+func (g *Registry) Backend(spec v1alpha1.ModelProviderSpec, apiKey string) (Backend, error) {
+	g.mu.RLock()
+	f, ok := g.factories[spec.Type]
+	g.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown provider type %q", spec.Type)
+	}
+	return f(spec, apiKey)
+}
+
+func endpointOr(endpoint, def string) string {
+	if endpoint == "" {
+		return def
+	}
+	return strings.TrimRight(endpoint, "/")
+}
+
+// postJSON sends body to url and decodes the JSON response into out. A
+// response with an error status is returned as an error quoting its body.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(raw)))
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
+
+// singleTurn builds the result of a one-shot API call, reporting the answer
+// as the only step. API calls keep no session to resume.
+func singleTurn(req ExecutionRequest, output string, tokensIn, tokensOut int) *ExecutionResult {
+	step := v1alpha1.TaskStep{
+		Turn:      1,
+		Type:      v1alpha1.StepMessage,
+		Text:      output,
+		TokensIn:  tokensIn,
+		TokensOut: tokensOut,
+	}
+	if req.OnStep != nil {
+		req.OnStep(step)
+	}
+	return &ExecutionResult{
+		Output:    output,
+		TokensIn:  tokensIn,
+		TokensOut: tokensOut,
+		Steps:     []v1alpha1.TaskStep{step},
+	}
+}
+
+// anthropicBackend answers each request with one call to the Anthropic
+// Messages API. Tools and agentic turns are not available.
+type anthropicBackend struct {
+	client   *http.Client
+	endpoint string
+	apiKey   string
+}
+
+func (b *anthropicBackend) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
+	body := map[string]interface{}{
+		"model":      req.Model,
+		"max_tokens": req.MaxTokens,
+		"messages":   []map[string]string{{"role": "user", "content": req.Prompt}},
+	}
+	if req.SystemPrompt != "" {
+		body["system"] = req.SystemPrompt
+	}
+	header := http.Header{}
+	header.Set("x-api-key", b.apiKey)
+	header.Set("anthropic-version", "2023-06-01")
+
+	var resp struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := postJSON(ctx, b.client, b.endpoint+"/v1/messages", header, body, &resp); err != nil {
+		return nil, fmt.Errorf("anthropic API error: %w", err)
+	}
+
+	var out strings.Builder
+	for _, c := range resp.Content {
+		if c.Type == "text" {
+			out.WriteString(c.Text)
+		}
+	}
+	return singleTurn(req, out.String(), resp.Usage.InputTokens, resp.Usage.OutputTokens), nil
+}
+
+// openAIBackend answers each request with one call to an OpenAI-compatible
+// chat completions API, as served by OpenAI and Ollama. Tools and agentic
+// turns are not available.
+type openAIBackend struct {
+	client   *http.Client
+	endpoint string
+	apiKey   string
+}
+
+func (b *openAIBackend) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
+	var messages []map[string]string
+	if req.SystemPrompt != "" {
+		messages = append(messages, map[string]string{"role": "system", "content": req.SystemPrompt})
+	}
+	messages = append(messages, map[string]string{"role": "user", "content": req.Prompt})
+	body := map[string]interface{}{
+		"model":    req.Model,
+		"messages": messages,
+	}
+	if req.MaxTokens > 0 {
+		body["max_tokens"] = req.MaxTokens
+	}
+	header := http.Header{}
+	if b.apiKey != "" {
+		header.Set("Authorization", "Bearer "+b.apiKey)
+	}
+
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := postJSON(ctx, b.client, b.endpoint+"/chat/completions", header, body, &resp); err != nil {
+		return nil, fmt.Errorf("chat completions API error: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("chat completions API returned no choices")
+	}
+	return singleTurn(req, resp.Choices[0].Message.Content, resp.Usage.PromptTokens, resp.Usage.CompletionTokens), nil
+}
+
+// provider is what a pod's ModelProvider resolves to for one execution.
+type provider struct {
+	backend Backend
+	// env is added to the environment of claude-cli executions.
+	env []string
+	// limiters are the pod's and the provider's request limits.
+	limiters []*rateLimiter
+	spec     *v1alpha1.ModelProviderSpec // nil without a provider
+}
+
+// resolveProvider loads the pod's ModelProvider and its credentials and
+// returns the backend executing the pod's requests.
+func (r *Runtime) resolveProvider(pod *v1alpha1.AgentPod) (*provider, error) {
+	podKey := store.ResourceKey(v1alpha1.KindAgentPod, pod.Metadata.Project, pod.Metadata.Name)
+	p := &provider{
+		backend:  r.executor,
+		limiters: []*rateLimiter{r.limiterFor(podKey, pod.Spec.MaxRequestsPerMinute)},
+	}
+	if pod.Spec.Provider == "" {
+		return p, nil
+	}
+
+	var mp v1alpha1.ModelProvider
+	key := store.ResourceKey(v1alpha1.KindModelProvider, pod.Metadata.Project, pod.Spec.Provider)
+	if err := r.store.Get(key, &mp); err != nil {
+		if err == store.ErrNotFound {
+			return nil, fmt.Errorf("model provider %q not found in project %q", pod.Spec.Provider, pod.Metadata.Project)
+		}
+		return nil, fmt.Errorf("getting model provider %q: %w", pod.Spec.Provider, err)
+	}
+
+	var apiKey string
+	if ref := mp.Spec.CredentialsSecret; ref != nil {
+		data, err := r.secretData(pod.Metadata.Project, ref.Name)
+		if err != nil {
+			return nil, fmt.Errorf("model provider %s: %w", mp.Metadata.Name, err)
+		}
+		v, ok := data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("model provider %s: key %q not found in secret %q", mp.Metadata.Name, ref.Key, ref.Name)
+		}
+		apiKey = v
+	}
+
+	backend, err := r.providers.Backend(mp.Spec, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("model provider %s: %w", mp.Metadata.Name, err)
+	}
+	p.backend = backend
+	p.spec = &mp.Spec
+	p.limiters = append(p.limiters, r.limiterFor(key, mp.Spec.MaxRequestsPerMinute))
+	if mp.Spec.Type == v1alpha1.ProviderClaudeCLI {
+		if apiKey != "" {
+			p.env = append(p.env, "ANTHROPIC_API_KEY="+apiKey)
+		}
+		if mp.Spec.Endpoint != "" {
+			p.env = append(p.env, "ANTHROPIC_BASE_URL="+mp.Spec.Endpoint)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestHTTPBackends(t *testing.T) {
+	var got struct {
+		path, auth string
+		body       map[string]interface{}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.path = r.URL.Path
+		got.auth = r.Header.Get("Authorization") + r.Header.Get("x-api-key")
+		got.body = nil
+		json.NewDecoder(r.Body).Decode(&got.body)
+		switch r.URL.Path {
+		case "/v1/messages":
+			w.Write([]byte(`{"content":[{"type":"text","text":"hi from claude"}],"usage":{"input_tokens":10,"output_tokens":3}}`))
+		case "/v1/chat/completions":
+			w.Write([]byte(`{"choices":[{"message":{"content":"hi from gpt"}}],"usage":{"prompt_tokens":7,"completion_tokens":2}}`))
+		default:
+			http.Error(w, `{"error":"no such model"}`, http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	g := NewRegistry(nil, srv.Client())
+	req := ExecutionRequest{Model: "m", SystemPrompt: "be terse", Prompt: "hello", MaxTokens: 100}
+
+	b, err := g.Backend(v1alpha1.ModelProviderSpec{Type: v1alpha1.ProviderAnthropicAPI, Endpoint: srv.URL}, "key-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := b.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Output != "hi from claude" || res.TokensIn != 10 || res.TokensOut != 3 || len(res.Steps) != 1 {
+		t.Errorf("anthropic result = %+v", res)
+	}
+	if got.auth != "key-a" || got.body["system"] != "be terse" || got.body["model"] != "m" {
+		t.Errorf("anthropic request: auth %q, body %v", got.auth, got.body)
+	}
+
+	b, err = g.Backend(v1alpha1.ModelProviderSpec{Type: v1alpha1.ProviderOpenAI, Endpoint: srv.URL + "/v1/"}, "key-o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err = b.Execute(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if res.Output != "hi from gpt" || res.TokensIn != 7 || res.TokensOut != 2 {
+		t.Errorf("openai result = %+v", res)
+	}
+	if got.auth != "Bearer key-o" || len(got.body["messages"].([]interface{})) != 2 {
+		t.Errorf("openai request: auth %q, body %v", got.auth, got.body)
+	}
+
+	b, _ = g.Backend(v1alpha1.ModelProviderSpec{Type: v1alpha1.ProviderOllama, Endpoint: srv.URL}, "")
+	if _, err := b.Execute(context.Background(), req); err == nil {
+		t.Error("a 404 response did not fail the request")
+	}
+	if _, err := g.Backend(v1alpha1.ModelProviderSpec{Type: "bard"}, ""); err == nil {
+		t.Error("an unknown provider type resolved")
+	}
+}
+
+// fixedBackend answers every request with the same usage.
+type fixedBackend struct{ apiKey string }
+
+func (b fixedBackend) Execute(ctx context.Context, req ExecutionRequest) (*ExecutionResult, error) {
+	return &ExecutionResult{Output: b.apiKey, TokensIn: 2_000_000, TokensOut: 1_000_000, CostUSD: 99}, nil
+}
+
+func TestResolveProvider(t *testing.T) {
+	st := store.NewMemoryStore()
+	r := NewRuntime(st, nil, config.DefaultConfig(), nil)
+	r.providers.Register("fixed", func(_ v1alpha1.ModelProviderSpec, apiKey string) (Backend, error) {
+		return fixedBackend{apiKey}, nil
+	})
+
+	sec := &v1alpha1.Secret{Data: map[string]string{"key": base64.StdEncoding.EncodeToString([]byte("s3cret"))}}
+	sec.Metadata = v1alpha1.ObjectMeta{Name: "creds", Project: "web"}
+	mp := &v1alpha1.ModelProvider{Spec: v1alpha1.ModelProviderSpec{
+		Type:              "fixed",
+		CredentialsSecret: &v1alpha1.SecretKeySelector{Name: "creds", Key: "key"},
+		Costs:             []v1alpha1.ModelCost{{Model: "m", InputPerMTok: 3, OutputPerMTok: 15}},
+	}}
+	mp.Metadata = v1alpha1.ObjectMeta{Name: "p", Project: "web"}
+	cli := &v1alpha1.ModelProvider{Spec: v1alpha1.ModelProviderSpec{
+		Type:              v1alpha1.ProviderClaudeCLI,
+		Endpoint:          "https://gateway.example.com",
+		CredentialsSecret: &v1alpha1.SecretKeySelector{Name: "creds", Key: "key"},
+	}}
+	cli.Metadata = v1alpha1.ObjectMeta{Name: "cli", Project: "web"}
+	for key, obj := range map[string]interface{}{
+		store.ResourceKey(v1alpha1.KindSecret, "web", "creds"):      sec,
+		store.ResourceKey(v1alpha1.KindModelProvider, "web", "p"):   mp,
+		store.ResourceKey(v1alpha1.KindModelProvider, "web", "cli"): cli,
+	} {
+		if err := st.Create(key, obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pod := &v1alpha1.AgentPod{Metadata: v1alpha1.ObjectMeta{Name: "a", Project: "web"}}
+	pod.Spec.Provider = "p"
+	p, err := r.resolveProvider(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.execute(context.Background(), p, ExecutionRequest{Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Output != "s3cret" || res.CostUSD != 21 {
+		t.Errorf("result = %+v, want the secret as API key and $21 from the cost table", res)
+	}
+	if res, _ = r.execute(context.Background(), p, ExecutionRequest{Model: "other"}); res.CostUSD != 99 {
+		t.Errorf("cost of an unpriced model = %v, want the backend's", res.CostUSD)
+	}
+
+	pod.Spec.Provider = "cli"
+	if p, err = r.resolveProvider(pod); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ANTHROPIC_API_KEY=s3cret", "ANTHROPIC_BASE_URL=https://gateway.example.com"}; !slices.Equal(p.env, want) {
+		t.Errorf("claude-cli env = %q, want %q", p.env, want)
+	}
+
+	pod.Spec.Provider = "missing"
+	if _, err := r.resolveProvider(pod); err == nil {
+		t.Error("a missing provider resolved")
+	}
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter allows at most limit calls in any sliding window. A nil
+// *rateLimiter never blocks.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu    sync.Mutex
+	calls []time.Time // start times of calls still inside the window
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window}
+}
+
+// wait blocks until a call may start, reserving the slot, or until ctx is
+// done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		delay := l.reserve(time.Now())
+		if delay == 0 {
+			return nil
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve records a call at now if the window has room and returns 0;
+// otherwise it returns how long until the oldest call leaves the window.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(l.calls) && !l.calls[i].After(cutoff) {
+		i++
+	}
+	l.calls = l.calls[i:]
+
+	if len(l.calls) < l.limit {
+		l.calls = append(l.calls, now)
+		return 0
+	}
+	return l.calls[0].Sub(cutoff)
+}
+
+// limiterFor returns the request limiter for the pod at key, creating or
+// resizing it as needed. It returns nil when perMinute is not positive.
+func (r *Runtime) limiterFor(key string, perMinute int) *rateLimiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if perMinute <= 0 {
+		delete(r.limiters, key)
+		return nil
+	}
+	l, ok := r.limiters[key]
+	if !ok || l.limit != perMinute {
+		l = newRateLimiter(perMinute, time.Minute)
+		r.limiters[key] = l
+	}
+	return l
+}
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := newRateLimiter(2, time.Minute)
+	start := time.Now()
+
+	if d := l.reserve(start); d != 0 {
+		t.Fatalf("first call delayed by %v", d)
+	}
+	if d := l.reserve(start.Add(10 * time.Second)); d != 0 {
+		t.Fatalf("second call delayed by %v", d)
+	}
+	if d := l.reserve(start.Add(20 * time.Second)); d != 40*time.Second {
+		t.Errorf("third call: expected 40s delay, got %v", d)
+	}
+	if d := l.reserve(start.Add(61 * time.Second)); d != 0 {
+		t.Errorf("call after window: expected no delay, got %v", d)
+	}
+}
+
+func TestRateLimiterWaitHonoursContext(t *testing.T) {
+	l := newRateLimiter(1, time.Hour)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+
+	var nilLimiter *rateLimiter
+	if err := nilLimiter.wait(context.Background()); err != nil {
+		t.Errorf("nil limiter should never block, got %v", err)
+	}
+}
@@ -2,10 +2,17 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/klubi/orca/internal/config"
@@ -13,27 +20,56 @@ import (
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 )
 
+var tracer = otel.Tracer("github.com/klubi/orca/internal/agent")
+
 // Runtime manages the lifecycle of AgentPods and coordinates task
 // execution via the Claude API Executor.
 type Runtime struct {
 	store    store.Store
 	executor *Executor
-	cfg      *config.Config
-	logger   *zap.Logger
-	mu       sync.Mutex
+	// providers resolves the ModelProviders referenced by pods.
+	providers *Registry
+	// cfg is replaced by SetConfig when the config file is reloaded.
+	cfg    atomic.Pointer[config.Config]
+	logger *zap.Logger
+	mu     sync.Mutex
 	// active tracks running agent goroutines by pod name.
 	active map[string]context.CancelFunc
+	// limiters enforces MaxRequestsPerMinute by pod key.
+	limiters map[string]*rateLimiter
+	// logs holds the recent log entries served by `orca logs`.
+	logs *podLogs
+	// streams carries the live steps of running tasks.
+	streams *taskStreams
+	// executions cancels the executions of Running tasks by task key.
+	executions map[string]context.CancelCauseFunc
 }
 
+// ErrTaskCancelled is the error of a task whose execution was cancelled with
+// CancelTask.
+var ErrTaskCancelled = errors.New("task cancelled")
+
 // NewRuntime creates a new agent Runtime.
 func NewRuntime(s store.Store, executor *Executor, cfg *config.Config, logger *zap.Logger) *Runtime {
-	return &Runtime{
-		store:    s,
-		executor: executor,
-		cfg:      cfg,
-		logger:   logger,
-		active:   make(map[string]context.CancelFunc),
+	r := &Runtime{
+		store:      s,
+		executor:   executor,
+		providers:  NewRegistry(executor, nil),
+		logger:     logger,
+		active:     make(map[string]context.CancelFunc),
+		limiters:   make(map[string]*rateLimiter),
+		logs:       newPodLogs(),
+		streams:    newTaskStreams(),
+		executions: make(map[string]context.CancelCauseFunc),
 	}
+	r.cfg.Store(cfg)
+	return r
+}
+
+// SetConfig replaces the runtime's configuration. Only the settings that
+// config.Config.Reload applies may differ from the previous configuration.
+func (r *Runtime) SetConfig(cfg *config.Config) {
+	r.cfg.Store(cfg)
 }
 
 // StartPod transitions an AgentPod from Pending to Ready.
@@ -53,6 +89,8 @@ func (r *Runtime) StartPod(ctx context.Context, pod *v1alpha1.AgentPod) error {
 	// Transition to Starting
 	pod.Status.Phase = v1alpha1.PodStarting
 	pod.Status.Message = "Initializing agent context"
+	v1alpha1.SetCondition(&pod.Status.Conditions, v1alpha1.NewCondition(
+		v1alpha1.ConditionReady, v1alpha1.ConditionFalse, v1alpha1.ReasonStarting, pod.Status.Message))
 	pod.Metadata.UpdatedAt = time.Now()
 	if err := r.store.Update(key, pod); err != nil {
 		return fmt.Errorf("failed to set pod Starting: %w", err)
@@ -61,6 +99,49 @@ func (r *Runtime) StartPod(ctx context.Context, pod *v1alpha1.AgentPod) error {
 	// In a full implementation, this is where we would initialize the
 	// agent's working directory, load tools, validate API keys, etc.
 
+	// The agent is given access to the project's artifact directories,
+	// which must exist when the CLI starts.
+	if err := os.MkdirAll(r.artifactsRoot(pod.Metadata.Project), 0o755); err != nil {
+		r.logger.Warn("cannot create artifact directory",
+			zap.String("pod", pod.Metadata.Name),
+			zap.Error(err),
+		)
+	}
+	// Volumes are mounted again before every task, so that tasks see the
+	// current Secrets and ConfigMaps; a volume that cannot be mounted yet
+	// fails the tasks, not the pod.
+	if _, err := r.mountVolumes(pod); err != nil {
+		r.logger.Warn("cannot mount volumes",
+			zap.String("pod", pod.Metadata.Name),
+			zap.Error(err),
+		)
+	}
+
+	// Pre-start CLI sessions so the first tasks skip the CLI cold start.
+	// Only pods served by the claude CLI have sessions to warm.
+	if pod.Spec.WarmSessions > 0 {
+		req, err := r.podRequest(pod)
+		var (
+			env []string
+			p   *provider
+		)
+		if err == nil {
+			env, err = r.resolveEnv(pod)
+		}
+		if err == nil {
+			p, err = r.resolveProvider(pod)
+		}
+		if err != nil {
+			r.logger.Warn("skipping warm sessions: cannot resolve tools, env or provider",
+				zap.String("pod", pod.Metadata.Name),
+				zap.Error(err),
+			)
+		} else if _, ok := p.backend.(*Executor); ok {
+			req.Env = append(env, p.env...)
+			r.executor.Warm(req.WarmKey, req, pod.Spec.WarmSessions)
+		}
+	}
+
 	// Create a cancellable context for this pod's lifetime
 	_, cancel := context.WithCancel(ctx)
 	r.active[pod.Metadata.Name] = cancel
@@ -70,6 +151,8 @@ func (r *Runtime) StartPod(ctx context.Context, pod *v1alpha1.AgentPod) error {
 	pod.Status.Phase = v1alpha1.PodReady
 	pod.Status.Message = ""
 	pod.Status.StartedAt = now
+	v1alpha1.SetCondition(&pod.Status.Conditions, v1alpha1.NewCondition(
+		v1alpha1.ConditionReady, v1alpha1.ConditionTrue, v1alpha1.ReasonStarted, ""))
 	pod.Status.LastHeartbeat = now
 	pod.Metadata.UpdatedAt = now
 	if err := r.store.Update(key, pod); err != nil {
@@ -80,6 +163,7 @@ func (r *Runtime) StartPod(ctx context.Context, pod *v1alpha1.AgentPod) error {
 		zap.String("pod", pod.Metadata.Name),
 		zap.String("model", pod.Spec.Model),
 	)
+	r.podLog(pod.Metadata.Project, pod.Metadata.Name, "INFO", "pod ready (model %s)", pod.Spec.Model)
 
 	return nil
 }
@@ -106,6 +190,8 @@ func (r *Runtime) StopPod(ctx context.Context, podName, project string) error {
 	// Transition to Terminating
 	pod.Status.Phase = v1alpha1.PodTerminating
 	pod.Status.Message = "Shutting down"
+	v1alpha1.SetCondition(&pod.Status.Conditions, v1alpha1.NewCondition(
+		v1alpha1.ConditionReady, v1alpha1.ConditionFalse, v1alpha1.ReasonTerminating, pod.Status.Message))
 	pod.Metadata.UpdatedAt = time.Now()
 	if err := r.store.Update(key, &pod); err != nil {
 		return fmt.Errorf("failed to set pod Terminating: %w", err)
@@ -116,16 +202,24 @@ func (r *Runtime) StopPod(ctx context.Context, podName, project string) error {
 		cancel()
 		delete(r.active, podName)
 	}
+	r.executor.Drain(key)
+	delete(r.limiters, key)
+	if err := os.RemoveAll(r.volumesRoot(project, podName)); err != nil {
+		r.logger.Warn("cannot remove volumes", zap.String("pod", podName), zap.Error(err))
+	}
 
 	// Transition to Terminated
 	pod.Status.Phase = v1alpha1.PodTerminated
 	pod.Status.Message = "Stopped"
+	v1alpha1.SetCondition(&pod.Status.Conditions, v1alpha1.NewCondition(
+		v1alpha1.ConditionReady, v1alpha1.ConditionFalse, v1alpha1.ReasonTerminated, pod.Status.Message))
 	pod.Metadata.UpdatedAt = time.Now()
 	if err := r.store.Update(key, &pod); err != nil {
 		return fmt.Errorf("failed to set pod Terminated: %w", err)
 	}
 
 	r.logger.Info("pod terminated", zap.String("pod", podName))
+	r.podLog(project, podName, "INFO", "pod terminated")
 
 	return nil
 }
@@ -142,12 +236,32 @@ func (r *Runtime) ExecuteTask(ctx context.Context, task *v1alpha1.DevTask, pod *
 		zap.String("pod", pod.Metadata.Name),
 	)
 
+	ctx, span := tracer.Start(ctx, "Runtime.ExecuteTask", trace.WithAttributes(
+		attribute.String("orca.project", task.Metadata.Project),
+		attribute.String("orca.task", task.Metadata.Name),
+		attribute.String("orca.pod", pod.Metadata.Name),
+	))
+	defer span.End()
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	r.mu.Lock()
+	r.executions[taskKey] = cancel
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.executions, taskKey)
+		r.mu.Unlock()
+	}()
+
 	now := time.Now()
 
 	// Mark task as Running
 	task.Status.Phase = v1alpha1.TaskRunning
 	task.Status.AssignedPod = pod.Metadata.Name
 	task.Status.StartedAt = now
+	task.Status.Steps = nil
+	task.Status.TokensIn, task.Status.TokensOut, task.Status.CostUSD = 0, 0, 0
 	task.Metadata.UpdatedAt = now
 	if err := r.store.Update(taskKey, task); err != nil {
 		return fmt.Errorf("failed to set task Running: %w", err)
@@ -163,29 +277,64 @@ func (r *Runtime) ExecuteTask(ctx context.Context, task *v1alpha1.DevTask, pod *
 		return fmt.Errorf("failed to set pod Busy: %w", err)
 	}
 	r.mu.Unlock()
-
-	// Build the execution request
-	model := pod.Spec.Model
+	r.podLog(pod.Metadata.Project, pod.Metadata.Name, "INFO", "task %s started", task.Metadata.Name)
+	r.streams.begin(taskKey)
+
+	// Build the execution request, render the prompt, mount the pod's
+	// volumes and resolve its environment (including Secret and ConfigMap
+	// references) and model provider, then call the model. A broken tool
+	// definition, a template error, a missing Secret or a schema violation
+	// fails the task like any other execution error.
+	var (
+		result     *ExecutionResult
+		structured interface{}
+		env        []string
+		p          *provider
+	)
+	req, err := r.podRequest(pod)
 	if task.Spec.PreferredModel != "" {
-		model = task.Spec.PreferredModel
+		req.Model = task.Spec.PreferredModel
 	}
-
-	maxTokens := pod.Spec.MaxTokens
-	if maxTokens == 0 {
-		maxTokens = r.cfg.Agent.DefaultMaxTokens
+	req.ResumeSession = task.Spec.SessionID
+	req.OnStep = func(step v1alpha1.TaskStep) {
+		r.streams.step(taskKey, step)
 	}
-
-	req := ExecutionRequest{
-		Model:        model,
-		SystemPrompt: pod.Spec.SystemPrompt,
-		Prompt:       task.Spec.Prompt,
-		MaxTokens:    maxTokens,
+	if err == nil {
+		req.Prompt, err = renderPrompt(task)
+	}
+	if err == nil {
+		artifactDir := r.ArtifactDir(task.Metadata.Project, task.Metadata.Name)
+		if err = os.MkdirAll(artifactDir, 0o755); err != nil {
+			err = fmt.Errorf("creating artifact directory: %w", err)
+		}
+		req.Prompt += artifactsPrompt(artifactDir)
+	}
+	if err == nil {
+		var volumes map[string]string
+		if volumes, err = r.mountVolumes(pod); err == nil {
+			req.Prompt += volumesPrompt(volumes)
+		}
+	}
+	if err == nil {
+		env, err = r.resolveEnv(pod)
+	}
+	if err == nil {
+		p, err = r.resolveProvider(pod)
+	}
+	if err == nil {
+		req.Env = append(env, p.env...)
+		if task.Spec.OutputSchema != nil {
+			result, structured, err = r.executeStructured(ctx, p, req, task.Spec.OutputSchema)
+		} else {
+			result, err = r.execute(ctx, p, req)
+		}
 	}
-
-	// Call the Claude API
-	result, err := r.executor.Execute(ctx, req)
 
 	finishedAt := time.Now()
+	if err != nil && errors.Is(context.Cause(ctx), ErrTaskCancelled) {
+		err = ErrTaskCancelled
+		task.Status.Cancelled = true
+	}
 
 	// Update task status based on the result
 	if err != nil {
@@ -196,7 +345,14 @@ func (r *Runtime) ExecuteTask(ctx context.Context, task *v1alpha1.DevTask, pod *
 		task.Status.Phase = v1alpha1.TaskFailed
 		task.Status.Error = err.Error()
 		task.Status.FinishedAt = finishedAt
+		reason := v1alpha1.ReasonFailed
+		if task.Status.Cancelled {
+			reason = v1alpha1.ReasonCancelled
+		}
+		v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(
+			v1alpha1.ConditionComplete, v1alpha1.ConditionFalse, reason, task.Status.Error))
 		task.Metadata.UpdatedAt = finishedAt
+		r.podLog(pod.Metadata.Project, pod.Metadata.Name, "ERROR", "task %s failed: %v", task.Metadata.Name, err)
 	} else {
 		r.logger.Info("task execution succeeded",
 			zap.String("task", task.Metadata.Name),
@@ -205,8 +361,24 @@ func (r *Runtime) ExecuteTask(ctx context.Context, task *v1alpha1.DevTask, pod *
 		)
 		task.Status.Phase = v1alpha1.TaskSucceeded
 		task.Status.Output = result.Output
+		task.Status.StructuredOutput = structured
+		task.Status.Steps = result.Steps
+		task.Status.TokensIn = result.TokensIn
+		task.Status.TokensOut = result.TokensOut
+		task.Status.CostUSD = result.CostUSD
+		task.Status.Model = req.Model
+		task.Status.SessionID = result.SessionID
 		task.Status.FinishedAt = finishedAt
+		v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(
+			v1alpha1.ConditionComplete, v1alpha1.ConditionTrue, v1alpha1.ReasonSucceeded, ""))
 		task.Metadata.UpdatedAt = finishedAt
+		for _, step := range result.Steps {
+			if step.Type == v1alpha1.StepToolCall {
+				r.podLog(pod.Metadata.Project, pod.Metadata.Name, "DEBUG", "task %s: %s %s", task.Metadata.Name, step.Tool, step.Input)
+			}
+		}
+		r.podLog(pod.Metadata.Project, pod.Metadata.Name, "INFO", "task %s succeeded in %s (%d in / %d out tokens)",
+			task.Metadata.Name, finishedAt.Sub(now).Round(time.Second), result.TokensIn, result.TokensOut)
 	}
 
 	r.logger.Debug("writing task result to store",
@@ -215,7 +387,9 @@ func (r *Runtime) ExecuteTask(ctx context.Context, task *v1alpha1.DevTask, pod *
 		zap.Int("outputLen", len(task.Status.Output)),
 	)
 
-	if storeErr := r.store.Update(taskKey, task); storeErr != nil {
+	storeErr := r.store.Update(taskKey, task)
+	r.streams.finish(taskKey, task.Status.Phase)
+	if storeErr != nil {
 		return fmt.Errorf("failed to update task status: %w", storeErr)
 	}
 
@@ -229,6 +403,9 @@ func (r *Runtime) ExecuteTask(ctx context.Context, task *v1alpha1.DevTask, pod *
 		pod.Status.FailedTasks++
 	} else {
 		pod.Status.CompletedTasks++
+		pod.Status.TokensIn += result.TokensIn
+		pod.Status.TokensOut += result.TokensOut
+		pod.Status.CostUSD += result.CostUSD
 	}
 	pod.Metadata.UpdatedAt = finishedAt
 	if storeErr := r.store.Update(podKey, pod); storeErr != nil {
@@ -238,6 +415,102 @@ func (r *Runtime) ExecuteTask(ctx context.Context, task *v1alpha1.DevTask, pod *
 	return nil
 }
 
+// CancelTask cancels the execution of a Running task, which then fails with
+// ErrTaskCancelled. It reports whether the task was executing.
+func (r *Runtime) CancelTask(project, name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cancel, ok := r.executions[store.ResourceKey(v1alpha1.KindDevTask, project, name)]
+	if ok {
+		cancel(ErrTaskCancelled)
+	}
+	return ok
+}
+
+// execute runs req on the provider's backend once the pod's and the
+// provider's request rate limits allow it. Models listed in the provider's
+// cost table are charged from it.
+func (r *Runtime) execute(ctx context.Context, p *provider, req ExecutionRequest) (*ExecutionResult, error) {
+	providerType := string(v1alpha1.ProviderClaudeCLI)
+	if p.spec != nil {
+		providerType = string(p.spec.Type)
+	}
+	ctx, span := tracer.Start(ctx, "Runtime.execute", trace.WithAttributes(
+		attribute.String("orca.model", req.Model),
+		attribute.String("orca.provider", providerType),
+	))
+	defer span.End()
+
+	for _, limiter := range p.limiters {
+		if err := limiter.wait(ctx); err != nil {
+			return nil, fmt.Errorf("waiting for request rate limit: %w", err)
+		}
+	}
+	span.AddEvent("rate limits passed")
+	result, err := p.backend.Execute(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if p.spec != nil {
+		if cost, ok := p.spec.Cost(req.Model, result.TokensIn, result.TokensOut); ok {
+			result.CostUSD = cost
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("orca.tokens_in", result.TokensIn),
+		attribute.Int("orca.tokens_out", result.TokensOut),
+		attribute.Float64("orca.cost_usd", result.CostUSD),
+		attribute.Int("orca.steps", len(result.Steps)),
+	)
+	return result, nil
+}
+
+// podRequest builds the execution request shared by every task run on pod.
+// The prompt and per-task overrides are filled in by the caller.
+func (r *Runtime) podRequest(pod *v1alpha1.AgentPod) (ExecutionRequest, error) {
+	// Tools are declared with orca names in manifests; the CLI expects its
+	// own tool names.
+	tools, err := r.resolveTools(pod)
+	if err != nil {
+		return ExecutionRequest{}, err
+	}
+	mcp, err := mcpConfig(tools.mcpServers)
+	if err != nil {
+		return ExecutionRequest{}, fmt.Errorf("encoding MCP config: %w", err)
+	}
+
+	maxTokens := pod.Spec.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = r.cfg.Load().Agent.DefaultMaxTokens
+	}
+
+	model := pod.Spec.Model
+	if model == "" {
+		model = r.cfg.Load().Agent.DefaultModel
+	}
+
+	req := ExecutionRequest{
+		Model:           model,
+		SystemPrompt:    pod.Spec.SystemPrompt,
+		MaxTokens:       maxTokens,
+		MaxTurns:        pod.Spec.MaxTurns,
+		PermissionMode:  string(pod.Spec.PermissionMode),
+		AllowedTools:    tools.allowed,
+		DisallowedTools: tools.disallowed,
+		AddDirs:         append([]string{r.artifactsRoot(pod.Metadata.Project)}, tools.dirs...),
+		MCPConfig:       mcp,
+	}
+	if len(pod.Spec.Volumes) > 0 {
+		req.AddDirs = append(req.AddDirs, r.volumesRoot(pod.Metadata.Project, pod.Metadata.Name))
+	}
+	if pod.Spec.WarmSessions > 0 {
+		req.WarmKey = store.ResourceKey(v1alpha1.KindAgentPod, pod.Metadata.Project, pod.Metadata.Name)
+	}
+	return req, nil
+}
+
 // Heartbeat updates the pod's last heartbeat timestamp in the store.
 func (r *Runtime) Heartbeat(podName, project string) error {
 	key := store.ResourceKey(v1alpha1.KindAgentPod, project, podName)
@@ -258,6 +531,21 @@ func (r *Runtime) Heartbeat(podName, project string) error {
 	return nil
 }
 
+// Ready reports whether the runtime can execute tasks, based on the
+// executor's last self-check.
+func (r *Runtime) Ready() error {
+	res, ok := r.executor.LastSelfCheck()
+	if !ok {
+		return fmt.Errorf("claude CLI self-check has not run")
+	}
+	return res.Err
+}
+
+// DefaultMaxTokens is the token limit of pods that don't set maxTokens.
+func (r *Runtime) DefaultMaxTokens() int {
+	return r.cfg.Load().Agent.DefaultMaxTokens
+}
+
 // IsActive checks whether a pod is actively managed by this runtime.
 func (r *Runtime) IsActive(podName string) bool {
 	r.mu.Lock()
@@ -265,3 +553,88 @@ func (r *Runtime) IsActive(podName string) bool {
 	_, ok := r.active[podName]
 	return ok
 }
+
+// Recover reconciles persisted state with the (empty) in-memory runtime after
+// the control plane restarts:
+//
+//   - Pods in Pending/Starting/Ready/Busy are restarted; their active task
+//     counters are reset since no execution survived the restart, and they
+//     get the runtime finalizer if they lack it.
+//   - Pods stuck in Terminating are moved to Terminated.
+//   - Tasks in Running are marked Failed so the DevTask controller can retry
+//     them according to their MaxRetries.
+//
+// Pods assigned to a node, and the tasks running on them, are left to the
+// node's worker.
+func (r *Runtime) Recover(ctx context.Context) error {
+	podObjs, err := r.store.List("/"+v1alpha1.KindAgentPod+"/", func() interface{} { return &v1alpha1.AgentPod{} })
+	if err != nil {
+		return fmt.Errorf("listing pods: %w", err)
+	}
+
+	var restarted, terminated, failedTasks int
+	remote := make(map[string]bool)
+	for _, obj := range podObjs {
+		pod := obj.(*v1alpha1.AgentPod)
+		if pod.Spec.NodeName != "" {
+			remote[store.ResourceKey(v1alpha1.KindAgentPod, pod.Metadata.Project, pod.Metadata.Name)] = true
+			continue
+		}
+		switch pod.Status.Phase {
+		case v1alpha1.PodPending, v1alpha1.PodStarting, v1alpha1.PodReady, v1alpha1.PodBusy:
+			pod.Status.ActiveTasks = 0
+			pod.Metadata.AddFinalizer(v1alpha1.FinalizerRuntime)
+			if err := r.StartPod(ctx, pod); err != nil {
+				r.logger.Error("failed to restart pod during recovery",
+					zap.String("pod", pod.Metadata.Name),
+					zap.Error(err),
+				)
+				continue
+			}
+			restarted++
+		case v1alpha1.PodTerminating:
+			key := store.ResourceKey(v1alpha1.KindAgentPod, pod.Metadata.Project, pod.Metadata.Name)
+			pod.Status.Phase = v1alpha1.PodTerminated
+			pod.Status.Message = "Stopped"
+			v1alpha1.SetCondition(&pod.Status.Conditions, v1alpha1.NewCondition(
+				v1alpha1.ConditionReady, v1alpha1.ConditionFalse, v1alpha1.ReasonTerminated, pod.Status.Message))
+			pod.Metadata.UpdatedAt = time.Now()
+			if err := r.store.Update(key, pod); err != nil {
+				return fmt.Errorf("terminating pod %q: %w", pod.Metadata.Name, err)
+			}
+			terminated++
+		}
+	}
+
+	taskObjs, err := r.store.List("/"+v1alpha1.KindDevTask+"/", func() interface{} { return &v1alpha1.DevTask{} })
+	if err != nil {
+		return fmt.Errorf("listing tasks: %w", err)
+	}
+
+	for _, obj := range taskObjs {
+		task := obj.(*v1alpha1.DevTask)
+		if task.Status.Phase != v1alpha1.TaskRunning ||
+			remote[store.ResourceKey(v1alpha1.KindAgentPod, task.Metadata.Project, task.Status.AssignedPod)] {
+			continue
+		}
+		key := store.ResourceKey(v1alpha1.KindDevTask, task.Metadata.Project, task.Metadata.Name)
+		now := time.Now()
+		task.Status.Phase = v1alpha1.TaskFailed
+		task.Status.Error = "interrupted: control plane restarted while the task was running"
+		task.Status.FinishedAt = now
+		v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(
+			v1alpha1.ConditionComplete, v1alpha1.ConditionFalse, v1alpha1.ReasonInterrupted, task.Status.Error))
+		task.Metadata.UpdatedAt = now
+		if err := r.store.Update(key, task); err != nil {
+			return fmt.Errorf("failing orphaned task %q: %w", task.Metadata.Name, err)
+		}
+		failedTasks++
+	}
+
+	r.logger.Info("runtime state recovered",
+		zap.Int("podsRestarted", restarted),
+		zap.Int("podsTerminated", terminated),
+		zap.Int("tasksInterrupted", failedTasks),
+	)
+	return nil
+}
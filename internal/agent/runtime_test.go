@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestRecover(t *testing.T) {
+	st := store.NewMemoryStore()
+	cfg := config.DefaultConfig()
+	cfg.Store.DataDir = t.TempDir()
+	r := NewRuntime(st, nil, cfg, zap.NewNop())
+
+	pods := map[string]*v1alpha1.AgentPod{
+		"pending":     {Status: v1alpha1.AgentPodStatus{Phase: v1alpha1.PodPending}},
+		"starting":    {Status: v1alpha1.AgentPodStatus{Phase: v1alpha1.PodStarting}},
+		"ready":       {Status: v1alpha1.AgentPodStatus{Phase: v1alpha1.PodReady}},
+		"busy":        {Status: v1alpha1.AgentPodStatus{Phase: v1alpha1.PodBusy, ActiveTasks: 2}},
+		"terminating": {Status: v1alpha1.AgentPodStatus{Phase: v1alpha1.PodTerminating}},
+		"failed":      {Status: v1alpha1.AgentPodStatus{Phase: v1alpha1.PodFailed}},
+		"remote": {
+			Spec:   v1alpha1.AgentPodSpec{NodeName: "gpu-1"},
+			Status: v1alpha1.AgentPodStatus{Phase: v1alpha1.PodBusy, ActiveTasks: 1},
+		},
+	}
+	for name, pod := range pods {
+		pod.Metadata = v1alpha1.ObjectMeta{Name: name, Project: "web"}
+		pod.Spec.Model = "claude-sonnet"
+		if err := st.Create(store.ResourceKey(v1alpha1.KindAgentPod, "web", name), pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tasks := map[string]*v1alpha1.DevTask{
+		"local":   {Status: v1alpha1.DevTaskStatus{Phase: v1alpha1.TaskRunning, AssignedPod: "busy"}},
+		"remote":  {Status: v1alpha1.DevTaskStatus{Phase: v1alpha1.TaskRunning, AssignedPod: "remote"}},
+		"pending": {Status: v1alpha1.DevTaskStatus{Phase: v1alpha1.TaskPending}},
+	}
+	for name, task := range tasks {
+		task.Metadata = v1alpha1.ObjectMeta{Name: name, Project: "web"}
+		task.Spec.Prompt = "fix it"
+		if err := st.Create(store.ResourceKey(v1alpha1.KindDevTask, "web", name), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.Recover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	pod := func(name string) *v1alpha1.AgentPod {
+		t.Helper()
+		var p v1alpha1.AgentPod
+		if err := st.Get(store.ResourceKey(v1alpha1.KindAgentPod, "web", name), &p); err != nil {
+			t.Fatal(err)
+		}
+		return &p
+	}
+	for _, name := range []string{"pending", "starting", "ready", "busy"} {
+		p := pod(name)
+		if p.Status.Phase != v1alpha1.PodReady || p.Status.ActiveTasks != 0 || !p.Metadata.HasFinalizer(v1alpha1.FinalizerRuntime) {
+			t.Errorf("pod %s recovered as %s with %d active tasks and finalizers %v, want Ready with none active and the runtime finalizer",
+				name, p.Status.Phase, p.Status.ActiveTasks, p.Metadata.Finalizers)
+		}
+	}
+	if p := pod("terminating"); p.Status.Phase != v1alpha1.PodTerminated {
+		t.Errorf("terminating pod recovered as %s, want Terminated", p.Status.Phase)
+	}
+	if p := pod("failed"); p.Status.Phase != v1alpha1.PodFailed {
+		t.Errorf("failed pod recovered as %s, want it left Failed", p.Status.Phase)
+	}
+	if p := pod("remote"); p.Status.Phase != v1alpha1.PodBusy || p.Status.ActiveTasks != 1 || len(p.Metadata.Finalizers) != 0 {
+		t.Errorf("pod of a node recovered as %s with %d active tasks and finalizers %v, want it left to its worker",
+			p.Status.Phase, p.Status.ActiveTasks, p.Metadata.Finalizers)
+	}
+
+	task := func(name string) *v1alpha1.DevTask {
+		t.Helper()
+		var task v1alpha1.DevTask
+		if err := st.Get(store.ResourceKey(v1alpha1.KindDevTask, "web", name), &task); err != nil {
+			t.Fatal(err)
+		}
+		return &task
+	}
+	local := task("local")
+	if local.Status.Phase != v1alpha1.TaskFailed || local.Status.FinishedAt.IsZero() {
+		t.Errorf("interrupted task recovered as %s, finished at %v, want Failed with a finish time", local.Status.Phase, local.Status.FinishedAt)
+	}
+	if c := v1alpha1.FindCondition(local.Status.Conditions, v1alpha1.ConditionComplete); c == nil || c.Reason != v1alpha1.ReasonInterrupted {
+		t.Errorf("interrupted task has Complete condition %+v, want reason %s", c, v1alpha1.ReasonInterrupted)
+	}
+	if phase := task("remote").Status.Phase; phase != v1alpha1.TaskRunning {
+		t.Errorf("task on a node's pod recovered as %s, want it left Running", phase)
+	}
+	if phase := task("pending").Status.Phase; phase != v1alpha1.TaskPending {
+		t.Errorf("pending task recovered as %s, want it left Pending", phase)
+	}
+}
@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// selfCheckTimeout bounds the trivial CLI invocation made by SelfCheck.
+const selfCheckTimeout = 15 * time.Second
+
+// SelfCheckResult is the outcome of Executor.SelfCheck.
+type SelfCheckResult struct {
+	// Path is the resolved location of the claude binary.
+	Path string
+	// Version is the CLI's reported version.
+	Version   string
+	CheckedAt time.Time
+	// Err is non-nil when the CLI is unusable.
+	Err error
+}
+
+// SelfCheck verifies that the configured claude CLI exists, is executable and
+// answers a trivial invocation (`claude --version`). The result is remembered
+// and reported by LastSelfCheck.
+func (e *Executor) SelfCheck(ctx context.Context) SelfCheckResult {
+	res := SelfCheckResult{CheckedAt: time.Now()}
+
+	path, err := exec.LookPath(e.cliBin)
+	if err != nil {
+		res.Err = fmt.Errorf("claude CLI %q not found or not executable: %w", e.cliBin, err)
+	} else {
+		res.Path = path
+
+		ctx, cancel := context.WithTimeout(ctx, selfCheckTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, path, "--version")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			msg := strings.TrimSpace(string(out))
+			if msg == "" {
+				msg = err.Error()
+			}
+			res.Err = fmt.Errorf("claude CLI %s did not respond to --version: %s", path, msg)
+		} else {
+			res.Version = strings.TrimSpace(string(out))
+		}
+	}
+
+	e.mu.Lock()
+	e.selfCheck = &res
+	e.mu.Unlock()
+	return res
+}
+
+// LastSelfCheck returns the most recent SelfCheck result. ok is false if
+// SelfCheck has never run.
+func (e *Executor) LastSelfCheck() (res SelfCheckResult, ok bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.selfCheck == nil {
+		return SelfCheckResult{}, false
+	}
+	return *e.selfCheck, true
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSelfCheck(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "claude")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\necho '1.2.3 (Claude Code)'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(bin, zap.NewNop())
+	if _, ok := e.LastSelfCheck(); ok {
+		t.Fatal("expected no self-check result before SelfCheck")
+	}
+
+	res := e.SelfCheck(context.Background())
+	if res.Err != nil {
+		t.Fatalf("unexpected error: %v", res.Err)
+	}
+	if res.Version != "1.2.3 (Claude Code)" {
+		t.Errorf("unexpected version %q", res.Version)
+	}
+	if last, ok := e.LastSelfCheck(); !ok || last.Version != res.Version {
+		t.Errorf("LastSelfCheck did not return the stored result: %+v", last)
+	}
+
+	missing := NewExecutor(filepath.Join(dir, "does-not-exist"), zap.NewNop())
+	if res := missing.SelfCheck(context.Background()); res.Err == nil {
+		t.Error("expected error for missing binary, got nil")
+	}
+}
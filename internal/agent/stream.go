@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// maxStepText bounds the text kept per step so traces stay small in the store.
+const maxStepText = 2000
+
+// streamEvent maps one line of `claude -p --output-format stream-json`.
+// The final line has type "result" and carries the same fields as the
+// plain JSON output format.
+type streamEvent struct {
+	cliResponse
+	Message struct {
+		Content []streamContent `json:"content"`
+		Usage   struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+type streamContent struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// streamParser decodes stream-json output one line at a time, accumulating
+// the step trace and the final response.
+type streamParser struct {
+	resp   *cliResponse
+	steps  []v1alpha1.TaskStep
+	turn   int
+	tools  map[string]string // tool_use id -> tool name
+	parsed int
+}
+
+func newStreamParser() *streamParser {
+	return &streamParser{tools: make(map[string]string)}
+}
+
+// feed decodes one line of output and returns the steps it produced.
+func (p *streamParser) feed(line []byte) ([]v1alpha1.TaskStep, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return nil, nil
+	}
+	var ev streamEvent
+	if err := json.Unmarshal(line, &ev); err != nil {
+		return nil, fmt.Errorf("decoding stream event: %w", err)
+	}
+	p.parsed++
+
+	before := len(p.steps)
+	switch ev.Type {
+	case "assistant":
+		p.turn++
+		first := true
+		for _, c := range ev.Message.Content {
+			step := v1alpha1.TaskStep{Turn: p.turn}
+			switch c.Type {
+			case "text":
+				step.Type = v1alpha1.StepMessage
+				step.Text = truncateStep(c.Text)
+			case "tool_use":
+				step.Type = v1alpha1.StepToolCall
+				step.Tool = c.Name
+				step.Input = truncateStep(string(c.Input))
+				p.tools[c.ID] = c.Name
+			default:
+				continue
+			}
+			if first {
+				step.TokensIn = ev.Message.Usage.InputTokens
+				step.TokensOut = ev.Message.Usage.OutputTokens
+				first = false
+			}
+			p.steps = append(p.steps, step)
+		}
+	case "user":
+		for _, c := range ev.Message.Content {
+			if c.Type != "tool_result" {
+				continue
+			}
+			p.steps = append(p.steps, v1alpha1.TaskStep{
+				Turn:    p.turn,
+				Type:    v1alpha1.StepToolResult,
+				Tool:    p.tools[c.ToolUseID],
+				Text:    truncateStep(toolResultText(c.Content)),
+				IsError: c.IsError,
+			})
+		}
+	case "result":
+		r := ev.cliResponse
+		p.resp = &r
+	}
+	return p.steps[before:], nil
+}
+
+// parseStream decodes the CLI's stream-json output into the final response
+// and the step trace. Output in the plain JSON format is accepted as well,
+// yielding no steps.
+func parseStream(out []byte) (*cliResponse, []v1alpha1.TaskStep, error) {
+	p := newStreamParser()
+
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for sc.Scan() {
+		if _, err := p.feed(sc.Bytes()); err != nil {
+			if p.parsed == 0 {
+				break // not line-delimited; try the whole output below
+			}
+			return nil, nil, err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, fmt.Errorf("reading stream: %w", err)
+	}
+
+	if p.parsed == 0 {
+		var r cliResponse
+		if err := json.Unmarshal(out, &r); err != nil {
+			return nil, nil, err
+		}
+		return &r, nil, nil
+	}
+	if p.resp == nil {
+		return nil, nil, fmt.Errorf("stream ended without a result event")
+	}
+	return p.resp, p.steps, nil
+}
+
+// toolResultText flattens a tool_result content value, which is either a
+// string or a list of content blocks.
+func toolResultText(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var blocks []streamContent
+	if err := json.Unmarshal(raw, &blocks); err == nil {
+		parts := make([]string, 0, len(blocks))
+		for _, b := range blocks {
+			if b.Type == "text" {
+				parts = append(parts, b.Text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return string(raw)
+}
+
+func truncateStep(s string) string {
+	if len(s) <= maxStepText {
+		return s
+	}
+	cut := maxStepText
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
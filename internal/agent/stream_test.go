@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"testing"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestParseStream(t *testing.T) {
+	out := []byte(`{"type":"system","subtype":"init","session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Let me look."},{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"main.go"}}],"usage":{"input_tokens":12,"output_tokens":7}}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"package main"}]}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Done."}],"usage":{"input_tokens":30,"output_tokens":3}}}
+{"type":"result","subtype":"success","is_error":false,"result":"Done.","total_cost_usd":0.01,"usage":{"input_tokens":42,"output_tokens":10}}
+`)
+
+	resp, steps, err := parseStream(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Result != "Done." || resp.Usage.InputTokens != 42 {
+		t.Errorf("unexpected result: %+v", resp)
+	}
+
+	want := []v1alpha1.TaskStep{
+		{Turn: 1, Type: v1alpha1.StepMessage, Text: "Let me look.", TokensIn: 12, TokensOut: 7},
+		{Turn: 1, Type: v1alpha1.StepToolCall, Tool: "Read", Input: `{"file_path":"main.go"}`},
+		{Turn: 1, Type: v1alpha1.StepToolResult, Tool: "Read", Text: "package main"},
+		{Turn: 2, Type: v1alpha1.StepMessage, Text: "Done.", TokensIn: 30, TokensOut: 3},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("expected %d steps, got %d: %+v", len(want), len(steps), steps)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("step %d: expected %+v, got %+v", i, want[i], steps[i])
+		}
+	}
+}
+
+func TestParseStreamPlainJSON(t *testing.T) {
+	out := []byte(`{
+  "type": "result",
+  "result": "ok"
+}`)
+	resp, steps, err := parseStream(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Result != "ok" || len(steps) != 0 {
+		t.Errorf("unexpected result %+v with steps %+v", resp, steps)
+	}
+
+	if _, _, err := parseStream([]byte(`{"type":"system"}` + "\n")); err == nil {
+		t.Error("expected error for stream without result event")
+	}
+}
+
+func TestLineBufferDeliversCompleteLines(t *testing.T) {
+	var b lineBuffer
+	var lines []string
+
+	// Output written before the callback is set is delivered on set.
+	b.Write([]byte("first\nsec"))
+	b.setOnLine(func(line []byte) { lines = append(lines, string(line)) })
+	b.Write([]byte("ond\nthi"))
+	b.Write([]byte("rd\n"))
+
+	want := []string{"first", "second", "third"}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %q, want %q", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+	if got := b.buf.String(); got != "first\nsecond\nthird\n" {
+		t.Errorf("buffered output = %q", got)
+	}
+}
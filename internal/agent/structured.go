@@ -0,0 +1,248 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// structuredOutputAttempts is how many times the model is asked for a
+// schema-conforming answer before the task fails.
+const structuredOutputAttempts = 3
+
+// executeStructured runs req and requires the answer to be JSON matching
+// schema. On a violation the model is re-prompted with the validation errors;
+// every attempt counts against the pod's and the provider's request rate
+// limits.
+// It returns the last raw result, with usage summed over all attempts, and the
+// parsed value.
+func (r *Runtime) executeStructured(ctx context.Context, p *provider, req ExecutionRequest, schema map[string]interface{}) (*ExecutionResult, interface{}, error) {
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding output schema: %w", err)
+	}
+
+	basePrompt := req.Prompt
+	req.Prompt = fmt.Sprintf("%s\n\nRespond ONLY with a JSON value (no prose, no code fences) that conforms to this JSON Schema:\n%s",
+		basePrompt, schemaJSON)
+
+	var (
+		lastErr error
+		usage   ExecutionResult // accumulated over attempts
+	)
+	for attempt := 1; attempt <= structuredOutputAttempts; attempt++ {
+		result, err := r.execute(ctx, p, req)
+		if err != nil {
+			return nil, nil, err
+		}
+		usage.TokensIn += result.TokensIn
+		usage.TokensOut += result.TokensOut
+		usage.CostUSD += result.CostUSD
+		result.TokensIn, result.TokensOut, result.CostUSD = usage.TokensIn, usage.TokensOut, usage.CostUSD
+
+		value, err := extractJSON(result.Output)
+		if err == nil {
+			if violations := validateSchema(schema, value); len(violations) > 0 {
+				err = fmt.Errorf("output does not match schema: %s", strings.Join(violations, "; "))
+			}
+		}
+		if err == nil {
+			return result, value, nil
+		}
+
+		lastErr = err
+		r.logger.Warn("structured output rejected",
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+		req.Prompt = fmt.Sprintf("%s\n\nYour previous answer was rejected: %v\nPrevious answer:\n%s\n\nRespond ONLY with a JSON value that conforms to this JSON Schema:\n%s",
+			basePrompt, err, result.Output, schemaJSON)
+	}
+
+	return nil, nil, fmt.Errorf("no schema-conforming output after %d attempts: %w", structuredOutputAttempts, lastErr)
+}
+
+// extractJSON parses the model output as JSON, tolerating surrounding code
+// fences or prose around a single top-level object or array.
+func extractJSON(output string) (interface{}, error) {
+	s := strings.TrimSpace(output)
+	if strings.HasPrefix(s, "```") {
+		s = strings.TrimPrefix(s, "```json")
+		s = strings.TrimPrefix(s, "```")
+		s = strings.TrimSuffix(strings.TrimSpace(s), "```")
+	}
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err == nil {
+		return v, nil
+	}
+
+	start := strings.IndexAny(s, "{[")
+	end := strings.LastIndexAny(s, "}]")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("output is not JSON")
+	}
+	if err := json.Unmarshal([]byte(s[start:end+1]), &v); err != nil {
+		return nil, fmt.Errorf("output is not JSON: %w", err)
+	}
+	return v, nil
+}
+
+// validateSchema checks v (as decoded by encoding/json) against a practical
+// subset of JSON Schema: type, enum, properties, required,
+// additionalProperties (boolean), items, minimum, maximum, minLength,
+// maxLength, minItems and maxItems. It returns one message per violation.
+func validateSchema(schema map[string]interface{}, v interface{}) []string {
+	var violations []string
+	validateAt("$", schema, v, &violations)
+	return violations
+}
+
+func validateAt(path string, schema map[string]interface{}, v interface{}, out *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*out = append(*out, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, v) {
+		fail("expected type %v, got %s", t, jsonType(v))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value %v is not one of %v", v, enum)
+		}
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, req := range required {
+				name, _ := req.(string)
+				if _, present := val[name]; !present {
+					fail("missing required property %q", name)
+				}
+			}
+		}
+		names := make([]string, 0, len(val))
+		for name := range val {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if sub, ok := props[name].(map[string]interface{}); ok {
+				validateAt(path+"."+name, sub, val[name], out)
+			} else if ap, ok := schema["additionalProperties"].(bool); ok && !ap {
+				fail("unexpected property %q", name)
+			}
+		}
+
+	case []interface{}:
+		if n, ok := toFloat(schema["minItems"]); ok && float64(len(val)) < n {
+			fail("expected at least %v items, got %d", n, len(val))
+		}
+		if n, ok := toFloat(schema["maxItems"]); ok && float64(len(val)) > n {
+			fail("expected at most %v items, got %d", n, len(val))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range val {
+				validateAt(fmt.Sprintf("%s[%d]", path, i), items, item, out)
+			}
+		}
+
+	case string:
+		if n, ok := toFloat(schema["minLength"]); ok && float64(len(val)) < n {
+			fail("expected length >= %v", n)
+		}
+		if n, ok := toFloat(schema["maxLength"]); ok && float64(len(val)) > n {
+			fail("expected length <= %v", n)
+		}
+
+	case float64:
+		if n, ok := toFloat(schema["minimum"]); ok && val < n {
+			fail("expected >= %v, got %v", n, val)
+		}
+		if n, ok := toFloat(schema["maximum"]); ok && val > n {
+			fail("expected <= %v, got %v", n, val)
+		}
+	}
+}
+
+// matchesType reports whether v satisfies a schema "type" (string or list).
+func matchesType(t interface{}, v interface{}) bool {
+	switch tt := t.(type) {
+	case string:
+		actual := jsonType(v)
+		if tt == "number" && actual == "integer" {
+			return true
+		}
+		return tt == actual
+	case []interface{}:
+		for _, one := range tt {
+			if matchesType(one, v) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// jsonType returns the JSON Schema type name of a decoded JSON value.
+func jsonType(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// toFloat converts numeric schema keywords, which may come from YAML (int) or
+// JSON (float64).
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// jsonEqual compares two values by their JSON encoding.
+func jsonEqual(a, b interface{}) bool {
+	aj, err1 := json.Marshal(a)
+	bj, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(aj) == string(bj)
+}
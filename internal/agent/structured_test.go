@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractJSON(t *testing.T) {
+	cases := map[string]string{
+		"plain":  `{"ok": true}`,
+		"fenced": "```json\n{\"ok\": true}\n```",
+		"prose":  "Here is the result:\n{\"ok\": true}\nHope this helps.",
+	}
+	for name, in := range cases {
+		v, err := extractJSON(in)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		obj, ok := v.(map[string]interface{})
+		if !ok || obj["ok"] != true {
+			t.Errorf("%s: expected {ok: true}, got %#v", name, v)
+		}
+	}
+
+	if _, err := extractJSON("no json here"); err == nil {
+		t.Error("expected error for non-JSON output, got nil")
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"severity", "files"},
+		"properties": map[string]interface{}{
+			"severity": map[string]interface{}{"type": "string", "enum": []interface{}{"low", "high"}},
+			"score":    map[string]interface{}{"type": "integer", "minimum": 0, "maximum": 10},
+			"files": map[string]interface{}{
+				"type":     "array",
+				"minItems": 1,
+				"items":    map[string]interface{}{"type": "string"},
+			},
+		},
+		"additionalProperties": false,
+	}
+
+	valid, _ := extractJSON(`{"severity": "high", "score": 7, "files": ["a.go"]}`)
+	if v := validateSchema(schema, valid); len(v) != 0 {
+		t.Errorf("expected no violations, got %v", v)
+	}
+
+	invalid, _ := extractJSON(`{"severity": "medium", "score": 11, "files": [1], "extra": 1}`)
+	violations := validateSchema(schema, invalid)
+	joined := strings.Join(violations, "\n")
+	for _, want := range []string{"$.severity", "$.score", "$.files[0]", `unexpected property "extra"`} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected violation mentioning %s, got:\n%s", want, joined)
+		}
+	}
+
+	missing, _ := extractJSON(`{"severity": "low"}`)
+	if v := validateSchema(schema, missing); len(v) != 1 || !strings.Contains(v[0], `"files"`) {
+		t.Errorf("expected one missing-property violation for files, got %v", v)
+	}
+}
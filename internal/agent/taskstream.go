@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"sync"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// taskStreams fans the steps of running tasks out to followers, keyed by the
+// task's store key. A stream exists while its task runs or is followed.
+type taskStreams struct {
+	mu      sync.Mutex
+	streams map[string]*taskStream
+}
+
+type taskStream struct {
+	running   bool
+	steps     []v1alpha1.TaskStep
+	followers map[chan v1alpha1.TaskStreamEvent]struct{}
+}
+
+func newTaskStreams() *taskStreams {
+	return &taskStreams{streams: make(map[string]*taskStream)}
+}
+
+// getLocked returns the stream for key, creating it. t.mu must be held.
+func (t *taskStreams) getLocked(key string) *taskStream {
+	s, ok := t.streams[key]
+	if !ok {
+		s = &taskStream{followers: make(map[chan v1alpha1.TaskStreamEvent]struct{})}
+		t.streams[key] = s
+	}
+	return s
+}
+
+// begin starts a new attempt, discarding the steps of a previous one.
+func (t *taskStreams) begin(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.getLocked(key)
+	s.running = true
+	s.steps = nil
+}
+
+// step records a step and passes it to every follower.
+func (t *taskStreams) step(key string, step v1alpha1.TaskStep) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.getLocked(key)
+	s.steps = append(s.steps, step)
+	for ch := range s.followers {
+		select {
+		case ch <- v1alpha1.TaskStreamEvent{Step: &step}:
+		default:
+			// The follower is not keeping up; drop rather than block the
+			// executor.
+		}
+	}
+}
+
+// finish ends the attempt: followers receive the final phase and their
+// channels are closed.
+func (t *taskStreams) finish(key string, phase v1alpha1.DevTaskPhase) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.streams[key]
+	if !ok {
+		return
+	}
+	for ch := range s.followers {
+		// The phase must not be dropped: if the buffer is full, make room
+		// by discarding the oldest pending step.
+		select {
+		case ch <- v1alpha1.TaskStreamEvent{Phase: phase}:
+		default:
+			<-ch
+			ch <- v1alpha1.TaskStreamEvent{Phase: phase}
+		}
+		close(ch)
+	}
+	delete(t.streams, key)
+}
+
+// follow returns the steps of the current attempt so far and a channel of
+// the events that follow, closed after the final phase. stop must be called
+// to release the channel.
+func (t *taskStreams) follow(key string) (backlog []v1alpha1.TaskStep, events <-chan v1alpha1.TaskStreamEvent, stop func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.getLocked(key)
+	ch := make(chan v1alpha1.TaskStreamEvent, logFollowBuffer)
+	s.followers[ch] = struct{}{}
+
+	stop = func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		// The stream may have finished (and been replaced) meanwhile.
+		if cur, ok := t.streams[key]; ok && cur == s {
+			delete(s.followers, ch)
+			if !s.running && len(s.followers) == 0 {
+				delete(t.streams, key)
+			}
+		}
+	}
+	return append([]v1alpha1.TaskStep(nil), s.steps...), ch, stop
+}
+
+// FollowTask returns the steps the task's current attempt has taken so far
+// and a channel of its further steps, ending with the attempt's final phase.
+// If the task is not running yet, the stream starts once it does. Call stop
+// once done following.
+func (r *Runtime) FollowTask(project, taskName string) (backlog []v1alpha1.TaskStep, events <-chan v1alpha1.TaskStreamEvent, stop func()) {
+	return r.streams.follow(store.ResourceKey(v1alpha1.KindDevTask, project, taskName))
+}
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"testing"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestTaskStreamsFollow(t *testing.T) {
+	ts := newTaskStreams()
+
+	// Following before the task starts receives the whole attempt.
+	backlog, events, stop := ts.follow("task")
+	defer stop()
+	if len(backlog) != 0 {
+		t.Fatalf("expected empty backlog, got %+v", backlog)
+	}
+
+	ts.begin("task")
+	ts.step("task", v1alpha1.TaskStep{Turn: 1, Type: v1alpha1.StepMessage, Text: "hi"})
+
+	// A late follower gets the steps so far as backlog.
+	late, _, stopLate := ts.follow("task")
+	stopLate()
+	if len(late) != 1 || late[0].Text != "hi" {
+		t.Fatalf("late backlog = %+v", late)
+	}
+
+	ts.finish("task", v1alpha1.TaskSucceeded)
+
+	var got []v1alpha1.TaskStreamEvent
+	for ev := range events {
+		got = append(got, ev)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %+v", got)
+	}
+	if got[0].Step == nil || got[0].Step.Text != "hi" {
+		t.Errorf("first event = %+v, want the step", got[0])
+	}
+	if got[1].Phase != v1alpha1.TaskSucceeded {
+		t.Errorf("last event = %+v, want phase Succeeded", got[1])
+	}
+	if len(ts.streams) != 0 {
+		t.Errorf("finished stream not released")
+	}
+}
+
+func TestTaskStreamsFinishWithFullBuffer(t *testing.T) {
+	ts := newTaskStreams()
+	_, events, stop := ts.follow("task")
+	defer stop()
+
+	ts.begin("task")
+	for i := 0; i < logFollowBuffer+5; i++ {
+		ts.step("task", v1alpha1.TaskStep{Turn: i})
+	}
+	ts.finish("task", v1alpha1.TaskFailed)
+
+	var last v1alpha1.TaskStreamEvent
+	for ev := range events {
+		last = ev
+	}
+	if last.Phase != v1alpha1.TaskFailed {
+		t.Errorf("final event = %+v, want phase Failed", last)
+	}
+}
+
+func TestTaskStreamsStopReleasesIdleStream(t *testing.T) {
+	ts := newTaskStreams()
+	_, _, stop := ts.follow("task")
+	stop()
+	if len(ts.streams) != 0 {
+		t.Errorf("idle stream not released after stop")
+	}
+}
@@ -1,20 +1,168 @@
-// Package agent implements the Claude-based agent runtime for Orca.
-// It handles Claude API interaction, tool definitions, and agent lifecycle management.
 package agent
 
-// ToolDef describes a tool an agent can use.
-type ToolDef struct {
-	Name        string
-	Description string
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// builtinTools are the tools of every project, bound to the Claude CLI tools
+// that implement them. A ToolDefinition of the same name in the pod's
+// project overrides a built-in tool. Names that are neither defined nor
+// built in are passed to the CLI unchanged, so manifests may also use CLI
+// names and patterns such as "Bash(git:*)".
+var builtinTools = map[string]v1alpha1.ToolDefinitionSpec{
+	"read_file":    {Description: "Read files", CLITools: []string{"Read"}},
+	"write_file":   {Description: "Create and modify files", CLITools: []string{"Write", "Edit", "MultiEdit"}},
+	"edit_file":    {Description: "Modify existing files", CLITools: []string{"Edit", "MultiEdit"}},
+	"list_files":   {Description: "List and find files", CLITools: []string{"LS", "Glob"}},
+	"search_code":  {Description: "Search file contents", CLITools: []string{"Grep", "Glob"}},
+	"run_command":  {Description: "Run shell commands", CLITools: []string{"Bash"}},
+	"web_fetch":    {Description: "Fetch web pages", CLITools: []string{"WebFetch"}},
+	"web_search":   {Description: "Search the web", CLITools: []string{"WebSearch"}},
+	"notebook":     {Description: "Edit Jupyter notebooks", CLITools: []string{"NotebookEdit"}},
+	"todo":         {Description: "Keep a todo list", CLITools: []string{"TodoWrite"}},
+	"run_subagent": {Description: "Delegate to a subagent", CLITools: []string{"Task"}},
+}
+
+// toolSet is what a pod's tools resolve to.
+type toolSet struct {
+	// allowed and disallowed are CLI tool names for --allowedTools and
+	// --disallowedTools.
+	allowed, disallowed []string
+	// mcpServers are the MCP servers of the allowed tools by tool name.
+	mcpServers map[string]v1alpha1.MCPServer
+	// dirs are the directories the allowed tools need.
+	dirs []string
+}
+
+// toolLookup returns the definition of the named tool, or nil if there is
+// none.
+type toolLookup func(name string) (*v1alpha1.ToolDefinitionSpec, error)
+
+// expandTools resolves a pod's allowed and disallowed tools into CLI tool
+// names, dropping duplicates while keeping the declared order. Disallowing
+// a tool disallows what it is bound to; the sandbox of an allowed tool adds
+// to the disallowed tools.
+func expandTools(allowed, disallowed []string, lookup toolLookup) (*toolSet, error) {
+	ts := &toolSet{}
+	for _, name := range allowed {
+		def, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		ts.allowed = append(ts.allowed, cliTools(name, def)...)
+		if def == nil {
+			continue
+		}
+		if def.MCP != nil {
+			if ts.mcpServers == nil {
+				ts.mcpServers = make(map[string]v1alpha1.MCPServer)
+			}
+			ts.mcpServers[name] = *def.MCP
+		}
+		if def.Sandbox != nil {
+			ts.disallowed = append(ts.disallowed, def.Sandbox.Deny...)
+			ts.dirs = append(ts.dirs, def.Sandbox.Paths...)
+		}
+	}
+	for _, name := range disallowed {
+		def, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		ts.disallowed = append(ts.disallowed, cliTools(name, def)...)
+	}
+	ts.allowed = uniqueNames(ts.allowed)
+	ts.disallowed = uniqueNames(ts.disallowed)
+	return ts, nil
+}
+
+// cliTools returns the CLI tools the named tool is bound to by def, or the
+// name itself without a definition.
+func cliTools(name string, def *v1alpha1.ToolDefinitionSpec) []string {
+	if def == nil {
+		return []string{name}
+	}
+	out := append([]string(nil), def.CLITools...)
+	if def.Command != "" {
+		out = append(out, "Bash("+def.Command+":*)")
+	}
+	if def.MCP != nil {
+		// The CLI names MCP tools mcp__<server>__<tool>; the server is
+		// named after the tool definition.
+		if len(def.MCP.Tools) == 0 {
+			out = append(out, "mcp__"+name)
+		}
+		for _, t := range def.MCP.Tools {
+			out = append(out, "mcp__"+name+"__"+t)
+		}
+	}
+	return out
+}
+
+// uniqueNames drops empty and repeated names, keeping the first occurrence.
+func uniqueNames(names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(names))
+	var out []string
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// resolveTools resolves the tools of pod against the ToolDefinitions of its
+// project and the built-in tools.
+func (r *Runtime) resolveTools(pod *v1alpha1.AgentPod) (*toolSet, error) {
+	return expandTools(pod.Spec.Tools, pod.Spec.DisallowedTools, func(name string) (*v1alpha1.ToolDefinitionSpec, error) {
+		var def v1alpha1.ToolDefinition
+		err := r.store.Get(store.ResourceKey(v1alpha1.KindToolDefinition, pod.Metadata.Project, name), &def)
+		switch {
+		case err == nil:
+			return &def.Spec, nil
+		case err != store.ErrNotFound:
+			return nil, fmt.Errorf("getting tool definition %q: %w", name, err)
+		}
+		if spec, ok := builtinTools[name]; ok {
+			return &spec, nil
+		}
+		return nil, nil
+	})
 }
 
-// AvailableTools returns the built-in tool definitions.
-// These are conceptual tool definitions for the agent system.
-// In a real implementation, these would map to actual Claude tool_use definitions.
-var AvailableTools = map[string]ToolDef{
-	"read_file":   {Name: "read_file", Description: "Read a file from disk"},
-	"write_file":  {Name: "write_file", Description: "Write content to a file"},
-	"run_command": {Name: "run_command", Description: "Execute a shell command"},
-	"search_code": {Name: "search_code", Description: "Search for patterns in code"},
-	"list_files":  {Name: "list_files", Description: "List files in a directory"},
+// mcpConfig encodes MCP servers in the format of the CLI's --mcp-config.
+func mcpConfig(servers map[string]v1alpha1.MCPServer) (string, error) {
+	if len(servers) == 0 {
+		return "", nil
+	}
+	type server struct {
+		Type    string            `json:"type,omitempty"`
+		Command string            `json:"command,omitempty"`
+		Args    []string          `json:"args,omitempty"`
+		Env     map[string]string `json:"env,omitempty"`
+		URL     string            `json:"url,omitempty"`
+	}
+	cfg := struct {
+		MCPServers map[string]server `json:"mcpServers"`
+	}{MCPServers: make(map[string]server, len(servers))}
+	for name, s := range servers {
+		if s.URL != "" {
+			cfg.MCPServers[name] = server{Type: "http", URL: s.URL}
+			continue
+		}
+		cfg.MCPServers[name] = server{Command: s.Command, Args: s.Args, Env: s.Env}
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
 }
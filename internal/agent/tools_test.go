@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// builtinLookup resolves only the built-in tools.
+func builtinLookup(name string) (*v1alpha1.ToolDefinitionSpec, error) {
+	if spec, ok := builtinTools[name]; ok {
+		return &spec, nil
+	}
+	return nil, nil
+}
+
+func TestResolveTools(t *testing.T) {
+	ts, err := expandTools([]string{"read_file", "write_file", "search_code", "Bash(git:*)", "Read"}, nil, builtinLookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Read", "Write", "Edit", "MultiEdit", "Grep", "Glob", "Bash(git:*)"}
+	if !reflect.DeepEqual(ts.allowed, want) {
+		t.Errorf("expected %v, got %v", want, ts.allowed)
+	}
+
+	if ts, _ := expandTools(nil, nil, builtinLookup); ts.allowed != nil || ts.disallowed != nil {
+		t.Errorf("expected nil for no tools, got %+v", ts)
+	}
+}
+
+func TestToolDefinitions(t *testing.T) {
+	st := store.NewMemoryStore()
+	r := &Runtime{store: st}
+	defs := map[string]v1alpha1.ToolDefinitionSpec{
+		// Overrides the built-in tool.
+		"read_file": {CLITools: []string{"Read", "Grep"}},
+		"tests":     {Command: "make test", Sandbox: &v1alpha1.ToolSandbox{Deny: []string{"Bash(rm:*)"}, Paths: []string{"/cache"}}},
+		"github":    {MCP: &v1alpha1.MCPServer{Command: "gh-mcp", Args: []string{"--stdio"}, Tools: []string{"create_issue"}}},
+		"docs":      {MCP: &v1alpha1.MCPServer{URL: "https://docs.example.com/mcp"}},
+	}
+	for name, spec := range defs {
+		def := &v1alpha1.ToolDefinition{Metadata: v1alpha1.ObjectMeta{Name: name, Project: "web"}, Spec: spec}
+		if err := st.Create(store.ResourceKey(v1alpha1.KindToolDefinition, "web", name), def); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pod := &v1alpha1.AgentPod{Metadata: v1alpha1.ObjectMeta{Name: "a", Project: "web"}}
+	pod.Spec.Tools = []string{"read_file", "tests", "github", "web_fetch"}
+	pod.Spec.DisallowedTools = []string{"docs", "run_command"}
+	ts, err := r.resolveTools(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Read", "Grep", "Bash(make test:*)", "mcp__github__create_issue", "WebFetch"}; !reflect.DeepEqual(ts.allowed, want) {
+		t.Errorf("allowed = %v, want %v", ts.allowed, want)
+	}
+	if want := []string{"Bash(rm:*)", "mcp__docs", "Bash"}; !reflect.DeepEqual(ts.disallowed, want) {
+		t.Errorf("disallowed = %v, want %v", ts.disallowed, want)
+	}
+	if !reflect.DeepEqual(ts.dirs, []string{"/cache"}) {
+		t.Errorf("dirs = %v", ts.dirs)
+	}
+
+	cfg, err := mcpConfig(ts.mcpServers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"mcpServers":{"github":{"command":"gh-mcp","args":["--stdio"]}}}`; cfg != want {
+		t.Errorf("mcp config = %s, want %s", cfg, want)
+	}
+}
+
+func TestBuildArgsTools(t *testing.T) {
+	args := buildArgs(ExecutionRequest{
+		AllowedTools:    []string{"Read", "Grep"},
+		DisallowedTools: []string{"Bash"},
+	})
+	want := map[string]string{"--allowedTools": "Read,Grep", "--disallowedTools": "Bash"}
+	for i := 0; i < len(args)-1; i++ {
+		if v, ok := want[args[i]]; ok {
+			if args[i+1] != v {
+				t.Errorf("%s: expected %q, got %q", args[i], v, args[i+1])
+			}
+			delete(want, args[i])
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing flags %v in %v", want, args)
+	}
+}
@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// volumesRoot is the directory holding a pod's volumes, one directory per
+// volume. Agents are given access to it with --add-dir.
+func (r *Runtime) volumesRoot(project, podName string) string {
+	return filepath.Join(r.cfg.Load().Store.DataDir, "volumes", project, podName)
+}
+
+// mountVolumes writes the keys of the Secrets and ConfigMaps of the pod's
+// volumes to files, one directory per volume, and returns the directories
+// by volume name. Each file is replaced atomically, so a task reading a
+// volume while another task of the pod mounts it sees either version; keys
+// removed from the source are removed from the directory.
+func (r *Runtime) mountVolumes(pod *v1alpha1.AgentPod) (map[string]string, error) {
+	if len(pod.Spec.Volumes) == 0 {
+		return nil, nil
+	}
+	root := r.volumesRoot(pod.Metadata.Project, pod.Metadata.Name)
+	dirs := make(map[string]string, len(pod.Spec.Volumes))
+	for _, vol := range pod.Spec.Volumes {
+		var (
+			data map[string]string
+			mode os.FileMode = 0o644
+			err  error
+		)
+		switch {
+		case vol.Secret != nil:
+			data, err = r.secretData(pod.Metadata.Project, vol.Secret.Name)
+			mode = 0o600
+		case vol.ConfigMap != nil:
+			data, err = r.configMapData(pod.Metadata.Project, vol.ConfigMap.Name)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("volume %s: %w", vol.Name, err)
+		}
+		dir := filepath.Join(root, vol.Name)
+		if err := writeVolume(dir, data, mode); err != nil {
+			return nil, fmt.Errorf("volume %s: %w", vol.Name, err)
+		}
+		dirs[vol.Name] = dir
+	}
+	return dirs, nil
+}
+
+// writeVolume makes dir hold exactly one file per key of data.
+func writeVolume(dir string, data map[string]string, mode os.FileMode) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	for key, value := range data {
+		if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+			return fmt.Errorf("invalid key %q", key)
+		}
+		tmp, err := os.CreateTemp(dir, ".tmp-")
+		if err != nil {
+			return err
+		}
+		_, err = tmp.WriteString(value)
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Chmod(tmp.Name(), mode)
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), filepath.Join(dir, key))
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if _, ok := data[e.Name()]; !ok && !strings.HasPrefix(e.Name(), ".tmp-") {
+			if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// volumesPrompt is appended to a task's prompt to tell the agent where the
+// pod's volumes are.
+func volumesPrompt(dirs map[string]string) string {
+	if len(dirs) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(dirs))
+	for name := range dirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString("\n\nThese directories hold files provided for this task, one file per key:")
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n- %s: %s", name, dirs[name])
+	}
+	return b.String()
+}
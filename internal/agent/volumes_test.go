@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestVolumesAndEnv(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Store.DataDir = t.TempDir()
+	st := store.NewMemoryStore()
+	r := NewRuntime(st, nil, cfg, nil)
+
+	sec := &v1alpha1.Secret{Data: map[string]string{"token": base64.StdEncoding.EncodeToString([]byte("s3cret"))}}
+	sec.Metadata = v1alpha1.ObjectMeta{Name: "gh", Project: "web"}
+	cm := &v1alpha1.ConfigMap{Data: map[string]string{"review.md": "Be thorough.", "LEVEL": "strict"}}
+	cm.Metadata = v1alpha1.ObjectMeta{Name: "prompts", Project: "web"}
+	if err := st.Create(store.ResourceKey(v1alpha1.KindSecret, "web", "gh"), sec); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Create(store.ResourceKey(v1alpha1.KindConfigMap, "web", "prompts"), cm); err != nil {
+		t.Fatal(err)
+	}
+
+	pod := &v1alpha1.AgentPod{Metadata: v1alpha1.ObjectMeta{Name: "a", Project: "web"}}
+	pod.Spec.EnvFrom = []v1alpha1.EnvFromSource{{Prefix: "CFG_", ConfigMapRef: &v1alpha1.ConfigMapReference{Name: "prompts"}}}
+	pod.Spec.Env = []v1alpha1.EnvVar{{Name: "LEVEL", ValueFrom: &v1alpha1.EnvVarSource{
+		ConfigMapKeyRef: &v1alpha1.ConfigMapKeySelector{Name: "prompts", Key: "LEVEL"},
+	}}}
+	pod.Spec.Volumes = []v1alpha1.Volume{
+		{Name: "prompts", ConfigMap: &v1alpha1.ConfigMapReference{Name: "prompts"}},
+		{Name: "creds", Secret: &v1alpha1.SecretReference{Name: "gh"}},
+	}
+
+	env, err := r.resolveEnv(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"CFG_LEVEL=strict", "CFG_review.md=Be thorough.", "LEVEL=strict"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("env %q lacks %q", env, want)
+		}
+	}
+
+	dirs, err := r.mountVolumes(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := r.volumesRoot("web", "a")
+	if dirs["creds"] != filepath.Join(root, "creds") || dirs["prompts"] != filepath.Join(root, "prompts") {
+		t.Fatalf("dirs = %v", dirs)
+	}
+	data, err := os.ReadFile(filepath.Join(dirs["creds"], "token"))
+	if err != nil || string(data) != "s3cret" {
+		t.Errorf("token = %q, %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(dirs["creds"], "token")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("secret file mode = %v, %v", info, err)
+	}
+	if prompt := volumesPrompt(dirs); !strings.Contains(prompt, "- prompts: "+dirs["prompts"]) {
+		t.Errorf("prompt = %q", prompt)
+	}
+
+	// Keys removed from the ConfigMap disappear from the volume.
+	cm.Data = map[string]string{"review.md": "Be brief."}
+	if err := st.Update(store.ResourceKey(v1alpha1.KindConfigMap, "web", "prompts"), cm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.mountVolumes(pod); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(dirs["prompts"])
+	if len(entries) != 1 || entries[0].Name() != "review.md" {
+		t.Errorf("prompts volume holds %v", entries)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dirs["prompts"], "review.md")); string(data) != "Be brief." {
+		t.Errorf("review.md = %q", data)
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, v1alpha1.Volume{Name: "missing", ConfigMap: &v1alpha1.ConfigMapReference{Name: "nope"}})
+	if _, err := r.mountVolumes(pod); err == nil || !strings.Contains(err.Error(), `configmap "nope" not found`) {
+		t.Errorf("missing configmap: got %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// cliProcess is a started claude CLI process that is waiting for its prompt
+// on stdin. Starting the process ahead of time hides the CLI's cold-start
+// cost from the task that eventually uses it.
+type cliProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout lineBuffer
+	stderr bytes.Buffer
+	warm   bool
+
+	exited  chan struct{} // closed once the process has exited
+	exitErr error
+}
+
+// spawn starts a CLI process for req without sending the prompt.
+func (e *Executor) spawn(req ExecutionRequest) (*cliProcess, error) {
+	cmd := exec.Command(e.cliBin, buildArgs(req)...)
+
+	// Unset CLAUDECODE env var to allow nested invocation.
+	cmd.Env = append(filterEnv(os.Environ(), "CLAUDECODE"), req.Env...)
+
+	p := &cliProcess{cmd: cmd, exited: make(chan struct{})}
+	cmd.Stdout = &p.stdout
+	cmd.Stderr = &p.stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	p.stdin = stdin
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		p.exitErr = cmd.Wait()
+		close(p.exited)
+	}()
+	return p, nil
+}
+
+// run delivers the prompt and waits for the process to exit. The process is
+// killed if ctx is cancelled first. onLine, when non-nil, is called with each
+// line of output as the process writes it.
+func (p *cliProcess) run(ctx context.Context, prompt string, onLine func([]byte)) (*bytes.Buffer, *bytes.Buffer, error) {
+	p.stdout.setOnLine(onLine)
+	go func() {
+		// A write error means the process already exited; the exit status
+		// reported below carries the real cause.
+		_, _ = io.WriteString(p.stdin, prompt)
+		p.stdin.Close()
+	}()
+
+	select {
+	case <-p.exited:
+		return &p.stdout.buf, &p.stderr, p.exitErr
+	case <-ctx.Done():
+		p.kill()
+		return &p.stdout.buf, &p.stderr, ctx.Err()
+	}
+}
+
+// lineBuffer collects a process's output and hands each complete line to
+// onLine as it arrives. Lines completed before onLine is set are delivered
+// when it is set.
+type lineBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	next   int // offset of the first line not yet delivered
+	onLine func([]byte)
+}
+
+func (b *lineBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n, err := b.buf.Write(data)
+	b.deliver()
+	return n, err
+}
+
+func (b *lineBuffer) setOnLine(fn func([]byte)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onLine = fn
+	b.deliver()
+}
+
+// deliver passes complete, undelivered lines to onLine. b.mu must be held.
+func (b *lineBuffer) deliver() {
+	if b.onLine == nil {
+		return
+	}
+	data := b.buf.Bytes()
+	for {
+		i := bytes.IndexByte(data[b.next:], '\n')
+		if i < 0 {
+			return
+		}
+		b.onLine(data[b.next : b.next+i])
+		b.next += i + 1
+	}
+}
+
+// alive reports whether the process is still running.
+func (p *cliProcess) alive() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// kill terminates the process and waits for it to be reaped.
+func (p *cliProcess) kill() {
+	if p.cmd.Process != nil {
+		_ = p.cmd.Process.Kill()
+	}
+	p.stdin.Close()
+	<-p.exited
+}
+
+// warmPool keeps a fixed number of idle processes for one request shape.
+type warmPool struct {
+	req   ExecutionRequest
+	sig   string
+	size  int
+	procs []*cliProcess
+}
+
+// warmSignature identifies requests that can share a pre-started process:
+// everything that ends up on the command line or in the environment.
+func warmSignature(req ExecutionRequest) string {
+	return strings.Join(buildArgs(req), "\x00") + "\x01" + strings.Join(req.Env, "\x00")
+}
+
+// Warm keeps n pre-started processes ready under key for requests shaped like
+// req. Calling Warm again with a different request shape replaces the pool.
+func (e *Executor) Warm(key string, req ExecutionRequest, n int) {
+	req.Prompt = ""
+	req.WarmKey = key
+	sig := warmSignature(req)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pool, ok := e.warm[key]
+	if ok && pool.sig != sig {
+		for _, p := range pool.procs {
+			go p.kill()
+		}
+		ok = false
+	}
+	if !ok {
+		pool = &warmPool{req: req, sig: sig}
+		e.warm[key] = pool
+	}
+	pool.size = n
+	e.fillLocked(key, pool)
+}
+
+// Drain stops every idle process kept under key.
+func (e *Executor) Drain(key string) {
+	e.mu.Lock()
+	pool, ok := e.warm[key]
+	delete(e.warm, key)
+	e.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	for _, p := range pool.procs {
+		p.kill()
+	}
+}
+
+// Close stops all warm processes.
+func (e *Executor) Close() {
+	e.mu.Lock()
+	keys := make([]string, 0, len(e.warm))
+	for k := range e.warm {
+		keys = append(keys, k)
+	}
+	e.mu.Unlock()
+
+	for _, k := range keys {
+		e.Drain(k)
+	}
+}
+
+// takeWarm pops a live pre-started process matching req, if any, and starts a
+// replacement in the background.
+func (e *Executor) takeWarm(req ExecutionRequest) *cliProcess {
+	if req.WarmKey == "" {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pool, ok := e.warm[req.WarmKey]
+	if !ok {
+		return nil
+	}
+	probe := req
+	probe.Prompt = ""
+	if warmSignature(probe) != pool.sig {
+		return nil
+	}
+
+	for len(pool.procs) > 0 {
+		p := pool.procs[0]
+		pool.procs = pool.procs[1:]
+		if p.alive() {
+			p.warm = true
+			go func() {
+				e.mu.Lock()
+				defer e.mu.Unlock()
+				if e.warm[req.WarmKey] == pool {
+					e.fillLocked(req.WarmKey, pool)
+				}
+			}()
+			return p
+		}
+	}
+	e.fillLocked(req.WarmKey, pool)
+	return nil
+}
+
+// fillLocked tops up pool to its configured size. e.mu must be held.
+func (e *Executor) fillLocked(key string, pool *warmPool) {
+	live := pool.procs[:0]
+	for _, p := range pool.procs {
+		if p.alive() {
+			live = append(live, p)
+		}
+	}
+	pool.procs = live
+
+	for len(pool.procs) < pool.size {
+		p, err := e.spawn(pool.req)
+		if err != nil {
+			e.logger.Warn("failed to pre-start claude CLI session",
+				zap.String("key", key),
+				zap.Error(err),
+			)
+			return
+		}
+		pool.procs = append(pool.procs, p)
+	}
+	for len(pool.procs) > pool.size {
+		last := pool.procs[len(pool.procs)-1]
+		pool.procs = pool.procs[:len(pool.procs)-1]
+		go last.kill()
+	}
+}
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// stubExecutor returns an executor whose CLI waits for its prompt and then
+// reports its process ID as the result.
+func stubExecutor(t *testing.T) *Executor {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\ncat >/dev/null\necho '{\"type\":\"result\",\"subtype\":\"success\",\"result\":\"'$$'\"}'\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	e := NewExecutor(bin, zap.NewNop())
+	t.Cleanup(e.Close)
+	return e
+}
+
+// warmProcs returns the idle processes kept under key.
+func warmProcs(e *Executor, key string) []*cliProcess {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	pool, ok := e.warm[key]
+	if !ok {
+		return nil
+	}
+	return append([]*cliProcess(nil), pool.procs...)
+}
+
+// awaitExit fails the test unless p exits within a few seconds.
+func awaitExit(t *testing.T, p *cliProcess) {
+	t.Helper()
+	select {
+	case <-p.exited:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("process %d still running", p.cmd.Process.Pid)
+	}
+}
+
+func TestWarmTakeAndRefill(t *testing.T) {
+	e := stubExecutor(t)
+	req := ExecutionRequest{Model: "claude-sonnet"}
+	e.Warm("pod", req, 2)
+	procs := warmProcs(e, "pod")
+	if len(procs) != 2 {
+		t.Fatalf("%d warm processes, want 2", len(procs))
+	}
+
+	req.Prompt = "fix it"
+	req.WarmKey = "pod"
+	res, err := e.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strconv.Itoa(procs[0].cmd.Process.Pid); res.Output != want {
+		t.Errorf("request served by process %s, want the warm process %s", res.Output, want)
+	}
+
+	// The process taken is replaced in the background.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		refilled := warmProcs(e, "pod")
+		if len(refilled) == 2 && refilled[0] == procs[1] && refilled[1] != procs[0] {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("warm processes after a take: %d, want 2 with a replacement", len(refilled))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A request of another shape does not take a warm process.
+	other := req
+	other.Model = "claude-opus"
+	if p := e.takeWarm(other); p != nil {
+		t.Error("a request of another shape took a warm process")
+	}
+}
+
+func TestWarmSignatureChangeReplacesProcesses(t *testing.T) {
+	e := stubExecutor(t)
+	e.Warm("pod", ExecutionRequest{Model: "claude-sonnet"}, 2)
+	stale := warmProcs(e, "pod")
+
+	e.Warm("pod", ExecutionRequest{Model: "claude-opus"}, 1)
+	for _, p := range stale {
+		awaitExit(t, p)
+	}
+	procs := warmProcs(e, "pod")
+	if len(procs) != 1 || procs[0] == stale[0] || procs[0] == stale[1] || !procs[0].alive() {
+		t.Fatalf("warm processes after a signature change: %v, want one new live process", procs)
+	}
+
+	if p := e.takeWarm(ExecutionRequest{Model: "claude-sonnet", WarmKey: "pod"}); p != nil {
+		t.Error("a request of the old shape took a warm process")
+	}
+}
+
+func TestDrainAndCloseStopWarmProcesses(t *testing.T) {
+	e := stubExecutor(t)
+	e.Warm("a", ExecutionRequest{Model: "claude-sonnet"}, 2)
+	e.Warm("b", ExecutionRequest{Model: "claude-sonnet"}, 1)
+	a, b := warmProcs(e, "a"), warmProcs(e, "b")
+
+	e.Drain("a")
+	for _, p := range a {
+		if p.alive() {
+			t.Errorf("process %d still running after Drain", p.cmd.Process.Pid)
+		}
+	}
+	if len(warmProcs(e, "b")) != 1 {
+		t.Error("Drain stopped the processes of another key")
+	}
+
+	e.Close()
+	for _, p := range b {
+		if p.alive() {
+			t.Errorf("process %d still running after Close", p.cmd.Process.Pid)
+		}
+	}
+	if warmProcs(e, "a") != nil || warmProcs(e, "b") != nil {
+		t.Error("warm processes kept after Close")
+	}
+}
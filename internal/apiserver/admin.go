@@ -0,0 +1,191 @@
+package apiserver
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/manifest"
+)
+
+// backupStoreDir is the directory of a backup tarball holding the store's
+// entries, one JSON file per key: the key "/AgentPod/default/worker" is kept
+// as "store/AgentPod/default/worker.json".
+const backupStoreDir = "store"
+
+// handleBackup writes a snapshot of the store, but for the audit log, as a
+// gzipped tarball.
+// With ?resourcesOnly=true it writes a YAML bundle of the resources users
+// declare instead (projects, secrets, pools, standalone pods and autoscalers), stripped of
+// the fields the server populates so that it can be applied to any server.
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	if ro := r.URL.Query().Get("resourcesOnly"); ro != "" && ro != "false" {
+		s.handleBackupResources(w, r)
+		return
+	}
+
+	data, err := s.storeFor(r).Snapshot()
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="orca-backup.tar.gz"`)
+	w.WriteHeader(http.StatusOK)
+
+	// The status is sent; a failure from here on can only be logged.
+	now := time.Now()
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, key := range keys {
+		hdr := &tar.Header{
+			Name:    backupStoreDir + key + ".json",
+			Mode:    0o600,
+			Size:    int64(len(data[key])),
+			ModTime: now,
+		}
+		if err = tw.WriteHeader(hdr); err == nil {
+			_, err = tw.Write(data[key])
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		s.logger.Error("writing backup", zap.Error(err))
+	}
+}
+
+// handleBackupResources writes the YAML bundle of handleBackup's
+// resourcesOnly mode.
+func (s *Server) handleBackupResources(w http.ResponseWriter, r *http.Request) {
+	kinds := []struct {
+		kind    string
+		factory func() interface{}
+	}{
+		{v1alpha1.KindProject, func() interface{} { return &v1alpha1.Project{} }},
+		{v1alpha1.KindSecret, func() interface{} { return &v1alpha1.Secret{} }},
+		{v1alpha1.KindConfigMap, func() interface{} { return &v1alpha1.ConfigMap{} }},
+		{v1alpha1.KindModelProvider, func() interface{} { return &v1alpha1.ModelProvider{} }},
+		{v1alpha1.KindToolDefinition, func() interface{} { return &v1alpha1.ToolDefinition{} }},
+		{v1alpha1.KindAgentPool, func() interface{} { return &v1alpha1.AgentPool{} }},
+		{v1alpha1.KindAgentPod, func() interface{} { return &v1alpha1.AgentPod{} }},
+		{v1alpha1.KindAgentPoolAutoscaler, func() interface{} { return &v1alpha1.AgentPoolAutoscaler{} }},
+	}
+
+	var docs []interface{}
+	for _, k := range kinds {
+		items, err := s.storeFor(r).List("/"+k.kind+"/", k.factory)
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		var kept []interface{}
+		for _, item := range items {
+			// A pool's pods are recreated from the pool.
+			if pod, ok := item.(*v1alpha1.AgentPod); ok && pod.OwnerPool() != "" {
+				continue
+			}
+			kept = append(kept, item)
+		}
+		sort.Slice(kept, func(i, j int) bool {
+			return resourceSortKey(kept[i]) < resourceSortKey(kept[j])
+		})
+		docs = append(docs, kept...)
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	if err := manifest.Marshal(docs, w, manifest.OmitServerFields()); err != nil {
+		s.logger.Error("writing resource bundle", zap.Error(err))
+	}
+}
+
+// resourceSortKey orders resources by project, then name.
+func resourceSortKey(resource interface{}) string {
+	var r struct {
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+	}
+	raw, _ := json.Marshal(resource)
+	_ = json.Unmarshal(raw, &r)
+	return r.Metadata.Project + "/" + r.Metadata.Name
+}
+
+// handleRestore replaces the store, but for the audit log, with the
+// contents of a tarball written by handleBackup.
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request) {
+	data, err := readBackup(r.Body)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.storeFor(r).Restore(data); err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.logger.Info("store restored from backup", zap.Int("keys", len(data)))
+	s.writeJSON(w, http.StatusOK, map[string]int{"restored": len(data)})
+}
+
+// readBackup reads the store entries of a backup tarball.
+func readBackup(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	defer gz.Close()
+
+	data := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading backup: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		key, ok := strings.CutPrefix(hdr.Name, backupStoreDir+"/")
+		if !ok || !strings.HasSuffix(key, ".json") {
+			return nil, fmt.Errorf("unexpected file %q in backup", hdr.Name)
+		}
+		key = "/" + strings.TrimSuffix(key, ".json")
+
+		raw, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading backup: %w", err)
+		}
+		if !json.Valid(raw) {
+			return nil, fmt.Errorf("%s: invalid JSON", hdr.Name)
+		}
+		data[key] = raw
+	}
+	return data, nil
+}
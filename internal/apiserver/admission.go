@@ -0,0 +1,67 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/klubi/orca/internal/policy"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/validation"
+)
+
+// admit fills the fields obj, a project-scoped resource being created or
+// updated, leaves empty with the defaults of its project and then with the
+// server's, and checks it against the schema of its kind and the project's
+// policy. It writes the error response and returns false if obj cannot be
+// admitted.
+func (s *Server) admit(w http.ResponseWriter, r *http.Request, obj v1alpha1.Object) bool {
+	meta := obj.GetObjectMeta()
+	if meta.Project == "" {
+		meta.Project = s.defaults.Project
+	}
+	p, err := policy.Project(s.storeFor(r), meta.Project)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+
+	policy.ApplyDefaults(p, obj)
+	s.defaults.Apply(obj)
+	if !s.validate(w, obj) {
+		return false
+	}
+	if err := policy.Check(p, obj); err != nil {
+		s.writeError(w, http.StatusForbidden, err.Error())
+		return false
+	}
+	return true
+}
+
+// validate checks obj against the schema of its kind. It writes a 422
+// response listing the violations and returns false if obj is invalid.
+func (s *Server) validate(w http.ResponseWriter, obj interface{}) bool {
+	if err := validation.Validate(obj); err != nil {
+		s.writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return false
+	}
+	return true
+}
+
+// admitNewTask checks that the project of a task being created has budget
+// left. It writes the error response and returns false if not.
+func (s *Server) admitNewTask(w http.ResponseWriter, r *http.Request, task *v1alpha1.DevTask) bool {
+	p, err := policy.Project(s.storeFor(r), task.Metadata.Project)
+	if err == nil {
+		err = policy.CheckBudget(s.storeFor(r), p)
+	}
+	var violation *policy.Violation
+	switch {
+	case errors.As(err, &violation):
+		s.writeError(w, http.StatusForbidden, err.Error())
+		return false
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	return true
+}
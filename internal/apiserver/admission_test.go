@@ -0,0 +1,74 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestUpdatesFollowProjectPolicy(t *testing.T) {
+	st := store.NewMemoryStore()
+	project := &v1alpha1.Project{
+		Metadata: v1alpha1.ObjectMeta{Name: "web"},
+		Spec: v1alpha1.ProjectSpec{
+			Defaults: v1alpha1.ProjectDefaults{Labels: map[string]string{"team": "web"}},
+			Policy:   v1alpha1.ProjectPolicy{AllowedModels: []string{"claude-sonnet-4-20250514"}},
+		},
+	}
+	if err := st.Create(store.ResourceKey(v1alpha1.KindProject, "", "web"), project); err != nil {
+		t.Fatal(err)
+	}
+	key := store.ResourceKey(v1alpha1.KindAgentPod, "web", "coder")
+	pod := &v1alpha1.AgentPod{
+		Metadata: v1alpha1.ObjectMeta{Name: "coder", Project: "web"},
+		Spec:     v1alpha1.AgentPodSpec{Model: "claude-sonnet-4-20250514"},
+	}
+	if err := st.Create(key, pod); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer("", st, nil, zap.NewNop())
+
+	serve := func(method, contentType, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/v1alpha1/agentpods/coder?project=web", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(http.MethodPut, "", `{"metadata":{"name":"coder"},"spec":{"model":"claude-opus-4-20250514"}}`); rec.Code != http.StatusForbidden {
+		t.Errorf("PUT of a disallowed model: %d %s, want 403", rec.Code, rec.Body)
+	}
+	if rec := serve(http.MethodPatch, mergePatchType, `{"spec":{"model":"claude-opus-4-20250514"}}`); rec.Code != http.StatusForbidden {
+		t.Errorf("merge PATCH of a disallowed model: %d %s, want 403", rec.Code, rec.Body)
+	}
+	if rec := serve(http.MethodPatch, jsonPatchType, `[{"op":"replace","path":"/spec/model","value":"claude-opus-4-20250514"}]`); rec.Code != http.StatusForbidden {
+		t.Errorf("JSON PATCH of a disallowed model: %d %s, want 403", rec.Code, rec.Body)
+	}
+	var got v1alpha1.AgentPod
+	if err := st.Get(key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.Model != "claude-sonnet-4-20250514" {
+		t.Errorf("model after rejected updates = %q, want claude-sonnet-4-20250514", got.Spec.Model)
+	}
+
+	if rec := serve(http.MethodPatch, mergePatchType, `{"spec":{"maxTokens":1024}}`); rec.Code != http.StatusOK {
+		t.Fatalf("merge PATCH: %d %s, want 200", rec.Code, rec.Body)
+	}
+	if err := st.Get(key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Metadata.Labels["team"] != "web" {
+		t.Errorf("labels after PATCH = %v, want the project's default team=web", got.Metadata.Labels)
+	}
+}
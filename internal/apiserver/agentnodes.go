@@ -0,0 +1,137 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// ---------------------------------------------------------------------------
+// AgentNodes
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleCreateAgentNode(w http.ResponseWriter, r *http.Request) {
+	var n v1alpha1.AgentNode
+	if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	n.APIVersion = v1alpha1.APIVersion
+	n.Kind = v1alpha1.KindAgentNode
+	n.Metadata.Project = ""
+	n.Metadata.UID = uuid.New().String()
+	now := time.Now()
+	n.Metadata.CreatedAt = now
+	n.Metadata.UpdatedAt = now
+	if n.Status.Phase == "" {
+		n.Status.Phase = v1alpha1.NodeNotReady
+	}
+
+	key := store.ResourceKey(v1alpha1.KindAgentNode, "", n.Metadata.Name)
+	if err := s.storeFor(r).Create(key, &n); err != nil {
+		if err == store.ErrAlreadyExists {
+			s.writeError(w, http.StatusConflict, "agentnode already exists")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, &n)
+}
+
+func (s *Server) handleGetAgentNode(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	key := store.ResourceKey(v1alpha1.KindAgentNode, "", name)
+
+	var n v1alpha1.AgentNode
+	if err := s.storeFor(r).Get(key, &n); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "agentnode not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &n)
+}
+
+func (s *Server) handleListAgentNodes(w http.ResponseWriter, r *http.Request) {
+	q, ok := s.listQuery(w, r)
+	if !ok {
+		return
+	}
+
+	prefix := "/" + v1alpha1.KindAgentNode + "/"
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.AgentNode{} })
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeList(s, w, q, items, func(obj *v1alpha1.AgentNode) *v1alpha1.ObjectMeta { return &obj.Metadata })
+}
+
+// handleUpdateAgentNode replaces a node's spec. Its status belongs to the
+// node's worker, which writes it through the status subresource.
+func (s *Server) handleUpdateAgentNode(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	key := store.ResourceKey(v1alpha1.KindAgentNode, "", name)
+
+	var existing v1alpha1.AgentNode
+	if err := s.storeFor(r).Get(key, &existing); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "agentnode not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var n v1alpha1.AgentNode
+	if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Preserve immutable fields
+	n.APIVersion = v1alpha1.APIVersion
+	n.Kind = v1alpha1.KindAgentNode
+	n.Metadata.Name = name
+	n.Metadata.Project = ""
+	n.Metadata.UID = existing.Metadata.UID
+	n.Metadata.CreatedAt = existing.Metadata.CreatedAt
+	n.Metadata.UpdatedAt = time.Now()
+	n.Status = existing.Status
+
+	if err := s.storeFor(r).UpdateIfVersion(key, &n, n.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &n)
+}
+
+func (s *Server) handleDeleteAgentNode(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	key := store.ResourceKey(v1alpha1.KindAgentNode, "", name)
+
+	if err := s.storeFor(r).Delete(key); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "agentnode not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,241 @@
+package apiserver
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/audit"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// maxAuditResponse is how much of a response the audit middleware keeps to
+// find the object a create or apply request made.
+const maxAuditResponse = 1 << 20
+
+// SetAuditLog makes the server record its mutating requests in l. With a
+// nil l they are not recorded.
+func (s *Server) SetAuditLog(l audit.Log) {
+	s.audit = l
+}
+
+// auditRequests is middleware that records every mutating API request in
+// the server's audit log, with the change it made to the object it
+// addressed.
+func (s *Server) auditRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.audit == nil || r.Method == http.MethodGet || r.Method == http.MethodHead ||
+			!strings.HasPrefix(r.URL.Path, apiPrefix+"/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := auditEntry(r)
+		before := s.auditSnapshot(r, entry.Object)
+
+		rec := &auditRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		entry.StatusCode = rec.status
+
+		if rec.status < 300 {
+			if entry.Object.Name == "" && (entry.Verb == "create" || entry.Verb == "apply") {
+				entry.Object = createdObject(rec.body.Bytes(), entry.Object)
+			}
+			if entry.Verb != "delete" && entry.Verb != "deletecollection" {
+				after := s.auditSnapshot(r, entry.Object)
+				if d := mergeDiff(before, after); len(d) > 0 {
+					entry.Diff = d
+				}
+			}
+		}
+		if err := s.audit.Record(entry); err != nil {
+			s.logger.Warn("cannot record audit entry", zap.String("path", entry.Path), zap.Error(err))
+		}
+	})
+}
+
+// auditEntry describes the request r, before it is served.
+func auditEntry(r *http.Request) *v1alpha1.AuditEntry {
+	entry := &v1alpha1.AuditEntry{
+		User:      requestUser(r.Context()),
+		RequestID: requestID(r.Context()),
+		Method:    r.Method,
+		Path:      r.URL.RequestURI(),
+		Timestamp: time.Now(),
+	}
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, apiPrefix+"/"), "/")
+	name := mux.Vars(r)["name"]
+	if kind, ok := resourceKinds[segments[0]]; ok {
+		entry.Object = v1alpha1.ObjectReference{Kind: kind, Name: name}
+		if !v1alpha1.ClusterScoped(kind) {
+			entry.Object.Project = r.URL.Query().Get("project")
+		}
+	}
+	if len(segments) > 2 {
+		entry.Subresource = segments[2]
+	}
+
+	switch {
+	case segments[0] == "apply":
+		entry.Verb = "apply"
+	case r.Method == http.MethodPost:
+		entry.Verb = "create"
+	case r.Method == http.MethodPut:
+		entry.Verb = "update"
+	case r.Method == http.MethodPatch:
+		entry.Verb = "patch"
+	case r.Method == http.MethodDelete && name == "" && entry.Object.Kind != "":
+		entry.Verb = "deletecollection"
+	case r.Method == http.MethodDelete:
+		entry.Verb = "delete"
+	default:
+		entry.Verb = strings.ToLower(r.Method)
+	}
+	return entry
+}
+
+// createdObject returns the object a create or apply request answered with
+// in body, or ref if body is not one.
+func createdObject(body []byte, ref v1alpha1.ObjectReference) v1alpha1.ObjectReference {
+	var head struct {
+		Kind     string              `json:"kind"`
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+	}
+	if err := json.Unmarshal(body, &head); err != nil || head.Kind == "" || head.Metadata.Name == "" {
+		return ref
+	}
+	return v1alpha1.ObjectReference{Kind: head.Kind, Name: head.Metadata.Name, Project: head.Metadata.Project}
+}
+
+// auditSnapshot returns the stored object ref refers to as a JSON document,
+// or nil if there is none. Secret values are replaced by their HMAC under
+// the server's audit key, which tells whether a value changed without
+// letting readers of the log guess it.
+func (s *Server) auditSnapshot(r *http.Request, ref v1alpha1.ObjectReference) map[string]interface{} {
+	if ref.Kind == "" || ref.Name == "" {
+		return nil
+	}
+	var doc map[string]interface{}
+	if err := s.storeFor(r).Get(store.ResourceKey(ref.Kind, ref.Project, ref.Name), &doc); err != nil {
+		return nil
+	}
+	if ref.Kind == v1alpha1.KindSecret {
+		for _, field := range []string{"data", "stringData"} {
+			values, _ := doc[field].(map[string]interface{})
+			for k, v := range values {
+				mac := hmac.New(sha256.New, s.auditKey)
+				mac.Write([]byte(fmt.Sprint(v)))
+				values[k] = "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)[:8])
+			}
+		}
+	}
+	return doc
+}
+
+// mergeDiff returns the JSON merge patch (RFC 7386) that turns before into
+// after: after itself if before is nil.
+func mergeDiff(before, after map[string]interface{}) map[string]interface{} {
+	if before == nil {
+		return after
+	}
+	diff := map[string]interface{}{}
+	for k, b := range before {
+		a, ok := after[k]
+		if !ok {
+			diff[k] = nil
+			continue
+		}
+		bm, bok := b.(map[string]interface{})
+		am, aok := a.(map[string]interface{})
+		if bok && aok {
+			if d := mergeDiff(bm, am); len(d) > 0 {
+				diff[k] = d
+			}
+			continue
+		}
+		if !reflect.DeepEqual(a, b) {
+			diff[k] = a
+		}
+	}
+	for k, a := range after {
+		if _, ok := before[k]; !ok {
+			diff[k] = a
+		}
+	}
+	return diff
+}
+
+// auditRecorder remembers the status code and the start of the body a
+// handler wrote.
+type auditRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *auditRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *auditRecorder) Write(p []byte) (int, error) {
+	if room := maxAuditResponse - r.body.Len(); room > 0 {
+		r.body.Write(p[:min(len(p), room)])
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *auditRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// handleListAudit returns the audit entries oldest first. ?user=, ?kind=,
+// ?project= and ?name= select entries; ?since= drops those older than a
+// duration such as 1h or an RFC 3339 time; ?limit= keeps the newest.
+func (s *Server) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	if s.audit == nil {
+		s.writeError(w, http.StatusNotFound, "auditing is off; set audit.backend in the server's config")
+		return
+	}
+	q := r.URL.Query()
+	f := audit.Filter{User: q.Get("user"), Kind: q.Get("kind"), Project: q.Get("project"), Name: q.Get("name")}
+	if since := q.Get("since"); since != "" {
+		if d, err := time.ParseDuration(since); err == nil {
+			f.Since = time.Now().Add(-d)
+		} else if f.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("since: want a duration or an RFC 3339 time, got %q", since))
+			return
+		}
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("limit: want a non-negative integer, got %q", limit))
+			return
+		}
+		f.Limit = n
+	}
+
+	entries, err := s.audit.List(f)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []v1alpha1.AuditEntry{}
+	}
+	s.writeJSON(w, http.StatusOK, entries)
+}
@@ -0,0 +1,75 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/audit"
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestAuditRequests(t *testing.T) {
+	st := store.NewMemoryStore()
+	log, err := audit.New(config.AuditConfig{Backend: "store"}, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := NewAuthenticator(config.AuthConfig{Tokens: []config.TokenConfig{{Name: "ci", Token: "t"}}}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer("", st, nil, zap.NewNop())
+	s.SetAuthenticator(auth)
+	s.SetAuditLog(log)
+
+	do := func(method, path, contentType, body string) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/v1alpha1/"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer t")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		if rec.Code >= 300 {
+			t.Fatalf("%s %s: %d %s", method, path, rec.Code, rec.Body)
+		}
+	}
+	do(http.MethodPost, "secrets?project=web", "", `{"metadata":{"name":"gh"},"stringData":{"token":"abc"}}`)
+	do(http.MethodPatch, "secrets/gh?project=web", mergePatchType, `{"metadata":{"labels":{"team":"infra"}}}`)
+	do(http.MethodGet, "secrets/gh?project=web", "", "")
+	do(http.MethodDelete, "secrets/gh?project=web", "", "")
+
+	entries, err := log.List(audit.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("%d entries recorded, want 3 (reads are not audited): %+v", len(entries), entries)
+	}
+	for i, verb := range []string{"create", "patch", "delete"} {
+		e := entries[i]
+		want := v1alpha1.ObjectReference{Kind: v1alpha1.KindSecret, Name: "gh", Project: "web"}
+		if e.Verb != verb || e.User != "ci" || e.Object != want {
+			t.Errorf("entry %d = %s by %s on %+v, want %s by ci on %+v", i, e.Verb, e.User, e.Object, verb, want)
+		}
+	}
+
+	data, _ := entries[0].Diff["data"].(map[string]interface{})
+	if token, _ := data["token"].(string); !strings.HasPrefix(token, "hmac-sha256:") {
+		t.Errorf("created secret's token in the diff = %q, want its HMAC", token)
+	}
+	meta, _ := entries[1].Diff["metadata"].(map[string]interface{})
+	if labels, _ := meta["labels"].(map[string]interface{}); labels["team"] != "infra" || entries[1].Diff["data"] != nil {
+		t.Errorf("patch diff = %v, want only the new label and metadata", entries[1].Diff)
+	}
+	if entries[2].Diff != nil {
+		t.Errorf("delete diff = %v, want none", entries[2].Diff)
+	}
+}
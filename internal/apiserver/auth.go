@@ -0,0 +1,208 @@
+package apiserver
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/config"
+)
+
+// tokenFileRecheck is how often the authenticator checks its token file
+// for changes, at most.
+const tokenFileRecheck = 2 * time.Second
+
+// credential is a bearer token and the name of the user it is issued to.
+type credential struct {
+	token string
+	user  string
+}
+
+// Authenticator checks the bearer tokens of API requests against the
+// tokens of the server's auth config.
+type Authenticator struct {
+	static []credential
+	file   string
+	logger *zap.Logger
+
+	mu          sync.Mutex
+	fromFile    []credential
+	fileMod     time.Time
+	fileSize    int64
+	lastRecheck time.Time
+}
+
+// NewAuthenticator returns an Authenticator for the tokens of cfg, reading
+// its token file if it has one.
+func NewAuthenticator(cfg config.AuthConfig, logger *zap.Logger) (*Authenticator, error) {
+	a := &Authenticator{file: cfg.TokenFile, logger: logger}
+	for i, t := range cfg.Tokens {
+		user := t.Name
+		if user == "" {
+			user = fmt.Sprintf("server.auth.tokens[%d]", i)
+		}
+		a.static = append(a.static, credential{token: t.Token, user: user})
+	}
+	if a.file != "" {
+		info, err := os.Stat(a.file)
+		if err != nil {
+			return nil, fmt.Errorf("reading token file: %w", err)
+		}
+		if a.fromFile, err = readTokenFile(a.file); err != nil {
+			return nil, err
+		}
+		a.fileMod, a.fileSize = info.ModTime(), info.Size()
+		a.lastRecheck = time.Now()
+	}
+	return a, nil
+}
+
+// Authenticate returns the user whose token r carries, or false if it
+// carries none or an unknown one.
+func (a *Authenticator) Authenticate(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", false
+	}
+
+	// Every token is compared, in constant time, so that the response time
+	// does not tell how much of a token was right.
+	user, found := "", false
+	for _, c := range slices.Concat(a.static, a.tokensFromFile()) {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) == 1 && !found {
+			user, found = c.user, true
+		}
+	}
+	return user, found
+}
+
+// tokensFromFile returns the tokens of the token file, re-reading it if it
+// changed. A file that cannot be read keeps its last tokens.
+func (a *Authenticator) tokensFromFile() []credential {
+	if a.file == "" {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if time.Since(a.lastRecheck) < tokenFileRecheck {
+		return a.fromFile
+	}
+	a.lastRecheck = time.Now()
+
+	info, err := os.Stat(a.file)
+	if err != nil {
+		a.logger.Warn("cannot check token file; keeping its last tokens", zap.String("path", a.file), zap.Error(err))
+		return a.fromFile
+	}
+	if info.ModTime().Equal(a.fileMod) && info.Size() == a.fileSize {
+		return a.fromFile
+	}
+	creds, err := readTokenFile(a.file)
+	if err != nil {
+		a.logger.Warn("cannot re-read token file; keeping its last tokens", zap.String("path", a.file), zap.Error(err))
+		return a.fromFile
+	}
+	a.fromFile, a.fileMod, a.fileSize = creds, info.ModTime(), info.Size()
+	a.logger.Info("token file reloaded", zap.String("path", a.file), zap.Int("tokens", len(creds)))
+	return a.fromFile
+}
+
+// readTokenFile parses a token file: one token per line, optionally
+// followed by the name of its user. Tokens without one are named after
+// their line.
+func readTokenFile(path string) ([]credential, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading token file: %w", err)
+	}
+	defer f.Close()
+
+	var creds []credential
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		c := credential{token: fields[0], user: fmt.Sprintf("%s:%d", filepath.Base(path), line)}
+		if len(fields) > 1 {
+			c.user = strings.Join(fields[1:], " ")
+		}
+		creds = append(creds, c)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading token file %s: %w", path, err)
+	}
+	return creds, nil
+}
+
+// unauthenticatedPaths are served without a token so that probes and
+// load balancers can check the server.
+var unauthenticatedPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+type userKey struct{}
+
+// requestUser returns the name of the user who made the request with ctx,
+// or "anonymous" if the server does not authenticate requests.
+func requestUser(ctx context.Context) string {
+	if user, ok := ctx.Value(userKey{}).(string); ok {
+		return user
+	}
+	return "anonymous"
+}
+
+// SetAuthenticator makes the server reject requests that carry no token a
+// accepts. With a nil a the server accepts anonymous requests.
+func (s *Server) SetAuthenticator(a *Authenticator) {
+	s.auth = a
+}
+
+// authenticate is middleware that rejects requests without a valid bearer
+// token, when the server has an authenticator, or without a verified client
+// certificate, when it requires one, with 401 Unauthorized.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unauthenticatedPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.requireClientCert && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+			s.writeError(w, http.StatusUnauthorized, "a client certificate is required")
+			return
+		}
+		if s.auth == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, ok := s.auth.Authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="orca"`)
+			s.writeError(w, http.StatusUnauthorized, "a valid bearer token is required")
+			return
+		}
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("enduser.id", user))
+		if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+			info.user = user
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
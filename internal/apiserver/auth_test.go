@@ -0,0 +1,75 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+)
+
+func TestAuthenticate(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(tokenFile, []byte("# ops\nfile-token alice\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	auth, err := NewAuthenticator(config.AuthConfig{
+		Tokens:    []config.TokenConfig{{Name: "ci", Token: "static-token"}},
+		TokenFile: tokenFile,
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer("", store.NewMemoryStore(), nil, zap.NewNop())
+	s.SetAuthenticator(auth)
+
+	get := func(path, authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		want          int
+	}{
+		{"no token", "/api/v1alpha1/projects", "", http.StatusUnauthorized},
+		{"wrong token", "/api/v1alpha1/projects", "Bearer nope", http.StatusUnauthorized},
+		{"wrong scheme", "/api/v1alpha1/projects", "Basic static-token", http.StatusUnauthorized},
+		{"static token", "/api/v1alpha1/projects", "Bearer static-token", http.StatusOK},
+		{"file token", "/api/v1alpha1/projects", "Bearer file-token", http.StatusOK},
+		{"comment is no token", "/api/v1alpha1/projects", "Bearer #", http.StatusUnauthorized},
+		{"health check", "/healthz", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := get(tt.path, tt.authorization); got != tt.want {
+				t.Errorf("GET %s with %q = %d, want %d", tt.path, tt.authorization, got, tt.want)
+			}
+		})
+	}
+
+	// A changed token file replaces the tokens read from it.
+	if err := os.WriteFile(tokenFile, []byte("new-token bob\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	auth.lastRecheck = time.Time{}
+	if got := get("/api/v1alpha1/projects", "Bearer file-token"); got != http.StatusUnauthorized {
+		t.Errorf("removed file token: %d, want 401", got)
+	}
+	if got := get("/api/v1alpha1/projects", "Bearer new-token"); got != http.StatusOK {
+		t.Errorf("added file token: %d, want 200", got)
+	}
+}
@@ -0,0 +1,173 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// ---------------------------------------------------------------------------
+// AgentPoolAutoscalers
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleCreateAutoscaler(w http.ResponseWriter, r *http.Request) {
+	var as v1alpha1.AgentPoolAutoscaler
+	if err := json.NewDecoder(r.Body).Decode(&as); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if project := r.URL.Query().Get("project"); project != "" {
+		as.Metadata.Project = project
+	}
+	if !s.admit(w, r, &as) {
+		return
+	}
+	project := as.Metadata.Project
+	if err := as.Spec.Validate(); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	as.APIVersion = v1alpha1.APIVersion
+	as.Kind = v1alpha1.KindAgentPoolAutoscaler
+	as.Metadata.Project = project
+	as.Metadata.UID = uuid.New().String()
+	now := time.Now()
+	as.Metadata.CreatedAt = now
+	as.Metadata.UpdatedAt = now
+	as.Status = v1alpha1.AgentPoolAutoscalerStatus{}
+
+	key := store.ResourceKey(v1alpha1.KindAgentPoolAutoscaler, project, as.Metadata.Name)
+	if err := s.storeFor(r).Create(key, &as); err != nil {
+		if err == store.ErrAlreadyExists {
+			s.writeError(w, http.StatusConflict, "autoscaler already exists")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, &as)
+}
+
+func (s *Server) handleGetAutoscaler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindAgentPoolAutoscaler, project, name)
+
+	var as v1alpha1.AgentPoolAutoscaler
+	if err := s.storeFor(r).Get(key, &as); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "autoscaler not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &as)
+}
+
+func (s *Server) handleListAutoscalers(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("project")
+	q, ok := s.listQuery(w, r)
+	if !ok {
+		return
+	}
+
+	prefix := "/" + v1alpha1.KindAgentPoolAutoscaler + "/"
+	if project != "" {
+		prefix += project + "/"
+	}
+
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.AgentPoolAutoscaler{} })
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeList(s, w, q, items, func(obj *v1alpha1.AgentPoolAutoscaler) *v1alpha1.ObjectMeta { return &obj.Metadata })
+}
+
+func (s *Server) handleUpdateAutoscaler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindAgentPoolAutoscaler, project, name)
+
+	var existing v1alpha1.AgentPoolAutoscaler
+	if err := s.storeFor(r).Get(key, &existing); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "autoscaler not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var as v1alpha1.AgentPoolAutoscaler
+	if err := json.NewDecoder(r.Body).Decode(&as); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := as.Spec.Validate(); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	as.APIVersion = v1alpha1.APIVersion
+	as.Kind = v1alpha1.KindAgentPoolAutoscaler
+	as.Metadata.Name = name
+	as.Metadata.Project = project
+	as.Metadata.UID = existing.Metadata.UID
+	as.Metadata.CreatedAt = existing.Metadata.CreatedAt
+	as.Metadata.UpdatedAt = time.Now()
+	if !s.admit(w, r, &as) {
+		return
+	}
+
+	if err := s.storeFor(r).UpdateIfVersion(key, &as, as.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &as)
+}
+
+func (s *Server) handleDeleteAutoscaler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindAgentPoolAutoscaler, project, name)
+
+	if err := s.storeFor(r).Delete(key); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "autoscaler not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
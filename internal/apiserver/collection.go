@@ -0,0 +1,63 @@
+package apiserver
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// handleDeleteCollection returns a handler that deletes the objects of kind
+// matching ?labelSelector=, in the project of ?project= unless kind is
+// cluster-scoped. With ?dryRun=true nothing is deleted. The response lists
+// the names of the (would-be) deleted objects and, under "pending", those of
+// them left for their finalizers to remove. DevTasks have their own handler,
+// which also filters by phase and age.
+func (s *Server) handleDeleteCollection(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		project := q.Get("project")
+		if project == "" && !v1alpha1.ClusterScoped(kind) {
+			s.writeError(w, http.StatusBadRequest, "project query param is required")
+			return
+		}
+		sel, ok := s.labelSelector(w, r)
+		if !ok {
+			return
+		}
+		dryRun := q.Get("dryRun") != "" && q.Get("dryRun") != "false"
+
+		type metaOnly struct {
+			Metadata v1alpha1.ObjectMeta `json:"metadata"`
+		}
+		items, err := s.storeFor(r).List("/"+kind+"/"+project+"/", func() interface{} { return &metaOnly{} })
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		deleted, pending := make([]string, 0), make([]string, 0)
+		for _, item := range items {
+			meta := item.(*metaOnly).Metadata
+			if !sel.Matches(meta.Labels) {
+				continue
+			}
+			if !dryRun {
+				key := store.ResourceKey(kind, project, meta.Name)
+				if err := s.storeFor(r).Delete(key); err != nil && err != store.ErrNotFound {
+					s.writeError(w, http.StatusInternalServerError, err.Error())
+					return
+				}
+				if s.storeFor(r).Get(key, &metaOnly{}) == nil {
+					pending = append(pending, meta.Name)
+				}
+			}
+			deleted = append(deleted, meta.Name)
+		}
+		sort.Strings(deleted)
+		sort.Strings(pending)
+
+		s.writeJSON(w, http.StatusOK, map[string][]string{"deleted": deleted, "pending": pending})
+	}
+}
@@ -0,0 +1,90 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestDeleteCollection(t *testing.T) {
+	st := store.NewMemoryStore()
+	for _, name := range []string{"b", "a", "c"} {
+		cm := &v1alpha1.ConfigMap{Metadata: v1alpha1.ObjectMeta{Name: name, Project: "web"}}
+		if name != "c" {
+			cm.Metadata.Labels = map[string]string{"tier": "cache"}
+		}
+		if name == "b" {
+			cm.Metadata.Finalizers = []string{"example.com/cleanup"}
+		}
+		if err := st.Create(store.ResourceKey(v1alpha1.KindConfigMap, "web", name), cm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	node := &v1alpha1.AgentNode{Metadata: v1alpha1.ObjectMeta{Name: "gpu-1"}}
+	if err := st.Create(store.ResourceKey(v1alpha1.KindAgentNode, "", "gpu-1"), node); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer("", st, nil, zap.NewNop())
+
+	deleteCollection := func(query string, want int) (deleted, pending []string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1alpha1/"+query, nil))
+		if rec.Code != want {
+			t.Fatalf("DELETE %s: %d %s, want %d", query, rec.Code, rec.Body, want)
+		}
+		var out struct {
+			Deleted []string `json:"deleted"`
+			Pending []string `json:"pending"`
+		}
+		if want == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return out.Deleted, out.Pending
+	}
+	left := func() string {
+		t.Helper()
+		items, err := st.List("/"+v1alpha1.KindConfigMap+"/web/", func() interface{} { return &v1alpha1.ConfigMap{} })
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, item := range items {
+			names = append(names, item.(*v1alpha1.ConfigMap).Metadata.Name)
+		}
+		sort.Strings(names)
+		return fmt.Sprint(names)
+	}
+
+	deleteCollection("configmaps?labelSelector=tier%3Dcache", http.StatusBadRequest)
+	deleteCollection("configmaps?project=web&labelSelector=tier%3D%3D%3D", http.StatusBadRequest)
+
+	if got, _ := deleteCollection("configmaps?project=web&labelSelector=tier%3Dcache&dryRun=true", http.StatusOK); fmt.Sprint(got) != "[a b]" {
+		t.Errorf("dry run deleted %v, want [a b]", got)
+	}
+	if got := left(); got != "[a b c]" {
+		t.Errorf("after a dry run, config maps %s are left, want [a b c]", got)
+	}
+
+	if deleted, pending := deleteCollection("configmaps?project=web&labelSelector=tier%3Dcache", http.StatusOK); fmt.Sprint(deleted) != "[a b]" || fmt.Sprint(pending) != "[b]" {
+		t.Errorf("deleted %v, pending %v, want [a b] deleted and [b] pending", deleted, pending)
+	}
+	if got := left(); got != "[b c]" {
+		t.Errorf("config maps %s are left, want [b c]", got)
+	}
+
+	// Cluster-scoped kinds need no project.
+	if got, _ := deleteCollection("agentnodes", http.StatusOK); fmt.Sprint(got) != "[gpu-1]" {
+		t.Errorf("deleted nodes %v, want [gpu-1]", got)
+	}
+}
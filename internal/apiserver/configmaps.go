@@ -0,0 +1,164 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// ---------------------------------------------------------------------------
+// ConfigMaps
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleCreateConfigMap(w http.ResponseWriter, r *http.Request) {
+	var cm v1alpha1.ConfigMap
+	if err := json.NewDecoder(r.Body).Decode(&cm); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if project := r.URL.Query().Get("project"); project != "" {
+		cm.Metadata.Project = project
+	}
+	if !s.admit(w, r, &cm) {
+		return
+	}
+	project := cm.Metadata.Project
+
+	cm.APIVersion = v1alpha1.APIVersion
+	cm.Kind = v1alpha1.KindConfigMap
+	cm.Metadata.Project = project
+	cm.Metadata.UID = uuid.New().String()
+	now := time.Now()
+	cm.Metadata.CreatedAt = now
+	cm.Metadata.UpdatedAt = now
+
+	key := store.ResourceKey(v1alpha1.KindConfigMap, project, cm.Metadata.Name)
+	if err := s.storeFor(r).Create(key, &cm); err != nil {
+		if err == store.ErrAlreadyExists {
+			s.writeError(w, http.StatusConflict, "configmap already exists")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, &cm)
+}
+
+func (s *Server) handleGetConfigMap(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindConfigMap, project, name)
+
+	var cm v1alpha1.ConfigMap
+	if err := s.storeFor(r).Get(key, &cm); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "configmap not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &cm)
+}
+
+func (s *Server) handleListConfigMaps(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("project")
+	q, ok := s.listQuery(w, r)
+	if !ok {
+		return
+	}
+
+	prefix := "/" + v1alpha1.KindConfigMap + "/"
+	if project != "" {
+		prefix += project + "/"
+	}
+
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.ConfigMap{} })
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeList(s, w, q, items, func(obj *v1alpha1.ConfigMap) *v1alpha1.ObjectMeta { return &obj.Metadata })
+}
+
+func (s *Server) handleUpdateConfigMap(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindConfigMap, project, name)
+
+	var existing v1alpha1.ConfigMap
+	if err := s.storeFor(r).Get(key, &existing); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "configmap not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var cm v1alpha1.ConfigMap
+	if err := json.NewDecoder(r.Body).Decode(&cm); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cm.APIVersion = v1alpha1.APIVersion
+	cm.Kind = v1alpha1.KindConfigMap
+	cm.Metadata.Name = name
+	cm.Metadata.Project = project
+	cm.Metadata.UID = existing.Metadata.UID
+	cm.Metadata.CreatedAt = existing.Metadata.CreatedAt
+	cm.Metadata.UpdatedAt = time.Now()
+	if !s.admit(w, r, &cm) {
+		return
+	}
+
+	if err := s.storeFor(r).UpdateIfVersion(key, &cm, cm.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &cm)
+}
+
+func (s *Server) handleDeleteConfigMap(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindConfigMap, project, name)
+
+	if err := s.storeFor(r).Delete(key); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "configmap not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
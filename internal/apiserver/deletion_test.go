@@ -0,0 +1,97 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestDeleteWithFinalizers(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Store.DataDir = t.TempDir()
+	executor := agent.NewExecutor(cfg.Agent.ClaudeCLI, zap.NewNop())
+	defer executor.Close()
+	st := store.NewMemoryStore()
+	rt := agent.NewRuntime(st, executor, cfg, zap.NewNop())
+	s := NewServer("", st, rt, zap.NewNop())
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	for _, name := range []string{"kept", "gone"} {
+		task := &v1alpha1.DevTask{
+			Metadata: v1alpha1.ObjectMeta{Name: name, Project: "web"},
+			Spec:     v1alpha1.DevTaskSpec{Prompt: "p"},
+		}
+		if name == "kept" {
+			task.Metadata.Finalizers = []string{v1alpha1.FinalizerExecution}
+		}
+		if err := st.Create(store.ResourceKey(v1alpha1.KindDevTask, "web", name), task); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(rt.ArtifactDir("web", name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A manifest without finalizers keeps those the controllers set.
+	rec := serve(http.MethodPut, "/api/v1alpha1/devtasks/kept?project=web", `{"metadata":{"name":"kept"},"spec":{"prompt":"q"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(http.MethodPost, "/api/v1alpha1/apply", `{"kind":"DevTask","metadata":{"name":"kept","project":"web"},"spec":{"prompt":"r"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("apply: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(http.MethodDelete, "/api/v1alpha1/devtasks/kept?project=web", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("delete of a task with a finalizer: %d %s, want 202", rec.Code, rec.Body)
+	}
+	var task v1alpha1.DevTask
+	if err := json.Unmarshal(rec.Body.Bytes(), &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Metadata.DeletionTimestamp == nil || !task.Metadata.HasFinalizer(v1alpha1.FinalizerExecution) {
+		t.Errorf("deleted task has metadata %+v, want a deletionTimestamp and its finalizer", task.Metadata)
+	}
+	if _, err := os.Stat(rt.ArtifactDir("web", "kept")); err != nil {
+		t.Errorf("artifacts of a task being deleted: %v, want them kept", err)
+	}
+
+	events, cancel := st.Watch(store.ResourceKey(v1alpha1.KindDevTask, "web", "gone"))
+	defer cancel()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1alpha1/devtasks/gone?project=web", nil)
+	req.Header.Set(requestIDHeader, "delete-gone")
+	rec = httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete of a task without finalizers: %d %s, want 204", rec.Code, rec.Body)
+	}
+	select {
+	case evt := <-events:
+		if evt.Type != v1alpha1.EventDeleted || evt.RequestID != "delete-gone" {
+			t.Errorf("watch event %s with request ID %q, want DELETED with delete-gone", evt.Type, evt.RequestID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no watch event for the deleted task")
+	}
+	if _, err := os.Stat(rt.ArtifactDir("web", "gone")); !os.IsNotExist(err) {
+		t.Errorf("artifacts of a deleted task: %v, want them removed", err)
+	}
+}
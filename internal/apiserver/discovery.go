@@ -0,0 +1,102 @@
+package apiserver
+
+import (
+	"net/http"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/klubi/orca/internal/version"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// apiPrefix is the path under which every API resource is served.
+const apiPrefix = "/api/v1alpha1"
+
+// resourceKinds maps the path segment of each API resource to its kind.
+// Paths under other segments, such as /watch or /apply, are not resources.
+var resourceKinds = map[string]string{
+	"projects":        v1alpha1.KindProject,
+	"agentpods":       v1alpha1.KindAgentPod,
+	"agentpools":      v1alpha1.KindAgentPool,
+	"autoscalers":     v1alpha1.KindAgentPoolAutoscaler,
+	"devtasks":        v1alpha1.KindDevTask,
+	"secrets":         v1alpha1.KindSecret,
+	"configmaps":      v1alpha1.KindConfigMap,
+	"modelproviders":  v1alpha1.KindModelProvider,
+	"tooldefinitions": v1alpha1.KindToolDefinition,
+	"events":          v1alpha1.KindEvent,
+	"agentnodes":      v1alpha1.KindAgentNode,
+}
+
+// varPattern matches the regexp part of a mux path variable, as in
+// {artifact:.+}, which OpenAPI path templates do not allow.
+var varPattern = regexp.MustCompile(`\{(\w+):[^}]*\}`)
+
+// handleVersion reports the server's build and the API version it serves.
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, map[string]string{
+		"version":    version.Get(),
+		"apiVersion": v1alpha1.APIVersion,
+		"goVersion":  runtime.Version(),
+	})
+}
+
+// handleOpenAPI describes the routes the server has registered as an
+// OpenAPI document. Only paths and methods are listed, not schemas; it is
+// meant for clients that need to know what the server supports. The path
+// items of a resource carry its kind and scope in the x-orca-kind and
+// x-orca-scope extensions.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	paths := map[string]map[string]interface{}{}
+	_ = s.router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Prefixes of subrouters match no method.
+			return nil
+		}
+		tmpl = varPattern.ReplaceAllString(tmpl, "{$1}")
+
+		item := paths[tmpl]
+		if item == nil {
+			item = map[string]interface{}{}
+			paths[tmpl] = item
+		}
+		for _, m := range methods {
+			item[strings.ToLower(m)] = map[string]interface{}{
+				"responses": map[string]interface{}{
+					"default": map[string]string{"description": "See the Orca API reference."},
+				},
+			}
+		}
+
+		rest, ok := strings.CutPrefix(tmpl, apiPrefix+"/")
+		if !ok {
+			return nil
+		}
+		resource, _, _ := strings.Cut(rest, "/")
+		if kind, ok := resourceKinds[resource]; ok {
+			item["x-orca-kind"] = kind
+			item["x-orca-scope"] = "project"
+			if v1alpha1.ClusterScoped(kind) {
+				item["x-orca-scope"] = "cluster"
+			}
+		}
+		return nil
+	})
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]string{
+			"title":   "Orca API",
+			"version": version.Get(),
+		},
+		"paths": paths,
+	})
+}
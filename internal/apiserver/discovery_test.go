@@ -0,0 +1,48 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func TestOpenAPI(t *testing.T) {
+	s := &Server{router: mux.NewRouter(), logger: zap.NewNop()}
+	s.registerRoutes()
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /openapi: %d %s", rec.Code, rec.Body)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	item := doc.Paths["/api/v1alpha1/devtasks/{name}"]
+	for _, m := range []string{"get", "put", "patch", "delete"} {
+		if item[m] == nil {
+			t.Errorf("devtasks/{name} lacks %s", m)
+		}
+	}
+	if item["x-orca-kind"] != "DevTask" || item["x-orca-scope"] != "project" {
+		t.Errorf("devtasks/{name} extensions = %v, %v", item["x-orca-kind"], item["x-orca-scope"])
+	}
+	if got := doc.Paths["/api/v1alpha1/projects"]["x-orca-scope"]; got != "cluster" {
+		t.Errorf("projects scope = %v, want cluster", got)
+	}
+	if _, ok := doc.Paths["/api/v1alpha1/devtasks/{name}/artifacts/{artifact}"]; !ok {
+		t.Error("artifact path template keeps its regexp")
+	}
+	if _, ok := doc.Paths["/api/v1alpha1/watch"]["x-orca-kind"]; ok {
+		t.Error("watch is marked as a resource")
+	}
+}
@@ -1,16 +1,26 @@
 package apiserver
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
+	"github.com/klubi/orca/internal/agent"
 	"github.com/klubi/orca/internal/store"
+	"github.com/klubi/orca/internal/tracing"
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/labels"
 )
 
 // ---------------------------------------------------------------------------
@@ -31,6 +41,43 @@ func (s *Server) writeError(w http.ResponseWriter, status int, msg string) {
 	s.writeJSON(w, status, map[string]string{"error": msg})
 }
 
+// conflictRetries is how many times a read-modify-write the server makes on
+// its own is attempted before a concurrent writer wins with 409 Conflict.
+const conflictRetries = 5
+
+// writeUpdateError writes the response for err, returned by a store
+// update: 409 Conflict if the object changed since the resourceVersion the
+// update was made against, 404 if it is gone, 500 otherwise.
+func (s *Server) writeUpdateError(w http.ResponseWriter, err error) {
+	switch err {
+	case store.ErrConflict:
+		s.writeError(w, http.StatusConflict, "the object has been modified; get the latest version and try again")
+	case store.ErrNotFound:
+		s.writeError(w, http.StatusNotFound, "the object has been deleted")
+	default:
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// resourceVersion returns the metadata.resourceVersion of doc, a decoded
+// JSON object.
+func resourceVersion(doc map[string]interface{}) string {
+	meta, _ := doc["metadata"].(map[string]interface{})
+	v, _ := meta["resourceVersion"].(string)
+	return v
+}
+
+// labelSelector parses the request's ?labelSelector= query parameter. It
+// writes a 400 response and returns false when the selector is malformed.
+func (s *Server) labelSelector(w http.ResponseWriter, r *http.Request) (labels.Selector, bool) {
+	sel, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return sel, true
+}
+
 // ---------------------------------------------------------------------------
 // Health
 // ---------------------------------------------------------------------------
@@ -39,6 +86,19 @@ func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
+// handleReadyz reports whether tasks can actually run, i.e. whether the
+// claude CLI passed its startup self-check.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if err := s.runtime.Ready(); err != nil {
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"status": "unavailable",
+			"reason": err.Error(),
+		})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
 // ---------------------------------------------------------------------------
 // Projects
 // ---------------------------------------------------------------------------
@@ -57,9 +117,12 @@ func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request) {
 	p.Metadata.CreatedAt = now
 	p.Metadata.UpdatedAt = now
 	p.Status = "Active"
+	if !s.validate(w, &p) {
+		return
+	}
 
 	key := store.ResourceKey(v1alpha1.KindProject, "", p.Metadata.Name)
-	if err := s.store.Create(key, &p); err != nil {
+	if err := s.storeFor(r).Create(key, &p); err != nil {
 		if err == store.ErrAlreadyExists {
 			s.writeError(w, http.StatusConflict, "project already exists")
 			return
@@ -76,7 +139,7 @@ func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
 	key := store.ResourceKey(v1alpha1.KindProject, "", name)
 
 	var p v1alpha1.Project
-	if err := s.store.Get(key, &p); err != nil {
+	if err := s.storeFor(r).Get(key, &p); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "project not found")
 			return
@@ -89,19 +152,19 @@ func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
+	q, ok := s.listQuery(w, r)
+	if !ok {
+		return
+	}
+
 	prefix := "/" + v1alpha1.KindProject + "/"
-	items, err := s.store.List(prefix, func() interface{} { return &v1alpha1.Project{} })
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.Project{} })
 	if err != nil {
 		s.writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	projects := make([]*v1alpha1.Project, 0, len(items))
-	for _, item := range items {
-		projects = append(projects, item.(*v1alpha1.Project))
-	}
-
-	s.writeJSON(w, http.StatusOK, projects)
+	writeList(s, w, q, items, func(obj *v1alpha1.Project) *v1alpha1.ObjectMeta { return &obj.Metadata })
 }
 
 func (s *Server) handleUpdateProject(w http.ResponseWriter, r *http.Request) {
@@ -109,7 +172,7 @@ func (s *Server) handleUpdateProject(w http.ResponseWriter, r *http.Request) {
 	key := store.ResourceKey(v1alpha1.KindProject, "", name)
 
 	var existing v1alpha1.Project
-	if err := s.store.Get(key, &existing); err != nil {
+	if err := s.storeFor(r).Get(key, &existing); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "project not found")
 			return
@@ -131,20 +194,51 @@ func (s *Server) handleUpdateProject(w http.ResponseWriter, r *http.Request) {
 	p.Metadata.UID = existing.Metadata.UID
 	p.Metadata.CreatedAt = existing.Metadata.CreatedAt
 	p.Metadata.UpdatedAt = time.Now()
+	if !s.validate(w, &p) {
+		return
+	}
 
-	if err := s.store.Update(key, &p); err != nil {
-		s.writeError(w, http.StatusInternalServerError, err.Error())
+	if err := s.storeFor(r).UpdateIfVersion(key, &p, p.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
 		return
 	}
 
 	s.writeJSON(w, http.StatusOK, &p)
 }
 
+// handleDeleteProject deletes a project and, unless ?propagation=orphan,
+// the workloads in it first, so that none is scheduled once it is gone.
 func (s *Server) handleDeleteProject(w http.ResponseWriter, r *http.Request) {
 	name := mux.Vars(r)["name"]
 	key := store.ResourceKey(v1alpha1.KindProject, "", name)
 
-	if err := s.store.Delete(key); err != nil {
+	propagation := v1alpha1.DeletionPropagation(r.URL.Query().Get("propagation"))
+	switch propagation {
+	case "":
+		propagation = v1alpha1.PropagationDelete
+	case v1alpha1.PropagationDelete, v1alpha1.PropagationOrphan:
+	default:
+		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid propagation %q (want delete or orphan)", propagation))
+		return
+	}
+
+	var p v1alpha1.Project
+	if err := s.storeFor(r).Get(key, &p); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if propagation == v1alpha1.PropagationDelete {
+		if err := s.deleteProjectWorkloads(r, name); err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	if err := s.storeFor(r).Delete(key); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "project not found")
 			return
@@ -156,6 +250,48 @@ func (s *Server) handleDeleteProject(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// projectWorkloadKinds are the kinds a project's cascading delete removes,
+// in order: tasks first, so that none is scheduled on a pod about to go,
+// then autoscalers and pools, so that none replaces a deleted pod.
+var projectWorkloadKinds = []string{
+	v1alpha1.KindDevTask,
+	v1alpha1.KindAgentPoolAutoscaler,
+	v1alpha1.KindAgentPool,
+	v1alpha1.KindAgentPod,
+}
+
+// deleteProjectWorkloads deletes the workloads in project.
+func (s *Server) deleteProjectWorkloads(r *http.Request, project string) error {
+	type metaOnly struct {
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+	}
+	for _, kind := range projectWorkloadKinds {
+		items, err := s.storeFor(r).List("/"+kind+"/"+project+"/", func() interface{} { return &metaOnly{} })
+		if err != nil {
+			return fmt.Errorf("listing %s of project %s: %w", kind, project, err)
+		}
+		for _, item := range items {
+			name := item.(*metaOnly).Metadata.Name
+			if kind == v1alpha1.KindDevTask {
+				err = s.deleteDevTask(r, project, name)
+			} else {
+				err = s.storeFor(r).Delete(store.ResourceKey(kind, project, name))
+			}
+			if err != nil && err != store.ErrNotFound {
+				return fmt.Errorf("deleting %s %s/%s: %w", kind, project, name, err)
+			}
+		}
+		if len(items) > 0 {
+			s.logger.Info("deleted the workloads of a deleted project",
+				zap.String("project", project),
+				zap.String("kind", kind),
+				zap.Int("count", len(items)),
+			)
+		}
+	}
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // AgentPods
 // ---------------------------------------------------------------------------
@@ -167,14 +303,13 @@ func (s *Server) handleCreateAgentPod(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	project := r.URL.Query().Get("project")
-	if project == "" {
-		project = pod.Metadata.Project
+	if project := r.URL.Query().Get("project"); project != "" {
+		pod.Metadata.Project = project
 	}
-	if project == "" {
-		s.writeError(w, http.StatusBadRequest, "project is required (query param or metadata.project)")
+	if !s.admit(w, r, &pod) {
 		return
 	}
+	project := pod.Metadata.Project
 
 	pod.APIVersion = v1alpha1.APIVersion
 	pod.Kind = v1alpha1.KindAgentPod
@@ -186,7 +321,7 @@ func (s *Server) handleCreateAgentPod(w http.ResponseWriter, r *http.Request) {
 	pod.Status.Phase = v1alpha1.PodPending
 
 	key := store.ResourceKey(v1alpha1.KindAgentPod, project, pod.Metadata.Name)
-	if err := s.store.Create(key, &pod); err != nil {
+	if err := s.storeFor(r).Create(key, &pod); err != nil {
 		if err == store.ErrAlreadyExists {
 			s.writeError(w, http.StatusConflict, "agentpod already exists")
 			return
@@ -209,7 +344,7 @@ func (s *Server) handleGetAgentPod(w http.ResponseWriter, r *http.Request) {
 	key := store.ResourceKey(v1alpha1.KindAgentPod, project, name)
 
 	var pod v1alpha1.AgentPod
-	if err := s.store.Get(key, &pod); err != nil {
+	if err := s.storeFor(r).Get(key, &pod); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "agentpod not found")
 			return
@@ -223,6 +358,10 @@ func (s *Server) handleGetAgentPod(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleListAgentPods(w http.ResponseWriter, r *http.Request) {
 	project := r.URL.Query().Get("project")
+	q, ok := s.listQuery(w, r)
+	if !ok {
+		return
+	}
 
 	var prefix string
 	if project != "" {
@@ -231,18 +370,13 @@ func (s *Server) handleListAgentPods(w http.ResponseWriter, r *http.Request) {
 		prefix = "/" + v1alpha1.KindAgentPod + "/"
 	}
 
-	items, err := s.store.List(prefix, func() interface{} { return &v1alpha1.AgentPod{} })
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.AgentPod{} })
 	if err != nil {
 		s.writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	pods := make([]*v1alpha1.AgentPod, 0, len(items))
-	for _, item := range items {
-		pods = append(pods, item.(*v1alpha1.AgentPod))
-	}
-
-	s.writeJSON(w, http.StatusOK, pods)
+	writeList(s, w, q, items, func(obj *v1alpha1.AgentPod) *v1alpha1.ObjectMeta { return &obj.Metadata })
 }
 
 func (s *Server) handleUpdateAgentPod(w http.ResponseWriter, r *http.Request) {
@@ -256,7 +390,7 @@ func (s *Server) handleUpdateAgentPod(w http.ResponseWriter, r *http.Request) {
 	key := store.ResourceKey(v1alpha1.KindAgentPod, project, name)
 
 	var existing v1alpha1.AgentPod
-	if err := s.store.Get(key, &existing); err != nil {
+	if err := s.storeFor(r).Get(key, &existing); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "agentpod not found")
 			return
@@ -278,9 +412,20 @@ func (s *Server) handleUpdateAgentPod(w http.ResponseWriter, r *http.Request) {
 	pod.Metadata.UID = existing.Metadata.UID
 	pod.Metadata.CreatedAt = existing.Metadata.CreatedAt
 	pod.Metadata.UpdatedAt = time.Now()
+	// Manifests rarely list the finalizers and owner references the
+	// controllers set; an explicitly empty list clears them.
+	if pod.Metadata.Finalizers == nil {
+		pod.Metadata.Finalizers = existing.Metadata.Finalizers
+	}
+	if pod.Metadata.OwnerReferences == nil {
+		pod.Metadata.OwnerReferences = existing.Metadata.OwnerReferences
+	}
+	if !s.admit(w, r, &pod) {
+		return
+	}
 
-	if err := s.store.Update(key, &pod); err != nil {
-		s.writeError(w, http.StatusInternalServerError, err.Error())
+	if err := s.storeFor(r).UpdateIfVersion(key, &pod, pod.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
 		return
 	}
 
@@ -297,7 +442,7 @@ func (s *Server) handleDeleteAgentPod(w http.ResponseWriter, r *http.Request) {
 
 	key := store.ResourceKey(v1alpha1.KindAgentPod, project, name)
 
-	if err := s.store.Delete(key); err != nil {
+	if err := s.storeFor(r).Delete(key); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "agentpod not found")
 			return
@@ -306,6 +451,17 @@ func (s *Server) handleDeleteAgentPod(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.writeDeleted(w, r, key, &v1alpha1.AgentPod{})
+}
+
+// writeDeleted answers the deletion of the object at key: 202 Accepted with
+// the object if it is left for its finalizers to remove, 204 No Content if
+// it is gone. obj receives the object.
+func (s *Server) writeDeleted(w http.ResponseWriter, r *http.Request, key string, obj v1alpha1.Object) {
+	if err := s.storeFor(r).Get(key, obj); err == nil {
+		s.writeJSON(w, http.StatusAccepted, obj)
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -320,14 +476,13 @@ func (s *Server) handleCreateAgentPool(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	project := r.URL.Query().Get("project")
-	if project == "" {
-		project = pool.Metadata.Project
+	if project := r.URL.Query().Get("project"); project != "" {
+		pool.Metadata.Project = project
 	}
-	if project == "" {
-		s.writeError(w, http.StatusBadRequest, "project is required (query param or metadata.project)")
+	if !s.admit(w, r, &pool) {
 		return
 	}
+	project := pool.Metadata.Project
 
 	pool.APIVersion = v1alpha1.APIVersion
 	pool.Kind = v1alpha1.KindAgentPool
@@ -341,7 +496,7 @@ func (s *Server) handleCreateAgentPool(w http.ResponseWriter, r *http.Request) {
 	pool.Status.BusyReplicas = 0
 
 	key := store.ResourceKey(v1alpha1.KindAgentPool, project, pool.Metadata.Name)
-	if err := s.store.Create(key, &pool); err != nil {
+	if err := s.storeFor(r).Create(key, &pool); err != nil {
 		if err == store.ErrAlreadyExists {
 			s.writeError(w, http.StatusConflict, "agentpool already exists")
 			return
@@ -364,7 +519,7 @@ func (s *Server) handleGetAgentPool(w http.ResponseWriter, r *http.Request) {
 	key := store.ResourceKey(v1alpha1.KindAgentPool, project, name)
 
 	var pool v1alpha1.AgentPool
-	if err := s.store.Get(key, &pool); err != nil {
+	if err := s.storeFor(r).Get(key, &pool); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "agentpool not found")
 			return
@@ -378,6 +533,10 @@ func (s *Server) handleGetAgentPool(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleListAgentPools(w http.ResponseWriter, r *http.Request) {
 	project := r.URL.Query().Get("project")
+	q, ok := s.listQuery(w, r)
+	if !ok {
+		return
+	}
 
 	var prefix string
 	if project != "" {
@@ -386,18 +545,13 @@ func (s *Server) handleListAgentPools(w http.ResponseWriter, r *http.Request) {
 		prefix = "/" + v1alpha1.KindAgentPool + "/"
 	}
 
-	items, err := s.store.List(prefix, func() interface{} { return &v1alpha1.AgentPool{} })
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.AgentPool{} })
 	if err != nil {
 		s.writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	pools := make([]*v1alpha1.AgentPool, 0, len(items))
-	for _, item := range items {
-		pools = append(pools, item.(*v1alpha1.AgentPool))
-	}
-
-	s.writeJSON(w, http.StatusOK, pools)
+	writeList(s, w, q, items, func(obj *v1alpha1.AgentPool) *v1alpha1.ObjectMeta { return &obj.Metadata })
 }
 
 func (s *Server) handleUpdateAgentPool(w http.ResponseWriter, r *http.Request) {
@@ -411,7 +565,7 @@ func (s *Server) handleUpdateAgentPool(w http.ResponseWriter, r *http.Request) {
 	key := store.ResourceKey(v1alpha1.KindAgentPool, project, name)
 
 	var existing v1alpha1.AgentPool
-	if err := s.store.Get(key, &existing); err != nil {
+	if err := s.storeFor(r).Get(key, &existing); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "agentpool not found")
 			return
@@ -433,9 +587,12 @@ func (s *Server) handleUpdateAgentPool(w http.ResponseWriter, r *http.Request) {
 	pool.Metadata.UID = existing.Metadata.UID
 	pool.Metadata.CreatedAt = existing.Metadata.CreatedAt
 	pool.Metadata.UpdatedAt = time.Now()
+	if !s.admit(w, r, &pool) {
+		return
+	}
 
-	if err := s.store.Update(key, &pool); err != nil {
-		s.writeError(w, http.StatusInternalServerError, err.Error())
+	if err := s.storeFor(r).UpdateIfVersion(key, &pool, pool.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
 		return
 	}
 
@@ -452,7 +609,7 @@ func (s *Server) handleDeleteAgentPool(w http.ResponseWriter, r *http.Request) {
 
 	key := store.ResourceKey(v1alpha1.KindAgentPool, project, name)
 
-	if err := s.store.Delete(key); err != nil {
+	if err := s.storeFor(r).Delete(key); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "agentpool not found")
 			return
@@ -464,7 +621,9 @@ func (s *Server) handleDeleteAgentPool(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// handleScaleAgentPool updates only the replicas count of an AgentPool.
+// handleScaleAgentPool updates only the replicas count of an AgentPool. When
+// the body carries currentReplicas, the pool is only scaled if its replica
+// count still has that value; otherwise the request fails with 409 Conflict.
 func (s *Server) handleScaleAgentPool(w http.ResponseWriter, r *http.Request) {
 	name := mux.Vars(r)["name"]
 	project := r.URL.Query().Get("project")
@@ -474,7 +633,8 @@ func (s *Server) handleScaleAgentPool(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var body struct {
-		Replicas int `json:"replicas"`
+		Replicas        int  `json:"replicas"`
+		CurrentReplicas *int `json:"currentReplicas,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		s.writeError(w, http.StatusBadRequest, err.Error())
@@ -487,25 +647,38 @@ func (s *Server) handleScaleAgentPool(w http.ResponseWriter, r *http.Request) {
 
 	key := store.ResourceKey(v1alpha1.KindAgentPool, project, name)
 
-	var pool v1alpha1.AgentPool
-	if err := s.store.Get(key, &pool); err != nil {
-		if err == store.ErrNotFound {
-			s.writeError(w, http.StatusNotFound, "agentpool not found")
+	// Scale the pool as stored; if a controller writes it in between, scale
+	// the new version.
+	for attempt := 1; ; attempt++ {
+		var pool v1alpha1.AgentPool
+		if err := s.storeFor(r).Get(key, &pool); err != nil {
+			if err == store.ErrNotFound {
+				s.writeError(w, http.StatusNotFound, "agentpool not found")
+				return
+			}
+			s.writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		s.writeError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
 
-	pool.Spec.Replicas = body.Replicas
-	pool.Metadata.UpdatedAt = time.Now()
+		if body.CurrentReplicas != nil && *body.CurrentReplicas != pool.Spec.Replicas {
+			s.writeError(w, http.StatusConflict, fmt.Sprintf("expected %d replicas, agentpool has %d", *body.CurrentReplicas, pool.Spec.Replicas))
+			return
+		}
 
-	if err := s.store.Update(key, &pool); err != nil {
-		s.writeError(w, http.StatusInternalServerError, err.Error())
+		pool.Spec.Replicas = body.Replicas
+		pool.Metadata.UpdatedAt = time.Now()
+
+		err := s.storeFor(r).UpdateIfVersion(key, &pool, pool.Metadata.ResourceVersion)
+		if err == store.ErrConflict && attempt < conflictRetries {
+			continue
+		}
+		if err != nil {
+			s.writeUpdateError(w, err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, &pool)
 		return
 	}
-
-	s.writeJSON(w, http.StatusOK, &pool)
 }
 
 // ---------------------------------------------------------------------------
@@ -519,14 +692,13 @@ func (s *Server) handleCreateDevTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	project := r.URL.Query().Get("project")
-	if project == "" {
-		project = task.Metadata.Project
+	if project := r.URL.Query().Get("project"); project != "" {
+		task.Metadata.Project = project
 	}
-	if project == "" {
-		s.writeError(w, http.StatusBadRequest, "project is required (query param or metadata.project)")
+	if !s.admit(w, r, &task) || !s.admitNewTask(w, r, &task) {
 		return
 	}
+	project := task.Metadata.Project
 
 	task.APIVersion = v1alpha1.APIVersion
 	task.Kind = v1alpha1.KindDevTask
@@ -536,9 +708,10 @@ func (s *Server) handleCreateDevTask(w http.ResponseWriter, r *http.Request) {
 	task.Metadata.CreatedAt = now
 	task.Metadata.UpdatedAt = now
 	task.Status.Phase = v1alpha1.TaskPending
+	tracing.Inject(r.Context(), &task.Metadata)
 
 	key := store.ResourceKey(v1alpha1.KindDevTask, project, task.Metadata.Name)
-	if err := s.store.Create(key, &task); err != nil {
+	if err := s.storeFor(r).Create(key, &task); err != nil {
 		if err == store.ErrAlreadyExists {
 			s.writeError(w, http.StatusConflict, "devtask already exists")
 			return
@@ -561,7 +734,7 @@ func (s *Server) handleGetDevTask(w http.ResponseWriter, r *http.Request) {
 	key := store.ResourceKey(v1alpha1.KindDevTask, project, name)
 
 	var task v1alpha1.DevTask
-	if err := s.store.Get(key, &task); err != nil {
+	if err := s.storeFor(r).Get(key, &task); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "devtask not found")
 			return
@@ -575,6 +748,10 @@ func (s *Server) handleGetDevTask(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleListDevTasks(w http.ResponseWriter, r *http.Request) {
 	project := r.URL.Query().Get("project")
+	q, ok := s.listQuery(w, r)
+	if !ok {
+		return
+	}
 
 	var prefix string
 	if project != "" {
@@ -583,18 +760,13 @@ func (s *Server) handleListDevTasks(w http.ResponseWriter, r *http.Request) {
 		prefix = "/" + v1alpha1.KindDevTask + "/"
 	}
 
-	items, err := s.store.List(prefix, func() interface{} { return &v1alpha1.DevTask{} })
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.DevTask{} })
 	if err != nil {
 		s.writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	tasks := make([]*v1alpha1.DevTask, 0, len(items))
-	for _, item := range items {
-		tasks = append(tasks, item.(*v1alpha1.DevTask))
-	}
-
-	s.writeJSON(w, http.StatusOK, tasks)
+	writeList(s, w, q, items, func(obj *v1alpha1.DevTask) *v1alpha1.ObjectMeta { return &obj.Metadata })
 }
 
 func (s *Server) handleUpdateDevTask(w http.ResponseWriter, r *http.Request) {
@@ -608,7 +780,7 @@ func (s *Server) handleUpdateDevTask(w http.ResponseWriter, r *http.Request) {
 	key := store.ResourceKey(v1alpha1.KindDevTask, project, name)
 
 	var existing v1alpha1.DevTask
-	if err := s.store.Get(key, &existing); err != nil {
+	if err := s.storeFor(r).Get(key, &existing); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "devtask not found")
 			return
@@ -630,9 +802,17 @@ func (s *Server) handleUpdateDevTask(w http.ResponseWriter, r *http.Request) {
 	task.Metadata.UID = existing.Metadata.UID
 	task.Metadata.CreatedAt = existing.Metadata.CreatedAt
 	task.Metadata.UpdatedAt = time.Now()
+	// Manifests rarely list the finalizers the controllers set; an
+	// explicitly empty list clears them.
+	if task.Metadata.Finalizers == nil {
+		task.Metadata.Finalizers = existing.Metadata.Finalizers
+	}
+	if !s.admit(w, r, &task) {
+		return
+	}
 
-	if err := s.store.Update(key, &task); err != nil {
-		s.writeError(w, http.StatusInternalServerError, err.Error())
+	if err := s.storeFor(r).UpdateIfVersion(key, &task, task.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
 		return
 	}
 
@@ -647,9 +827,130 @@ func (s *Server) handleDeleteDevTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := s.deleteDevTask(r, project, name); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "devtask not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeDeleted(w, r, store.ResourceKey(v1alpha1.KindDevTask, project, name), &v1alpha1.DevTask{})
+}
+
+// deleteDevTask removes a task and its artifacts. A task the runtime has
+// executed is only marked for deletion; the DevTask controller removes its
+// artifacts once its execution has stopped.
+func (s *Server) deleteDevTask(r *http.Request, project, name string) error {
+	key := store.ResourceKey(v1alpha1.KindDevTask, project, name)
+	if err := s.storeFor(r).Delete(key); err != nil {
+		return err
+	}
+	if err := s.storeFor(r).Get(key, &v1alpha1.DevTask{}); err == nil {
+		return nil
+	}
+	if err := s.runtime.RemoveArtifacts(project, name); err != nil {
+		s.logger.Warn("removing task artifacts",
+			zap.String("task", name),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
+
+// handleDeleteDevTaskCollection deletes the tasks of a project matching
+// ?labelSelector=, ?phase= (a comma-separated list of phases) and
+// ?olderThan= (a duration measured from when the task finished, or was
+// created if it has not). With ?dryRun=true nothing is deleted. The response
+// lists the names of the (would-be) deleted tasks and, under "pending", those
+// of them left for their finalizers to remove.
+func (s *Server) handleDeleteDevTaskCollection(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	project := q.Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+	sel, ok := s.labelSelector(w, r)
+	if !ok {
+		return
+	}
+
+	phases := make(map[v1alpha1.DevTaskPhase]bool)
+	if p := q.Get("phase"); p != "" {
+		for _, phase := range strings.Split(p, ",") {
+			phases[v1alpha1.DevTaskPhase(phase)] = true
+		}
+	}
+	var cutoff time.Time
+	if o := q.Get("olderThan"); o != "" {
+		d, err := time.ParseDuration(o)
+		if err != nil || d < 0 {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid olderThan %q", o))
+			return
+		}
+		cutoff = time.Now().Add(-d)
+	}
+	dryRun := q.Get("dryRun") != "" && q.Get("dryRun") != "false"
+
+	items, err := s.storeFor(r).List("/"+v1alpha1.KindDevTask+"/"+project+"/", func() interface{} { return &v1alpha1.DevTask{} })
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	deleted, pending := make([]string, 0), make([]string, 0)
+	for _, item := range items {
+		task := item.(*v1alpha1.DevTask)
+		if !sel.Matches(task.Metadata.Labels) {
+			continue
+		}
+		if len(phases) > 0 && !phases[task.Status.Phase] {
+			continue
+		}
+		if !cutoff.IsZero() {
+			at := task.Status.FinishedAt
+			if at.IsZero() {
+				at = task.Metadata.CreatedAt
+			}
+			if at.After(cutoff) {
+				continue
+			}
+		}
+
+		if !dryRun {
+			if err := s.deleteDevTask(r, project, task.Metadata.Name); err != nil && err != store.ErrNotFound {
+				s.writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			key := store.ResourceKey(v1alpha1.KindDevTask, project, task.Metadata.Name)
+			if s.storeFor(r).Get(key, &v1alpha1.DevTask{}) == nil {
+				pending = append(pending, task.Metadata.Name)
+			}
+		}
+		deleted = append(deleted, task.Metadata.Name)
+	}
+	sort.Strings(deleted)
+	sort.Strings(pending)
+
+	s.writeJSON(w, http.StatusOK, map[string][]string{"deleted": deleted, "pending": pending})
+}
+
+// handleRetryDevTask resets a Failed task to Pending, so it is scheduled
+// again like a new task.
+func (s *Server) handleRetryDevTask(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
 	key := store.ResourceKey(v1alpha1.KindDevTask, project, name)
 
-	if err := s.store.Delete(key); err != nil {
+	var task v1alpha1.DevTask
+	if err := s.storeFor(r).Get(key, &task); err != nil {
 		if err == store.ErrNotFound {
 			s.writeError(w, http.StatusNotFound, "devtask not found")
 			return
@@ -658,18 +959,339 @@ func (s *Server) handleDeleteDevTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	if task.Status.Phase != v1alpha1.TaskFailed {
+		s.writeError(w, http.StatusConflict, fmt.Sprintf("devtask is %s; only Failed devtasks can be retried", task.Status.Phase))
+		return
+	}
+
+	task.Status.Phase = v1alpha1.TaskPending
+	task.Status.AssignedPod = ""
+	task.Status.Error = ""
+	task.Status.Cancelled = false
+	task.Status.StartedAt = time.Time{}
+	task.Status.FinishedAt = time.Time{}
+	task.Status.Conditions = nil
+	task.Metadata.UpdatedAt = time.Now()
+
+	if err := s.storeFor(r).UpdateIfVersion(key, &task, task.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &task)
+}
+
+// handleCancelDevTask cancels a task that has not finished. A Running task's
+// execution is stopped and the task fails once it has; the response is 202
+// Accepted with the task as it is. Other tasks fail right away.
+func (s *Server) handleCancelDevTask(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindDevTask, project, name)
+
+	var task v1alpha1.DevTask
+	if err := s.storeFor(r).Get(key, &task); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "devtask not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	switch task.Status.Phase {
+	case v1alpha1.TaskSucceeded, v1alpha1.TaskFailed:
+		s.writeError(w, http.StatusConflict, fmt.Sprintf("devtask already %s", task.Status.Phase))
+		return
+	case v1alpha1.TaskRunning:
+		if s.runtime.CancelTask(project, name) {
+			s.writeJSON(w, http.StatusAccepted, &task)
+			return
+		}
+	}
+
+	// Not executing: nothing to stop, so fail the task here.
+	now := time.Now()
+	task.Status.Phase = v1alpha1.TaskFailed
+	task.Status.Error = agent.ErrTaskCancelled.Error()
+	task.Status.Cancelled = true
+	task.Status.FinishedAt = now
+	v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(
+		v1alpha1.ConditionComplete, v1alpha1.ConditionFalse, v1alpha1.ReasonCancelled, task.Status.Error))
+	task.Metadata.UpdatedAt = now
+
+	if err := s.storeFor(r).UpdateIfVersion(key, &task, task.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &task)
+}
+
+// ---------------------------------------------------------------------------
+// Events
+// ---------------------------------------------------------------------------
+
+// handleListEvents returns events oldest first. ?project= limits them to one
+// project (all projects when empty); ?kind= and ?name= limit them to one
+// involved object.
+func (s *Server) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	prefix := "/" + v1alpha1.KindEvent + "/"
+	if project := q.Get("project"); project != "" {
+		prefix += project + "/"
+	}
+
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.Event{} })
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	kind, name := q.Get("kind"), q.Get("name")
+	result := make([]*v1alpha1.Event, 0, len(items))
+	for _, item := range items {
+		ev := item.(*v1alpha1.Event)
+		if kind != "" && ev.InvolvedObject.Kind != kind {
+			continue
+		}
+		if name != "" && ev.InvolvedObject.Name != name {
+			continue
+		}
+		result = append(result, ev)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastTimestamp.Before(result[j].LastTimestamp)
+	})
+
+	s.writeJSON(w, http.StatusOK, result)
 }
 
 // ---------------------------------------------------------------------------
 // Logs
 // ---------------------------------------------------------------------------
 
-// handleGetLogs returns logs for an AgentPod.
-// A real implementation would read from a log store; for now we return an
-// empty slice since we don't have a log backend yet.
+// handleGetLogs returns a pod's recent log entries. ?tail=N keeps the last N
+// entries and ?since=<duration> drops entries older than the duration. With
+// ?follow=true the entries are streamed as server-sent events, followed by
+// new entries as they are recorded, until the client disconnects.
 func (s *Server) handleGetLogs(w http.ResponseWriter, r *http.Request) {
-	s.writeJSON(w, http.StatusOK, []v1alpha1.LogEntry{})
+	name := mux.Vars(r)["name"]
+	q := r.URL.Query()
+	project := q.Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	var opts agent.LogOptions
+	if tail := q.Get("tail"); tail != "" {
+		n, err := strconv.Atoi(tail)
+		if err != nil || n < 0 {
+			s.writeError(w, http.StatusBadRequest, "tail must be a non-negative integer")
+			return
+		}
+		opts.Tail = n
+	}
+	if since := q.Get("since"); since != "" {
+		d, err := time.ParseDuration(since)
+		if err != nil || d <= 0 {
+			s.writeError(w, http.StatusBadRequest, "since must be a positive duration, e.g. 10m")
+			return
+		}
+		opts.Since = time.Now().Add(-d)
+	}
+
+	var pod v1alpha1.AgentPod
+	if err := s.storeFor(r).Get(store.ResourceKey(v1alpha1.KindAgentPod, project, name), &pod); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "agentpod not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if follow := q.Get("follow"); follow == "" || follow == "false" {
+		s.writeJSON(w, http.StatusOK, s.runtime.Logs(project, name, opts))
+		return
+	}
+
+	backlog, entries, stop := s.runtime.FollowLogs(project, name, opts)
+	defer stop()
+
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, entry := range backlog {
+		if err := sse.send(entry); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case entry := <-entries:
+			err = sse.send(entry)
+		case <-ticker.C:
+			err = sse.keepAlive()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleStreamDevTask streams a task's steps as server-sent events while it
+// runs, ending with an event carrying the phase the attempt finished in. For
+// a task that already finished, the recorded steps and phase are sent.
+func (s *Server) handleStreamDevTask(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	// Follow before reading the task so that an attempt finishing in
+	// between is seen either in the store or on the stream.
+	backlog, events, stop := s.runtime.FollowTask(project, name)
+	defer stop()
+
+	var task v1alpha1.DevTask
+	if err := s.storeFor(r).Get(store.ResourceKey(v1alpha1.KindDevTask, project, name), &task); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "devtask not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if phase := task.Status.Phase; phase == v1alpha1.TaskSucceeded || phase == v1alpha1.TaskFailed {
+		for i := range task.Status.Steps {
+			if err := sse.send(v1alpha1.TaskStreamEvent{Step: &task.Status.Steps[i]}); err != nil {
+				return
+			}
+		}
+		_ = sse.send(v1alpha1.TaskStreamEvent{Phase: phase})
+		return
+	}
+
+	for i := range backlog {
+		if err := sse.send(v1alpha1.TaskStreamEvent{Step: &backlog[i]}); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			err = sse.send(ev)
+		case <-ticker.C:
+			err = sse.keepAlive()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Artifacts
+// ---------------------------------------------------------------------------
+
+// getTaskForArtifacts checks that the devtask named in the request exists,
+// writing an error response and returning false when it does not.
+func (s *Server) getTaskForArtifacts(w http.ResponseWriter, r *http.Request) (project, name string, ok bool) {
+	name = mux.Vars(r)["name"]
+	project = r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return "", "", false
+	}
+
+	var task v1alpha1.DevTask
+	if err := s.storeFor(r).Get(store.ResourceKey(v1alpha1.KindDevTask, project, name), &task); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "devtask not found")
+			return "", "", false
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return "", "", false
+	}
+	return project, name, true
+}
+
+// handleListArtifacts returns the files the task saved as artifacts.
+func (s *Server) handleListArtifacts(w http.ResponseWriter, r *http.Request) {
+	project, name, ok := s.getTaskForArtifacts(w, r)
+	if !ok {
+		return
+	}
+
+	artifacts, err := s.runtime.ListArtifacts(project, name)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, artifacts)
+}
+
+// handleGetArtifact returns the content of one of the task's artifacts.
+func (s *Server) handleGetArtifact(w http.ResponseWriter, r *http.Request) {
+	project, name, ok := s.getTaskForArtifacts(w, r)
+	if !ok {
+		return
+	}
+
+	artifact := mux.Vars(r)["artifact"]
+	f, err := s.runtime.OpenArtifact(project, name, artifact)
+	if err != nil {
+		switch {
+		case errors.Is(err, agent.ErrInvalidArtifactName):
+			s.writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, fs.ErrNotExist):
+			s.writeError(w, http.StatusNotFound, "artifact not found")
+		default:
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// Artifacts are served as downloads whatever their type.
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
 }
 
 // ---------------------------------------------------------------------------
@@ -692,6 +1314,13 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// With ?dryRun=true the request is fully processed but nothing is
+	// persisted; the response shows the object as it would be stored.
+	st := s.storeFor(r)
+	if dryRun := r.URL.Query().Get("dryRun"); dryRun != "" && dryRun != "false" {
+		st = dryRunStore{st}
+	}
+
 	now := time.Now()
 
 	switch meta.Kind {
@@ -704,10 +1333,13 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 
 		p.APIVersion = v1alpha1.APIVersion
 		p.Kind = v1alpha1.KindProject
+		if !s.validate(w, &p) {
+			return
+		}
 		key := store.ResourceKey(v1alpha1.KindProject, "", p.Metadata.Name)
 
 		var existing v1alpha1.Project
-		if err := s.store.Get(key, &existing); err == store.ErrNotFound {
+		if err := st.Get(key, &existing); err == store.ErrNotFound {
 			// Create
 			p.Metadata.UID = uuid.New().String()
 			p.Metadata.CreatedAt = now
@@ -715,7 +1347,7 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 			if p.Status == "" {
 				p.Status = "Active"
 			}
-			if err := s.store.Create(key, &p); err != nil {
+			if err := st.Create(key, &p); err != nil {
 				s.writeError(w, http.StatusInternalServerError, err.Error())
 				return
 			}
@@ -727,8 +1359,8 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 			p.Metadata.UID = existing.Metadata.UID
 			p.Metadata.CreatedAt = existing.Metadata.CreatedAt
 			p.Metadata.UpdatedAt = now
-			if err := s.store.Update(key, &p); err != nil {
-				s.writeError(w, http.StatusInternalServerError, err.Error())
+			if err := st.UpdateIfVersion(key, &p, p.Metadata.ResourceVersion); err != nil {
+				s.writeUpdateError(w, err)
 				return
 			}
 			s.writeJSON(w, http.StatusOK, &p)
@@ -741,23 +1373,22 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		project := pod.Metadata.Project
-		if project == "" {
-			s.writeError(w, http.StatusBadRequest, "metadata.project is required for AgentPod")
+		if !s.admit(w, r, &pod) {
 			return
 		}
+		project := pod.Metadata.Project
 
 		pod.APIVersion = v1alpha1.APIVersion
 		pod.Kind = v1alpha1.KindAgentPod
 		key := store.ResourceKey(v1alpha1.KindAgentPod, project, pod.Metadata.Name)
 
 		var existing v1alpha1.AgentPod
-		if err := s.store.Get(key, &existing); err == store.ErrNotFound {
+		if err := st.Get(key, &existing); err == store.ErrNotFound {
 			pod.Metadata.UID = uuid.New().String()
 			pod.Metadata.CreatedAt = now
 			pod.Metadata.UpdatedAt = now
 			pod.Status.Phase = v1alpha1.PodPending
-			if err := s.store.Create(key, &pod); err != nil {
+			if err := st.Create(key, &pod); err != nil {
 				s.writeError(w, http.StatusInternalServerError, err.Error())
 				return
 			}
@@ -768,8 +1399,16 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 			pod.Metadata.UID = existing.Metadata.UID
 			pod.Metadata.CreatedAt = existing.Metadata.CreatedAt
 			pod.Metadata.UpdatedAt = now
-			if err := s.store.Update(key, &pod); err != nil {
-				s.writeError(w, http.StatusInternalServerError, err.Error())
+			// As on PUT, finalizers and owner references the manifest
+			// leaves out are kept.
+			if pod.Metadata.Finalizers == nil {
+				pod.Metadata.Finalizers = existing.Metadata.Finalizers
+			}
+			if pod.Metadata.OwnerReferences == nil {
+				pod.Metadata.OwnerReferences = existing.Metadata.OwnerReferences
+			}
+			if err := st.UpdateIfVersion(key, &pod, pod.Metadata.ResourceVersion); err != nil {
+				s.writeUpdateError(w, err)
 				return
 			}
 			s.writeJSON(w, http.StatusOK, &pod)
@@ -782,25 +1421,24 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		project := pool.Metadata.Project
-		if project == "" {
-			s.writeError(w, http.StatusBadRequest, "metadata.project is required for AgentPool")
+		if !s.admit(w, r, &pool) {
 			return
 		}
+		project := pool.Metadata.Project
 
 		pool.APIVersion = v1alpha1.APIVersion
 		pool.Kind = v1alpha1.KindAgentPool
 		key := store.ResourceKey(v1alpha1.KindAgentPool, project, pool.Metadata.Name)
 
 		var existing v1alpha1.AgentPool
-		if err := s.store.Get(key, &existing); err == store.ErrNotFound {
+		if err := st.Get(key, &existing); err == store.ErrNotFound {
 			pool.Metadata.UID = uuid.New().String()
 			pool.Metadata.CreatedAt = now
 			pool.Metadata.UpdatedAt = now
 			pool.Status.Replicas = 0
 			pool.Status.ReadyReplicas = 0
 			pool.Status.BusyReplicas = 0
-			if err := s.store.Create(key, &pool); err != nil {
+			if err := st.Create(key, &pool); err != nil {
 				s.writeError(w, http.StatusInternalServerError, err.Error())
 				return
 			}
@@ -811,8 +1449,8 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 			pool.Metadata.UID = existing.Metadata.UID
 			pool.Metadata.CreatedAt = existing.Metadata.CreatedAt
 			pool.Metadata.UpdatedAt = now
-			if err := s.store.Update(key, &pool); err != nil {
-				s.writeError(w, http.StatusInternalServerError, err.Error())
+			if err := st.UpdateIfVersion(key, &pool, pool.Metadata.ResourceVersion); err != nil {
+				s.writeUpdateError(w, err)
 				return
 			}
 			s.writeJSON(w, http.StatusOK, &pool)
@@ -825,23 +1463,26 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		project := task.Metadata.Project
-		if project == "" {
-			s.writeError(w, http.StatusBadRequest, "metadata.project is required for DevTask")
+		if !s.admit(w, r, &task) {
 			return
 		}
+		project := task.Metadata.Project
 
 		task.APIVersion = v1alpha1.APIVersion
 		task.Kind = v1alpha1.KindDevTask
 		key := store.ResourceKey(v1alpha1.KindDevTask, project, task.Metadata.Name)
 
 		var existing v1alpha1.DevTask
-		if err := s.store.Get(key, &existing); err == store.ErrNotFound {
+		if err := st.Get(key, &existing); err == store.ErrNotFound {
+			if !s.admitNewTask(w, r, &task) {
+				return
+			}
 			task.Metadata.UID = uuid.New().String()
 			task.Metadata.CreatedAt = now
 			task.Metadata.UpdatedAt = now
 			task.Status.Phase = v1alpha1.TaskPending
-			if err := s.store.Create(key, &task); err != nil {
+			tracing.Inject(r.Context(), &task.Metadata)
+			if err := st.Create(key, &task); err != nil {
 				s.writeError(w, http.StatusInternalServerError, err.Error())
 				return
 			}
@@ -852,14 +1493,254 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 			task.Metadata.UID = existing.Metadata.UID
 			task.Metadata.CreatedAt = existing.Metadata.CreatedAt
 			task.Metadata.UpdatedAt = now
-			if err := s.store.Update(key, &task); err != nil {
-				s.writeError(w, http.StatusInternalServerError, err.Error())
+			// As on PUT, finalizers the manifest leaves out are kept.
+			if task.Metadata.Finalizers == nil {
+				task.Metadata.Finalizers = existing.Metadata.Finalizers
+			}
+			if err := st.UpdateIfVersion(key, &task, task.Metadata.ResourceVersion); err != nil {
+				s.writeUpdateError(w, err)
 				return
 			}
 			s.writeJSON(w, http.StatusOK, &task)
 		}
 
+	case v1alpha1.KindAgentPoolAutoscaler:
+		var as v1alpha1.AgentPoolAutoscaler
+		if err := json.Unmarshal(raw, &as); err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if !s.admit(w, r, &as) {
+			return
+		}
+		project := as.Metadata.Project
+		if err := as.Spec.Validate(); err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		as.APIVersion = v1alpha1.APIVersion
+		as.Kind = v1alpha1.KindAgentPoolAutoscaler
+		key := store.ResourceKey(v1alpha1.KindAgentPoolAutoscaler, project, as.Metadata.Name)
+
+		var existing v1alpha1.AgentPoolAutoscaler
+		if err := st.Get(key, &existing); err == store.ErrNotFound {
+			as.Metadata.UID = uuid.New().String()
+			as.Metadata.CreatedAt = now
+			as.Metadata.UpdatedAt = now
+			as.Status = v1alpha1.AgentPoolAutoscalerStatus{}
+			if err := st.Create(key, &as); err != nil {
+				s.writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			s.writeJSON(w, http.StatusCreated, &as)
+		} else if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+		} else {
+			as.Metadata.UID = existing.Metadata.UID
+			as.Metadata.CreatedAt = existing.Metadata.CreatedAt
+			as.Metadata.UpdatedAt = now
+			as.Status = existing.Status
+			if err := st.UpdateIfVersion(key, &as, as.Metadata.ResourceVersion); err != nil {
+				s.writeUpdateError(w, err)
+				return
+			}
+			s.writeJSON(w, http.StatusOK, &as)
+		}
+
+	case v1alpha1.KindSecret:
+		var sec v1alpha1.Secret
+		if err := json.Unmarshal(raw, &sec); err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if !s.admit(w, r, &sec) {
+			return
+		}
+		project := sec.Metadata.Project
+
+		sec.APIVersion = v1alpha1.APIVersion
+		sec.Kind = v1alpha1.KindSecret
+		if err := normalizeSecret(&sec); err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		key := store.ResourceKey(v1alpha1.KindSecret, project, sec.Metadata.Name)
+
+		var existing v1alpha1.Secret
+		if err := st.Get(key, &existing); err == store.ErrNotFound {
+			sec.Metadata.UID = uuid.New().String()
+			sec.Metadata.CreatedAt = now
+			sec.Metadata.UpdatedAt = now
+			if err := st.Create(key, &sec); err != nil {
+				s.writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			s.writeJSON(w, http.StatusCreated, &sec)
+		} else if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+		} else {
+			sec.Metadata.UID = existing.Metadata.UID
+			sec.Metadata.CreatedAt = existing.Metadata.CreatedAt
+			sec.Metadata.UpdatedAt = now
+			if err := st.UpdateIfVersion(key, &sec, sec.Metadata.ResourceVersion); err != nil {
+				s.writeUpdateError(w, err)
+				return
+			}
+			s.writeJSON(w, http.StatusOK, &sec)
+		}
+
+	case v1alpha1.KindConfigMap:
+		var cm v1alpha1.ConfigMap
+		if err := json.Unmarshal(raw, &cm); err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if !s.admit(w, r, &cm) {
+			return
+		}
+		project := cm.Metadata.Project
+
+		cm.APIVersion = v1alpha1.APIVersion
+		cm.Kind = v1alpha1.KindConfigMap
+		key := store.ResourceKey(v1alpha1.KindConfigMap, project, cm.Metadata.Name)
+
+		var existing v1alpha1.ConfigMap
+		if err := st.Get(key, &existing); err == store.ErrNotFound {
+			cm.Metadata.UID = uuid.New().String()
+			cm.Metadata.CreatedAt = now
+			cm.Metadata.UpdatedAt = now
+			if err := st.Create(key, &cm); err != nil {
+				s.writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			s.writeJSON(w, http.StatusCreated, &cm)
+		} else if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+		} else {
+			cm.Metadata.UID = existing.Metadata.UID
+			cm.Metadata.CreatedAt = existing.Metadata.CreatedAt
+			cm.Metadata.UpdatedAt = now
+			if err := st.UpdateIfVersion(key, &cm, cm.Metadata.ResourceVersion); err != nil {
+				s.writeUpdateError(w, err)
+				return
+			}
+			s.writeJSON(w, http.StatusOK, &cm)
+		}
+
+	case v1alpha1.KindModelProvider:
+		var mp v1alpha1.ModelProvider
+		if err := json.Unmarshal(raw, &mp); err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if !s.admit(w, r, &mp) {
+			return
+		}
+		project := mp.Metadata.Project
+
+		mp.APIVersion = v1alpha1.APIVersion
+		mp.Kind = v1alpha1.KindModelProvider
+		key := store.ResourceKey(v1alpha1.KindModelProvider, project, mp.Metadata.Name)
+
+		var existing v1alpha1.ModelProvider
+		if err := st.Get(key, &existing); err == store.ErrNotFound {
+			mp.Metadata.UID = uuid.New().String()
+			mp.Metadata.CreatedAt = now
+			mp.Metadata.UpdatedAt = now
+			if err := st.Create(key, &mp); err != nil {
+				s.writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			s.writeJSON(w, http.StatusCreated, &mp)
+		} else if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+		} else {
+			mp.Metadata.UID = existing.Metadata.UID
+			mp.Metadata.CreatedAt = existing.Metadata.CreatedAt
+			mp.Metadata.UpdatedAt = now
+			if err := st.UpdateIfVersion(key, &mp, mp.Metadata.ResourceVersion); err != nil {
+				s.writeUpdateError(w, err)
+				return
+			}
+			s.writeJSON(w, http.StatusOK, &mp)
+		}
+
+	case v1alpha1.KindToolDefinition:
+		var td v1alpha1.ToolDefinition
+		if err := json.Unmarshal(raw, &td); err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if !s.admit(w, r, &td) {
+			return
+		}
+		project := td.Metadata.Project
+
+		td.APIVersion = v1alpha1.APIVersion
+		td.Kind = v1alpha1.KindToolDefinition
+		key := store.ResourceKey(v1alpha1.KindToolDefinition, project, td.Metadata.Name)
+
+		var existing v1alpha1.ToolDefinition
+		if err := st.Get(key, &existing); err == store.ErrNotFound {
+			td.Metadata.UID = uuid.New().String()
+			td.Metadata.CreatedAt = now
+			td.Metadata.UpdatedAt = now
+			if err := st.Create(key, &td); err != nil {
+				s.writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			s.writeJSON(w, http.StatusCreated, &td)
+		} else if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err.Error())
+		} else {
+			td.Metadata.UID = existing.Metadata.UID
+			td.Metadata.CreatedAt = existing.Metadata.CreatedAt
+			td.Metadata.UpdatedAt = now
+			if err := st.UpdateIfVersion(key, &td, td.Metadata.ResourceVersion); err != nil {
+				s.writeUpdateError(w, err)
+				return
+			}
+			s.writeJSON(w, http.StatusOK, &td)
+		}
+
 	default:
 		s.writeError(w, http.StatusBadRequest, "unsupported kind: "+meta.Kind)
 	}
 }
+
+// normalizeSecret merges StringData into Data (base64 encoded) and verifies
+// that every Data value is valid base64, so the stored form is canonical.
+func normalizeSecret(sec *v1alpha1.Secret) error {
+	for k, v := range sec.Data {
+		if _, err := base64.StdEncoding.DecodeString(v); err != nil {
+			return fmt.Errorf("secret data %q is not valid base64: %v", k, err)
+		}
+	}
+	if len(sec.StringData) > 0 {
+		if sec.Data == nil {
+			sec.Data = make(map[string]string, len(sec.StringData))
+		}
+		for k, v := range sec.StringData {
+			sec.Data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+		}
+		sec.StringData = nil
+	}
+	return nil
+}
+
+// dryRunStore reads through to the wrapped store but discards writes.
+type dryRunStore struct {
+	store.Store
+}
+
+func (dryRunStore) Create(string, interface{}) error { return nil }
+func (dryRunStore) Update(string, interface{}) error { return nil }
+func (dryRunStore) Delete(string) error              { return nil }
+
+func (dryRunStore) UpdateIfVersion(string, interface{}, string) error { return nil }
@@ -0,0 +1,146 @@
+package apiserver
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/klubi/orca/internal/config"
+)
+
+// bucketIdle is how long a client's token bucket is kept after its last
+// request. A bucket idle that long has refilled anyway, unless its rate is
+// tiny.
+const bucketIdle = 10 * time.Minute
+
+// rateLimiter keeps a token bucket per client.
+type rateLimiter struct {
+	mu        sync.Mutex
+	cfg       config.RateLimitConfig
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// bucket holds the tokens a client has left as of last.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	return &rateLimiter{cfg: cfg, buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// setConfig replaces the limits. The clients keep the tokens they have
+// left, up to their new burst.
+func (l *rateLimiter) setConfig(cfg config.RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cfg = cfg
+}
+
+// allow takes a token from the bucket of client. If it has none left it
+// returns false and how long until it has one.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := l.cfg.For(client)
+	if !limit.Enabled() {
+		return true, 0
+	}
+	now := l.now()
+	if now.Sub(l.lastSweep) > bucketIdle {
+		for c, b := range l.buckets {
+			if now.Sub(b.last) > bucketIdle {
+				delete(l.buckets, c)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.RequestsPerSecond)
+	b.last = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / limit.RequestsPerSecond * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// SetRateLimit limits the rate at which each client makes requests, as cfg
+// says. Call it before Start; later calls change the limits of the running
+// server.
+func (s *Server) SetRateLimit(cfg config.RateLimitConfig) {
+	if s.limiter == nil {
+		s.limiter = newRateLimiter(cfg)
+		return
+	}
+	s.limiter.setConfig(cfg)
+}
+
+// SetMaxBodyBytes makes the server reject request bodies larger than n
+// bytes with 413 Request Entity Too Large. 0 lifts the cap.
+func (s *Server) SetMaxBodyBytes(n int64) {
+	s.maxBodyBytes = n
+}
+
+// unlimitedBodyPaths take bodies of any size.
+var unlimitedBodyPaths = map[string]bool{
+	apiPrefix + "/admin/restore": true,
+}
+
+// limitRequests is middleware that rejects the requests of clients over
+// their rate limit with 429 Too Many Requests, and caps the size of request
+// bodies. It runs after authenticate, so that the clients of an
+// authenticating server are its users.
+func (s *Server) limitRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unauthenticatedPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.limiter != nil {
+			if ok, wait := s.limiter.allow(requestClient(r)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				s.writeError(w, http.StatusTooManyRequests, "rate limit exceeded; slow down")
+				return
+			}
+		}
+		if s.maxBodyBytes > 0 && !unlimitedBodyPaths[r.URL.Path] {
+			if r.ContentLength > s.maxBodyBytes {
+				s.writeError(w, http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("request body of %d bytes is larger than the limit of %d", r.ContentLength, s.maxBodyBytes))
+				return
+			}
+			// Bodies of unknown length fail to read past the limit.
+			r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestClient identifies the client that made r for rate limiting: the
+// authenticated user, or else the remote IP address. X-Forwarded-For is
+// not trusted, since any client can set it.
+func requestClient(r *http.Request) string {
+	if user, ok := r.Context().Value(userKey{}).(string); ok {
+		return user
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,82 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(config.RateLimitConfig{
+		RateLimit: config.RateLimit{RequestsPerSecond: 2, Burst: 3},
+		Clients:   map[string]config.RateLimit{"ci": {}},
+	})
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("alice"); !ok {
+			t.Fatalf("request %d of the burst was limited", i+1)
+		}
+	}
+	ok, wait := l.allow("alice")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("allow() after the burst = %v, %s; want false, 500ms", ok, wait)
+	}
+	if ok, _ := l.allow("bob"); !ok {
+		t.Error("another client was limited")
+	}
+	for i := 0; i < 10; i++ {
+		if ok, _ := l.allow("ci"); !ok {
+			t.Fatal("a client without a limit was limited")
+		}
+	}
+
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("alice"); !ok {
+			t.Fatalf("request %d a second later was limited", i+1)
+		}
+	}
+	if ok, _ := l.allow("alice"); ok {
+		t.Error("bucket refilled beyond the rate")
+	}
+}
+
+func TestLimitRequests(t *testing.T) {
+	s := NewServer("", store.NewMemoryStore(), nil, zap.NewNop())
+	s.SetRateLimit(config.RateLimitConfig{RateLimit: config.RateLimit{RequestsPerSecond: 0.001, Burst: 2}})
+	s.SetMaxBodyBytes(64)
+
+	do := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		return rec
+	}
+	big := `{"metadata":{"name":"` + strings.Repeat("a", 64) + `"}}`
+	if rec := do("/api/v1alpha1/configmaps?project=web", big); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized body: %d %s, want 413", rec.Code, rec.Body)
+	}
+	if rec := do("/api/v1alpha1/configmaps?project=web", `{"metadata":{"name":"c"}}`); rec.Code != http.StatusCreated {
+		t.Errorf("create: %d %s, want 201", rec.Code, rec.Body)
+	}
+	rec := do("/api/v1alpha1/configmaps?project=web", `{"metadata":{"name":"d"}}`)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("request over the limit: %d %s with Retry-After %q, want 429 with one", rec.Code, rec.Body, rec.Header().Get("Retry-After"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec = httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("/healthz over the limit: %d, want 200", rec.Code)
+	}
+}
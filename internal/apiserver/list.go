@@ -0,0 +1,96 @@
+package apiserver
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/labels"
+)
+
+// continueHeader carries the token for the next page of a list response
+// that was cut short by ?limit=. The body stays a plain JSON array.
+const continueHeader = "X-Continue"
+
+// listQuery is how a list request narrows down and pages its results, from
+// the labelSelector, fieldSelector, limit and continue query parameters.
+type listQuery struct {
+	labels labels.Selector
+	fields labels.Selector
+	limit  int
+	// after is the key of the last object of the previous page.
+	after string
+}
+
+// listQuery parses the list parameters of r. It writes a 400 response and
+// returns false when one is malformed.
+func (s *Server) listQuery(w http.ResponseWriter, r *http.Request) (listQuery, bool) {
+	var q listQuery
+	sel, ok := s.labelSelector(w, r)
+	if !ok {
+		return q, false
+	}
+	q.labels = sel
+
+	params := r.URL.Query()
+	fields, err := labels.Parse(params.Get("fieldSelector"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid field selector: "+err.Error())
+		return q, false
+	}
+	q.fields = fields
+
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit %q", v))
+			return q, false
+		}
+		q.limit = n
+	}
+
+	if v := params.Get("continue"); v != "" {
+		after, err := base64.RawURLEncoding.DecodeString(v)
+		if err != nil || len(after) == 0 {
+			s.writeError(w, http.StatusBadRequest, "invalid continue token")
+			return q, false
+		}
+		q.after = string(after)
+	}
+	return q, true
+}
+
+// writeList writes the objects of items, which hold *T, that match q,
+// ordered by project and name. When q.limit cuts the list short, the token
+// for the next page is set in the X-Continue header.
+func writeList[T any](s *Server, w http.ResponseWriter, q listQuery, items []interface{}, meta func(*T) *v1alpha1.ObjectMeta) {
+	key := func(obj *T) string {
+		m := meta(obj)
+		return m.Project + "/" + m.Name
+	}
+
+	out := make([]*T, 0, len(items))
+	for _, item := range items {
+		obj := item.(*T)
+		if !q.labels.Matches(meta(obj).Labels) {
+			continue
+		}
+		if q.after != "" && key(obj) <= q.after {
+			continue
+		}
+		if !q.fields.Empty() && !q.fields.Matches(labels.Fields(obj)) {
+			continue
+		}
+		out = append(out, obj)
+	}
+	sort.Slice(out, func(i, j int) bool { return key(out[i]) < key(out[j]) })
+
+	if q.limit > 0 && len(out) > q.limit {
+		out = out[:q.limit]
+		w.Header().Set(continueHeader, base64.RawURLEncoding.EncodeToString([]byte(key(out[len(out)-1]))))
+	}
+	s.writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,83 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestListDevTasksFieldSelectorAndPaging(t *testing.T) {
+	st := store.NewMemoryStore()
+	for _, tc := range []struct {
+		name  string
+		phase v1alpha1.DevTaskPhase
+	}{
+		{"e", v1alpha1.TaskPending},
+		{"a", v1alpha1.TaskPending},
+		{"d", v1alpha1.TaskRunning},
+		{"c", v1alpha1.TaskPending},
+		{"b", v1alpha1.TaskPending},
+	} {
+		var task v1alpha1.DevTask
+		task.Metadata.Name = tc.name
+		task.Metadata.Project = "default"
+		task.Status.Phase = tc.phase
+		if err := st.Create(store.ResourceKey(v1alpha1.KindDevTask, "default", tc.name), &task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Server{store: st, logger: zap.NewNop()}
+
+	list := func(query string) ([]string, string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.handleListDevTasks(rec, httptest.NewRequest(http.MethodGet, "/api/v1alpha1/devtasks?"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list %q: status %d: %s", query, rec.Code, rec.Body)
+		}
+		var tasks []v1alpha1.DevTask
+		if err := json.NewDecoder(rec.Body).Decode(&tasks); err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, len(tasks))
+		for i, task := range tasks {
+			names[i] = task.Metadata.Name
+		}
+		return names, rec.Header().Get(continueHeader)
+	}
+
+	var all []string
+	token := ""
+	pages := 0
+	for {
+		names, next := list("fieldSelector=status.phase%3DPending&limit=2&continue=" + token)
+		all = append(all, names...)
+		pages++
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	if want := "[a b c e]"; pages != 2 || fmt.Sprint(all) != want {
+		t.Errorf("paged list = %v in %d pages, want %s in 2", all, pages, want)
+	}
+
+	if names, next := list("fieldSelector=metadata.name!%3Da,status.phase!%3DRunning"); fmt.Sprint(names) != "[b c e]" || next != "" {
+		t.Errorf("field selector list = %v (continue %q), want [b c e]", names, next)
+	}
+
+	for _, bad := range []string{"limit=-1", "limit=x", "continue=%25%25", "fieldSelector=a%3Db%3Dc"} {
+		rec := httptest.NewRecorder()
+		s.handleListDevTasks(rec, httptest.NewRequest(http.MethodGet, "/api/v1alpha1/devtasks?"+bad, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("list %q: status %d, want 400", bad, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// ---------------------------------------------------------------------------
+// ModelProviders
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleCreateModelProvider(w http.ResponseWriter, r *http.Request) {
+	var mp v1alpha1.ModelProvider
+	if err := json.NewDecoder(r.Body).Decode(&mp); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if project := r.URL.Query().Get("project"); project != "" {
+		mp.Metadata.Project = project
+	}
+	if !s.admit(w, r, &mp) {
+		return
+	}
+	project := mp.Metadata.Project
+
+	mp.APIVersion = v1alpha1.APIVersion
+	mp.Kind = v1alpha1.KindModelProvider
+	mp.Metadata.Project = project
+	mp.Metadata.UID = uuid.New().String()
+	now := time.Now()
+	mp.Metadata.CreatedAt = now
+	mp.Metadata.UpdatedAt = now
+
+	key := store.ResourceKey(v1alpha1.KindModelProvider, project, mp.Metadata.Name)
+	if err := s.storeFor(r).Create(key, &mp); err != nil {
+		if err == store.ErrAlreadyExists {
+			s.writeError(w, http.StatusConflict, "model provider already exists")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, &mp)
+}
+
+func (s *Server) handleGetModelProvider(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindModelProvider, project, name)
+
+	var mp v1alpha1.ModelProvider
+	if err := s.storeFor(r).Get(key, &mp); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "model provider not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &mp)
+}
+
+func (s *Server) handleListModelProviders(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("project")
+	q, ok := s.listQuery(w, r)
+	if !ok {
+		return
+	}
+
+	prefix := "/" + v1alpha1.KindModelProvider + "/"
+	if project != "" {
+		prefix += project + "/"
+	}
+
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.ModelProvider{} })
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeList(s, w, q, items, func(obj *v1alpha1.ModelProvider) *v1alpha1.ObjectMeta { return &obj.Metadata })
+}
+
+func (s *Server) handleUpdateModelProvider(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindModelProvider, project, name)
+
+	var existing v1alpha1.ModelProvider
+	if err := s.storeFor(r).Get(key, &existing); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "model provider not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var mp v1alpha1.ModelProvider
+	if err := json.NewDecoder(r.Body).Decode(&mp); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mp.APIVersion = v1alpha1.APIVersion
+	mp.Kind = v1alpha1.KindModelProvider
+	mp.Metadata.Name = name
+	mp.Metadata.Project = project
+	mp.Metadata.UID = existing.Metadata.UID
+	mp.Metadata.CreatedAt = existing.Metadata.CreatedAt
+	mp.Metadata.UpdatedAt = time.Now()
+	if !s.admit(w, r, &mp) {
+		return
+	}
+
+	if err := s.storeFor(r).UpdateIfVersion(key, &mp, mp.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &mp)
+}
+
+func (s *Server) handleDeleteModelProvider(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindModelProvider, project, name)
+
+	if err := s.storeFor(r).Delete(key); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "model provider not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,45 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestUpdateKeepsOwnerReferences(t *testing.T) {
+	st := store.NewMemoryStore()
+	key := store.ResourceKey(v1alpha1.KindAgentPod, "web", "pool-1")
+	owner := v1alpha1.OwnerReference{Kind: v1alpha1.KindAgentPool, Name: "pool", UID: "u1", Controller: true}
+	pod := &v1alpha1.AgentPod{
+		Metadata: v1alpha1.ObjectMeta{Name: "pool-1", Project: "web", OwnerReferences: []v1alpha1.OwnerReference{owner}},
+		Spec:     v1alpha1.AgentPodSpec{Model: "claude-sonnet"},
+	}
+	if err := st.Create(key, pod); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer("", st, nil, zap.NewNop())
+
+	for _, req := range []struct{ method, path, body string }{
+		{http.MethodPut, "/api/v1alpha1/agentpods/pool-1?project=web", `{"metadata":{"name":"pool-1"},"spec":{"model":"claude-sonnet"}}`},
+		{http.MethodPost, "/api/v1alpha1/apply", `{"kind":"AgentPod","metadata":{"name":"pool-1","project":"web"},"spec":{"model":"claude-sonnet"}}`},
+	} {
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, httptest.NewRequest(req.method, req.path, strings.NewReader(req.body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %s: %d %s", req.method, req.path, rec.Code, rec.Body)
+		}
+		var got v1alpha1.AgentPod
+		if err := st.Get(key, &got); err != nil {
+			t.Fatal(err)
+		}
+		if ref := got.Metadata.ControllerRef(); ref == nil || *ref != owner {
+			t.Errorf("after %s %s, owner references are %+v, want %+v", req.method, req.path, got.Metadata.OwnerReferences, owner)
+		}
+	}
+}
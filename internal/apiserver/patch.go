@@ -0,0 +1,399 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// Patch content types accepted by the PATCH endpoints. Plain
+// application/json is treated as a merge patch.
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// handlePatch returns a handler that applies a JSON merge patch (RFC 7386) or
+// JSON patch (RFC 6902) to a stored resource of the given kind. Identity
+// fields (apiVersion, kind, name, project, uid, createdAt) cannot be changed.
+func (s *Server) handlePatch(kind string, newObj func() interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["name"]
+		project := r.URL.Query().Get("project")
+		if !v1alpha1.ClusterScoped(kind) && project == "" {
+			s.writeError(w, http.StatusBadRequest, "project query param is required")
+			return
+		}
+
+		key := store.ResourceKey(kind, project, name)
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// The patch is applied to the stored object again if that changes
+		// before it is written, unless the patch sets the resourceVersion
+		// the object had.
+		for attempt := 1; ; attempt++ {
+			var doc map[string]interface{}
+			if err := s.storeFor(r).Get(key, &doc); err != nil {
+				if err == store.ErrNotFound {
+					s.writeError(w, http.StatusNotFound, strings.ToLower(kind)+" not found")
+					return
+				}
+				s.writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			// Patches modify the document in place; keep the stored identity.
+			orig := deepCopy(doc).(map[string]interface{})
+
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			var patched interface{}
+			switch mediaType {
+			case jsonPatchType:
+				var ops []patchOp
+				if err := json.Unmarshal(body, &ops); err != nil {
+					s.writeError(w, http.StatusBadRequest, "invalid JSON patch: "+err.Error())
+					return
+				}
+				patched, err = applyJSONPatch(doc, ops)
+				if err != nil {
+					s.writeError(w, http.StatusUnprocessableEntity, err.Error())
+					return
+				}
+			case mergePatchType, "application/json", "":
+				var patch interface{}
+				if err := json.Unmarshal(body, &patch); err != nil {
+					s.writeError(w, http.StatusBadRequest, "invalid merge patch: "+err.Error())
+					return
+				}
+				patched = applyMergePatch(doc, patch)
+			default:
+				s.writeError(w, http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported patch type %q", mediaType))
+				return
+			}
+
+			out, ok := patched.(map[string]interface{})
+			if !ok {
+				s.writeError(w, http.StatusUnprocessableEntity, "patch must produce an object")
+				return
+			}
+			version := resourceVersion(out)
+			if version != resourceVersion(orig) {
+				s.writeUpdateError(w, store.ErrConflict)
+				return
+			}
+			restoreIdentity(out, orig)
+
+			raw, err := json.Marshal(out)
+			if err != nil {
+				s.writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			obj := newObj()
+			if err := json.Unmarshal(raw, obj); err != nil {
+				s.writeError(w, http.StatusUnprocessableEntity, "patched object is invalid: "+err.Error())
+				return
+			}
+			if sec, ok := obj.(*v1alpha1.Secret); ok {
+				if err := normalizeSecret(sec); err != nil {
+					s.writeError(w, http.StatusUnprocessableEntity, err.Error())
+					return
+				}
+			}
+			// Project-scoped objects get their project's defaults and
+			// policy, as on PUT.
+			if o, ok := obj.(v1alpha1.Object); ok && !v1alpha1.ClusterScoped(kind) {
+				if !s.admit(w, r, o) {
+					return
+				}
+			} else if !s.validate(w, obj) {
+				return
+			}
+
+			err = s.storeFor(r).UpdateIfVersion(key, obj, version)
+			if err == store.ErrConflict && attempt < conflictRetries {
+				continue
+			}
+			if err != nil {
+				s.writeUpdateError(w, err)
+				return
+			}
+			s.writeJSON(w, http.StatusOK, obj)
+			return
+		}
+	}
+}
+
+// restoreIdentity copies the immutable fields of orig into patched and
+// stamps a new updatedAt.
+func restoreIdentity(patched, orig map[string]interface{}) {
+	patched["apiVersion"] = orig["apiVersion"]
+	patched["kind"] = orig["kind"]
+
+	origMeta, _ := orig["metadata"].(map[string]interface{})
+	meta, ok := patched["metadata"].(map[string]interface{})
+	if !ok {
+		meta = make(map[string]interface{})
+		patched["metadata"] = meta
+	}
+	for _, field := range []string{"name", "project", "uid", "createdAt"} {
+		if v, ok := origMeta[field]; ok {
+			meta[field] = v
+		} else {
+			delete(meta, field)
+		}
+	}
+	meta["updatedAt"] = time.Now()
+}
+
+// applyMergePatch implements RFC 7386: objects are merged recursively, null
+// deletes a member and any other value replaces the target.
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = applyMergePatch(targetObj[k], v)
+	}
+	return targetObj
+}
+
+// patchOp is a single RFC 6902 operation.
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// applyJSONPatch applies ops to doc in order. It supports add, remove,
+// replace, move, copy and test; the first failing operation aborts the patch.
+func applyJSONPatch(doc interface{}, ops []patchOp) (interface{}, error) {
+	for i, op := range ops {
+		var err error
+		switch op.Op {
+		case "add", "replace", "test":
+			var value interface{}
+			if len(op.Value) == 0 {
+				return nil, fmt.Errorf("operation %d (%s): missing value", i, op.Op)
+			}
+			if err = json.Unmarshal(op.Value, &value); err != nil {
+				return nil, fmt.Errorf("operation %d (%s): invalid value: %w", i, op.Op, err)
+			}
+			switch op.Op {
+			case "add":
+				doc, err = patchAdd(doc, op.Path, value)
+			case "replace":
+				if doc, _, err = patchRemove(doc, op.Path); err == nil {
+					doc, err = patchAdd(doc, op.Path, value)
+				}
+			case "test":
+				var current interface{}
+				if current, err = patchGet(doc, op.Path); err == nil && !jsonEqual(current, value) {
+					err = fmt.Errorf("test failed at %s", op.Path)
+				}
+			}
+		case "remove":
+			doc, _, err = patchRemove(doc, op.Path)
+		case "move":
+			var value interface{}
+			if doc, value, err = patchRemove(doc, op.From); err == nil {
+				doc, err = patchAdd(doc, op.Path, value)
+			}
+		case "copy":
+			var value interface{}
+			if value, err = patchGet(doc, op.From); err == nil {
+				doc, err = patchAdd(doc, op.Path, deepCopy(value))
+			}
+		default:
+			err = fmt.Errorf("unknown op %q", op.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+// splitPointer decodes an RFC 6901 JSON pointer into its reference tokens.
+func splitPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", ptr)
+	}
+	parts := strings.Split(ptr[1:], "/")
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~1", "/"), "~0", "~")
+	}
+	return parts, nil
+}
+
+// patchGet returns the value at ptr.
+func patchGet(doc interface{}, ptr string) (interface{}, error) {
+	parts, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	cur := doc
+	for _, p := range parts {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			v, ok := node[p]
+			if !ok {
+				return nil, fmt.Errorf("path %s not found", ptr)
+			}
+			cur = v
+		case []interface{}:
+			idx, err := strconv.Atoi(p)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, fmt.Errorf("invalid array index %q in %s", p, ptr)
+			}
+			cur = node[idx]
+		default:
+			return nil, fmt.Errorf("path %s not found", ptr)
+		}
+	}
+	return cur, nil
+}
+
+// patchAdd inserts value at ptr and returns the (possibly new) document.
+func patchAdd(doc interface{}, ptr string, value interface{}) (interface{}, error) {
+	parts, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return value, nil
+	}
+	parent, err := patchGet(doc, joinPointer(parts[:len(parts)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := parts[len(parts)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return doc, nil
+	case []interface{}:
+		idx := len(node)
+		if last != "-" {
+			idx, err = strconv.Atoi(last)
+			if err != nil || idx < 0 || idx > len(node) {
+				return nil, fmt.Errorf("invalid array index %q", last)
+			}
+		}
+		node = append(node, nil)
+		copy(node[idx+1:], node[idx:])
+		node[idx] = value
+		return patchSet(doc, parts[:len(parts)-1], node)
+	default:
+		return nil, fmt.Errorf("parent of %s is not a container", ptr)
+	}
+}
+
+// patchRemove deletes the value at ptr, returning the document and the
+// removed value.
+func patchRemove(doc interface{}, ptr string) (interface{}, interface{}, error) {
+	parts, err := splitPointer(ptr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(parts) == 0 {
+		return nil, doc, nil
+	}
+	parent, err := patchGet(doc, joinPointer(parts[:len(parts)-1]))
+	if err != nil {
+		return nil, nil, err
+	}
+	last := parts[len(parts)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		v, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("path %s not found", ptr)
+		}
+		delete(node, last)
+		return doc, v, nil
+	case []interface{}:
+		idx, err := strconv.Atoi(last)
+		if err != nil || idx < 0 || idx >= len(node) {
+			return nil, nil, fmt.Errorf("invalid array index %q", last)
+		}
+		v := node[idx]
+		node = append(node[:idx:idx], node[idx+1:]...)
+		doc, err = patchSet(doc, parts[:len(parts)-1], node)
+		return doc, v, err
+	default:
+		return nil, nil, fmt.Errorf("path %s not found", ptr)
+	}
+}
+
+// patchSet replaces the value at the location given by parts. It is used to
+// store arrays whose length changed.
+func patchSet(doc interface{}, parts []string, value interface{}) (interface{}, error) {
+	if len(parts) == 0 {
+		return value, nil
+	}
+	parent, err := patchGet(doc, joinPointer(parts[:len(parts)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := parts[len(parts)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+	case []interface{}:
+		idx, _ := strconv.Atoi(last)
+		node[idx] = value
+	}
+	return doc, nil
+}
+
+func joinPointer(parts []string) string {
+	if len(parts) == 0 {
+		return ""
+	}
+	escaped := make([]string, len(parts))
+	for i, p := range parts {
+		escaped[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~", "~0"), "/", "~1")
+	}
+	return "/" + strings.Join(escaped, "/")
+}
+
+func deepCopy(v interface{}) interface{} {
+	raw, _ := json.Marshal(v)
+	var out interface{}
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+func jsonEqual(a, b interface{}) bool {
+	aj, err1 := json.Marshal(a)
+	bj, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(aj) == string(bj)
+}
@@ -0,0 +1,61 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("bad JSON %q: %v", s, err)
+	}
+	return v
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	doc := decode(t, `{"spec":{"replicas":1,"selector":{"app":"x"},"tools":["a"]},"status":{"ready":1}}`)
+	patch := decode(t, `{"spec":{"replicas":4,"selector":null,"tools":["b","c"]}}`)
+
+	got := applyMergePatch(doc, patch)
+	want := decode(t, `{"spec":{"replicas":4,"tools":["b","c"]},"status":{"ready":1}}`)
+	if !jsonEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := decode(t, `{"spec":{"replicas":1,"tools":["a","c"]},"metadata":{"labels":{"x/y":"1"}}}`)
+
+	var ops []patchOp
+	if err := json.Unmarshal([]byte(`[
+		{"op":"test","path":"/spec/replicas","value":1},
+		{"op":"replace","path":"/spec/replicas","value":3},
+		{"op":"add","path":"/spec/tools/1","value":"b"},
+		{"op":"add","path":"/spec/tools/-","value":"d"},
+		{"op":"remove","path":"/metadata/labels/x~1y"},
+		{"op":"copy","from":"/spec/replicas","path":"/spec/min"},
+		{"op":"move","from":"/spec/min","path":"/spec/max"}
+	]`), &ops); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := applyJSONPatch(doc, ops)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := decode(t, `{"spec":{"replicas":3,"max":3,"tools":["a","b","c","d"]},"metadata":{"labels":{}}}`)
+	if !jsonEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	failing := []patchOp{{Op: "test", Path: "/spec/replicas", Value: json.RawMessage(`99`)}}
+	if _, err := applyJSONPatch(got, failing); err == nil {
+		t.Error("expected failed test operation to abort the patch")
+	}
+	missing := []patchOp{{Op: "remove", Path: "/spec/nope"}}
+	if _, err := applyJSONPatch(got, missing); err == nil {
+		t.Error("expected error removing a missing path")
+	}
+}
@@ -0,0 +1,80 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestDeleteProjectPropagation(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Store.DataDir = t.TempDir()
+	executor := agent.NewExecutor(cfg.Agent.ClaudeCLI, zap.NewNop())
+	defer executor.Close()
+
+	for _, tc := range []struct {
+		query      string
+		status     int
+		wantOrphan bool
+	}{
+		{"", http.StatusNoContent, false},
+		{"?propagation=delete", http.StatusNoContent, false},
+		{"?propagation=orphan", http.StatusNoContent, true},
+		{"?propagation=foreground", http.StatusBadRequest, true},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			st := store.NewMemoryStore()
+			s := NewServer("", st, agent.NewRuntime(st, executor, cfg, zap.NewNop()), zap.NewNop())
+			children := map[string]interface{}{
+				store.ResourceKey(v1alpha1.KindDevTask, "web", "t"):   &v1alpha1.DevTask{Metadata: v1alpha1.ObjectMeta{Name: "t", Project: "web"}},
+				store.ResourceKey(v1alpha1.KindAgentPool, "web", "p"): &v1alpha1.AgentPool{Metadata: v1alpha1.ObjectMeta{Name: "p", Project: "web"}},
+				store.ResourceKey(v1alpha1.KindAgentPod, "web", "p1"): &v1alpha1.AgentPod{Metadata: v1alpha1.ObjectMeta{Name: "p1", Project: "web"}},
+			}
+			other := store.ResourceKey(v1alpha1.KindAgentPod, "webapp", "p1")
+			objects := map[string]interface{}{
+				store.ResourceKey(v1alpha1.KindProject, "", "web"): &v1alpha1.Project{Metadata: v1alpha1.ObjectMeta{Name: "web"}},
+				other: &v1alpha1.AgentPod{Metadata: v1alpha1.ObjectMeta{Name: "p1", Project: "webapp"}},
+			}
+			for key, obj := range children {
+				objects[key] = obj
+			}
+			for key, obj := range objects {
+				if err := st.Create(key, obj); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rec := httptest.NewRecorder()
+			s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1alpha1/projects/web"+tc.query, nil))
+			if rec.Code != tc.status {
+				t.Fatalf("delete: %d %s, want %d", rec.Code, rec.Body, tc.status)
+			}
+			for key := range children {
+				err := st.Get(key, &struct{}{})
+				if tc.wantOrphan && err != nil {
+					t.Errorf("%s: %v, want it kept", key, err)
+				}
+				if !tc.wantOrphan && err != store.ErrNotFound {
+					t.Errorf("%s: %v, want it deleted", key, err)
+				}
+			}
+			if err := st.Get(other, &struct{}{}); err != nil {
+				t.Errorf("pod of another project: %v, want it kept", err)
+			}
+		})
+	}
+
+	s := NewServer("", store.NewMemoryStore(), nil, zap.NewNop())
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1alpha1/projects/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("delete of a missing project: %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,89 @@
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// requestIDHeader carries the ID of a request, in both directions: a
+// client may choose the ID, and the server reports the one it used.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the length of the longest client-chosen request ID
+// the server keeps; longer ones are replaced.
+const maxRequestIDLength = 128
+
+// requestInfo is what the request log records about a request beyond its
+// method, path and status. Later middleware fill it in.
+type requestInfo struct {
+	id   string
+	user string
+}
+
+type requestInfoKey struct{}
+
+// requestID returns the ID of the request made with ctx, or "" outside a
+// request.
+func requestID(ctx context.Context) string {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// logRequests is middleware that gives each request an ID, taken from its
+// X-Request-ID header if it has a usable one, returns the ID in the same
+// header and logs the request once it is served: reads at debug level,
+// writes at info and server errors at error level.
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		info := &requestInfo{id: r.Header.Get(requestIDHeader)}
+		if !validRequestID(info.id) {
+			info.id = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, info.id)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+
+		level := zapcore.InfoLevel
+		switch {
+		case rec.status >= 500:
+			level = zapcore.ErrorLevel
+		case r.Method == http.MethodGet || r.Method == http.MethodHead:
+			level = zapcore.DebugLevel
+		}
+		fields := []zap.Field{
+			zap.String("requestID", info.id),
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", rec.status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("remote", r.RemoteAddr),
+		}
+		if info.user != "" {
+			fields = append(fields, zap.String("user", info.user))
+		}
+		s.logger.Log(level, "request served", fields...)
+	})
+}
+
+// validRequestID reports whether id, chosen by a client, is safe to log and
+// echo: printable ASCII without spaces, and not too long.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestLogRequests(t *testing.T) {
+	st := store.NewMemoryStore()
+	core, logs := observer.New(zap.DebugLevel)
+	s := NewServer("", st, nil, zap.New(core))
+	events, cancel := st.Watch("/" + v1alpha1.KindConfigMap + "/")
+	defer cancel()
+
+	create := func(name, requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1alpha1/configmaps?project=web",
+			strings.NewReader(`{"metadata":{"name":"`+name+`"}}`))
+		if requestID != "" {
+			req.Header.Set(requestIDHeader, requestID)
+		}
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create %s: %d %s", name, rec.Code, rec.Body)
+		}
+		return rec
+	}
+
+	rec := create("a", "")
+	id := rec.Header().Get(requestIDHeader)
+	if id == "" {
+		t.Fatal("response has no X-Request-ID")
+	}
+	select {
+	case evt := <-events:
+		if evt.RequestID != id {
+			t.Errorf("watch event has request ID %q, want %q", evt.RequestID, id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no watch event for the created configmap")
+	}
+
+	if got := create("b", "client-chosen-1").Header().Get(requestIDHeader); got != "client-chosen-1" {
+		t.Errorf("X-Request-ID = %q, want the client's", got)
+	}
+	if got := create("c", "bad id\n").Header().Get(requestIDHeader); got == "bad id\n" || got == "" {
+		t.Errorf("X-Request-ID = %q, want a fresh one for an unusable header", got)
+	}
+
+	served := logs.FilterMessage("request served").AllUntimed()
+	if len(served) != 3 {
+		t.Fatalf("%d requests logged, want 3", len(served))
+	}
+	fields := served[0].ContextMap()
+	if fields["requestID"] != id || fields["method"] != http.MethodPost || fields["status"] != int64(http.StatusCreated) {
+		t.Errorf("request log fields = %v", fields)
+	}
+}
@@ -1,44 +1,136 @@
 package apiserver
 
+import v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+
 // registerRoutes wires every API endpoint to its handler.
 func (s *Server) registerRoutes() {
-	api := s.router.PathPrefix("/api/v1alpha1").Subrouter()
+	api := s.router.PathPrefix(apiPrefix).Subrouter()
 
 	// Health
 	s.router.HandleFunc("/healthz", s.handleHealthz).Methods("GET")
+	s.router.HandleFunc("/readyz", s.handleReadyz).Methods("GET")
+
+	// Discovery
+	s.router.HandleFunc("/version", s.handleVersion).Methods("GET")
+	s.router.HandleFunc("/openapi", s.handleOpenAPI).Methods("GET")
 
 	// Projects
 	api.HandleFunc("/projects", s.handleListProjects).Methods("GET")
 	api.HandleFunc("/projects/{name}", s.handleGetProject).Methods("GET")
 	api.HandleFunc("/projects", s.handleCreateProject).Methods("POST")
 	api.HandleFunc("/projects/{name}", s.handleUpdateProject).Methods("PUT")
+	api.HandleFunc("/projects/{name}", s.handlePatch(v1alpha1.KindProject, func() interface{} { return &v1alpha1.Project{} })).Methods("PATCH")
 	api.HandleFunc("/projects/{name}", s.handleDeleteProject).Methods("DELETE")
+	api.HandleFunc("/projects/{name}/status", s.handleStatus(v1alpha1.KindProject, func() interface{} { return &v1alpha1.Project{} })).Methods("GET", "PUT")
 
 	// AgentPods - scoped by project query param: ?project=xxx
 	api.HandleFunc("/agentpods", s.handleListAgentPods).Methods("GET")
 	api.HandleFunc("/agentpods/{name}", s.handleGetAgentPod).Methods("GET")
 	api.HandleFunc("/agentpods", s.handleCreateAgentPod).Methods("POST")
 	api.HandleFunc("/agentpods/{name}", s.handleUpdateAgentPod).Methods("PUT")
+	api.HandleFunc("/agentpods/{name}", s.handlePatch(v1alpha1.KindAgentPod, func() interface{} { return &v1alpha1.AgentPod{} })).Methods("PATCH")
 	api.HandleFunc("/agentpods/{name}", s.handleDeleteAgentPod).Methods("DELETE")
+	api.HandleFunc("/agentpods", s.handleDeleteCollection(v1alpha1.KindAgentPod)).Methods("DELETE")
+	api.HandleFunc("/agentpods/{name}/status", s.handleStatus(v1alpha1.KindAgentPod, func() interface{} { return &v1alpha1.AgentPod{} })).Methods("GET", "PUT")
 
 	// AgentPools
 	api.HandleFunc("/agentpools", s.handleListAgentPools).Methods("GET")
 	api.HandleFunc("/agentpools/{name}", s.handleGetAgentPool).Methods("GET")
 	api.HandleFunc("/agentpools", s.handleCreateAgentPool).Methods("POST")
 	api.HandleFunc("/agentpools/{name}", s.handleUpdateAgentPool).Methods("PUT")
+	api.HandleFunc("/agentpools/{name}", s.handlePatch(v1alpha1.KindAgentPool, func() interface{} { return &v1alpha1.AgentPool{} })).Methods("PATCH")
 	api.HandleFunc("/agentpools/{name}", s.handleDeleteAgentPool).Methods("DELETE")
+	api.HandleFunc("/agentpools", s.handleDeleteCollection(v1alpha1.KindAgentPool)).Methods("DELETE")
+	api.HandleFunc("/agentpools/{name}/status", s.handleStatus(v1alpha1.KindAgentPool, func() interface{} { return &v1alpha1.AgentPool{} })).Methods("GET", "PUT")
 	api.HandleFunc("/agentpools/{name}/scale", s.handleScaleAgentPool).Methods("PUT")
 
+	// AgentPoolAutoscalers
+	api.HandleFunc("/autoscalers", s.handleListAutoscalers).Methods("GET")
+	api.HandleFunc("/autoscalers/{name}", s.handleGetAutoscaler).Methods("GET")
+	api.HandleFunc("/autoscalers", s.handleCreateAutoscaler).Methods("POST")
+	api.HandleFunc("/autoscalers/{name}", s.handleUpdateAutoscaler).Methods("PUT")
+	api.HandleFunc("/autoscalers/{name}", s.handlePatch(v1alpha1.KindAgentPoolAutoscaler, func() interface{} { return &v1alpha1.AgentPoolAutoscaler{} })).Methods("PATCH")
+	api.HandleFunc("/autoscalers/{name}", s.handleDeleteAutoscaler).Methods("DELETE")
+	api.HandleFunc("/autoscalers", s.handleDeleteCollection(v1alpha1.KindAgentPoolAutoscaler)).Methods("DELETE")
+	api.HandleFunc("/autoscalers/{name}/status", s.handleStatus(v1alpha1.KindAgentPoolAutoscaler, func() interface{} { return &v1alpha1.AgentPoolAutoscaler{} })).Methods("GET", "PUT")
+
+	// Secrets
+	api.HandleFunc("/secrets", s.handleListSecrets).Methods("GET")
+	api.HandleFunc("/secrets/{name}", s.handleGetSecret).Methods("GET")
+	api.HandleFunc("/secrets", s.handleCreateSecret).Methods("POST")
+	api.HandleFunc("/secrets/{name}", s.handleUpdateSecret).Methods("PUT")
+	api.HandleFunc("/secrets/{name}", s.handlePatch(v1alpha1.KindSecret, func() interface{} { return &v1alpha1.Secret{} })).Methods("PATCH")
+	api.HandleFunc("/secrets/{name}", s.handleDeleteSecret).Methods("DELETE")
+	api.HandleFunc("/secrets", s.handleDeleteCollection(v1alpha1.KindSecret)).Methods("DELETE")
+
+	// ConfigMaps
+	api.HandleFunc("/configmaps", s.handleListConfigMaps).Methods("GET")
+	api.HandleFunc("/configmaps/{name}", s.handleGetConfigMap).Methods("GET")
+	api.HandleFunc("/configmaps", s.handleCreateConfigMap).Methods("POST")
+	api.HandleFunc("/configmaps/{name}", s.handleUpdateConfigMap).Methods("PUT")
+	api.HandleFunc("/configmaps/{name}", s.handlePatch(v1alpha1.KindConfigMap, func() interface{} { return &v1alpha1.ConfigMap{} })).Methods("PATCH")
+	api.HandleFunc("/configmaps/{name}", s.handleDeleteConfigMap).Methods("DELETE")
+	api.HandleFunc("/configmaps", s.handleDeleteCollection(v1alpha1.KindConfigMap)).Methods("DELETE")
+
+	// ModelProviders
+	api.HandleFunc("/modelproviders", s.handleListModelProviders).Methods("GET")
+	api.HandleFunc("/modelproviders/{name}", s.handleGetModelProvider).Methods("GET")
+	api.HandleFunc("/modelproviders", s.handleCreateModelProvider).Methods("POST")
+	api.HandleFunc("/modelproviders/{name}", s.handleUpdateModelProvider).Methods("PUT")
+	api.HandleFunc("/modelproviders/{name}", s.handlePatch(v1alpha1.KindModelProvider, func() interface{} { return &v1alpha1.ModelProvider{} })).Methods("PATCH")
+	api.HandleFunc("/modelproviders/{name}", s.handleDeleteModelProvider).Methods("DELETE")
+	api.HandleFunc("/modelproviders", s.handleDeleteCollection(v1alpha1.KindModelProvider)).Methods("DELETE")
+
+	// ToolDefinitions
+	api.HandleFunc("/tooldefinitions", s.handleListToolDefinitions).Methods("GET")
+	api.HandleFunc("/tooldefinitions/{name}", s.handleGetToolDefinition).Methods("GET")
+	api.HandleFunc("/tooldefinitions", s.handleCreateToolDefinition).Methods("POST")
+	api.HandleFunc("/tooldefinitions/{name}", s.handleUpdateToolDefinition).Methods("PUT")
+	api.HandleFunc("/tooldefinitions/{name}", s.handlePatch(v1alpha1.KindToolDefinition, func() interface{} { return &v1alpha1.ToolDefinition{} })).Methods("PATCH")
+	api.HandleFunc("/tooldefinitions/{name}", s.handleDeleteToolDefinition).Methods("DELETE")
+	api.HandleFunc("/tooldefinitions", s.handleDeleteCollection(v1alpha1.KindToolDefinition)).Methods("DELETE")
+
 	// DevTasks
 	api.HandleFunc("/devtasks", s.handleListDevTasks).Methods("GET")
 	api.HandleFunc("/devtasks/{name}", s.handleGetDevTask).Methods("GET")
 	api.HandleFunc("/devtasks", s.handleCreateDevTask).Methods("POST")
 	api.HandleFunc("/devtasks/{name}", s.handleUpdateDevTask).Methods("PUT")
+	api.HandleFunc("/devtasks/{name}", s.handlePatch(v1alpha1.KindDevTask, func() interface{} { return &v1alpha1.DevTask{} })).Methods("PATCH")
 	api.HandleFunc("/devtasks/{name}", s.handleDeleteDevTask).Methods("DELETE")
+	api.HandleFunc("/devtasks/{name}/status", s.handleStatus(v1alpha1.KindDevTask, func() interface{} { return &v1alpha1.DevTask{} })).Methods("GET", "PUT")
+	api.HandleFunc("/devtasks", s.handleDeleteDevTaskCollection).Methods("DELETE")
+	api.HandleFunc("/devtasks/{name}/retry", s.handleRetryDevTask).Methods("POST")
+	api.HandleFunc("/devtasks/{name}/cancel", s.handleCancelDevTask).Methods("POST")
+	api.HandleFunc("/devtasks/{name}/stream", s.handleStreamDevTask).Methods("GET")
+	api.HandleFunc("/devtasks/{name}/artifacts", s.handleListArtifacts).Methods("GET")
+	api.HandleFunc("/devtasks/{name}/artifacts/{artifact:.+}", s.handleGetArtifact).Methods("GET")
+
+	// AgentNodes
+	api.HandleFunc("/agentnodes", s.handleListAgentNodes).Methods("GET")
+	api.HandleFunc("/agentnodes/{name}", s.handleGetAgentNode).Methods("GET")
+	api.HandleFunc("/agentnodes", s.handleCreateAgentNode).Methods("POST")
+	api.HandleFunc("/agentnodes/{name}", s.handleUpdateAgentNode).Methods("PUT")
+	api.HandleFunc("/agentnodes/{name}", s.handlePatch(v1alpha1.KindAgentNode, func() interface{} { return &v1alpha1.AgentNode{} })).Methods("PATCH")
+	api.HandleFunc("/agentnodes/{name}", s.handleDeleteAgentNode).Methods("DELETE")
+	api.HandleFunc("/agentnodes", s.handleDeleteCollection(v1alpha1.KindAgentNode)).Methods("DELETE")
+	api.HandleFunc("/agentnodes/{name}/status", s.handleStatus(v1alpha1.KindAgentNode, func() interface{} { return &v1alpha1.AgentNode{} })).Methods("GET", "PUT")
+
+	// Events
+	api.HandleFunc("/events", s.handleListEvents).Methods("GET")
 
 	// Logs
 	api.HandleFunc("/agentpods/{name}/logs", s.handleGetLogs).Methods("GET")
 
+	// Watch
+	api.HandleFunc("/watch", s.handleWatch).Methods("GET")
+
 	// Apply (generic resource creation/update)
 	api.HandleFunc("/apply", s.handleApply).Methods("POST")
+
+	// Audit
+	api.HandleFunc("/audit", s.handleListAudit).Methods("GET")
+
+	// Admin
+	api.HandleFunc("/admin/backup", s.handleBackup).Methods("GET")
+	api.HandleFunc("/admin/restore", s.handleRestore).Methods("POST")
 }
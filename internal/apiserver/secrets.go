@@ -0,0 +1,172 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// ---------------------------------------------------------------------------
+// Secrets
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleCreateSecret(w http.ResponseWriter, r *http.Request) {
+	var sec v1alpha1.Secret
+	if err := json.NewDecoder(r.Body).Decode(&sec); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if project := r.URL.Query().Get("project"); project != "" {
+		sec.Metadata.Project = project
+	}
+	if !s.admit(w, r, &sec) {
+		return
+	}
+	project := sec.Metadata.Project
+	if err := normalizeSecret(&sec); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sec.APIVersion = v1alpha1.APIVersion
+	sec.Kind = v1alpha1.KindSecret
+	sec.Metadata.Project = project
+	sec.Metadata.UID = uuid.New().String()
+	now := time.Now()
+	sec.Metadata.CreatedAt = now
+	sec.Metadata.UpdatedAt = now
+
+	key := store.ResourceKey(v1alpha1.KindSecret, project, sec.Metadata.Name)
+	if err := s.storeFor(r).Create(key, &sec); err != nil {
+		if err == store.ErrAlreadyExists {
+			s.writeError(w, http.StatusConflict, "secret already exists")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, &sec)
+}
+
+func (s *Server) handleGetSecret(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindSecret, project, name)
+
+	var sec v1alpha1.Secret
+	if err := s.storeFor(r).Get(key, &sec); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "secret not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &sec)
+}
+
+func (s *Server) handleListSecrets(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("project")
+	q, ok := s.listQuery(w, r)
+	if !ok {
+		return
+	}
+
+	prefix := "/" + v1alpha1.KindSecret + "/"
+	if project != "" {
+		prefix += project + "/"
+	}
+
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.Secret{} })
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeList(s, w, q, items, func(obj *v1alpha1.Secret) *v1alpha1.ObjectMeta { return &obj.Metadata })
+}
+
+func (s *Server) handleUpdateSecret(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindSecret, project, name)
+
+	var existing v1alpha1.Secret
+	if err := s.storeFor(r).Get(key, &existing); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "secret not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var sec v1alpha1.Secret
+	if err := json.NewDecoder(r.Body).Decode(&sec); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := normalizeSecret(&sec); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sec.APIVersion = v1alpha1.APIVersion
+	sec.Kind = v1alpha1.KindSecret
+	sec.Metadata.Name = name
+	sec.Metadata.Project = project
+	sec.Metadata.UID = existing.Metadata.UID
+	sec.Metadata.CreatedAt = existing.Metadata.CreatedAt
+	sec.Metadata.UpdatedAt = time.Now()
+	if !s.admit(w, r, &sec) {
+		return
+	}
+
+	if err := s.storeFor(r).UpdateIfVersion(key, &sec, sec.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &sec)
+}
+
+func (s *Server) handleDeleteSecret(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindSecret, project, name)
+
+	if err := s.storeFor(r).Delete(key); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "secret not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,79 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/manifest"
+)
+
+func TestSecretsAndConfigMaps(t *testing.T) {
+	s := &Server{router: mux.NewRouter(), store: store.NewMemoryStore(), logger: zap.NewNop(), defaults: manifest.DefaultValues}
+	s.registerRoutes()
+
+	do := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1alpha1/"+path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "secrets?project=web", "", `{"metadata":{"name":"gh"},"stringData":{"token":"abc"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST secret: %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodPost, "secrets?project=web", "", `{"metadata":{"name":"gh"}}`); rec.Code != http.StatusConflict {
+		t.Errorf("POST duplicate secret: %d, want 409", rec.Code)
+	}
+	if rec := do(http.MethodPost, "secrets?project=web", "", `{"metadata":{"name":"bad"},"data":{"k":"not base64!"}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("POST secret with invalid data: %d, want 422", rec.Code)
+	}
+
+	rec = do(http.MethodPatch, "secrets/gh?project=web", mergePatchType, `{"stringData":{"user":"orca"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH secret: %d %s", rec.Code, rec.Body)
+	}
+	var sec v1alpha1.Secret
+	rec = do(http.MethodGet, "secrets/gh?project=web", "", "")
+	if err := json.NewDecoder(rec.Body).Decode(&sec); err != nil {
+		t.Fatal(err)
+	}
+	if sec.Data["token"] != "YWJj" || sec.Data["user"] != "b3JjYQ==" || sec.StringData != nil || sec.Kind != v1alpha1.KindSecret {
+		t.Errorf("stored secret = %+v, want stringData merged into data", sec)
+	}
+
+	rec = do(http.MethodPost, "configmaps", "", `{"metadata":{"name":"prompts"},"data":{"review.md":"Be thorough."}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST configmap: %d %s", rec.Code, rec.Body)
+	}
+	rec = do(http.MethodPut, "configmaps/prompts?project=default", "", `{"data":{"review.md":"Be brief."}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT configmap: %d %s", rec.Code, rec.Body)
+	}
+	var cms []v1alpha1.ConfigMap
+	rec = do(http.MethodGet, "configmaps", "", "")
+	if err := json.NewDecoder(rec.Body).Decode(&cms); err != nil {
+		t.Fatal(err)
+	}
+	if len(cms) != 1 || cms[0].Metadata.Name != "prompts" || cms[0].Data["review.md"] != "Be brief." {
+		t.Errorf("listed configmaps %+v", cms)
+	}
+
+	if rec := do(http.MethodDelete, "configmaps/prompts?project=default", "", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE configmap: %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "configmaps/prompts?project=default", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET deleted configmap: %d, want 404", rec.Code)
+	}
+}
@@ -2,14 +2,22 @@ package apiserver
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
 	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/audit"
+	"github.com/klubi/orca/internal/config"
 	"github.com/klubi/orca/internal/store"
+	"github.com/klubi/orca/pkg/manifest"
 )
 
 // Server is the Orca REST API server. It exposes CRUD endpoints for all
@@ -20,6 +28,21 @@ type Server struct {
 	runtime *agent.Runtime
 	logger  *zap.Logger
 	server  *http.Server
+	// defaults fill the fields created and updated resources leave empty.
+	defaults manifest.Defaults
+	// auth, if set, authenticates the requests.
+	auth *Authenticator
+	// audit, if set, records the mutating requests.
+	audit audit.Log
+	// auditKey is the random key the audit log hashes secret values with.
+	auditKey []byte
+	// limiter, if set, limits the rate of each client's requests.
+	limiter *rateLimiter
+	// maxBodyBytes, if positive, caps the size of request bodies.
+	maxBodyBytes int64
+	// requireClientCert rejects requests without a verified client
+	// certificate.
+	requireClientCert bool
 }
 
 // NewServer creates a fully-wired Server ready to Start().
@@ -30,19 +53,61 @@ func NewServer(addr string, s store.Store, rt *agent.Runtime, logger *zap.Logger
 		runtime: rt,
 		logger:  logger,
 	}
+	srv.auditKey = make([]byte, 32)
+	rand.Read(srv.auditKey) // Never fails; see crypto/rand.Read.
+	srv.defaults = manifest.DefaultValues
+	if rt != nil {
+		srv.defaults.MaxTokens = rt.DefaultMaxTokens()
+	}
 	srv.server = &http.Server{
 		Addr:         addr,
 		Handler:      srv.router,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
+	srv.router.Use(srv.logRequests, srv.traceRequests, srv.authenticate, srv.limitRequests, srv.auditRequests)
 	srv.registerRoutes()
 	return srv
 }
 
-// Start begins listening and serving HTTP requests. It blocks until the
-// server is shut down or encounters a fatal error.
+// SetTLS makes the server serve HTTPS with the certificate and key of cfg,
+// requiring client certificates signed by its client CAs if it has any.
+func (s *Server) SetTLS(cfg config.TLSConfig) error {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in %s", cfg.ClientCAFile)
+		}
+		// The handshake accepts clients without a certificate so that
+		// health checks get through; authenticate rejects the rest.
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		s.requireClientCert = true
+	}
+	s.server.TLSConfig = tlsConfig
+	return nil
+}
+
+// Start begins listening and serving HTTP requests, or HTTPS ones after
+// SetTLS. It blocks until the server is shut down or encounters a fatal
+// error.
 func (s *Server) Start() error {
+	if s.server.TLSConfig != nil {
+		s.logger.Info("API server starting", zap.String("addr", s.server.Addr), zap.Bool("tls", true))
+		return s.server.ListenAndServeTLS("", "")
+	}
 	s.logger.Info("API server starting", zap.String("addr", s.server.Addr))
 	return s.server.ListenAndServe()
 }
@@ -0,0 +1,56 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sseKeepAlive is how often an idle event stream sends a comment so proxies
+// and clients don't time the connection out.
+const sseKeepAlive = 15 * time.Second
+
+// sseWriter writes a text/event-stream response.
+type sseWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// newSSEWriter starts an event stream on w. It lifts the server's write
+// timeout, which would otherwise cut long-lived streams.
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, error) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		return nil, fmt.Errorf("streaming not supported: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// A failed flush resurfaces on the first send.
+	_ = rc.Flush()
+	return &sseWriter{w: w, rc: rc}, nil
+}
+
+// send writes data as a JSON-encoded event.
+func (s *sseWriter) send(data interface{}) error {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "data: %s\n\n", buf); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// keepAlive writes a comment line, which clients ignore.
+func (s *sseWriter) keepAlive() error {
+	if _, err := fmt.Fprint(s.w, ": keep-alive\n\n"); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
@@ -0,0 +1,90 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// handleStatus returns a handler for the status subresource of the given
+// kind. GET returns the object's status; PUT replaces it, leaving the spec
+// and metadata alone, and returns the new status. Controllers and agents
+// report through it without racing users who edit the spec.
+func (s *Server) handleStatus(kind string, newObj func() interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["name"]
+		project := r.URL.Query().Get("project")
+		if !v1alpha1.ClusterScoped(kind) && project == "" {
+			s.writeError(w, http.StatusBadRequest, "project query param is required")
+			return
+		}
+
+		key := store.ResourceKey(kind, project, name)
+
+		var status interface{}
+		if r.Method != http.MethodGet {
+			if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+				s.writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		// The status is written over the object as stored; if that changes
+		// before the write, it is written over the new version.
+		for attempt := 1; ; attempt++ {
+			var doc map[string]interface{}
+			if err := s.storeFor(r).Get(key, &doc); err != nil {
+				if err == store.ErrNotFound {
+					s.writeError(w, http.StatusNotFound, strings.ToLower(kind)+" not found")
+					return
+				}
+				s.writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			if r.Method == http.MethodGet {
+				s.writeJSON(w, http.StatusOK, doc["status"])
+				return
+			}
+
+			doc["status"] = status
+			if meta, ok := doc["metadata"].(map[string]interface{}); ok {
+				meta["updatedAt"] = time.Now()
+			}
+
+			raw, err := json.Marshal(doc)
+			if err != nil {
+				s.writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			obj := newObj()
+			if err := json.Unmarshal(raw, obj); err != nil {
+				s.writeError(w, http.StatusUnprocessableEntity, "invalid status: "+err.Error())
+				return
+			}
+			err = s.storeFor(r).UpdateIfVersion(key, obj, resourceVersion(doc))
+			if err == store.ErrConflict && attempt < conflictRetries {
+				continue
+			}
+			if err != nil {
+				s.writeUpdateError(w, err)
+				return
+			}
+
+			// Answer with the status as stored, i.e. without unknown fields.
+			var stored struct {
+				Status interface{} `json:"status"`
+			}
+			raw, _ = json.Marshal(obj)
+			_ = json.Unmarshal(raw, &stored)
+			s.writeJSON(w, http.StatusOK, stored.Status)
+			return
+		}
+	}
+}
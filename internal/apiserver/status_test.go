@@ -0,0 +1,128 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestStatusSubresource(t *testing.T) {
+	st := store.NewMemoryStore()
+	var task v1alpha1.DevTask
+	task.Kind = v1alpha1.KindDevTask
+	task.Metadata.Name = "fix"
+	task.Metadata.Project = "default"
+	task.Spec.Prompt = "fix the login"
+	task.Status.Phase = v1alpha1.TaskPending
+	key := store.ResourceKey(v1alpha1.KindDevTask, "default", "fix")
+	if err := st.Create(key, &task); err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st, logger: zap.NewNop()}
+	handler := s.handleStatus(v1alpha1.KindDevTask, func() interface{} { return &v1alpha1.DevTask{} })
+
+	do := func(method, project, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1alpha1/devtasks/fix/status?project="+project, strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"name": "fix"})
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPut, "default", `{"phase":"Running","assignedPod":"worker-1"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status: %d %s", rec.Code, rec.Body)
+	}
+
+	var got v1alpha1.DevTask
+	if err := st.Get(key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Phase != v1alpha1.TaskRunning || got.Status.AssignedPod != "worker-1" {
+		t.Errorf("stored status = %+v, want Running on worker-1", got.Status)
+	}
+	if got.Spec.Prompt != "fix the login" {
+		t.Errorf("spec changed to %+v", got.Spec)
+	}
+
+	rec = do(http.MethodGet, "default", "")
+	var status v1alpha1.DevTaskStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil || status.Phase != v1alpha1.TaskRunning {
+		t.Errorf("GET status = %+v (%v), want Running", status, err)
+	}
+
+	if rec := do(http.MethodPut, "default", `{"phase":7}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("PUT invalid status: %d, want 422", rec.Code)
+	}
+	if rec := do(http.MethodGet, "other", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET status of a missing task: %d, want 404", rec.Code)
+	}
+}
+
+func TestUpdateConflict(t *testing.T) {
+	st := store.NewMemoryStore()
+	cm := v1alpha1.ConfigMap{
+		Metadata: v1alpha1.ObjectMeta{Name: "settings", Project: "default"},
+		Data:     map[string]string{"a": "1"},
+	}
+	key := store.ResourceKey(v1alpha1.KindConfigMap, "default", "settings")
+	if err := st.Create(key, &cm); err != nil {
+		t.Fatal(err)
+	}
+	stale := cm.Metadata.ResourceVersion
+	s := &Server{store: st, logger: zap.NewNop()}
+
+	put := func(version, value string) *httptest.ResponseRecorder {
+		body := `{"metadata":{"name":"settings","resourceVersion":"` + version + `"},"data":{"a":"` + value + `"}}`
+		req := httptest.NewRequest(http.MethodPut, "/api/v1alpha1/configmaps/settings?project=default", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"name": "settings"})
+		rec := httptest.NewRecorder()
+		s.handleUpdateConfigMap(rec, req)
+		return rec
+	}
+
+	if rec := put(stale, "2"); rec.Code != http.StatusOK {
+		t.Fatalf("PUT at the current version: %d %s", rec.Code, rec.Body)
+	}
+	if rec := put(stale, "3"); rec.Code != http.StatusConflict {
+		t.Fatalf("PUT at a stale version: %d %s, want 409", rec.Code, rec.Body)
+	}
+	if rec := put("", "4"); rec.Code != http.StatusOK {
+		t.Fatalf("PUT without a version: %d %s", rec.Code, rec.Body)
+	}
+
+	var got v1alpha1.ConfigMap
+	if err := st.Get(key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Data["a"] != "4" {
+		t.Errorf("data = %v, want a=4", got.Data)
+	}
+
+	apply := func(version, value string) *httptest.ResponseRecorder {
+		body := `{"kind":"ConfigMap","metadata":{"name":"settings","project":"default","resourceVersion":"` + version + `"},"data":{"a":"` + value + `"}}`
+		rec := httptest.NewRecorder()
+		s.handleApply(rec, httptest.NewRequest(http.MethodPost, "/api/v1alpha1/apply", strings.NewReader(body)))
+		return rec
+	}
+	if rec := apply(stale, "5"); rec.Code != http.StatusConflict {
+		t.Fatalf("apply at a stale version: %d %s, want 409", rec.Code, rec.Body)
+	}
+	if rec := apply(got.Metadata.ResourceVersion, "6"); rec.Code != http.StatusOK {
+		t.Fatalf("apply at the current version: %d %s", rec.Code, rec.Body)
+	}
+	if err := st.Get(key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Data["a"] != "6" {
+		t.Errorf("data after apply = %v, want a=6", got.Data)
+	}
+}
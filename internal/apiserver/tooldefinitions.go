@@ -0,0 +1,164 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// ---------------------------------------------------------------------------
+// ToolDefinitions
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleCreateToolDefinition(w http.ResponseWriter, r *http.Request) {
+	var td v1alpha1.ToolDefinition
+	if err := json.NewDecoder(r.Body).Decode(&td); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if project := r.URL.Query().Get("project"); project != "" {
+		td.Metadata.Project = project
+	}
+	if !s.admit(w, r, &td) {
+		return
+	}
+	project := td.Metadata.Project
+
+	td.APIVersion = v1alpha1.APIVersion
+	td.Kind = v1alpha1.KindToolDefinition
+	td.Metadata.Project = project
+	td.Metadata.UID = uuid.New().String()
+	now := time.Now()
+	td.Metadata.CreatedAt = now
+	td.Metadata.UpdatedAt = now
+
+	key := store.ResourceKey(v1alpha1.KindToolDefinition, project, td.Metadata.Name)
+	if err := s.storeFor(r).Create(key, &td); err != nil {
+		if err == store.ErrAlreadyExists {
+			s.writeError(w, http.StatusConflict, "tool definition already exists")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, &td)
+}
+
+func (s *Server) handleGetToolDefinition(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindToolDefinition, project, name)
+
+	var td v1alpha1.ToolDefinition
+	if err := s.storeFor(r).Get(key, &td); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "tool definition not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &td)
+}
+
+func (s *Server) handleListToolDefinitions(w http.ResponseWriter, r *http.Request) {
+	project := r.URL.Query().Get("project")
+	q, ok := s.listQuery(w, r)
+	if !ok {
+		return
+	}
+
+	prefix := "/" + v1alpha1.KindToolDefinition + "/"
+	if project != "" {
+		prefix += project + "/"
+	}
+
+	items, err := s.storeFor(r).List(prefix, func() interface{} { return &v1alpha1.ToolDefinition{} })
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeList(s, w, q, items, func(obj *v1alpha1.ToolDefinition) *v1alpha1.ObjectMeta { return &obj.Metadata })
+}
+
+func (s *Server) handleUpdateToolDefinition(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindToolDefinition, project, name)
+
+	var existing v1alpha1.ToolDefinition
+	if err := s.storeFor(r).Get(key, &existing); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "tool definition not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var td v1alpha1.ToolDefinition
+	if err := json.NewDecoder(r.Body).Decode(&td); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	td.APIVersion = v1alpha1.APIVersion
+	td.Kind = v1alpha1.KindToolDefinition
+	td.Metadata.Name = name
+	td.Metadata.Project = project
+	td.Metadata.UID = existing.Metadata.UID
+	td.Metadata.CreatedAt = existing.Metadata.CreatedAt
+	td.Metadata.UpdatedAt = time.Now()
+	if !s.admit(w, r, &td) {
+		return
+	}
+
+	if err := s.storeFor(r).UpdateIfVersion(key, &td, td.Metadata.ResourceVersion); err != nil {
+		s.writeUpdateError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &td)
+}
+
+func (s *Server) handleDeleteToolDefinition(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		s.writeError(w, http.StatusBadRequest, "project query param is required")
+		return
+	}
+
+	key := store.ResourceKey(v1alpha1.KindToolDefinition, project, name)
+
+	if err := s.storeFor(r).Delete(key); err != nil {
+		if err == store.ErrNotFound {
+			s.writeError(w, http.StatusNotFound, "tool definition not found")
+			return
+		}
+		s.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,29 @@
+package apiserver
+
+import (
+	"testing"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestWatchable(t *testing.T) {
+	tests := []struct {
+		kind, key, project string
+		want               bool
+	}{
+		{v1alpha1.KindAgentPod, "/AgentPod/web/coder", "", true},
+		{v1alpha1.KindAgentPod, "/AgentPod/web/coder", "web", true},
+		{v1alpha1.KindAgentPod, "/AgentPod/web/coder", "api", false},
+		{v1alpha1.KindProject, "/Project//web", "web", true},
+		{v1alpha1.KindAgentNode, "/AgentNode//gpu-1", "", true},
+		{v1alpha1.KindAgentNode, "/AgentNode//gpu-1", "web", false},
+		{v1alpha1.KindSecret, "/Secret/web/github", "", false},
+		{v1alpha1.KindAuditEntry, "/AuditEntry//000001", "", false},
+	}
+	for _, tt := range tests {
+		ev := v1alpha1.WatchEvent{Kind: tt.kind, Key: tt.key}
+		if got := watchable(ev, tt.project); got != tt.want {
+			t.Errorf("watchable(%s, %q) = %v, want %v", tt.key, tt.project, got, tt.want)
+		}
+	}
+}
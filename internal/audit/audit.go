@@ -0,0 +1,224 @@
+// Package audit keeps the log of the mutating requests the API server
+// serves, either in the store, where `orca get audit` reads it, or in a
+// file of JSON lines for shipping elsewhere.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// Log records audit entries and lists them back.
+type Log interface {
+	// Record appends e, naming it if it has no name.
+	Record(e *v1alpha1.AuditEntry) error
+	// List returns the entries that match f, oldest first.
+	List(f Filter) ([]v1alpha1.AuditEntry, error)
+	Close() error
+}
+
+// Filter selects audit entries. Empty fields match every entry.
+type Filter struct {
+	User    string
+	Kind    string
+	Project string
+	Name    string
+	// Since drops the entries recorded before it.
+	Since time.Time
+	// Limit keeps only the newest Limit entries when positive.
+	Limit int
+}
+
+// Matches reports whether e passes f, not counting its limit.
+func (f Filter) Matches(e *v1alpha1.AuditEntry) bool {
+	switch {
+	case f.User != "" && e.User != f.User,
+		f.Kind != "" && e.Object.Kind != f.Kind,
+		f.Project != "" && e.Object.Project != f.Project,
+		f.Name != "" && e.Object.Name != f.Name,
+		e.Timestamp.Before(f.Since):
+		return false
+	}
+	return true
+}
+
+// apply returns the entries that pass f, oldest first.
+func (f Filter) apply(entries []v1alpha1.AuditEntry) []v1alpha1.AuditEntry {
+	out := entries[:0]
+	for i := range entries {
+		if f.Matches(&entries[i]) {
+			out = append(out, entries[i])
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[len(out)-f.Limit:]
+	}
+	return out
+}
+
+// New returns the audit log cfg configures, writing to st for the store
+// backend, or nil when auditing is off.
+func New(cfg config.AuditConfig, st store.Store) (Log, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "store":
+		l := &storeLog{store: st, maxEntries: cfg.MaxEntries}
+		if err := l.prune(); err != nil {
+			return nil, err
+		}
+		return l, nil
+	case "file":
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("opening audit file: %w", err)
+		}
+		return &fileLog{path: cfg.File, file: f}, nil
+	default:
+		return nil, fmt.Errorf("unknown audit backend %q", cfg.Backend)
+	}
+}
+
+// name returns a unique name for an entry recorded at t that sorts after
+// the names of the entries recorded before it.
+func name(t time.Time) string {
+	return fmt.Sprintf("%019d-%s", t.UnixNano(), uuid.New().String()[:8])
+}
+
+// stamp fills in the type and name of e.
+func stamp(e *v1alpha1.AuditEntry) {
+	e.APIVersion = v1alpha1.APIVersion
+	e.Kind = v1alpha1.KindAuditEntry
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	if e.Metadata.Name == "" {
+		e.Metadata.Name = name(e.Timestamp)
+	}
+	e.Metadata.CreatedAt = e.Timestamp
+}
+
+// pruneEvery is how many entries the store log records between prunes.
+const pruneEvery = 100
+
+// storeLog keeps the entries in the store, deleting the oldest beyond
+// maxEntries.
+type storeLog struct {
+	store      store.Store
+	maxEntries int
+
+	mu      sync.Mutex
+	written int
+}
+
+func (l *storeLog) Record(e *v1alpha1.AuditEntry) error {
+	stamp(e)
+	if err := l.store.Create(store.ResourceKey(v1alpha1.KindAuditEntry, "", e.Metadata.Name), e); err != nil {
+		return fmt.Errorf("recording audit entry: %w", err)
+	}
+	l.mu.Lock()
+	l.written++
+	due := l.written%pruneEvery == 0
+	l.mu.Unlock()
+	if due {
+		return l.prune()
+	}
+	return nil
+}
+
+func (l *storeLog) List(f Filter) ([]v1alpha1.AuditEntry, error) {
+	items, err := l.store.List("/"+v1alpha1.KindAuditEntry+"/", func() interface{} { return &v1alpha1.AuditEntry{} })
+	if err != nil {
+		return nil, fmt.Errorf("listing audit entries: %w", err)
+	}
+	entries := make([]v1alpha1.AuditEntry, len(items))
+	for i, item := range items {
+		entries[i] = *item.(*v1alpha1.AuditEntry)
+	}
+	return f.apply(entries), nil
+}
+
+// prune deletes the oldest entries beyond maxEntries.
+func (l *storeLog) prune() error {
+	if l.maxEntries <= 0 {
+		return nil
+	}
+	entries, err := l.List(Filter{})
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(entries)-l.maxEntries; i++ {
+		key := store.ResourceKey(v1alpha1.KindAuditEntry, "", entries[i].Metadata.Name)
+		if err := l.store.Delete(key); err != nil && err != store.ErrNotFound {
+			return fmt.Errorf("pruning audit entries: %w", err)
+		}
+	}
+	return nil
+}
+
+func (l *storeLog) Close() error { return nil }
+
+// fileLog appends the entries to a file as JSON lines.
+type fileLog struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (l *fileLog) Record(e *v1alpha1.AuditEntry) error {
+	stamp(e)
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding audit entry: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing audit entry: %w", err)
+	}
+	return nil
+}
+
+func (l *fileLog) List(f Filter) ([]v1alpha1.AuditEntry, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading audit file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []v1alpha1.AuditEntry
+	sc := bufio.NewScanner(file)
+	sc.Buffer(nil, 16<<20)
+	for sc.Scan() {
+		var e v1alpha1.AuditEntry
+		// A line cut short by a crash is skipped rather than hiding the
+		// entries after it.
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading audit file: %w", err)
+	}
+	return f.apply(entries), nil
+}
+
+func (l *fileLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
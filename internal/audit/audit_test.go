@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func entry(user, kind string, age time.Duration) *v1alpha1.AuditEntry {
+	return &v1alpha1.AuditEntry{
+		User:      user,
+		Verb:      "create",
+		Object:    v1alpha1.ObjectReference{Kind: kind, Name: "x", Project: "web"},
+		Timestamp: time.Now().Add(-age),
+	}
+}
+
+func TestLogs(t *testing.T) {
+	for _, cfg := range []config.AuditConfig{
+		{Backend: "store"},
+		{Backend: "file", File: filepath.Join(t.TempDir(), "audit.log")},
+	} {
+		t.Run(cfg.Backend, func(t *testing.T) {
+			l, err := New(cfg, store.NewMemoryStore())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+			for _, e := range []*v1alpha1.AuditEntry{
+				entry("alice", v1alpha1.KindDevTask, 3*time.Hour),
+				entry("bob", v1alpha1.KindDevTask, 2*time.Hour),
+				entry("alice", v1alpha1.KindAgentPool, time.Hour),
+			} {
+				if err := l.Record(e); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tests := []struct {
+				name   string
+				filter Filter
+				users  []string
+			}{
+				{"all", Filter{}, []string{"alice", "bob", "alice"}},
+				{"user", Filter{User: "alice"}, []string{"alice", "alice"}},
+				{"kind", Filter{Kind: v1alpha1.KindDevTask}, []string{"alice", "bob"}},
+				{"since", Filter{Since: time.Now().Add(-150 * time.Minute)}, []string{"bob", "alice"}},
+				{"limit", Filter{Limit: 1}, []string{"alice"}},
+			}
+			for _, tt := range tests {
+				got, err := l.List(tt.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var users []string
+				for _, e := range got {
+					users = append(users, e.User)
+				}
+				if len(users) != len(tt.users) {
+					t.Errorf("%s: users %v, want %v", tt.name, users, tt.users)
+					continue
+				}
+				for i := range users {
+					if users[i] != tt.users[i] {
+						t.Errorf("%s: users %v, want %v", tt.name, users, tt.users)
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestStoreLogPrunes(t *testing.T) {
+	st := store.NewMemoryStore()
+	l, err := New(config.AuditConfig{Backend: "store", MaxEntries: 10}, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < pruneEvery; i++ {
+		if err := l.Record(entry("alice", v1alpha1.KindDevTask, time.Duration(pruneEvery-i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := l.List(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 10 {
+		t.Fatalf("%d entries kept, want 10", len(got))
+	}
+	if age := time.Since(got[0].Timestamp); age > 11*time.Minute {
+		t.Errorf("oldest kept entry is %s old, want the newest kept", age)
+	}
+}
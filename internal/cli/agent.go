@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/logging"
+	"github.com/klubi/orca/internal/worker"
+)
+
+func newAgentCmd() *cobra.Command {
+	var (
+		name       string
+		configPath string
+		dataDir    string
+		claudeCLI  string
+		interval   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Run agent pods on this machine for a remote control plane",
+		Long: `Run this machine as an agent node of the control plane at --server.
+
+The worker registers an AgentNode named --name (the hostname by default),
+starts the pods whose spec.nodeName is that name and executes the tasks
+scheduled on them with the local claude CLI. It polls the control plane and
+reports the node's and its pods' heartbeats every --heartbeat-interval; keep
+it well under the server's agent.healthCheckInterval, or the pods are
+marked failed.
+
+Settings are read as by "orca serve": agent.claudeCLI, store.dataDir and the
+log settings apply. Task artifacts and pod logs are kept under the local
+data directory, so "orca artifacts" and "orca logs" cannot reach them
+through the control plane.
+
+On SIGINT or SIGTERM the running tasks are cancelled and the node is marked
+NotReady.`,
+		Example: `  orca agent --server http://control-plane:7117
+  orca agent --name gpu-1 --heartbeat-interval 5s`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Resolve(configPath)
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("data-dir") {
+				cfg.Store.DataDir = dataDir
+			}
+			if cmd.Flags().Changed("claude-cli") {
+				cfg.Agent.ClaudeCLI = claudeCLI
+			}
+			if err := cfg.Validate(); err != nil {
+				return withExitCode(ExitUsage, err)
+			}
+			if interval <= 0 {
+				return withExitCode(ExitUsage, fmt.Errorf("--heartbeat-interval must be positive, got %s", interval))
+			}
+			if name == "" {
+				if name, err = os.Hostname(); err != nil {
+					return fmt.Errorf("determining node name: %w", err)
+				}
+			}
+
+			logger, _, closeLog, err := logging.New(cfg.Log)
+			if err != nil {
+				return fmt.Errorf("creating logger: %w", err)
+			}
+			defer closeLog()
+			defer logger.Sync()
+
+			if err := os.MkdirAll(cfg.Store.DataDir, 0755); err != nil {
+				return fmt.Errorf("creating data directory %s: %w", cfg.Store.DataDir, err)
+			}
+
+			executor := agent.NewExecutor(cfg.Agent.ClaudeCLI, logger.Named(logging.ComponentAgent))
+			defer executor.Close()
+			if check := executor.SelfCheck(context.Background()); check.Err != nil {
+				logger.Warn("claude CLI self-check failed; tasks will fail until it is fixed",
+					zap.Error(check.Err),
+				)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			w := worker.New(name, apiClient, executor, cfg, interval, logger.Named("worker"))
+			return w.Run(ctx)
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Name of the agent node (default: the hostname)")
+	cmd.Flags().StringVar(&configPath, "config", "", "Path to config file (default: ~/.orca/config.yaml if it exists)")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "Directory for task artifacts and pod volumes (overrides config)")
+	cmd.Flags().StringVar(&claudeCLI, "claude-cli", "", "Path to the claude binary (overrides config)")
+	cmd.Flags().DurationVar(&interval, "heartbeat-interval", 10*time.Second, "How often to poll the control plane and report heartbeats")
+
+	return cmd
+}
@@ -1,67 +1,229 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
 	"github.com/klubi/orca/pkg/manifest"
 )
 
 func newApplyCmd() *cobra.Command {
-	var filename string
+	var (
+		filenames []string
+		recursive bool
+		dryRun    string
+		diff      bool
+		parsing   manifestFlags
+	)
 
 	cmd := &cobra.Command{
-		Use:   "apply -f <file>",
+		Use:   "apply -f <file|dir|glob|url|->",
 		Short: "Apply a manifest file",
-		Long:  "Create or update resources from a YAML manifest file.",
+		Long: `Create or update resources from YAML or JSON manifest files.
+
+-f accepts files, directories, glob patterns, "-" for stdin and http(s) URLs,
+and may be repeated; -R also descends into subdirectories. A directory with
+an overlay.yaml is an overlay: base manifests plus patches and labels merged
+over them, e.g. for per-environment variants. Resources are applied in
+dependency order: Projects first, then Secrets, ConfigMaps, ModelProviders
+and ToolDefinitions, agents and finally tasks.
+
+A URL may end in #sha256:<hex digest> to pin the manifest to a checksum, so
+a shared definition cannot change under you: a download with any other
+content is an error.
+
+With --dry-run=server the server validates each resource without storing it;
+--dry-run=client only parses and validates the manifest locally. --diff shows
+what would change against the live objects without applying anything.
+
+With --expand-env, ${VAR} and ${VAR:-default} in the manifests are replaced
+by environment variables before parsing; an unset variable without a
+default is an error. Write $${ for a literal ${.
+
+With --values, the manifests are Go templates rendered with the values in
+the given YAML file, e.g. {{ .Values.replicas }}; default, required and
+quote are available. Escape template text that must reach the server, such
+as a task's promptTemplate, as {{"{{"}}.
+
+With --strict, a field the resource does not have, such as a misspelled
+maxConcurency, is an error rather than silently ignored.`,
 		Example: `  orca apply -f project.yaml
-  orca apply -f agents.yaml`,
+  orca apply -f agents.yaml
+  orca apply -f ./manifests/
+  orca apply -f './envs/*.yaml' -R
+  orca apply -f ./envs/prod/
+  generate-agents | orca apply -f -
+  orca apply -f https://example.com/agents.yaml
+  orca apply -f 'https://example.com/agents-v2.yaml#sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08'
+  orca apply -f agents.yaml --dry-run=server
+  orca apply -f agents.yaml --diff
+  MODEL=claude-opus-4-20250514 orca apply -f pool.yaml --expand-env
+  orca apply -f pool.tpl.yaml --values prod.yaml`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			resources, err := manifest.ParseFile(filename)
+			switch dryRun {
+			case "none", "client", "server":
+			default:
+				return fmt.Errorf("invalid --dry-run value %q (want none, client or server)", dryRun)
+			}
+
+			opts, err := parsing.options()
 			if err != nil {
-				return fmt.Errorf("parsing manifest %s: %w", filename, err)
+				return err
+			}
+			resources, err := loadManifests(filenames, recursive, opts...)
+			if err != nil {
+				return err
 			}
 
 			if len(resources) == 0 {
-				fmt.Println("No resources found in manifest.")
+				printNone("No resources found in manifest.")
 				return nil
 			}
 
+			if diff {
+				return diffResources(resources)
+			}
+
 			for _, resource := range resources {
-				kind, name := resourceIdentity(resource)
+				kind, name := manifest.Identity(resource)
 
-				_, err := apiClient.Apply(resource)
+				switch dryRun {
+				case "client":
+					printChanged(kind+"/"+name, "configured (client dry run)")
+					continue
+				case "server":
+					created, err := apiClient.ApplyInto(resource, nil, true)
+					if err != nil {
+						return fmt.Errorf("applying %s/%s: %w", kind, name, err)
+					}
+					printChanged(kind+"/"+name, appliedVerb(created)+" (server dry run)")
+					continue
+				}
+
+				created, err := apiClient.ApplyInto(resource, nil, false)
 				if err != nil {
 					return fmt.Errorf("applying %s/%s: %w", kind, name, err)
 				}
 
-				fmt.Printf("%s/%s configured\n", kind, name)
+				printChanged(kind+"/"+name, appliedVerb(created))
 			}
 
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&filename, "filename", "f", "", "Path to manifest file (required)")
+	cmd.Flags().StringSliceVarP(&filenames, "filename", "f", nil, "Manifest file, directory, glob, URL or - for stdin (required, repeatable)")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Process directories recursively")
+	cmd.Flags().StringVar(&dryRun, "dry-run", "none", "Validate without persisting: none|client|server")
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = "client"
+	cmd.Flags().BoolVar(&diff, "diff", false, "Show the changes apply would make without applying them")
+	cmd.Flags().StringVar(&parsing.values, "values", "", "Render the manifests as Go templates with the values in this YAML file")
+	cmd.Flags().BoolVar(&parsing.expandEnv, "expand-env", false, "Expand ${VAR} and ${VAR:-default} in manifests from the environment")
+	cmd.Flags().BoolVar(&parsing.strict, "strict", false, "Reject manifest fields the resource does not have, e.g. misspelled ones")
 	cmd.MarkFlagRequired("filename")
 
 	return cmd
 }
 
-// resourceIdentity extracts the kind and name from a typed resource.
-func resourceIdentity(resource interface{}) (kind, name string) {
+// diffResources prints, for each resource, a diff between the live object
+// and the object the server would store if the manifest were applied.
+func diffResources(resources []interface{}) error {
+	for _, resource := range resources {
+		kind, name := manifest.Identity(resource)
+		id := kind + "/" + name
+
+		if kind == v1alpha1.KindSecret {
+			fmt.Printf("%s: secret contents are not diffed\n", id)
+			continue
+		}
+
+		var merged interface{}
+		if _, err := apiClient.ApplyInto(resource, &merged, true); err != nil {
+			return fmt.Errorf("applying %s (dry run): %w", id, err)
+		}
+
+		var live interface{}
+		project := resourceProject(resource)
+		if err := apiClient.Get(normalizeResourceType(kind), name, project, &live); err != nil {
+			if !client.IsNotFound(err) {
+				return fmt.Errorf("getting live %s: %w", id, err)
+			}
+			live = nil
+		}
+
+		from, err := diffableYAML(live)
+		if err != nil {
+			return err
+		}
+		to, err := diffableYAML(merged)
+		if err != nil {
+			return err
+		}
+		if !printUnifiedDiff("live/"+id, "merged/"+id, from, to) {
+			fmt.Printf("%s unchanged\n", id)
+		}
+	}
+	return nil
+}
+
+// diffableYAML renders an API object as YAML without the fields the server
+// manages (status, updatedAt, generation, resourceVersion, finalizers,
+// deletionTimestamp), which would otherwise show up in every diff.
+func diffableYAML(obj interface{}) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return "", err
+	}
+	delete(m, "status")
+	if meta, ok := m["metadata"].(map[string]interface{}); ok {
+		for _, field := range []string{"updatedAt", "generation", "resourceVersion", "finalizers", "deletionTimestamp"} {
+			delete(meta, field)
+		}
+	}
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(m); err != nil {
+		return "", err
+	}
+	enc.Close()
+	return b.String(), nil
+}
+
+// resourceProject returns the project a typed resource belongs to (empty for
+// Projects and resources that rely on the server default).
+func resourceProject(resource interface{}) string {
 	switch r := resource.(type) {
-	case *v1alpha1.Project:
-		return r.Kind, r.Metadata.Name
 	case *v1alpha1.AgentPod:
-		return r.Kind, r.Metadata.Name
+		return r.Metadata.Project
 	case *v1alpha1.AgentPool:
-		return r.Kind, r.Metadata.Name
+		return r.Metadata.Project
 	case *v1alpha1.DevTask:
-		return r.Kind, r.Metadata.Name
+		return r.Metadata.Project
+	case *v1alpha1.Secret:
+		return r.Metadata.Project
+	case *v1alpha1.ConfigMap:
+		return r.Metadata.Project
+	case *v1alpha1.ModelProvider:
+		return r.Metadata.Project
+	case *v1alpha1.ToolDefinition:
+		return r.Metadata.Project
+	case *v1alpha1.AgentPoolAutoscaler:
+		return r.Metadata.Project
 	default:
-		return "Unknown", "unknown"
+		return ""
 	}
 }
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func newCpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cp task/<name>:<artifact> <dest>",
+		Short: "Copy a task artifact to a local file",
+		Long: `Copy a file a task saved as an artifact to the local filesystem.
+
+If dest is an existing directory the artifact is copied into it under its
+base name; "-" writes it to stdout.`,
+		Example: `  orca cp task/review-auth:report.md ./report.md
+  orca cp task/review-auth:patches/fix.diff . -p myproject
+  orca cp task/review-auth:report.md -`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetString("project")
+			taskName, name, err := parseArtifactRef(args[0])
+			if err != nil {
+				return err
+			}
+
+			dest := args[1]
+			if dest == "-" {
+				return apiClient.DownloadArtifact(taskName, project, name, os.Stdout)
+			}
+			if info, err := os.Stat(dest); err == nil && info.IsDir() {
+				dest = filepath.Join(dest, path.Base(name))
+			}
+			return downloadArtifact(taskName, project, name, dest)
+		},
+	}
+
+	cmd.Flags().StringP("project", "p", "default", "Project name")
+
+	return cmd
+}
+
+// parseArtifactRef splits "task/<name>:<artifact>" into the task name and
+// the artifact's name.
+func parseArtifactRef(ref string) (taskName, name string, err error) {
+	target, name, ok := strings.Cut(ref, ":")
+	kind, taskName, hasKind := strings.Cut(target, "/")
+	if !ok || !hasKind || normalizeResourceType(kind) != "devtasks" || taskName == "" || name == "" {
+		return "", "", fmt.Errorf("expected task/<name>:<artifact>, got %q", ref)
+	}
+	return taskName, name, nil
+}
+
+func newArtifactsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "artifacts",
+		Short: "List and download task artifacts",
+		Long: `Tasks keep the files their agent saves to the task's artifact directory.
+The artifacts stay on the server until the task is deleted.`,
+	}
+
+	cmd.AddCommand(newArtifactsListCmd(), newArtifactsDownloadCmd())
+	return cmd
+}
+
+func newArtifactsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list <task>",
+		Aliases: []string{"ls"},
+		Short:   "List a task's artifacts",
+		Example: `  orca artifacts list review-auth
+  orca artifacts list review-auth -p myproject -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetString("project")
+			artifacts, err := apiClient.ListArtifacts(args[0], project)
+			if err != nil {
+				return err
+			}
+
+			if len(artifacts) == 0 && outputFormat == "table" {
+				printNone("No artifacts found.")
+				return nil
+			}
+			items := make([]interface{}, len(artifacts))
+			for i := range artifacts {
+				items[i] = &artifacts[i]
+			}
+			printOutput(items, []string{"NAME", "SIZE", "AGE"}, artifactToRow)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("project", "p", "default", "Project name")
+
+	return cmd
+}
+
+func artifactToRow(v interface{}) []string {
+	a, ok := v.(*v1alpha1.Artifact)
+	if !ok {
+		return []string{"?", "?", "?"}
+	}
+	return []string{a.Name, formatSize(a.Size), formatAge(a.ModTime)}
+}
+
+func newArtifactsDownloadCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "download <task> [artifact...]",
+		Short: "Download a task's artifacts",
+		Long: `Download the named artifacts of a task, or all of them, into a local
+directory. Artifacts keep their paths relative to the task's artifact
+directory.`,
+		Example: `  orca artifacts download review-auth
+  orca artifacts download review-auth report.md -d ./out`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetString("project")
+			taskName, names := args[0], args[1:]
+
+			if len(names) == 0 {
+				artifacts, err := apiClient.ListArtifacts(taskName, project)
+				if err != nil {
+					return err
+				}
+				if len(artifacts) == 0 {
+					printNone("No artifacts found.")
+					return nil
+				}
+				for _, a := range artifacts {
+					names = append(names, a.Name)
+				}
+			}
+
+			for _, name := range names {
+				dest := filepath.Join(dir, filepath.FromSlash(name))
+				if !filepath.IsLocal(filepath.FromSlash(name)) {
+					return fmt.Errorf("artifact name %q leaves the target directory", name)
+				}
+				if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+					return err
+				}
+				if err := downloadArtifact(taskName, project, name, dest); err != nil {
+					return err
+				}
+				fmt.Println(dest)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("project", "p", "default", "Project name")
+	cmd.Flags().StringVarP(&dir, "dir", "d", ".", "Directory to download into")
+
+	return cmd
+}
+
+// downloadArtifact writes a task's artifact to the file dest. A partly
+// written file is removed if the download fails.
+func downloadArtifact(taskName, project, name, dest string) error {
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	err = apiClient.DownloadArtifact(taskName, project, name, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dest)
+		return fmt.Errorf("downloading %s: %w", name, err)
+	}
+	return nil
+}
+
+// formatSize returns a byte count in human-readable form, e.g. "12.3K".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+)
+
+// listAudit prints the server's audit entries selected by opts, oldest
+// first.
+func listAudit(opts client.AuditOptions) error {
+	entries, err := apiClient.ListAuditEntries(opts)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 && outputFormat == "table" {
+		printNone("No audit entries found.")
+		return nil
+	}
+
+	items := make([]interface{}, len(entries))
+	for i := range entries {
+		items[i] = &entries[i]
+	}
+	printOutput(items, auditHeaders(), auditToRow)
+	return nil
+}
+
+func auditHeaders() []string {
+	return []string{"AGE", "USER", "VERB", "OBJECT", "STATUS", "CHANGED"}
+}
+
+func auditToRow(v interface{}) []string {
+	e, ok := v.(*v1alpha1.AuditEntry)
+	if !ok {
+		return []string{"?", "?", "?", "?", "?", "?"}
+	}
+	verb := e.Verb
+	if e.Subresource != "" {
+		verb += " " + e.Subresource
+	}
+	object := "-"
+	if e.Object.Kind != "" {
+		object = strings.ToLower(e.Object.Kind)
+		if e.Object.Name != "" {
+			object += "/" + e.Object.Name
+		}
+	}
+	status := fmt.Sprint(e.StatusCode)
+	if e.StatusCode >= 400 {
+		status = color.YellowString(status)
+	}
+	changed := "-"
+	if _, whole := e.Diff["kind"]; whole {
+		// Only a created object's diff has the kind, which never changes.
+		changed = "(new object)"
+	} else if fields := diffFields("", e.Diff); len(fields) > 0 {
+		changed = truncate(strings.Join(fields, ", "), 60)
+	}
+	return []string{formatAge(e.Timestamp), e.User, verb, object, status, changed}
+}
+
+// diffFields returns the dotted paths of the fields a merge patch sets,
+// sorted, leaving out the metadata every write changes.
+func diffFields(prefix string, diff map[string]interface{}) []string {
+	var fields []string
+	for k, v := range diff {
+		path := prefix + k
+		switch path {
+		case "metadata.resourceVersion", "metadata.updatedAt", "metadata.generation":
+			continue
+		}
+		if sub, ok := v.(map[string]interface{}); ok && len(sub) > 0 {
+			fields = append(fields, diffFields(path+".", sub)...)
+			continue
+		}
+		fields = append(fields, path)
+	}
+	slices.Sort(fields)
+	return fields
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klubi/orca/pkg/manifest"
+)
+
+func newBackupCmd() *cobra.Command {
+	var (
+		filename      string
+		resourcesOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backup [-f file]",
+		Short: "Back up the server's store",
+		Long: `Snapshot the server's store (resources, task history and events, but not
+the audit log) to a gzipped tarball that orca restore reads back, e.g. to move orca to
+another machine or to recover from a corrupted orca.db.
+
+With --resources-only the backup is a YAML bundle of the resources you
+declare instead: projects, secrets, config maps, model providers, tool
+definitions, agent pools and standalone agent pods, without the fields the
+server populates.
+It can be restored with orca restore --resources-only or orca apply -f. The
+bundle holds secret values, so keep it safe.`,
+		Example: `  orca backup
+  orca backup -f orca.tar.gz
+  orca backup --resources-only -f resources.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				filename = "orca-backup-" + time.Now().Format("20060102-150405") + ".tar.gz"
+				if resourcesOnly {
+					filename = "orca-resources-" + time.Now().Format("20060102-150405") + ".yaml"
+				}
+			}
+			if filename == "-" {
+				return apiClient.Backup(os.Stdout, resourcesOnly)
+			}
+
+			f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+			if err != nil {
+				return err
+			}
+			err = apiClient.Backup(f, resourcesOnly)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(filename)
+				return fmt.Errorf("backing up: %w", err)
+			}
+			fmt.Printf("Backup written to %s\n", filename)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "File to write, - for stdout (default: orca-backup-<time>.tar.gz)")
+	cmd.Flags().BoolVar(&resourcesOnly, "resources-only", false, "Write a YAML bundle of the declared resources instead of the whole store")
+
+	return cmd
+}
+
+func newRestoreCmd() *cobra.Command {
+	var resourcesOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <file>",
+		Short: "Restore the server's store from a backup",
+		Long: `Replace the server's entire store with a tarball written by orca backup.
+Everything stored since the backup was taken is lost, except for the audit
+log, which a restore leaves as it is.
+
+With --resources-only the file is a YAML bundle written by
+orca backup --resources-only; its resources are applied on top of the
+current store, like orca apply -f.`,
+		Example: `  orca restore orca-backup-20260101-120000.tar.gz
+  orca restore --resources-only resources.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if resourcesOnly {
+				return restoreResources(args[0])
+			}
+
+			var r io.Reader = os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			n, err := apiClient.Restore(r)
+			if err != nil {
+				return fmt.Errorf("restoring: %w", err)
+			}
+			fmt.Printf("Restored %d objects from %s\n", n, args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&resourcesOnly, "resources-only", false, "Apply a YAML bundle written by orca backup --resources-only")
+
+	return cmd
+}
+
+// restoreResources applies the resources of a YAML bundle.
+func restoreResources(path string) error {
+	resources, err := loadManifests([]string{path}, false)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		kind, name := manifest.Identity(resource)
+		created, err := apiClient.ApplyInto(resource, nil, false)
+		if err != nil {
+			return fmt.Errorf("applying %s/%s: %w", kind, name, err)
+		}
+		printChanged(kind+"/"+name, appliedVerb(created))
+	}
+	return nil
+}
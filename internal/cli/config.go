@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/pkg/client"
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage CLI contexts",
+		Long: `Manage the contexts stored in ~/.orca/config (or $ORCA_CONFIG). A context
+names a server URL, an optional token, the certificates to reach an https
+server with and a default project; the current context is used whenever
+--server or -p is not given.`,
+		Example: `  orca config set-context team --server https://orca.example.com --token $TOKEN --project web
+  orca config set-context secure --server https://orca.internal --certificate-authority ca.pem \
+    --client-certificate me.pem --client-key me-key.pem
+  orca config use-context team
+  orca config get-contexts`,
+	}
+
+	cmd.AddCommand(
+		newConfigSetContextCmd(),
+		newConfigUseContextCmd(),
+		newConfigGetContextsCmd(),
+		newConfigCurrentContextCmd(),
+		newConfigDeleteContextCmd(),
+	)
+
+	return cmd
+}
+
+func newConfigSetContextCmd() *cobra.Command {
+	var (
+		token, project            string
+		caFile, certFile, keyFile string
+		insecure                  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set-context <name>",
+		Short: "Create or update a context",
+		Long:  "Create a context, or update the given fields of an existing one.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := config.DefaultClientConfigPath()
+			cfg, err := config.LoadClientConfig(path)
+			if err != nil {
+				return err
+			}
+
+			ctx := config.Context{Name: args[0]}
+			verb := "created"
+			if existing := cfg.Context(args[0]); existing != nil {
+				ctx = *existing
+				verb = "modified"
+			}
+			if cmd.Flags().Changed("server") {
+				ctx.Server = serverAddr
+			}
+			if cmd.Flags().Changed("token") {
+				ctx.Token = token
+			}
+			if cmd.Flags().Changed("project") {
+				ctx.Project = project
+			}
+			if cmd.Flags().Changed("certificate-authority") {
+				ctx.CertificateAuthority = caFile
+			}
+			if cmd.Flags().Changed("client-certificate") {
+				ctx.ClientCertificate = certFile
+			}
+			if cmd.Flags().Changed("client-key") {
+				ctx.ClientKey = keyFile
+			}
+			if cmd.Flags().Changed("insecure-skip-tls-verify") {
+				ctx.InsecureSkipTLSVerify = insecure
+			}
+			if _, err := ctx.ClientOptions(); err != nil {
+				return withExitCode(ExitUsage, err)
+			}
+			cfg.SetContext(ctx)
+
+			if err := cfg.Save(path); err != nil {
+				return err
+			}
+			fmt.Printf("context %q %s\n", ctx.Name, verb)
+			return nil
+		},
+	}
+
+	// The context's server is set through the inherited --server flag.
+	cmd.Flags().StringVar(&token, "token", "", "Bearer token sent to the server")
+	cmd.Flags().StringVarP(&project, "project", "p", "", "Default project")
+	cmd.Flags().StringVar(&caFile, "certificate-authority", "", "PEM file of the CAs to trust for an https server")
+	cmd.Flags().StringVar(&certFile, "client-certificate", "", "PEM file of the client certificate to present")
+	cmd.Flags().StringVar(&keyFile, "client-key", "", "PEM file of the client certificate's key")
+	cmd.Flags().BoolVar(&insecure, "insecure-skip-tls-verify", false, "Accept any server certificate (testing only)")
+
+	return cmd
+}
+
+func newConfigUseContextCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use-context <name>",
+		Short: "Switch the current context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := config.DefaultClientConfigPath()
+			cfg, err := config.LoadClientConfig(path)
+			if err != nil {
+				return err
+			}
+			if cfg.Context(args[0]) == nil {
+				return fmt.Errorf("context %q not found", args[0])
+			}
+			cfg.CurrentContext = args[0]
+			if err := cfg.Save(path); err != nil {
+				return err
+			}
+			fmt.Printf("switched to context %q\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newConfigGetContextsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List contexts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadClientConfig(config.DefaultClientConfigPath())
+			if err != nil {
+				return err
+			}
+			if len(cfg.Contexts) == 0 {
+				printNone("No contexts found.")
+				return nil
+			}
+
+			var rows [][]string
+			for _, ctx := range cfg.Contexts {
+				current := ""
+				if ctx.Name == cfg.CurrentContext {
+					current = "*"
+				}
+				project := ctx.Project
+				if project == "" {
+					project = "<none>"
+				}
+				rows = append(rows, []string{current, ctx.Name, ctx.Server, project})
+			}
+			printTable([]string{"CURRENT", "NAME", "SERVER", "PROJECT"}, rows)
+			return nil
+		},
+	}
+}
+
+func newConfigCurrentContextCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "current-context",
+		Short: "Print the current context",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadClientConfig(config.DefaultClientConfigPath())
+			if err != nil {
+				return err
+			}
+			if cfg.CurrentContext == "" {
+				return fmt.Errorf("current context is not set")
+			}
+			fmt.Println(cfg.CurrentContext)
+			return nil
+		},
+	}
+}
+
+func newConfigDeleteContextCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete-context <name>",
+		Short: "Delete a context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := config.DefaultClientConfigPath()
+			cfg, err := config.LoadClientConfig(path)
+			if err != nil {
+				return err
+			}
+			if !cfg.DeleteContext(args[0]) {
+				return fmt.Errorf("context %q not found", args[0])
+			}
+			if err := cfg.Save(path); err != nil {
+				return err
+			}
+			fmt.Printf("deleted context %q\n", args[0])
+			return nil
+		},
+	}
+}
+
+// resolveContext applies the selected context (--context, else the current
+// one) to flags the user did not set: the server address and the command's
+// --project default. It returns the client options for the context's
+// credentials. Without a context, or when the context names no project, the
+// project set by `orca project use` is the default.
+func resolveContext(cmd *cobra.Command) (opts []client.Option, err error) {
+	cfg, err := config.LoadClientConfig(config.DefaultClientConfigPath())
+	if err != nil {
+		return nil, err
+	}
+
+	var ctx *config.Context
+	if contextName != "" {
+		if ctx = cfg.Context(contextName); ctx == nil {
+			return nil, fmt.Errorf("context %q not found", contextName)
+		}
+	} else {
+		ctx = cfg.Current()
+	}
+
+	project := cfg.Project
+	activeContext = ""
+	if ctx != nil {
+		activeContext = ctx.Name
+		if ctx.Server != "" && !cmd.Flags().Changed("server") {
+			serverAddr = ctx.Server
+		}
+		if ctx.Project != "" {
+			project = ctx.Project
+		}
+		if opts, err = ctx.ClientOptions(); err != nil {
+			return nil, err
+		}
+	}
+	if f := cmd.Flags().Lookup("project"); f != nil && project != "" && !f.Changed {
+		if err := f.Value.Set(project); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
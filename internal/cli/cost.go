@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// costDimensions are the fields `orca cost` can group by, in column order.
+var costDimensions = []string{"project", "pool", "pod", "model"}
+
+// costRow is one group of `orca cost`. Fields not grouped by are empty.
+type costRow struct {
+	Project   string  `json:"project,omitempty" yaml:"project,omitempty"`
+	Pool      string  `json:"pool,omitempty" yaml:"pool,omitempty"`
+	Pod       string  `json:"pod,omitempty" yaml:"pod,omitempty"`
+	Model     string  `json:"model,omitempty" yaml:"model,omitempty"`
+	Tasks     int     `json:"tasks" yaml:"tasks"`
+	TokensIn  int     `json:"tokensIn" yaml:"tokensIn"`
+	TokensOut int     `json:"tokensOut" yaml:"tokensOut"`
+	CostUSD   float64 `json:"costUSD" yaml:"costUSD"`
+}
+
+// dimension returns the row's value of one of costDimensions.
+func (r *costRow) dimension(name string) string {
+	switch name {
+	case "project":
+		return r.Project
+	case "pool":
+		return r.Pool
+	case "pod":
+		return r.Pod
+	default:
+		return r.Model
+	}
+}
+
+func newCostCmd() *cobra.Command {
+	var (
+		project string
+		since   string
+		by      []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "Summarize spend and tokens",
+		Long: `Summarize the tokens and cost of the tasks that finished in a time window,
+grouped by project, pool, pod and model. Rows are sorted by cost, highest
+first, and followed by a total.
+
+--since takes a duration such as 12h or 7d. --by limits the grouping to some
+of project, pool, pod and model.`,
+		Example: `  orca cost
+  orca cost --project myproj --since 7d
+  orca cost --by model --since 30d -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			window, err := parseLongDuration(since)
+			if err != nil {
+				return fmt.Errorf("--since %w", err)
+			}
+			for _, d := range by {
+				if !containsString(costDimensions, d) {
+					return fmt.Errorf("--by: unknown field %q, expected one of %s", d, strings.Join(costDimensions, ", "))
+				}
+			}
+
+			tasks, err := apiClient.ListDevTasks(project)
+			if err != nil {
+				return err
+			}
+			pods, err := apiClient.ListAgentPods(project)
+			if err != nil {
+				return err
+			}
+
+			rows := computeCost(tasks, pods, time.Now().Add(-window), by)
+			if outputFormat != "table" && outputFormat != "wide" {
+				items := make([]interface{}, len(rows))
+				for i := range rows {
+					items[i] = &rows[i]
+				}
+				printOutput(items, nil, nil)
+				return nil
+			}
+			if len(rows) == 0 {
+				printNone(fmt.Sprintf("No tasks finished in the last %s.", since))
+				return nil
+			}
+			printCostTable(rows, by)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&project, "project", "p", "", "Only count tasks of this project (default: all projects)")
+	cmd.Flags().StringVar(&since, "since", "7d", "Count tasks that finished within this duration, e.g. 24h or 30d")
+	cmd.Flags().StringSliceVar(&by, "by", costDimensions, "Fields to group by (comma-separated): project, pool, pod, model")
+
+	return cmd
+}
+
+// parseLongDuration parses a positive duration that may also be given in
+// days, e.g. "7d".
+func parseLongDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("must be a positive duration such as 12h or 7d, got %q", s)
+}
+
+// computeCost sums the usage of the tasks that finished after start, grouped
+// by the dimensions in by. A task's pool is that of the pod it ran on, or the
+// pool it asked for when the pod is gone.
+func computeCost(tasks []v1alpha1.DevTask, pods []v1alpha1.AgentPod, start time.Time, by []string) []costRow {
+	podPools := make(map[string]string, len(pods))
+	for _, pod := range pods {
+		podPools[pod.Metadata.Project+"/"+pod.Metadata.Name] = pod.OwnerPool()
+	}
+
+	var rows []costRow
+	index := make(map[costRow]int)
+	for _, task := range tasks {
+		st := task.Status
+		if st.FinishedAt.IsZero() || st.FinishedAt.Before(start) {
+			continue
+		}
+
+		pool, ok := podPools[task.Metadata.Project+"/"+st.AssignedPod]
+		if !ok {
+			pool = task.Spec.Pool
+		}
+		full := costRow{
+			Project: task.Metadata.Project,
+			Pool:    orNone(pool),
+			Pod:     orNone(st.AssignedPod),
+			Model:   orNone(st.Model),
+		}
+		var group costRow
+		for _, d := range by {
+			switch d {
+			case "project":
+				group.Project = full.Project
+			case "pool":
+				group.Pool = full.Pool
+			case "pod":
+				group.Pod = full.Pod
+			case "model":
+				group.Model = full.Model
+			}
+		}
+
+		i, ok := index[group]
+		if !ok {
+			i = len(rows)
+			index[group] = i
+			rows = append(rows, group)
+		}
+		rows[i].Tasks++
+		rows[i].TokensIn += st.TokensIn
+		rows[i].TokensOut += st.TokensOut
+		rows[i].CostUSD += st.CostUSD
+	}
+
+	sort.SliceStable(rows, func(a, b int) bool {
+		if rows[a].CostUSD != rows[b].CostUSD {
+			return rows[a].CostUSD > rows[b].CostUSD
+		}
+		for _, d := range costDimensions {
+			if va, vb := rows[a].dimension(d), rows[b].dimension(d); va != vb {
+				return va < vb
+			}
+		}
+		return false
+	})
+	return rows
+}
+
+// printCostTable prints rows with a column per grouped field, followed by the
+// total.
+func printCostTable(rows []costRow, by []string) {
+	var dims, headers []string
+	for _, d := range costDimensions {
+		if containsString(by, d) {
+			dims = append(dims, d)
+			headers = append(headers, strings.ToUpper(d))
+		}
+	}
+	headers = append(headers, "TASKS", "TOKENS-IN", "TOKENS-OUT", "COST")
+
+	var total costRow
+	table := make([][]string, 0, len(rows)+1)
+	for i := range rows {
+		r := &rows[i]
+		row := make([]string, 0, len(headers))
+		for _, d := range dims {
+			row = append(row, r.dimension(d))
+		}
+		table = append(table, append(row, costColumns(r)...))
+
+		total.Tasks += r.Tasks
+		total.TokensIn += r.TokensIn
+		total.TokensOut += r.TokensOut
+		total.CostUSD += r.CostUSD
+	}
+
+	if len(rows) > 1 {
+		row := make([]string, len(dims))
+		if len(dims) > 0 {
+			row[0] = "TOTAL"
+		}
+		table = append(table, append(row, costColumns(&total)...))
+	}
+	printTable(headers, table)
+}
+
+func costColumns(r *costRow) []string {
+	return []string{
+		strconv.Itoa(r.Tasks),
+		strconv.Itoa(r.TokensIn),
+		strconv.Itoa(r.TokensOut),
+		fmt.Sprintf("$%.4f", r.CostUSD),
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -2,59 +2,243 @@ package cli
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+	"github.com/klubi/orca/pkg/manifest"
 )
 
 func newDeleteCmd() *cobra.Command {
+	var (
+		filenames   []string
+		recursive   bool
+		selector    string
+		all         bool
+		propagation string
+		parsing     manifestFlags
+	)
+
 	cmd := &cobra.Command{
-		Use:   "delete <resource-type> <name>",
+		Use:   "delete (<resource-type> <name> | <resource-type> (-l <selector> | --all) | -f <file>)",
 		Short: "Delete a resource",
-		Long:  "Delete a resource by type and name.",
+		Long: `Delete a resource by type and name, every resource of a type matching a
+label selector or, with --all, every resource of a type in the project, or
+every resource declared in a manifest file.
+
+Deleting a project deletes its tasks, autoscalers, pools and pods first;
+with --propagation orphan they are left in place.
+
+Pods the server has started and tasks it has executed are stopped before
+they are removed; until then they remain, with a deletionTimestamp.`,
 		Example: `  orca delete pod my-agent -p myproject
   orca delete pool my-pool
+  orca delete autoscaler my-pool
   orca delete task build-feature
-  orca delete project staging`,
-		Args: cobra.ExactArgs(2),
+  orca delete tasks -l batch=nightly
+  orca delete tasks --all
+  orca delete project staging
+  orca delete project staging --propagation orphan
+  orca delete secret github
+  orca delete configmap prompts
+  orca delete provider ollama
+  orca delete tool github
+  orca delete node gpu-1
+  orca delete -f project.yaml`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			project, _ := cmd.Flags().GetString("project")
-			resourceType := normalizeResourceType(args[0])
-			name := args[1]
-
-			switch resourceType {
-			case "agentpods":
-				if err := apiClient.DeleteAgentPod(name, project); err != nil {
-					return err
+			var opts []client.ListOption
+			switch p := v1alpha1.DeletionPropagation(propagation); p {
+			case "":
+			case v1alpha1.PropagationDelete, v1alpha1.PropagationOrphan:
+				if len(args) > 0 && normalizeResourceType(args[0]) != "projects" {
+					return withExitCode(ExitUsage, fmt.Errorf("--propagation only applies to projects"))
 				}
-				fmt.Printf("agentpod/%s deleted\n", name)
+				opts = append(opts, client.WithPropagation(p))
+			default:
+				return withExitCode(ExitUsage, fmt.Errorf("invalid --propagation %q (want delete or orphan)", propagation))
+			}
 
-			case "agentpools":
-				if err := apiClient.DeleteAgentPool(name, project); err != nil {
-					return err
+			if len(filenames) > 0 {
+				if len(args) > 0 || selector != "" || all {
+					return fmt.Errorf("cannot combine -f with a resource type, name, -l or --all")
 				}
-				fmt.Printf("agentpool/%s deleted\n", name)
-
-			case "devtasks":
-				if err := apiClient.DeleteDevTask(name, project); err != nil {
+				parseOpts, err := parsing.options()
+				if err != nil {
 					return err
 				}
-				fmt.Printf("devtask/%s deleted\n", name)
+				return deleteFromManifests(filenames, recursive, project, parseOpts, opts)
+			}
 
-			case "projects":
-				if err := apiClient.DeleteProject(name); err != nil {
-					return err
+			if selector != "" || all {
+				if selector != "" && all {
+					return fmt.Errorf("cannot combine -l with --all")
 				}
-				fmt.Printf("project/%s deleted\n", name)
-
-			default:
-				return fmt.Errorf("unknown resource type %q. Valid types: agentpods, agentpools, devtasks, projects", args[0])
+				if len(args) != 1 {
+					return fmt.Errorf("expected <resource-type> -l <selector> or <resource-type> --all")
+				}
+				return deleteSelected(normalizeResourceType(args[0]), selector, project, opts...)
 			}
 
-			return nil
+			if len(args) != 2 {
+				return fmt.Errorf("expected <resource-type> <name>, <resource-type> -l <selector>, <resource-type> --all or -f <file>")
+			}
+			return deleteResource(normalizeResourceType(args[0]), args[1], project, opts...)
 		},
 	}
 
 	cmd.Flags().StringP("project", "p", "default", "Project name")
+	cmd.Flags().StringSliceVarP(&filenames, "filename", "f", nil, "Delete the resources declared in manifest files, directories, globs, URLs or stdin (-)")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Process directories recursively")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Delete the resources matching this label selector, e.g. batch=nightly")
+	cmd.Flags().BoolVar(&all, "all", false, "Delete every resource of the type in the project")
+	cmd.Flags().StringVar(&propagation, "propagation", "", "What deleting a project does to its tasks, autoscalers, pools and pods: delete (default) or orphan")
+	cmd.Flags().BoolVar(&parsing.expandEnv, "expand-env", false, "Expand ${VAR} and ${VAR:-default} in manifests from the environment")
 
 	return cmd
 }
+
+// deleteSelected deletes every resource of the given normalized type whose
+// labels match selector, or every one of them when selector is empty. Other
+// types than projects are deleted with one request, which reports those left
+// for their finalizers as pending; opts apply to project deletions.
+func deleteSelected(resourceType, selector, project string, opts ...client.ListOption) error {
+	sel := client.WithLabelSelector(selector)
+	var names []string
+	switch resourceType {
+	case "projects":
+		projects, err := apiClient.ListProjects(sel)
+		if err != nil {
+			return err
+		}
+		for _, p := range projects {
+			if err := deleteResource(resourceType, p.Metadata.Name, project, opts...); err != nil {
+				return err
+			}
+			names = append(names, p.Metadata.Name)
+		}
+	case "agentpods", "agentpools", "autoscalers", "devtasks", "secrets", "configmaps", "modelproviders", "tooldefinitions", "agentnodes":
+		deleted, pending, err := apiClient.DeleteCollection(resourceType, project, sel)
+		if err != nil {
+			return err
+		}
+		for _, name := range deleted {
+			action := "deleted"
+			if slices.Contains(pending, name) {
+				action = "deletion pending finalizers"
+			}
+			printChanged(strings.TrimSuffix(resourceType, "s")+"/"+name, action)
+		}
+		names = deleted
+	default:
+		return withExitCode(ExitUsage, fmt.Errorf("unknown resource type %q. Valid types: agentpods, agentpools, autoscalers, devtasks, projects, secrets, configmaps, modelproviders, tooldefinitions, agentnodes", resourceType))
+	}
+
+	if len(names) == 0 {
+		printNone("No resources found.")
+	}
+	return nil
+}
+
+// deleteResource deletes a single resource identified by its normalized
+// type. opts apply to project deletions.
+func deleteResource(resourceType, name, project string, opts ...client.ListOption) error {
+	switch resourceType {
+	case "agentpods":
+		if err := apiClient.DeleteAgentPod(name, project); err != nil {
+			return err
+		}
+		printChanged("agentpod/"+name, "deleted")
+
+	case "agentpools":
+		if err := apiClient.DeleteAgentPool(name, project); err != nil {
+			return err
+		}
+		printChanged("agentpool/"+name, "deleted")
+
+	case "devtasks":
+		if err := apiClient.DeleteDevTask(name, project); err != nil {
+			return err
+		}
+		printChanged("devtask/"+name, "deleted")
+
+	case "autoscalers":
+		if err := apiClient.DeleteAutoscaler(name, project); err != nil {
+			return err
+		}
+		printChanged("autoscaler/"+name, "deleted")
+
+	case "projects":
+		if err := apiClient.DeleteProject(name, opts...); err != nil {
+			return err
+		}
+		printChanged("project/"+name, "deleted")
+
+	case "secrets":
+		if err := apiClient.DeleteSecret(name, project); err != nil {
+			return err
+		}
+		printChanged("secret/"+name, "deleted")
+
+	case "configmaps":
+		if err := apiClient.DeleteConfigMap(name, project); err != nil {
+			return err
+		}
+		printChanged("configmap/"+name, "deleted")
+
+	case "modelproviders":
+		if err := apiClient.DeleteModelProvider(name, project); err != nil {
+			return err
+		}
+		printChanged("modelprovider/"+name, "deleted")
+
+	case "tooldefinitions":
+		if err := apiClient.DeleteToolDefinition(name, project); err != nil {
+			return err
+		}
+		printChanged("tooldefinition/"+name, "deleted")
+
+	case "agentnodes":
+		if err := apiClient.DeleteAgentNode(name); err != nil {
+			return err
+		}
+		printChanged("agentnode/"+name, "deleted")
+
+	default:
+		return withExitCode(ExitUsage, fmt.Errorf("unknown resource type %q. Valid types: agentpods, agentpools, autoscalers, devtasks, projects, secrets, configmaps, modelproviders, tooldefinitions, agentnodes", resourceType))
+	}
+
+	return nil
+}
+
+// deleteFromManifests deletes every resource declared in the given manifests.
+// Resources are removed in reverse dependency order so that Projects go last.
+// Resources without a project use defaultProject. deleteOpts apply to project
+// deletions.
+func deleteFromManifests(paths []string, recursive bool, defaultProject string, opts []manifest.Option, deleteOpts []client.ListOption) error {
+	resources, err := loadManifests(paths, recursive, opts...)
+	if err != nil {
+		return err
+	}
+
+	if len(resources) == 0 {
+		printNone("No resources found in manifest.")
+		return nil
+	}
+
+	for i := len(resources) - 1; i >= 0; i-- {
+		kind, name := manifest.Identity(resources[i])
+		project := resourceProject(resources[i])
+		if project == "" {
+			project = defaultProject
+		}
+		if err := deleteResource(normalizeResourceType(kind), name, project, deleteOpts...); err != nil {
+			return fmt.Errorf("deleting %s/%s: %w", kind, name, err)
+		}
+	}
+
+	return nil
+}
@@ -1,11 +1,17 @@
 package cli
 
 import (
+	"encoding/base64"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 )
 
 func newDescribeCmd() *cobra.Command {
@@ -16,7 +22,12 @@ func newDescribeCmd() *cobra.Command {
 		Example: `  orca describe pod my-agent
   orca describe pool my-pool -p myproject
   orca describe task build-feature
-  orca describe project default`,
+  orca describe project default
+  orca describe secret github
+  orca describe configmap prompts
+  orca describe provider anthropic
+  orca describe tool github
+  orca describe node gpu-1`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			project, _ := cmd.Flags().GetString("project")
@@ -32,8 +43,18 @@ func newDescribeCmd() *cobra.Command {
 				return describeDevTask(name, project)
 			case "projects":
 				return describeProject(name)
+			case "secrets":
+				return describeSecret(name, project)
+			case "configmaps":
+				return describeConfigMap(name, project)
+			case "modelproviders":
+				return describeModelProvider(name, project)
+			case "tooldefinitions":
+				return describeToolDefinition(name, project)
+			case "agentnodes":
+				return describeAgentNode(name)
 			default:
-				return fmt.Errorf("unknown resource type %q", args[0])
+				return withExitCode(ExitUsage, fmt.Errorf("unknown resource type %q", args[0]))
 			}
 		},
 	}
@@ -56,22 +77,48 @@ func describeAgentPod(name, project string) error {
 	printField("  Project", pod.Metadata.Project)
 	printField("  UID", pod.Metadata.UID)
 	printField("  Labels", formatLabels(pod.Metadata.Labels))
+	printField("  Annotations", formatLabels(pod.Metadata.Annotations))
 	printField("  Created", pod.Metadata.CreatedAt.Format("2006-01-02 15:04:05"))
 	printField("  Updated", pod.Metadata.UpdatedAt.Format("2006-01-02 15:04:05"))
 
 	fmt.Println()
 	bold.Println("Spec:")
 	printField("  Model", pod.Spec.Model)
+	if pod.Spec.Provider != "" {
+		printField("  Provider", pod.Spec.Provider)
+	}
 	if pod.Spec.SystemPrompt != "" {
 		printField("  System Prompt", truncate(pod.Spec.SystemPrompt, 80))
 	}
 	printField("  Capabilities", formatStringSlice(pod.Spec.Capabilities))
 	printField("  Max Concurrency", fmt.Sprintf("%d", pod.Spec.MaxConcurrency))
 	printField("  Max Tokens", fmt.Sprintf("%d", pod.Spec.MaxTokens))
+	if pod.Spec.MaxTurns > 0 {
+		printField("  Max Turns", fmt.Sprintf("%d", pod.Spec.MaxTurns))
+	}
+	if pod.Spec.PermissionMode != "" {
+		printField("  Permission Mode", string(pod.Spec.PermissionMode))
+	}
+	if pod.Spec.WarmSessions > 0 {
+		printField("  Warm Sessions", fmt.Sprintf("%d", pod.Spec.WarmSessions))
+	}
+	if pod.Spec.MaxRequestsPerMinute > 0 {
+		printField("  Max Requests/Min", fmt.Sprintf("%d", pod.Spec.MaxRequestsPerMinute))
+	}
 	printField("  Tools", formatStringSlice(pod.Spec.Tools))
+	if len(pod.Spec.DisallowedTools) > 0 {
+		printField("  Disallowed Tools", formatStringSlice(pod.Spec.DisallowedTools))
+	}
+	printField("  Env", formatEnv(pod.Spec.Env, pod.Spec.EnvFrom))
+	if len(pod.Spec.Volumes) > 0 {
+		printField("  Volumes", formatVolumes(pod.Spec.Volumes))
+	}
 	printField("  Restart Policy", pod.Spec.RestartPolicy)
-	if pod.Spec.OwnerPool != "" {
-		printField("  Owner Pool", pod.Spec.OwnerPool)
+	if pod.OwnerPool() != "" {
+		printField("  Owner Pool", pod.OwnerPool())
+	}
+	if pod.Spec.NodeName != "" {
+		printField("  Node", pod.Spec.NodeName)
 	}
 
 	fmt.Println()
@@ -80,6 +127,10 @@ func describeAgentPod(name, project string) error {
 	printField("  Active Tasks", fmt.Sprintf("%d", pod.Status.ActiveTasks))
 	printField("  Completed Tasks", fmt.Sprintf("%d", pod.Status.CompletedTasks))
 	printField("  Failed Tasks", fmt.Sprintf("%d", pod.Status.FailedTasks))
+	if pod.Status.TokensIn > 0 || pod.Status.TokensOut > 0 {
+		printField("  Tokens", fmt.Sprintf("%d in / %d out", pod.Status.TokensIn, pod.Status.TokensOut))
+		printField("  Cost", fmt.Sprintf("$%.4f", pod.Status.CostUSD))
+	}
 	if !pod.Status.StartedAt.IsZero() {
 		printField("  Started At", pod.Status.StartedAt.Format("2006-01-02 15:04:05"))
 	}
@@ -89,6 +140,7 @@ func describeAgentPod(name, project string) error {
 	if pod.Status.Message != "" {
 		printField("  Message", pod.Status.Message)
 	}
+	printConditions(pod.Status.Conditions)
 
 	return nil
 }
@@ -106,6 +158,8 @@ func describeAgentPool(name, project string) error {
 	printField("  Project", pool.Metadata.Project)
 	printField("  UID", pool.Metadata.UID)
 	printField("  Labels", formatLabels(pool.Metadata.Labels))
+	printField("  Annotations", formatLabels(pool.Metadata.Annotations))
+	printField("  Generation", fmt.Sprintf("%d (observed %d)", pool.Metadata.Generation, pool.Status.ObservedGeneration))
 	printField("  Created", pool.Metadata.CreatedAt.Format("2006-01-02 15:04:05"))
 	printField("  Updated", pool.Metadata.UpdatedAt.Format("2006-01-02 15:04:05"))
 
@@ -113,6 +167,9 @@ func describeAgentPool(name, project string) error {
 	bold.Println("Spec:")
 	printField("  Replicas", fmt.Sprintf("%d", pool.Spec.Replicas))
 	printField("  Selector", formatLabels(pool.Spec.Selector))
+	if len(pool.Spec.PropagateLabels) > 0 {
+		printField("  Propagate Labels", formatStringSlice(pool.Spec.PropagateLabels))
+	}
 
 	fmt.Println()
 	bold.Println("  Template:")
@@ -131,6 +188,8 @@ func describeAgentPool(name, project string) error {
 	printField("  Replicas", fmt.Sprintf("%d", pool.Status.Replicas))
 	printField("  Ready Replicas", fmt.Sprintf("%d", pool.Status.ReadyReplicas))
 	printField("  Busy Replicas", fmt.Sprintf("%d", pool.Status.BusyReplicas))
+	printField("  Updated Replicas", fmt.Sprintf("%d", pool.Status.UpdatedReplicas))
+	printConditions(pool.Status.Conditions)
 
 	return nil
 }
@@ -148,16 +207,32 @@ func describeDevTask(name, project string) error {
 	printField("  Project", task.Metadata.Project)
 	printField("  UID", task.Metadata.UID)
 	printField("  Labels", formatLabels(task.Metadata.Labels))
+	printField("  Annotations", formatLabels(task.Metadata.Annotations))
 	printField("  Created", task.Metadata.CreatedAt.Format("2006-01-02 15:04:05"))
 	printField("  Updated", task.Metadata.UpdatedAt.Format("2006-01-02 15:04:05"))
 
 	fmt.Println()
 	bold.Println("Spec:")
-	printField("  Prompt", task.Spec.Prompt)
+	if task.Spec.PromptTemplate != "" {
+		printField("  Prompt Template", task.Spec.PromptTemplate)
+		printField("  Params", formatLabels(task.Spec.Params))
+	} else {
+		printField("  Prompt", task.Spec.Prompt)
+	}
 	printField("  Required Capabilities", formatStringSlice(task.Spec.RequiredCapabilities))
 	if task.Spec.PreferredModel != "" {
 		printField("  Preferred Model", task.Spec.PreferredModel)
 	}
+	if task.Spec.Pool != "" {
+		printField("  Pool", task.Spec.Pool)
+	}
+	if task.Spec.Pod != "" {
+		printField("  Pod", task.Spec.Pod)
+	}
+	if task.Spec.SessionID != "" {
+		printField("  Resume Session", task.Spec.SessionID)
+	}
+	printField("  Priority", fmt.Sprintf("%d", task.Spec.Priority))
 	printField("  Max Retries", fmt.Sprintf("%d", task.Spec.MaxRetries))
 	printField("  Timeout Seconds", fmt.Sprintf("%d", task.Spec.TimeoutSeconds))
 	if len(task.Spec.DependsOn) > 0 {
@@ -179,6 +254,24 @@ func describeDevTask(name, project string) error {
 	if !task.Status.FinishedAt.IsZero() {
 		printField("  Finished At", task.Status.FinishedAt.Format("2006-01-02 15:04:05"))
 	}
+	if task.Status.Model != "" {
+		printField("  Model", task.Status.Model)
+	}
+	if task.Status.SessionID != "" {
+		printField("  Session", task.Status.SessionID)
+	}
+	if task.Status.TokensIn > 0 || task.Status.TokensOut > 0 {
+		printField("  Tokens", fmt.Sprintf("%d in / %d out", task.Status.TokensIn, task.Status.TokensOut))
+		printField("  Cost", fmt.Sprintf("$%.4f", task.Status.CostUSD))
+	}
+	printConditions(task.Status.Conditions)
+	if len(task.Status.Steps) > 0 {
+		fmt.Println()
+		bold.Println("Steps:")
+		for _, step := range task.Status.Steps {
+			fmt.Println("  " + formatStep(step))
+		}
+	}
 	if task.Status.Output != "" {
 		fmt.Println()
 		bold.Println("Output:")
@@ -205,6 +298,7 @@ func describeProject(name string) error {
 	printField("  Name", proj.Metadata.Name)
 	printField("  UID", proj.Metadata.UID)
 	printField("  Labels", formatLabels(proj.Metadata.Labels))
+	printField("  Annotations", formatLabels(proj.Metadata.Annotations))
 	printField("  Created", proj.Metadata.CreatedAt.Format("2006-01-02 15:04:05"))
 	printField("  Updated", proj.Metadata.UpdatedAt.Format("2006-01-02 15:04:05"))
 
@@ -216,6 +310,30 @@ func describeProject(name string) error {
 	if proj.Spec.Path != "" {
 		printField("  Path", proj.Spec.Path)
 	}
+	if d := proj.Spec.Defaults; d.Model != "" || d.MaxTokens > 0 || len(d.Labels) > 0 {
+		fmt.Println("  Defaults:")
+		if d.Model != "" {
+			printField("    Model", d.Model)
+		}
+		if d.MaxTokens > 0 {
+			printField("    Max Tokens", fmt.Sprintf("%d", d.MaxTokens))
+		}
+		if len(d.Labels) > 0 {
+			printField("    Labels", formatLabels(d.Labels))
+		}
+	}
+	if pol := proj.Spec.Policy; len(pol.AllowedModels) > 0 || pol.TokenBudget > 0 || pol.CostBudgetUSD > 0 {
+		fmt.Println("  Policy:")
+		if len(pol.AllowedModels) > 0 {
+			printField("    Allowed Models", strings.Join(pol.AllowedModels, ", "))
+		}
+		if pol.TokenBudget > 0 {
+			printField("    Token Budget", fmt.Sprintf("%d", pol.TokenBudget))
+		}
+		if pol.CostBudgetUSD > 0 {
+			printField("    Cost Budget", fmt.Sprintf("$%.2f", pol.CostBudgetUSD))
+		}
+	}
 
 	fmt.Println()
 	bold.Println("Status:")
@@ -228,6 +346,184 @@ func describeProject(name string) error {
 	return nil
 }
 
+func describeAgentNode(name string) error {
+	node, err := apiClient.GetAgentNode(name)
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	bold.Println("AgentNode:")
+	printField("  Name", node.Metadata.Name)
+	printField("  UID", node.Metadata.UID)
+	printField("  Labels", formatLabels(node.Metadata.Labels))
+	printField("  Annotations", formatLabels(node.Metadata.Annotations))
+	printField("  Created", node.Metadata.CreatedAt.Format("2006-01-02 15:04:05"))
+	printField("  Updated", node.Metadata.UpdatedAt.Format("2006-01-02 15:04:05"))
+
+	fmt.Println()
+	bold.Println("Spec:")
+	maxPods := "unlimited"
+	if node.Spec.MaxPods > 0 {
+		maxPods = fmt.Sprintf("%d", node.Spec.MaxPods)
+	}
+	printField("  Max Pods", maxPods)
+
+	fmt.Println()
+	bold.Println("Status:")
+	printField("  Phase", colorPhase(string(node.Status.Phase)))
+	printField("  Hostname", node.Status.Hostname)
+	printField("  Version", node.Status.Version)
+	printField("  Pods", fmt.Sprintf("%d", node.Status.Pods))
+	if node.Status.LastHeartbeat != nil {
+		printField("  Last Heartbeat", node.Status.LastHeartbeat.Format("2006-01-02 15:04:05"))
+	}
+
+	return nil
+}
+
+// describeSecret prints a secret's keys and the size of their values, but
+// not the values.
+func describeSecret(name, project string) error {
+	sec, err := apiClient.GetSecret(name, project)
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	bold.Println("Secret:")
+	printField("  Name", sec.Metadata.Name)
+	printField("  Project", sec.Metadata.Project)
+	printField("  UID", sec.Metadata.UID)
+	printField("  Labels", formatLabels(sec.Metadata.Labels))
+	printField("  Annotations", formatLabels(sec.Metadata.Annotations))
+	printField("  Created", sec.Metadata.CreatedAt.Format("2006-01-02 15:04:05"))
+	printField("  Updated", sec.Metadata.UpdatedAt.Format("2006-01-02 15:04:05"))
+
+	fmt.Println()
+	bold.Println("Data:")
+	if len(sec.Data) == 0 {
+		fmt.Println("  <none>")
+	}
+	for _, k := range sortedKeys(sec.Data) {
+		raw, err := base64.StdEncoding.DecodeString(sec.Data[k])
+		if err != nil {
+			printField("  "+k, "<invalid base64>")
+			continue
+		}
+		printField("  "+k, fmt.Sprintf("%d bytes", len(raw)))
+	}
+
+	return nil
+}
+
+func describeConfigMap(name, project string) error {
+	cm, err := apiClient.GetConfigMap(name, project)
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	bold.Println("ConfigMap:")
+	printField("  Name", cm.Metadata.Name)
+	printField("  Project", cm.Metadata.Project)
+	printField("  UID", cm.Metadata.UID)
+	printField("  Labels", formatLabels(cm.Metadata.Labels))
+	printField("  Annotations", formatLabels(cm.Metadata.Annotations))
+	printField("  Created", cm.Metadata.CreatedAt.Format("2006-01-02 15:04:05"))
+	printField("  Updated", cm.Metadata.UpdatedAt.Format("2006-01-02 15:04:05"))
+
+	fmt.Println()
+	bold.Println("Data:")
+	if len(cm.Data) == 0 {
+		fmt.Println("  <none>")
+	}
+	for _, k := range sortedKeys(cm.Data) {
+		fmt.Printf("  %s:\n", k)
+		for _, line := range strings.Split(strings.TrimRight(cm.Data[k], "\n"), "\n") {
+			fmt.Println("    " + line)
+		}
+	}
+
+	return nil
+}
+
+func describeModelProvider(name, project string) error {
+	mp, err := apiClient.GetModelProvider(name, project)
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	bold.Println("ModelProvider:")
+	printField("  Name", mp.Metadata.Name)
+	printField("  Project", mp.Metadata.Project)
+	printField("  UID", mp.Metadata.UID)
+	printField("  Labels", formatLabels(mp.Metadata.Labels))
+	printField("  Annotations", formatLabels(mp.Metadata.Annotations))
+	printField("  Created", mp.Metadata.CreatedAt.Format("2006-01-02 15:04:05"))
+	printField("  Updated", mp.Metadata.UpdatedAt.Format("2006-01-02 15:04:05"))
+
+	fmt.Println()
+	bold.Println("Spec:")
+	printField("  Type", string(mp.Spec.Type))
+	printField("  Endpoint", mp.Spec.Endpoint)
+	if ref := mp.Spec.CredentialsSecret; ref != nil {
+		printField("  Credentials", fmt.Sprintf("secret %s/%s", ref.Name, ref.Key))
+	} else {
+		printField("  Credentials", "")
+	}
+	if mp.Spec.MaxRequestsPerMinute > 0 {
+		printField("  Max Requests/Min", fmt.Sprintf("%d", mp.Spec.MaxRequestsPerMinute))
+	}
+
+	if len(mp.Spec.Costs) > 0 {
+		fmt.Println()
+		bold.Println("Costs (USD per million tokens):")
+		for _, c := range mp.Spec.Costs {
+			printField("  "+c.Model, fmt.Sprintf("$%.2f in / $%.2f out", c.InputPerMTok, c.OutputPerMTok))
+		}
+	}
+
+	return nil
+}
+
+func describeToolDefinition(name, project string) error {
+	td, err := apiClient.GetToolDefinition(name, project)
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	bold.Println("ToolDefinition:")
+	printField("  Name", td.Metadata.Name)
+	printField("  Project", td.Metadata.Project)
+	printField("  UID", td.Metadata.UID)
+	printField("  Labels", formatLabels(td.Metadata.Labels))
+	printField("  Annotations", formatLabels(td.Metadata.Annotations))
+	printField("  Created", td.Metadata.CreatedAt.Format("2006-01-02 15:04:05"))
+	printField("  Updated", td.Metadata.UpdatedAt.Format("2006-01-02 15:04:05"))
+
+	fmt.Println()
+	bold.Println("Spec:")
+	printField("  Description", td.Spec.Description)
+	printField("  Binding", formatToolBinding(&td.Spec))
+	if td.Spec.MCP != nil && len(td.Spec.MCP.Tools) > 0 {
+		printField("  MCP Tools", formatStringSlice(td.Spec.MCP.Tools))
+	}
+	if sb := td.Spec.Sandbox; sb != nil {
+		printField("  Sandbox Deny", formatStringSlice(sb.Deny))
+		printField("  Sandbox Paths", formatStringSlice(sb.Paths))
+	}
+
+	return nil
+}
+
 // --- Helpers ---
 
 func printField(label, value string) {
@@ -248,6 +544,21 @@ func formatLabels(labels map[string]string) string {
 	return strings.Join(parts, ", ")
 }
 
+// printConditions prints a Conditions section, if there are any.
+func printConditions(conds []v1alpha1.Condition) {
+	if len(conds) == 0 {
+		return
+	}
+	fmt.Println()
+	color.New(color.Bold).Println("Conditions:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tSINCE\tMESSAGE")
+	for _, c := range conds {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, formatAge(c.LastTransitionTime), c.Message)
+	}
+	w.Flush()
+}
+
 func formatStringSlice(items []string) string {
 	if len(items) == 0 {
 		return "<none>"
@@ -255,9 +566,80 @@ func formatStringSlice(items []string) string {
 	return strings.Join(items, ", ")
 }
 
+// formatEnv lists env var names and envFrom sources without revealing values.
+func formatEnv(env []v1alpha1.EnvVar, envFrom []v1alpha1.EnvFromSource) string {
+	var parts []string
+	for _, e := range env {
+		switch {
+		case e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil:
+			parts = append(parts, fmt.Sprintf("%s (secret %s/%s)", e.Name, e.ValueFrom.SecretKeyRef.Name, e.ValueFrom.SecretKeyRef.Key))
+		case e.ValueFrom != nil && e.ValueFrom.ConfigMapKeyRef != nil:
+			parts = append(parts, fmt.Sprintf("%s (configmap %s/%s)", e.Name, e.ValueFrom.ConfigMapKeyRef.Name, e.ValueFrom.ConfigMapKeyRef.Key))
+		default:
+			parts = append(parts, e.Name)
+		}
+	}
+	for _, src := range envFrom {
+		switch {
+		case src.SecretRef != nil:
+			parts = append(parts, fmt.Sprintf("%s* (secret %s)", src.Prefix, src.SecretRef.Name))
+		case src.ConfigMapRef != nil:
+			parts = append(parts, fmt.Sprintf("%s* (configmap %s)", src.Prefix, src.ConfigMapRef.Name))
+		}
+	}
+	return formatStringSlice(parts)
+}
+
+// formatVolumes lists volumes with the Secret or ConfigMap they expose.
+func formatVolumes(volumes []v1alpha1.Volume) string {
+	var parts []string
+	for _, v := range volumes {
+		switch {
+		case v.Secret != nil:
+			parts = append(parts, fmt.Sprintf("%s (secret %s)", v.Name, v.Secret.Name))
+		case v.ConfigMap != nil:
+			parts = append(parts, fmt.Sprintf("%s (configmap %s)", v.Name, v.ConfigMap.Name))
+		default:
+			parts = append(parts, v.Name)
+		}
+	}
+	return formatStringSlice(parts)
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
 	}
 	return s[:maxLen-3] + "..."
 }
+
+// formatStep renders one trace entry on a single line.
+func formatStep(step v1alpha1.TaskStep) string {
+	var detail string
+	switch step.Type {
+	case v1alpha1.StepToolCall:
+		detail = step.Tool + " " + truncate(step.Input, 80)
+	case v1alpha1.StepToolResult:
+		detail = step.Tool + " -> " + truncate(strings.Join(strings.Fields(step.Text), " "), 80)
+		if step.IsError {
+			detail = color.RedString(detail)
+		}
+	default:
+		detail = truncate(strings.Join(strings.Fields(step.Text), " "), 80)
+	}
+	line := fmt.Sprintf("[%d] %-10s %s", step.Turn, step.Type, detail)
+	if step.TokensIn > 0 || step.TokensOut > 0 {
+		line += fmt.Sprintf(" (%d in / %d out)", step.TokensIn, step.TokensOut)
+	}
+	return line
+}
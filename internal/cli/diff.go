@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diffOp is one line of a line-based diff.
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// diffLines computes a minimal line diff between a and b using the longest
+// common subsequence. Manifests are small, so the quadratic cost is fine.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// printUnifiedDiff prints a colored unified diff of two texts. It returns
+// false without printing anything when they are identical.
+func printUnifiedDiff(fromName, toName, from, to string) bool {
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	changed := false
+	for _, op := range ops {
+		if op.kind != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return false
+	}
+
+	bold := color.New(color.Bold)
+	bold.Printf("--- %s\n", fromName)
+	bold.Printf("+++ %s\n", toName)
+
+	// Walk the ops and emit hunks of changes with surrounding context.
+	for start := 0; start < len(ops); {
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		lo := start - diffContext
+		if lo < 0 {
+			lo = 0
+		}
+		hi := start
+		for hi < len(ops) {
+			if ops[hi].kind != ' ' {
+				hi++
+				continue
+			}
+			// Stop once a run of unchanged lines is long enough to split.
+			run := hi
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-hi > 2*diffContext {
+				hi += diffContext
+				if hi > len(ops) {
+					hi = len(ops)
+				}
+				break
+			}
+			hi = run
+		}
+
+		aStart, bStart := 1, 1
+		for _, op := range ops[:lo] {
+			if op.kind != '+' {
+				aStart++
+			}
+			if op.kind != '-' {
+				bStart++
+			}
+		}
+		aLen, bLen := 0, 0
+		for _, op := range ops[lo:hi] {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+		// An empty range is numbered after the line it follows.
+		if aLen == 0 {
+			aStart--
+		}
+		if bLen == 0 {
+			bStart--
+		}
+		color.Cyan("@@ -%d,%d +%d,%d @@", aStart, aLen, bStart, bLen)
+		for _, op := range ops[lo:hi] {
+			line := fmt.Sprintf("%c%s", op.kind, op.line)
+			switch op.kind {
+			case '-':
+				color.Red(line)
+			case '+':
+				color.Green(line)
+			default:
+				fmt.Println(line)
+			}
+		}
+		start = hi
+	}
+	return true
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
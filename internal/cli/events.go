@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func newEventsCmd() *cobra.Command {
+	var (
+		forObject   string
+		allProjects bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "List recent events",
+		Long: `List events recorded by the control plane, oldest first. Events explain
+what happened to a resource, e.g. why a task has not been scheduled.`,
+		Example: `  orca events
+  orca events -p myproject
+  orca events --for task/fix-auth-bug
+  orca get events -A`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetString("project")
+			if allProjects {
+				project = ""
+			}
+			return listEvents(project, forObject)
+		},
+	}
+
+	cmd.Flags().StringP("project", "p", "default", "Project name")
+	cmd.Flags().StringVar(&forObject, "for", "", "Only show events about this object, e.g. task/fix-auth-bug")
+	cmd.Flags().BoolVarP(&allProjects, "all-projects", "A", false, "List events from all projects")
+
+	return cmd
+}
+
+// listEvents prints events for project (all projects when empty), optionally
+// limited to one object given as "<type>/<name>".
+func listEvents(project, forObject string) error {
+	var kind, name string
+	if forObject != "" {
+		i := strings.Index(forObject, "/")
+		if i <= 0 || i == len(forObject)-1 {
+			return fmt.Errorf("--for must be <type>/<name>, got %q", forObject)
+		}
+		kind = resourceKind(normalizeResourceType(forObject[:i]))
+		if kind == "" {
+			return withExitCode(ExitUsage, fmt.Errorf("unknown resource type %q", forObject[:i]))
+		}
+		name = forObject[i+1:]
+	}
+
+	evs, err := apiClient.ListEvents(project, kind, name)
+	if err != nil {
+		return err
+	}
+
+	if len(evs) == 0 && outputFormat == "table" {
+		printNone("No events found.")
+		return nil
+	}
+
+	items := make([]interface{}, len(evs))
+	for i := range evs {
+		items[i] = &evs[i]
+	}
+	printOutput(items, eventHeaders(), eventToRow)
+	return nil
+}
+
+// resourceKind maps a normalized resource type to its Kind.
+func resourceKind(resourceType string) string {
+	switch resourceType {
+	case "agentpods":
+		return v1alpha1.KindAgentPod
+	case "agentpools":
+		return v1alpha1.KindAgentPool
+	case "devtasks":
+		return v1alpha1.KindDevTask
+	case "projects":
+		return v1alpha1.KindProject
+	default:
+		return ""
+	}
+}
+
+func eventHeaders() []string {
+	return []string{"LAST SEEN", "TYPE", "REASON", "OBJECT", "COUNT", "MESSAGE"}
+}
+
+func eventToRow(v interface{}) []string {
+	ev, ok := v.(*v1alpha1.Event)
+	if !ok {
+		return []string{"?", "?", "?", "?", "?", "?"}
+	}
+	evType := ev.Type
+	if evType == v1alpha1.EventTypeWarning {
+		evType = color.YellowString(evType)
+	}
+	return []string{
+		formatAge(ev.LastTimestamp),
+		evType,
+		ev.Reason,
+		strings.ToLower(ev.InvolvedObject.Kind) + "/" + ev.InvolvedObject.Name,
+		fmt.Sprintf("%d", ev.Count),
+		ev.Message,
+	}
+}
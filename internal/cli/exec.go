@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -12,26 +15,39 @@ import (
 )
 
 func newExecCmd() *cobra.Command {
-	var timeout int
+	var (
+		timeout     int
+		interactive bool
+		tty         bool
+	)
 
 	cmd := &cobra.Command{
-		Use:   "exec <podname> -- <prompt>",
+		Use:   "exec <podname> [-it | -- <prompt>]",
 		Short: "Send a prompt to a specific pod",
-		Long: `Execute a prompt on a specific agent pod by creating a targeted DevTask.
+		Long: `Execute a prompt on a specific agent pod by creating a DevTask pinned to it.
 
-Everything after "--" is treated as the prompt text.`,
+Everything after "--" is treated as the prompt text. With -i, prompts are read
+from stdin one line at a time and form a single conversation: each turn
+resumes the previous turn's session and its response is streamed as it is
+produced. -t shows a prompt before each line. Type "exit" or press Ctrl+D to
+quit.`,
 		Example: `  orca exec my-agent -- "Explain this codebase"
-  orca exec my-agent -p myproject -- "Write tests for auth.go"`,
+  orca exec my-agent -p myproject -- "Write tests for auth.go"
+  orca exec -it my-agent`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			project, _ := cmd.Flags().GetString("project")
 			podName := args[0]
 
+			timeoutDuration := time.Duration(timeout) * time.Second
+			if timeout == 0 {
+				timeoutDuration = 5 * time.Minute
+			}
+
 			// Find the prompt: everything after the first arg (pod name).
-			if len(args) < 2 {
+			if len(args) < 2 && !interactive {
 				return fmt.Errorf("prompt required: orca exec <podname> -- \"your prompt\"")
 			}
-			prompt := strings.Join(args[1:], " ")
 
 			// Verify the pod exists and get its model.
 			pod, err := apiClient.GetAgentPod(podName, project)
@@ -39,26 +55,13 @@ Everything after "--" is treated as the prompt text.`,
 				return fmt.Errorf("getting pod %s: %w", podName, err)
 			}
 
-			// Create a task targeting this pod's project with the pod's model.
-			taskName := fmt.Sprintf("exec-%s-%d", podName, time.Now().UnixMilli())
-
-			task := &v1alpha1.DevTask{
-				TypeMeta: v1alpha1.TypeMeta{
-					APIVersion: v1alpha1.APIVersion,
-					Kind:       v1alpha1.KindDevTask,
-				},
-				Metadata: v1alpha1.ObjectMeta{
-					Name:    taskName,
-					Project: project,
-				},
-				Spec: v1alpha1.DevTaskSpec{
-					Prompt:         prompt,
-					PreferredModel: pod.Spec.Model,
-					MaxRetries:     0,
-					TimeoutSeconds: timeout,
-				},
+			if interactive {
+				return execInteractive(cmd.Context(), pod, tty, timeoutDuration)
 			}
 
+			task := execTask(pod, strings.Join(args[1:], " "), "", timeout)
+			taskName := task.Metadata.Name
+
 			created, err := apiClient.CreateDevTask(task)
 			if err != nil {
 				return fmt.Errorf("creating exec task: %w", err)
@@ -66,55 +69,136 @@ Everything after "--" is treated as the prompt text.`,
 
 			fmt.Printf("Exec task %s created targeting pod %s. Waiting for completion...\n", created.Metadata.Name, podName)
 
-			// Poll for task completion.
-			pollInterval := 2 * time.Second
-			timeoutDuration := time.Duration(timeout) * time.Second
-			if timeout == 0 {
-				timeoutDuration = 5 * time.Minute
+			current, err := waitForTask(cmd.Context(), taskName, project, timeoutDuration)
+			if err == errWaitTimeout {
+				return withExitCode(ExitTimeout, fmt.Errorf("exec task %s did not complete within timeout (%v)", taskName, timeoutDuration))
+			}
+			if err != nil {
+				return err
 			}
-			deadline := time.Now().Add(timeoutDuration)
-
-			for {
-				if time.Now().After(deadline) {
-					return fmt.Errorf("exec task %s did not complete within timeout (%v)", taskName, timeoutDuration)
-				}
-
-				current, err := apiClient.GetDevTask(taskName, project)
-				if err != nil {
-					return fmt.Errorf("polling task status: %w", err)
-				}
 
-				switch current.Status.Phase {
-				case v1alpha1.TaskSucceeded:
-					fmt.Println()
-					color.New(color.FgGreen, color.Bold).Printf("Exec on %s Succeeded\n", podName)
-					fmt.Println(strings.Repeat("-", 60))
-					fmt.Println(current.Status.Output)
-					return nil
-
-				case v1alpha1.TaskFailed:
-					fmt.Println()
-					color.New(color.FgRed, color.Bold).Printf("Exec on %s Failed\n", podName)
-					fmt.Println(strings.Repeat("-", 60))
-					if current.Status.Error != "" {
-						fmt.Println(current.Status.Error)
-					}
-					return fmt.Errorf("exec task %s failed", taskName)
-
-				case v1alpha1.TaskRunning, v1alpha1.TaskScheduled:
-					fmt.Print(".")
-
-				case v1alpha1.TaskPending:
-					// Still waiting.
+			fmt.Println()
+			if current.Status.Phase == v1alpha1.TaskFailed {
+				color.New(color.FgRed, color.Bold).Printf("Exec on %s Failed\n", podName)
+				fmt.Println(strings.Repeat("-", 60))
+				if current.Status.Error != "" {
+					fmt.Println(current.Status.Error)
 				}
-
-				time.Sleep(pollInterval)
+				return withExitCode(ExitTaskFailed, fmt.Errorf("exec task %s failed", taskName))
 			}
+			color.New(color.FgGreen, color.Bold).Printf("Exec on %s Succeeded\n", podName)
+			fmt.Println(strings.Repeat("-", 60))
+			fmt.Println(current.Status.Output)
+			return nil
 		},
 	}
 
 	cmd.Flags().StringP("project", "p", "default", "Project name")
-	cmd.Flags().IntVar(&timeout, "timeout", 300, "Timeout in seconds")
+	cmd.Flags().IntVar(&timeout, "timeout", 300, "Timeout in seconds (per prompt with -i)")
+	cmd.Flags().BoolVarP(&interactive, "stdin", "i", false, "Hold a conversation with the pod, reading prompts from stdin")
+	cmd.Flags().BoolVarP(&tty, "tty", "t", false, "Show a prompt before each line (use with -i)")
 
 	return cmd
 }
+
+// execTask builds a DevTask that runs prompt on pod, resuming session when
+// non-empty.
+func execTask(pod *v1alpha1.AgentPod, prompt, session string, timeout int) *v1alpha1.DevTask {
+	return &v1alpha1.DevTask{
+		TypeMeta: v1alpha1.TypeMeta{
+			APIVersion: v1alpha1.APIVersion,
+			Kind:       v1alpha1.KindDevTask,
+		},
+		Metadata: v1alpha1.ObjectMeta{
+			Name:    fmt.Sprintf("exec-%s-%d", pod.Metadata.Name, time.Now().UnixMilli()),
+			Project: pod.Metadata.Project,
+		},
+		Spec: v1alpha1.DevTaskSpec{
+			Prompt:         prompt,
+			PreferredModel: pod.Spec.Model,
+			Pod:            pod.Metadata.Name,
+			SessionID:      session,
+			MaxRetries:     0,
+			TimeoutSeconds: timeout,
+		},
+	}
+}
+
+// execInteractive runs a prompt loop against pod. Each line read from stdin
+// becomes a task resuming the previous turn's session, and its steps are
+// streamed as they happen.
+func execInteractive(ctx context.Context, pod *v1alpha1.AgentPod, tty bool, timeout time.Duration) error {
+	podName := pod.Metadata.Name
+	if tty {
+		fmt.Printf("Connected to pod %s (model %s). Type \"exit\" or press Ctrl+D to quit.\n", podName, pod.Spec.Model)
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var session string
+	for {
+		if tty {
+			color.New(color.FgCyan, color.Bold).Printf("%s> ", podName)
+		}
+		if !in.Scan() {
+			if tty {
+				fmt.Println()
+			}
+			return in.Err()
+		}
+		prompt := strings.TrimSpace(in.Text())
+		switch prompt {
+		case "":
+			continue
+		case "exit", "quit":
+			return nil
+		}
+
+		task, err := execTurn(ctx, pod, prompt, session, timeout)
+		if err != nil {
+			// Keep the conversation going; the next prompt may succeed.
+			color.Red("error: %v", err)
+			continue
+		}
+		if task.Status.Phase == v1alpha1.TaskFailed {
+			color.Red("error: %s", task.Status.Error)
+			continue
+		}
+		if task.Status.SessionID != "" {
+			session = task.Status.SessionID
+		}
+		if tty {
+			fmt.Println()
+		}
+	}
+}
+
+// execTurn runs one prompt of an interactive exec and streams its steps. It
+// returns the finished task.
+func execTurn(ctx context.Context, pod *v1alpha1.AgentPod, prompt, session string, timeout time.Duration) (*v1alpha1.DevTask, error) {
+	task := execTask(pod, prompt, session, int(timeout/time.Second))
+	if _, err := apiClient.CreateDevTask(task); err != nil {
+		return nil, fmt.Errorf("creating exec task: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var phase v1alpha1.DevTaskPhase
+	err := apiClient.FollowTask(ctx, task.Metadata.Name, pod.Metadata.Project, func(ev v1alpha1.TaskStreamEvent) {
+		if ev.Step != nil {
+			printLiveStep(*ev.Step)
+		}
+		if ev.Phase != "" {
+			phase = ev.Phase
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("streaming response: %w", err)
+	}
+	if phase == "" {
+		return nil, fmt.Errorf("no response within %v", timeout)
+	}
+	return apiClient.GetDevTask(task.Metadata.Name, pod.Metadata.Project)
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klubi/orca/pkg/client"
+)
+
+// Exit codes of the orca command, listed in its help text. Errors that fit
+// none of the specific cases exit with ExitError.
+const (
+	ExitOK          = 0
+	ExitError       = 1 // the command failed
+	ExitUsage       = 2 // invalid arguments or flags
+	ExitNotFound    = 3 // a resource the command needs does not exist
+	ExitTaskFailed  = 4 // a task the command waited for failed
+	ExitTimeout     = 5 // the command gave up waiting
+	ExitUnavailable = 6 // the API server could not be reached
+)
+
+// exitCodeHelp documents the exit codes in the root command's help.
+const exitCodeHelp = `Exit codes:
+  0  success
+  1  the command failed
+  2  invalid arguments or flags
+  3  a resource was not found
+  4  a task the command waited for failed
+  5  timed out waiting
+  6  the API server could not be reached`
+
+// exitCodeError makes the error it wraps end the command with code.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// withExitCode returns err with an exit code attached.
+func withExitCode(code int, err error) error {
+	return &exitCodeError{code: code, err: err}
+}
+
+// ExitCode returns the exit code for an error returned by the root command.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var coded *exitCodeError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	if client.IsNotFound(err) {
+		return ExitNotFound
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ExitTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ExitUnavailable
+	}
+	// cobra reports unknown subcommands with an untyped error.
+	if strings.HasPrefix(err.Error(), "unknown command ") {
+		return ExitUsage
+	}
+	return ExitError
+}
+
+// markUsageErrors gives the argument and flag errors of cmd and its
+// subcommands the ExitUsage exit code.
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return withExitCode(ExitUsage, err)
+	})
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		if args := c.Args; args != nil {
+			c.Args = func(c *cobra.Command, a []string) error {
+				if err := args(c, a); err != nil {
+					return withExitCode(ExitUsage, err)
+				}
+				return nil
+			}
+		}
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(cmd)
+}
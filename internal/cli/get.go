@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
 )
 
 func newGetCmd() *cobra.Command {
@@ -17,38 +19,114 @@ func newGetCmd() *cobra.Command {
 		Short: "List or get resources",
 		Long: `Display one or many resources.
 
-Resource types: agentpods (pod), agentpools (pool), devtasks (task), projects`,
-		Example: `  orca get pods
+Resource types: agentpods (pod), agentpools (pool), autoscalers, devtasks
+(task), projects, secrets, configmaps (cm), modelproviders (provider),
+tooldefinitions (tool), agentnodes (node), events, audit (the requests that
+changed resources, when the server audits them), and all (the pools, pods
+and tasks of a project)`,
+		Example: `  orca get all -p myproject
+  orca get pods
   orca get pods my-agent -p myproject
   orca get pools
+  orca get autoscalers
   orca get tasks
-  orca get projects`,
+  orca get tasks -l batch=nightly,team!=web
+  orca get tasks --sort-by=.metadata.createdAt
+  orca get projects
+  orca get secrets
+  orca get configmap prompts -o yaml
+  orca get providers
+  orca get tools
+  orca get nodes
+  orca get events
+  orca get audit --user ci --since 24h
+  orca get audit -p web --limit 20
+  orca get pool reviewers -o yaml --export > pool.yaml`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			project, _ := cmd.Flags().GetString("project")
+			exportOutput, _ = cmd.Flags().GetBool("export")
+			sortBy, _ = cmd.Flags().GetString("sort-by")
+			if _, err := evalPath(map[string]interface{}{}, sortBy); err != nil {
+				return fmt.Errorf("--sort-by: %w", err)
+			}
+			if exportOutput && outputFormat != "json" && outputFormat != "yaml" {
+				return fmt.Errorf("--export requires -o yaml or -o json")
+			}
 			resourceType := normalizeResourceType(args[0])
 
 			var name string
 			if len(args) > 1 {
 				name = args[1]
 			}
+			selector, _ := cmd.Flags().GetString("selector")
+			if selector != "" && name != "" {
+				return fmt.Errorf("cannot combine a name with -l")
+			}
+			if selector != "" && resourceType == "events" {
+				return fmt.Errorf("events cannot be selected by label")
+			}
+			if selector != "" && resourceType == "audit" {
+				return fmt.Errorf("audit entries cannot be selected by label; use --user, --since and -p")
+			}
 
 			switch resourceType {
 			case "agentpods":
-				return getAgentPods(project, name)
+				return getAgentPods(project, name, selector)
 			case "agentpools":
-				return getAgentPools(project, name)
+				return getAgentPools(project, name, selector)
+			case "autoscalers":
+				return getAutoscalers(project, name, selector)
 			case "devtasks":
-				return getDevTasks(project, name)
+				return getDevTasks(project, name, selector)
 			case "projects":
-				return getProjects(name)
+				return getProjects(name, selector)
+			case "secrets":
+				return getSecrets(project, name, selector)
+			case "configmaps":
+				return getConfigMaps(project, name, selector)
+			case "modelproviders":
+				return getModelProviders(project, name, selector)
+			case "tooldefinitions":
+				return getToolDefinitions(project, name, selector)
+			case "agentnodes":
+				return getAgentNodes(name, selector)
+			case "all":
+				if name != "" {
+					return fmt.Errorf("get all does not take a name")
+				}
+				return getAll(project, selector)
+			case "events":
+				if allProjects, _ := cmd.Flags().GetBool("all-projects"); allProjects {
+					project = ""
+				}
+				return listEvents(project, "")
+			case "audit":
+				if name != "" {
+					return fmt.Errorf("get audit does not take a name")
+				}
+				opts := client.AuditOptions{}
+				opts.User, _ = cmd.Flags().GetString("user")
+				opts.Since, _ = cmd.Flags().GetDuration("since")
+				opts.Limit, _ = cmd.Flags().GetInt("limit")
+				if cmd.Flags().Changed("project") {
+					opts.Project = project
+				}
+				return listAudit(opts)
 			default:
-				return fmt.Errorf("unknown resource type %q. Valid types: agentpods, agentpools, devtasks, projects", args[0])
+				return withExitCode(ExitUsage, fmt.Errorf("unknown resource type %q. Valid types: agentpods, agentpools, autoscalers, devtasks, projects, secrets, configmaps, modelproviders, tooldefinitions, agentnodes, events, audit, all", args[0]))
 			}
 		},
 	}
 
 	cmd.Flags().StringP("project", "p", "default", "Project name")
+	cmd.Flags().BoolP("all-projects", "A", false, "List events from all projects (events only)")
+	cmd.Flags().StringP("selector", "l", "", "Label selector, e.g. batch=nightly,team!=web")
+	cmd.Flags().String("user", "", "Only list the requests of this user (audit only)")
+	cmd.Flags().Duration("since", 0, "Only list the requests made within this duration, e.g. 24h (audit only)")
+	cmd.Flags().Int("limit", 0, "Only list the newest requests (audit only)")
+	cmd.Flags().String("sort-by", "", "Sort lists by a field given as a JSONPath, e.g. .metadata.createdAt or .status.phase")
+	cmd.Flags().Bool("export", false, "Omit server-populated fields (uid, timestamps, status) so the output can be re-applied")
 
 	return cmd
 }
@@ -61,16 +139,32 @@ func normalizeResourceType(t string) string {
 		return "agentpods"
 	case "agentpool", "agentpools", "pool", "pools":
 		return "agentpools"
+	case "agentpoolautoscaler", "agentpoolautoscalers", "autoscaler", "autoscalers":
+		return "autoscalers"
 	case "devtask", "devtasks", "task", "tasks":
 		return "devtasks"
 	case "project", "projects", "proj":
 		return "projects"
+	case "secret", "secrets":
+		return "secrets"
+	case "configmap", "configmaps", "cm":
+		return "configmaps"
+	case "modelprovider", "modelproviders", "provider", "providers":
+		return "modelproviders"
+	case "tooldefinition", "tooldefinitions", "tool", "tools":
+		return "tooldefinitions"
+	case "agentnode", "agentnodes", "node", "nodes":
+		return "agentnodes"
+	case "event", "events", "ev":
+		return "events"
+	case "audit", "auditentries", "auditentry":
+		return "audit"
 	default:
 		return t
 	}
 }
 
-func getAgentPods(project, name string) error {
+func getAgentPods(project, name, selector string) error {
 	if name != "" {
 		pod, err := apiClient.GetAgentPod(name, project)
 		if err != nil {
@@ -80,13 +174,13 @@ func getAgentPods(project, name string) error {
 		return nil
 	}
 
-	pods, err := apiClient.ListAgentPods(project)
+	pods, err := apiClient.ListAgentPods(project, client.WithLabelSelector(selector))
 	if err != nil {
 		return err
 	}
 
 	if len(pods) == 0 {
-		fmt.Println("No agent pods found.")
+		printNone("No agent pods found.")
 		return nil
 	}
 
@@ -98,7 +192,7 @@ func getAgentPods(project, name string) error {
 	return nil
 }
 
-func getAgentPools(project, name string) error {
+func getAgentPools(project, name, selector string) error {
 	if name != "" {
 		pool, err := apiClient.GetAgentPool(name, project)
 		if err != nil {
@@ -108,13 +202,13 @@ func getAgentPools(project, name string) error {
 		return nil
 	}
 
-	pools, err := apiClient.ListAgentPools(project)
+	pools, err := apiClient.ListAgentPools(project, client.WithLabelSelector(selector))
 	if err != nil {
 		return err
 	}
 
 	if len(pools) == 0 {
-		fmt.Println("No agent pools found.")
+		printNone("No agent pools found.")
 		return nil
 	}
 
@@ -126,7 +220,35 @@ func getAgentPools(project, name string) error {
 	return nil
 }
 
-func getDevTasks(project, name string) error {
+func getAutoscalers(project, name, selector string) error {
+	if name != "" {
+		as, err := apiClient.GetAutoscaler(name, project)
+		if err != nil {
+			return err
+		}
+		printOutput(as, autoscalerHeaders(), autoscalerToRow)
+		return nil
+	}
+
+	autoscalers, err := apiClient.ListAutoscalers(project, client.WithLabelSelector(selector))
+	if err != nil {
+		return err
+	}
+
+	if len(autoscalers) == 0 {
+		printNone("No autoscalers found.")
+		return nil
+	}
+
+	items := make([]interface{}, len(autoscalers))
+	for i := range autoscalers {
+		items[i] = &autoscalers[i]
+	}
+	printOutput(items, autoscalerHeaders(), autoscalerToRow)
+	return nil
+}
+
+func getDevTasks(project, name, selector string) error {
 	if name != "" {
 		task, err := apiClient.GetDevTask(name, project)
 		if err != nil {
@@ -136,13 +258,13 @@ func getDevTasks(project, name string) error {
 		return nil
 	}
 
-	tasks, err := apiClient.ListDevTasks(project)
+	tasks, err := apiClient.ListDevTasks(project, client.WithLabelSelector(selector))
 	if err != nil {
 		return err
 	}
 
 	if len(tasks) == 0 {
-		fmt.Println("No dev tasks found.")
+		printNone("No dev tasks found.")
 		return nil
 	}
 
@@ -154,7 +276,119 @@ func getDevTasks(project, name string) error {
 	return nil
 }
 
-func getProjects(name string) error {
+func getSecrets(project, name, selector string) error {
+	if name != "" {
+		sec, err := apiClient.GetSecret(name, project)
+		if err != nil {
+			return err
+		}
+		printOutput(sec, secretHeaders(), secretToRow)
+		return nil
+	}
+
+	secrets, err := apiClient.ListSecrets(project, client.WithLabelSelector(selector))
+	if err != nil {
+		return err
+	}
+
+	if len(secrets) == 0 {
+		printNone("No secrets found.")
+		return nil
+	}
+
+	items := make([]interface{}, len(secrets))
+	for i := range secrets {
+		items[i] = &secrets[i]
+	}
+	printOutput(items, secretHeaders(), secretToRow)
+	return nil
+}
+
+func getConfigMaps(project, name, selector string) error {
+	if name != "" {
+		cm, err := apiClient.GetConfigMap(name, project)
+		if err != nil {
+			return err
+		}
+		printOutput(cm, configMapHeaders(), configMapToRow)
+		return nil
+	}
+
+	configMaps, err := apiClient.ListConfigMaps(project, client.WithLabelSelector(selector))
+	if err != nil {
+		return err
+	}
+
+	if len(configMaps) == 0 {
+		printNone("No config maps found.")
+		return nil
+	}
+
+	items := make([]interface{}, len(configMaps))
+	for i := range configMaps {
+		items[i] = &configMaps[i]
+	}
+	printOutput(items, configMapHeaders(), configMapToRow)
+	return nil
+}
+
+func getModelProviders(project, name, selector string) error {
+	if name != "" {
+		mp, err := apiClient.GetModelProvider(name, project)
+		if err != nil {
+			return err
+		}
+		printOutput(mp, modelProviderHeaders(), modelProviderToRow)
+		return nil
+	}
+
+	providers, err := apiClient.ListModelProviders(project, client.WithLabelSelector(selector))
+	if err != nil {
+		return err
+	}
+
+	if len(providers) == 0 {
+		printNone("No model providers found.")
+		return nil
+	}
+
+	items := make([]interface{}, len(providers))
+	for i := range providers {
+		items[i] = &providers[i]
+	}
+	printOutput(items, modelProviderHeaders(), modelProviderToRow)
+	return nil
+}
+
+func getToolDefinitions(project, name, selector string) error {
+	if name != "" {
+		td, err := apiClient.GetToolDefinition(name, project)
+		if err != nil {
+			return err
+		}
+		printOutput(td, toolDefinitionHeaders(), toolDefinitionToRow)
+		return nil
+	}
+
+	tools, err := apiClient.ListToolDefinitions(project, client.WithLabelSelector(selector))
+	if err != nil {
+		return err
+	}
+
+	if len(tools) == 0 {
+		printNone("No tool definitions found.")
+		return nil
+	}
+
+	items := make([]interface{}, len(tools))
+	for i := range tools {
+		items[i] = &tools[i]
+	}
+	printOutput(items, toolDefinitionHeaders(), toolDefinitionToRow)
+	return nil
+}
+
+func getProjects(name, selector string) error {
 	if name != "" {
 		proj, err := apiClient.GetProject(name)
 		if err != nil {
@@ -164,13 +398,13 @@ func getProjects(name string) error {
 		return nil
 	}
 
-	projects, err := apiClient.ListProjects()
+	projects, err := apiClient.ListProjects(client.WithLabelSelector(selector))
 	if err != nil {
 		return err
 	}
 
 	if len(projects) == 0 {
-		fmt.Println("No projects found.")
+		printNone("No projects found.")
 		return nil
 	}
 
@@ -182,10 +416,116 @@ func getProjects(name string) error {
 	return nil
 }
 
+func getAgentNodes(name, selector string) error {
+	if name != "" {
+		node, err := apiClient.GetAgentNode(name)
+		if err != nil {
+			return err
+		}
+		printOutput(node, agentNodeHeaders(), agentNodeToRow)
+		return nil
+	}
+
+	nodes, err := apiClient.ListAgentNodes(client.WithLabelSelector(selector))
+	if err != nil {
+		return err
+	}
+
+	if len(nodes) == 0 {
+		printNone("No agent nodes found.")
+		return nil
+	}
+
+	items := make([]interface{}, len(nodes))
+	for i := range nodes {
+		items[i] = &nodes[i]
+	}
+	printOutput(items, agentNodeHeaders(), agentNodeToRow)
+	return nil
+}
+
+// getAll prints the pools, pods and tasks of project that match the label
+// selector. Tables are printed one
+// per kind, with names prefixed by the kind as in "agentpool/reviewers";
+// JSON and YAML output is a single list.
+func getAll(project, selector string) error {
+	pools, err := apiClient.ListAgentPools(project, client.WithLabelSelector(selector))
+	if err != nil {
+		return err
+	}
+	pods, err := apiClient.ListAgentPods(project, client.WithLabelSelector(selector))
+	if err != nil {
+		return err
+	}
+	tasks, err := apiClient.ListDevTasks(project, client.WithLabelSelector(selector))
+	if err != nil {
+		return err
+	}
+
+	groups := []struct {
+		prefix  string
+		items   []interface{}
+		headers []string
+		toRow   func(interface{}) []string
+	}{
+		{"agentpool/", make([]interface{}, len(pools)), agentPoolHeaders(), agentPoolToRow},
+		{"agentpod/", make([]interface{}, len(pods)), agentPodHeaders(), agentPodToRow},
+		{"devtask/", make([]interface{}, len(tasks)), devTaskHeaders(), devTaskToRow},
+	}
+	for i := range pools {
+		groups[0].items[i] = &pools[i]
+	}
+	for i := range pods {
+		groups[1].items[i] = &pods[i]
+	}
+	for i := range tasks {
+		groups[2].items[i] = &tasks[i]
+	}
+
+	if outputFormat != "table" && outputFormat != "wide" {
+		all := make([]interface{}, 0, len(pools)+len(pods)+len(tasks))
+		for _, g := range groups {
+			all = append(all, g.items...)
+		}
+		printOutput(all, nil, nil)
+		return nil
+	}
+
+	printed := false
+	for _, g := range groups {
+		if len(g.items) == 0 {
+			continue
+		}
+		if printed && !quiet {
+			fmt.Println()
+		}
+		if sortBy != "" {
+			if err := sortItems(g.items, sortBy); err != nil {
+				return err
+			}
+		}
+		rows := make([][]string, len(g.items))
+		for i, item := range g.items {
+			rows[i] = g.toRow(item)
+			rows[i][0] = g.prefix + rows[i][0]
+		}
+		printTable(g.headers, rows)
+		printed = true
+	}
+	if !printed {
+		printNone(fmt.Sprintf("No resources found in project %q.", project))
+	}
+	return nil
+}
+
 // --- Table headers and row converters ---
 
 func agentPodHeaders() []string {
-	return []string{"NAME", "PROJECT", "MODEL", "PHASE", "ACTIVE-TASKS", "AGE"}
+	headers := []string{"NAME", "PROJECT", "MODEL", "PHASE", "ACTIVE-TASKS", "AGE"}
+	if outputFormat == "wide" {
+		headers = append(headers, "POOL", "NODE", "COMPLETED", "FAILED", "TOKENS", "COST", "HEARTBEAT")
+	}
+	return headers
 }
 
 func agentPodToRow(v interface{}) []string {
@@ -193,7 +533,7 @@ func agentPodToRow(v interface{}) []string {
 	if !ok {
 		return []string{"?", "?", "?", "?", "?", "?"}
 	}
-	return []string{
+	row := []string{
 		pod.Metadata.Name,
 		pod.Metadata.Project,
 		pod.Spec.Model,
@@ -201,10 +541,34 @@ func agentPodToRow(v interface{}) []string {
 		strconv.Itoa(pod.Status.ActiveTasks),
 		formatAge(pod.Metadata.CreatedAt),
 	}
+	if outputFormat == "wide" {
+		pool := pod.OwnerPool()
+		if pool == "" {
+			pool = "<none>"
+		}
+		node := pod.Spec.NodeName
+		if node == "" {
+			node = "<none>"
+		}
+		row = append(row,
+			pool,
+			node,
+			strconv.Itoa(pod.Status.CompletedTasks),
+			strconv.Itoa(pod.Status.FailedTasks),
+			strconv.Itoa(pod.Status.TokensIn+pod.Status.TokensOut),
+			fmt.Sprintf("$%.4f", pod.Status.CostUSD),
+			formatAge(pod.Status.LastHeartbeat),
+		)
+	}
+	return row
 }
 
 func agentPoolHeaders() []string {
-	return []string{"NAME", "PROJECT", "REPLICAS", "READY", "BUSY", "AGE"}
+	headers := []string{"NAME", "PROJECT", "REPLICAS", "READY", "BUSY", "AGE"}
+	if outputFormat == "wide" {
+		headers = append(headers, "UP-TO-DATE", "MODEL", "SELECTOR")
+	}
+	return headers
 }
 
 func agentPoolToRow(v interface{}) []string {
@@ -212,7 +576,7 @@ func agentPoolToRow(v interface{}) []string {
 	if !ok {
 		return []string{"?", "?", "?", "?", "?", "?"}
 	}
-	return []string{
+	row := []string{
 		pool.Metadata.Name,
 		pool.Metadata.Project,
 		strconv.Itoa(pool.Spec.Replicas),
@@ -220,10 +584,143 @@ func agentPoolToRow(v interface{}) []string {
 		strconv.Itoa(pool.Status.BusyReplicas),
 		formatAge(pool.Metadata.CreatedAt),
 	}
+	if outputFormat == "wide" {
+		row = append(row,
+			strconv.Itoa(pool.Status.UpdatedReplicas),
+			pool.Spec.Template.Spec.Model,
+			formatLabels(pool.Spec.Selector),
+		)
+	}
+	return row
+}
+
+func autoscalerHeaders() []string {
+	headers := []string{"NAME", "PROJECT", "POOL", "MIN", "MAX", "REPLICAS", "DESIRED", "AGE"}
+	if outputFormat == "wide" {
+		headers = append(headers, "LAST-SCALE", "MESSAGE")
+	}
+	return headers
+}
+
+func autoscalerToRow(v interface{}) []string {
+	as, ok := v.(*v1alpha1.AgentPoolAutoscaler)
+	if !ok {
+		return []string{"?", "?", "?", "?", "?", "?", "?", "?"}
+	}
+	row := []string{
+		as.Metadata.Name,
+		as.Metadata.Project,
+		as.Spec.Pool,
+		strconv.Itoa(as.Spec.MinReplicas),
+		strconv.Itoa(as.Spec.MaxReplicas),
+		strconv.Itoa(as.Status.CurrentReplicas),
+		strconv.Itoa(as.Status.DesiredReplicas),
+		formatAge(as.Metadata.CreatedAt),
+	}
+	if outputFormat == "wide" {
+		lastScale := "<never>"
+		if !as.Status.LastScaleTime.IsZero() {
+			lastScale = formatAge(as.Status.LastScaleTime)
+		}
+		row = append(row, lastScale, orNone(as.Status.Message))
+	}
+	return row
+}
+
+func secretHeaders() []string {
+	return []string{"NAME", "PROJECT", "DATA", "AGE"}
+}
+
+func secretToRow(v interface{}) []string {
+	sec, ok := v.(*v1alpha1.Secret)
+	if !ok {
+		return []string{"?", "?", "?", "?"}
+	}
+	return []string{
+		sec.Metadata.Name,
+		sec.Metadata.Project,
+		strconv.Itoa(len(sec.Data)),
+		formatAge(sec.Metadata.CreatedAt),
+	}
+}
+
+func configMapHeaders() []string {
+	return []string{"NAME", "PROJECT", "DATA", "AGE"}
+}
+
+func configMapToRow(v interface{}) []string {
+	cm, ok := v.(*v1alpha1.ConfigMap)
+	if !ok {
+		return []string{"?", "?", "?", "?"}
+	}
+	return []string{
+		cm.Metadata.Name,
+		cm.Metadata.Project,
+		strconv.Itoa(len(cm.Data)),
+		formatAge(cm.Metadata.CreatedAt),
+	}
+}
+
+func modelProviderHeaders() []string {
+	return []string{"NAME", "PROJECT", "TYPE", "ENDPOINT", "AGE"}
+}
+
+func modelProviderToRow(v interface{}) []string {
+	mp, ok := v.(*v1alpha1.ModelProvider)
+	if !ok {
+		return []string{"?", "?", "?", "?", "?"}
+	}
+	endpoint := mp.Spec.Endpoint
+	if endpoint == "" {
+		endpoint = "<default>"
+	}
+	return []string{
+		mp.Metadata.Name,
+		mp.Metadata.Project,
+		string(mp.Spec.Type),
+		endpoint,
+		formatAge(mp.Metadata.CreatedAt),
+	}
+}
+
+func toolDefinitionHeaders() []string {
+	return []string{"NAME", "PROJECT", "BINDING", "DESCRIPTION", "AGE"}
+}
+
+func toolDefinitionToRow(v interface{}) []string {
+	td, ok := v.(*v1alpha1.ToolDefinition)
+	if !ok {
+		return []string{"?", "?", "?", "?", "?"}
+	}
+	return []string{
+		td.Metadata.Name,
+		td.Metadata.Project,
+		formatToolBinding(&td.Spec),
+		truncate(td.Spec.Description, 40),
+		formatAge(td.Metadata.CreatedAt),
+	}
+}
+
+// formatToolBinding summarizes what implements a tool.
+func formatToolBinding(s *v1alpha1.ToolDefinitionSpec) string {
+	switch {
+	case s.MCP != nil && s.MCP.URL != "":
+		return "mcp " + s.MCP.URL
+	case s.MCP != nil:
+		return "mcp " + strings.Join(append([]string{s.MCP.Command}, s.MCP.Args...), " ")
+	case s.Command != "":
+		return "command " + s.Command
+	default:
+		return strings.Join(s.CLITools, ",")
+	}
 }
 
 func devTaskHeaders() []string {
-	return []string{"NAME", "PROJECT", "PHASE", "ASSIGNED-POD", "RETRIES", "AGE"}
+	headers := []string{"NAME", "PROJECT", "PHASE", "ASSIGNED-POD", "RETRIES", "AGE"}
+	if outputFormat == "wide" {
+		headers = append(headers, "PRIORITY", "MODEL", "TOKENS", "COST", "DURATION")
+	}
+	return headers
 }
 
 func devTaskToRow(v interface{}) []string {
@@ -235,7 +732,7 @@ func devTaskToRow(v interface{}) []string {
 	if assignedPod == "" {
 		assignedPod = "<none>"
 	}
-	return []string{
+	row := []string{
 		task.Metadata.Name,
 		task.Metadata.Project,
 		colorPhase(string(task.Status.Phase)),
@@ -243,6 +740,24 @@ func devTaskToRow(v interface{}) []string {
 		strconv.Itoa(task.Status.Retries),
 		formatAge(task.Metadata.CreatedAt),
 	}
+	if outputFormat == "wide" {
+		model := task.Status.Model
+		if model == "" {
+			model = "<none>"
+		}
+		duration := "<none>"
+		if !task.Status.StartedAt.IsZero() && !task.Status.FinishedAt.IsZero() {
+			duration = task.Status.FinishedAt.Sub(task.Status.StartedAt).Round(time.Second).String()
+		}
+		row = append(row,
+			strconv.Itoa(task.Spec.Priority),
+			model,
+			strconv.Itoa(task.Status.TokensIn+task.Status.TokensOut),
+			fmt.Sprintf("$%.4f", task.Status.CostUSD),
+			duration,
+		)
+	}
+	return row
 }
 
 func projectHeaders() []string {
@@ -265,12 +780,46 @@ func projectToRow(v interface{}) []string {
 	}
 }
 
+func agentNodeHeaders() []string {
+	headers := []string{"NAME", "PHASE", "PODS", "HEARTBEAT", "AGE"}
+	if outputFormat == "wide" {
+		headers = append(headers, "MAX-PODS", "HOSTNAME", "VERSION")
+	}
+	return headers
+}
+
+func agentNodeToRow(v interface{}) []string {
+	node, ok := v.(*v1alpha1.AgentNode)
+	if !ok {
+		return []string{"?", "?", "?", "?", "?"}
+	}
+	heartbeat := "<none>"
+	if node.Status.LastHeartbeat != nil {
+		heartbeat = formatAge(*node.Status.LastHeartbeat)
+	}
+	row := []string{
+		node.Metadata.Name,
+		colorPhase(string(node.Status.Phase)),
+		strconv.Itoa(node.Status.Pods),
+		heartbeat,
+		formatAge(node.Metadata.CreatedAt),
+	}
+	if outputFormat == "wide" {
+		maxPods := "<none>"
+		if node.Spec.MaxPods > 0 {
+			maxPods = strconv.Itoa(node.Spec.MaxPods)
+		}
+		row = append(row, maxPods, node.Status.Hostname, node.Status.Version)
+	}
+	return row
+}
+
 // colorPhase returns a colored string for known phases.
 func colorPhase(phase string) string {
 	switch phase {
 	case "Ready", "Succeeded":
 		return color.GreenString(phase)
-	case "Failed":
+	case "Failed", "NotReady":
 		return color.RedString(phase)
 	case "Busy", "Running":
 		return color.YellowString(phase)
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// toGeneric converts v to its generic JSON form: maps, slices, strings,
+// float64s, bools and nil.
+func toGeneric(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// evalPath resolves a simple JSONPath in the generic value v. The path is a
+// sequence of ".field", "[index]" and "[*]" (every element) steps, e.g.
+// ".metadata.name" or ".status.steps[*].tool"; an optional leading "$" and
+// surrounding braces are ignored. Missing fields yield no results.
+func evalPath(v interface{}, path string) ([]interface{}, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}")
+	path = strings.TrimPrefix(path, "$")
+
+	current := []interface{}{v}
+	for path != "" {
+		var next []interface{}
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			field := path[:end]
+			path = path[end:]
+			if field == "" {
+				if path == "" {
+					// A lone "." selects the object itself.
+					return current, nil
+				}
+				return nil, fmt.Errorf("invalid path: empty field name")
+			}
+			for _, c := range current {
+				if m, ok := c.(map[string]interface{}); ok {
+					if val, ok := m[field]; ok {
+						next = append(next, val)
+					}
+				}
+			}
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path: missing ]")
+			}
+			index := path[1:end]
+			path = path[end+1:]
+			for _, c := range current {
+				list, ok := c.([]interface{})
+				if !ok {
+					continue
+				}
+				if index == "*" {
+					next = append(next, list...)
+					continue
+				}
+				i, err := strconv.Atoi(index)
+				if err != nil {
+					return nil, fmt.Errorf("invalid path: bad index %q", index)
+				}
+				if i < 0 {
+					i += len(list)
+				}
+				if i >= 0 && i < len(list) {
+					next = append(next, list[i])
+				}
+			}
+		default:
+			return nil, fmt.Errorf("invalid path %q: steps start with . or [", path)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// formatValue renders a generic JSON value for display: strings as is,
+// whole numbers without a fraction and objects and lists as JSON.
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		raw, _ := json.Marshal(val)
+		return string(raw)
+	}
+}
+
+// compareValues orders generic JSON values: numbers numerically, RFC 3339
+// timestamps chronologically and everything else by its text. A missing
+// value (nil) sorts first.
+func compareValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if fa, ok := a.(float64); ok {
+		if fb, ok := b.(float64); ok {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	sa, sb := formatValue(a), formatValue(b)
+	if ta, err := time.Parse(time.RFC3339Nano, sa); err == nil {
+		if tb, err := time.Parse(time.RFC3339Nano, sb); err == nil {
+			return ta.Compare(tb)
+		}
+	}
+	return strings.Compare(sa, sb)
+}
+
+// renderJSONPath evaluates a kubectl-style JSONPath template against the
+// generic value v: text outside braces is copied, each {path} is replaced by
+// the values it resolves to, separated by spaces, and {"..."} by the quoted
+// string (e.g. {"\n"}). A template without braces is a single path.
+func renderJSONPath(v interface{}, tmpl string) (string, error) {
+	if !strings.Contains(tmpl, "{") {
+		tmpl = "{" + tmpl + "}"
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(tmpl, '{')
+		if start < 0 {
+			b.WriteString(tmpl)
+			return b.String(), nil
+		}
+		b.WriteString(tmpl[:start])
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unclosed { in template")
+		}
+		expr := strings.TrimSpace(tmpl[start+1 : start+end])
+		tmpl = tmpl[start+end+1:]
+
+		if strings.HasPrefix(expr, `"`) {
+			lit, err := strconv.Unquote(expr)
+			if err != nil {
+				return "", fmt.Errorf("invalid string %s in template", expr)
+			}
+			b.WriteString(lit)
+			continue
+		}
+		values, err := evalPath(v, expr)
+		if err != nil {
+			return "", err
+		}
+		for i, val := range values {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(formatValue(val))
+		}
+	}
+}
+
+// customColumn is one column of -o custom-columns.
+type customColumn struct {
+	header string
+	path   string
+}
+
+// parseCustomColumns parses a custom-columns spec such as
+// "NAME:.metadata.name,PHASE:.status.phase".
+func parseCustomColumns(spec string) ([]customColumn, error) {
+	var cols []customColumn
+	for _, part := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(part, ":")
+		if !ok || header == "" || path == "" {
+			return nil, fmt.Errorf("custom-columns: expected HEADER:path, got %q", part)
+		}
+		if _, err := evalPath(map[string]interface{}{}, path); err != nil {
+			return nil, fmt.Errorf("custom-columns: %w", err)
+		}
+		cols = append(cols, customColumn{header: header, path: path})
+	}
+	return cols, nil
+}
@@ -1,15 +1,23 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
 )
 
 func newLogsCmd() *cobra.Command {
-	var follow bool
+	var (
+		follow     bool
+		timestamps bool
+		opts       client.LogOptions
+	)
 
 	cmd := &cobra.Command{
 		Use:   "logs <podname>",
@@ -17,71 +25,73 @@ func newLogsCmd() *cobra.Command {
 		Long:  "Retrieve and display log entries from a specific agent pod.",
 		Example: `  orca logs my-agent
   orca logs my-agent -p myproject
+  orca logs my-agent --tail=20
+  orca logs my-agent --since=10m --timestamps
   orca logs my-agent --follow`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			project, _ := cmd.Flags().GetString("project")
 			podName := args[0]
 
+			if opts.Tail < 0 {
+				return fmt.Errorf("--tail must be >= 0, got %d", opts.Tail)
+			}
+			if opts.Since < 0 {
+				return fmt.Errorf("--since must be positive, got %s", opts.Since)
+			}
+
 			if follow {
-				return logsFollow(podName, project)
+				return logsFollow(cmd.Context(), podName, project, opts, timestamps)
 			}
 
-			return logsPrint(podName, project)
+			return logsPrint(podName, project, opts, timestamps)
 		},
 	}
 
 	cmd.Flags().StringP("project", "p", "default", "Project name")
-	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Follow log output (polls every 2 seconds)")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Stream new log entries as they are written")
+	cmd.Flags().IntVar(&opts.Tail, "tail", 0, "Number of most recent lines to show (0 shows all)")
+	cmd.Flags().DurationVar(&opts.Since, "since", 0, "Only show lines newer than this duration, e.g. 10m or 1h")
+	cmd.Flags().BoolVar(&timestamps, "timestamps", false, "Prefix each line with its timestamp")
 
 	return cmd
 }
 
-func logsPrint(podName, project string) error {
-	entries, err := apiClient.GetLogs(podName, project)
+func logsPrint(podName, project string, opts client.LogOptions, timestamps bool) error {
+	entries, err := apiClient.GetLogs(podName, project, opts)
 	if err != nil {
 		return err
 	}
 
 	if len(entries) == 0 {
-		fmt.Printf("No logs found for pod %s.\n", podName)
+		printNone(fmt.Sprintf("No logs found for pod %s.", podName))
 		return nil
 	}
 
 	for _, entry := range entries {
-		printLogEntry(entry.Timestamp, entry.Level, entry.Message)
+		printLogEntry(entry, timestamps)
 	}
 
 	return nil
 }
 
-func logsFollow(podName, project string) error {
-	// Track the number of entries we've already printed to avoid duplicates.
-	seen := 0
-
+func logsFollow(ctx context.Context, podName, project string, opts client.LogOptions, timestamps bool) error {
+	entries, err := apiClient.StreamLogEntries(ctx, podName, project, opts)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("Following logs for pod %s (Ctrl+C to stop)...\n", podName)
 
-	for {
-		entries, err := apiClient.GetLogs(podName, project)
-		if err != nil {
-			return err
-		}
-
-		// Print only new entries.
-		if len(entries) > seen {
-			for _, entry := range entries[seen:] {
-				printLogEntry(entry.Timestamp, entry.Level, entry.Message)
-			}
-			seen = len(entries)
-		}
-
-		time.Sleep(2 * time.Second)
+	for entry := range entries {
+		printLogEntry(entry, timestamps)
 	}
+	return nil
 }
 
-// printLogEntry prints a single formatted log line.
-func printLogEntry(ts time.Time, level, message string) {
-	timestamp := ts.Format("2006-01-02 15:04:05")
+// printLogEntry prints a single formatted log line, optionally prefixed with
+// its timestamp.
+func printLogEntry(entry v1alpha1.LogEntry, timestamps bool) {
+	level := entry.Level
 
 	var levelStr string
 	switch level {
@@ -97,5 +107,9 @@ func printLogEntry(ts time.Time, level, message string) {
 		levelStr = fmt.Sprintf("%-5s", level)
 	}
 
-	fmt.Printf("[%s] [%s] %s\n", timestamp, levelStr, message)
+	if timestamps {
+		fmt.Printf("%s [%s] %s\n", entry.Timestamp.Format(time.RFC3339), levelStr, entry.Message)
+		return
+	}
+	fmt.Printf("[%s] %s\n", levelStr, entry.Message)
 }
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/klubi/orca/pkg/manifest"
+)
+
+// loadManifests parses every manifest named by paths. Each path may be a
+// file, a directory (its manifest files; subdirectories too when recursive),
+// an overlay (see manifest.Overlay), a glob pattern, "-" for stdin or an
+// http(s) URL, optionally pinned to a checksum; see manifest.ParseFiles and
+// manifest.ParseURL. Resources are returned in dependency order, see
+// manifest.SortByDependency.
+func loadManifests(paths []string, recursive bool, opts ...manifest.Option) ([]interface{}, error) {
+	var (
+		resources []interface{}
+		local     []string
+	)
+	for _, p := range paths {
+		switch {
+		case p == "-":
+			parsed, err := manifest.ParseReader(os.Stdin, opts...)
+			if err != nil {
+				return nil, fmt.Errorf("parsing manifest from stdin: %w", err)
+			}
+			resources = append(resources, parsed...)
+		case manifest.IsURL(p):
+			parsed, err := manifest.ParseURL(p, opts...)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, parsed...)
+		default:
+			local = append(local, p)
+		}
+	}
+
+	if recursive {
+		opts = append(opts, manifest.Recursive())
+	}
+	parsed, err := manifest.ParseFiles(local, opts...)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, parsed...)
+	manifest.SortByDependency(resources)
+	return resources, nil
+}
+
+// manifestFlags are the flags of commands that read manifests, which
+// change how they are parsed.
+type manifestFlags struct {
+	expandEnv bool
+	values    string
+	strict    bool
+}
+
+// options returns the parse options the flags ask for.
+func (f *manifestFlags) options() ([]manifest.Option, error) {
+	var opts []manifest.Option
+	if f.values != "" {
+		values, err := manifest.LoadValues(f.values)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, manifest.WithValues(values))
+	}
+	if f.expandEnv {
+		opts = append(opts, manifest.WithEnv(os.LookupEnv))
+	}
+	if f.strict {
+		opts = append(opts, manifest.Strict())
+	}
+	return opts, nil
+}
@@ -4,18 +4,51 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/klubi/orca/pkg/manifest"
 )
 
 // outputFormat is set by the root command's -o flag.
-// Supported values: "table" (default), "json", "yaml".
+// Supported values: "table" (default), "wide", "json", "yaml",
+// "custom-columns=<spec>" and "jsonpath=<template>".
 var outputFormat string
 
+// exportOutput is set by get's --export flag. JSON and YAML output then omit
+// the fields the server populates, so it can be applied again as a manifest.
+var exportOutput bool
+
+// sortBy is set by get's --sort-by flag: a JSONPath (see evalPath) that
+// lists are ordered by.
+var sortBy string
+
+// quiet is set by the root command's -q flag. Tables are then reduced to
+// their NAME column (or their first column) without headers, and commands
+// print only the resources they acted on.
+var quiet bool
+
 // printTable writes tabular data to stdout using aligned columns.
 func printTable(headers []string, rows [][]string) {
+	if quiet {
+		col := 0
+		for i, h := range headers {
+			if h == "NAME" {
+				col = i
+				break
+			}
+		}
+		for _, row := range rows {
+			if col < len(row) {
+				fmt.Println(row[col])
+			}
+		}
+		return
+	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	for i, h := range headers {
 		if i > 0 {
@@ -55,6 +88,29 @@ func printYAML(v interface{}) error {
 // For table output it uses the provided headers and toRow function to convert
 // each item in a slice to a row of strings.
 func printOutput(v interface{}, headers []string, toRow func(interface{}) []string) {
+	if items, ok := v.([]interface{}); ok && sortBy != "" {
+		if err := sortItems(items, sortBy); err != nil {
+			exitError(fmt.Sprintf("failed to sort: %v", err))
+		}
+	}
+	if spec, ok := strings.CutPrefix(outputFormat, "custom-columns="); ok {
+		if err := printCustomColumns(v, spec); err != nil {
+			exitError(err.Error())
+		}
+		return
+	}
+	if tmpl, ok := strings.CutPrefix(outputFormat, "jsonpath="); ok {
+		if err := printJSONPath(v, tmpl); err != nil {
+			exitError(fmt.Sprintf("jsonpath: %v", err))
+		}
+		return
+	}
+	if exportOutput && (outputFormat == "json" || outputFormat == "yaml") {
+		if err := printExport(v); err != nil {
+			exitError(fmt.Sprintf("failed to export: %v", err))
+		}
+		return
+	}
 	switch outputFormat {
 	case "json":
 		if err := printJSON(v); err != nil {
@@ -65,7 +121,8 @@ func printOutput(v interface{}, headers []string, toRow func(interface{}) []stri
 			exitError(fmt.Sprintf("failed to encode YAML: %v", err))
 		}
 	default:
-		// Table output: v must be a slice represented as []interface{}.
+		// Table (and wide) output: v must be a slice represented as
+		// []interface{}.
 		items, ok := v.([]interface{})
 		if !ok {
 			// Single item -- wrap in a slice.
@@ -79,10 +136,175 @@ func printOutput(v interface{}, headers []string, toRow func(interface{}) []stri
 	}
 }
 
-// exitError prints an error message to stderr and exits with code 1.
+// validateOutputFormat checks the -o flag's value.
+func validateOutputFormat() error {
+	switch outputFormat {
+	case "table", "wide", "json", "yaml":
+		return nil
+	}
+	if spec, ok := strings.CutPrefix(outputFormat, "custom-columns="); ok {
+		_, err := parseCustomColumns(spec)
+		return err
+	}
+	if _, ok := strings.CutPrefix(outputFormat, "jsonpath="); ok {
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q (want table, wide, json, yaml, custom-columns=... or jsonpath=...)", outputFormat)
+}
+
+// printCustomColumns prints v, a resource or a list of resources, as a table
+// with the columns of a custom-columns spec.
+func printCustomColumns(v interface{}, spec string) error {
+	cols, err := parseCustomColumns(spec)
+	if err != nil {
+		return err
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		items = []interface{}{v}
+	}
+
+	headers := make([]string, len(cols))
+	for i, col := range cols {
+		headers[i] = col.header
+	}
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		generic, err := toGeneric(item)
+		if err != nil {
+			return err
+		}
+		row := make([]string, len(cols))
+		for i, col := range cols {
+			values, err := evalPath(generic, col.path)
+			if err != nil {
+				return err
+			}
+			parts := make([]string, 0, len(values))
+			for _, val := range values {
+				if s := formatValue(val); s != "" {
+					parts = append(parts, s)
+				}
+			}
+			row[i] = strings.Join(parts, ",")
+			if row[i] == "" {
+				row[i] = "<none>"
+			}
+		}
+		rows = append(rows, row)
+	}
+	printTable(headers, rows)
+	return nil
+}
+
+// printJSONPath prints a JSONPath template (see renderJSONPath) evaluated
+// against v. As with kubectl, a list is presented as an object whose items
+// field holds the resources.
+func printJSONPath(v interface{}, tmpl string) error {
+	if items, ok := v.([]interface{}); ok {
+		v = map[string]interface{}{"items": items}
+	}
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	out, err := renderJSONPath(generic, tmpl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
+
+// printExport writes v, a resource or a list of resources, with the
+// server-populated fields stripped. A list is written as a multi-document
+// YAML stream, or a JSON array.
+func printExport(v interface{}) error {
+	items, isList := v.([]interface{})
+	if !isList {
+		items = []interface{}{v}
+	}
+	if outputFormat == "yaml" {
+		return manifest.Marshal(items, os.Stdout, manifest.OmitServerFields())
+	}
+
+	docs := make([]interface{}, len(items))
+	for i, item := range items {
+		doc, err := manifest.Export(item)
+		if err != nil {
+			return err
+		}
+		docs[i] = doc
+	}
+	if isList {
+		return printJSON(docs)
+	}
+	return printJSON(docs[0])
+}
+
+// sortItems orders items by the value path resolves to in each, see
+// compareValues. The sort is stable.
+func sortItems(items []interface{}, path string) error {
+	keys := make([]interface{}, len(items))
+	for i, item := range items {
+		generic, err := toGeneric(item)
+		if err != nil {
+			return err
+		}
+		values, err := evalPath(generic, path)
+		if err != nil {
+			return err
+		}
+		if len(values) > 0 {
+			keys[i] = values[0]
+		}
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return compareValues(keys[order[a]], keys[order[b]]) < 0
+	})
+	sorted := make([]interface{}, len(items))
+	for i, j := range order {
+		sorted[i] = items[j]
+	}
+	copy(items, sorted)
+	return nil
+}
+
+// printChanged reports that a command acted on a resource, e.g.
+// "devtask/fix-login deleted". With -q only the resource is printed.
+func printChanged(ref, action string) {
+	if quiet {
+		fmt.Println(ref)
+		return
+	}
+	fmt.Println(ref, action)
+}
+
+// appliedVerb is the action printChanged reports for an applied resource.
+func appliedVerb(created bool) string {
+	if created {
+		return "created"
+	}
+	return "configured"
+}
+
+// printNone prints a message such as "No dev tasks found." for an empty
+// result. It is left out with -q, so empty results print nothing.
+func printNone(msg string) {
+	if !quiet {
+		fmt.Println(msg)
+	}
+}
+
+// exitError prints an error message to stderr and exits with ExitError.
 func exitError(msg string) {
 	fmt.Fprintf(os.Stderr, "Error: %s\n", msg)
-	os.Exit(1)
+	os.Exit(ExitError)
 }
 
 // formatAge returns a human-readable duration string relative to the given
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klubi/orca/pkg/client"
+)
+
+func newPatchCmd() *cobra.Command {
+	var (
+		patch     string
+		patchType string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "patch <resource-type> <name> -p <patch>",
+		Short: "Update fields of a resource",
+		Long: `Update individual fields of a resource with a JSON merge patch (default)
+or a JSON patch (--type=json).`,
+		Example: `  orca patch pool my-pool -p '{"spec":{"replicas":4}}'
+  orca patch pod my-agent -p '{"spec":{"maxConcurrency":4}}' --project myproject
+  orca patch task fix-bug --type=json -p '[{"op":"replace","path":"/spec/maxRetries","value":3}]'`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetString("project")
+			resourceType := normalizeResourceType(args[0])
+			name := args[1]
+
+			switch resourceType {
+			case "agentpods", "agentpools", "devtasks", "projects":
+			default:
+				return withExitCode(ExitUsage, fmt.Errorf("unknown resource type %q. Valid types: agentpods, agentpools, devtasks, projects", args[0]))
+			}
+
+			var pt client.PatchType
+			switch patchType {
+			case "merge":
+				pt = client.MergePatchType
+			case "json":
+				pt = client.JSONPatchType
+			default:
+				return fmt.Errorf("unknown patch type %q (want merge or json)", patchType)
+			}
+
+			if !json.Valid([]byte(patch)) {
+				return fmt.Errorf("patch is not valid JSON")
+			}
+
+			var out interface{}
+			if err := apiClient.Patch(resourceType, name, project, pt, []byte(patch), &out); err != nil {
+				return err
+			}
+
+			switch outputFormat {
+			case "json":
+				return printJSON(out)
+			case "yaml":
+				return printYAML(out)
+			}
+			printChanged(strings.TrimSuffix(resourceType, "s")+"/"+name, "patched")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&patch, "patch", "p", "", "The patch document (JSON)")
+	cmd.Flags().StringVar(&patchType, "type", "merge", "Patch type: merge|json")
+	cmd.Flags().String("project", "default", "Project name")
+	cmd.MarkFlagRequired("patch")
+
+	return cmd
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klubi/orca/internal/config"
+)
+
+func newProjectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "project",
+		Aliases: []string{"proj"},
+		Short:   "Set or show the default project",
+		Long: `Commands taking -p/--project default to the project chosen with
+"orca project use", which is stored in ~/.orca/config (or $ORCA_CONFIG).
+When a context is in use the project is stored in that context.`,
+		Example: `  orca project use myproject
+  orca project current`,
+	}
+
+	cmd.AddCommand(newProjectUseCmd(), newProjectCurrentCmd())
+	return cmd
+}
+
+func newProjectUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Make a project the default for later commands",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			// "default" need not exist as a resource.
+			if name != "default" {
+				if _, err := apiClient.GetProject(name); err != nil {
+					return fmt.Errorf("project %q: %w", name, err)
+				}
+			}
+
+			path := config.DefaultClientConfigPath()
+			cfg, err := config.LoadClientConfig(path)
+			if err != nil {
+				return err
+			}
+			ctx := cfg.Current()
+			if contextName != "" {
+				ctx = cfg.Context(contextName)
+			}
+			if ctx != nil {
+				ctx.Project = name
+			} else {
+				cfg.Project = name
+			}
+			if err := cfg.Save(path); err != nil {
+				return err
+			}
+
+			if ctx != nil {
+				fmt.Printf("now using project %q in context %q\n", name, ctx.Name)
+			} else {
+				fmt.Printf("now using project %q\n", name)
+			}
+			return nil
+		},
+	}
+}
+
+func newProjectCurrentCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "current",
+		Short: "Print the default project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadClientConfig(config.DefaultClientConfigPath())
+			if err != nil {
+				return err
+			}
+			project := cfg.Project
+			ctx := cfg.Current()
+			if contextName != "" {
+				ctx = cfg.Context(contextName)
+			}
+			if ctx != nil && ctx.Project != "" {
+				project = ctx.Project
+			}
+			if project == "" {
+				project = "default"
+			}
+			fmt.Println(project)
+			return nil
+		},
+	}
+}
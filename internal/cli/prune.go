@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+)
+
+func newPruneCmd() *cobra.Command {
+	var (
+		olderThan string
+		phases    []string
+		selector  string
+		dryRun    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune tasks",
+		Short: "Delete finished tasks",
+		Long: `Delete the tasks of a project that finished in one of the given phases,
+together with their artifacts. With --older-than only tasks that finished at
+least that long ago are deleted.`,
+		Example: `  orca prune tasks --older-than 24h
+  orca prune tasks --older-than 7d --phase Failed -p myproject
+  orca prune tasks -l batch=nightly --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetString("project")
+			if normalizeResourceType(args[0]) != "devtasks" {
+				return withExitCode(ExitUsage, fmt.Errorf("pruning is only supported for tasks, got %q", args[0]))
+			}
+
+			opts := []client.ListOption{client.WithLabelSelector(selector)}
+			if olderThan != "" {
+				d, err := parseLongDuration(olderThan)
+				if err != nil {
+					return fmt.Errorf("--older-than %w", err)
+				}
+				opts = append(opts, client.WithOlderThan(d))
+			}
+			var want []v1alpha1.DevTaskPhase
+			for _, p := range phases {
+				phase, ok := finishedPhase(p)
+				if !ok {
+					return fmt.Errorf("--phase: %q is not a finished phase (want Succeeded or Failed)", p)
+				}
+				want = append(want, phase)
+			}
+			opts = append(opts, client.WithPhases(want...))
+			if dryRun {
+				opts = append(opts, client.WithDryRun())
+			}
+
+			deleted, err := apiClient.DeleteDevTasks(project, opts...)
+			if err != nil {
+				return err
+			}
+			if len(deleted) == 0 {
+				printNone("No tasks to prune.")
+				return nil
+			}
+			suffix := "deleted"
+			if dryRun {
+				suffix = "would be deleted (dry run)"
+			}
+			for _, name := range deleted {
+				printChanged("devtask/"+name, suffix)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("project", "p", "default", "Project name")
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Only prune tasks that finished at least this long ago, e.g. 24h or 7d")
+	cmd.Flags().StringSliceVar(&phases, "phase", []string{string(v1alpha1.TaskSucceeded), string(v1alpha1.TaskFailed)}, "Phases to prune (comma-separated)")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Only prune tasks matching this label selector")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the tasks that would be deleted without deleting them")
+
+	return cmd
+}
+
+// finishedPhase matches s case-insensitively against the phases of finished
+// tasks.
+func finishedPhase(s string) (v1alpha1.DevTaskPhase, bool) {
+	for _, phase := range []v1alpha1.DevTaskPhase{v1alpha1.TaskSucceeded, v1alpha1.TaskFailed} {
+		if strings.EqualFold(s, string(phase)) {
+			return phase, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/apiserver"
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/controller"
+	"github.com/klubi/orca/internal/events"
+	"github.com/klubi/orca/internal/logging"
+	"github.com/klubi/orca/internal/scheduler"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// configPollInterval is how often orca serve checks its config file for
+// changes.
+const configPollInterval = 2 * time.Second
+
+// configReloader applies the runtime-tunable settings of a changed config
+// file to a running control plane.
+type configReloader struct {
+	path     string
+	current  *config.Config
+	levels   *logging.Levels
+	runtime  *agent.Runtime
+	sched    *scheduler.Scheduler
+	health   *controller.HealthCheckController
+	apiSrv   *apiserver.Server
+	recorder *events.Recorder
+	logger   *zap.Logger
+}
+
+// reload applies next, the configuration read from the changed file, or
+// reports err reading it. An invalid configuration is not applied at all.
+func (r *configReloader) reload(next *config.Config, err error) {
+	// Events about the config file go to the default project, where
+	// `orca get events` looks without -p.
+	ref := v1alpha1.ObjectReference{Kind: "Config", Name: filepath.Base(r.path), Project: "default"}
+
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		r.logger.Warn("config file changed but was not reloaded", zap.String("path", r.path), zap.Error(err))
+		r.recorder.Warning(ref, "ConfigReloadFailed", "%s was not reloaded: %v", r.path, err)
+		return
+	}
+
+	applied, changed, needRestart := r.current.Reload(next)
+	if len(needRestart) > 0 {
+		r.logger.Warn("config changes that need a restart were not applied",
+			zap.Strings("settings", needRestart))
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	if err := r.levels.Set(applied.Log.Level, applied.Log.Components); err != nil {
+		r.logger.Warn("cannot change log levels", zap.Error(err))
+	}
+	r.runtime.SetConfig(applied)
+	r.sched.SetWeights(applied.Scheduler.Weights)
+	r.health.SetInterval(time.Duration(applied.Agent.HealthCheckInterval) * time.Second)
+	r.apiSrv.SetRateLimit(applied.Server.RateLimit)
+	r.current = applied
+
+	r.logger.Info("config reloaded", zap.String("path", r.path), zap.Strings("settings", changed))
+	r.recorder.Normal(ref, "ConfigReloaded", "Reloaded %s: %s", r.path, strings.Join(changed, ", "))
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+)
+
+func newRolloutCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollout",
+		Short: "Manage the rollout of an agent pool",
+		Long: `Manage rolling updates of agent pools. A change to a pool's template
+replaces its pods one at a time; idle pods are replaced first and busy pods
+once they finish their current task.`,
+	}
+
+	cmd.AddCommand(newRolloutStatusCmd(), newRolloutRestartCmd())
+	return cmd
+}
+
+func newRolloutStatusCmd() *cobra.Command {
+	var (
+		watch   bool
+		timeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "status pool/<name>",
+		Short: "Show the status of a pool rollout",
+		Example: `  orca rollout status pool/coding-team
+  orca rollout status pool coding-team -p my-erp --timeout=5m`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetString("project")
+			name, err := rolloutTarget(args)
+			if err != nil {
+				return err
+			}
+
+			var deadline time.Time
+			if timeout > 0 {
+				deadline = time.Now().Add(timeout)
+			}
+
+			last := ""
+			for {
+				pool, err := apiClient.GetAgentPool(name, project)
+				if err != nil {
+					return err
+				}
+
+				msg, done := rolloutProgress(pool)
+				if msg != last {
+					fmt.Println(msg)
+					last = msg
+				}
+				if done || !watch {
+					return nil
+				}
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					return withExitCode(ExitTimeout, fmt.Errorf("timed out waiting for pool %q to roll out", name))
+				}
+				time.Sleep(time.Second)
+			}
+		},
+	}
+
+	cmd.Flags().StringP("project", "p", "default", "Project name")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", true, "Wait until the rollout finishes")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Give up waiting after this long (0 = wait forever)")
+
+	return cmd
+}
+
+func newRolloutRestartCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restart pool/<name>",
+		Short: "Gracefully replace every pod of a pool",
+		Example: `  orca rollout restart pool/coding-team
+  orca rollout restart pool coding-team -p my-erp`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetString("project")
+			name, err := rolloutTarget(args)
+			if err != nil {
+				return err
+			}
+
+			// Changing a template label changes the template hash, which
+			// makes the pool controller roll every pod.
+			patch, err := json.Marshal(map[string]interface{}{
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"labels": map[string]string{
+								v1alpha1.LabelRestartedAt: fmt.Sprintf("%d", time.Now().Unix()),
+							},
+						},
+					},
+				},
+			})
+			if err != nil {
+				return err
+			}
+			if err := apiClient.Patch("agentpools", name, project, client.MergePatchType, patch, nil); err != nil {
+				return err
+			}
+
+			printChanged("agentpool/"+name, "restarted")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("project", "p", "default", "Project name")
+
+	return cmd
+}
+
+// rolloutTarget accepts "pool/<name>" or "pool <name>" and returns the name.
+func rolloutTarget(args []string) (string, error) {
+	kind, name := args[0], ""
+	if len(args) == 2 {
+		name = args[1]
+	} else if i := strings.Index(kind, "/"); i >= 0 {
+		kind, name = kind[:i], kind[i+1:]
+	}
+	if normalizeResourceType(kind) != "agentpools" {
+		return "", withExitCode(ExitUsage, fmt.Errorf("rollout is only supported for agentpools, got %q", kind))
+	}
+	if name == "" {
+		return "", fmt.Errorf("pool name is required")
+	}
+	return name, nil
+}
+
+// rolloutProgress describes how far a pool's rollout has come and whether it
+// is complete.
+func rolloutProgress(pool *v1alpha1.AgentPool) (string, bool) {
+	desired := pool.Spec.Replicas
+	st := pool.Status
+	available := st.ReadyReplicas + st.BusyReplicas
+
+	switch {
+	case st.TemplateHash != pool.Spec.Template.Hash():
+		return fmt.Sprintf("Waiting for pool %q rollout to start...", pool.Metadata.Name), false
+	case st.UpdatedReplicas < desired:
+		return fmt.Sprintf("Waiting for pool %q rollout to finish: %d of %d updated replicas...",
+			pool.Metadata.Name, st.UpdatedReplicas, desired), false
+	case st.Replicas > desired:
+		return fmt.Sprintf("Waiting for pool %q rollout to finish: %d old replicas pending termination...",
+			pool.Metadata.Name, st.Replicas-desired), false
+	case available < desired:
+		return fmt.Sprintf("Waiting for pool %q rollout to finish: %d of %d updated replicas are available...",
+			pool.Metadata.Name, available, desired), false
+	default:
+		return fmt.Sprintf("pool %q successfully rolled out", pool.Metadata.Name), true
+	}
+}
@@ -1,51 +1,115 @@
 package cli
 
 import (
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/klubi/orca/internal/version"
 	"github.com/klubi/orca/pkg/client"
 	"github.com/spf13/cobra"
 )
 
 var (
-	serverAddr string
-	apiClient  *client.Client
+	serverAddr  string
+	contextName string
+	token       string
+	noColor     bool
+	retries     int
+	apiClient   client.Interface
+
+	// requestTimeout bounds each API request.
+	requestTimeout time.Duration
+
+	// activeContext is the name of the context in use, if any.
+	activeContext string
 )
 
 // NewRootCmd creates the top-level orca CLI command with all subcommands.
 func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "orca",
-		Short: "Kubernetes-inspired AI Agent Orchestration",
+		Use:     "orca",
+		Short:   "Kubernetes-inspired AI Agent Orchestration",
+		Version: version.Get(),
 		Long: `Orca orchestrates AI agents using Kubernetes patterns.
-Manage agent pods, pools, and development tasks.`,
+Manage agent pods, pools, and development tasks.
+
+` + exitCodeHelp,
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(); err != nil {
+				return withExitCode(ExitUsage, err)
+			}
+			// cobra checks required flags only after this hook; checking
+			// them here lets them fail with the usage exit code.
+			if err := cmd.ValidateRequiredFlags(); err != nil {
+				return withExitCode(ExitUsage, err)
+			}
+			if noColor || os.Getenv("NO_COLOR") != "" {
+				color.NoColor = true
+				// The terminal UI follows NO_COLOR as well.
+				os.Setenv("NO_COLOR", "1")
+			}
 			// Skip client init for commands that don't need the API server.
 			name := cmd.Name()
-			if name == "serve" || name == "init" || name == "ui" {
-				return
+			if name == "serve" || name == "init" || (cmd.HasParent() && cmd.Parent().Name() == "config") {
+				return nil
+			}
+			opts, err := resolveContext(cmd)
+			if err != nil {
+				return err
+			}
+			policy := client.DefaultRetryPolicy()
+			policy.MaxRetries = max(retries, 0)
+			opts = append(opts, client.WithRetryPolicy(policy), client.WithTimeout(requestTimeout))
+			if cmd.Flags().Changed("token") {
+				opts = append(opts, client.WithToken(token))
 			}
-			apiClient = client.New(serverAddr)
+			apiClient = client.New(serverAddr, opts...)
+			return nil
 		},
 	}
 
 	cmd.PersistentFlags().StringVar(&serverAddr, "server", "http://127.0.0.1:7117", "Orca server address")
-	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format: table|json|yaml")
+	cmd.PersistentFlags().StringVar(&contextName, "context", "", "Context from ~/.orca/config to use (default: the current context)")
+	cmd.PersistentFlags().StringVar(&token, "token", "", "Bearer token for the API server (overrides the context's)")
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format: table|wide|json|yaml|custom-columns=<spec>|jsonpath=<template>")
+	cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only resource names")
+	cmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also set by the NO_COLOR environment variable)")
+	cmd.PersistentFlags().DurationVar(&requestTimeout, "request-timeout", 30*time.Second, "Time limit of each API request (0 disables)")
+	cmd.PersistentFlags().IntVar(&retries, "retries", client.DefaultRetryPolicy().MaxRetries, "Times to retry reads, updates and deletes that fail transiently (0 disables)")
 
 	cmd.AddCommand(
 		newServeCmd(),
+		newServerCmd(),
+		newAgentCmd(),
 		newApplyCmd(),
 		newGetCmd(),
 		newDescribeCmd(),
 		newDeleteCmd(),
+		newPruneCmd(),
 		newLogsCmd(),
 		newRunCmd(),
 		newScaleCmd(),
+		newAutoscaleCmd(),
+		newPatchCmd(),
+		newRolloutCmd(),
+		newEventsCmd(),
+		newTopCmd(),
+		newCostCmd(),
 		newStatusCmd(),
 		newExecCmd(),
+		newCpCmd(),
+		newArtifactsCmd(),
 		newInitCmd(),
+		newConfigCmd(),
+		newProjectCmd(),
+		newBackupCmd(),
+		newRestoreCmd(),
 		newUICmd(),
 	)
+	markUsageErrors(cmd)
 
 	return cmd
 }
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -8,112 +10,313 @@ import (
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"github.com/klubi/orca/internal/store"
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+	"github.com/klubi/orca/pkg/manifest"
 )
 
 func newRunCmd() *cobra.Command {
 	var (
-		model   string
-		project string
-		timeout int
+		model        string
+		project      string
+		timeout      int
+		follow       bool
+		filename     string
+		capabilities []string
+		labels       []string
+		dependsOn    []string
+		pool         string
+		priority     int
 	)
 
 	cmd := &cobra.Command{
-		Use:   "run -- <prompt>",
+		Use:   "run [-f task.yaml] [-- <prompt>]",
 		Short: "Run a one-shot task",
 		Long: `Create a temporary DevTask from a prompt and wait for completion.
 
-Everything after "--" is treated as the prompt text.`,
+Everything after "--" is treated as the prompt text. With -f the task is read
+from a manifest holding a single DevTask; flags and a prompt given on the
+command line override the manifest's values.`,
 		Example: `  orca run -- "Write a hello world program in Go"
   orca run --model claude-haiku -- "Summarize this code"
-  orca run -p myproject -- "Fix the bug in auth.go"`,
+  orca run -p myproject -- "Fix the bug in auth.go"
+  orca run --follow -- "Refactor the config loader"
+  orca run --capabilities go,testing --pool reviewers --label team=web -- "Review auth.go"
+  orca run -f task.yaml --depends-on build-api
+  orca run --priority 10 -- "Fix the failing release build"`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				return fmt.Errorf("prompt required: orca run -- \"your prompt here\"")
-			}
-			prompt := strings.Join(args, " ")
-
-			// Generate a unique task name based on the current time.
-			taskName := fmt.Sprintf("run-%d", time.Now().UnixMilli())
-
-			task := &v1alpha1.DevTask{
-				TypeMeta: v1alpha1.TypeMeta{
-					APIVersion: v1alpha1.APIVersion,
-					Kind:       v1alpha1.KindDevTask,
-				},
-				Metadata: v1alpha1.ObjectMeta{
-					Name:    taskName,
-					Project: project,
-				},
-				Spec: v1alpha1.DevTaskSpec{
-					Prompt:         prompt,
-					PreferredModel: model,
-					MaxRetries:     0,
-					TimeoutSeconds: timeout,
-				},
+			var task *v1alpha1.DevTask
+			if filename != "" {
+				var err error
+				if task, err = loadRunTask(filename); err != nil {
+					return err
+				}
+			} else {
+				if len(args) == 0 {
+					return fmt.Errorf("prompt required: orca run -- \"your prompt here\"")
+				}
+				task = &v1alpha1.DevTask{
+					TypeMeta: v1alpha1.TypeMeta{
+						APIVersion: v1alpha1.APIVersion,
+						Kind:       v1alpha1.KindDevTask,
+					},
+				}
 			}
 
+			// Command-line values override the manifest; without a
+			// manifest they define the task.
+			set := func(name string) bool {
+				return filename == "" || cmd.Flags().Changed(name)
+			}
+			if len(args) > 0 {
+				task.Spec.Prompt = strings.Join(args, " ")
+				task.Spec.PromptTemplate = ""
+			}
+			if task.Metadata.Name == "" {
+				// Generate a unique task name based on the current time.
+				task.Metadata.Name = fmt.Sprintf("run-%d", time.Now().UnixMilli())
+			}
+			if set("project") || task.Metadata.Project == "" {
+				task.Metadata.Project = project
+			}
+			if set("model") {
+				task.Spec.PreferredModel = model
+			}
+			if set("timeout") {
+				task.Spec.TimeoutSeconds = timeout
+			}
+			if cmd.Flags().Changed("capabilities") {
+				task.Spec.RequiredCapabilities = capabilities
+			}
+			if cmd.Flags().Changed("depends-on") {
+				task.Spec.DependsOn = dependsOn
+			}
+			if cmd.Flags().Changed("pool") {
+				task.Spec.Pool = pool
+			}
+			if cmd.Flags().Changed("priority") {
+				task.Spec.Priority = priority
+			}
+			for _, l := range labels {
+				k, v, ok := strings.Cut(l, "=")
+				if !ok || k == "" {
+					return fmt.Errorf("--label must be key=value, got %q", l)
+				}
+				if task.Metadata.Labels == nil {
+					task.Metadata.Labels = make(map[string]string)
+				}
+				task.Metadata.Labels[k] = v
+			}
+
+			taskName := task.Metadata.Name
+			project = task.Metadata.Project
+			timeout = task.Spec.TimeoutSeconds
+
 			// Create the task via the API.
 			created, err := apiClient.CreateDevTask(task)
 			if err != nil {
 				return fmt.Errorf("creating task: %w", err)
 			}
 
-			fmt.Printf("Task %s created. Waiting for completion...\n", created.Metadata.Name)
-
-			// Poll for task completion.
-			pollInterval := 2 * time.Second
 			timeoutDuration := time.Duration(timeout) * time.Second
 			if timeout == 0 {
 				timeoutDuration = 5 * time.Minute
 			}
-			deadline := time.Now().Add(timeoutDuration)
 
-			for {
-				if time.Now().After(deadline) {
-					return fmt.Errorf("task %s did not complete within timeout (%v)", taskName, timeoutDuration)
-				}
-
-				current, err := apiClient.GetDevTask(taskName, project)
-				if err != nil {
-					return fmt.Errorf("polling task status: %w", err)
-				}
+			if follow {
+				fmt.Printf("Task %s created. Streaming output...\n\n", created.Metadata.Name)
+				return runFollow(cmd.Context(), taskName, project, timeoutDuration)
+			}
 
-				switch current.Status.Phase {
-				case v1alpha1.TaskSucceeded:
-					fmt.Println()
-					color.New(color.FgGreen, color.Bold).Println("Task Succeeded")
-					fmt.Println(strings.Repeat("-", 60))
-					fmt.Println(current.Status.Output)
-					return nil
-
-				case v1alpha1.TaskFailed:
-					fmt.Println()
-					color.New(color.FgRed, color.Bold).Println("Task Failed")
-					fmt.Println(strings.Repeat("-", 60))
-					if current.Status.Error != "" {
-						fmt.Println(current.Status.Error)
-					}
-					return fmt.Errorf("task %s failed", taskName)
-
-				case v1alpha1.TaskRunning:
-					fmt.Print(".")
-
-				case v1alpha1.TaskScheduled:
-					fmt.Print(".")
-
-				case v1alpha1.TaskPending:
-					// Still waiting for scheduling.
-				}
+			fmt.Printf("Task %s created. Waiting for completion...\n", created.Metadata.Name)
 
-				time.Sleep(pollInterval)
+			current, err := waitForTask(cmd.Context(), taskName, project, timeoutDuration)
+			if err == errWaitTimeout {
+				return withExitCode(ExitTimeout, fmt.Errorf("task %s did not complete within timeout (%v)", taskName, timeoutDuration))
+			}
+			if err != nil {
+				return err
 			}
+			fmt.Println()
+			return printRunResult(current, true)
 		},
 	}
 
 	cmd.Flags().StringVar(&model, "model", "claude-sonnet", "Model to use")
 	cmd.Flags().StringVarP(&project, "project", "p", "default", "Project name")
 	cmd.Flags().IntVar(&timeout, "timeout", 300, "Timeout in seconds (0 for default 5 minutes)")
+	cmd.Flags().BoolVar(&follow, "follow", false, "Stream the agent's output as the task runs")
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "Manifest holding the DevTask to run")
+	cmd.Flags().StringSliceVar(&capabilities, "capabilities", nil, "Capabilities the agent must have (comma-separated)")
+	cmd.Flags().StringArrayVar(&labels, "label", nil, "Label to set on the task as key=value (repeatable)")
+	cmd.Flags().StringSliceVar(&dependsOn, "depends-on", nil, "Tasks that must succeed first (comma-separated)")
+	cmd.Flags().StringVar(&pool, "pool", "", "Only run on pods of this agent pool")
+	cmd.Flags().IntVar(&priority, "priority", 0, "Scheduling priority; higher-priority tasks get free pods first")
 
 	return cmd
 }
+
+// runFollow streams the task's steps to the terminal until it finishes or
+// timeout elapses, then prints the outcome.
+func runFollow(ctx context.Context, taskName, project string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var phase v1alpha1.DevTaskPhase
+	err := apiClient.FollowTask(ctx, taskName, project, func(ev v1alpha1.TaskStreamEvent) {
+		if ev.Step != nil {
+			printLiveStep(*ev.Step)
+		}
+		if ev.Phase != "" {
+			phase = ev.Phase
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("streaming task output: %w", err)
+	}
+	if phase == "" {
+		if ctx.Err() == context.DeadlineExceeded {
+			return withExitCode(ExitTimeout, fmt.Errorf("task %s did not complete within timeout (%v)", taskName, timeout))
+		}
+		return fmt.Errorf("output stream of task %s ended before it finished", taskName)
+	}
+
+	task, err := apiClient.GetDevTask(taskName, project)
+	if err != nil {
+		return fmt.Errorf("getting task result: %w", err)
+	}
+	fmt.Println()
+	// The output was streamed already as the agent's messages.
+	return printRunResult(task, false)
+}
+
+// errWaitTimeout is returned by waitForTask when the task did not finish in
+// time.
+var errWaitTimeout = errors.New("timed out waiting for task")
+
+// waitForTask checks the task every waitForTaskPoll when the server cannot
+// watch it, and every waitForTaskResync when it can, in case the watch missed
+// a change. A watch that breaks is reopened after waitForTaskWatchRetry.
+const (
+	waitForTaskPoll       = 2 * time.Second
+	waitForTaskResync     = 30 * time.Second
+	waitForTaskWatchRetry = 5 * time.Second
+)
+
+// waitForTask waits up to timeout for the task to succeed or fail and
+// returns it, printing a dot whenever it sees the task scheduled or running.
+// The task is checked whenever a watch of the project's tasks reports a
+// change to it, and polled when the server has no watch endpoint.
+func waitForTask(ctx context.Context, taskName, project string, timeout time.Duration) (*v1alpha1.DevTask, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	watching := make(chan bool, 1)
+	go func() {
+		key := store.ResourceKey(v1alpha1.KindDevTask, project, taskName)
+		for ctx.Err() == nil {
+			err := apiClient.Watch(ctx, v1alpha1.KindDevTask, project, func(ev v1alpha1.WatchEvent) {
+				if ev.Key != key {
+					return
+				}
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			})
+			if client.IsNotFound(err) {
+				watching <- false
+				return
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(waitForTaskWatchRetry):
+			}
+		}
+	}()
+
+	interval := waitForTaskResync
+	for {
+		current, err := apiClient.GetDevTask(taskName, project)
+		if err != nil {
+			return nil, fmt.Errorf("polling task status: %w", err)
+		}
+		switch current.Status.Phase {
+		case v1alpha1.TaskSucceeded, v1alpha1.TaskFailed:
+			return current, nil
+		case v1alpha1.TaskRunning, v1alpha1.TaskScheduled:
+			fmt.Print(".")
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, errWaitTimeout
+			}
+			return nil, ctx.Err()
+		case <-changed:
+		case <-watching:
+			interval = waitForTaskPoll
+		case <-time.After(interval):
+		}
+	}
+}
+
+// printLiveStep prints a step as it happens: messages in full, tool calls
+// and results as one dimmed line each.
+func printLiveStep(step v1alpha1.TaskStep) {
+	switch step.Type {
+	case v1alpha1.StepMessage:
+		fmt.Println(step.Text)
+	case v1alpha1.StepToolCall:
+		color.Cyan("-> %s %s", step.Tool, truncate(step.Input, 100))
+	case v1alpha1.StepToolResult:
+		line := fmt.Sprintf("<- %s %s", step.Tool, truncate(strings.Join(strings.Fields(step.Text), " "), 100))
+		if step.IsError {
+			color.Red(line)
+		} else {
+			color.HiBlack(line)
+		}
+	}
+}
+
+// printRunResult prints a finished task's outcome, including its output when
+// withOutput is set, and returns an error if the task failed.
+func printRunResult(task *v1alpha1.DevTask, withOutput bool) error {
+	if task.Status.Phase == v1alpha1.TaskFailed {
+		color.New(color.FgRed, color.Bold).Println("Task Failed")
+		fmt.Println(strings.Repeat("-", 60))
+		if task.Status.Error != "" {
+			fmt.Println(task.Status.Error)
+		}
+		return withExitCode(ExitTaskFailed, fmt.Errorf("task %s failed", task.Metadata.Name))
+	}
+
+	color.New(color.FgGreen, color.Bold).Println("Task Succeeded")
+	if withOutput {
+		fmt.Println(strings.Repeat("-", 60))
+		fmt.Println(task.Status.Output)
+	} else if task.Status.TokensIn > 0 || task.Status.TokensOut > 0 {
+		fmt.Printf("%d in / %d out tokens, $%.4f\n", task.Status.TokensIn, task.Status.TokensOut, task.Status.CostUSD)
+	}
+	return nil
+}
+
+// loadRunTask reads the single DevTask of the manifest at path.
+func loadRunTask(path string) (*v1alpha1.DevTask, error) {
+	resources, err := loadManifests([]string{path}, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) != 1 {
+		return nil, fmt.Errorf("%s: expected exactly one DevTask, found %d resources", path, len(resources))
+	}
+	task, ok := resources[0].(*v1alpha1.DevTask)
+	if !ok {
+		kind, name := manifest.Identity(resources[0])
+		return nil, fmt.Errorf("%s: expected a DevTask, found %s/%s", path, kind, name)
+	}
+	return task, nil
+}
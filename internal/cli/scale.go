@@ -2,19 +2,26 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 )
 
 func newScaleCmd() *cobra.Command {
-	var replicas int
+	var replicas, currentReplicas int
 
 	cmd := &cobra.Command{
 		Use:   "scale <resource-type> <name>",
 		Short: "Scale an agent pool",
-		Long:  "Adjust the replica count of an agent pool.",
+		Long: `Adjust the replica count of an agent pool.
+
+With --current-replicas the pool is only scaled if it still has that many
+replicas, so that concurrent scalings don't silently overwrite each other.`,
 		Example: `  orca scale agentpool my-pool --replicas=5
-  orca scale pool my-pool --replicas=3 -p myproject`,
+  orca scale pool my-pool --replicas=3 -p myproject
+  orca scale pool my-pool --current-replicas=3 --replicas=5`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			project, _ := cmd.Flags().GetString("project")
@@ -22,25 +29,100 @@ func newScaleCmd() *cobra.Command {
 			name := args[1]
 
 			if resourceType != "agentpools" {
-				return fmt.Errorf("scaling is only supported for agentpools, got %q", args[0])
+				return withExitCode(ExitUsage, fmt.Errorf("scaling is only supported for agentpools, got %q", args[0]))
 			}
 
 			if replicas < 0 {
 				return fmt.Errorf("replicas must be >= 0, got %d", replicas)
 			}
 
-			_, err := apiClient.ScaleAgentPool(name, project, replicas)
+			var err error
+			if cmd.Flags().Changed("current-replicas") {
+				_, err = apiClient.ScaleAgentPoolFrom(name, project, currentReplicas, replicas)
+			} else {
+				_, err = apiClient.ScaleAgentPool(name, project, replicas)
+			}
 			if err != nil {
 				return err
 			}
 
-			fmt.Printf("agentpool/%s scaled to %d replicas\n", name, replicas)
+			printChanged("agentpool/"+name, fmt.Sprintf("scaled to %d replicas", replicas))
 			return nil
 		},
 	}
 
 	cmd.Flags().IntVar(&replicas, "replicas", 1, "Number of replicas")
+	cmd.Flags().IntVar(&currentReplicas, "current-replicas", 0, "Only scale if the pool currently has this many replicas")
+	cmd.Flags().StringP("project", "p", "default", "Project name")
+
+	return cmd
+}
+
+func newAutoscaleCmd() *cobra.Command {
+	var (
+		minReplicas    int
+		maxReplicas    int
+		name           string
+		scaleDownDelay time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "autoscale <resource-type> <name>",
+		Short: "Autoscale an agent pool",
+		Long: `Create an AgentPoolAutoscaler that keeps an agent pool between --min and
+--max replicas, sized to the tasks running on or waiting for its pods.
+
+The pool is scaled up as soon as tasks wait, and scaled down once
+--scale-down-delay has passed since it was last scaled. The autoscaler is
+named after the pool unless --name is given; remove it with
+"orca delete autoscaler <name>".`,
+		Example: `  orca autoscale pool my-pool --min 1 --max 10
+  orca autoscale pool reviewers --max 5 --scale-down-delay 10m -p myproject`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetString("project")
+			if normalizeResourceType(args[0]) != "agentpools" {
+				return withExitCode(ExitUsage, fmt.Errorf("autoscaling is only supported for agentpools, got %q", args[0]))
+			}
+			pool := args[1]
+			if name == "" {
+				name = pool
+			}
+
+			as := &v1alpha1.AgentPoolAutoscaler{
+				TypeMeta: v1alpha1.TypeMeta{
+					APIVersion: v1alpha1.APIVersion,
+					Kind:       v1alpha1.KindAgentPoolAutoscaler,
+				},
+				Metadata: v1alpha1.ObjectMeta{Name: name, Project: project},
+				Spec: v1alpha1.AgentPoolAutoscalerSpec{
+					Pool:                  pool,
+					MinReplicas:           minReplicas,
+					MaxReplicas:           maxReplicas,
+					ScaleDownDelaySeconds: int(scaleDownDelay / time.Second),
+				},
+			}
+			if err := as.Spec.Validate(); err != nil {
+				return err
+			}
+			if _, err := apiClient.GetAgentPool(pool, project); err != nil {
+				return fmt.Errorf("agentpool %q: %w", pool, err)
+			}
+			if _, err := apiClient.CreateAutoscaler(as); err != nil {
+				return err
+			}
+
+			printChanged("autoscaler/"+name, fmt.Sprintf("created (agentpool/%s: %d-%d replicas)", pool, minReplicas, maxReplicas))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&minReplicas, "min", 1, "Minimum number of replicas")
+	cmd.Flags().IntVar(&maxReplicas, "max", 0, "Maximum number of replicas (required)")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the autoscaler (default: the pool's name)")
+	cmd.Flags().DurationVar(&scaleDownDelay, "scale-down-delay", 0, "Time to wait after scaling before scaling down (default 5m)")
 	cmd.Flags().StringP("project", "p", "default", "Project name")
+	cmd.MarkFlagRequired("max")
 
 	return cmd
 }
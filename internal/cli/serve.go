@@ -15,101 +15,339 @@ import (
 
 	"github.com/klubi/orca/internal/agent"
 	"github.com/klubi/orca/internal/apiserver"
+	"github.com/klubi/orca/internal/audit"
 	"github.com/klubi/orca/internal/config"
 	"github.com/klubi/orca/internal/controller"
+	"github.com/klubi/orca/internal/events"
+	"github.com/klubi/orca/internal/logging"
 	"github.com/klubi/orca/internal/scheduler"
 	"github.com/klubi/orca/internal/store"
+	"github.com/klubi/orca/internal/tracing"
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 )
 
 func newServeCmd() *cobra.Command {
 	var (
-		port    int
-		host    string
-		dataDir string
+		port       int
+		host       string
+		dataDir    string
+		configPath string
+		detach     bool
+		pidFile    string
+		logFile    string
+		tlsCert    string
+		tlsKey     string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Start the Orca control plane",
-		Long:  "Start the Orca API server and all controllers.",
+		Long: `Start the Orca API server and all controllers.
+
+Settings are taken from the defaults, then the YAML file given with --config
+(~/.orca/config.yaml if it exists and --config is not given), then ORCA_*
+environment variables (e.g. ORCA_SERVER_PORT, ORCA_STORE_TYPE,
+ORCA_AGENT_CLAUDE_CLI, ORCA_LOG_LEVEL), then the command-line flags. The
+result is checked before anything starts, so e.g. port 0 or an unknown store
+type is reported up front. A config file looks like:
+
+  server:
+    host: 0.0.0.0
+    port: 7117
+    auth:               # require a bearer token on API requests
+      tokens:
+        - name: ci
+          token: s3cr3t
+      tokenFile: /etc/orca/tokens   # "<token> [name]" per line, re-read on change
+    tls:                # serve HTTPS
+      certFile: /etc/orca/tls.crt
+      keyFile: /etc/orca/tls.key
+      clientCAFile: /etc/orca/ca.crt  # optional: require client certificates
+    maxBodyBytes: 1048576   # larger request bodies get 413; 0 lifts the cap
+    rateLimit:          # per client: user of its token, or else its IP
+      requestsPerSecond: 50   # 0 turns limiting off
+      burst: 100
+      clients:          # overrides by client
+        ci:
+          requestsPerSecond: 200
+          burst: 400
+  store:
+    type: bolt          # or memory
+    dataDir: ~/.orca/data
+  agent:
+    claudeCLI: /usr/local/bin/claude
+    defaultMaxTokens: 8192
+    healthCheckInterval: 30
+  scheduler:
+    weights:            # how much each priority counts, 0 turns it off
+      leastLoaded: 1
+      capabilityMatch: 1
+      modelPreference: 1
+  log:
+    level: info
+    format: console     # or json, for log aggregation
+    components:         # per-component levels: agent, apiserver,
+      scheduler: debug  # controller, events and scheduler
+    file: /var/log/orca/orca.log   # instead of stderr, rotated
+    maxSizeMB: 100
+    maxBackups: 5
+  tracing:              # OpenTelemetry traces over OTLP/HTTP
+    enabled: true
+    endpoint: localhost:4318
+    insecure: true
+    sampleRatio: 1
+  audit:                # record mutating API requests; see "orca get audit"
+    backend: store      # or file, appending JSON lines to audit.file
+    maxEntries: 10000   # store backend: entries kept, oldest deleted first
+
+While the server runs, changes to the config file's log.level,
+log.components, agent.defaultModel, agent.healthCheckInterval,
+scheduler.weights and server.rateLimit are applied without a restart and recorded as a
+ConfigReloaded event in the default project; other changes take effect at
+the next start.
+
+Without server.auth tokens the API accepts anonymous requests; with them,
+every request but /healthz and /readyz needs "Authorization: Bearer <token>"
+(see "orca config set-context --token" and the --token flag). Clients over
+their rate limit get 429 Too Many Requests with a Retry-After header; orca
+commands wait and retry.
+
+Each API request is logged with an ID, returned in its X-Request-ID header
+(or taken from it, if the client sent one). The controllers' logs of the
+changes a request made carry the same requestID field.
+
+The server writes its pid to <data-dir>/orca.pid. With --detach it runs in
+the background, logging to <data-dir>/orca.log; check on it with
+"orca server status" and stop it with "orca server stop".`,
+		Example: `  orca serve
+  orca serve --config ~/.orca/config.yaml
+  ORCA_STORE_TYPE=memory orca serve --port 7200
+  orca serve --detach
+  orca serve --host 0.0.0.0 --tls-cert tls.crt --tls-key tls.key`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// 1. Build configuration with CLI overrides.
-			cfg := config.DefaultConfig()
-			if cmd.Flags().Changed("port") {
-				cfg.Server.Port = port
+			// 1. Build configuration: defaults < config file < environment
+			// < flags.
+			applyFlags := func(cfg *config.Config) {
+				if cmd.Flags().Changed("port") {
+					cfg.Server.Port = port
+				}
+				if cmd.Flags().Changed("host") {
+					cfg.Server.Host = host
+				}
+				if cmd.Flags().Changed("data-dir") {
+					cfg.Store.DataDir = dataDir
+				}
+				if cmd.Flags().Changed("tls-cert") {
+					cfg.Server.TLS.CertFile = tlsCert
+				}
+				if cmd.Flags().Changed("tls-key") {
+					cfg.Server.TLS.KeyFile = tlsKey
+				}
+			}
+			cfg, err := config.Resolve(configPath)
+			if err != nil {
+				return err
 			}
-			if cmd.Flags().Changed("host") {
-				cfg.Server.Host = host
+			applyFlags(cfg)
+			if err := cfg.Validate(); err != nil {
+				return withExitCode(ExitUsage, err)
 			}
-			if cmd.Flags().Changed("data-dir") {
-				cfg.Store.DataDir = dataDir
+			if pidFile == "" {
+				pidFile = defaultPidFile(cfg.Store.DataDir)
+			}
+			if detach && os.Getenv(detachedEnv) == "" {
+				if logFile == "" {
+					logFile = defaultLogFile(cfg.Store.DataDir)
+				}
+				return startDetached(cfg, pidFile, logFile)
 			}
 
-			// 2. Create logger.
-			logger, err := zap.NewDevelopment()
+			// 2. Create logger. Its levels can be changed by reloading the
+			// config file.
+			logger, logLevels, closeLog, err := logging.New(cfg.Log)
 			if err != nil {
 				return fmt.Errorf("creating logger: %w", err)
 			}
+			defer closeLog()
 			defer logger.Sync()
 
-			// 3. Ensure data directory exists and open BoltDB store.
+			shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := shutdownTracing(ctx); err != nil {
+					logger.Warn("flushing traces", zap.Error(err))
+				}
+			}()
+
+			// 3. Ensure data directory exists (it also holds task
+			// artifacts) and open the store.
 			if err := os.MkdirAll(cfg.Store.DataDir, 0755); err != nil {
 				return fmt.Errorf("creating data directory %s: %w", cfg.Store.DataDir, err)
 			}
+			if err := acquirePidFile(pidFile); err != nil {
+				return err
+			}
+			defer os.Remove(pidFile)
 
-			boltStore, err := store.NewBoltStore(cfg.DBPath())
-			if err != nil {
-				return fmt.Errorf("opening store at %s: %w", cfg.DBPath(), err)
+			var st store.Store
+			switch cfg.Store.Type {
+			case "bolt", "":
+				if st, err = store.NewBoltStore(cfg.DBPath()); err != nil {
+					return fmt.Errorf("opening store at %s: %w", cfg.DBPath(), err)
+				}
+			case "memory":
+				st = store.NewMemoryStore()
+			default:
+				return fmt.Errorf("unknown store type %q (want bolt or memory)", cfg.Store.Type)
 			}
-			defer boltStore.Close()
+			defer st.Close()
 
 			// 4. Create executor and runtime.
-			executor := agent.NewExecutor(cfg.Agent.ClaudeCLI, logger)
-			runtime := agent.NewRuntime(boltStore, executor, cfg, logger)
+			executor := agent.NewExecutor(cfg.Agent.ClaudeCLI, logger.Named(logging.ComponentAgent))
+			defer executor.Close()
+			runtime := agent.NewRuntime(st, executor, cfg, logger.Named(logging.ComponentAgent))
+
+			// Verify the claude CLI up front so a missing or broken install
+			// shows up here and in /readyz rather than as failed tasks.
+			cliCheck := executor.SelfCheck(context.Background())
+			if cliCheck.Err != nil {
+				logger.Warn("claude CLI self-check failed; tasks will fail until it is fixed",
+					zap.Error(cliCheck.Err),
+				)
+			}
 
 			// 5. Create scheduler.
-			sched := scheduler.NewScheduler(boltStore, logger)
+			sched := scheduler.NewScheduler(st, logger.Named(logging.ComponentScheduler))
 
 			// 6. Create controller manager and register controllers.
-			mgr := controller.NewManager(boltStore, logger)
+			ctrlLogger := logger.Named(logging.ComponentController)
+			eventsLogger := logger.Named(logging.ComponentEvents)
+			mgr := controller.NewManager(st, ctrlLogger)
 
-			agentPoolCtrl := controller.NewAgentPoolController(boltStore, runtime, logger)
+			agentPoolCtrl := controller.NewAgentPoolController(st, runtime,
+				events.NewRecorder(st, "agentpool-controller", eventsLogger), ctrlLogger.Named("agentpool"))
 			mgr.Register("AgentPoolController", agentPoolCtrl, []string{
 				v1alpha1.KindAgentPool,
 				v1alpha1.KindAgentPod,
 			})
 
-			devTaskCtrl := controller.NewDevTaskController(boltStore, sched, runtime, logger)
+			devTaskCtrl := controller.NewDevTaskController(st, sched, runtime,
+				events.NewRecorder(st, "devtask-controller", eventsLogger), ctrlLogger.Named("devtask"))
 			mgr.Register("DevTaskController", devTaskCtrl, []string{
 				v1alpha1.KindDevTask,
 				v1alpha1.KindAgentPod,
 			})
 
+			autoscalerCtrl := controller.NewAutoscalerController(st,
+				events.NewRecorder(st, "autoscaler-controller", eventsLogger), ctrlLogger.Named("autoscaler"))
+			mgr.Register("AutoscalerController", autoscalerCtrl, []string{
+				v1alpha1.KindAgentPoolAutoscaler,
+				v1alpha1.KindAgentPod,
+				v1alpha1.KindDevTask,
+			})
+
 			healthCheckInterval := time.Duration(cfg.Agent.HealthCheckInterval) * time.Second
-			healthCheckCtrl := controller.NewHealthCheckController(boltStore, runtime, healthCheckInterval, logger)
+			healthCheckCtrl := controller.NewHealthCheckController(st, runtime,
+				events.NewRecorder(st, "healthcheck-controller", eventsLogger), healthCheckInterval, ctrlLogger.Named("healthcheck"))
 			mgr.Register("HealthCheckController", healthCheckCtrl, []string{
 				v1alpha1.KindAgentPod,
 			})
 
-			// 7. Start controller manager.
+			gc := controller.NewGarbageCollector(st,
+				events.NewRecorder(st, "garbage-collector", eventsLogger), ctrlLogger.Named("gc"))
+			mgr.Register("GarbageCollector", gc, []string{
+				v1alpha1.KindAgentPool,
+				v1alpha1.KindAgentPod,
+			})
+
+			// 7. Recover runtime state left over from a previous run, then
+			// start the controller manager.
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
+			if err := runtime.Recover(ctx); err != nil {
+				return fmt.Errorf("recovering runtime state: %w", err)
+			}
+
 			if err := mgr.Start(ctx); err != nil {
 				return fmt.Errorf("starting controller manager: %w", err)
 			}
 
 			// 8. Create and start API server.
 			addr := cfg.ServerAddress()
-			apiSrv := apiserver.NewServer(addr, boltStore, runtime, logger)
+			apiSrv := apiserver.NewServer(addr, st, runtime, logger.Named(logging.ComponentAPIServer))
+			if cfg.Server.Auth.Enabled() {
+				auth, err := apiserver.NewAuthenticator(cfg.Server.Auth, logger.Named(logging.ComponentAPIServer))
+				if err != nil {
+					return fmt.Errorf("configuring API authentication: %w", err)
+				}
+				apiSrv.SetAuthenticator(auth)
+			}
+			auditLog, err := audit.New(cfg.Audit, st)
+			if err != nil {
+				return fmt.Errorf("opening audit log: %w", err)
+			}
+			if auditLog != nil {
+				defer auditLog.Close()
+				apiSrv.SetAuditLog(auditLog)
+			}
+			apiSrv.SetRateLimit(cfg.Server.RateLimit)
+			apiSrv.SetMaxBodyBytes(int64(cfg.Server.MaxBodyBytes))
+			if cfg.Server.TLS.Enabled() {
+				if err := apiSrv.SetTLS(cfg.Server.TLS); err != nil {
+					return fmt.Errorf("configuring API server TLS: %w", err)
+				}
+			} else if cfg.Server.Auth.Enabled() {
+				logger.Warn("bearer tokens are sent in plaintext; set --tls-cert and --tls-key to serve HTTPS")
+			}
+
+			// Apply the runtime-tunable settings of a changed config file.
+			watchPath := configPath
+			if watchPath == "" {
+				watchPath = config.DefaultConfigPath()
+			}
+			reloader := &configReloader{
+				path:     watchPath,
+				current:  cfg,
+				levels:   logLevels,
+				runtime:  runtime,
+				sched:    sched,
+				health:   healthCheckCtrl,
+				apiSrv:   apiSrv,
+				recorder: events.NewRecorder(st, "config-reloader", eventsLogger),
+				logger:   logger,
+			}
+			go config.Watch(ctx, watchPath, configPollInterval, func(next *config.Config, err error) {
+				if err == nil {
+					applyFlags(next)
+				}
+				reloader.reload(next, err)
+			})
 
 			// Print startup banner.
 			banner := color.New(color.FgCyan, color.Bold)
 			banner.Println("Orca Control Plane")
-			fmt.Printf("   API Server: http://%s:%d\n", cfg.Server.Host, cfg.Server.Port)
+			fmt.Printf("   API Server: %s\n", cfg.ServerURL())
+			if cfg.Server.Auth.Enabled() {
+				fmt.Printf("   Auth:       bearer tokens\n")
+			} else {
+				color.Yellow("   Auth:       none (anyone who can reach the port has full access)")
+			}
 			fmt.Printf("   Data Dir:   %s\n", cfg.Store.DataDir)
-			fmt.Printf("   DB Path:    %s\n", cfg.DBPath())
+			if cfg.Store.Type == "memory" {
+				fmt.Printf("   Store:      memory (not persisted)\n")
+			} else {
+				fmt.Printf("   DB Path:    %s\n", cfg.DBPath())
+			}
+			if cliCheck.Err != nil {
+				color.Yellow("   Claude CLI: NOT READY (%v)", cliCheck.Err)
+			} else {
+				fmt.Printf("   Claude CLI: %s (%s)\n", cliCheck.Path, cliCheck.Version)
+			}
 			fmt.Println()
 
 			// Start API server in a goroutine.
@@ -160,6 +398,12 @@ func newServeCmd() *cobra.Command {
 	cmd.Flags().IntVar(&port, "port", 7117, "API server port")
 	cmd.Flags().StringVar(&host, "host", "127.0.0.1", "API server host")
 	cmd.Flags().StringVar(&dataDir, "data-dir", "", "Data directory (default: ~/.orca/data)")
+	cmd.Flags().StringVar(&tlsCert, "tls-cert", "", "PEM certificate file to serve HTTPS with (overrides config)")
+	cmd.Flags().StringVar(&tlsKey, "tls-key", "", "PEM private key file of --tls-cert (overrides config)")
+	cmd.Flags().StringVar(&configPath, "config", "", "YAML file with the control plane's configuration (default: ~/.orca/config.yaml if it exists)")
+	cmd.Flags().BoolVarP(&detach, "detach", "d", false, "Run in the background")
+	cmd.Flags().StringVar(&pidFile, "pid-file", "", "Pidfile (default: <data-dir>/orca.pid)")
+	cmd.Flags().StringVar(&logFile, "log-file", "", "Log file of a detached server (default: <data-dir>/orca.log)")
 
 	return cmd
 }
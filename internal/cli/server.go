@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klubi/orca/internal/config"
+)
+
+// detachedEnv is set in the environment of the server process started by
+// `orca serve --detach`, telling it to run in the foreground.
+const detachedEnv = "ORCA_SERVE_DETACHED"
+
+// errNotRunning reports a missing pidfile or one naming a dead process.
+var errNotRunning = errors.New("orca control plane is not running")
+
+// defaultPidFile and defaultLogFile are kept in the data directory.
+func defaultPidFile(dataDir string) string { return filepath.Join(dataDir, "orca.pid") }
+func defaultLogFile(dataDir string) string { return filepath.Join(dataDir, "orca.log") }
+
+// readPidFile returns the pid recorded in path if that process is alive.
+func readPidFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, errNotRunning
+	}
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pidfile %s: %w", path, err)
+	}
+	if !processAlive(pid) {
+		return pid, errNotRunning
+	}
+	return pid, nil
+}
+
+// acquirePidFile records the current process in path, failing if the
+// pidfile names another live process. A stale pidfile is replaced.
+func acquirePidFile(path string) error {
+	if pid, err := readPidFile(path); err == nil && pid != os.Getpid() {
+		return fmt.Errorf("orca is already running (pid %d, pidfile %s)", pid, path)
+	}
+	return os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644)
+}
+
+// startDetached starts `orca serve` again as a background process writing to
+// logFile, and waits until its API server answers or it exits.
+func startDetached(cfg *config.Config, pidFile, logFile string) error {
+	if pid, err := readPidFile(pidFile); err == nil {
+		return fmt.Errorf("orca is already running (pid %d, pidfile %s)", pid, pidFile)
+	}
+	if err := os.MkdirAll(cfg.Store.DataDir, 0755); err != nil {
+		return fmt.Errorf("creating data directory %s: %w", cfg.Store.DataDir, err)
+	}
+	logOut, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	defer logOut.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating the orca binary: %w", err)
+	}
+	child := exec.Command(exe, os.Args[1:]...)
+	child.Env = append(os.Environ(), detachedEnv+"=1")
+	child.Stdout = logOut
+	child.Stderr = logOut
+	child.SysProcAttr = detachedProcAttr()
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("starting server: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+
+	host := cfg.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	scheme := "http"
+	hc := &http.Client{Timeout: time.Second}
+	if cfg.Server.TLS.Enabled() {
+		// Only the liveness of the child is checked here, not who serves it.
+		scheme = "https"
+		hc.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	url := fmt.Sprintf("%s://%s/healthz", scheme, net.JoinHostPort(host, strconv.Itoa(cfg.Server.Port)))
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-exited:
+			return fmt.Errorf("server exited during startup (%v); see %s", err, logFile)
+		case <-time.After(200 * time.Millisecond):
+		}
+		if resp, err := hc.Get(url); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				fmt.Printf("Orca control plane started (pid %d)\n", child.Process.Pid)
+				fmt.Printf("   API Server: %s\n", cfg.ServerURL())
+				fmt.Printf("   Log File:   %s\n", logFile)
+				fmt.Printf("   Pid File:   %s\n", pidFile)
+				return nil
+			}
+		}
+	}
+	return withExitCode(ExitTimeout, fmt.Errorf("server (pid %d) did not become healthy within 30s; see %s", child.Process.Pid, logFile))
+}
+
+func newServerCmd() *cobra.Command {
+	var dataDir, pidFile string
+
+	cmd := &cobra.Command{
+		Use:   "server",
+		Short: "Manage a control plane started with orca serve",
+		Long: `Check on or stop the control plane running on this machine, found through
+the pidfile "orca serve" writes to its data directory.`,
+		Example: `  orca serve --detach
+  orca server status
+  orca server stop`,
+	}
+
+	// resolvePidFile returns the pidfile named by the flags.
+	resolvePidFile := func() string {
+		if pidFile != "" {
+			return pidFile
+		}
+		if dataDir != "" {
+			return defaultPidFile(dataDir)
+		}
+		// Find the data directory the way orca serve does, falling back
+		// to the default one if the config cannot be read.
+		cfg, err := config.Resolve("")
+		if err != nil {
+			cfg = config.DefaultConfig()
+		}
+		return defaultPidFile(cfg.Store.DataDir)
+	}
+
+	stop := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the control plane",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			path := resolvePidFile()
+			pid, err := readPidFile(path)
+			if err == errNotRunning {
+				if pid != 0 {
+					os.Remove(path)
+				}
+				return err
+			}
+			if err != nil {
+				return err
+			}
+
+			proc, err := os.FindProcess(pid)
+			if err != nil {
+				return err
+			}
+			if err := proc.Signal(syscall.SIGTERM); err != nil {
+				return fmt.Errorf("stopping pid %d: %w", pid, err)
+			}
+			for deadline := time.Now().Add(timeout); time.Now().Before(deadline); {
+				if !processAlive(pid) {
+					fmt.Printf("Orca control plane stopped (pid %d)\n", pid)
+					return nil
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+			return withExitCode(ExitTimeout, fmt.Errorf("pid %d is still running after %s", pid, timeout))
+		},
+	}
+	stop.Flags().Duration("timeout", 15*time.Second, "How long to wait for a graceful shutdown")
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the control plane is running",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pid, err := readPidFile(resolvePidFile())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Orca control plane is running (pid %d)\n", pid)
+			if err := apiClient.Healthz(); err != nil {
+				fmt.Printf("   API Server: %s is not responding: %v\n", serverAddr, err)
+			} else {
+				fmt.Printf("   API Server: %s is healthy\n", serverAddr)
+			}
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&dataDir, "data-dir", "", "Data directory of the server (default: ~/.orca/data)")
+	cmd.PersistentFlags().StringVar(&pidFile, "pid-file", "", "Pidfile of the server (default: <data-dir>/orca.pid)")
+	cmd.AddCommand(stop, status)
+	return cmd
+}
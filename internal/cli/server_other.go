@@ -0,0 +1,23 @@
+//go:build !unix
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// detachedProcAttr returns no special attributes where sessions don't exist.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// processAlive reports whether a process with the given pid exists.
+func processAlive(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	proc.Release()
+	return true
+}
@@ -49,6 +49,11 @@ func statusPrint(project string) error {
 	fmt.Println("========================")
 	fmt.Println()
 
+	if err := apiClient.Readyz(); err != nil {
+		color.Yellow("Warning: %v", err)
+		fmt.Println()
+	}
+
 	// Projects
 	projects, err := apiClient.ListProjects()
 	if err != nil {
@@ -0,0 +1,270 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+)
+
+// podUsage is one row of `orca top pods`.
+type podUsage struct {
+	Name         string  `json:"name" yaml:"name"`
+	Pool         string  `json:"pool,omitempty" yaml:"pool,omitempty"`
+	Phase        string  `json:"phase" yaml:"phase"`
+	ActiveTasks  int     `json:"activeTasks" yaml:"activeTasks"`
+	TokensToday  int     `json:"tokensToday" yaml:"tokensToday"`
+	CostToday    float64 `json:"costTodayUSD" yaml:"costTodayUSD"`
+	TasksPerHour int     `json:"tasksPerHour" yaml:"tasksPerHour"`
+	// Heartbeat is the time of the last heartbeat, shown as its age.
+	Heartbeat time.Time `json:"lastHeartbeat,omitempty" yaml:"lastHeartbeat,omitempty"`
+}
+
+// poolUsage is one row of `orca top pools`.
+type poolUsage struct {
+	Name         string  `json:"name" yaml:"name"`
+	Pods         int     `json:"pods" yaml:"pods"`
+	ActiveTasks  int     `json:"activeTasks" yaml:"activeTasks"`
+	TokensToday  int     `json:"tokensToday" yaml:"tokensToday"`
+	CostToday    float64 `json:"costTodayUSD" yaml:"costTodayUSD"`
+	TasksPerHour int     `json:"tasksPerHour" yaml:"tasksPerHour"`
+}
+
+func newTopCmd() *cobra.Command {
+	var (
+		watch    bool
+		interval time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "top <pods|pools>",
+		Short: "Show usage per agent pod or pool",
+		Long: `Show live usage per agent pod or pool: active tasks, tokens and cost
+spent today, tasks finished in the last hour and heartbeat age. Rows are
+sorted by usage, heaviest first.`,
+		Example: `  orca top pods
+  orca top pools -p myproject
+  orca top pods --watch`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetString("project")
+			resourceType := normalizeResourceType(args[0])
+			if resourceType != "agentpods" && resourceType != "agentpools" {
+				return fmt.Errorf("top supports pods or pools, got %q", args[0])
+			}
+
+			if watch {
+				return watchTop(cmd.Context(), resourceType, project, interval)
+			}
+			return printTop(resourceType, project)
+		},
+	}
+
+	cmd.Flags().StringP("project", "p", "default", "Project name")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Refresh continuously")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Refresh interval with --watch")
+
+	return cmd
+}
+
+// printTop prints usage for the pods or pools of project.
+func printTop(resourceType, project string) error {
+	pods, err := apiClient.ListAgentPods(project)
+	if err != nil {
+		return err
+	}
+	tasks, err := apiClient.ListDevTasks(project)
+	if err != nil {
+		return err
+	}
+	printUsage(resourceType, pods, tasks)
+	return nil
+}
+
+// watchTop reprints usage for the pods or pools of project every interval
+// until ctx is cancelled. Pods and tasks are read from informers, so the
+// server is not asked to list all tasks on every refresh.
+func watchTop(ctx context.Context, resourceType, project string, interval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pods := client.NewAgentPodInformer(apiClient, project)
+	tasks := client.NewDevTaskInformer(apiClient, project)
+	errs := make(chan error, 2)
+	go func() { errs <- pods.Run(ctx) }()
+	go func() { errs <- tasks.Run(ctx) }()
+
+	synced := make(chan struct{})
+	go func() {
+		if pods.WaitForSync(ctx) && tasks.WaitForSync(ctx) {
+			close(synced)
+		}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errs:
+		return err
+	case <-synced:
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Clear the screen and move the cursor home.
+		fmt.Print("\033[H\033[2J")
+		printUsage(resourceType, pods.List(), tasks.List())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+// printUsage prints usage for pods, or their pools, from tasks.
+func printUsage(resourceType string, pods []v1alpha1.AgentPod, tasks []v1alpha1.DevTask) {
+	usage := computePodUsage(pods, tasks, time.Now())
+	if resourceType == "agentpools" {
+		pools := rollupPoolUsage(usage)
+		if len(pools) == 0 && outputFormat == "table" {
+			printNone("No agent pools found.")
+			return
+		}
+		items := make([]interface{}, len(pools))
+		for i := range pools {
+			items[i] = &pools[i]
+		}
+		printOutput(items, []string{"NAME", "PODS", "ACTIVE", "TOKENS(TODAY)", "COST(TODAY)", "TASKS/H"}, poolUsageToRow)
+		return
+	}
+
+	if len(usage) == 0 && outputFormat == "table" {
+		printNone("No agent pods found.")
+		return
+	}
+	items := make([]interface{}, len(usage))
+	for i := range usage {
+		items[i] = &usage[i]
+	}
+	printOutput(items, []string{"NAME", "PHASE", "ACTIVE", "TOKENS(TODAY)", "COST(TODAY)", "TASKS/H", "HEARTBEAT"}, podUsageToRow)
+}
+
+// computePodUsage aggregates task usage per pod. "Today" starts at local
+// midnight; tasks/hour counts tasks that finished in the hour before now.
+func computePodUsage(pods []v1alpha1.AgentPod, tasks []v1alpha1.DevTask, now time.Time) []podUsage {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	hourAgo := now.Add(-time.Hour)
+
+	usage := make([]podUsage, 0, len(pods))
+	index := make(map[string]int, len(pods))
+	for _, pod := range pods {
+		index[pod.Metadata.Name] = len(usage)
+		usage = append(usage, podUsage{
+			Name:        pod.Metadata.Name,
+			Pool:        pod.OwnerPool(),
+			Phase:       string(pod.Status.Phase),
+			ActiveTasks: pod.Status.ActiveTasks,
+			Heartbeat:   pod.Status.LastHeartbeat,
+		})
+	}
+
+	for _, task := range tasks {
+		i, ok := index[task.Status.AssignedPod]
+		if !ok || task.Status.FinishedAt.IsZero() {
+			continue
+		}
+		if !task.Status.FinishedAt.Before(midnight) {
+			usage[i].TokensToday += task.Status.TokensIn + task.Status.TokensOut
+			usage[i].CostToday += task.Status.CostUSD
+		}
+		if task.Status.FinishedAt.After(hourAgo) {
+			usage[i].TasksPerHour++
+		}
+	}
+
+	sort.SliceStable(usage, func(a, b int) bool {
+		return usageLess(usage[a].TokensToday, usage[a].ActiveTasks, usage[a].Name,
+			usage[b].TokensToday, usage[b].ActiveTasks, usage[b].Name)
+	})
+	return usage
+}
+
+// rollupPoolUsage sums pod usage per owning pool. Standalone pods are skipped.
+func rollupPoolUsage(pods []podUsage) []poolUsage {
+	var pools []poolUsage
+	index := make(map[string]int)
+	for _, p := range pods {
+		if p.Pool == "" {
+			continue
+		}
+		i, ok := index[p.Pool]
+		if !ok {
+			i = len(pools)
+			index[p.Pool] = i
+			pools = append(pools, poolUsage{Name: p.Pool})
+		}
+		pools[i].Pods++
+		pools[i].ActiveTasks += p.ActiveTasks
+		pools[i].TokensToday += p.TokensToday
+		pools[i].CostToday += p.CostToday
+		pools[i].TasksPerHour += p.TasksPerHour
+	}
+
+	sort.SliceStable(pools, func(a, b int) bool {
+		return usageLess(pools[a].TokensToday, pools[a].ActiveTasks, pools[a].Name,
+			pools[b].TokensToday, pools[b].ActiveTasks, pools[b].Name)
+	})
+	return pools
+}
+
+// usageLess orders rows by tokens spent today, then active tasks (both
+// descending), then name.
+func usageLess(tokensA, activeA int, nameA string, tokensB, activeB int, nameB string) bool {
+	if tokensA != tokensB {
+		return tokensA > tokensB
+	}
+	if activeA != activeB {
+		return activeA > activeB
+	}
+	return nameA < nameB
+}
+
+func podUsageToRow(v interface{}) []string {
+	u, ok := v.(*podUsage)
+	if !ok {
+		return []string{"?", "?", "?", "?", "?", "?", "?"}
+	}
+	return []string{
+		u.Name,
+		u.Phase,
+		fmt.Sprintf("%d", u.ActiveTasks),
+		fmt.Sprintf("%d", u.TokensToday),
+		fmt.Sprintf("$%.4f", u.CostToday),
+		fmt.Sprintf("%d", u.TasksPerHour),
+		formatAge(u.Heartbeat),
+	}
+}
+
+func poolUsageToRow(v interface{}) []string {
+	u, ok := v.(*poolUsage)
+	if !ok {
+		return []string{"?", "?", "?", "?", "?", "?"}
+	}
+	return []string{
+		u.Name,
+		fmt.Sprintf("%d", u.Pods),
+		fmt.Sprintf("%d", u.ActiveTasks),
+		fmt.Sprintf("%d", u.TokensToday),
+		fmt.Sprintf("$%.4f", u.CostToday),
+		fmt.Sprintf("%d", u.TasksPerHour),
+	}
+}
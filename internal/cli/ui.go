@@ -9,17 +9,46 @@ import (
 )
 
 func newUICmd() *cobra.Command {
-	var server string
+	var skinPath string
 
 	cmd := &cobra.Command{
 		Use:     "ui",
-		Aliases: []string{"top", "dashboard"},
+		Aliases: []string{"dashboard"},
 		Short:   "Launch the interactive terminal UI",
-		Long:    "Launch a k9s-style terminal UI for real-time monitoring and management of Orca resources.",
+		Long: `Launch a k9s-style terminal UI for real-time monitoring and management of Orca resources.
+
+Colors come from the skin file ~/.orca/skin.yaml when it exists. It only
+needs the colors it changes, for example on a light terminal:
+
+  text: black
+  background: default
+  header: {fg: black, bg: lightskyblue}
+  footer: {fg: black, bg: lightgray}
+  tableHeader: {fg: white, bg: teal}
+  keys: darkblue
+  selection: {fg: white, bg: navy}
+  border: teal
+  phases:
+    Running: darkorange
+    Pending: gray`,
 		Example: `  orca ui
-  orca ui --server http://127.0.0.1:7117`,
+  orca ui --server http://127.0.0.1:7117
+  orca ui --skin ~/.orca/light.yaml`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			app := tui.NewApp(server)
+			if skinPath == "" {
+				skinPath = tui.DefaultSkinPath()
+			}
+			skin, err := tui.LoadSkin(skinPath)
+			if err != nil {
+				return err
+			}
+
+			app := tui.NewApp(tui.Config{
+				Client:  apiClient,
+				Server:  serverAddr,
+				Context: activeContext,
+				Skin:    skin,
+			})
 			if err := app.Run(); err != nil {
 				return fmt.Errorf("UI error: %w", err)
 			}
@@ -27,7 +56,7 @@ func newUICmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&server, "server", "http://127.0.0.1:7117", "Orca API server address")
+	cmd.Flags().StringVar(&skinPath, "skin", "", "Skin file with the UI colors (default: ~/.orca/skin.yaml)")
 
 	return cmd
 }
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/klubi/orca/pkg/client"
+)
+
+// ClientConfig is the CLI's configuration file (~/.orca/config). It holds
+// named contexts, each pointing at an Orca server, and the context in use.
+// Project is the default project when no context is in use or the context
+// does not name one.
+type ClientConfig struct {
+	CurrentContext string    `yaml:"currentContext,omitempty"`
+	Project        string    `yaml:"project,omitempty"`
+	Contexts       []Context `yaml:"contexts,omitempty"`
+}
+
+// Context describes how to reach one Orca server.
+type Context struct {
+	Name    string `yaml:"name"`
+	Server  string `yaml:"server,omitempty"`
+	Token   string `yaml:"token,omitempty"`
+	Project string `yaml:"project,omitempty"` // default project for commands
+
+	// CertificateAuthority is a PEM file of the CAs to trust for an https
+	// server, in addition to the system's.
+	CertificateAuthority string `yaml:"certificateAuthority,omitempty"`
+	// ClientCertificate and ClientKey are PEM files of the certificate to
+	// present to servers that require one.
+	ClientCertificate string `yaml:"clientCertificate,omitempty"`
+	ClientKey         string `yaml:"clientKey,omitempty"`
+	// InsecureSkipTLSVerify accepts any server certificate. For testing only.
+	InsecureSkipTLSVerify bool `yaml:"insecureSkipTLSVerify,omitempty"`
+}
+
+// ClientOptions returns the client options for the credentials and TLS
+// settings of the context.
+func (c *Context) ClientOptions() ([]client.Option, error) {
+	opts := []client.Option{client.WithToken(c.Token)}
+
+	if c.CertificateAuthority != "" || c.InsecureSkipTLSVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipTLSVerify}
+		if c.CertificateAuthority != "" {
+			pem, err := os.ReadFile(c.CertificateAuthority)
+			if err != nil {
+				return nil, fmt.Errorf("context %s: reading certificate authority: %w", c.Name, err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("context %s: no certificates in %s", c.Name, c.CertificateAuthority)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		opts = append(opts, client.WithTLSConfig(tlsConfig))
+	}
+
+	if c.ClientCertificate != "" || c.ClientKey != "" {
+		if c.ClientCertificate == "" || c.ClientKey == "" {
+			return nil, fmt.Errorf("context %s: clientCertificate and clientKey must be set together", c.Name)
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCertificate, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("context %s: loading client certificate: %w", c.Name, err)
+		}
+		opts = append(opts, client.WithClientCert(cert))
+	}
+	return opts, nil
+}
+
+// DefaultClientConfigPath returns the CLI config path. $ORCA_CONFIG takes
+// precedence over ~/.orca/config.
+func DefaultClientConfigPath() string {
+	if path := os.Getenv("ORCA_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join("/tmp", "orca", "config")
+	}
+	return filepath.Join(home, ".orca", "config")
+}
+
+// LoadClientConfig reads the CLI config at path. A missing file yields an
+// empty config.
+func LoadClientConfig(path string) (*ClientConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &ClientConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	var cfg ClientConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Save writes the config to path. The file is only readable by the owner
+// because contexts may carry tokens.
+func (c *ClientConfig) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing config %s: %w", path, err)
+	}
+	return nil
+}
+
+// Context returns the named context, or nil if there is none.
+func (c *ClientConfig) Context(name string) *Context {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i]
+		}
+	}
+	return nil
+}
+
+// Current returns the current context, or nil if none is set or it no
+// longer exists.
+func (c *ClientConfig) Current() *Context {
+	if c.CurrentContext == "" {
+		return nil
+	}
+	return c.Context(c.CurrentContext)
+}
+
+// SetContext adds ctx, replacing an existing context of the same name.
+func (c *ClientConfig) SetContext(ctx Context) {
+	if existing := c.Context(ctx.Name); existing != nil {
+		*existing = ctx
+		return
+	}
+	c.Contexts = append(c.Contexts, ctx)
+}
+
+// DeleteContext removes the named context and reports whether it existed.
+// Deleting the current context unsets it.
+func (c *ClientConfig) DeleteContext(name string) bool {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			c.Contexts = append(c.Contexts[:i], c.Contexts[i+1:]...)
+			if c.CurrentContext == name {
+				c.CurrentContext = ""
+			}
+			return true
+		}
+	}
+	return false
+}
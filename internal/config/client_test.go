@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadClientConfigMissingFile(t *testing.T) {
+	cfg, err := LoadClientConfig(filepath.Join(t.TempDir(), "config"))
+	if err != nil {
+		t.Fatalf("LoadClientConfig: %v", err)
+	}
+	if cfg.Current() != nil || len(cfg.Contexts) != 0 {
+		t.Fatalf("expected empty config, got %+v", cfg)
+	}
+}
+
+func TestClientConfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config")
+
+	cfg := &ClientConfig{}
+	cfg.SetContext(Context{Name: "local", Server: "http://127.0.0.1:7117"})
+	cfg.SetContext(Context{Name: "team", Server: "https://orca.example.com", Token: "s3cret", Project: "web"})
+	cfg.SetContext(Context{Name: "local", Server: "http://127.0.0.1:8000"})
+	cfg.CurrentContext = "team"
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("config mode = %o, want 600", perm)
+	}
+
+	loaded, err := LoadClientConfig(path)
+	if err != nil {
+		t.Fatalf("LoadClientConfig: %v", err)
+	}
+	if len(loaded.Contexts) != 2 {
+		t.Fatalf("expected 2 contexts, got %d", len(loaded.Contexts))
+	}
+	if got := loaded.Context("local").Server; got != "http://127.0.0.1:8000" {
+		t.Errorf("local server = %q, want replaced value", got)
+	}
+	cur := loaded.Current()
+	if cur == nil || cur.Token != "s3cret" || cur.Project != "web" {
+		t.Errorf("current context = %+v", cur)
+	}
+
+	if !loaded.DeleteContext("team") {
+		t.Fatal("DeleteContext(team) = false")
+	}
+	if loaded.CurrentContext != "" {
+		t.Errorf("deleting the current context should unset it, got %q", loaded.CurrentContext)
+	}
+	if loaded.DeleteContext("team") {
+		t.Error("DeleteContext of a missing context = true")
+	}
+}
+
+func TestClientConfigDefaultProject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	cfg := &ClientConfig{Project: "web"}
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadClientConfig(path)
+	if err != nil {
+		t.Fatalf("LoadClientConfig: %v", err)
+	}
+	if loaded.Project != "web" {
+		t.Errorf("project = %q, want web", loaded.Project)
+	}
+	if loaded.Current() != nil {
+		t.Errorf("expected no current context, got %+v", loaded.Current())
+	}
+}
+
+func TestContextClientOptions(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     Context
+		wantErr bool
+	}{
+		{"token only", Context{Name: "a", Token: "t"}, false},
+		{"insecure", Context{Name: "a", InsecureSkipTLSVerify: true}, false},
+		{"missing CA file", Context{Name: "a", CertificateAuthority: filepath.Join(dir, "missing.pem")}, true},
+		{"CA without certificates", Context{Name: "a", CertificateAuthority: notPEM}, true},
+		{"certificate without key", Context{Name: "a", ClientCertificate: notPEM}, true},
+		{"unreadable key pair", Context{Name: "a", ClientCertificate: notPEM, ClientKey: notPEM}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.ctx.ClientOptions()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ClientOptions() error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,47 +1,181 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
+// Config is the control plane's configuration, read by `orca serve` from an
+// optional YAML file (see Load) and ORCA_* environment variables (see
+// ApplyEnv). Resolve does both.
 type Config struct {
-	Server ServerConfig
-	Store  StoreConfig
-	Agent  AgentConfig
-	Log    LogConfig
+	Server    ServerConfig    `yaml:"server"`
+	Store     StoreConfig     `yaml:"store"`
+	Agent     AgentConfig     `yaml:"agent"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Audit     AuditConfig     `yaml:"audit"`
 }
 
 type ServerConfig struct {
-	Port int    // default 7117
-	Host string // default "127.0.0.1"
+	Port int        `yaml:"port"` // default 7117
+	Host string     `yaml:"host"` // default "127.0.0.1"
+	Auth AuthConfig `yaml:"auth"`
+	TLS  TLSConfig  `yaml:"tls"`
+	// MaxBodyBytes caps the size of request bodies; 0 lifts the cap.
+	// Backups restored through the API are exempt. Default 1 MiB.
+	MaxBodyBytes int             `yaml:"maxBodyBytes"`
+	RateLimit    RateLimitConfig `yaml:"rateLimit"`
+}
+
+// RateLimitConfig caps the rate at which each client of the API server
+// makes requests. A client is the user of its bearer token or, when the
+// server does not authenticate requests, its IP address.
+type RateLimitConfig struct {
+	RateLimit `yaml:",inline"`
+	// Clients overrides the limit for the clients it names.
+	Clients map[string]RateLimit `yaml:"clients"`
+}
+
+// RateLimit lets a client make RequestsPerSecond requests a second, and
+// Burst requests at once after it has been idle. A RequestsPerSecond of 0
+// lets it make any number.
+type RateLimit struct {
+	RequestsPerSecond float64 `yaml:"requestsPerSecond"`
+	Burst             int     `yaml:"burst"`
+}
+
+// Enabled reports whether l limits the rate of requests.
+func (l RateLimit) Enabled() bool {
+	return l.RequestsPerSecond > 0
+}
+
+// For returns the limit of client.
+func (c RateLimitConfig) For(client string) RateLimit {
+	if l, ok := c.Clients[client]; ok {
+		return l
+	}
+	return c.RateLimit
+}
+
+// TLSConfig makes the API server serve HTTPS with the certificate in
+// CertFile and its key in KeyFile, PEM files both.
+type TLSConfig struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	// ClientCAFile, if set, is a PEM file of the CAs whose client
+	// certificates the server requires.
+	ClientCAFile string `yaml:"clientCAFile"`
+}
+
+// Enabled reports whether the API server serves HTTPS.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// AuthConfig lists the bearer tokens the API server accepts. Without any
+// tokens or token file the server accepts anonymous requests.
+type AuthConfig struct {
+	Tokens []TokenConfig `yaml:"tokens"`
+	// TokenFile holds further tokens, one per line, each optionally
+	// followed by whitespace and the name of its user. Blank lines and
+	// lines starting with # are ignored. The server re-reads the file when
+	// it changes.
+	TokenFile string `yaml:"tokenFile"`
+}
+
+// TokenConfig is a bearer token and the name of the user or component it
+// is issued to, which the server logs.
+type TokenConfig struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
+}
+
+// Enabled reports whether the API server requires a bearer token.
+func (a AuthConfig) Enabled() bool {
+	return len(a.Tokens) > 0 || a.TokenFile != ""
 }
 
 type StoreConfig struct {
-	Type    string // "bolt" or "memory"
-	DataDir string // default "~/.orca/data"
+	Type    string `yaml:"type"`    // "bolt" or "memory"
+	DataDir string `yaml:"dataDir"` // default "~/.orca/data"
 }
 
 type AgentConfig struct {
-	ClaudeCLI           string // path to claude binary (default: "claude", resolved via PATH)
-	DefaultModel        string // default "claude-sonnet-4-20250514"
-	DefaultMaxTokens    int    // default 8192
-	DefaultTimeout      int    // default 300 (seconds)
-	HealthCheckInterval int    // default 30 (seconds)
+	ClaudeCLI           string `yaml:"claudeCLI"`           // path to claude binary (default: "claude", resolved via PATH)
+	DefaultModel        string `yaml:"defaultModel"`        // default "claude-sonnet-4-20250514"
+	DefaultMaxTokens    int    `yaml:"defaultMaxTokens"`    // default 8192
+	DefaultTimeout      int    `yaml:"defaultTimeout"`      // default 300 (seconds)
+	HealthCheckInterval int    `yaml:"healthCheckInterval"` // default 30 (seconds)
+}
+
+type SchedulerConfig struct {
+	Weights SchedulerWeights `yaml:"weights"`
+}
+
+// SchedulerWeights scale the scheduler's priority scores before they are
+// summed; 0 turns a priority off.
+type SchedulerWeights struct {
+	LeastLoaded     int `yaml:"leastLoaded"`     // default 1
+	CapabilityMatch int `yaml:"capabilityMatch"` // default 1
+	ModelPreference int `yaml:"modelPreference"` // default 1
 }
 
 type LogConfig struct {
-	Level  string // default "info"
-	Format string // default "console"
+	Level  string `yaml:"level"`  // default "info"
+	Format string `yaml:"format"` // "console" (default) or "json"
+	// Components overrides Level for the named components, e.g.
+	// scheduler: debug. See internal/logging for the component names.
+	Components map[string]string `yaml:"components"`
+	// File, if set, receives the logs instead of stderr. It is rotated
+	// when it grows beyond MaxSizeMB, keeping MaxBackups old files.
+	File       string `yaml:"file"`
+	MaxSizeMB  int    `yaml:"maxSizeMB"`  // default 100
+	MaxBackups int    `yaml:"maxBackups"` // default 5
+}
+
+// TracingConfig configures the export of OpenTelemetry traces over OTLP/HTTP.
+// The standard OTEL_EXPORTER_OTLP_* environment variables also apply.
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled"`
+	Endpoint    string  `yaml:"endpoint"`    // collector host:port, default "localhost:4318"
+	Insecure    bool    `yaml:"insecure"`    // plain HTTP instead of HTTPS
+	SampleRatio float64 `yaml:"sampleRatio"` // share of traces kept, default 1
+}
+
+// AuditConfig turns on the audit log of the mutating API requests.
+type AuditConfig struct {
+	// Backend is "store" to keep the entries in the store, where `orca get
+	// audit` reads them, "file" to append them to File as JSON lines, or
+	// empty to turn auditing off.
+	Backend string `yaml:"backend"`
+	File    string `yaml:"file"`
+	// MaxEntries is how many entries the store backend keeps, the oldest
+	// being deleted first; 0 keeps them all. Default 10000.
+	MaxEntries int `yaml:"maxEntries"`
 }
 
 // DefaultConfig returns a Config populated with all default values.
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: 7117,
-			Host: "127.0.0.1",
+			Port:         7117,
+			Host:         "127.0.0.1",
+			MaxBodyBytes: 1 << 20,
+			RateLimit: RateLimitConfig{
+				RateLimit: RateLimit{RequestsPerSecond: 50, Burst: 100},
+			},
 		},
 		Store: StoreConfig{
 			Type:    "bolt",
@@ -54,18 +188,244 @@ func DefaultConfig() *Config {
 			DefaultTimeout:      300,
 			HealthCheckInterval: 30,
 		},
+		Scheduler: SchedulerConfig{
+			Weights: SchedulerWeights{
+				LeastLoaded:     1,
+				CapabilityMatch: 1,
+				ModelPreference: 1,
+			},
+		},
 		Log: LogConfig{
-			Level:  "info",
-			Format: "console",
+			Level:      "info",
+			Format:     "console",
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+		},
+		Tracing: TracingConfig{
+			SampleRatio: 1,
+		},
+		Audit: AuditConfig{
+			MaxEntries: 10000,
 		},
 	}
 }
 
+// Load reads the YAML config file at path on top of the defaults: settings
+// the file leaves out keep their default values. A leading "~/" in the data
+// directory is expanded to the user's home directory.
+func Load(path string) (*Config, error) {
+	cfg := DefaultConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+	// Unknown keys are rejected so that typos don't go unnoticed. An empty
+	// file decodes to io.EOF and leaves the defaults.
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+
+	cfg.Store.DataDir = expandHome(cfg.Store.DataDir)
+	cfg.Log.File = expandHome(cfg.Log.File)
+	cfg.Server.Auth.TokenFile = expandHome(cfg.Server.Auth.TokenFile)
+	cfg.Server.TLS.CertFile = expandHome(cfg.Server.TLS.CertFile)
+	cfg.Server.TLS.KeyFile = expandHome(cfg.Server.TLS.KeyFile)
+	cfg.Server.TLS.ClientCAFile = expandHome(cfg.Server.TLS.ClientCAFile)
+	cfg.Audit.File = expandHome(cfg.Audit.File)
+	return cfg, nil
+}
+
+// DefaultConfigPath returns the path of the control plane's config file
+// when none is given: ~/.orca/config.yaml.
+func DefaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join("/tmp", "orca", "config.yaml")
+	}
+	return filepath.Join(home, ".orca", "config.yaml")
+}
+
+// Resolve builds the configuration from the defaults, the config file at
+// path and the environment, in increasing precedence. With an empty path the
+// file at DefaultConfigPath is read if it exists. Command-line flags are
+// applied by the caller, which should then call Validate.
+func Resolve(path string) (*Config, error) {
+	cfg := DefaultConfig()
+	if path == "" {
+		if _, err := os.Stat(DefaultConfigPath()); err == nil {
+			path = DefaultConfigPath()
+		}
+	}
+	if path != "" {
+		var err error
+		if cfg, err = Load(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ApplyEnv overrides settings with the ORCA_* environment variables that are
+// set, e.g. ORCA_SERVER_PORT or ORCA_AGENT_CLAUDE_CLI.
+func (c *Config) ApplyEnv() error {
+	strs := []struct {
+		name  string
+		field *string
+	}{
+		{"ORCA_SERVER_HOST", &c.Server.Host},
+		{"ORCA_SERVER_AUTH_TOKEN_FILE", &c.Server.Auth.TokenFile},
+		{"ORCA_SERVER_TLS_CERT_FILE", &c.Server.TLS.CertFile},
+		{"ORCA_SERVER_TLS_KEY_FILE", &c.Server.TLS.KeyFile},
+		{"ORCA_STORE_TYPE", &c.Store.Type},
+		{"ORCA_STORE_DATA_DIR", &c.Store.DataDir},
+		{"ORCA_AGENT_CLAUDE_CLI", &c.Agent.ClaudeCLI},
+		{"ORCA_AGENT_DEFAULT_MODEL", &c.Agent.DefaultModel},
+		{"ORCA_LOG_LEVEL", &c.Log.Level},
+		{"ORCA_LOG_FORMAT", &c.Log.Format},
+		{"ORCA_LOG_FILE", &c.Log.File},
+		{"ORCA_AUDIT_BACKEND", &c.Audit.Backend},
+		{"ORCA_AUDIT_FILE", &c.Audit.File},
+	}
+	for _, e := range strs {
+		if v, ok := os.LookupEnv(e.name); ok {
+			*e.field = v
+		}
+	}
+
+	ints := []struct {
+		name  string
+		field *int
+	}{
+		{"ORCA_SERVER_PORT", &c.Server.Port},
+		{"ORCA_SERVER_MAX_BODY_BYTES", &c.Server.MaxBodyBytes},
+		{"ORCA_AGENT_DEFAULT_MAX_TOKENS", &c.Agent.DefaultMaxTokens},
+		{"ORCA_AGENT_DEFAULT_TIMEOUT", &c.Agent.DefaultTimeout},
+		{"ORCA_AGENT_HEALTH_CHECK_INTERVAL", &c.Agent.HealthCheckInterval},
+	}
+	for _, e := range ints {
+		v, ok := os.LookupEnv(e.name)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not an integer", e.name, v)
+		}
+		*e.field = n
+	}
+
+	c.Store.DataDir = expandHome(c.Store.DataDir)
+	c.Log.File = expandHome(c.Log.File)
+	c.Server.Auth.TokenFile = expandHome(c.Server.Auth.TokenFile)
+	c.Server.TLS.CertFile = expandHome(c.Server.TLS.CertFile)
+	c.Server.TLS.KeyFile = expandHome(c.Server.TLS.KeyFile)
+	c.Server.TLS.ClientCAFile = expandHome(c.Server.TLS.ClientCAFile)
+	c.Audit.File = expandHome(c.Audit.File)
+	return nil
+}
+
+// Validate checks that the settings make sense, reporting every problem it
+// finds by its config file key.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port: must be between 1 and 65535, got %d", c.Server.Port)
+	for i, t := range c.Server.Auth.Tokens {
+		check(t.Token != "", "server.auth.tokens[%d].token: must be set", i)
+		check(!strings.ContainsAny(t.Token, " \t\r\n"), "server.auth.tokens[%d].token: must not contain whitespace", i)
+	}
+	check((c.Server.TLS.CertFile == "") == (c.Server.TLS.KeyFile == ""),
+		"server.tls: certFile and keyFile must be set together")
+	check(c.Server.TLS.ClientCAFile == "" || c.Server.TLS.Enabled(),
+		"server.tls.clientCAFile: needs certFile and keyFile")
+	check(c.Server.MaxBodyBytes >= 0, "server.maxBodyBytes: must not be negative, got %d", c.Server.MaxBodyBytes)
+	checkRate := func(key string, l RateLimit) {
+		check(l.RequestsPerSecond >= 0, "%s.requestsPerSecond: must not be negative, got %v", key, l.RequestsPerSecond)
+		check(!l.Enabled() || l.Burst >= 1, "%s.burst: must be at least 1, got %d", key, l.Burst)
+	}
+	checkRate("server.rateLimit", c.Server.RateLimit.RateLimit)
+	for _, client := range slices.Sorted(maps.Keys(c.Server.RateLimit.Clients)) {
+		checkRate("server.rateLimit.clients."+client, c.Server.RateLimit.Clients[client])
+	}
+	switch c.Store.Type {
+	case "bolt":
+		check(c.Store.DataDir != "", "store.dataDir: must be set for the bolt store")
+	case "memory":
+	default:
+		errs = append(errs, fmt.Errorf("store.type: unknown store type %q (want bolt or memory)", c.Store.Type))
+	}
+	check(c.Agent.ClaudeCLI != "", "agent.claudeCLI: must be set")
+	check(c.Agent.DefaultModel != "", "agent.defaultModel: must be set")
+	check(c.Agent.DefaultMaxTokens > 0, "agent.defaultMaxTokens: must be positive, got %d", c.Agent.DefaultMaxTokens)
+	check(c.Agent.DefaultTimeout > 0, "agent.defaultTimeout: must be positive, got %d", c.Agent.DefaultTimeout)
+	check(c.Agent.HealthCheckInterval > 0, "agent.healthCheckInterval: must be positive, got %d", c.Agent.HealthCheckInterval)
+	w := c.Scheduler.Weights
+	check(w.LeastLoaded >= 0 && w.CapabilityMatch >= 0 && w.ModelPreference >= 0,
+		"scheduler.weights: must not be negative, got %+v", w)
+	checkLevel := func(key, level string) {
+		switch level {
+		case "debug", "info", "warn", "error":
+		default:
+			errs = append(errs, fmt.Errorf("%s: unknown level %q (want debug, info, warn or error)", key, level))
+		}
+	}
+	checkLevel("log.level", c.Log.Level)
+	for _, component := range slices.Sorted(maps.Keys(c.Log.Components)) {
+		checkLevel("log.components."+component, c.Log.Components[component])
+	}
+	switch c.Log.Format {
+	case "console", "json":
+	default:
+		errs = append(errs, fmt.Errorf("log.format: unknown format %q (want console or json)", c.Log.Format))
+	}
+	if c.Log.File != "" {
+		check(c.Log.MaxSizeMB > 0, "log.maxSizeMB: must be positive, got %d", c.Log.MaxSizeMB)
+		check(c.Log.MaxBackups >= 0, "log.maxBackups: must not be negative, got %d", c.Log.MaxBackups)
+	}
+
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing.sampleRatio: must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+
+	switch c.Audit.Backend {
+	case "", "store":
+	case "file":
+		check(c.Audit.File != "", "audit.file: must be set for the file backend")
+	default:
+		errs = append(errs, fmt.Errorf("audit.backend: unknown backend %q (want store or file)", c.Audit.Backend))
+	}
+	check(c.Audit.MaxEntries >= 0, "audit.maxEntries: must not be negative, got %d", c.Audit.MaxEntries)
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return nil
+}
+
 // ServerAddress returns the listen address in "host:port" format.
 func (c *Config) ServerAddress() string {
 	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
 }
 
+// ServerURL returns the URL of the API server, e.g. "https://0.0.0.0:7117".
+func (c *Config) ServerURL() string {
+	scheme := "http"
+	if c.Server.TLS.Enabled() {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, c.Server.Host, c.Server.Port)
+}
+
 // DBPath returns the full path to the BoltDB file (DataDir + "/orca.db").
 func (c *Config) DBPath() string {
 	return filepath.Join(c.Store.DataDir, "orca.db")
@@ -81,3 +441,16 @@ func defaultDataDir() string {
 	}
 	return filepath.Join(home, ".orca", "data")
 }
+
+// expandHome replaces a leading "~/" in path with the user's home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
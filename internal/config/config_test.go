@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := []byte(`
+server:
+  port: 8000
+store:
+  type: memory
+  dataDir: ~/orca-data
+agent:
+  claudeCLI: /opt/claude
+log:
+  level: debug
+`)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 8000 || cfg.Store.Type != "memory" || cfg.Agent.ClaudeCLI != "/opt/claude" || cfg.Log.Level != "debug" {
+		t.Errorf("file settings not applied: %+v", cfg)
+	}
+	if cfg.Server.Host != "127.0.0.1" || cfg.Agent.DefaultMaxTokens != 8192 || cfg.Log.Format != "console" {
+		t.Errorf("unset settings lost their defaults: %+v", cfg)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if want := filepath.Join(home, "orca-data"); cfg.Store.DataDir != want {
+			t.Errorf("data dir = %q, want %q", cfg.Store.DataDir, want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+
+	path := filepath.Join(dir, "typo.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  prot: 8000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for an unknown key")
+	}
+
+	empty := filepath.Join(dir, "empty.yaml")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(empty)
+	if err != nil {
+		t.Fatalf("Load of an empty file: %v", err)
+	}
+	if cfg.Server.Port != 7117 {
+		t.Errorf("expected defaults from an empty file, got port %d", cfg.Server.Port)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("ORCA_SERVER_PORT", "9000")
+	t.Setenv("ORCA_STORE_TYPE", "memory")
+	t.Setenv("ORCA_AGENT_CLAUDE_CLI", "/usr/bin/claude")
+
+	cfg := DefaultConfig()
+	if err := cfg.ApplyEnv(); err != nil {
+		t.Fatalf("ApplyEnv: %v", err)
+	}
+	if cfg.Server.Port != 9000 || cfg.Store.Type != "memory" || cfg.Agent.ClaudeCLI != "/usr/bin/claude" {
+		t.Errorf("environment not applied: %+v", cfg)
+	}
+
+	t.Setenv("ORCA_AGENT_DEFAULT_TIMEOUT", "soon")
+	if err := cfg.ApplyEnv(); err == nil {
+		t.Error("expected error for a non-integer value")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ORCA_SERVER_PORT", "")
+	os.Unsetenv("ORCA_SERVER_PORT")
+
+	cfg, err := Resolve("")
+	if err != nil {
+		t.Fatalf("Resolve without a config file: %v", err)
+	}
+	if cfg.Server.Port != 7117 {
+		t.Errorf("port = %d, want the default", cfg.Server.Port)
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, ".orca"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("server:\n  port: 8000\n  host: 0.0.0.0\n")
+	if err := os.WriteFile(filepath.Join(home, ".orca", "config.yaml"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ORCA_SERVER_PORT", "9000")
+	if cfg, err = Resolve(""); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if cfg.Server.Host != "0.0.0.0" || cfg.Server.Port != 9000 {
+		t.Errorf("server = %+v, want the file's host and the environment's port", cfg.Server)
+	}
+
+	if _, err := Resolve(filepath.Join(home, "missing.yaml")); err == nil {
+		t.Error("expected error for a missing explicit config file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("defaults do not validate: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Server.Port = 0
+	cfg.Store.Type = "postgres"
+	cfg.Log.Level = "loud"
+	cfg.Server.RateLimit.Clients = map[string]RateLimit{"ci": {RequestsPerSecond: 5}}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an invalid configuration to fail")
+	}
+	for _, want := range []string{"server.port", `store.type: unknown store type "postgres"`, "log.level", "server.rateLimit.clients.ci.burst"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	cur := DefaultConfig()
+	next := DefaultConfig()
+	next.Log.Level = "debug"
+	next.Scheduler.Weights.LeastLoaded = 3
+	next.Server.Port = 8000
+	next.Server.RateLimit.RequestsPerSecond = 10
+
+	applied, changed, needRestart := cur.Reload(next)
+	if want := []string{"server.rateLimit.requestsPerSecond", "scheduler.weights.leastLoaded", "log.level"}; !slices.Equal(changed, want) {
+		t.Errorf("changed = %q, want %q", changed, want)
+	}
+	if want := []string{"server.port"}; !slices.Equal(needRestart, want) {
+		t.Errorf("needRestart = %q, want %q", needRestart, want)
+	}
+	if applied.Log.Level != "debug" || applied.Scheduler.Weights.LeastLoaded != 3 || applied.Server.Port != 7117 {
+		t.Errorf("applied = %+v, want the new log level and weights on the old port", applied)
+	}
+	if cur.Log.Level != "info" {
+		t.Error("Reload modified the current config")
+	}
+}
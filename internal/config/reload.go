@@ -0,0 +1,106 @@
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// reloadable are the settings a running control plane picks up from a
+// changed config file, by config file key or key prefix.
+var reloadable = []string{
+	"server.rateLimit",
+	"agent.defaultModel",
+	"agent.healthCheckInterval",
+	"scheduler.weights",
+	"log.level",
+	"log.components",
+}
+
+// Reload returns a copy of c with the reloadable settings of next, along
+// with the keys of the reloadable settings that changed and of the other
+// changed settings, which only take effect after a restart.
+func (c *Config) Reload(next *Config) (applied *Config, changed, needRestart []string) {
+	var keys []string
+	diffKeys("", reflect.ValueOf(*c), reflect.ValueOf(*next), &keys)
+	for _, key := range keys {
+		if isReloadable(key) {
+			changed = append(changed, key)
+		} else {
+			needRestart = append(needRestart, key)
+		}
+	}
+
+	cfg := *c
+	cfg.Server.RateLimit = next.Server.RateLimit
+	cfg.Agent.DefaultModel = next.Agent.DefaultModel
+	cfg.Agent.HealthCheckInterval = next.Agent.HealthCheckInterval
+	cfg.Scheduler.Weights = next.Scheduler.Weights
+	cfg.Log.Level = next.Log.Level
+	cfg.Log.Components = next.Log.Components
+	return &cfg, changed, needRestart
+}
+
+func isReloadable(key string) bool {
+	for _, r := range reloadable {
+		if key == r || strings.HasPrefix(key, r+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// diffKeys appends the config file keys of the settings that differ
+// between a and b, which are values of the same struct type.
+func diffKeys(prefix string, a, b reflect.Value, keys *[]string) {
+	if a.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*keys = append(*keys, prefix)
+		}
+		return
+	}
+	for i := 0; i < a.NumField(); i++ {
+		name, _, _ := strings.Cut(a.Type().Field(i).Tag.Get("yaml"), ",")
+		switch {
+		case name == "":
+			// An inlined struct's settings are keyed as its parent's.
+			name = prefix
+		case prefix != "":
+			name = prefix + "." + name
+		}
+		diffKeys(name, a.Field(i), b.Field(i), keys)
+	}
+}
+
+// Watch polls the config file at path every interval and, whenever its
+// modification time or size changes, calls onChange with the configuration
+// resolved from it as by Resolve, or the error resolving it. It returns when
+// ctx is done.
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func(*Config, error)) {
+	stamp := func() (time.Time, int64) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+	lastMod, lastSize := stamp()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		mod, size := stamp()
+		if mod.Equal(lastMod) && size == lastSize {
+			continue
+		}
+		lastMod, lastSize = mod, size
+		onChange(Resolve(path))
+	}
+}
@@ -9,33 +9,38 @@ import (
 	"github.com/google/uuid"
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/events"
 	"github.com/klubi/orca/internal/store"
 	"go.uber.org/zap"
 )
 
 // AgentPoolController manages the desired replica count for agent pools.
 type AgentPoolController struct {
-	store   store.Store
-	runtime *agent.Runtime
-	logger  *zap.Logger
+	store    store.Store
+	runtime  *agent.Runtime
+	recorder *events.Recorder
+	logger   *zap.Logger
 }
 
 // NewAgentPoolController creates a new AgentPoolController.
-func NewAgentPoolController(s store.Store, rt *agent.Runtime, logger *zap.Logger) *AgentPoolController {
+func NewAgentPoolController(s store.Store, rt *agent.Runtime, recorder *events.Recorder, logger *zap.Logger) *AgentPoolController {
 	return &AgentPoolController{
-		store:   s,
-		runtime: rt,
-		logger:  logger,
+		store:    s,
+		runtime:  rt,
+		recorder: recorder,
+		logger:   logger,
 	}
 }
 
 // Reconcile ensures the number of AgentPods matches the pool's desired replicas.
 //
 //  1. Get the AgentPool from the key.
-//  2. List all AgentPods with matching ownerPool.
+//  2. List all AgentPods the pool controls.
 //  3. If actual < desired: create new pods.
 //  4. If actual > desired: mark excess pods for termination.
-//  5. Update pool status (Replicas, ReadyReplicas, BusyReplicas counts).
+//  5. Replace one outdated pod if a rolling update is in progress.
+//  6. Update pool status (Replicas, ReadyReplicas, BusyReplicas,
+//     UpdatedReplicas counts).
 func (c *AgentPoolController) Reconcile(ctx context.Context, key string) error {
 	// If we received an AgentPod event, find its owner pool and reconcile that instead.
 	if strings.HasPrefix(key, "/"+v1alpha1.KindAgentPod+"/") {
@@ -75,7 +80,7 @@ func (c *AgentPoolController) Reconcile(ctx context.Context, key string) error {
 		if !ok {
 			continue
 		}
-		if pod.Spec.OwnerPool == pool.Metadata.Name &&
+		if pod.OwnerPool() == pool.Metadata.Name &&
 			pod.Status.Phase != v1alpha1.PodTerminated &&
 			pod.Status.Phase != v1alpha1.PodTerminating {
 			ownedPods = append(ownedPods, pod)
@@ -116,11 +121,8 @@ func (c *AgentPoolController) Reconcile(ctx context.Context, key string) error {
 			}
 			// Prefer terminating non-busy pods first.
 			if pod.Status.Phase != v1alpha1.PodBusy {
-				pod.Status.Phase = v1alpha1.PodTerminating
-				pod.Status.Message = "scaling down"
-				podKey := store.ResourceKey(v1alpha1.KindAgentPod, pod.Metadata.Project, pod.Metadata.Name)
-				if err := c.store.Update(podKey, pod); err != nil {
-					return fmt.Errorf("terminating pod %q: %w", pod.Metadata.Name, err)
+				if err := c.terminatePod(&pool, pod, "scaling down"); err != nil {
+					return err
 				}
 				terminated++
 			}
@@ -132,11 +134,8 @@ func (c *AgentPoolController) Reconcile(ctx context.Context, key string) error {
 					break
 				}
 				if pod.Status.Phase == v1alpha1.PodBusy {
-					pod.Status.Phase = v1alpha1.PodTerminating
-					pod.Status.Message = "scaling down"
-					podKey := store.ResourceKey(v1alpha1.KindAgentPod, pod.Metadata.Project, pod.Metadata.Name)
-					if err := c.store.Update(podKey, pod); err != nil {
-						return fmt.Errorf("terminating pod %q: %w", pod.Metadata.Name, err)
+					if err := c.terminatePod(&pool, pod, "scaling down"); err != nil {
+						return err
 					}
 					terminated++
 				}
@@ -148,7 +147,17 @@ func (c *AgentPoolController) Reconcile(ctx context.Context, key string) error {
 		)
 	}
 
-	// 5. Update pool status with current counts.
+	// 5. Rolling update: once the pool is at its desired size and every pod
+	// has settled, replace one pod created from an outdated template. The
+	// replacement is created by the scale-up step on the next reconcile.
+	hash := pool.Spec.Template.Hash()
+	if actual == desired {
+		if err := c.rollOne(&pool, ownedPods, hash); err != nil {
+			return err
+		}
+	}
+
+	// 6. Update pool status with current counts.
 	// Re-list pods to get accurate counts after mutations.
 	objects, err = c.store.List(prefix, func() interface{} {
 		return &v1alpha1.AgentPod{}
@@ -157,19 +166,22 @@ func (c *AgentPoolController) Reconcile(ctx context.Context, key string) error {
 		return fmt.Errorf("re-listing pods for pool %q status: %w", pool.Metadata.Name, err)
 	}
 
-	var replicas, ready, busy int
+	var replicas, ready, busy, updated int
 	for _, obj := range objects {
 		pod, ok := obj.(*v1alpha1.AgentPod)
 		if !ok {
 			continue
 		}
-		if pod.Spec.OwnerPool != pool.Metadata.Name {
+		if pod.OwnerPool() != pool.Metadata.Name {
 			continue
 		}
 		if pod.Status.Phase == v1alpha1.PodTerminated || pod.Status.Phase == v1alpha1.PodTerminating {
 			continue
 		}
 		replicas++
+		if pod.Metadata.Labels[v1alpha1.LabelTemplateHash] == hash {
+			updated++
+		}
 		switch pod.Status.Phase {
 		case v1alpha1.PodReady:
 			ready++
@@ -188,25 +200,60 @@ func (c *AgentPoolController) Reconcile(ctx context.Context, key string) error {
 		return fmt.Errorf("re-reading pool %q for status update: %w", pool.Metadata.Name, err)
 	}
 
-	// Only write if status actually changed to avoid an infinite event loop
-	// (each Update triggers a MODIFIED event which re-triggers Reconcile).
-	if freshPool.Status.Replicas == replicas &&
+	conditionsChanged := setPoolConditions(&freshPool, ready+busy, updated, replicas)
+
+	// Only write if status actually changed. The write records the
+	// generation it was computed for, so the MODIFIED event it triggers
+	// does not re-trigger Reconcile (see observedCurrent).
+	if !conditionsChanged &&
+		freshPool.Status.Replicas == replicas &&
 		freshPool.Status.ReadyReplicas == ready &&
-		freshPool.Status.BusyReplicas == busy {
+		freshPool.Status.BusyReplicas == busy &&
+		freshPool.Status.UpdatedReplicas == updated &&
+		freshPool.Status.TemplateHash == hash &&
+		freshPool.Status.ObservedGeneration == freshPool.Metadata.Generation {
 		return nil
 	}
 
+	freshPool.Status.ObservedGeneration = freshPool.Metadata.Generation
 	freshPool.Status.Replicas = replicas
 	freshPool.Status.ReadyReplicas = ready
 	freshPool.Status.BusyReplicas = busy
+	freshPool.Status.UpdatedReplicas = updated
+	freshPool.Status.TemplateHash = hash
 
-	if err := c.store.Update(key, &freshPool); err != nil {
+	// Write only over the version read, so that a spec change made since,
+	// e.g. by the scale endpoint, is not lost; the conflict requeues the pool.
+	if err := c.store.UpdateIfVersion(key, &freshPool, freshPool.Metadata.ResourceVersion); err != nil {
 		return fmt.Errorf("updating pool %q status: %w", pool.Metadata.Name, err)
 	}
 
 	return nil
 }
 
+// setPoolConditions sets the Available and Progressing conditions of pool
+// from the counts of its available (Ready or Busy), updated and live pods,
+// and reports whether they changed.
+func setPoolConditions(pool *v1alpha1.AgentPool, available, updated, replicas int) bool {
+	desired := pool.Spec.Replicas
+	availableCond := v1alpha1.NewCondition(v1alpha1.ConditionAvailable, v1alpha1.ConditionTrue,
+		v1alpha1.ReasonReplicasAvailable, fmt.Sprintf("%d of %d replicas available", available, desired))
+	if available < desired {
+		availableCond.Status = v1alpha1.ConditionFalse
+		availableCond.Reason = v1alpha1.ReasonReplicasUnavailable
+	}
+	progressing := v1alpha1.NewCondition(v1alpha1.ConditionProgressing, v1alpha1.ConditionFalse,
+		v1alpha1.ReasonUpToDate, "")
+	if updated < replicas {
+		progressing.Status = v1alpha1.ConditionTrue
+		progressing.Reason = v1alpha1.ReasonRollingUpdate
+		progressing.Message = fmt.Sprintf("%d of %d pods updated", updated, replicas)
+	}
+
+	changed := v1alpha1.SetCondition(&pool.Status.Conditions, availableCond)
+	return v1alpha1.SetCondition(&pool.Status.Conditions, progressing) || changed
+}
+
 // reconcileFromPodEvent handles AgentPod events by finding the owner pool
 // and delegating to the main Reconcile method. This avoids a TOCTOU race
 // where a separate read-modify-write could overwrite Spec changes (e.g. replicas).
@@ -218,29 +265,80 @@ func (c *AgentPoolController) reconcileFromPodEvent(ctx context.Context, podKey
 		}
 		return err
 	}
-	if pod.Spec.OwnerPool == "" {
+	if pod.OwnerPool() == "" {
 		return nil // Standalone pod, not managed by a pool.
 	}
 	// Delegate to the main Reconcile with the pool key so that
 	// scale up/down and status update happen atomically on the latest state.
-	poolKey := store.ResourceKey(v1alpha1.KindAgentPool, pod.Metadata.Project, pod.Spec.OwnerPool)
+	poolKey := store.ResourceKey(v1alpha1.KindAgentPool, pod.Metadata.Project, pod.OwnerPool())
 	return c.Reconcile(ctx, poolKey)
 }
 
+// rollOne terminates one outdated, idle pod when no pod of the pool is
+// still starting up: it marks the pod Terminating and deletes it, so that
+// the runtime, or the worker of its node, stops it before it is removed.
+// Busy outdated pods are left to finish their work and are replaced on a
+// later reconcile.
+func (c *AgentPoolController) rollOne(pool *v1alpha1.AgentPool, pods []*v1alpha1.AgentPod, hash string) error {
+	var candidate *v1alpha1.AgentPod
+	for _, pod := range pods {
+		if pod.Status.Phase != v1alpha1.PodReady && pod.Status.Phase != v1alpha1.PodBusy {
+			return nil // wait for the previous replacement to come up
+		}
+		if candidate == nil && pod.Status.Phase == v1alpha1.PodReady &&
+			pod.Metadata.Labels[v1alpha1.LabelTemplateHash] != hash {
+			candidate = pod
+		}
+	}
+	if candidate == nil {
+		return nil
+	}
+
+	if err := c.terminatePod(pool, candidate, "rolling update"); err != nil {
+		return err
+	}
+	podKey := store.ResourceKey(v1alpha1.KindAgentPod, candidate.Metadata.Project, candidate.Metadata.Name)
+	if err := c.store.Delete(podKey); err != nil && err != store.ErrNotFound {
+		return fmt.Errorf("deleting pod %q: %w", candidate.Metadata.Name, err)
+	}
+	c.logger.Info("replacing outdated pod",
+		zap.String("pool", pool.Metadata.Name),
+		zap.String("pod", candidate.Metadata.Name),
+	)
+	return nil
+}
+
+// terminatePod marks pod of pool Terminating, and no longer Ready, for
+// reason, and records it on the pool.
+func (c *AgentPoolController) terminatePod(pool *v1alpha1.AgentPool, pod *v1alpha1.AgentPod, reason string) error {
+	pod.Status.Phase = v1alpha1.PodTerminating
+	pod.Status.Message = reason
+	v1alpha1.SetCondition(&pod.Status.Conditions, v1alpha1.NewCondition(
+		v1alpha1.ConditionReady, v1alpha1.ConditionFalse, v1alpha1.ReasonTerminating, pod.Status.Message))
+	podKey := store.ResourceKey(v1alpha1.KindAgentPod, pod.Metadata.Project, pod.Metadata.Name)
+	if err := c.store.Update(podKey, pod); err != nil {
+		return fmt.Errorf("terminating pod %q: %w", pod.Metadata.Name, err)
+	}
+	c.recorder.Normal(events.Ref(v1alpha1.KindAgentPool, pool.Metadata), "Terminating", "Terminating pod %s: %s", pod.Metadata.Name, reason)
+	return nil
+}
+
 // createPod creates a new AgentPod from the pool's template.
 func (c *AgentPoolController) createPod(_ context.Context, pool *v1alpha1.AgentPool) error {
 	// Generate a short random suffix from UUID (first 8 chars).
 	suffix := strings.ReplaceAll(uuid.New().String(), "-", "")[:8]
 	podName := fmt.Sprintf("%s-%s", pool.Metadata.Name, suffix)
 
-	// Merge labels: pool selector labels + template labels.
-	labels := make(map[string]string)
+	// Merge labels: propagated pool labels + pool selector labels +
+	// template labels.
+	labels := pool.PropagatedLabels()
 	for k, v := range pool.Spec.Selector {
 		labels[k] = v
 	}
 	for k, v := range pool.Spec.Template.Metadata.Labels {
 		labels[k] = v
 	}
+	labels[v1alpha1.LabelTemplateHash] = pool.Spec.Template.Hash()
 
 	pod := &v1alpha1.AgentPod{
 		TypeMeta: v1alpha1.TypeMeta{
@@ -253,6 +351,9 @@ func (c *AgentPoolController) createPod(_ context.Context, pool *v1alpha1.AgentP
 			Labels:    labels,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
+			OwnerReferences: []v1alpha1.OwnerReference{
+				v1alpha1.NewControllerRef(v1alpha1.KindAgentPool, &pool.Metadata),
+			},
 		},
 		Spec: v1alpha1.AgentPodSpec{
 			Model:          pool.Spec.Template.Spec.Model,
@@ -262,13 +363,28 @@ func (c *AgentPoolController) createPod(_ context.Context, pool *v1alpha1.AgentP
 			MaxTokens:      pool.Spec.Template.Spec.MaxTokens,
 			Tools:          pool.Spec.Template.Spec.Tools,
 			RestartPolicy:  pool.Spec.Template.Spec.RestartPolicy,
-			OwnerPool:      pool.Metadata.Name,
+			MaxTurns:       pool.Spec.Template.Spec.MaxTurns,
+			PermissionMode: pool.Spec.Template.Spec.PermissionMode,
+			WarmSessions:   pool.Spec.Template.Spec.WarmSessions,
+			Env:            pool.Spec.Template.Spec.Env,
+			EnvFrom:        pool.Spec.Template.Spec.EnvFrom,
+			NodeName:       pool.Spec.Template.Spec.NodeName,
+
+			MaxRequestsPerMinute: pool.Spec.Template.Spec.MaxRequestsPerMinute,
+			DisallowedTools:      pool.Spec.Template.Spec.DisallowedTools,
 		},
 		Status: v1alpha1.AgentPodStatus{
 			Phase: v1alpha1.PodPending,
 		},
 	}
 
+	// The runtime, or the worker of the pod's node, must stop the pods it
+	// starts before they are removed.
+	startLocally := c.runtime != nil && pod.Spec.NodeName == ""
+	if startLocally || pod.Spec.NodeName != "" {
+		pod.Metadata.AddFinalizer(v1alpha1.FinalizerRuntime)
+	}
+
 	podKey := store.ResourceKey(v1alpha1.KindAgentPod, pool.Metadata.Project, podName)
 	if err := c.store.Create(podKey, pod); err != nil {
 		return fmt.Errorf("creating pod %q: %w", podName, err)
@@ -278,9 +394,11 @@ func (c *AgentPoolController) createPod(_ context.Context, pool *v1alpha1.AgentP
 		zap.String("pod", podName),
 		zap.String("pool", pool.Metadata.Name),
 	)
+	c.recorder.Normal(events.Ref(v1alpha1.KindAgentPool, pool.Metadata), "SuccessfulCreate", "Created pod %s", podName)
 
-	// Start the pod to transition it to Ready.
-	if c.runtime != nil {
+	// Start the pod to transition it to Ready. A pod assigned to a node is
+	// started by the node's worker.
+	if startLocally {
 		go func() {
 			if err := c.runtime.StartPod(context.Background(), pod); err != nil {
 				c.logger.Error("failed to start pod",
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// poolPods returns the pods of the pool called pool, by name.
+func poolPods(t *testing.T, st store.Store, pool string) map[string]*v1alpha1.AgentPod {
+	t.Helper()
+	objs, err := st.List("/"+v1alpha1.KindAgentPod+"/web/", func() interface{} { return &v1alpha1.AgentPod{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods := make(map[string]*v1alpha1.AgentPod)
+	for _, obj := range objs {
+		if pod := obj.(*v1alpha1.AgentPod); pod.OwnerPool() == pool {
+			pods[pod.Metadata.Name] = pod
+		}
+	}
+	return pods
+}
+
+// startPods makes the pending pods of pool Ready with the runtime
+// finalizer, as the runtime would.
+func startPods(t *testing.T, st store.Store, pool string) {
+	t.Helper()
+	for name, pod := range poolPods(t, st, pool) {
+		if pod.Status.Phase != v1alpha1.PodPending {
+			continue
+		}
+		pod.Status.Phase = v1alpha1.PodReady
+		pod.Metadata.AddFinalizer(v1alpha1.FinalizerRuntime)
+		if err := st.Update(store.ResourceKey(v1alpha1.KindAgentPod, "web", name), pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAgentPoolRollingUpdate(t *testing.T) {
+	st := store.NewMemoryStore()
+	pool := testPool("coders", "uid-1")
+	pool.Spec.Replicas = 2
+	pool.Spec.Template.Spec.Model = "claude-sonnet"
+	poolKey := mustCreate(t, st, v1alpha1.KindAgentPool, "coders", pool)
+	c := NewAgentPoolController(st, nil, nil, zap.NewNop())
+
+	reconcile := func() *v1alpha1.AgentPool {
+		t.Helper()
+		if err := c.Reconcile(context.Background(), poolKey); err != nil {
+			t.Fatal(err)
+		}
+		var got v1alpha1.AgentPool
+		if err := st.Get(poolKey, &got); err != nil {
+			t.Fatal(err)
+		}
+		return &got
+	}
+
+	reconcile()
+	startPods(t, st, "coders")
+	old := poolPods(t, st, "coders")
+	if got := reconcile(); len(old) != 2 || got.Status.UpdatedReplicas != 2 {
+		t.Fatalf("pool has %d pods, %d updated, want 2 and 2", len(old), got.Status.UpdatedReplicas)
+	}
+
+	if err := st.Get(poolKey, pool); err != nil {
+		t.Fatal(err)
+	}
+	pool.Spec.Template.Spec.Model = "claude-opus"
+	if err := st.Update(poolKey, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	for round := 1; round <= 2; round++ {
+		// One outdated pod is deleted, to be stopped by the runtime.
+		got := reconcile()
+		var replaced []string
+		for name, pod := range poolPods(t, st, "coders") {
+			if old[name] != nil && pod.Metadata.DeletionTimestamp != nil {
+				if pod.Status.Phase != v1alpha1.PodTerminating {
+					t.Errorf("replaced pod %s is %s, want Terminating", name, pod.Status.Phase)
+				}
+				replaced = append(replaced, name)
+			}
+		}
+		if len(replaced) != 1 {
+			t.Fatalf("round %d: deleted outdated pods %v, want one", round, replaced)
+		}
+		if got.Status.Replicas != 1 || got.Status.UpdatedReplicas != round-1 {
+			t.Errorf("round %d: status has %d replicas, %d updated, want 1 and %d",
+				round, got.Status.Replicas, got.Status.UpdatedReplicas, round-1)
+		}
+
+		// Its replacement is created, and no other pod is replaced until
+		// the replacement is up.
+		got = reconcile()
+		if got.Status.Replicas != 2 || got.Status.UpdatedReplicas != round {
+			t.Errorf("round %d: status has %d replicas, %d updated, want 2 and %d",
+				round, got.Status.Replicas, got.Status.UpdatedReplicas, round)
+		}
+		reconcile()
+		deleting := 0
+		for _, pod := range poolPods(t, st, "coders") {
+			if pod.Metadata.DeletionTimestamp != nil {
+				deleting++
+			}
+		}
+		if deleting != 1 {
+			t.Errorf("round %d: %d pods deleted before the replacement is up, want 1", round, deleting)
+		}
+
+		// The runtime stops the replaced pod, which removes it.
+		pod := poolPods(t, st, "coders")[replaced[0]]
+		pod.Metadata.RemoveFinalizer(v1alpha1.FinalizerRuntime)
+		if err := st.Update(store.ResourceKey(v1alpha1.KindAgentPod, "web", replaced[0]), pod); err != nil {
+			t.Fatal(err)
+		}
+		startPods(t, st, "coders")
+	}
+
+	if got := reconcile(); got.Status.Replicas != 2 || got.Status.UpdatedReplicas != 2 {
+		t.Errorf("after the rollout, status has %d replicas, %d updated, want 2 and 2", got.Status.Replicas, got.Status.UpdatedReplicas)
+	}
+}
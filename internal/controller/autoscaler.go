@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/internal/events"
+	"github.com/klubi/orca/internal/store"
+	"go.uber.org/zap"
+)
+
+// defaultScaleDownDelay applies to autoscalers without ScaleDownDelaySeconds.
+const defaultScaleDownDelay = 5 * time.Minute
+
+// AutoscalerController sizes AgentPools to their workload according to their
+// AgentPoolAutoscalers.
+type AutoscalerController struct {
+	store    store.Store
+	recorder *events.Recorder
+	logger   *zap.Logger
+}
+
+// NewAutoscalerController creates a new AutoscalerController.
+func NewAutoscalerController(s store.Store, recorder *events.Recorder, logger *zap.Logger) *AutoscalerController {
+	return &AutoscalerController{
+		store:    s,
+		recorder: recorder,
+		logger:   logger,
+	}
+}
+
+// Reconcile sets the replica count of an autoscaler's pool:
+//
+//  1. The workload is the number of tasks running on the pool's pods plus
+//     the pending tasks that may run on them (tasks for the pool, or for any
+//     pod of the project).
+//  2. The desired replica count is the workload divided by the pods'
+//     MaxConcurrency, clamped to [MinReplicas, MaxReplicas].
+//  3. The pool is scaled up at once, but only scaled down once the scale-down
+//     delay has passed since the last scaling.
+//
+// AgentPod and DevTask events change the workload, so they reconcile every
+// autoscaler in their project.
+func (c *AutoscalerController) Reconcile(ctx context.Context, key string) error {
+	if !strings.HasPrefix(key, "/"+v1alpha1.KindAgentPoolAutoscaler+"/") {
+		return c.reconcileProject(ctx, key)
+	}
+
+	var as v1alpha1.AgentPoolAutoscaler
+	if err := c.store.Get(key, &as); err != nil {
+		if err == store.ErrNotFound {
+			c.logger.Debug("autoscaler not found, possibly deleted", zap.String("key", key))
+			return nil
+		}
+		return fmt.Errorf("getting autoscaler %q: %w", key, err)
+	}
+
+	poolKey := store.ResourceKey(v1alpha1.KindAgentPool, as.Metadata.Project, as.Spec.Pool)
+	var pool v1alpha1.AgentPool
+	if err := c.store.Get(poolKey, &pool); err != nil {
+		if err == store.ErrNotFound {
+			status := as.Status
+			status.Message = fmt.Sprintf("agentpool %q not found", as.Spec.Pool)
+			return c.setStatus(key, &as, status)
+		}
+		return fmt.Errorf("getting pool %q: %w", poolKey, err)
+	}
+
+	workload, err := c.workload(&pool)
+	if err != nil {
+		return err
+	}
+	perPod := pool.Spec.Template.Spec.MaxConcurrency
+	if perPod <= 0 {
+		perPod = 1
+	}
+	desired := (workload + perPod - 1) / perPod
+	desired = max(desired, as.Spec.MinReplicas)
+	desired = min(desired, as.Spec.MaxReplicas)
+
+	current := pool.Spec.Replicas
+	status := v1alpha1.AgentPoolAutoscalerStatus{
+		CurrentReplicas: current,
+		DesiredReplicas: desired,
+		LastScaleTime:   as.Status.LastScaleTime,
+	}
+	if desired < current {
+		delay := time.Duration(as.Spec.ScaleDownDelaySeconds) * time.Second
+		if delay == 0 {
+			delay = defaultScaleDownDelay
+		}
+		if until := as.Status.LastScaleTime.Add(delay); time.Now().Before(until) {
+			// Pod heartbeats reconcile the autoscaler again later.
+			status.Message = fmt.Sprintf("scale-down delayed until %s", until.Format(time.RFC3339))
+			return c.setStatus(key, &as, status)
+		}
+	}
+	if desired == current {
+		return c.setStatus(key, &as, status)
+	}
+
+	pool.Spec.Replicas = desired
+	pool.Metadata.UpdatedAt = time.Now()
+	if err := c.store.UpdateIfVersion(poolKey, &pool, pool.Metadata.ResourceVersion); err != nil {
+		return fmt.Errorf("scaling pool %q: %w", pool.Metadata.Name, err)
+	}
+
+	reason := "ScaledUp"
+	if desired < current {
+		reason = "ScaledDown"
+	}
+	c.recorder.Normal(events.Ref(v1alpha1.KindAgentPool, pool.Metadata), reason,
+		"Autoscaler %s scaled from %d to %d replicas for %d tasks", as.Metadata.Name, current, desired, workload)
+	c.logger.Info("autoscaled pool",
+		zap.String("pool", pool.Metadata.Name),
+		zap.Int("from", current),
+		zap.Int("to", desired),
+		zap.Int("workload", workload),
+	)
+
+	status.CurrentReplicas = desired
+	status.LastScaleTime = time.Now()
+	return c.setStatus(key, &as, status)
+}
+
+// reconcileProject reconciles every autoscaler in the project of the
+// AgentPod or DevTask with the given key.
+func (c *AutoscalerController) reconcileProject(ctx context.Context, key string) error {
+	// Keys have the form /<kind>/<project>/<name>.
+	parts := strings.Split(key, "/")
+	if len(parts) != 4 {
+		return nil
+	}
+	prefix := fmt.Sprintf("/%s/%s/", v1alpha1.KindAgentPoolAutoscaler, parts[2])
+	objects, err := c.store.List(prefix, func() interface{} {
+		return &v1alpha1.AgentPoolAutoscaler{}
+	})
+	if err != nil {
+		return fmt.Errorf("listing autoscalers: %w", err)
+	}
+	for _, obj := range objects {
+		as := obj.(*v1alpha1.AgentPoolAutoscaler)
+		asKey := store.ResourceKey(v1alpha1.KindAgentPoolAutoscaler, as.Metadata.Project, as.Metadata.Name)
+		if err := c.Reconcile(ctx, asKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workload counts the tasks running on the pool's pods and the pending
+// tasks that could be scheduled onto them.
+func (c *AutoscalerController) workload(pool *v1alpha1.AgentPool) (int, error) {
+	project := pool.Metadata.Project
+
+	pods, err := c.store.List(fmt.Sprintf("/%s/%s/", v1alpha1.KindAgentPod, project), func() interface{} {
+		return &v1alpha1.AgentPod{}
+	})
+	if err != nil {
+		return 0, fmt.Errorf("listing pods for pool %q: %w", pool.Metadata.Name, err)
+	}
+	workload := 0
+	for _, obj := range pods {
+		if pod := obj.(*v1alpha1.AgentPod); pod.OwnerPool() == pool.Metadata.Name {
+			workload += pod.Status.ActiveTasks
+		}
+	}
+
+	tasks, err := c.store.List(fmt.Sprintf("/%s/%s/", v1alpha1.KindDevTask, project), func() interface{} {
+		return &v1alpha1.DevTask{}
+	})
+	if err != nil {
+		return 0, fmt.Errorf("listing tasks for pool %q: %w", pool.Metadata.Name, err)
+	}
+	for _, obj := range tasks {
+		task := obj.(*v1alpha1.DevTask)
+		if task.Status.Phase != v1alpha1.TaskPending || task.Spec.Pod != "" {
+			continue
+		}
+		if task.Spec.Pool == "" || task.Spec.Pool == pool.Metadata.Name {
+			workload++
+		}
+	}
+	return workload, nil
+}
+
+// setStatus stores the autoscaler's new status, computed for its current
+// generation, if it differs from the current one. The Manager does not
+// reconcile the autoscaler again for the write, see observedCurrent.
+func (c *AutoscalerController) setStatus(key string, as *v1alpha1.AgentPoolAutoscaler, status v1alpha1.AgentPoolAutoscalerStatus) error {
+	status.ObservedGeneration = as.Metadata.Generation
+	if status == as.Status {
+		return nil
+	}
+	as.Status = status
+	as.Metadata.UpdatedAt = time.Now()
+	if err := c.store.Update(key, as); err != nil {
+		return fmt.Errorf("updating autoscaler %q status: %w", as.Metadata.Name, err)
+	}
+	return nil
+}
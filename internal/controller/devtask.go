@@ -2,13 +2,19 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/events"
+	"github.com/klubi/orca/internal/policy"
 	"github.com/klubi/orca/internal/scheduler"
 	"github.com/klubi/orca/internal/store"
+	"github.com/klubi/orca/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -17,15 +23,17 @@ type DevTaskController struct {
 	store     store.Store
 	scheduler *scheduler.Scheduler
 	runtime   *agent.Runtime
+	recorder  *events.Recorder
 	logger    *zap.Logger
 }
 
 // NewDevTaskController creates a new DevTaskController.
-func NewDevTaskController(s store.Store, sched *scheduler.Scheduler, rt *agent.Runtime, logger *zap.Logger) *DevTaskController {
+func NewDevTaskController(s store.Store, sched *scheduler.Scheduler, rt *agent.Runtime, recorder *events.Recorder, logger *zap.Logger) *DevTaskController {
 	return &DevTaskController{
 		store:     s,
 		scheduler: sched,
 		runtime:   rt,
+		recorder:  recorder,
 		logger:    logger,
 	}
 }
@@ -33,9 +41,12 @@ func NewDevTaskController(s store.Store, sched *scheduler.Scheduler, rt *agent.R
 // Reconcile manages the task lifecycle:
 //
 //   - Pending:   Check dependencies, schedule if satisfied.
-//   - Scheduled: Launch runtime.ExecuteTask() in a goroutine.
+//   - Scheduled: Launch runtime.ExecuteTask() in a goroutine, unless the
+//                pod runs on a node, whose worker launches it.
 //   - Failed:    Retry if retries < maxRetries.
 //   - Succeeded/Running: No action needed.
+//
+// A task being deleted is torn down instead, whatever its phase.
 func (c *DevTaskController) Reconcile(ctx context.Context, key string) error {
 	// If we received an AgentPod event, check if any pending tasks can now be scheduled.
 	if strings.HasPrefix(key, "/"+v1alpha1.KindAgentPod+"/") {
@@ -57,6 +68,21 @@ func (c *DevTaskController) Reconcile(ctx context.Context, key string) error {
 		zap.String("phase", string(task.Status.Phase)),
 	)
 
+	// Continue the trace of the request that created the task, linked to
+	// the reconcile that got here.
+	ctx, span := tracer.Start(tracing.Extract(ctx, task.Metadata), "DevTaskController.reconcileTask",
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithAttributes(
+			attribute.String("orca.project", task.Metadata.Project),
+			attribute.String("orca.task", task.Metadata.Name),
+			attribute.String("orca.phase", string(task.Status.Phase)),
+		))
+	defer span.End()
+
+	if task.Metadata.DeletionTimestamp != nil {
+		return c.finalize(ctx, key, &task)
+	}
+
 	switch task.Status.Phase {
 	case v1alpha1.TaskPending:
 		return c.reconcilePending(ctx, key, &task)
@@ -114,20 +140,53 @@ func (c *DevTaskController) reconcilePending(ctx context.Context, key string, ta
 		)
 	}
 
+	// A project whose budget is spent runs no more tasks; raising the
+	// budget lets them be scheduled at the next retry.
+	project, err := policy.Project(c.store, task.Metadata.Project)
+	if err == nil {
+		err = policy.CheckBudget(c.store, project)
+	}
+	var violation *policy.Violation
+	if errors.As(err, &violation) {
+		if v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(
+			v1alpha1.ConditionSchedulable, v1alpha1.ConditionFalse, v1alpha1.ReasonBudgetExceeded, err.Error())) {
+			c.recorder.Warning(events.Ref(v1alpha1.KindDevTask, task.Metadata), "BudgetExceeded", "%v", err)
+			if err := c.store.Update(key, task); err != nil {
+				return fmt.Errorf("updating task %q conditions: %w", task.Metadata.Name, err)
+			}
+		}
+		return fmt.Errorf("scheduling task %q: %w", task.Metadata.Name, err)
+	} else if err != nil {
+		return err
+	}
+
 	// Schedule: find a suitable pod.
-	pod, err := c.scheduler.Schedule(task)
+	pod, err := c.scheduler.Schedule(ctx, task)
 	if err != nil {
 		c.logger.Warn("scheduling failed, will retry",
 			zap.String("task", task.Metadata.Name),
 			zap.Error(err),
 		)
+		c.recorder.Warning(events.Ref(v1alpha1.KindDevTask, task.Metadata), "FailedScheduling", "%v", err)
+		if v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(
+			v1alpha1.ConditionSchedulable, v1alpha1.ConditionFalse, v1alpha1.ReasonNoCapacity, err.Error())) {
+			if err := c.store.Update(key, task); err != nil {
+				return fmt.Errorf("updating task %q conditions: %w", task.Metadata.Name, err)
+			}
+		}
 		// Return error to trigger requeue with backoff.
 		return fmt.Errorf("scheduling task %q: %w", task.Metadata.Name, err)
 	}
 
+	if err := c.propagateLabels(task, pod); err != nil {
+		return err
+	}
+
 	// Transition to Scheduled.
 	task.Status.Phase = v1alpha1.TaskScheduled
 	task.Status.AssignedPod = pod.Metadata.Name
+	v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(v1alpha1.ConditionSchedulable,
+		v1alpha1.ConditionTrue, v1alpha1.ReasonScheduled, "assigned to pod "+pod.Metadata.Name))
 
 	if err := c.store.Update(key, task); err != nil {
 		return fmt.Errorf("updating task %q to Scheduled: %w", task.Metadata.Name, err)
@@ -137,7 +196,34 @@ func (c *DevTaskController) reconcilePending(ctx context.Context, key string, ta
 		zap.String("task", task.Metadata.Name),
 		zap.String("pod", pod.Metadata.Name),
 	)
+	c.recorder.Normal(events.Ref(v1alpha1.KindDevTask, task.Metadata), "Scheduled", "Assigned to pod %s", pod.Metadata.Name)
+
+	return nil
+}
 
+// propagateLabels adds to task the labels that the pool of pod, if it has
+// one, propagates and that task does not set itself.
+func (c *DevTaskController) propagateLabels(task *v1alpha1.DevTask, pod *v1alpha1.AgentPod) error {
+	if pod.OwnerPool() == "" {
+		return nil
+	}
+	var pool v1alpha1.AgentPool
+	poolKey := store.ResourceKey(v1alpha1.KindAgentPool, pod.Metadata.Project, pod.OwnerPool())
+	if err := c.store.Get(poolKey, &pool); err != nil {
+		if err == store.ErrNotFound {
+			return nil
+		}
+		return fmt.Errorf("getting pool %q of pod %q: %w", pod.OwnerPool(), pod.Metadata.Name, err)
+	}
+	for k, v := range pool.PropagatedLabels() {
+		if _, ok := task.Metadata.Labels[k]; ok {
+			continue
+		}
+		if task.Metadata.Labels == nil {
+			task.Metadata.Labels = make(map[string]string)
+		}
+		task.Metadata.Labels[k] = v
+	}
 	return nil
 }
 
@@ -153,6 +239,8 @@ func (c *DevTaskController) reconcileScheduled(ctx context.Context, key string,
 				zap.String("task", task.Metadata.Name),
 				zap.String("pod", task.Status.AssignedPod),
 			)
+			v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(v1alpha1.ConditionSchedulable,
+				v1alpha1.ConditionFalse, v1alpha1.ReasonPodNotFound, fmt.Sprintf("assigned pod %s not found", task.Status.AssignedPod)))
 			task.Status.Phase = v1alpha1.TaskPending
 			task.Status.AssignedPod = ""
 			return c.store.Update(key, task)
@@ -160,6 +248,11 @@ func (c *DevTaskController) reconcileScheduled(ctx context.Context, key string,
 		return fmt.Errorf("getting assigned pod %q: %w", task.Status.AssignedPod, err)
 	}
 
+	// The worker of the pod's node launches the task itself.
+	if pod.Spec.NodeName != "" {
+		return nil
+	}
+
 	c.logger.Info("launching task execution",
 		zap.String("task", task.Metadata.Name),
 		zap.String("pod", pod.Metadata.Name),
@@ -168,12 +261,16 @@ func (c *DevTaskController) reconcileScheduled(ctx context.Context, key string,
 	// Mark as Running immediately to prevent duplicate launches.
 	// Without this, a second reconcile could see the task still in Scheduled
 	// phase and launch another goroutine before the first one writes Running.
+	// The finalizer keeps the task until its execution has stopped.
 	task.Status.Phase = v1alpha1.TaskRunning
 	task.Status.AssignedPod = pod.Metadata.Name
+	task.Metadata.AddFinalizer(v1alpha1.FinalizerExecution)
 	if err := c.store.Update(key, task); err != nil {
 		return fmt.Errorf("marking task %q as Running: %w", task.Metadata.Name, err)
 	}
 
+	c.recorder.Normal(events.Ref(v1alpha1.KindDevTask, task.Metadata), "Started", "Started execution on pod %s", pod.Metadata.Name)
+
 	// Launch execution in a goroutine.
 	// The runtime handles remaining transitions:
 	//   Running -> Succeeded/Failed for the task
@@ -190,11 +287,50 @@ func (c *DevTaskController) reconcileScheduled(ctx context.Context, key string,
 	return nil
 }
 
+// finalize tears down a deleted task the runtime executed: it cancels the
+// execution, waits for it to stop, removes the task's artifacts and then
+// clears the execution finalizer, which removes the task. The tasks of a
+// node's pods are torn down by its worker, unless the pod or the node is
+// gone.
+func (c *DevTaskController) finalize(_ context.Context, key string, task *v1alpha1.DevTask) error {
+	if !task.Metadata.HasFinalizer(v1alpha1.FinalizerExecution) {
+		return nil
+	}
+	var pod v1alpha1.AgentPod
+	err := c.store.Get(store.ResourceKey(v1alpha1.KindAgentPod, task.Metadata.Project, task.Status.AssignedPod), &pod)
+	switch {
+	case err == nil && pod.Spec.NodeName != "":
+		if exists, err := nodeExists(c.store, pod.Spec.NodeName); err != nil || exists {
+			return err
+		}
+	case err != nil && err != store.ErrNotFound:
+		return fmt.Errorf("getting pod of task %q: %w", task.Metadata.Name, err)
+	}
+	if c.runtime.CancelTask(task.Metadata.Project, task.Metadata.Name) {
+		// The task is requeued until the execution has written its result,
+		// which it could not once the task is gone.
+		return fmt.Errorf("waiting for the execution of task %q to stop", task.Metadata.Name)
+	}
+
+	if err := c.runtime.RemoveArtifacts(task.Metadata.Project, task.Metadata.Name); err != nil {
+		c.logger.Warn("removing task artifacts",
+			zap.String("task", task.Metadata.Name),
+			zap.Error(err),
+		)
+	}
+	task.Metadata.RemoveFinalizer(v1alpha1.FinalizerExecution)
+	if err := c.store.Update(key, task); err != nil {
+		return fmt.Errorf("clearing finalizer of task %q: %w", task.Metadata.Name, err)
+	}
+	c.logger.Info("deleted task torn down", zap.String("task", task.Metadata.Name))
+	return nil
+}
+
 // reconcileFailed checks if the task can be retried.
 func (c *DevTaskController) reconcileFailed(_ context.Context, key string, task *v1alpha1.DevTask) error {
 	maxRetries := task.Spec.MaxRetries
-	if maxRetries <= 0 {
-		// No retries configured; leave as Failed.
+	if maxRetries <= 0 || task.Status.Cancelled {
+		// No retries configured, or cancelled on purpose; leave as Failed.
 		return nil
 	}
 
@@ -212,11 +348,14 @@ func (c *DevTaskController) reconcileFailed(_ context.Context, key string, task
 	task.Status.Retries++
 	task.Status.AssignedPod = ""
 	task.Status.Error = ""
+	v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(v1alpha1.ConditionComplete,
+		v1alpha1.ConditionFalse, v1alpha1.ReasonRetryPending, fmt.Sprintf("retry %d of %d", task.Status.Retries, maxRetries)))
 
 	if err := c.store.Update(key, task); err != nil {
 		return fmt.Errorf("resetting task %q for retry: %w", task.Metadata.Name, err)
 	}
 
+	c.recorder.Normal(events.Ref(v1alpha1.KindDevTask, task.Metadata), "Retrying", "Retry %d of %d", task.Status.Retries, maxRetries)
 	c.logger.Info("task reset for retry",
 		zap.String("task", task.Metadata.Name),
 		zap.Int("retry", task.Status.Retries),
@@ -241,7 +380,7 @@ func (c *DevTaskController) reconcileFromPodEvent(ctx context.Context, podKey st
 	if err := c.store.Get(podKey, &pod); err != nil {
 		return nil // Pod gone, nothing to do.
 	}
-	if pod.Status.Phase != v1alpha1.PodReady {
+	if pod.Status.Phase != v1alpha1.PodReady || pod.Metadata.DeletionTimestamp != nil {
 		return nil // Pod not ready, no point scheduling.
 	}
 
@@ -252,12 +391,16 @@ func (c *DevTaskController) reconcileFromPodEvent(ctx context.Context, podKey st
 		return nil
 	}
 
-	// For each pending task, try to reconcile it.
+	var pending []*v1alpha1.DevTask
 	for _, obj := range objects {
-		task, ok := obj.(*v1alpha1.DevTask)
-		if !ok || task.Status.Phase != v1alpha1.TaskPending {
-			continue
+		if task, ok := obj.(*v1alpha1.DevTask); ok && task.Status.Phase == v1alpha1.TaskPending && task.Metadata.DeletionTimestamp == nil {
+			pending = append(pending, task)
 		}
+	}
+	// Try the pending tasks in priority order, so the most important ones
+	// get the pod.
+	scheduler.SortByPriority(pending)
+	for _, task := range pending {
 		taskKey := store.ResourceKey(v1alpha1.KindDevTask, project, task.Metadata.Name)
 		if err := c.reconcilePending(ctx, taskKey, task); err != nil {
 			c.logger.Debug("pending task not yet schedulable",
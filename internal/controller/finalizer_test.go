@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// executing starts the execution of a task on a pod whose model provider
+// answers only once ctx is done, and returns the runtime, the pod, the
+// task and a channel closed when the execution has returned.
+func executing(t *testing.T, st store.Store) (*agent.Runtime, *v1alpha1.AgentPod, *v1alpha1.DevTask, <-chan struct{}) {
+	t.Helper()
+	called, stop := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(called) })
+		select {
+		case <-r.Context().Done():
+		case <-stop:
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(stop) })
+
+	cfg := config.DefaultConfig()
+	cfg.Store.DataDir = t.TempDir()
+	executor := agent.NewExecutor(cfg.Agent.ClaudeCLI, zap.NewNop())
+	t.Cleanup(func() { executor.Close() })
+	rt := agent.NewRuntime(st, executor, cfg, zap.NewNop())
+
+	mustCreate(t, st, v1alpha1.KindModelProvider, "slow", &v1alpha1.ModelProvider{
+		Metadata: v1alpha1.ObjectMeta{Name: "slow", Project: "web"},
+		Spec:     v1alpha1.ModelProviderSpec{Type: v1alpha1.ProviderOllama, Endpoint: srv.URL},
+	})
+	pod := &v1alpha1.AgentPod{
+		Metadata: v1alpha1.ObjectMeta{Name: "worker", Project: "web", Finalizers: []string{v1alpha1.FinalizerRuntime}},
+		Spec:     v1alpha1.AgentPodSpec{Model: "llama3", Provider: "slow"},
+		Status:   v1alpha1.AgentPodStatus{Phase: v1alpha1.PodReady},
+	}
+	mustCreate(t, st, v1alpha1.KindAgentPod, "worker", pod)
+	task := &v1alpha1.DevTask{
+		Metadata: v1alpha1.ObjectMeta{Name: "fix", Project: "web", Finalizers: []string{v1alpha1.FinalizerExecution}},
+		Spec:     v1alpha1.DevTaskSpec{Prompt: "fix it"},
+		Status:   v1alpha1.DevTaskStatus{Phase: v1alpha1.TaskRunning, AssignedPod: "worker"},
+	}
+	mustCreate(t, st, v1alpha1.KindDevTask, "fix", task)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rt.ExecuteTask(context.Background(), task, pod)
+	}()
+	select {
+	case <-called:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the execution did not call its provider")
+	}
+	return rt, pod, task, done
+}
+
+// awaitDone waits for the execution to return after it was cancelled.
+func awaitDone(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the cancelled execution did not return")
+	}
+}
+
+func TestDevTaskFinalizeWaitsForExecution(t *testing.T) {
+	st := store.NewMemoryStore()
+	rt, _, _, done := executing(t, st)
+	c := NewDevTaskController(st, nil, rt, nil, zap.NewNop())
+	key := store.ResourceKey(v1alpha1.KindDevTask, "web", "fix")
+	if err := os.MkdirAll(rt.ArtifactDir("web", "fix"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := st.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reconcile(context.Background(), key); err == nil {
+		t.Error("finalizing a task being executed succeeded, want it requeued until the execution stops")
+	}
+	var task v1alpha1.DevTask
+	if err := st.Get(key, &task); err != nil || !task.Metadata.HasFinalizer(v1alpha1.FinalizerExecution) {
+		t.Fatalf("task being executed: %v, finalizers %v, want it kept with its finalizer", err, task.Metadata.Finalizers)
+	}
+
+	awaitDone(t, done)
+	if err := st.Get(key, &task); err != nil || !task.Status.Cancelled {
+		t.Fatalf("cancelled task: %v, status %+v, want its result written", err, task.Status)
+	}
+	if err := c.Reconcile(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	if exists(t, st, key) {
+		t.Error("task kept after its execution stopped")
+	}
+	if _, err := os.Stat(rt.ArtifactDir("web", "fix")); !os.IsNotExist(err) {
+		t.Errorf("artifacts of the deleted task: %v, want them removed", err)
+	}
+}
+
+func TestHealthCheckFinalizeStopsPodAfterItsTasks(t *testing.T) {
+	st := store.NewMemoryStore()
+	rt, _, _, done := executing(t, st)
+	c := NewHealthCheckController(st, rt, nil, time.Minute, zap.NewNop())
+	key := store.ResourceKey(v1alpha1.KindAgentPod, "web", "worker")
+
+	if err := st.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reconcile(context.Background(), key); err == nil {
+		t.Error("finalizing a pod with a task being executed succeeded, want it requeued until the task stops")
+	}
+	var pod v1alpha1.AgentPod
+	if err := st.Get(key, &pod); err != nil || !pod.Metadata.HasFinalizer(v1alpha1.FinalizerRuntime) {
+		t.Fatalf("pod with a task being executed: %v, finalizers %v, want it kept with its finalizer", err, pod.Metadata.Finalizers)
+	}
+
+	awaitDone(t, done)
+	if err := c.Reconcile(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	if exists(t, st, key) {
+		t.Error("pod kept after it was stopped")
+	}
+}
+
+func TestFinalizeLeavesNodeWorkloadsToTheirWorker(t *testing.T) {
+	st := store.NewMemoryStore()
+	cfg := config.DefaultConfig()
+	cfg.Store.DataDir = t.TempDir()
+	executor := agent.NewExecutor(cfg.Agent.ClaudeCLI, zap.NewNop())
+	defer executor.Close()
+	rt := agent.NewRuntime(st, executor, cfg, zap.NewNop())
+	pods := NewHealthCheckController(st, rt, nil, time.Minute, zap.NewNop())
+	tasks := NewDevTaskController(st, nil, rt, nil, zap.NewNop())
+
+	nodeKey := store.ResourceKey(v1alpha1.KindAgentNode, "", "gpu-1")
+	if err := st.Create(nodeKey, &v1alpha1.AgentNode{Metadata: v1alpha1.ObjectMeta{Name: "gpu-1"}}); err != nil {
+		t.Fatal(err)
+	}
+	podKey := mustCreate(t, st, v1alpha1.KindAgentPod, "remote", &v1alpha1.AgentPod{
+		Metadata: v1alpha1.ObjectMeta{Name: "remote", Project: "web", Finalizers: []string{v1alpha1.FinalizerRuntime}},
+		Spec:     v1alpha1.AgentPodSpec{Model: "claude-sonnet", NodeName: "gpu-1"},
+		Status:   v1alpha1.AgentPodStatus{Phase: v1alpha1.PodReady},
+	})
+	taskKey := mustCreate(t, st, v1alpha1.KindDevTask, "fix", &v1alpha1.DevTask{
+		Metadata: v1alpha1.ObjectMeta{Name: "fix", Project: "web", Finalizers: []string{v1alpha1.FinalizerExecution}},
+		Spec:     v1alpha1.DevTaskSpec{Prompt: "fix it"},
+		Status:   v1alpha1.DevTaskStatus{Phase: v1alpha1.TaskRunning, AssignedPod: "remote"},
+	})
+	for _, key := range []string{taskKey, podKey} {
+		if err := st.Delete(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reconcile := func() {
+		t.Helper()
+		if err := tasks.Reconcile(context.Background(), taskKey); err != nil {
+			t.Fatal(err)
+		}
+		if err := pods.Reconcile(context.Background(), podKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reconcile()
+	if !exists(t, st, taskKey) || !exists(t, st, podKey) {
+		t.Fatal("the controllers removed the workloads of a node whose worker tears them down")
+	}
+
+	// Nothing can tear down the workloads of a node that is gone.
+	if err := st.Delete(nodeKey); err != nil {
+		t.Fatal(err)
+	}
+	reconcile()
+	if exists(t, st, taskKey) || exists(t, st, podKey) {
+		t.Error("the workloads of a missing node were kept")
+	}
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/klubi/orca/internal/events"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"go.uber.org/zap"
+)
+
+// dependentKinds are the kinds whose objects may have owner references,
+// with a factory for each.
+var dependentKinds = map[string]func() v1alpha1.Object{
+	v1alpha1.KindAgentPod: func() v1alpha1.Object { return &v1alpha1.AgentPod{} },
+}
+
+// GarbageCollector deletes the objects whose controller, named by their
+// owner references, is gone: the pods of a deleted AgentPool, whatever
+// phase they were left in.
+type GarbageCollector struct {
+	store    store.Store
+	recorder *events.Recorder
+	logger   *zap.Logger
+}
+
+// NewGarbageCollector creates a new GarbageCollector.
+func NewGarbageCollector(s store.Store, recorder *events.Recorder, logger *zap.Logger) *GarbageCollector {
+	return &GarbageCollector{
+		store:    s,
+		recorder: recorder,
+		logger:   logger,
+	}
+}
+
+// Reconcile collects the garbage a change of key may have made:
+//
+//   - A dependent (an object of one of the dependentKinds) is deleted if its
+//     controller does not exist, or is a later object of the same name.
+//   - For an owner, every dependent in its project that names it as its
+//     controller is checked the same way.
+//
+// A deleted dependent with finalizers is removed once they are cleared.
+func (c *GarbageCollector) Reconcile(ctx context.Context, key string) error {
+	parts := strings.SplitN(strings.TrimPrefix(key, "/"), "/", 3)
+	if len(parts) < 3 {
+		return nil
+	}
+	kind, project, name := parts[0], parts[1], parts[2]
+
+	if newObj, ok := dependentKinds[kind]; ok {
+		obj := newObj()
+		if err := c.store.Get(key, obj); err != nil {
+			if err == store.ErrNotFound {
+				return nil
+			}
+			return fmt.Errorf("getting %q: %w", key, err)
+		}
+		return c.collect(kind, obj.GetObjectMeta())
+	}
+
+	for dependentKind, newObj := range dependentKinds {
+		prefix := fmt.Sprintf("/%s/%s/", dependentKind, project)
+		objects, err := c.store.List(prefix, func() interface{} { return newObj() })
+		if err != nil {
+			return fmt.Errorf("listing dependents of %q: %w", key, err)
+		}
+		for _, obj := range objects {
+			meta := obj.(v1alpha1.Object).GetObjectMeta()
+			if ref := meta.ControllerRef(); ref == nil || ref.Kind != kind || ref.Name != name {
+				continue
+			}
+			if err := c.collect(dependentKind, meta); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collect deletes the object of kind with metadata meta if its controller
+// is gone.
+func (c *GarbageCollector) collect(kind string, meta *v1alpha1.ObjectMeta) error {
+	ref := meta.ControllerRef()
+	if ref == nil || meta.DeletionTimestamp != nil {
+		return nil
+	}
+
+	var owner struct {
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+	}
+	err := c.store.Get(store.ResourceKey(ref.Kind, meta.Project, ref.Name), &owner)
+	switch {
+	case err == store.ErrNotFound:
+	case err != nil:
+		return fmt.Errorf("getting %s %q: %w", ref.Kind, ref.Name, err)
+	case ref.UID == "" || ref.UID == owner.Metadata.UID:
+		return nil // The controller exists.
+	}
+
+	key := store.ResourceKey(kind, meta.Project, meta.Name)
+	if err := c.store.Delete(key); err != nil && err != store.ErrNotFound {
+		return fmt.Errorf("deleting orphaned %s %q: %w", kind, meta.Name, err)
+	}
+	c.logger.Info("deleted orphaned object",
+		zap.String("kind", kind),
+		zap.String("name", meta.Name),
+		zap.String("project", meta.Project),
+		zap.String("owner", ref.Kind+"/"+ref.Name),
+	)
+	c.recorder.Normal(events.Ref(kind, *meta), "GarbageCollected", "Deleted as its %s %s is gone", ref.Kind, ref.Name)
+	return nil
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func testPool(name, uid string) *v1alpha1.AgentPool {
+	return &v1alpha1.AgentPool{Metadata: v1alpha1.ObjectMeta{Name: name, Project: "web", UID: uid}}
+}
+
+// ownedPod returns a pod whose controller is the pool owner.
+func ownedPod(name string, owner *v1alpha1.AgentPool) *v1alpha1.AgentPod {
+	pod := &v1alpha1.AgentPod{
+		Metadata: v1alpha1.ObjectMeta{Name: name, Project: "web"},
+		Spec:     v1alpha1.AgentPodSpec{Model: "claude-sonnet"},
+	}
+	pod.Metadata.OwnerReferences = []v1alpha1.OwnerReference{v1alpha1.NewControllerRef(v1alpha1.KindAgentPool, &owner.Metadata)}
+	return pod
+}
+
+func mustCreate(t *testing.T, st store.Store, kind, name string, obj interface{}) string {
+	t.Helper()
+	key := store.ResourceKey(kind, "web", name)
+	if err := st.Create(key, obj); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func exists(t *testing.T, st store.Store, key string) bool {
+	t.Helper()
+	var doc map[string]interface{}
+	err := st.Get(key, &doc)
+	if err != nil && err != store.ErrNotFound {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+func TestGarbageCollectorDeletesPodsOfDeletedPool(t *testing.T) {
+	st := store.NewMemoryStore()
+	pool := testPool("reviewers", "uid-1")
+	poolKey := mustCreate(t, st, v1alpha1.KindAgentPool, "reviewers", pool)
+	owned := []string{
+		mustCreate(t, st, v1alpha1.KindAgentPod, "reviewers-a", ownedPod("reviewers-a", pool)),
+		mustCreate(t, st, v1alpha1.KindAgentPod, "reviewers-b", ownedPod("reviewers-b", pool)),
+	}
+	other := mustCreate(t, st, v1alpha1.KindAgentPod, "other", ownedPod("other", testPool("other", "uid-2")))
+	standalone := mustCreate(t, st, v1alpha1.KindAgentPod, "standalone", &v1alpha1.AgentPod{
+		Metadata: v1alpha1.ObjectMeta{Name: "standalone", Project: "web"},
+	})
+	gc := NewGarbageCollector(st, nil, zap.NewNop())
+
+	// The pods of an existing pool are kept.
+	if err := gc.Reconcile(context.Background(), poolKey); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range owned {
+		if !exists(t, st, key) {
+			t.Fatalf("%s was collected while its pool exists", key)
+		}
+	}
+
+	if err := st.Delete(poolKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := gc.Reconcile(context.Background(), poolKey); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range owned {
+		if exists(t, st, key) {
+			t.Errorf("%s was kept after its pool was deleted", key)
+		}
+	}
+	// The collector does not look beyond the deleted pool's dependents.
+	for _, key := range []string{other, standalone} {
+		if !exists(t, st, key) {
+			t.Errorf("%s was collected with another pool", key)
+		}
+	}
+}
+
+func TestGarbageCollectorTreatsRecreatedOwnerAsGone(t *testing.T) {
+	st := store.NewMemoryStore()
+	old := testPool("reviewers", "uid-old")
+	stale := mustCreate(t, st, v1alpha1.KindAgentPod, "reviewers-a", ownedPod("reviewers-a", old))
+	current := testPool("reviewers", "uid-new")
+	mustCreate(t, st, v1alpha1.KindAgentPool, "reviewers", current)
+	kept := mustCreate(t, st, v1alpha1.KindAgentPod, "reviewers-b", ownedPod("reviewers-b", current))
+	gc := NewGarbageCollector(st, nil, zap.NewNop())
+
+	for _, key := range []string{stale, kept} {
+		if err := gc.Reconcile(context.Background(), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if exists(t, st, stale) {
+		t.Error("a pod of an earlier pool of the same name was kept")
+	}
+	if !exists(t, st, kept) {
+		t.Error("a pod of the current pool was collected")
+	}
+}
+
+func TestGarbageCollectorKeepsLegacyOwnedPods(t *testing.T) {
+	st := store.NewMemoryStore()
+	mustCreate(t, st, v1alpha1.KindAgentPool, "reviewers", testPool("reviewers", "uid-1"))
+	// A pod stored before owner references, naming its pool in the spec.
+	legacy := mustCreate(t, st, v1alpha1.KindAgentPod, "reviewers-a", map[string]interface{}{
+		"kind":     v1alpha1.KindAgentPod,
+		"metadata": map[string]interface{}{"name": "reviewers-a", "project": "web"},
+		"spec":     map[string]interface{}{"model": "claude-sonnet", "ownerPool": "reviewers"},
+	})
+	gc := NewGarbageCollector(st, nil, zap.NewNop())
+
+	if err := gc.Reconcile(context.Background(), legacy); err != nil {
+		t.Fatal(err)
+	}
+	var pod v1alpha1.AgentPod
+	if err := st.Get(legacy, &pod); err != nil {
+		t.Fatalf("a legacy pod of an existing pool was collected: %v", err)
+	}
+	if ref := pod.Metadata.ControllerRef(); ref == nil || ref.Kind != v1alpha1.KindAgentPool || ref.Name != "reviewers" || pod.OwnerPool() != "reviewers" {
+		t.Errorf("legacy pod has controller %+v, want AgentPool reviewers", ref)
+	}
+}
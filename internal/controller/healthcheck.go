@@ -3,32 +3,43 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/events"
 	"github.com/klubi/orca/internal/store"
 	"go.uber.org/zap"
 )
 
-// HealthCheckController monitors agent pod health via heartbeats.
+// HealthCheckController monitors agent pod health via heartbeats, and
+// tears down the pods the runtime started when they are deleted.
 type HealthCheckController struct {
 	store    store.Store
 	runtime  *agent.Runtime
-	interval time.Duration
+	recorder *events.Recorder
+	interval atomic.Int64 // time.Duration
 	logger   *zap.Logger
 }
 
 // NewHealthCheckController creates a new HealthCheckController.
 // The interval defines the expected heartbeat frequency. A pod is considered
 // unhealthy if its last heartbeat is older than 3x the interval.
-func NewHealthCheckController(s store.Store, rt *agent.Runtime, interval time.Duration, logger *zap.Logger) *HealthCheckController {
-	return &HealthCheckController{
+func NewHealthCheckController(s store.Store, rt *agent.Runtime, recorder *events.Recorder, interval time.Duration, logger *zap.Logger) *HealthCheckController {
+	c := &HealthCheckController{
 		store:    s,
 		runtime:  rt,
-		interval: interval,
+		recorder: recorder,
 		logger:   logger,
 	}
+	c.SetInterval(interval)
+	return c
+}
+
+// SetInterval changes the expected heartbeat frequency.
+func (c *HealthCheckController) SetInterval(interval time.Duration) {
+	c.interval.Store(int64(interval))
 }
 
 // Reconcile checks pod health:
@@ -37,8 +48,10 @@ func NewHealthCheckController(s store.Store, rt *agent.Runtime, interval time.Du
 //  2. If pod is Ready or Busy:
 //     - Check LastHeartbeat. If older than 3x interval, mark as Failed.
 //     - Otherwise, pod is healthy.
-//  3. If pod is Failed and RestartPolicy is "Always":
+//  3. If pod is Failed and RestartPolicy is "Always" or "OnFailure":
 //     - Reset to Pending for restart.
+//
+// A pod being deleted is torn down instead, whatever its phase.
 func (c *HealthCheckController) Reconcile(ctx context.Context, key string) error {
 	var pod v1alpha1.AgentPod
 	if err := c.store.Get(key, &pod); err != nil {
@@ -49,6 +62,10 @@ func (c *HealthCheckController) Reconcile(ctx context.Context, key string) error
 		return fmt.Errorf("getting pod %q: %w", key, err)
 	}
 
+	if pod.Metadata.DeletionTimestamp != nil {
+		return c.finalize(ctx, key, &pod)
+	}
+
 	c.logger.Debug("health check",
 		zap.String("pod", pod.Metadata.Name),
 		zap.String("phase", string(pod.Status.Phase)),
@@ -70,7 +87,7 @@ func (c *HealthCheckController) Reconcile(ctx context.Context, key string) error
 // checkHeartbeat verifies the pod's last heartbeat is within the acceptable threshold.
 // If the heartbeat is older than 3x the configured interval, the pod is marked as Failed.
 func (c *HealthCheckController) checkHeartbeat(key string, pod *v1alpha1.AgentPod) error {
-	threshold := 3 * c.interval
+	threshold := 3 * time.Duration(c.interval.Load())
 	deadline := time.Now().Add(-threshold)
 
 	if pod.Status.LastHeartbeat.IsZero() {
@@ -105,6 +122,8 @@ func (c *HealthCheckController) checkHeartbeat(key string, pod *v1alpha1.AgentPo
 func (c *HealthCheckController) markFailed(key string, pod *v1alpha1.AgentPod, message string) error {
 	pod.Status.Phase = v1alpha1.PodFailed
 	pod.Status.Message = message
+	v1alpha1.SetCondition(&pod.Status.Conditions, v1alpha1.NewCondition(
+		v1alpha1.ConditionReady, v1alpha1.ConditionFalse, v1alpha1.ReasonHeartbeatTimeout, message))
 	pod.Metadata.UpdatedAt = time.Now()
 
 	if err := c.store.Update(key, pod); err != nil {
@@ -115,14 +134,17 @@ func (c *HealthCheckController) markFailed(key string, pod *v1alpha1.AgentPod, m
 		zap.String("pod", pod.Metadata.Name),
 		zap.String("reason", message),
 	)
+	c.recorder.Warning(events.Ref(v1alpha1.KindAgentPod, pod.Metadata), "HeartbeatFailed", "%s", message)
 
 	return nil
 }
 
-// checkRestart resets a Failed pod to Pending if its RestartPolicy is "Always".
+// checkRestart resets a Failed pod to Pending if its RestartPolicy is
+// "Always" or "OnFailure". A pod only stops otherwise by being deleted, so
+// the two policies restart the same pods.
 func (c *HealthCheckController) checkRestart(key string, pod *v1alpha1.AgentPod) error {
-	if pod.Spec.RestartPolicy != "Always" {
-		c.logger.Debug("pod failed but restart policy is not Always",
+	if pod.Spec.RestartPolicy != "Always" && pod.Spec.RestartPolicy != "OnFailure" {
+		c.logger.Debug("pod failed but its restart policy does not restart it",
 			zap.String("pod", pod.Metadata.Name),
 			zap.String("restartPolicy", pod.Spec.RestartPolicy),
 		)
@@ -135,12 +157,89 @@ func (c *HealthCheckController) checkRestart(key string, pod *v1alpha1.AgentPod)
 
 	pod.Status.Phase = v1alpha1.PodPending
 	pod.Status.Message = "restarting after failure"
+	v1alpha1.SetCondition(&pod.Status.Conditions, v1alpha1.NewCondition(
+		v1alpha1.ConditionReady, v1alpha1.ConditionFalse, v1alpha1.ReasonRestarting, pod.Status.Message))
 	pod.Status.ActiveTasks = 0
 	pod.Metadata.UpdatedAt = time.Now()
 
 	if err := c.store.Update(key, pod); err != nil {
 		return fmt.Errorf("resetting pod %q to Pending: %w", pod.Metadata.Name, err)
 	}
+	c.recorder.Normal(events.Ref(v1alpha1.KindAgentPod, pod.Metadata), "Restarting", "Restarting pod after failure (restartPolicy %s)", pod.Spec.RestartPolicy)
+
+	return nil
+}
+
+// finalize tears down a deleted pod the runtime started: it cancels the
+// tasks running on the pod, waits for them to stop, stops the pod and then
+// clears the runtime finalizer, which removes the pod. The pods of a node
+// are torn down by its worker, unless the node is gone.
+func (c *HealthCheckController) finalize(ctx context.Context, key string, pod *v1alpha1.AgentPod) error {
+	if !pod.Metadata.HasFinalizer(v1alpha1.FinalizerRuntime) {
+		return nil
+	}
+	if pod.Spec.NodeName != "" {
+		if exists, err := nodeExists(c.store, pod.Spec.NodeName); err != nil || exists {
+			return err
+		}
+		pod.Metadata.RemoveFinalizer(v1alpha1.FinalizerRuntime)
+		if err := c.store.Update(key, pod); err != nil {
+			return fmt.Errorf("clearing finalizer of pod %q: %w", pod.Metadata.Name, err)
+		}
+		c.logger.Info("deleted pod of a missing node removed",
+			zap.String("pod", pod.Metadata.Name),
+			zap.String("node", pod.Spec.NodeName),
+		)
+		return nil
+	}
+
+	prefix := "/" + v1alpha1.KindDevTask + "/" + pod.Metadata.Project + "/"
+	tasks, err := c.store.List(prefix, func() interface{} { return &v1alpha1.DevTask{} })
+	if err != nil {
+		return fmt.Errorf("listing tasks of pod %q: %w", pod.Metadata.Name, err)
+	}
+	running := 0
+	for _, obj := range tasks {
+		task := obj.(*v1alpha1.DevTask)
+		if task.Status.AssignedPod == pod.Metadata.Name && c.runtime.CancelTask(task.Metadata.Project, task.Metadata.Name) {
+			running++
+		}
+	}
+	if running > 0 {
+		// The pod is requeued until the executions have written their
+		// results, which they could not once it is gone.
+		return fmt.Errorf("waiting for %d tasks on pod %q to stop", running, pod.Metadata.Name)
+	}
 
+	if err := c.runtime.StopPod(ctx, pod.Metadata.Name, pod.Metadata.Project); err != nil {
+		return fmt.Errorf("stopping pod %q: %w", pod.Metadata.Name, err)
+	}
+
+	// StopPod has written the pod since it was read.
+	var stopped v1alpha1.AgentPod
+	if err := c.store.Get(key, &stopped); err != nil {
+		return fmt.Errorf("getting pod %q: %w", pod.Metadata.Name, err)
+	}
+	stopped.Metadata.RemoveFinalizer(v1alpha1.FinalizerRuntime)
+	if err := c.store.Update(key, &stopped); err != nil {
+		return fmt.Errorf("clearing finalizer of pod %q: %w", pod.Metadata.Name, err)
+	}
+
+	c.logger.Info("deleted pod stopped", zap.String("pod", pod.Metadata.Name))
+	c.recorder.Normal(events.Ref(v1alpha1.KindAgentPod, pod.Metadata), "Stopped", "Stopped deleted pod")
 	return nil
 }
+
+// nodeExists reports whether the AgentNode called name exists, so that its
+// worker tears down the workloads assigned to it.
+func nodeExists(s store.Store, name string) (bool, error) {
+	var node v1alpha1.AgentNode
+	err := s.Get(store.ResourceKey(v1alpha1.KindAgentNode, "", name), &node)
+	switch {
+	case err == store.ErrNotFound:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("getting node %q: %w", name, err)
+	}
+	return true, nil
+}
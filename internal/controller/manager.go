@@ -3,15 +3,22 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
 
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 	"github.com/klubi/orca/internal/store"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("github.com/klubi/orca/internal/controller")
+
 // Reconciler processes a single resource key.
 type Reconciler interface {
 	Reconcile(ctx context.Context, key string) error
@@ -24,6 +31,12 @@ type workItem struct {
 	nextRetry time.Time
 }
 
+// keyPriority is the store key of an object and its spec.priority.
+type keyPriority struct {
+	key      string
+	priority int
+}
+
 const (
 	initialBackoff = 1 * time.Second
 	maxBackoff     = 60 * time.Second
@@ -31,12 +44,15 @@ const (
 
 // WorkQueue is a rate-limited work queue with exponential backoff.
 // It uses the K8s pattern of dirty/processing sets to ensure no events
-// are lost while an item is being processed.
+// are lost while an item is being processed. Of the items that are ready,
+// Get returns the one with the highest priority first.
 type WorkQueue struct {
 	mu         sync.Mutex
 	items      []workItem
-	dirty      map[string]bool // items queued or needing re-queue
-	processing map[string]bool // items currently being processed
+	dirty      map[string]bool   // items queued or needing re-queue
+	processing map[string]bool   // items currently being processed
+	priorities map[string]int    // priorities of items, 0 when absent
+	requestIDs map[string]string // API requests that last changed items
 	notify     chan struct{}
 	closed     bool
 }
@@ -46,6 +62,8 @@ func NewWorkQueue() *WorkQueue {
 	return &WorkQueue{
 		dirty:      make(map[string]bool),
 		processing: make(map[string]bool),
+		priorities: make(map[string]int),
+		requestIDs: make(map[string]string),
 		notify:     make(chan struct{}, 1),
 	}
 }
@@ -55,7 +73,40 @@ func NewWorkQueue() *WorkQueue {
 func (q *WorkQueue) Add(key string) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	q.add(key)
+}
+
+// AddWithPriority enqueues an item as Add does and sets its priority,
+// which it keeps until it is done.
+func (q *WorkQueue) AddWithPriority(key string, priority int) {
+	q.AddForRequest(key, priority, "")
+}
+
+// AddForRequest enqueues an item as AddWithPriority does, recording that
+// the API request with requestID changed it, until it is done.
+func (q *WorkQueue) AddForRequest(key string, priority int, requestID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if requestID != "" {
+		q.requestIDs[key] = requestID
+	}
+	if priority == 0 {
+		delete(q.priorities, key)
+	} else {
+		q.priorities[key] = priority
+	}
+	q.add(key)
+}
+
+// RequestID returns the ID of the last API request that changed the item,
+// or "" if none did since it was last done.
+func (q *WorkQueue) RequestID(key string) string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.requestIDs[key]
+}
 
+func (q *WorkQueue) add(key string) {
 	if q.closed {
 		return
 	}
@@ -98,19 +149,27 @@ func (q *WorkQueue) Get() (string, bool) {
 			return "", false
 		}
 
-		// Find the first item whose nextRetry has passed.
+		// Find the first item of the highest priority whose nextRetry has
+		// passed.
 		now := time.Now()
+		next := -1
 		for i, item := range q.items {
-			if now.After(item.nextRetry) || now.Equal(item.nextRetry) {
-				key := item.key
-				// Remove from the items slice.
-				q.items = append(q.items[:i], q.items[i+1:]...)
-				// Mark as processing.
-				q.processing[key] = true
-				q.mu.Unlock()
-				return key, true
+			if item.nextRetry.After(now) {
+				continue
+			}
+			if next < 0 || q.priorities[item.key] > q.priorities[q.items[next].key] {
+				next = i
 			}
 		}
+		if next >= 0 {
+			key := q.items[next].key
+			// Remove from the items slice.
+			q.items = append(q.items[:next], q.items[next+1:]...)
+			// Mark as processing.
+			q.processing[key] = true
+			q.mu.Unlock()
+			return key, true
+		}
 
 		// If there are items but none ready, calculate the shortest wait.
 		var sleepDuration time.Duration
@@ -152,8 +211,9 @@ func (q *WorkQueue) Done(key string) {
 
 	delete(q.processing, key)
 
-	// If the key was re-dirtied while processing, re-add it to the queue.
-	if q.dirty[key] {
+	// If the key was re-dirtied while processing, re-add it to the queue,
+	// unless the queue has been closed meanwhile.
+	if q.dirty[key] && !q.closed {
 		delete(q.dirty, key)
 		// Re-add as a fresh item.
 		q.dirty[key] = true
@@ -166,6 +226,9 @@ func (q *WorkQueue) Done(key string) {
 		case q.notify <- struct{}{}:
 		default:
 		}
+	} else {
+		delete(q.priorities, key)
+		delete(q.requestIDs, key)
 	}
 }
 
@@ -283,6 +346,16 @@ func (m *Manager) Start(ctx context.Context) error {
 
 			// Feed watch events into the controller's work queue.
 			go m.watchLoop(cCtx, name, eventCh, cancelWatch, cr.queue)
+
+			// Enqueue objects that already exist so state persisted before a
+			// restart gets reconciled even if it never changes again.
+			keys, err := m.listKeys(kind)
+			if err != nil {
+				return fmt.Errorf("initial list of %s for %s: %w", kind, name, err)
+			}
+			for _, k := range keys {
+				cr.queue.AddWithPriority(k.key, k.priority)
+			}
 		}
 
 		// Start the worker goroutine.
@@ -292,6 +365,30 @@ func (m *Manager) Start(ctx context.Context) error {
 	return nil
 }
 
+// listKeys returns the store keys and priorities of every existing object
+// of kind.
+func (m *Manager) listKeys(kind string) ([]keyPriority, error) {
+	type metaOnly struct {
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+		Spec     struct {
+			Priority int `json:"priority"`
+		} `json:"spec"`
+	}
+	objects, err := m.store.List(fmt.Sprintf("/%s/", kind), func() interface{} { return &metaOnly{} })
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]keyPriority, 0, len(objects))
+	for _, obj := range objects {
+		o := obj.(*metaOnly)
+		keys = append(keys, keyPriority{
+			key:      store.ResourceKey(kind, o.Metadata.Project, o.Metadata.Name),
+			priority: o.Spec.Priority,
+		})
+	}
+	return keys, nil
+}
+
 // watchLoop reads events from a store watch channel and feeds them into the work queue.
 func (m *Manager) watchLoop(ctx context.Context, controllerName string, eventCh <-chan v1alpha1.WatchEvent, cancelWatch func(), queue *WorkQueue) {
 	defer cancelWatch()
@@ -304,17 +401,56 @@ func (m *Manager) watchLoop(ctx context.Context, controllerName string, eventCh
 			if !ok {
 				return
 			}
+			if event.Type == v1alpha1.EventModified && observedCurrent(event.Object) {
+				continue
+			}
 			m.logger.Debug("watch event received",
 				zap.String("controller", controllerName),
 				zap.String("type", string(event.Type)),
 				zap.String("kind", event.Kind),
 				zap.String("key", event.Key),
+				zap.String("requestID", event.RequestID),
 			)
-			queue.Add(event.Key)
+			queue.AddForRequest(event.Key, specPriority(event.Object), event.RequestID)
 		}
 	}
 }
 
+// observedCurrent reports whether obj, a modified object, has a status
+// whose observedGeneration is its current generation. Such a write is a
+// controller recording the status of a spec it has already reconciled, so
+// reconciling again would be a no-op. Kinds without observedGeneration are
+// always reconciled.
+func observedCurrent(obj interface{}) bool {
+	var o struct {
+		Metadata struct {
+			Generation int64 `json:"generation"`
+		} `json:"metadata"`
+		Status struct {
+			ObservedGeneration int64 `json:"observedGeneration"`
+		} `json:"status"`
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil || json.Unmarshal(raw, &o) != nil {
+		return false
+	}
+	return o.Metadata.Generation > 0 && o.Status.ObservedGeneration == o.Metadata.Generation
+}
+
+// specPriority returns the spec.priority of obj, 0 for kinds without one.
+func specPriority(obj interface{}) int {
+	var o struct {
+		Spec struct {
+			Priority int `json:"priority"`
+		} `json:"spec"`
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil || json.Unmarshal(raw, &o) != nil {
+		return 0
+	}
+	return o.Spec.Priority
+}
+
 // workerLoop processes items from the work queue using the reconciler.
 func (m *Manager) workerLoop(ctx context.Context, controllerName string, reconciler Reconciler, queue *WorkQueue) {
 	for {
@@ -330,21 +466,31 @@ func (m *Manager) workerLoop(ctx context.Context, controllerName string, reconci
 		default:
 		}
 
-		m.logger.Debug("reconciling",
-			zap.String("controller", controllerName),
-			zap.String("key", key),
-		)
-
-		if err := reconciler.Reconcile(ctx, key); err != nil {
-			m.logger.Error("reconcile failed",
-				zap.String("controller", controllerName),
-				zap.String("key", key),
-				zap.Error(err),
-			)
+		// The request that changed the object, if one did, ties the
+		// reconcile's logs and span to the API server's log of it.
+		requestID := queue.RequestID(key)
+		logger := m.logger.With(zap.String("controller", controllerName), zap.String("key", key))
+		if requestID != "" {
+			logger = logger.With(zap.String("requestID", requestID))
+		}
+		logger.Debug("reconciling")
+
+		spanCtx, span := tracer.Start(ctx, controllerName+".Reconcile", trace.WithAttributes(
+			attribute.String("orca.controller", controllerName),
+			attribute.String("orca.key", key),
+		))
+		if requestID != "" {
+			span.SetAttributes(attribute.String("orca.request_id", requestID))
+		}
+		if err := reconciler.Reconcile(spanCtx, key); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logger.Error("reconcile failed", zap.Error(err))
 			queue.Requeue(key)
 		} else {
 			queue.Done(key)
 		}
+		span.End()
 	}
 }
 
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// reconcilerFunc adapts a function to Reconciler.
+type reconcilerFunc func(ctx context.Context, key string) error
+
+func (f reconcilerFunc) Reconcile(ctx context.Context, key string) error { return f(ctx, key) }
+
+func TestManagerReconcilesExistingObjectsOnStart(t *testing.T) {
+	st := store.NewMemoryStore()
+	want := []string{
+		mustCreate(t, st, v1alpha1.KindAgentPod, "a", &v1alpha1.AgentPod{Metadata: v1alpha1.ObjectMeta{Name: "a", Project: "web"}}),
+		mustCreate(t, st, v1alpha1.KindAgentPod, "b", &v1alpha1.AgentPod{Metadata: v1alpha1.ObjectMeta{Name: "b", Project: "web"}}),
+	}
+	mustCreate(t, st, v1alpha1.KindDevTask, "fix", &v1alpha1.DevTask{Metadata: v1alpha1.ObjectMeta{Name: "fix", Project: "web"}})
+
+	reconciled := make(chan string, 10)
+	m := NewManager(st, zap.NewNop())
+	m.Register("pods", reconcilerFunc(func(_ context.Context, key string) error {
+		reconciled <- key
+		return nil
+	}), []string{v1alpha1.KindAgentPod})
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	// Existing objects may also be reconciled for the events of the watch.
+	seen := make(map[string]bool)
+	deadline := time.After(5 * time.Second)
+	for len(seen) < len(want) {
+		select {
+		case key := <-reconciled:
+			seen[key] = true
+		case <-deadline:
+			t.Fatalf("reconciled %v on start, want %v", seen, want)
+		}
+	}
+	for _, key := range want {
+		if !seen[key] {
+			t.Errorf("%s was not reconciled on start", key)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package events records v1alpha1.Event resources on behalf of controllers.
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// TTL is how long an Event is kept after it last occurred.
+const TTL = time.Hour
+
+// pruneInterval is how often a Recorder deletes expired Events, at most.
+const pruneInterval = time.Minute
+
+// Recorder writes Events to the store. Events about the same object with the
+// same type, reason and message share one stored Event whose Count and
+// LastTimestamp are bumped. Events that have not occurred for TTL are
+// deleted. A nil *Recorder discards events.
+type Recorder struct {
+	store     store.Store
+	source    string
+	logger    *zap.Logger
+	mu        sync.Mutex // serialises read-modify-write of aggregated events
+	lastPrune time.Time
+}
+
+// NewRecorder creates a Recorder that reports events as coming from source
+// (e.g. "devtask-controller").
+func NewRecorder(s store.Store, source string, logger *zap.Logger) *Recorder {
+	return &Recorder{store: s, source: source, logger: logger}
+}
+
+// Normal records an informational event.
+func (r *Recorder) Normal(obj v1alpha1.ObjectReference, reason, format string, args ...interface{}) {
+	r.record(obj, v1alpha1.EventTypeNormal, reason, fmt.Sprintf(format, args...))
+}
+
+// Warning records an event that likely needs the user's attention.
+func (r *Recorder) Warning(obj v1alpha1.ObjectReference, reason, format string, args ...interface{}) {
+	r.record(obj, v1alpha1.EventTypeWarning, reason, fmt.Sprintf(format, args...))
+}
+
+func (r *Recorder) record(obj v1alpha1.ObjectReference, eventType, reason, message string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastPrune) >= pruneInterval {
+		r.prune(now)
+		r.lastPrune = now
+	}
+
+	name := eventName(obj, eventType, reason, message)
+	key := store.ResourceKey(v1alpha1.KindEvent, obj.Project, name)
+
+	var ev v1alpha1.Event
+	err := r.store.Get(key, &ev)
+	switch {
+	case err == nil:
+		ev.Count++
+		ev.LastTimestamp = now
+		ev.Metadata.UpdatedAt = now
+		err = r.store.Update(key, &ev)
+	case err == store.ErrNotFound:
+		ev = v1alpha1.Event{
+			TypeMeta: v1alpha1.TypeMeta{APIVersion: v1alpha1.APIVersion, Kind: v1alpha1.KindEvent},
+			Metadata: v1alpha1.ObjectMeta{
+				Name:      name,
+				Project:   obj.Project,
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			InvolvedObject: obj,
+			Type:           eventType,
+			Reason:         reason,
+			Message:        message,
+			Source:         r.source,
+			Count:          1,
+			FirstTimestamp: now,
+			LastTimestamp:  now,
+		}
+		err = r.store.Create(key, &ev)
+	}
+	if err != nil {
+		r.logger.Warn("failed to record event",
+			zap.String("object", obj.Kind+"/"+obj.Name),
+			zap.String("reason", reason),
+			zap.Error(err),
+		)
+	}
+}
+
+// prune deletes the events, of every project, that last occurred more than
+// TTL before now.
+func (r *Recorder) prune(now time.Time) {
+	objs, err := r.store.List("/"+v1alpha1.KindEvent+"/", func() interface{} { return &v1alpha1.Event{} })
+	if err != nil {
+		r.logger.Warn("failed to list events to prune", zap.Error(err))
+		return
+	}
+	for _, obj := range objs {
+		ev := obj.(*v1alpha1.Event)
+		if now.Sub(ev.LastTimestamp) <= TTL {
+			continue
+		}
+		key := store.ResourceKey(v1alpha1.KindEvent, ev.Metadata.Project, ev.Metadata.Name)
+		if err := r.store.Delete(key); err != nil && err != store.ErrNotFound {
+			r.logger.Warn("failed to prune event", zap.String("event", ev.Metadata.Name), zap.Error(err))
+		}
+	}
+}
+
+// eventName derives a stable name so identical events aggregate.
+func eventName(obj v1alpha1.ObjectReference, eventType, reason, message string) string {
+	sum := sha256.Sum256([]byte(obj.Kind + "\x00" + eventType + "\x00" + reason + "\x00" + message))
+	return obj.Name + "." + hex.EncodeToString(sum[:])[:10]
+}
+
+// Ref returns a reference to the object with the given kind and metadata.
+func Ref(kind string, meta v1alpha1.ObjectMeta) v1alpha1.ObjectReference {
+	return v1alpha1.ObjectReference{Kind: kind, Name: meta.Name, Project: meta.Project}
+}
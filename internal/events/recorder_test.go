@@ -0,0 +1,72 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestRecorderAggregates(t *testing.T) {
+	s := store.NewMemoryStore()
+	r := NewRecorder(s, "test", zap.NewNop())
+	task := v1alpha1.ObjectReference{Kind: v1alpha1.KindDevTask, Name: "fix-bug", Project: "p"}
+
+	r.Warning(task, "FailedScheduling", "no ready pods")
+	r.Warning(task, "FailedScheduling", "no ready pods")
+	r.Normal(task, "Scheduled", "assigned to %s", "pod-1")
+
+	objs, err := s.List("/"+v1alpha1.KindEvent+"/p/", func() interface{} { return &v1alpha1.Event{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(objs))
+	}
+	for _, obj := range objs {
+		ev := obj.(*v1alpha1.Event)
+		switch ev.Reason {
+		case "FailedScheduling":
+			if ev.Count != 2 || ev.Type != v1alpha1.EventTypeWarning {
+				t.Errorf("unexpected aggregated event: %+v", ev)
+			}
+		case "Scheduled":
+			if ev.Count != 1 || ev.Message != "assigned to pod-1" || ev.Source != "test" {
+				t.Errorf("unexpected event: %+v", ev)
+			}
+		default:
+			t.Errorf("unexpected reason %q", ev.Reason)
+		}
+	}
+
+	var nilRecorder *Recorder
+	nilRecorder.Normal(task, "Ignored", "no-op")
+}
+
+func TestRecorderPrunesExpiredEvents(t *testing.T) {
+	s := store.NewMemoryStore()
+	for name, age := range map[string]time.Duration{"old": 2 * TTL, "recent": TTL / 2} {
+		ev := v1alpha1.Event{
+			Metadata:      v1alpha1.ObjectMeta{Name: name, Project: "p"},
+			Count:         1,
+			LastTimestamp: time.Now().Add(-age),
+		}
+		if err := s.Create(store.ResourceKey(v1alpha1.KindEvent, "p", name), &ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := NewRecorder(s, "test", zap.NewNop())
+	r.Normal(v1alpha1.ObjectReference{Kind: v1alpha1.KindAgentPool, Name: "pool", Project: "q"}, "SuccessfulCreate", "Created pod pool-1")
+
+	var ev v1alpha1.Event
+	if err := s.Get(store.ResourceKey(v1alpha1.KindEvent, "p", "old"), &ev); err != store.ErrNotFound {
+		t.Errorf("getting an expired event: %v, want it pruned", err)
+	}
+	if err := s.Get(store.ResourceKey(v1alpha1.KindEvent, "p", "recent"), &ev); err != nil {
+		t.Errorf("getting a recent event: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package logging builds the control plane's zap logger from its config:
+// console or JSON output, a level per component and an optional rotated log
+// file.
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/klubi/orca/internal/config"
+)
+
+// Component names of the control plane's loggers, usable as keys of
+// log.components. A component's level also applies to the loggers named
+// after it, e.g. "controller" to "controller.devtask".
+const (
+	ComponentAgent      = "agent"
+	ComponentAPIServer  = "apiserver"
+	ComponentController = "controller"
+	ComponentEvents     = "events"
+	ComponentScheduler  = "scheduler"
+)
+
+// Levels holds the minimum levels of a logger built by New. They can be
+// changed while the logger is in use.
+type Levels struct {
+	mu         sync.RWMutex
+	base       zapcore.Level
+	components map[string]zapcore.Level
+}
+
+// Set replaces the base level and the component levels.
+func (l *Levels) Set(level string, components map[string]string) error {
+	base, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	parsed := make(map[string]zapcore.Level, len(components))
+	for name, level := range components {
+		lvl, err := zapcore.ParseLevel(level)
+		if err != nil {
+			return fmt.Errorf("component %s: %w", name, err)
+		}
+		parsed[name] = lvl
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.base = base
+	l.components = parsed
+	return nil
+}
+
+// For returns the level of the named logger: that of the longest component
+// name it starts with, or the base level.
+func (l *Levels) For(name string) zapcore.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for {
+		if lvl, ok := l.components[name]; ok {
+			return lvl
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return l.base
+		}
+		name = name[:i]
+	}
+}
+
+// min returns the lowest level any logger is enabled at.
+func (l *Levels) min() zapcore.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	lvl := l.base
+	for _, c := range l.components {
+		if c < lvl {
+			lvl = c
+		}
+	}
+	return lvl
+}
+
+// New builds a logger from cfg. The returned Levels change its levels; the
+// returned close function flushes and closes the log file, if any.
+func New(cfg config.LogConfig) (*zap.Logger, *Levels, func() error, error) {
+	levels := &Levels{}
+	if err := levels.Set(cfg.Level, cfg.Components); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var enc zapcore.Encoder
+	switch cfg.Format {
+	case "json":
+		encCfg := zap.NewProductionEncoderConfig()
+		encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		enc = zapcore.NewJSONEncoder(encCfg)
+	case "console", "":
+		enc = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	var out zapcore.WriteSyncer = zapcore.Lock(os.Stderr)
+	closeFn := func() error { return nil }
+	if cfg.File != "" {
+		f, err := newRotatingFile(cfg.File, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		out = f
+		closeFn = f.Close
+	}
+
+	core := &levelCore{Core: zapcore.NewCore(enc, out, zapcore.DebugLevel), levels: levels}
+	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	return logger, levels, closeFn, nil
+}
+
+// levelCore drops the entries below the level of the logger that wrote them.
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.levels.min()
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.levels.For(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klubi/orca/internal/config"
+)
+
+func TestComponentLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orca.log")
+	logger, levels, closeFn, err := New(config.LogConfig{
+		Level:      "warn",
+		Format:     "json",
+		Components: map[string]string{"scheduler": "debug"},
+		File:       path,
+		MaxSizeMB:  1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("base info")
+	logger.Named("scheduler").Debug("scheduler debug")
+	logger.Named("scheduler").Named("score").Debug("scheduler sub debug")
+	logger.Named("agent").Info("agent info")
+	logger.Named("agent").Warn("agent warn")
+	if err := levels.Set("info", nil); err != nil {
+		t.Fatal(err)
+	}
+	logger.Named("scheduler").Debug("scheduler debug after reset")
+	logger.Info("base info after reset")
+	logger.Sync()
+	closeFn()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry struct {
+			Msg string `json:"msg"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		got = append(got, entry.Msg)
+	}
+	want := []string{"scheduler debug", "scheduler sub debug", "agent warn", "base info after reset"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orca.log")
+	f, err := newRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+
+	for name, want := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		data, err := os.ReadFile(name)
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q (%v), want %q", filepath.Base(name), data, err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("kept more backups than maxBackups")
+	}
+}
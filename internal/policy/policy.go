@@ -0,0 +1,134 @@
+// Package policy applies the defaults and enforces the policies that a
+// Project declares for the resources in it. The API server applies both
+// when resources are created or updated; the DevTask controller checks the
+// budget again before it schedules a task.
+package policy
+
+import (
+	"fmt"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// Violation is a resource breaking its project's policy.
+type Violation struct {
+	Project string
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("project %s policy: %s", v.Project, v.Message)
+}
+
+// ApplyDefaults fills the fields of obj, a typed resource of p, that it
+// leaves empty with p's defaults.
+func ApplyDefaults(p *v1alpha1.Project, obj interface{}) {
+	d := &p.Spec.Defaults
+	if o, ok := obj.(v1alpha1.Object); ok && len(d.Labels) > 0 {
+		meta := o.GetObjectMeta()
+		for k, v := range d.Labels {
+			if _, ok := meta.Labels[k]; ok {
+				continue
+			}
+			if meta.Labels == nil {
+				meta.Labels = make(map[string]string)
+			}
+			meta.Labels[k] = v
+		}
+	}
+
+	podSpec := func(s *v1alpha1.AgentPodSpec) {
+		if s.Model == "" {
+			s.Model = d.Model
+		}
+		if s.MaxTokens == 0 {
+			s.MaxTokens = d.MaxTokens
+		}
+	}
+	switch r := obj.(type) {
+	case *v1alpha1.AgentPod:
+		podSpec(&r.Spec)
+	case *v1alpha1.AgentPool:
+		podSpec(&r.Spec.Template.Spec)
+	}
+}
+
+// Check returns a *Violation if obj, a typed resource of p, names a model
+// p does not allow.
+func Check(p *v1alpha1.Project, obj interface{}) error {
+	policy := &p.Spec.Policy
+	var model, field string
+	switch r := obj.(type) {
+	case *v1alpha1.AgentPod:
+		model, field = r.Spec.Model, "spec.model"
+	case *v1alpha1.AgentPool:
+		model, field = r.Spec.Template.Spec.Model, "spec.template.spec.model"
+	case *v1alpha1.DevTask:
+		model, field = r.Spec.PreferredModel, "spec.preferredModel"
+	default:
+		return nil
+	}
+	if !policy.AllowsModel(model) {
+		return &Violation{
+			Project: p.Metadata.Name,
+			Message: fmt.Sprintf("%s %q is not one of the allowed models %v", field, model, policy.AllowedModels),
+		}
+	}
+	return nil
+}
+
+// Usage sums the tokens and the cost of the last attempts of the tasks in
+// project.
+func Usage(st store.Store, project string) (tokens int, costUSD float64, err error) {
+	prefix := "/" + v1alpha1.KindDevTask + "/" + project + "/"
+	items, err := st.List(prefix, func() interface{} { return &v1alpha1.DevTask{} })
+	if err != nil {
+		return 0, 0, fmt.Errorf("listing tasks of project %s: %w", project, err)
+	}
+	for _, item := range items {
+		task := item.(*v1alpha1.DevTask)
+		tokens += task.Status.TokensIn + task.Status.TokensOut
+		costUSD += task.Status.CostUSD
+	}
+	return tokens, costUSD, nil
+}
+
+// CheckBudget returns a *Violation if the tasks of p have spent its token
+// or cost budget.
+func CheckBudget(st store.Store, p *v1alpha1.Project) error {
+	policy := &p.Spec.Policy
+	if policy.TokenBudget <= 0 && policy.CostBudgetUSD <= 0 {
+		return nil
+	}
+	tokens, cost, err := Usage(st, p.Metadata.Name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case policy.TokenBudget > 0 && tokens >= policy.TokenBudget:
+		return &Violation{
+			Project: p.Metadata.Name,
+			Message: fmt.Sprintf("token budget of %d spent (%d used)", policy.TokenBudget, tokens),
+		}
+	case policy.CostBudgetUSD > 0 && cost >= policy.CostBudgetUSD:
+		return &Violation{
+			Project: p.Metadata.Name,
+			Message: fmt.Sprintf("cost budget of $%.2f spent ($%.4f used)", policy.CostBudgetUSD, cost),
+		}
+	}
+	return nil
+}
+
+// Project reads the project named name from st. A project that does not
+// exist has no defaults or policy, so it is returned empty.
+func Project(st store.Store, name string) (*v1alpha1.Project, error) {
+	var p v1alpha1.Project
+	if err := st.Get(store.ResourceKey(v1alpha1.KindProject, "", name), &p); err != nil {
+		if err == store.ErrNotFound {
+			return &v1alpha1.Project{Metadata: v1alpha1.ObjectMeta{Name: name}}, nil
+		}
+		return nil, fmt.Errorf("getting project %s: %w", name, err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func testProject() *v1alpha1.Project {
+	return &v1alpha1.Project{
+		Metadata: v1alpha1.ObjectMeta{Name: "team"},
+		Spec: v1alpha1.ProjectSpec{
+			Defaults: v1alpha1.ProjectDefaults{
+				Model:     "claude-haiku",
+				MaxTokens: 2048,
+				Labels:    map[string]string{"team": "infra", "tier": "dev"},
+			},
+			Policy: v1alpha1.ProjectPolicy{
+				AllowedModels: []string{"claude-haiku", "claude-sonnet"},
+				TokenBudget:   1000,
+			},
+		},
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	pod := &v1alpha1.AgentPod{
+		Metadata: v1alpha1.ObjectMeta{Name: "p", Labels: map[string]string{"tier": "prod"}},
+		Spec:     v1alpha1.AgentPodSpec{MaxTokens: 100},
+	}
+	ApplyDefaults(testProject(), pod)
+
+	if pod.Spec.Model != "claude-haiku" || pod.Spec.MaxTokens != 100 {
+		t.Errorf("spec = model %q, maxTokens %d; want claude-haiku, 100", pod.Spec.Model, pod.Spec.MaxTokens)
+	}
+	if pod.Metadata.Labels["team"] != "infra" || pod.Metadata.Labels["tier"] != "prod" {
+		t.Errorf("labels = %v, want team=infra added and tier=prod kept", pod.Metadata.Labels)
+	}
+
+	cm := &v1alpha1.ConfigMap{Metadata: v1alpha1.ObjectMeta{Name: "c"}}
+	ApplyDefaults(testProject(), cm)
+	if cm.Metadata.Labels["team"] != "infra" {
+		t.Errorf("configmap labels = %v, want the project's", cm.Metadata.Labels)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := testProject()
+	tests := []struct {
+		name string
+		obj  interface{}
+		ok   bool
+	}{
+		{"allowed pod", &v1alpha1.AgentPod{Spec: v1alpha1.AgentPodSpec{Model: "claude-sonnet"}}, true},
+		{"pod without model", &v1alpha1.AgentPod{}, true},
+		{"disallowed pod", &v1alpha1.AgentPod{Spec: v1alpha1.AgentPodSpec{Model: "claude-opus"}}, false},
+		{"disallowed pool", &v1alpha1.AgentPool{Spec: v1alpha1.AgentPoolSpec{
+			Template: v1alpha1.AgentPodTemplate{Spec: v1alpha1.AgentPodSpec{Model: "claude-opus"}},
+		}}, false},
+		{"disallowed task", &v1alpha1.DevTask{Spec: v1alpha1.DevTaskSpec{PreferredModel: "claude-opus"}}, false},
+		{"other kind", &v1alpha1.ConfigMap{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(p, tt.obj)
+			if tt.ok && err != nil {
+				t.Errorf("Check() = %v, want nil", err)
+			}
+			var violation *Violation
+			if !tt.ok && !errors.As(err, &violation) {
+				t.Errorf("Check() = %v, want a *Violation", err)
+			}
+		})
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	st := store.NewMemoryStore()
+	p := testProject()
+	task := func(name string, tokens int) {
+		t.Helper()
+		err := st.Create(store.ResourceKey(v1alpha1.KindDevTask, "team", name), &v1alpha1.DevTask{
+			Metadata: v1alpha1.ObjectMeta{Name: name, Project: "team"},
+			Status:   v1alpha1.DevTaskStatus{TokensIn: tokens / 2, TokensOut: tokens - tokens/2},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	task("a", 400)
+	if err := CheckBudget(st, p); err != nil {
+		t.Fatalf("CheckBudget() with 400 of 1000 tokens used = %v, want nil", err)
+	}
+	task("b", 600)
+	var violation *Violation
+	if err := CheckBudget(st, p); !errors.As(err, &violation) {
+		t.Fatalf("CheckBudget() with the budget spent = %v, want a *Violation", err)
+	}
+
+	p.Spec.Policy.TokenBudget = 0
+	if err := CheckBudget(st, p); err != nil {
+		t.Errorf("CheckBudget() without a budget = %v, want nil", err)
+	}
+}
+
+func TestProjectNotFound(t *testing.T) {
+	p, err := Project(store.NewMemoryStore(), "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Metadata.Name != "missing" || len(p.Spec.Policy.AllowedModels) != 0 {
+		t.Errorf("Project() = %+v, want an empty project named missing", p)
+	}
+}
@@ -5,9 +5,10 @@ import v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 // Predicate is a filter function that returns true if a pod can accept the task.
 type Predicate func(pod *v1alpha1.AgentPod, task *v1alpha1.DevTask) bool
 
-// PodIsReady checks that the pod is in Ready phase (not Busy, Failed, etc.).
+// PodIsReady checks that the pod is in Ready phase (not Busy, Failed, etc.)
+// and not being deleted.
 func PodIsReady(pod *v1alpha1.AgentPod, task *v1alpha1.DevTask) bool {
-	return pod.Status.Phase == v1alpha1.PodReady
+	return pod.Status.Phase == v1alpha1.PodReady && pod.Metadata.DeletionTimestamp == nil
 }
 
 // PodHasCapacity checks that pod's ActiveTasks < MaxConcurrency.
@@ -49,6 +50,21 @@ func PodMatchesModel(pod *v1alpha1.AgentPod, task *v1alpha1.DevTask) bool {
 	return pod.Spec.Model == task.Spec.PreferredModel
 }
 
+// PodInPool checks that the pod belongs to the task's pool.
+// If the task names no pool, any pod matches.
+func PodInPool(pod *v1alpha1.AgentPod, task *v1alpha1.DevTask) bool {
+	if task.Spec.Pool == "" {
+		return true
+	}
+	return pod.OwnerPool() == task.Spec.Pool
+}
+
+// PodIsTarget checks that the pod is the one the task is pinned to.
+// If the task is not pinned, any pod matches.
+func PodIsTarget(pod *v1alpha1.AgentPod, task *v1alpha1.DevTask) bool {
+	return task.Spec.Pod == "" || pod.Metadata.Name == task.Spec.Pod
+}
+
 // PodInSameProject checks that the pod's project matches the task's project.
 func PodInSameProject(pod *v1alpha1.AgentPod, task *v1alpha1.DevTask) bool {
 	return pod.Metadata.Project == task.Metadata.Project
@@ -1,14 +1,23 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"sync"
 
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/internal/config"
 	"github.com/klubi/orca/internal/store"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("github.com/klubi/orca/internal/scheduler")
+
 // Scheduler assigns DevTasks to AgentPods using Kubernetes-style
 // predicate filtering and priority scoring.
 type Scheduler struct {
@@ -16,6 +25,10 @@ type Scheduler struct {
 	predicates []Predicate
 	priorities []PriorityFunc
 	logger     *zap.Logger
+
+	mu sync.RWMutex
+	// weights scale the scores of priorities, index for index.
+	weights []int
 }
 
 // scoreResult holds a pod and its total priority score.
@@ -34,26 +47,42 @@ func NewScheduler(s store.Store, logger *zap.Logger) *Scheduler {
 			PodHasCapacity,
 			PodMatchesCapability,
 			PodMatchesModel,
+			PodInPool,
+			PodIsTarget,
 		},
 		priorities: []PriorityFunc{
 			LeastLoaded,
 			CapabilityMatch,
 			ModelPreference,
 		},
-		logger: logger,
+		weights: []int{1, 1, 1},
+		logger:  logger,
 	}
 }
 
+// SetWeights changes how much each priority counts towards a pod's score.
+func (s *Scheduler) SetWeights(w config.SchedulerWeights) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.weights = []int{w.LeastLoaded, w.CapabilityMatch, w.ModelPreference}
+}
+
 // Schedule finds the best pod for a task.
 //
 //  1. List all AgentPods in the task's project.
 //  2. Filter through all predicates (pod must pass ALL).
-//  3. Score remaining pods through all priorities (sum scores).
+//  3. Score remaining pods through all priorities (weighted sum of scores).
 //  4. Sort by total score descending.
 //  5. Return the highest-scoring pod.
 //
 // Returns an error if no suitable pod is found.
-func (s *Scheduler) Schedule(task *v1alpha1.DevTask) (*v1alpha1.AgentPod, error) {
+func (s *Scheduler) Schedule(ctx context.Context, task *v1alpha1.DevTask) (*v1alpha1.AgentPod, error) {
+	_, span := tracer.Start(ctx, "Scheduler.Schedule", trace.WithAttributes(
+		attribute.String("orca.project", task.Metadata.Project),
+		attribute.String("orca.task", task.Metadata.Name),
+	))
+	defer span.End()
+
 	// 1. List all AgentPods in the task's project.
 	prefix := fmt.Sprintf("/%s/%s/", v1alpha1.KindAgentPod, task.Metadata.Project)
 	objects, err := s.store.List(prefix, func() interface{} {
@@ -92,17 +121,25 @@ func (s *Scheduler) Schedule(task *v1alpha1.DevTask) (*v1alpha1.AgentPod, error)
 		zap.Int("feasible", len(feasible)),
 	)
 
+	span.SetAttributes(
+		attribute.Int("orca.scheduler.pods", len(objects)),
+		attribute.Int("orca.scheduler.feasible", len(feasible)),
+	)
 	if len(feasible) == 0 {
+		span.SetStatus(codes.Error, "no suitable pod")
 		return nil, fmt.Errorf("no suitable pod found for task %q in project %q",
 			task.Metadata.Name, task.Metadata.Project)
 	}
 
 	// 3. Score remaining pods through all priorities.
+	s.mu.RLock()
+	weights := s.weights
+	s.mu.RUnlock()
 	results := make([]scoreResult, len(feasible))
 	for i, pod := range feasible {
 		total := 0
-		for _, pf := range s.priorities {
-			total += pf(pod, task)
+		for j, pf := range s.priorities {
+			total += weights[j] * pf(pod, task)
 		}
 		results[i] = scoreResult{pod: pod, score: total}
 	}
@@ -119,6 +156,20 @@ func (s *Scheduler) Schedule(task *v1alpha1.DevTask) (*v1alpha1.AgentPod, error)
 		zap.Int("score", best.score),
 	)
 
+	span.SetAttributes(attribute.String("orca.pod", best.pod.Metadata.Name), attribute.Int("orca.scheduler.score", best.score))
+
 	// 5. Return the highest-scoring pod.
 	return best.pod, nil
 }
+
+// SortByPriority sorts tasks by descending spec.priority, and tasks of the
+// same priority oldest first, the order in which they should be scheduled.
+func SortByPriority(tasks []*v1alpha1.DevTask) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if a.Spec.Priority != b.Spec.Priority {
+			return a.Spec.Priority > b.Spec.Priority
+		}
+		return a.Metadata.CreatedAt.Before(b.Metadata.CreatedAt)
+	})
+}
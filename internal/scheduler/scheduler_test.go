@@ -1,9 +1,12 @@
 package scheduler
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/internal/config"
 	"github.com/klubi/orca/internal/store"
 	"go.uber.org/zap"
 )
@@ -59,6 +62,11 @@ func (b *podBuilder) maxConcurrency(n int) *podBuilder {
 	return b
 }
 
+func (b *podBuilder) ownerPool(pool string) *podBuilder {
+	b.pod.Metadata.OwnerReferences = []v1alpha1.OwnerReference{{Kind: v1alpha1.KindAgentPool, Name: pool, Controller: true}}
+	return b
+}
+
 func (b *podBuilder) activeTasks(n int) *podBuilder {
 	b.pod.Status.ActiveTasks = n
 	return b
@@ -102,6 +110,26 @@ func (b *taskBuilder) preferredModel(m string) *taskBuilder {
 	return b
 }
 
+func (b *taskBuilder) pool(p string) *taskBuilder {
+	b.task.Spec.Pool = p
+	return b
+}
+
+func (b *taskBuilder) pod(name string) *taskBuilder {
+	b.task.Spec.Pod = name
+	return b
+}
+
+func (b *taskBuilder) priority(p int) *taskBuilder {
+	b.task.Spec.Priority = p
+	return b
+}
+
+func (b *taskBuilder) createdAt(t time.Time) *taskBuilder {
+	b.task.Metadata.CreatedAt = t
+	return b
+}
+
 func (b *taskBuilder) build() *v1alpha1.DevTask {
 	t := b.task // copy
 	return &t
@@ -262,6 +290,57 @@ func TestPodInSameProject(t *testing.T) {
 	}
 }
 
+func TestPodInPool(t *testing.T) {
+	tests := []struct {
+		name     string
+		podPool  string
+		taskPool string
+		want     bool
+	}{
+		{"no pool requested", "reviewers", "", true},
+		{"same pool", "reviewers", "reviewers", true},
+		{"different pool", "coders", "reviewers", false},
+		{"standalone pod", "", "reviewers", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newPod("p1", "proj").ownerPool(tt.podPool).build()
+			task := newTask("t1", "proj").pool(tt.taskPool).build()
+			got := PodInPool(pod, task)
+			if got != tt.want {
+				t.Errorf("PodInPool(pod=%q, task=%q) = %v, want %v",
+					tt.podPool, tt.taskPool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodIsTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		podName string
+		target  string
+		want    bool
+	}{
+		{"not pinned", "p1", "", true},
+		{"pinned to pod", "p1", "p1", true},
+		{"pinned elsewhere", "p1", "p2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newPod(tt.podName, "proj").build()
+			task := newTask("t1", "proj").pod(tt.target).build()
+			got := PodIsTarget(pod, task)
+			if got != tt.want {
+				t.Errorf("PodIsTarget(pod=%q, target=%q) = %v, want %v",
+					tt.podName, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
 // =========================================================================
 // Priority tests
 // =========================================================================
@@ -413,7 +492,7 @@ func TestScheduleSuccess(t *testing.T) {
 		preferredModel("claude-3").
 		build()
 
-	best, err := sched.Schedule(task)
+	best, err := sched.Schedule(context.Background(), task)
 	if err != nil {
 		t.Fatalf("Schedule() returned unexpected error: %v", err)
 	}
@@ -453,7 +532,7 @@ func TestScheduleSelectsBestByModelPreference(t *testing.T) {
 		preferredModel("claude-3").
 		build()
 
-	best, err := sched.Schedule(task)
+	best, err := sched.Schedule(context.Background(), task)
 	if err != nil {
 		t.Fatalf("Schedule() returned unexpected error: %v", err)
 	}
@@ -469,7 +548,7 @@ func TestScheduleNoPods(t *testing.T) {
 
 	task := newTask("task-1", "proj").build()
 
-	_, err := sched.Schedule(task)
+	_, err := sched.Schedule(context.Background(), task)
 	if err == nil {
 		t.Fatal("Schedule() expected error for empty store, got nil")
 	}
@@ -500,7 +579,7 @@ func TestScheduleNoMatchingPods(t *testing.T) {
 		requiredCapabilities("go").
 		build()
 
-	_, err := sched.Schedule(task)
+	_, err := sched.Schedule(context.Background(), task)
 	if err == nil {
 		t.Fatal("Schedule() expected error when no pods match predicates, got nil")
 	}
@@ -519,7 +598,7 @@ func TestScheduleNoMatchingPods_FullCapacity(t *testing.T) {
 
 	task := newTask("task-1", "proj").build()
 
-	_, err := sched.Schedule(task)
+	_, err := sched.Schedule(context.Background(), task)
 	if err == nil {
 		t.Fatal("Schedule() expected error when only pod is at full capacity, got nil")
 	}
@@ -539,7 +618,7 @@ func TestScheduleNoMatchingPods_ModelMismatch(t *testing.T) {
 		preferredModel("claude-3").
 		build()
 
-	_, err := sched.Schedule(task)
+	_, err := sched.Schedule(context.Background(), task)
 	if err == nil {
 		t.Fatal("Schedule() expected error when pod model does not match task preference, got nil")
 	}
@@ -581,7 +660,7 @@ func TestScheduleMultipleFeasiblePods(t *testing.T) {
 		preferredModel("claude-3").
 		build()
 
-	best, err := sched.Schedule(task)
+	best, err := sched.Schedule(context.Background(), task)
 	if err != nil {
 		t.Fatalf("Schedule() returned unexpected error: %v", err)
 	}
@@ -590,3 +669,47 @@ func TestScheduleMultipleFeasiblePods(t *testing.T) {
 		t.Errorf("Schedule() selected %q, want %q (lightest load)", best.Metadata.Name, "pod-c")
 	}
 }
+
+func TestScheduleWeights(t *testing.T) {
+	sched, s := newTestScheduler(t)
+	defer s.Close()
+
+	// Pod A is lightly loaded; pod B is busier but has extra capabilities.
+	addPodToStore(t, s, newPod("pod-a", "proj").capabilities("go").maxConcurrency(10).activeTasks(1).build())
+	addPodToStore(t, s, newPod("pod-b", "proj").capabilities("go", "docker").maxConcurrency(10).activeTasks(8).build())
+	task := newTask("task-1", "proj").requiredCapabilities("go").build()
+
+	best, err := sched.Schedule(context.Background(), task)
+	if err != nil {
+		t.Fatalf("Schedule() returned unexpected error: %v", err)
+	}
+	if best.Metadata.Name != "pod-a" {
+		t.Errorf("with equal weights Schedule() selected %q, want %q", best.Metadata.Name, "pod-a")
+	}
+
+	sched.SetWeights(config.SchedulerWeights{LeastLoaded: 0, CapabilityMatch: 1, ModelPreference: 1})
+	if best, err = sched.Schedule(context.Background(), task); err != nil {
+		t.Fatalf("Schedule() returned unexpected error: %v", err)
+	}
+	if best.Metadata.Name != "pod-b" {
+		t.Errorf("without LeastLoaded Schedule() selected %q, want %q", best.Metadata.Name, "pod-b")
+	}
+}
+
+func TestSortByPriority(t *testing.T) {
+	now := time.Now()
+	tasks := []*v1alpha1.DevTask{
+		newTask("low", "p").priority(-1).createdAt(now).build(),
+		newTask("new", "p").createdAt(now.Add(time.Minute)).build(),
+		newTask("old", "p").createdAt(now).build(),
+		newTask("urgent", "p").priority(10).createdAt(now.Add(time.Hour)).build(),
+	}
+	SortByPriority(tasks)
+
+	want := []string{"urgent", "old", "new", "low"}
+	for i, task := range tasks {
+		if task.Metadata.Name != want[i] {
+			t.Fatalf("tasks[%d] = %s, want order %v", i, task.Metadata.Name, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"strings"
 	"sync"
+	"time"
 
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 	bolt "go.etcd.io/bbolt"
@@ -45,16 +46,24 @@ func NewBoltStore(path string) (*BoltStore, error) {
 // ---------- CRUD ----------
 
 func (b *BoltStore) Create(key string, value interface{}) error {
-	raw, err := json.Marshal(value)
-	if err != nil {
-		return err
-	}
+	return b.create(key, value, "")
+}
 
-	err = b.db.Update(func(tx *bolt.Tx) error {
+func (b *BoltStore) create(key string, value interface{}, requestID string) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket(bucketName)
 		if bkt.Get([]byte(key)) != nil {
 			return ErrAlreadyExists
 		}
+		// The bucket sequence is the last resourceVersion handed out.
+		version, err := bkt.NextSequence()
+		if err != nil {
+			return err
+		}
+		raw, err := encode(value, nil, version)
+		if err != nil {
+			return err
+		}
 		return bkt.Put([]byte(key), raw)
 	})
 	if err != nil {
@@ -62,10 +71,11 @@ func (b *BoltStore) Create(key string, value interface{}) error {
 	}
 
 	b.notify(v1alpha1.WatchEvent{
-		Type:   v1alpha1.EventAdded,
-		Kind:   kindFromKey(key),
-		Key:    key,
-		Object: value,
+		Type:      v1alpha1.EventAdded,
+		Kind:      kindFromKey(key),
+		Key:       key,
+		RequestID: requestID,
+		Object:    value,
 	})
 	return nil
 }
@@ -82,16 +92,36 @@ func (b *BoltStore) Get(key string, target interface{}) error {
 }
 
 func (b *BoltStore) Update(key string, value interface{}) error {
-	raw, err := json.Marshal(value)
-	if err != nil {
-		return err
-	}
+	return b.UpdateIfVersion(key, value, "")
+}
 
-	err = b.db.Update(func(tx *bolt.Tx) error {
+func (b *BoltStore) UpdateIfVersion(key string, value interface{}, resourceVersion string) error {
+	return b.updateIfVersion(key, value, resourceVersion, "")
+}
+
+func (b *BoltStore) updateIfVersion(key string, value interface{}, resourceVersion, requestID string) error {
+	evt := v1alpha1.EventModified
+	err := b.db.Update(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket(bucketName)
-		if bkt.Get([]byte(key)) == nil {
+		old := bkt.Get([]byte(key))
+		if old == nil {
 			return ErrNotFound
 		}
+		if err := checkVersion(old, resourceVersion); err != nil {
+			return err
+		}
+		version, err := bkt.NextSequence()
+		if err != nil {
+			return err
+		}
+		raw, err := encode(value, old, version)
+		if err != nil {
+			return err
+		}
+		if readDeletionState(raw).finished() {
+			evt = v1alpha1.EventDeleted
+			return bkt.Delete([]byte(key))
+		}
 		return bkt.Put([]byte(key), raw)
 	})
 	if err != nil {
@@ -99,16 +129,22 @@ func (b *BoltStore) Update(key string, value interface{}) error {
 	}
 
 	b.notify(v1alpha1.WatchEvent{
-		Type:   v1alpha1.EventModified,
-		Kind:   kindFromKey(key),
-		Key:    key,
-		Object: value,
+		Type:      evt,
+		Kind:      kindFromKey(key),
+		Key:       key,
+		RequestID: requestID,
+		Object:    value,
 	})
 	return nil
 }
 
 func (b *BoltStore) Delete(key string) error {
+	return b.delete(key, "")
+}
+
+func (b *BoltStore) delete(key, requestID string) error {
 	var obj interface{}
+	evt := v1alpha1.EventDeleted
 
 	err := b.db.Update(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket(bucketName)
@@ -116,19 +152,37 @@ func (b *BoltStore) Delete(key string) error {
 		if raw == nil {
 			return ErrNotFound
 		}
+		if state := readDeletionState(raw); state.deferred() {
+			if state.Metadata.DeletionTimestamp != nil {
+				evt = ""
+				return nil
+			}
+			version, err := bkt.NextSequence()
+			if err != nil {
+				return err
+			}
+			marked, err := markDeleted(raw, version, time.Now())
+			if err != nil {
+				return err
+			}
+			evt = v1alpha1.EventModified
+			_ = json.Unmarshal(marked, &obj)
+			return bkt.Put([]byte(key), marked)
+		}
 		// Capture the object before deletion so watchers receive it.
 		_ = json.Unmarshal(raw, &obj)
 		return bkt.Delete([]byte(key))
 	})
-	if err != nil {
+	if err != nil || evt == "" {
 		return err
 	}
 
 	b.notify(v1alpha1.WatchEvent{
-		Type:   v1alpha1.EventDeleted,
-		Kind:   kindFromKey(key),
-		Key:    key,
-		Object: obj,
+		Type:      evt,
+		Kind:      kindFromKey(key),
+		Key:       key,
+		RequestID: requestID,
+		Object:    obj,
 	})
 	return nil
 }
@@ -155,6 +209,66 @@ func (b *BoltStore) List(prefix string, factory func() interface{}) ([]interface
 	return results, err
 }
 
+// ---------- Snapshot / Restore ----------
+
+func (b *BoltStore) Snapshot() (map[string][]byte, error) {
+	data := make(map[string][]byte)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		// Values are only valid for the life of the transaction.
+		return tx.Bucket(bucketName).ForEach(func(k, v []byte) error {
+			if !auditKey(string(k)) {
+				data[string(k)] = append([]byte(nil), v...)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (b *BoltStore) Restore(data map[string][]byte) error {
+	var old, fresh map[string][]byte
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		old = make(map[string][]byte)
+		if err := tx.Bucket(bucketName).ForEach(func(k, v []byte) error {
+			old[string(k)] = append([]byte(nil), v...)
+			return nil
+		}); err != nil {
+			return err
+		}
+		fresh = restoredContents(old, data)
+
+		// Keep the sequence so that resourceVersions are never reused.
+		version := max(tx.Bucket(bucketName).Sequence(), maxResourceVersion(fresh))
+		if err := tx.DeleteBucket(bucketName); err != nil {
+			return err
+		}
+		bkt, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+		if err := bkt.SetSequence(version); err != nil {
+			return err
+		}
+		for k, v := range fresh {
+			if err := bkt.Put([]byte(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, evt := range restoreEvents(old, fresh) {
+		b.notify(evt)
+	}
+	return nil
+}
+
 // ---------- Watch ----------
 
 func (b *BoltStore) Watch(prefix string) (<-chan v1alpha1.WatchEvent, func()) {
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// deletionState is the part of a stored object's metadata that decides
+// whether deleting it removes it.
+type deletionState struct {
+	Metadata struct {
+		Finalizers        []string   `json:"finalizers"`
+		DeletionTimestamp *time.Time `json:"deletionTimestamp"`
+	} `json:"metadata"`
+}
+
+func readDeletionState(raw []byte) deletionState {
+	var d deletionState
+	_ = json.Unmarshal(raw, &d)
+	return d
+}
+
+// deferred reports whether deleting the object must wait for its
+// finalizers.
+func (d deletionState) deferred() bool {
+	return len(d.Metadata.Finalizers) > 0
+}
+
+// finished reports whether the object is being deleted and has no
+// finalizers left, so that the store removes it.
+func (d deletionState) finished() bool {
+	return d.Metadata.DeletionTimestamp != nil && len(d.Metadata.Finalizers) == 0
+}
+
+// markDeleted returns raw, the JSON of a stored object, with its
+// deletionTimestamp set to now, its generation bumped, so that controllers
+// skipping writes they have observed do not skip this one, and version as
+// its resourceVersion.
+func markDeleted(raw []byte, version uint64, now time.Time) ([]byte, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal(obj["metadata"], &meta); err != nil {
+		return nil, err
+	}
+	generation, _ := meta["generation"].(float64)
+	meta["generation"] = int64(generation) + 1
+	meta["resourceVersion"] = strconv.FormatUint(version, 10)
+	meta["deletionTimestamp"] = now
+	m, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	obj["metadata"] = m
+	return json.Marshal(obj)
+}
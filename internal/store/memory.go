@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"strings"
 	"sync"
+	"time"
 
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 )
@@ -19,6 +20,7 @@ type watcher struct {
 type MemoryStore struct {
 	mu       sync.RWMutex
 	data     map[string][]byte // key -> JSON bytes
+	version  uint64            // last resourceVersion handed out
 	watchers []*watcher
 }
 
@@ -32,24 +34,29 @@ func NewMemoryStore() *MemoryStore {
 // ---------- CRUD ----------
 
 func (m *MemoryStore) Create(key string, value interface{}) error {
-	raw, err := json.Marshal(value)
-	if err != nil {
-		return err
-	}
+	return m.create(key, value, "")
+}
 
+func (m *MemoryStore) create(key string, value interface{}, requestID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if _, exists := m.data[key]; exists {
 		return ErrAlreadyExists
 	}
+	raw, err := encode(value, nil, m.version+1)
+	if err != nil {
+		return err
+	}
+	m.version++
 	m.data[key] = raw
 
 	m.notify(v1alpha1.WatchEvent{
-		Type:   v1alpha1.EventAdded,
-		Kind:   kindFromKey(key),
-		Key:    key,
-		Object: value,
+		Type:      v1alpha1.EventAdded,
+		Kind:      kindFromKey(key),
+		Key:       key,
+		RequestID: requestID,
+		Object:    value,
 	})
 	return nil
 }
@@ -66,29 +73,57 @@ func (m *MemoryStore) Get(key string, target interface{}) error {
 }
 
 func (m *MemoryStore) Update(key string, value interface{}) error {
-	raw, err := json.Marshal(value)
-	if err != nil {
-		return err
-	}
+	return m.UpdateIfVersion(key, value, "")
+}
 
+func (m *MemoryStore) UpdateIfVersion(key string, value interface{}, resourceVersion string) error {
+	return m.updateIfVersion(key, value, resourceVersion, "")
+}
+
+func (m *MemoryStore) updateIfVersion(key string, value interface{}, resourceVersion, requestID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, exists := m.data[key]; !exists {
+	old, exists := m.data[key]
+	if !exists {
 		return ErrNotFound
 	}
+	if err := checkVersion(old, resourceVersion); err != nil {
+		return err
+	}
+	raw, err := encode(value, old, m.version+1)
+	if err != nil {
+		return err
+	}
+	if readDeletionState(raw).finished() {
+		delete(m.data, key)
+		m.notify(v1alpha1.WatchEvent{
+			Type:      v1alpha1.EventDeleted,
+			Kind:      kindFromKey(key),
+			Key:       key,
+			RequestID: requestID,
+			Object:    value,
+		})
+		return nil
+	}
+	m.version++
 	m.data[key] = raw
 
 	m.notify(v1alpha1.WatchEvent{
-		Type:   v1alpha1.EventModified,
-		Kind:   kindFromKey(key),
-		Key:    key,
-		Object: value,
+		Type:      v1alpha1.EventModified,
+		Kind:      kindFromKey(key),
+		Key:       key,
+		RequestID: requestID,
+		Object:    value,
 	})
 	return nil
 }
 
 func (m *MemoryStore) Delete(key string) error {
+	return m.delete(key, "")
+}
+
+func (m *MemoryStore) delete(key, requestID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -96,6 +131,28 @@ func (m *MemoryStore) Delete(key string) error {
 	if !exists {
 		return ErrNotFound
 	}
+	if state := readDeletionState(raw); state.deferred() {
+		if state.Metadata.DeletionTimestamp != nil {
+			return nil
+		}
+		marked, err := markDeleted(raw, m.version+1, time.Now())
+		if err != nil {
+			return err
+		}
+		m.version++
+		m.data[key] = marked
+
+		var obj interface{}
+		_ = json.Unmarshal(marked, &obj)
+		m.notify(v1alpha1.WatchEvent{
+			Type:      v1alpha1.EventModified,
+			Kind:      kindFromKey(key),
+			Key:       key,
+			RequestID: requestID,
+			Object:    obj,
+		})
+		return nil
+	}
 	delete(m.data, key)
 
 	// Deserialise the old value so watchers receive the deleted object.
@@ -103,10 +160,11 @@ func (m *MemoryStore) Delete(key string) error {
 	_ = json.Unmarshal(raw, &obj)
 
 	m.notify(v1alpha1.WatchEvent{
-		Type:   v1alpha1.EventDeleted,
-		Kind:   kindFromKey(key),
-		Key:    key,
-		Object: obj,
+		Type:      v1alpha1.EventDeleted,
+		Kind:      kindFromKey(key),
+		Key:       key,
+		RequestID: requestID,
+		Object:    obj,
 	})
 	return nil
 }
@@ -131,6 +189,35 @@ func (m *MemoryStore) List(prefix string, factory func() interface{}) ([]interfa
 	return results, nil
 }
 
+// ---------- Snapshot / Restore ----------
+
+func (m *MemoryStore) Snapshot() (map[string][]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data := make(map[string][]byte, len(m.data))
+	for k, raw := range m.data {
+		if !auditKey(k) {
+			data[k] = append([]byte(nil), raw...)
+		}
+	}
+	return data, nil
+}
+
+func (m *MemoryStore) Restore(data map[string][]byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old := m.data
+	fresh := restoredContents(old, data)
+	m.data = fresh
+	m.version = max(m.version, maxResourceVersion(fresh))
+	for _, evt := range restoreEvents(old, fresh) {
+		m.notify(evt)
+	}
+	return nil
+}
+
 // ---------- Watch ----------
 
 func (m *MemoryStore) Watch(prefix string) (<-chan v1alpha1.WatchEvent, func()) {
@@ -0,0 +1,43 @@
+package store
+
+// requestWriter is implemented by the stores that can tag the watch events
+// of their writes with the ID of the request that made them.
+type requestWriter interface {
+	create(key string, value interface{}, requestID string) error
+	updateIfVersion(key string, value interface{}, resourceVersion, requestID string) error
+	delete(key, requestID string) error
+}
+
+// WithRequestID returns s with the watch events of its writes carrying
+// requestID, so that the controllers reacting to them can log which API
+// request they reconcile. s is returned unchanged if id is empty or s cannot
+// tag its events.
+func WithRequestID(s Store, requestID string) Store {
+	w, ok := s.(requestWriter)
+	if !ok || requestID == "" {
+		return s
+	}
+	return &requestStore{Store: s, w: w, requestID: requestID}
+}
+
+type requestStore struct {
+	Store
+	w         requestWriter
+	requestID string
+}
+
+func (r *requestStore) Create(key string, value interface{}) error {
+	return r.w.create(key, value, r.requestID)
+}
+
+func (r *requestStore) Update(key string, value interface{}) error {
+	return r.w.updateIfVersion(key, value, "", r.requestID)
+}
+
+func (r *requestStore) UpdateIfVersion(key string, value interface{}, resourceVersion string) error {
+	return r.w.updateIfVersion(key, value, resourceVersion, r.requestID)
+}
+
+func (r *requestStore) Delete(key string) error {
+	return r.w.delete(key, r.requestID)
+}
@@ -5,7 +5,13 @@
 package store
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 )
@@ -20,11 +26,20 @@ type Store interface {
 	// Returns ErrNotFound if the key does not exist.
 	Get(key string, target interface{}) error
 
-	// Update replaces the object at the given key.
+	// Update replaces the object at the given key. An object being deleted
+	// keeps its deletionTimestamp, and is removed instead once it has no
+	// finalizers left.
 	// Returns ErrNotFound if the key does not exist.
 	Update(key string, value interface{}) error
 
-	// Delete removes the object at the given key.
+	// UpdateIfVersion replaces the object at the given key as Update does,
+	// but only while its resourceVersion is resourceVersion; otherwise it
+	// returns ErrConflict. An empty resourceVersion matches any.
+	UpdateIfVersion(key string, value interface{}, resourceVersion string) error
+
+	// Delete removes the object at the given key, or, if it has
+	// finalizers, sets its deletionTimestamp and leaves it to be removed by
+	// the write that clears the last of them.
 	// Returns ErrNotFound if the key does not exist.
 	Delete(key string) error
 
@@ -38,6 +53,17 @@ type Store interface {
 	// and closes the channel.
 	Watch(prefix string) (<-chan v1alpha1.WatchEvent, func())
 
+	// Snapshot returns every key in the store with its JSON value, as of a
+	// single point in time, except for the audit entries.
+	Snapshot() (map[string][]byte, error)
+
+	// Restore replaces the entire contents of the store with data, a map of
+	// keys to JSON values as returned by Snapshot. The audit entries are
+	// kept, and those of data ignored, so that a restore cannot rewrite the
+	// audit log. Watchers are notified of every key added, modified or
+	// deleted as a result.
+	Restore(data map[string][]byte) error
+
 	// Close releases any resources held by the store (e.g. BoltDB file handle).
 	Close() error
 }
@@ -46,6 +72,7 @@ type Store interface {
 var (
 	ErrAlreadyExists = fmt.Errorf("key already exists")
 	ErrNotFound      = fmt.Errorf("key not found")
+	ErrConflict      = fmt.Errorf("object has been modified")
 )
 
 // ResourceKey builds a canonical store key for a resource.
@@ -55,3 +82,152 @@ var (
 func ResourceKey(kind, project, name string) string {
 	return fmt.Sprintf("/%s/%s/%s", kind, project, name)
 }
+
+// encode returns the JSON of value, about to be written over old (nil on
+// create). When value is a v1alpha1.Object its generation and
+// resourceVersion are set first: the generation is 1 on create and bumped
+// when anything but the metadata and status differs from old; version
+// becomes the resourceVersion. Its deletionTimestamp is cleared on create
+// and carried over from old otherwise, so that writers holding a copy read
+// before the object was deleted cannot undo the deletion.
+func encode(value interface{}, old []byte, version uint64) ([]byte, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := value.(v1alpha1.Object)
+	if !ok {
+		return raw, nil
+	}
+
+	meta := obj.GetObjectMeta()
+	meta.Generation = 1
+	meta.DeletionTimestamp = nil
+	if old != nil {
+		var prev struct {
+			Metadata struct {
+				Generation        int64      `json:"generation"`
+				DeletionTimestamp *time.Time `json:"deletionTimestamp"`
+			} `json:"metadata"`
+		}
+		_ = json.Unmarshal(old, &prev)
+		meta.Generation = max(prev.Metadata.Generation, 1)
+		if !sameDesiredState(old, raw) {
+			meta.Generation++
+		}
+		meta.DeletionTimestamp = prev.Metadata.DeletionTimestamp
+	}
+	meta.ResourceVersion = strconv.FormatUint(version, 10)
+	return json.Marshal(value)
+}
+
+// checkVersion returns ErrConflict unless old, the JSON of a stored object,
+// has resourceVersion, or resourceVersion is empty.
+func checkVersion(old []byte, resourceVersion string) error {
+	if resourceVersion == "" {
+		return nil
+	}
+	var obj struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	}
+	_ = json.Unmarshal(old, &obj)
+	if obj.Metadata.ResourceVersion != resourceVersion {
+		return ErrConflict
+	}
+	return nil
+}
+
+// sameDesiredState reports whether two JSON objects are equal but for their
+// metadata and status.
+func sameDesiredState(a, b []byte) bool {
+	var x, y map[string]json.RawMessage
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return false
+	}
+	for _, m := range []map[string]json.RawMessage{x, y} {
+		delete(m, "metadata")
+		delete(m, "status")
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// maxResourceVersion returns the highest resourceVersion among data, so
+// that a store restoring it never hands out one of its versions again.
+func maxResourceVersion(data map[string][]byte) uint64 {
+	var highest uint64
+	for _, raw := range data {
+		var obj struct {
+			Metadata struct {
+				ResourceVersion string `json:"resourceVersion"`
+			} `json:"metadata"`
+		}
+		_ = json.Unmarshal(raw, &obj)
+		if v, err := strconv.ParseUint(obj.Metadata.ResourceVersion, 10, 64); err == nil {
+			highest = max(highest, v)
+		}
+	}
+	return highest
+}
+
+// auditKey reports whether key is that of an audit entry, which Snapshot
+// and Restore leave alone.
+func auditKey(key string) bool {
+	return strings.HasPrefix(key, "/"+v1alpha1.KindAuditEntry+"/")
+}
+
+// restoredContents returns what restoring data over the contents old
+// leaves in a store: data without its audit entries, and the audit entries
+// of old.
+func restoredContents(old, data map[string][]byte) map[string][]byte {
+	fresh := make(map[string][]byte, len(data))
+	for k, raw := range data {
+		if !auditKey(k) {
+			fresh[k] = append([]byte(nil), raw...)
+		}
+	}
+	for k, raw := range old {
+		if auditKey(k) {
+			fresh[k] = raw
+		}
+	}
+	return fresh
+}
+
+// restoreEvents lists the watch events that replacing the contents old with
+// data amounts to.
+func restoreEvents(old, data map[string][]byte) []v1alpha1.WatchEvent {
+	var events []v1alpha1.WatchEvent
+	for key, raw := range old {
+		if _, ok := data[key]; ok {
+			continue
+		}
+		var obj interface{}
+		_ = json.Unmarshal(raw, &obj)
+		events = append(events, v1alpha1.WatchEvent{
+			Type:   v1alpha1.EventDeleted,
+			Kind:   kindFromKey(key),
+			Key:    key,
+			Object: obj,
+		})
+	}
+	for key, raw := range data {
+		typ := v1alpha1.EventAdded
+		if prev, ok := old[key]; ok {
+			if bytes.Equal(prev, raw) {
+				continue
+			}
+			typ = v1alpha1.EventModified
+		}
+		var obj interface{}
+		_ = json.Unmarshal(raw, &obj)
+		events = append(events, v1alpha1.WatchEvent{
+			Type:   typ,
+			Kind:   kindFromKey(key),
+			Key:    key,
+			Object: obj,
+		})
+	}
+	return events
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -526,3 +527,273 @@ func receiveEvent(t *testing.T, ch <-chan v1alpha1.WatchEvent, timeout time.Dura
 		return v1alpha1.WatchEvent{} // unreachable, satisfies compiler
 	}
 }
+
+func TestSnapshotRestore(t *testing.T) {
+	bolt, err := NewBoltStore(filepath.Join(t.TempDir(), "orca.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "bolt": bolt} {
+		t.Run(name, func(t *testing.T) {
+			defer s.Close()
+
+			keep := ResourceKey(v1alpha1.KindAgentPod, "default", "keep")
+			gone := ResourceKey(v1alpha1.KindAgentPod, "default", "gone")
+			if err := s.Create(keep, newTestPod("keep", "default", "claude-sonnet")); err != nil {
+				t.Fatal(err)
+			}
+			before := ResourceKey(v1alpha1.KindAuditEntry, "", "before")
+			if err := s.Create(before, &v1alpha1.AuditEntry{Verb: "create"}); err != nil {
+				t.Fatal(err)
+			}
+			snap, err := s.Snapshot()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(snap) != 1 || snap[keep] == nil {
+				t.Fatalf("unexpected snapshot %v", snap)
+			}
+
+			// Audit entries are neither rolled back nor forged by a restore.
+			after := ResourceKey(v1alpha1.KindAuditEntry, "", "after")
+			if err := s.Create(after, &v1alpha1.AuditEntry{Verb: "update"}); err != nil {
+				t.Fatal(err)
+			}
+			forged := ResourceKey(v1alpha1.KindAuditEntry, "", "forged")
+			snap[forged] = []byte(`{"verb":"delete"}`)
+
+			// Diverge from the snapshot, then restore it.
+			if err := s.Create(gone, newTestPod("gone", "default", "claude-sonnet")); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Update(keep, newTestPod("keep", "default", "claude-opus")); err != nil {
+				t.Fatal(err)
+			}
+
+			ch, cancel := s.Watch("/")
+			defer cancel()
+			if err := s.Restore(snap); err != nil {
+				t.Fatal(err)
+			}
+
+			var pod v1alpha1.AgentPod
+			if err := s.Get(keep, &pod); err != nil || pod.Spec.Model != "claude-sonnet" {
+				t.Errorf("restored pod: %+v, %v", pod.Spec, err)
+			}
+			if err := s.Get(gone, &pod); err != ErrNotFound {
+				t.Errorf("expected %s to be gone, got %v", gone, err)
+			}
+			var entry v1alpha1.AuditEntry
+			for _, key := range []string{before, after} {
+				if err := s.Get(key, &entry); err != nil {
+					t.Errorf("audit entry %s after a restore: %v", key, err)
+				}
+			}
+			if err := s.Get(forged, &entry); err != ErrNotFound {
+				t.Errorf("restored a backup's audit entry %s: %v", forged, err)
+			}
+
+			got := make(map[string]v1alpha1.EventType)
+			for len(got) < 2 {
+				select {
+				case evt := <-ch:
+					got[evt.Key] = evt.Type
+				case <-time.After(time.Second):
+					t.Fatalf("timed out waiting for events, got %v", got)
+				}
+			}
+			if got[keep] != v1alpha1.EventModified || got[gone] != v1alpha1.EventDeleted {
+				t.Errorf("unexpected events %v", got)
+			}
+		})
+	}
+}
+
+func TestGenerationAndResourceVersion(t *testing.T) {
+	bolt, err := NewBoltStore(filepath.Join(t.TempDir(), "orca.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "bolt": bolt} {
+		t.Run(name, func(t *testing.T) {
+			defer s.Close()
+
+			key := ResourceKey(v1alpha1.KindAgentPod, "default", "p")
+			pod := newTestPod("p", "default", "claude-sonnet")
+			if err := s.Create(key, pod); err != nil {
+				t.Fatal(err)
+			}
+			if pod.Metadata.Generation != 1 || pod.Metadata.ResourceVersion == "" {
+				t.Fatalf("after create: generation %d, resourceVersion %q", pod.Metadata.Generation, pod.Metadata.ResourceVersion)
+			}
+			versions := map[string]bool{pod.Metadata.ResourceVersion: true}
+
+			steps := []struct {
+				change func(*v1alpha1.AgentPod)
+				want   int64
+			}{
+				{func(p *v1alpha1.AgentPod) { p.Status.Phase = v1alpha1.PodReady }, 1},
+				{func(p *v1alpha1.AgentPod) { p.Metadata.Annotations = map[string]string{"a": "b"} }, 1},
+				{func(p *v1alpha1.AgentPod) { p.Spec.Model = "claude-opus" }, 2},
+				{func(p *v1alpha1.AgentPod) {}, 2},
+			}
+			for i, step := range steps {
+				var cur v1alpha1.AgentPod
+				if err := s.Get(key, &cur); err != nil {
+					t.Fatal(err)
+				}
+				step.change(&cur)
+				if err := s.Update(key, &cur); err != nil {
+					t.Fatal(err)
+				}
+
+				var got v1alpha1.AgentPod
+				if err := s.Get(key, &got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Metadata.Generation != step.want {
+					t.Errorf("step %d: generation %d, want %d", i, got.Metadata.Generation, step.want)
+				}
+				if versions[got.Metadata.ResourceVersion] {
+					t.Errorf("step %d: resourceVersion %q reused", i, got.Metadata.ResourceVersion)
+				}
+				versions[got.Metadata.ResourceVersion] = true
+			}
+		})
+	}
+}
+
+func TestUpdateIfVersion(t *testing.T) {
+	bolt, err := NewBoltStore(filepath.Join(t.TempDir(), "orca.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "bolt": bolt} {
+		t.Run(name, func(t *testing.T) {
+			defer s.Close()
+
+			key := ResourceKey(v1alpha1.KindAgentPod, "default", "p")
+			pod := newTestPod("p", "default", "claude-sonnet")
+			if err := s.Create(key, pod); err != nil {
+				t.Fatal(err)
+			}
+			stale := pod.Metadata.ResourceVersion
+
+			pod.Spec.Model = "claude-opus"
+			if err := s.UpdateIfVersion(key, pod, stale); err != nil {
+				t.Fatalf("update at the current version: %v", err)
+			}
+			pod.Spec.Model = "claude-haiku"
+			if err := s.UpdateIfVersion(key, pod, stale); err != ErrConflict {
+				t.Fatalf("update at a stale version = %v, want ErrConflict", err)
+			}
+			if err := s.UpdateIfVersion(key, pod, ""); err != nil {
+				t.Fatalf("update at any version: %v", err)
+			}
+			if err := s.UpdateIfVersion(ResourceKey(v1alpha1.KindAgentPod, "default", "missing"), pod, ""); err != ErrNotFound {
+				t.Errorf("update of a missing key = %v, want ErrNotFound", err)
+			}
+
+			var got v1alpha1.AgentPod
+			if err := s.Get(key, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Spec.Model != "claude-haiku" {
+				t.Errorf("model = %s, want claude-haiku", got.Spec.Model)
+			}
+		})
+	}
+}
+
+func TestWithRequestID(t *testing.T) {
+	bolt, err := NewBoltStore(filepath.Join(t.TempDir(), "orca.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "bolt": bolt} {
+		t.Run(name, func(t *testing.T) {
+			defer s.Close()
+			ch, cancel := s.Watch("/" + v1alpha1.KindAgentPod + "/")
+			defer cancel()
+
+			key := ResourceKey(v1alpha1.KindAgentPod, "default", "p")
+			rs := WithRequestID(s, "req-1")
+			if err := rs.Create(key, newTestPod("p", "default", "claude-sonnet")); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Update(key, newTestPod("p", "default", "claude-opus")); err != nil {
+				t.Fatal(err)
+			}
+			if err := rs.Delete(key); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range []string{"req-1", "", "req-1"} {
+				if evt := receiveEvent(t, ch, 2*time.Second); evt.RequestID != want {
+					t.Errorf("%s event has request ID %q, want %q", evt.Type, evt.RequestID, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteWithFinalizers(t *testing.T) {
+	bolt, err := NewBoltStore(filepath.Join(t.TempDir(), "orca.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "bolt": bolt} {
+		t.Run(name, func(t *testing.T) {
+			defer s.Close()
+			ch, cancel := s.Watch("/" + v1alpha1.KindAgentPod + "/")
+			defer cancel()
+
+			key := ResourceKey(v1alpha1.KindAgentPod, "default", "p")
+			pod := newTestPod("p", "default", "claude-sonnet")
+			pod.Metadata.Finalizers = []string{v1alpha1.FinalizerRuntime}
+			if err := s.Create(key, pod); err != nil {
+				t.Fatal(err)
+			}
+			stale := *pod
+
+			// Deleting the pod only marks it.
+			if err := s.Delete(key); err != nil {
+				t.Fatal(err)
+			}
+			var got v1alpha1.AgentPod
+			if err := s.Get(key, &got); err != nil {
+				t.Fatalf("pod with a finalizer removed by Delete: %v", err)
+			}
+			if got.Metadata.DeletionTimestamp == nil || got.Metadata.Generation != 2 {
+				t.Fatalf("deleted pod has deletionTimestamp %v and generation %d", got.Metadata.DeletionTimestamp, got.Metadata.Generation)
+			}
+			if err := s.Delete(key); err != nil {
+				t.Fatalf("deleting a pod being deleted: %v", err)
+			}
+
+			// A write from before the deletion does not undo it.
+			stale.Status.Phase = v1alpha1.PodReady
+			if err := s.Update(key, &stale); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Get(key, &got); err != nil || got.Metadata.DeletionTimestamp == nil {
+				t.Fatalf("stale write cleared the deletionTimestamp: %+v, %v", got.Metadata, err)
+			}
+
+			// Clearing the last finalizer removes the pod.
+			got.Metadata.RemoveFinalizer(v1alpha1.FinalizerRuntime)
+			if err := s.Update(key, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Get(key, &got); err != ErrNotFound {
+				t.Fatalf("expected the pod to be removed, got %v", err)
+			}
+
+			for _, want := range []v1alpha1.EventType{v1alpha1.EventAdded, v1alpha1.EventModified, v1alpha1.EventModified, v1alpha1.EventDeleted} {
+				if evt := receiveEvent(t, ch, 2*time.Second); evt.Type != want {
+					t.Errorf("got %s event, want %s", evt.Type, want)
+				}
+			}
+		})
+	}
+}
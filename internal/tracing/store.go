@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/klubi/orca/internal/store"
+)
+
+var tracer = otel.Tracer("github.com/klubi/orca/internal/store")
+
+// Store returns s with its reads and writes recorded as spans under the
+// span of ctx. Without a recording span in ctx, s is returned unchanged.
+func Store(ctx context.Context, s store.Store) store.Store {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return s
+	}
+	return &tracedStore{Store: s, ctx: ctx}
+}
+
+type tracedStore struct {
+	store.Store
+	ctx context.Context
+}
+
+// span runs op in a span named after it, recording its error. A missing
+// key is not an error of the store.
+func (t *tracedStore) span(op, key string, fn func() error) error {
+	_, span := tracer.Start(t.ctx, "store."+op, trace.WithAttributes(attribute.String("orca.store.key", key)))
+	defer span.End()
+	err := fn()
+	if err != nil && err != store.ErrNotFound {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func (t *tracedStore) Create(key string, value interface{}) error {
+	return t.span("Create", key, func() error { return t.Store.Create(key, value) })
+}
+
+func (t *tracedStore) Get(key string, target interface{}) error {
+	return t.span("Get", key, func() error { return t.Store.Get(key, target) })
+}
+
+func (t *tracedStore) Update(key string, value interface{}) error {
+	return t.span("Update", key, func() error { return t.Store.Update(key, value) })
+}
+
+func (t *tracedStore) UpdateIfVersion(key string, value interface{}, resourceVersion string) error {
+	return t.span("UpdateIfVersion", key, func() error { return t.Store.UpdateIfVersion(key, value, resourceVersion) })
+}
+
+func (t *tracedStore) Delete(key string) error {
+	return t.span("Delete", key, func() error { return t.Store.Delete(key) })
+}
+
+func (t *tracedStore) List(prefix string, factory func() interface{}) ([]interface{}, error) {
+	var out []interface{}
+	err := t.span("List", prefix, func() error {
+		var err error
+		out, err = t.Store.List(prefix, factory)
+		return err
+	})
+	return out, err
+}
@@ -0,0 +1,93 @@
+// Package tracing sets up OpenTelemetry tracing for the control plane and
+// carries trace context through the store, so that a task's trace runs from
+// the API request that created it through its reconciles, scheduling and
+// execution.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/version"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// propagator encodes span contexts as W3C traceparent headers and
+// annotations.
+var propagator = propagation.TraceContext{}
+
+// Setup installs the global tracer provider described by cfg and returns a
+// function that flushes and stops it. When tracing is disabled spans are
+// not recorded, but incoming trace context is still propagated.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator)
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("orca"),
+		semconv.ServiceVersion(version.Get()),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("creating trace resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Inject records the span context of ctx in the object's annotations, if
+// the span is being recorded, for Extract to continue the trace from.
+func Inject(ctx context.Context, meta *v1alpha1.ObjectMeta) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return
+	}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	tp := carrier.Get("traceparent")
+	if tp == "" {
+		return
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[v1alpha1.AnnotationTraceParent] = tp
+}
+
+// Extract returns ctx with the span context recorded in the object's
+// annotations by Inject as the remote parent, or ctx unchanged if there is
+// none.
+func Extract(ctx context.Context, meta v1alpha1.ObjectMeta) context.Context {
+	tp := meta.Annotations[v1alpha1.AnnotationTraceParent]
+	if tp == "" {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{"traceparent": tp})
+}
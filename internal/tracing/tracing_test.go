@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestTraceThroughStore(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	ctx, root := tp.Tracer("test").Start(context.Background(), "request")
+
+	var meta v1alpha1.ObjectMeta
+	Inject(ctx, &meta)
+	if meta.Annotations[v1alpha1.AnnotationTraceParent] == "" {
+		t.Fatal("Inject did not record the trace context")
+	}
+
+	st := Store(ctx, store.NewMemoryStore())
+	if err := st.Create("/DevTask/web/fix", &meta); err != nil {
+		t.Fatal(err)
+	}
+	var got v1alpha1.ObjectMeta
+	if err := st.Get("/DevTask/web/missing", &got); err != store.ErrNotFound {
+		t.Fatalf("Get of a missing key = %v", err)
+	}
+	root.End()
+
+	// A later reconcile continues the trace from the annotation.
+	rctx := Extract(context.Background(), meta)
+	if sc := trace.SpanContextFromContext(rctx); sc.TraceID() != root.SpanContext().TraceID() {
+		t.Errorf("extracted trace %s, want %s", sc.TraceID(), root.SpanContext().TraceID())
+	}
+
+	spans := exp.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans, want 3", len(spans))
+	}
+	for _, s := range spans[:2] {
+		if s.Parent.SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of the request", s.Name)
+		}
+	}
+	if spans[0].Name != "store.Create" || spans[1].Name != "store.Get" {
+		t.Errorf("spans = %s, %s", spans[0].Name, spans[1].Name)
+	}
+
+	if Store(context.Background(), st) != st {
+		t.Error("a store without a recording span was wrapped")
+	}
+}
@@ -2,6 +2,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -17,21 +18,41 @@ import (
 // App is the main TUI application. It polls the Orca REST API and displays
 // resources (Pods, Pools, Tasks, Projects) in a navigable table view.
 type App struct {
-	app        *tview.Application
-	pages      *tview.Pages
-	header     *tview.TextView
-	footer     *tview.TextView
-	table      *tview.Table
+	app         *tview.Application
+	pages       *tview.Pages
+	header      *tview.TextView
+	footer      *tview.TextView
+	table       *tview.Table
 	filterInput *tview.InputField
-	detailView *tview.TextView
-	layout     *tview.Flex
+	detailView  *tview.TextView
+	layout      *tview.Flex
 
-	client         *client.Client
+	// commandInput is the command bar opened with ':'.
+	commandInput *tview.InputField
+
+	// tree shows the xray view; collapsed holds the keys of the nodes the
+	// user collapsed.
+	tree      *tview.TreeView
+	collapsed map[string]bool
+
+	// metricsView shows the metrics view; replicaHistory holds the pod
+	// counts it has seen, by minute.
+	metricsView    *tview.TextView
+	replicaHistory map[time.Time]replicaSample
+
+	client         client.Interface
 	serverAddr     string
-	currentView    string // "pods", "pools", "tasks", "projects"
+	contextName    string // CLI context in use, if any
+	skin           *Skin
+	currentView    string // "pods", "pools", "tasks", "projects", "xray", "metrics"
 	currentProject string
 	filter         string
 
+	// headers are the columns of the table; sorts holds the sort order
+	// chosen for each view.
+	headers []string
+	sorts   map[string]sortState
+
 	// Cached data from the last successful refresh.
 	pods     []v1alpha1.AgentPod
 	pools    []v1alpha1.AgentPool
@@ -48,43 +69,93 @@ type App struct {
 	describeOpen bool
 	// filterOpen tracks whether the filter input is visible.
 	filterOpen bool
+	// commandOpen tracks whether the command bar is visible.
+	commandOpen bool
+
+	// streamView shows a stream such as a pod's logs; streamOpen tracks
+	// whether it is visible, streamFollow whether it scrolls to new lines,
+	// streamWrap whether long lines wrap and streamCancel stops the stream.
+	streamView   *tview.TextView
+	streamOpen   bool
+	streamFollow bool
+	streamWrap   bool
+	streamCancel context.CancelFunc
+
+	// output is the open task output viewer, if any.
+	output *outputPane
+	// formOpen tracks whether a form such as the new task form is visible.
+	formOpen bool
+
+	// watchCancel stops keeping the current view up to date.
+	watchCancel context.CancelFunc
+	// paused is set while the view is not kept up to date. interval is the
+	// refresh interval; lastUpdate is when a change was last drawn and
+	// updatePending is set while a redraw is scheduled.
+	paused        bool
+	interval      time.Duration
+	lastUpdate    time.Time
+	updatePending bool
 }
 
-// NewApp creates a new TUI application connected to the given Orca API server.
-func NewApp(serverAddr string) *App {
+// Config is what the TUI starts with.
+type Config struct {
+	// Client talks to the Orca API server at Server. Context names the CLI
+	// context they came from, if any.
+	Client  client.Interface
+	Server  string
+	Context string
+	Skin    *Skin
+}
+
+// NewApp creates a new TUI application connected to the Orca API server of
+// cfg.
+func NewApp(cfg Config) *App {
+	skin := cfg.Skin
+	skin.apply()
 	a := &App{
 		app:         tview.NewApplication(),
-		client:      client.New(serverAddr),
-		serverAddr:  serverAddr,
+		client:      cfg.Client,
+		serverAddr:  cfg.Server,
+		contextName: cfg.Context,
+		skin:        skin,
 		currentView: "pods",
+		sorts:       make(map[string]sortState),
+		collapsed:   make(map[string]bool),
+		interval:    defaultRefreshInterval,
+
+		replicaHistory: make(map[time.Time]replicaSample),
 	}
 
 	// -- Header --
 	a.header = tview.NewTextView().
 		SetDynamicColors(true).
-		SetTextAlign(tview.AlignLeft)
-	a.header.SetBackgroundColor(tcell.ColorDarkBlue)
+		SetTextAlign(tview.AlignLeft).
+		SetTextColor(color(skin.Header.Fg))
+	a.header.SetBackgroundColor(color(skin.Header.Bg))
 
 	// -- Footer --
 	a.footer = tview.NewTextView().
 		SetDynamicColors(true).
-		SetTextAlign(tview.AlignLeft)
-	a.footer.SetBackgroundColor(tcell.ColorDarkBlue)
+		SetTextAlign(tview.AlignLeft).
+		SetTextColor(color(skin.Footer.Fg))
+	a.footer.SetBackgroundColor(color(skin.Footer.Bg))
 
 	// -- Table --
 	a.table = tview.NewTable().
 		SetSelectable(true, false).
 		SetFixed(1, 0). // header row stays fixed
-		SetSeparator(tview.Borders.Vertical)
+		SetSeparator(tview.Borders.Vertical).
+		SetSelectedStyle(skin.selectedStyle())
 	a.table.SetBorder(false)
 	a.table.SetBorderPadding(0, 0, 1, 1)
 
 	// -- Filter input --
 	a.filterInput = tview.NewInputField().
 		SetLabel(" Filter: ").
-		SetFieldWidth(40).
-		SetFieldBackgroundColor(tcell.ColorBlack).
-		SetLabelColor(tcell.ColorYellow)
+		SetPlaceholder("fuzzy text, or column:text such as phase:Failed").
+		SetFieldWidth(50).
+		SetFieldBackgroundColor(color(skin.Background)).
+		SetLabelColor(color(skin.Keys))
 
 	a.filterInput.SetDoneFunc(func(key tcell.Key) {
 		switch key {
@@ -94,7 +165,7 @@ func NewApp(serverAddr string) *App {
 			a.mu.Unlock()
 			a.hideFilter()
 			a.updateTable()
-			a.app.SetFocus(a.table)
+			a.app.SetFocus(a.mainView())
 		case tcell.KeyEscape:
 			a.mu.Lock()
 			a.filter = ""
@@ -102,10 +173,19 @@ func NewApp(serverAddr string) *App {
 			a.filterInput.SetText("")
 			a.hideFilter()
 			a.updateTable()
-			a.app.SetFocus(a.table)
+			a.app.SetFocus(a.mainView())
 		}
 	})
 
+	// -- XRay tree --
+	a.tree = a.newTree()
+
+	// -- Metrics dashboard --
+	a.metricsView = newMetricsView()
+
+	// -- Command bar --
+	a.commandInput = a.newCommandInput()
+
 	// -- Detail / Describe view --
 	a.detailView = tview.NewTextView().
 		SetDynamicColors(true).
@@ -113,7 +193,7 @@ func NewApp(serverAddr string) *App {
 		SetWrap(true)
 	a.detailView.SetBorder(true).
 		SetTitle(" Describe ").
-		SetBorderColor(tcell.ColorDodgerBlue)
+		SetBorderColor(color(skin.Border))
 
 	// -- Build the main layout --
 	// contentFlex holds the table (and optionally the detail panel).
@@ -141,24 +221,14 @@ func NewApp(serverAddr string) *App {
 	return a
 }
 
-// Run starts the background refresh goroutine and runs the TUI event loop.
+// Run starts watching the current view and runs the TUI event loop.
 func (a *App) Run() error {
 	// Perform an initial synchronous refresh so the table is populated
 	// before the first render.
 	a.refresh()
+	a.updateTable()
 
-	// Background poller.
-	go func() {
-		ticker := time.NewTicker(2 * time.Second)
-		defer ticker.Stop()
-		for range ticker.C {
-			a.refresh()
-			a.app.QueueUpdateDraw(func() {
-				a.updateTable()
-			})
-		}
-	}()
-
+	a.startWatch()
 	return a.app.Run()
 }
 
@@ -168,8 +238,22 @@ func (a *App) Run() error {
 
 func (a *App) setupKeyBindings() {
 	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		// When the filter input has focus, let it handle its own keys.
-		if a.filterOpen {
+		// When the filter input, the command bar, a form or a confirmation
+		// has focus, let it handle its own keys.
+		if a.filterOpen || a.commandOpen || a.formOpen || a.pages.HasPage("confirm") {
+			return event
+		}
+
+		// The output viewer and the stream pane have keys of their own.
+		if a.output != nil {
+			return a.handleOutputKey(event)
+		}
+		if a.streamOpen {
+			return a.handleStreamKey(event)
+		}
+
+		// The xray tree moves its own selection and expands nodes on Enter.
+		if a.currentView == "xray" && (event.Key() == tcell.KeyEnter || event.Rune() == 'j' || event.Rune() == 'k') {
 			return event
 		}
 
@@ -194,23 +278,68 @@ func (a *App) setupKeyBindings() {
 			case '4':
 				a.switchView("projects")
 				return nil
+			case '5':
+				a.switchView("xray")
+				return nil
+			case '0':
+				a.switchView("metrics")
+				return nil
 			case '/':
 				a.showFilter()
 				return nil
+			case ':':
+				a.showCommand()
+				return nil
+			case 'c':
+				if a.currentView == "tasks" {
+					a.confirmCancel()
+					return nil
+				}
+				a.showContexts()
+				return nil
+			case 'p':
+				a.togglePause()
+				return nil
+			case '+', '=':
+				a.stepInterval(1)
+				return nil
+			case '-':
+				a.stepInterval(-1)
+				return nil
 			case 'q':
 				a.app.Stop()
 				return nil
 			case 'r':
-				go func() {
-					a.refresh()
-					a.app.QueueUpdateDraw(func() {
-						a.updateTable()
-					})
-				}()
+				if a.currentView == "tasks" {
+					a.confirmRetry()
+					return nil
+				}
+				a.refreshNow()
 				return nil
 			case 'd':
 				a.confirmDelete()
 				return nil
+			case 'l':
+				if a.currentView == "pods" {
+					a.showLogs()
+				}
+				return nil
+			case 'o':
+				if a.currentView == "tasks" {
+					a.showOutput()
+				}
+				return nil
+			case 'x':
+				if a.currentView == "pods" {
+					a.showExecForm()
+				}
+				return nil
+			case 'n':
+				a.showNewTaskForm()
+				return nil
+			case 'N', 'A', 'P', 'T', 'R':
+				a.toggleSort(sortKeys[event.Rune()])
+				return nil
 			case 'j':
 				// Move selection down (vim-style).
 				row, _ := a.table.GetSelection()
@@ -229,6 +358,9 @@ func (a *App) setupKeyBindings() {
 		case tcell.KeyEnter:
 			a.showDescribe()
 			return nil
+		case tcell.KeyCtrlR:
+			a.refreshNow()
+			return nil
 		case tcell.KeyEscape:
 			if a.filter != "" {
 				a.mu.Lock()
@@ -252,14 +384,14 @@ func (a *App) switchView(view string) {
 	a.currentView = view
 	a.mu.Unlock()
 
-	a.updateHeader()
+	a.hideDescribe()
+	a.layout.Clear()
+	a.layout.AddItem(a.mainView(), 0, 1, true)
+	a.app.SetFocus(a.mainView())
 
-	go func() {
-		a.refresh()
-		a.app.QueueUpdateDraw(func() {
-			a.updateTable()
-		})
-	}()
+	a.updateHeader()
+	a.updateFooter()
+	a.startWatch()
 }
 
 // ---------------------------------------------------------------------------
@@ -270,33 +402,36 @@ func (a *App) refresh() {
 	a.mu.Lock()
 	view := a.currentView
 	project := a.currentProject
+	c := a.client
 	a.mu.Unlock()
 
 	switch view {
 	case "pods":
-		pods, err := a.client.ListAgentPods(project)
+		pods, err := c.ListAgentPods(project)
 		a.mu.Lock()
 		a.pods = pods
 		a.lastErr = err
 		a.mu.Unlock()
 	case "pools":
-		pools, err := a.client.ListAgentPools(project)
+		pools, err := c.ListAgentPools(project)
 		a.mu.Lock()
 		a.pools = pools
 		a.lastErr = err
 		a.mu.Unlock()
 	case "tasks":
-		tasks, err := a.client.ListDevTasks(project)
+		tasks, err := c.ListDevTasks(project)
 		a.mu.Lock()
 		a.tasks = tasks
 		a.lastErr = err
 		a.mu.Unlock()
 	case "projects":
-		projects, err := a.client.ListProjects()
+		projects, err := c.ListProjects()
 		a.mu.Lock()
 		a.projects = projects
 		a.lastErr = err
 		a.mu.Unlock()
+	case "xray", "metrics":
+		a.refreshWorkloads(c, project)
 	}
 }
 
@@ -305,6 +440,24 @@ func (a *App) refresh() {
 // ---------------------------------------------------------------------------
 
 func (a *App) updateTable() {
+	switch a.currentView {
+	case "xray":
+		a.mu.Lock()
+		filter := strings.ToLower(a.filter)
+		a.mu.Unlock()
+		a.updateTree(filter)
+		return
+	case "metrics":
+		a.updateMetrics()
+		return
+	}
+
+	// Keep the selection on the same resource, at the same height on
+	// screen, across the rebuild.
+	selRow, _ := a.table.GetSelection()
+	rowOffset, columnOffset := a.table.GetOffset()
+	selName, selProject, hadSel := a.selected()
+
 	a.table.Clear()
 
 	a.mu.Lock()
@@ -323,26 +476,40 @@ func (a *App) updateTable() {
 
 	switch view {
 	case "pods":
-		a.renderPods(filter)
+		a.renderPods()
 	case "pools":
-		a.renderPools(filter)
+		a.renderPools()
 	case "tasks":
-		a.renderTasks(filter)
+		a.renderTasks()
 	case "projects":
-		a.renderProjects(filter)
+		a.renderProjects()
 	}
+	a.filterTable(filter)
+	a.sortTable()
 
 	// Ensure a row is selected.
-	if a.table.GetRowCount() > 1 {
-		a.table.Select(1, 0)
+	rows := a.table.GetRowCount()
+	if rows <= 1 {
+		return
 	}
+	if hadSel {
+		for row := 1; row < rows; row++ {
+			if name, project, _ := a.rowResource(row); name == selName && project == selProject {
+				a.table.Select(row, 0)
+				a.table.SetOffset(max(0, row-(selRow-rowOffset)), columnOffset)
+				return
+			}
+		}
+	}
+	a.table.Select(max(1, min(selRow, rows-1)), 0)
 }
 
 func (a *App) setTableHeaders(headers []string) {
+	a.headers = headers
 	for col, h := range headers {
-		cell := tview.NewTableCell(h).
-			SetTextColor(tcell.ColorWhite).
-			SetBackgroundColor(tcell.ColorDarkCyan).
+		cell := tview.NewTableCell(a.headerLabel(h)).
+			SetTextColor(color(a.skin.TableHeader.Fg)).
+			SetBackgroundColor(color(a.skin.TableHeader.Bg)).
 			SetAttributes(tcell.AttrBold).
 			SetSelectable(false).
 			SetExpansion(1)
@@ -350,20 +517,7 @@ func (a *App) setTableHeaders(headers []string) {
 	}
 }
 
-// matchesFilter returns true if any of the values contain the filter string.
-func matchesFilter(filter string, values ...string) bool {
-	if filter == "" {
-		return true
-	}
-	for _, v := range values {
-		if strings.Contains(strings.ToLower(v), filter) {
-			return true
-		}
-	}
-	return false
-}
-
-func (a *App) renderPods(filter string) {
+func (a *App) renderPods() {
 	headers := []string{"NAME", "PROJECT", "MODEL", "PHASE", "ACTIVE-TASKS", "AGE"}
 	a.setTableHeaders(headers)
 
@@ -377,22 +531,20 @@ func (a *App) renderPods(filter string) {
 		active := fmt.Sprintf("%d", p.Status.ActiveTasks)
 		age := formatAge(p.Metadata.CreatedAt)
 
-		if !matchesFilter(filter, p.Metadata.Name, p.Metadata.Project, p.Spec.Model, phase, active, age) {
-			continue
-		}
-
 		a.table.SetCell(row, 0, tview.NewTableCell(p.Metadata.Name).SetExpansion(1))
 		a.table.SetCell(row, 1, tview.NewTableCell(p.Metadata.Project).SetExpansion(1))
 		a.table.SetCell(row, 2, tview.NewTableCell(p.Spec.Model).SetExpansion(1))
 		a.table.SetCell(row, 3, tview.NewTableCell(phase).
-			SetTextColor(phaseColor(phase)).SetExpansion(1))
-		a.table.SetCell(row, 4, tview.NewTableCell(active).SetExpansion(1))
-		a.table.SetCell(row, 5, tview.NewTableCell(age).SetExpansion(1))
+			SetTextColor(a.skin.phaseColor(phase)).SetExpansion(1))
+		a.table.SetCell(row, 4, tview.NewTableCell(active).
+			SetReference(p.Status.ActiveTasks).SetExpansion(1))
+		a.table.SetCell(row, 5, tview.NewTableCell(age).
+			SetReference(p.Metadata.CreatedAt).SetExpansion(1))
 		row++
 	}
 }
 
-func (a *App) renderPools(filter string) {
+func (a *App) renderPools() {
 	headers := []string{"NAME", "PROJECT", "REPLICAS", "READY", "BUSY", "AGE"}
 	a.setTableHeaders(headers)
 
@@ -407,23 +559,23 @@ func (a *App) renderPools(filter string) {
 		busy := fmt.Sprintf("%d", p.Status.BusyReplicas)
 		age := formatAge(p.Metadata.CreatedAt)
 
-		if !matchesFilter(filter, p.Metadata.Name, p.Metadata.Project, replicas, ready, busy, age) {
-			continue
-		}
-
 		a.table.SetCell(row, 0, tview.NewTableCell(p.Metadata.Name).SetExpansion(1))
 		a.table.SetCell(row, 1, tview.NewTableCell(p.Metadata.Project).SetExpansion(1))
-		a.table.SetCell(row, 2, tview.NewTableCell(replicas).SetExpansion(1))
+		a.table.SetCell(row, 2, tview.NewTableCell(replicas).
+			SetReference(p.Spec.Replicas).SetExpansion(1))
 		a.table.SetCell(row, 3, tview.NewTableCell(ready).
-			SetTextColor(tcell.ColorGreen).SetExpansion(1))
+			SetReference(p.Status.ReadyReplicas).
+			SetTextColor(a.skin.phaseColor("Ready")).SetExpansion(1))
 		a.table.SetCell(row, 4, tview.NewTableCell(busy).
-			SetTextColor(tcell.ColorYellow).SetExpansion(1))
-		a.table.SetCell(row, 5, tview.NewTableCell(age).SetExpansion(1))
+			SetReference(p.Status.BusyReplicas).
+			SetTextColor(a.skin.phaseColor("Busy")).SetExpansion(1))
+		a.table.SetCell(row, 5, tview.NewTableCell(age).
+			SetReference(p.Metadata.CreatedAt).SetExpansion(1))
 		row++
 	}
 }
 
-func (a *App) renderTasks(filter string) {
+func (a *App) renderTasks() {
 	headers := []string{"NAME", "PROJECT", "PHASE", "ASSIGNED-POD", "RETRIES", "AGE"}
 	a.setTableHeaders(headers)
 
@@ -437,22 +589,20 @@ func (a *App) renderTasks(filter string) {
 		retries := fmt.Sprintf("%d", t.Status.Retries)
 		age := formatAge(t.Metadata.CreatedAt)
 
-		if !matchesFilter(filter, t.Metadata.Name, t.Metadata.Project, phase, t.Status.AssignedPod, retries, age) {
-			continue
-		}
-
 		a.table.SetCell(row, 0, tview.NewTableCell(t.Metadata.Name).SetExpansion(1))
 		a.table.SetCell(row, 1, tview.NewTableCell(t.Metadata.Project).SetExpansion(1))
 		a.table.SetCell(row, 2, tview.NewTableCell(phase).
-			SetTextColor(phaseColor(phase)).SetExpansion(1))
+			SetTextColor(a.skin.phaseColor(phase)).SetExpansion(1))
 		a.table.SetCell(row, 3, tview.NewTableCell(t.Status.AssignedPod).SetExpansion(1))
-		a.table.SetCell(row, 4, tview.NewTableCell(retries).SetExpansion(1))
-		a.table.SetCell(row, 5, tview.NewTableCell(age).SetExpansion(1))
+		a.table.SetCell(row, 4, tview.NewTableCell(retries).
+			SetReference(t.Status.Retries).SetExpansion(1))
+		a.table.SetCell(row, 5, tview.NewTableCell(age).
+			SetReference(t.Metadata.CreatedAt).SetExpansion(1))
 		row++
 	}
 }
 
-func (a *App) renderProjects(filter string) {
+func (a *App) renderProjects() {
 	headers := []string{"NAME", "DESCRIPTION", "AGE"}
 	a.setTableHeaders(headers)
 
@@ -464,13 +614,10 @@ func (a *App) renderProjects(filter string) {
 	for _, p := range projects {
 		age := formatAge(p.Metadata.CreatedAt)
 
-		if !matchesFilter(filter, p.Metadata.Name, p.Spec.Description, age) {
-			continue
-		}
-
 		a.table.SetCell(row, 0, tview.NewTableCell(p.Metadata.Name).SetExpansion(1))
 		a.table.SetCell(row, 1, tview.NewTableCell(p.Spec.Description).SetExpansion(1))
-		a.table.SetCell(row, 2, tview.NewTableCell(age).SetExpansion(1))
+		a.table.SetCell(row, 2, tview.NewTableCell(age).
+			SetReference(p.Metadata.CreatedAt).SetExpansion(1))
 		row++
 	}
 }
@@ -479,17 +626,34 @@ func (a *App) renderProjects(filter string) {
 // Describe (detail panel)
 // ---------------------------------------------------------------------------
 
-func (a *App) showDescribe() {
+// selected returns the name and project of the resource in the selected
+// row. ok is false when no resource is selected.
+func (a *App) selected() (name, project string, ok bool) {
+	if a.mainView() != a.table {
+		return "", "", false
+	}
 	row, _ := a.table.GetSelection()
+	return a.rowResource(row)
+}
+
+// rowResource returns the name and project of the resource in row.
+func (a *App) rowResource(row int) (name, project string, ok bool) {
 	if row < 1 || row >= a.table.GetRowCount() {
-		return
+		return "", "", false
 	}
 
-	name := a.table.GetCell(row, 0).Text
-	project := ""
+	name = cellText(a.table.GetCell(row, 0))
 	// For non-project views, column 1 is the project.
 	if a.currentView != "projects" && a.table.GetColumnCount() > 1 {
-		project = a.table.GetCell(row, 1).Text
+		project = cellText(a.table.GetCell(row, 1))
+	}
+	return name, project, true
+}
+
+func (a *App) showDescribe() {
+	name, project, ok := a.selected()
+	if !ok {
+		return
 	}
 
 	a.detailView.Clear()
@@ -543,7 +707,7 @@ func (a *App) hideDescribe() {
 	if a.describeOpen {
 		a.layout.RemoveItem(a.detailView)
 		a.describeOpen = false
-		a.app.SetFocus(a.table)
+		a.app.SetFocus(a.mainView())
 	}
 }
 
@@ -554,14 +718,14 @@ func (a *App) formatPodDescribe(pod *v1alpha1.AgentPod) string {
 	b.WriteString(fmt.Sprintf("[::b]UID:[-::-]           %s\n", pod.Metadata.UID))
 	b.WriteString(fmt.Sprintf("[::b]Model:[-::-]         %s\n", pod.Spec.Model))
 	b.WriteString(fmt.Sprintf("[::b]Phase:[-::-]         [%s]%s[-]\n",
-		phaseColorName(string(pod.Status.Phase)), pod.Status.Phase))
+		a.skin.phaseColorName(string(pod.Status.Phase)), pod.Status.Phase))
 	b.WriteString(fmt.Sprintf("[::b]Active Tasks:[-::-]  %d\n", pod.Status.ActiveTasks))
 	b.WriteString(fmt.Sprintf("[::b]Completed:[-::-]     %d\n", pod.Status.CompletedTasks))
 	b.WriteString(fmt.Sprintf("[::b]Failed:[-::-]        %d\n", pod.Status.FailedTasks))
 	b.WriteString(fmt.Sprintf("[::b]Max Concurrency:[-::-] %d\n", pod.Spec.MaxConcurrency))
 	b.WriteString(fmt.Sprintf("[::b]Max Tokens:[-::-]    %d\n", pod.Spec.MaxTokens))
 	b.WriteString(fmt.Sprintf("[::b]Restart Policy:[-::-] %s\n", pod.Spec.RestartPolicy))
-	b.WriteString(fmt.Sprintf("[::b]Owner Pool:[-::-]    %s\n", pod.Spec.OwnerPool))
+	b.WriteString(fmt.Sprintf("[::b]Owner Pool:[-::-]    %s\n", pod.OwnerPool()))
 	if pod.Status.Message != "" {
 		b.WriteString(fmt.Sprintf("[::b]Message:[-::-]       %s\n", pod.Status.Message))
 	}
@@ -598,7 +762,7 @@ func (a *App) formatPoolDescribe(pool *v1alpha1.AgentPool) string {
 	b.WriteString(fmt.Sprintf("[::b]Replicas:[-::-]       %d (desired) / %d (current)\n",
 		pool.Spec.Replicas, pool.Status.Replicas))
 	b.WriteString(fmt.Sprintf("[::b]Ready:[-::-]          [green]%d[-]\n", pool.Status.ReadyReplicas))
-	b.WriteString(fmt.Sprintf("[::b]Busy:[-::-]           [yellow]%d[-]\n", pool.Status.BusyReplicas))
+	b.WriteString(fmt.Sprintf("[::b]Busy:[-::-]           [%s]%d[-]\n", a.skin.phaseColorName("Busy"), pool.Status.BusyReplicas))
 	b.WriteString(fmt.Sprintf("[::b]Template Model:[-::-] %s\n", pool.Spec.Template.Spec.Model))
 	b.WriteString(fmt.Sprintf("[::b]Created:[-::-]        %s\n", pool.Metadata.CreatedAt.Format(time.RFC3339)))
 
@@ -624,11 +788,15 @@ func (a *App) formatTaskDescribe(task *v1alpha1.DevTask) string {
 	b.WriteString(fmt.Sprintf("[::b]Project:[-::-]      %s\n", task.Metadata.Project))
 	b.WriteString(fmt.Sprintf("[::b]UID:[-::-]          %s\n", task.Metadata.UID))
 	b.WriteString(fmt.Sprintf("[::b]Phase:[-::-]        [%s]%s[-]\n",
-		phaseColorName(string(task.Status.Phase)), task.Status.Phase))
+		a.skin.phaseColorName(string(task.Status.Phase)), task.Status.Phase))
 	b.WriteString(fmt.Sprintf("[::b]Assigned Pod:[-::-] %s\n", task.Status.AssignedPod))
 	b.WriteString(fmt.Sprintf("[::b]Retries:[-::-]      %d / %d\n",
 		task.Status.Retries, task.Spec.MaxRetries))
-	b.WriteString(fmt.Sprintf("[::b]Prompt:[-::-]\n  %s\n", task.Spec.Prompt))
+	if task.Spec.PromptTemplate != "" {
+		b.WriteString(fmt.Sprintf("[::b]Prompt Template:[-::-]\n  %s\n", task.Spec.PromptTemplate))
+	} else {
+		b.WriteString(fmt.Sprintf("[::b]Prompt:[-::-]\n  %s\n", task.Spec.Prompt))
+	}
 
 	if task.Spec.PreferredModel != "" {
 		b.WriteString(fmt.Sprintf("[::b]Preferred Model:[-::-] %s\n", task.Spec.PreferredModel))
@@ -716,7 +884,7 @@ func (a *App) hideFilter() {
 	// Restore footer in place of filter input.
 	a.mainFlex.RemoveItem(a.filterInput)
 	a.mainFlex.AddItem(a.footer, 1, 0, false)
-	a.app.SetFocus(a.table)
+	a.app.SetFocus(a.mainView())
 }
 
 // ---------------------------------------------------------------------------
@@ -724,26 +892,27 @@ func (a *App) hideFilter() {
 // ---------------------------------------------------------------------------
 
 func (a *App) confirmDelete() {
-	row, _ := a.table.GetSelection()
-	if row < 1 || row >= a.table.GetRowCount() {
+	name, project, ok := a.selected()
+	if !ok {
 		return
 	}
+	a.confirm(fmt.Sprintf("Delete %s \"%s\"?", a.currentView[:len(a.currentView)-1], name), "Delete", func() {
+		a.deleteResource(name, project)
+	})
+}
 
-	name := a.table.GetCell(row, 0).Text
-	project := ""
-	if a.currentView != "projects" && a.table.GetColumnCount() > 1 {
-		project = a.table.GetCell(row, 1).Text
-	}
-
+// confirm asks text with an action and a Cancel button and runs do when
+// the action is chosen.
+func (a *App) confirm(text, action string, do func()) {
 	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Delete %s \"%s\"?", a.currentView[:len(a.currentView)-1], name)).
-		AddButtons([]string{"Delete", "Cancel"}).
+		SetText(text).
+		AddButtons([]string{action, "Cancel"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Delete" {
-				a.deleteResource(name, project)
-			}
 			a.pages.RemovePage("confirm")
-			a.app.SetFocus(a.table)
+			a.app.SetFocus(a.mainView())
+			if buttonLabel == action {
+				do()
+			}
 		})
 	modal.SetBackgroundColor(tcell.ColorDarkRed)
 
@@ -768,18 +937,16 @@ func (a *App) deleteResource(name, project string) {
 	}
 
 	if err != nil {
-		// Show error briefly in footer or just log it.
-		a.footer.SetText(fmt.Sprintf(" [red]Delete failed: %v[-]", err))
-		go func() {
-			time.Sleep(3 * time.Second)
-			a.app.QueueUpdateDraw(func() {
-				a.updateFooter()
-			})
-		}()
+		a.flashError(fmt.Sprintf("Delete failed: %v", err))
 		return
 	}
 
 	// Refresh immediately after delete.
+	a.refreshNow()
+}
+
+// refreshNow lists the current view again and redraws it.
+func (a *App) refreshNow() {
 	go func() {
 		a.refresh()
 		a.app.QueueUpdateDraw(func() {
@@ -788,6 +955,42 @@ func (a *App) deleteResource(name, project string) {
 	}()
 }
 
+// ---------------------------------------------------------------------------
+// Task actions
+// ---------------------------------------------------------------------------
+
+// confirmRetry asks to run the selected Failed task again.
+func (a *App) confirmRetry() {
+	name, project, ok := a.selected()
+	if !ok {
+		return
+	}
+	a.confirm(fmt.Sprintf("Retry task \"%s\"?", name), "Retry", func() {
+		if _, err := a.client.RetryDevTask(name, project); err != nil {
+			a.flashError(fmt.Sprintf("Retry failed: %v", err))
+			return
+		}
+		a.flash(fmt.Sprintf("Task %s reset to Pending", name))
+		a.refreshNow()
+	})
+}
+
+// confirmCancel asks to cancel the selected unfinished task.
+func (a *App) confirmCancel() {
+	name, project, ok := a.selected()
+	if !ok {
+		return
+	}
+	a.confirm(fmt.Sprintf("Cancel task \"%s\"?", name), "Cancel Task", func() {
+		if _, err := a.client.CancelDevTask(name, project); err != nil {
+			a.flashError(fmt.Sprintf("Cancel failed: %v", err))
+			return
+		}
+		a.flash(fmt.Sprintf("Task %s cancelled", name))
+		a.refreshNow()
+	})
+}
+
 // ---------------------------------------------------------------------------
 // Header & Footer
 // ---------------------------------------------------------------------------
@@ -801,6 +1004,8 @@ func (a *App) updateHeader() {
 		{"2", "Pools"},
 		{"3", "Tasks"},
 		{"4", "Projects"},
+		{"5", "XRay"},
+		{"0", "Metrics"},
 	}
 
 	viewMap := map[string]string{
@@ -808,6 +1013,8 @@ func (a *App) updateHeader() {
 		"2": "pools",
 		"3": "tasks",
 		"4": "projects",
+		"5": "xray",
+		"0": "metrics",
 	}
 
 	var parts []string
@@ -821,17 +1028,81 @@ func (a *App) updateHeader() {
 
 	filterInfo := ""
 	a.mu.Lock()
+	server := a.serverAddr
+	if a.contextName != "" {
+		server = fmt.Sprintf("[%s]%s[-] (%s)", tag(a.skin.Keys), tview.Escape(a.contextName), a.serverAddr)
+	}
+	if a.currentProject != "" {
+		filterInfo = fmt.Sprintf(" | project: %s", tview.Escape(a.currentProject))
+	}
 	if a.filter != "" {
-		filterInfo = fmt.Sprintf(" | [yellow]filter: %s[-]", a.filter)
+		filterInfo += fmt.Sprintf(" | [yellow]filter: %s[-]", a.filter)
 	}
+	refresh := fmt.Sprintf("refresh %s", a.interval)
 	a.mu.Unlock()
+	if a.paused {
+		refresh = "[red::b]PAUSED[-::-]"
+	}
+	filterInfo += " | " + refresh
 
 	a.header.SetText(fmt.Sprintf(" [::b]Orca[::-] | %s | %s%s",
-		a.serverAddr, strings.Join(parts, "  "), filterInfo))
+		server, strings.Join(parts, "  "), filterInfo))
+}
+
+// flash shows msg in the footer for a few seconds.
+func (a *App) flash(msg string) {
+	a.flashColored("green", msg)
+}
+
+// flashError shows msg in red in the footer for a few seconds.
+func (a *App) flashError(msg string) {
+	a.flashColored("red", msg)
+}
+
+func (a *App) flashColored(tagColor, msg string) {
+	a.footer.SetText(fmt.Sprintf(" [%s]%s[-]", tagColor, tview.Escape(msg)))
+	go func() {
+		time.Sleep(3 * time.Second)
+		a.app.QueueUpdateDraw(func() {
+			a.updateFooter()
+		})
+	}()
 }
 
 func (a *App) updateFooter() {
-	a.footer.SetText(" [yellow]<enter>[white]Describe  [yellow]<d>[white]Delete  [yellow]</>[white]Filter  [yellow]<q>[white]Quit  [yellow]<r>[white]Refresh  [yellow]<esc>[white]Back")
+	var hints []string
+	switch {
+	case a.streamOpen:
+		follow := "on"
+		if !a.streamFollow {
+			follow = "off"
+		}
+		hints = []string{"s", fmt.Sprintf("Autoscroll (%s)", follow), "w", "Wrap", "c", "Clear", "esc", "Back"}
+	case a.currentView == "metrics":
+		hints = []string{"n", "New Task", ":", "Command", "q", "Quit", "r", "Refresh", "p", "Pause", "+/-", "Interval"}
+	case a.currentView == "xray":
+		hints = []string{"enter", "Expand/Collapse", "n", "New Task", "/", "Filter", ":", "Command", "q", "Quit", "r", "Refresh", "p", "Pause", "+/-", "Interval", "esc", "Back"}
+	default:
+		hints = []string{"enter", "Describe"}
+		switch a.currentView {
+		case "pods":
+			hints = append(hints, "l", "Logs", "x", "Exec")
+		case "tasks":
+			hints = append(hints, "o", "Output", "r", "Retry", "c", "Cancel")
+		}
+		hints = append(hints, "n", "New Task", "d", "Delete", "/", "Filter", ":", "Command")
+		if a.currentView != "tasks" {
+			hints = append(hints, "c", "Context")
+		}
+		hints = append(hints, "N/A/P", "Sort", "q", "Quit")
+		if a.currentView == "tasks" {
+			hints = append(hints, "ctrl-r", "Refresh")
+		} else {
+			hints = append(hints, "r", "Refresh")
+		}
+		hints = append(hints, "p", "Pause", "+/-", "Interval", "esc", "Back")
+	}
+	a.footer.SetText(" " + a.skin.hints(a.skin.Footer, hints...))
 }
 
 // ---------------------------------------------------------------------------
@@ -856,39 +1127,3 @@ func formatAge(t time.Time) string {
 		return fmt.Sprintf("%dd", int(d.Hours()/24))
 	}
 }
-
-// phaseColor returns the tcell color appropriate for a phase string.
-func phaseColor(phase string) tcell.Color {
-	switch phase {
-	case "Ready", "Succeeded":
-		return tcell.ColorGreen
-	case "Running", "Busy", "Starting":
-		return tcell.ColorYellow
-	case "Pending", "Scheduled":
-		return tcell.ColorWhite
-	case "Failed":
-		return tcell.ColorRed
-	case "Terminating", "Terminated":
-		return tcell.ColorGray
-	default:
-		return tcell.ColorWhite
-	}
-}
-
-// phaseColorName returns the tview color tag name for a phase string.
-func phaseColorName(phase string) string {
-	switch phase {
-	case "Ready", "Succeeded":
-		return "green"
-	case "Running", "Busy", "Starting":
-		return "yellow"
-	case "Pending", "Scheduled":
-		return "white"
-	case "Failed":
-		return "red"
-	case "Terminating", "Terminated":
-		return "gray"
-	default:
-		return "white"
-	}
-}
@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/klubi/orca/pkg/manifest"
+)
+
+// commands maps the names accepted by the command bar to their handlers,
+// which get the words following the name.
+var commands = map[string]func(a *App, args []string){
+	"pods":     viewCommand("pods"),
+	"pools":    viewCommand("pools"),
+	"tasks":    viewCommand("tasks"),
+	"projects": viewCommand("projects"),
+	"xray":     viewCommand("xray"),
+	"metrics":  viewCommand("metrics"),
+	"apply":    (*App).applyCommand,
+	"scale":    (*App).scaleCommand,
+	"ctx":      (*App).contextCommand,
+	"quit":     (*App).quitCommand,
+	"q":        (*App).quitCommand,
+}
+
+// commandNames lists the commands for completion.
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		if len(name) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newCommandInput creates the command bar. Enter runs the command, Escape
+// just closes the bar.
+func (a *App) newCommandInput() *tview.InputField {
+	input := tview.NewInputField().
+		SetLabel(" :").
+		SetFieldBackgroundColor(color(a.skin.Background)).
+		SetLabelColor(color(a.skin.Keys))
+	// Complete the command name while it is the only word.
+	input.SetAutocompleteFunc(func(text string) []string {
+		if text == "" || strings.Contains(text, " ") {
+			return nil
+		}
+		var entries []string
+		for _, name := range commandNames() {
+			if strings.HasPrefix(name, text) && name != text {
+				entries = append(entries, name)
+			}
+		}
+		return entries
+	})
+	input.SetDoneFunc(func(key tcell.Key) {
+		line := input.GetText()
+		a.hideCommand()
+		if key == tcell.KeyEnter {
+			a.runCommand(line)
+		}
+	})
+	return input
+}
+
+// showCommand replaces the footer with the command bar, like the filter.
+func (a *App) showCommand() {
+	if a.commandOpen {
+		return
+	}
+	a.commandOpen = true
+	a.commandInput.SetText("")
+
+	a.mainFlex.RemoveItem(a.footer)
+	a.mainFlex.AddItem(a.commandInput, 1, 0, true)
+	a.app.SetFocus(a.commandInput)
+}
+
+func (a *App) hideCommand() {
+	if !a.commandOpen {
+		return
+	}
+	a.commandOpen = false
+
+	a.mainFlex.RemoveItem(a.commandInput)
+	a.mainFlex.AddItem(a.footer, 1, 0, false)
+	a.app.SetFocus(a.mainView())
+}
+
+// runCommand runs a line entered in the command bar.
+func (a *App) runCommand(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	run, ok := commands[fields[0]]
+	if !ok {
+		a.flashError(fmt.Sprintf("Unknown command %q", fields[0]))
+		return
+	}
+	run(a, fields[1:])
+}
+
+// viewCommand returns the handler of :<view> [project], which switches to
+// view, showing only the resources of project when one is given or those of
+// all projects for "-A".
+func viewCommand(view string) func(a *App, args []string) {
+	return func(a *App, args []string) {
+		if len(args) > 1 {
+			a.flashError(fmt.Sprintf("Usage: :%s [project|-A]", view))
+			return
+		}
+		if len(args) == 1 {
+			project := args[0]
+			if project == "-A" {
+				project = ""
+			}
+			a.mu.Lock()
+			a.currentProject = project
+			a.mu.Unlock()
+		}
+		a.switchView(view)
+	}
+}
+
+// scaleCommand sets the replicas of a pool, like `orca scale`. The pool is
+// looked up in the project shown, or the default project when all are.
+func (a *App) scaleCommand(args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		a.flashError("Usage: :scale <pool> <replicas> [project]")
+		return
+	}
+	replicas, err := strconv.Atoi(args[1])
+	if err != nil || replicas < 0 {
+		a.flashError(fmt.Sprintf("Invalid replica count %q", args[1]))
+		return
+	}
+	a.mu.Lock()
+	project := a.currentProject
+	a.mu.Unlock()
+	if len(args) == 3 {
+		project = args[2]
+	}
+	if project == "" {
+		project = "default"
+	}
+
+	if _, err := a.client.ScaleAgentPool(args[0], project, replicas); err != nil {
+		a.flashError(fmt.Sprintf("Scaling failed: %v", err))
+		return
+	}
+	a.flash(fmt.Sprintf("Scaled pool %s/%s to %d replicas", project, args[0], replicas))
+}
+
+func (a *App) quitCommand([]string) {
+	a.app.Stop()
+}
+
+// applyCommand applies the manifests in a file or directory, like
+// `orca apply -f`, and lists the result for each resource.
+func (a *App) applyCommand(args []string) {
+	if len(args) != 1 {
+		a.flashError("Usage: :apply <file or directory>")
+		return
+	}
+	path := args[0]
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+
+	a.showStream("Apply: "+path, func(ctx context.Context, w io.Writer) error {
+		resources, err := loadManifests(path)
+		if err != nil {
+			return err
+		}
+		if len(resources) == 0 {
+			fmt.Fprintln(w, "[gray]No resources found in manifest.[-]")
+			return nil
+		}
+
+		failed := 0
+		for _, resource := range resources {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			kind, name := manifest.Identity(resource)
+			id := tview.Escape(kind + "/" + name)
+			created, err := a.client.ApplyInto(resource, nil, false)
+			if err != nil {
+				failed++
+				fmt.Fprintf(w, "[red]%s failed: %s[-]\n", id, tview.Escape(err.Error()))
+				continue
+			}
+			verb := "configured"
+			if created {
+				verb = "created"
+			}
+			fmt.Fprintf(w, "[green]%s %s[-]\n", id, verb)
+		}
+
+		fmt.Fprintln(w)
+		if failed > 0 {
+			fmt.Fprintf(w, "[red::b]%d of %d resources failed[-::-]\n", failed, len(resources))
+		} else {
+			fmt.Fprintf(w, "[green::b]%d resources applied[-::-]\n", len(resources))
+		}
+		return nil
+	})
+}
+
+// loadManifests parses the overlay or manifest file at path, or every
+// manifest file directly in it when path is a directory. Resources are
+// returned in dependency order.
+func loadManifests(path string) ([]interface{}, error) {
+	resources, err := manifest.ParseFiles([]string{path})
+	if err != nil {
+		return nil, err
+	}
+	manifest.SortByDependency(resources)
+	return resources, nil
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/pkg/client"
+)
+
+// showContexts lists the contexts of the CLI config to switch to, like
+// `orca config get-contexts`.
+func (a *App) showContexts() {
+	cfg, err := config.LoadClientConfig(config.DefaultClientConfigPath())
+	if err != nil {
+		a.flashError(err.Error())
+		return
+	}
+	if len(cfg.Contexts) == 0 {
+		a.flashError("No contexts; add one with `orca config set-context`")
+		return
+	}
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+	width := 0
+	for _, ctx := range cfg.Contexts {
+		marker := "  "
+		if ctx.Name == a.contextName {
+			marker = "* "
+		}
+		text := fmt.Sprintf("%s%s  [gray]%s[-]", marker, tview.Escape(ctx.Name), tview.Escape(ctx.Server))
+		width = max(width, tview.TaggedStringWidth(text))
+		list.AddItem(text, "", 0, func() {
+			a.hideForm()
+			a.switchContext(ctx)
+		})
+		if ctx.Name == a.contextName {
+			list.SetCurrentItem(list.GetItemCount() - 1)
+		}
+	}
+	list.SetDoneFunc(a.hideForm)
+	list.SetBorder(true).
+		SetTitle(" Contexts ").
+		SetBorderColor(color(a.skin.Border))
+	a.showModal(list, max(width+4, 40), len(cfg.Contexts)+2)
+}
+
+// contextCommand switches to the context named by :ctx <name>, or lists the
+// contexts for :ctx.
+func (a *App) contextCommand(args []string) {
+	if len(args) == 0 {
+		a.showContexts()
+		return
+	}
+	if len(args) > 1 {
+		a.flashError("Usage: :ctx [name]")
+		return
+	}
+	cfg, err := config.LoadClientConfig(config.DefaultClientConfigPath())
+	if err != nil {
+		a.flashError(err.Error())
+		return
+	}
+	ctx := cfg.Context(args[0])
+	if ctx == nil {
+		a.flashError(fmt.Sprintf("Context %q not found", args[0]))
+		return
+	}
+	a.switchContext(*ctx)
+}
+
+// switchContext connects to the server of ctx and shows the current view
+// there, for all projects. The CLI's current context is left alone.
+func (a *App) switchContext(ctx config.Context) {
+	server := ctx.Server
+	if server == "" {
+		server = a.serverAddr
+	}
+	opts, err := ctx.ClientOptions()
+	if err != nil {
+		a.flashError(err.Error())
+		return
+	}
+	c := client.New(server, opts...)
+
+	a.mu.Lock()
+	a.client = c
+	a.serverAddr = server
+	a.contextName = ctx.Name
+	a.currentProject = ""
+	a.pods, a.pools, a.tasks, a.projects = nil, nil, nil, nil
+	a.lastErr = nil
+	view := a.currentView
+	a.mu.Unlock()
+
+	a.switchView(view)
+	a.flash(fmt.Sprintf("Switched to context %s (%s)", ctx.Name, server))
+}
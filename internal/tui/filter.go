@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/rivo/tview"
+)
+
+// filterTerm is one word of the filter. It matches fuzzily, see fuzzyMatch,
+// against any column, or only against column when written as
+// column:pattern, e.g. phase:Failed.
+type filterTerm struct {
+	column  string
+	pattern string
+}
+
+// parseFilter splits filter into its terms. Column names are matched
+// case-insensitively.
+func parseFilter(filter string) []filterTerm {
+	var terms []filterTerm
+	for _, word := range strings.Fields(filter) {
+		column, pattern, ok := strings.Cut(word, ":")
+		if !ok || !isColumnName(column) {
+			column, pattern = "", word
+		}
+		terms = append(terms, filterTerm{column: strings.ToUpper(column), pattern: pattern})
+	}
+	return terms
+}
+
+// isColumnName reports whether s can be a column name such as ASSIGNED-POD.
+func isColumnName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// filterTable removes the rows that do not match every term of the filter
+// and highlights the matches in the rows left. A term for a column the view
+// does not have matches nothing.
+func (a *App) filterTable(filter string) {
+	terms := parseFilter(filter)
+	if len(terms) == 0 {
+		return
+	}
+	for r := a.table.GetRowCount() - 1; r >= 1; r-- {
+		matches := make(map[int][]int) // column -> matched rune positions
+		ok := true
+		for _, t := range terms {
+			if !a.matchTerm(r, t, matches) {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			a.table.RemoveRow(r)
+			continue
+		}
+		for col, positions := range matches {
+			cell := a.table.GetCell(r, col)
+			text := cellText(cell)
+			if cell.GetReference() == nil {
+				cell.SetReference(text)
+			}
+			cell.SetText(highlight(text, positions, a.skin.Keys))
+		}
+	}
+}
+
+// matchTerm matches t against the cells of row and adds the positions it
+// matched at to matches.
+func (a *App) matchTerm(row int, t filterTerm, matches map[int][]int) bool {
+	for col, h := range a.headers {
+		if t.column != "" && t.column != h {
+			continue
+		}
+		if positions, ok := fuzzyMatch(cellText(a.table.GetCell(row, col)), t.pattern); ok {
+			matches[col] = append(matches[col], positions...)
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFilter reports whether every term of filter matches one of the
+// values. Column names in terms are ignored.
+func matchesFilter(filter string, values ...string) bool {
+	for _, t := range parseFilter(filter) {
+		found := false
+		for _, v := range values {
+			if _, ok := fuzzyMatch(v, t.pattern); ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyMatch reports whether the runes of pattern appear in text in order,
+// ignoring case, and returns the positions of the runes of text it matched.
+// A contiguous match is preferred over a scattered one.
+func fuzzyMatch(text, pattern string) ([]int, bool) {
+	t := []rune(strings.ToLower(text))
+	p := []rune(strings.ToLower(pattern))
+	if len(p) == 0 {
+		return nil, true
+	}
+
+	for i := 0; i+len(p) <= len(t); i++ {
+		if string(t[i:i+len(p)]) == string(p) {
+			positions := make([]int, len(p))
+			for j := range p {
+				positions[j] = i + j
+			}
+			return positions, true
+		}
+	}
+
+	positions := make([]int, 0, len(p))
+	for i := 0; i < len(t) && len(positions) < len(p); i++ {
+		if t[i] == p[len(positions)] {
+			positions = append(positions, i)
+		}
+	}
+	return positions, len(positions) == len(p)
+}
+
+// highlight returns text with the runes at positions shown bold in
+// tagColor, escaped for a tview cell.
+func highlight(text string, positions []int, tagColor string) string {
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && marked[j] == marked[i] {
+			j++
+		}
+		run := tview.Escape(string(runes[i:j]))
+		if marked[i] {
+			run = "[" + tag(tagColor) + "::b]" + run + "[-::-]"
+		}
+		b.WriteString(run)
+		i = j
+	}
+	return b.String()
+}
+
+// cellText returns the plain text of a cell, before any highlighting.
+func cellText(cell *tview.TableCell) string {
+	if s, ok := cell.GetReference().(string); ok {
+		return s
+	}
+	return cell.Text
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rivo/tview"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+)
+
+// logsTail is the number of past entries the log pane starts with.
+const logsTail = 200
+
+// showLogs opens a stream pane following the logs of the selected pod.
+func (a *App) showLogs() {
+	name, project, ok := a.selected()
+	if !ok {
+		return
+	}
+	a.showStream(fmt.Sprintf("Logs: %s/%s", project, name), func(ctx context.Context, w io.Writer) error {
+		entries, err := a.client.StreamLogEntries(ctx, name, project, client.LogOptions{Tail: logsTail})
+		if err != nil {
+			return err
+		}
+		for entry := range entries {
+			fmt.Fprintln(w, formatLogEntry(entry))
+		}
+		return nil
+	})
+}
+
+// formatLogEntry renders a log entry as a line of the log pane.
+func formatLogEntry(entry v1alpha1.LogEntry) string {
+	var color string
+	switch entry.Level {
+	case "ERROR", "error":
+		color = "red"
+	case "WARN", "warn":
+		color = "yellow"
+	case "INFO", "info":
+		color = "green"
+	default:
+		color = "gray"
+	}
+	return fmt.Sprintf("[gray]%s[-] [%s]%-5s[-] %s",
+		entry.Timestamp.Format("15:04:05"), color, entry.Level, tview.Escape(entry.Message))
+}
@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// metricsWindow is how many minutes the metrics view charts.
+const metricsWindow = 60
+
+// sparks are the bar heights of a sparkline, lowest first.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// replicaSample is the number of ready and busy pods seen in a minute.
+type replicaSample struct {
+	ready, busy int
+}
+
+// newMetricsView creates the text view of the metrics dashboard.
+func newMetricsView() *tview.TextView {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false)
+	view.SetBorderPadding(1, 0, 1, 1)
+	return view
+}
+
+// tickMetrics redraws the metrics view every 10s until ctx is cancelled, so
+// the charts move on in minutes without changes.
+func (a *App) tickMetrics(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.app.QueueUpdateDraw(a.updateTable)
+		}
+	}
+}
+
+// updateMetrics redraws the metrics dashboard: the totals `orca status`
+// prints and, per minute over the last hour, the tasks finished, their
+// tokens and cost and the ready and busy pods. Task charts come from the
+// tasks' finish times; pod charts from what the view has seen while open.
+func (a *App) updateMetrics() {
+	a.headers = nil
+
+	a.mu.Lock()
+	pools, pods, tasks := a.pools, a.pods, a.tasks
+	err := a.lastErr
+	a.mu.Unlock()
+
+	if err != nil {
+		a.metricsView.SetText(fmt.Sprintf("[red]Error: %s[-]", tview.Escape(err.Error())))
+		return
+	}
+
+	now := time.Now().Truncate(time.Minute)
+	minute := func(t time.Time) int {
+		return metricsWindow - 1 - int(now.Sub(t.Truncate(time.Minute))/time.Minute)
+	}
+
+	var ready, busy, pending, failedPods int
+	for _, p := range pods {
+		switch p.Status.Phase {
+		case v1alpha1.PodReady:
+			ready++
+		case v1alpha1.PodBusy:
+			busy++
+		case v1alpha1.PodPending, v1alpha1.PodStarting:
+			pending++
+		case v1alpha1.PodFailed:
+			failedPods++
+		}
+	}
+	a.replicaHistory[now] = replicaSample{ready: ready, busy: busy}
+	readySeries := make([]float64, metricsWindow)
+	busySeries := make([]float64, metricsWindow)
+	for t, s := range a.replicaHistory {
+		i := minute(t)
+		if i < 0 {
+			delete(a.replicaHistory, t)
+			continue
+		}
+		readySeries[i], busySeries[i] = float64(s.ready), float64(s.busy)
+	}
+
+	var (
+		taskCounts                = map[v1alpha1.DevTaskPhase]int{}
+		succeeded, failed         = make([]float64, metricsWindow), make([]float64, metricsWindow)
+		tokens, cost              = make([]float64, metricsWindow), make([]float64, metricsWindow)
+		totalTokens, totalCost    float64
+		hourTokens, hourCost      float64
+		hourSucceeded, hourFailed int
+	)
+	for _, t := range tasks {
+		taskCounts[t.Status.Phase]++
+		used := float64(t.Status.TokensIn + t.Status.TokensOut)
+		totalTokens += used
+		totalCost += t.Status.CostUSD
+
+		i := minute(t.Status.FinishedAt)
+		if t.Status.FinishedAt.IsZero() || i < 0 || i >= metricsWindow {
+			continue
+		}
+		switch t.Status.Phase {
+		case v1alpha1.TaskSucceeded:
+			succeeded[i]++
+			hourSucceeded++
+		case v1alpha1.TaskFailed:
+			failed[i]++
+			hourFailed++
+		}
+		tokens[i] += used
+		cost[i] += t.Status.CostUSD
+		hourTokens += used
+		hourCost += t.Status.CostUSD
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[::b]Pods[::-]   %d total: [%s]%d ready[-], [%s]%d busy[-], %d pending, [%s]%d failed[-]\n",
+		len(pods), a.skin.phaseColorName("Ready"), ready, a.skin.phaseColorName("Busy"), busy, pending, a.skin.phaseColorName("Failed"), failedPods)
+	fmt.Fprintf(&b, "[::b]Pools[::-]  %d\n", len(pools))
+	fmt.Fprintf(&b, "[::b]Tasks[::-]  %d total: %d pending, [%s]%d running[-], [%s]%d succeeded[-], [%s]%d failed[-]\n",
+		len(tasks), taskCounts[v1alpha1.TaskPending], a.skin.phaseColorName("Running"), taskCounts[v1alpha1.TaskRunning],
+		a.skin.phaseColorName("Succeeded"), taskCounts[v1alpha1.TaskSucceeded], a.skin.phaseColorName("Failed"), taskCounts[v1alpha1.TaskFailed])
+	fmt.Fprintf(&b, "[::b]Usage[::-]  %s tokens, $%.4f\n\n", formatCount(totalTokens), totalCost)
+
+	fmt.Fprintf(&b, "[::b]Last %d minutes[::-]\n\n", metricsWindow)
+	chart := func(label, color string, series []float64, total string) {
+		fmt.Fprintf(&b, "%-20s [%s]%s[-]  %s\n", label, color, sparkline(series), total)
+	}
+	chart("Tasks succeeded/min", a.skin.phaseColorName("Succeeded"), succeeded, fmt.Sprintf("%d", hourSucceeded))
+	chart("Tasks failed/min", a.skin.phaseColorName("Failed"), failed, fmt.Sprintf("%d", hourFailed))
+	chart("Tokens/min", a.skin.phaseColorName("Running"), tokens, formatCount(hourTokens))
+	chart("Cost/min", a.skin.phaseColorName("Running"), cost, fmt.Sprintf("$%.4f", hourCost))
+	chart("Ready pods", a.skin.phaseColorName("Ready"), readySeries, fmt.Sprintf("%d now", ready))
+	chart("Busy pods", a.skin.phaseColorName("Busy"), busySeries, fmt.Sprintf("%d now", busy))
+
+	a.metricsView.SetText(b.String())
+}
+
+// sparkline draws values as one bar per value, scaled to the largest.
+func sparkline(values []float64) string {
+	top := 0.0
+	for _, v := range values {
+		top = math.Max(top, v)
+	}
+	out := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if top > 0 {
+			level = int(math.Ceil(v / top * float64(len(sparks)-1)))
+		}
+		out[i] = sparks[level]
+	}
+	return string(out)
+}
+
+// formatCount abbreviates large counts, e.g. 12.3k.
+func formatCount(n float64) string {
+	switch {
+	case n >= 1e6:
+		return fmt.Sprintf("%.1fM", n/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1fk", n/1e3)
+	default:
+		return fmt.Sprintf("%.0f", n)
+	}
+}
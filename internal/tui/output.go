@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// outputPane is the full-screen viewer for a task's output and error.
+type outputPane struct {
+	task    *v1alpha1.DevTask
+	view    *tview.TextView
+	status  *tview.TextView
+	search  *tview.InputField
+	layout  *tview.Flex
+	query   string
+	matches int // number of matches of query
+	current int // index of the highlighted match
+
+	// searching is set while the search input has focus; nowrap when
+	// long lines are cut off rather than wrapped.
+	searching bool
+	nowrap    bool
+}
+
+// showOutput opens the output viewer for the selected task.
+func (a *App) showOutput() {
+	name, project, ok := a.selected()
+	if !ok {
+		return
+	}
+	task, err := a.client.GetDevTask(name, project)
+	if err != nil {
+		a.flashError(fmt.Sprintf("Getting task failed: %v", err))
+		return
+	}
+
+	p := &outputPane{task: task}
+	p.view = tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true).
+		SetScrollable(true).
+		SetWrap(true)
+	p.view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Output: %s/%s (%s) ", project, name, task.Status.Phase)).
+		SetBorderColor(color(a.skin.Border))
+	p.status = tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextColor(color(a.skin.Footer.Fg))
+	p.status.SetBackgroundColor(color(a.skin.Footer.Bg))
+	p.search = tview.NewInputField().
+		SetLabel(" Search: ").
+		SetFieldBackgroundColor(color(a.skin.Background)).
+		SetLabelColor(color(a.skin.Keys))
+	p.search.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			a.searchOutput(p.search.GetText())
+		}
+		a.hideOutputSearch()
+	})
+	p.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(p.view, 0, 1, true).
+		AddItem(p.status, 1, 0, false)
+
+	a.output = p
+	a.renderOutput()
+	a.pages.AddPage("output", p.layout, true, true)
+	a.app.SetFocus(p.view)
+}
+
+// hideOutput closes the output viewer.
+func (a *App) hideOutput() {
+	if a.output == nil {
+		return
+	}
+	a.output = nil
+	a.pages.RemovePage("output")
+	a.app.SetFocus(a.mainView())
+}
+
+// handleOutputKey handles a key press while the output viewer is open. Keys
+// it does not use go to the viewer itself for scrolling.
+func (a *App) handleOutputKey(event *tcell.EventKey) *tcell.EventKey {
+	p := a.output
+	if p.searching {
+		return event
+	}
+	switch event.Key() {
+	case tcell.KeyEscape:
+		if p.query != "" {
+			a.searchOutput("")
+			return nil
+		}
+		a.hideOutput()
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'q':
+			a.hideOutput()
+			return nil
+		case '/':
+			a.showOutputSearch()
+			return nil
+		case 'n':
+			a.nextMatch(1)
+			return nil
+		case 'N':
+			a.nextMatch(-1)
+			return nil
+		case 'w':
+			p.wrap()
+			return nil
+		}
+	}
+	return event
+}
+
+func (a *App) showOutputSearch() {
+	p := a.output
+	p.searching = true
+	p.search.SetText(p.query)
+	p.layout.RemoveItem(p.status)
+	p.layout.AddItem(p.search, 1, 0, true)
+	a.app.SetFocus(p.search)
+}
+
+func (a *App) hideOutputSearch() {
+	p := a.output
+	p.searching = false
+	p.layout.RemoveItem(p.search)
+	p.layout.AddItem(p.status, 1, 0, false)
+	a.app.SetFocus(p.view)
+}
+
+// searchOutput highlights the matches of query and scrolls to the first.
+func (a *App) searchOutput(query string) {
+	p := a.output
+	p.query = query
+	p.current = 0
+	a.renderOutput()
+	if p.matches > 0 {
+		p.view.Highlight("0").ScrollToHighlight()
+	} else {
+		p.view.Highlight()
+	}
+	a.updateOutputStatus()
+}
+
+// nextMatch moves the highlight delta matches forward, wrapping around.
+func (a *App) nextMatch(delta int) {
+	p := a.output
+	if p.matches == 0 {
+		return
+	}
+	p.current = (p.current + delta + p.matches) % p.matches
+	p.view.Highlight(fmt.Sprint(p.current)).ScrollToHighlight()
+	a.updateOutputStatus()
+}
+
+// renderOutput fills the viewer with the task's output and error, marking
+// the matches of the search query as regions.
+func (a *App) renderOutput() {
+	p := a.output
+	var b strings.Builder
+	p.matches = 0
+	if p.task.Status.Output == "" && p.task.Status.Error == "" {
+		b.WriteString("[gray]No output yet.[-]")
+	}
+	if p.task.Status.Output != "" {
+		p.matches = markMatches(&b, p.task.Status.Output, p.query, p.matches)
+	}
+	if p.task.Status.Error != "" {
+		if p.task.Status.Output != "" {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("[red::b]Error:[-::-]\n")
+		p.matches = markMatches(&b, p.task.Status.Error, p.query, p.matches)
+	}
+	p.view.SetText(b.String())
+	a.updateOutputStatus()
+}
+
+func (a *App) updateOutputStatus() {
+	p := a.output
+	search := ""
+	switch {
+	case p.query != "" && p.matches == 0:
+		search = fmt.Sprintf("  [red]no match for %q[-]", tview.Escape(p.query))
+	case p.query != "":
+		search = fmt.Sprintf("  [yellow]match %d/%d[-]", p.current+1, p.matches)
+	}
+	p.status.SetText(" " + a.skin.hints(a.skin.Footer, "/", "Search", "n/N", "Next/Prev", "w", "Wrap", "g/G", "Top/Bottom", "esc", "Back") + search)
+}
+
+func (p *outputPane) wrap() {
+	p.nowrap = !p.nowrap
+	p.view.SetWrap(!p.nowrap)
+}
+
+// markMatches writes text to b with tview tags escaped and each
+// case-insensitive match of query wrapped in a region numbered from first
+// on. It returns the number of the next region.
+func markMatches(b *strings.Builder, text, query string, first int) int {
+	n := first
+	if query == "" {
+		b.WriteString(tview.Escape(text))
+		return n
+	}
+	haystack, needle := strings.ToLower(text), strings.ToLower(query)
+	if len(haystack) != len(text) || len(needle) != len(query) {
+		// Lowercasing changed byte offsets; fall back to an exact search.
+		haystack, needle = text, query
+	}
+	for {
+		i := strings.Index(haystack, needle)
+		if i < 0 {
+			break
+		}
+		b.WriteString(tview.Escape(text[:i]))
+		fmt.Fprintf(b, `["%d"]%s[""]`, n, tview.Escape(text[i:i+len(needle)]))
+		n++
+		text, haystack = text[i+len(needle):], haystack[i+len(needle):]
+	}
+	b.WriteString(tview.Escape(text))
+	return n
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// showForm shows form centered over the main view. Escape closes it.
+func (a *App) showForm(form *tview.Form, width, height int) {
+	form.SetBorder(true).SetBorderColor(color(a.skin.Border))
+	form.SetCancelFunc(a.hideForm)
+	a.showModal(form, width, height)
+}
+
+// showModal shows p centered over the main view until hideForm. p gets all
+// keys, so it must call hideForm itself.
+func (a *App) showModal(p tview.Primitive, width, height int) {
+	// Spacers on all sides center the modal.
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	a.formOpen = true
+	a.pages.AddPage("form", modal, true, true)
+	a.app.SetFocus(p)
+}
+
+func (a *App) hideForm() {
+	if !a.formOpen {
+		return
+	}
+	a.formOpen = false
+	a.pages.RemovePage("form")
+	a.app.SetFocus(a.mainView())
+}
+
+// showExecForm asks for a prompt to run on the selected pod, like
+// `orca exec`.
+func (a *App) showExecForm() {
+	name, project, ok := a.selected()
+	if !ok {
+		return
+	}
+
+	form := tview.NewForm().
+		AddInputField("Prompt", "", 70, nil, nil)
+	form.AddButton("Run", func() {
+		prompt := strings.TrimSpace(form.GetFormItemByLabel("Prompt").(*tview.InputField).GetText())
+		if prompt == "" {
+			return
+		}
+		a.hideForm()
+
+		pod, err := a.client.GetAgentPod(name, project)
+		if err != nil {
+			a.flashError(fmt.Sprintf("Getting pod failed: %v", err))
+			return
+		}
+		a.submitTask(&v1alpha1.DevTask{
+			Metadata: v1alpha1.ObjectMeta{
+				Name:    fmt.Sprintf("exec-%s-%d", pod.Metadata.Name, time.Now().UnixMilli()),
+				Project: pod.Metadata.Project,
+			},
+			Spec: v1alpha1.DevTaskSpec{
+				Prompt:         prompt,
+				PreferredModel: pod.Spec.Model,
+				Pod:            pod.Metadata.Name,
+			},
+		})
+	})
+	form.AddButton("Cancel", a.hideForm)
+	form.SetTitle(fmt.Sprintf(" Exec on %s/%s ", project, name))
+	a.showForm(form, 90, 7)
+}
+
+// showNewTaskForm asks for a prompt and the placement of a new task, like
+// `orca run`.
+func (a *App) showNewTaskForm() {
+	a.mu.Lock()
+	project := a.currentProject
+	a.mu.Unlock()
+	if project == "" {
+		project = "default"
+	}
+
+	form := tview.NewForm().
+		AddInputField("Prompt", "", 70, nil, nil).
+		AddInputField("Project", project, 30, nil, nil).
+		AddInputField("Pool", "", 30, nil, nil).
+		AddInputField("Model", "", 30, nil, nil)
+	text := func(label string) string {
+		return strings.TrimSpace(form.GetFormItemByLabel(label).(*tview.InputField).GetText())
+	}
+	form.AddButton("Run", func() {
+		if text("Prompt") == "" || text("Project") == "" {
+			return
+		}
+		a.hideForm()
+		a.submitTask(&v1alpha1.DevTask{
+			Metadata: v1alpha1.ObjectMeta{
+				Name:    fmt.Sprintf("run-%d", time.Now().UnixMilli()),
+				Project: text("Project"),
+			},
+			Spec: v1alpha1.DevTaskSpec{
+				Prompt:         text("Prompt"),
+				Pool:           text("Pool"),
+				PreferredModel: text("Model"),
+			},
+		})
+	})
+	form.AddButton("Cancel", a.hideForm)
+	form.SetTitle(" New Task ")
+	a.showForm(form, 90, 13)
+}
+
+// submitTask creates task and follows its output in a stream pane.
+func (a *App) submitTask(task *v1alpha1.DevTask) {
+	task.APIVersion = v1alpha1.APIVersion
+	task.Kind = v1alpha1.KindDevTask
+	if _, err := a.client.CreateDevTask(task); err != nil {
+		a.flashError(fmt.Sprintf("Creating task failed: %v", err))
+		return
+	}
+
+	name, project := task.Metadata.Name, task.Metadata.Project
+	a.showStream(fmt.Sprintf("Task: %s/%s", project, name), func(ctx context.Context, w io.Writer) error {
+		fmt.Fprintf(w, "[gray]Task %s created. Waiting for an agent...[-]\n", tview.Escape(name))
+		var phase v1alpha1.DevTaskPhase
+		err := a.client.FollowTask(ctx, name, project, func(ev v1alpha1.TaskStreamEvent) {
+			if ev.Step != nil {
+				fmt.Fprintln(w, formatStep(*ev.Step))
+			}
+			if ev.Phase != "" {
+				phase = ev.Phase
+			}
+		})
+		if err != nil || phase == "" {
+			return err
+		}
+
+		done, err := a.client.GetDevTask(name, project)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+		if done.Status.Phase == v1alpha1.TaskFailed {
+			fmt.Fprintln(w, "[red::b]Task Failed[-::-]")
+			if done.Status.Error != "" {
+				fmt.Fprintf(w, "[red]%s[-]\n", tview.Escape(done.Status.Error))
+			}
+			return nil
+		}
+		fmt.Fprintln(w, "[green::b]Task Succeeded[-::-]")
+		if done.Status.TokensIn > 0 || done.Status.TokensOut > 0 {
+			fmt.Fprintf(w, "%d in / %d out tokens, $%.4f\n", done.Status.TokensIn, done.Status.TokensOut, done.Status.CostUSD)
+		}
+		return nil
+	})
+}
+
+// formatStep renders a task step as it happens: messages in full, tool
+// calls and results as one line each.
+func formatStep(step v1alpha1.TaskStep) string {
+	switch step.Type {
+	case v1alpha1.StepToolCall:
+		return fmt.Sprintf("[aqua]-> %s %s[-]", tview.Escape(step.Tool), tview.Escape(oneLine(step.Input, 100)))
+	case v1alpha1.StepToolResult:
+		color := "gray"
+		if step.IsError {
+			color = "red"
+		}
+		return fmt.Sprintf("[%s]<- %s %s[-]", color, tview.Escape(step.Tool), tview.Escape(oneLine(step.Text, 100)))
+	default:
+		return tview.Escape(step.Text)
+	}
+}
+
+// oneLine collapses whitespace in s and cuts it to at most n runes.
+func oneLine(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// Skin holds the colors of the TUI. Colors are tcell color names such as
+// "darkblue" or hex values such as "#1e1e2e"; "default" keeps the
+// terminal's own color.
+type Skin struct {
+	// Text and Background are the colors of everything not styled below.
+	Text       string `yaml:"text"`
+	Background string `yaml:"background"`
+	// Header, Footer and TableHeader are the top bar, the key hint bar and
+	// the column titles.
+	Header      Style `yaml:"header"`
+	Footer      Style `yaml:"footer"`
+	TableHeader Style `yaml:"tableHeader"`
+	// Keys colors the keys in the key hints.
+	Keys string `yaml:"keys"`
+	// Selection is the selected row or node. Unset, its colors are
+	// reversed.
+	Selection Style `yaml:"selection"`
+	// Border colors the borders of panes and forms.
+	Border string `yaml:"border"`
+	// Phases maps resource phases, such as Running, to their color.
+	// Phases not listed use Text.
+	Phases map[string]string `yaml:"phases"`
+}
+
+// Style is a foreground and background color pair.
+type Style struct {
+	Fg string `yaml:"fg"`
+	Bg string `yaml:"bg"`
+}
+
+// DefaultSkin returns the built-in skin, made for dark terminals.
+func DefaultSkin() *Skin {
+	return &Skin{
+		Text:        "white",
+		Background:  "black",
+		Header:      Style{Fg: "white", Bg: "darkblue"},
+		Footer:      Style{Fg: "white", Bg: "darkblue"},
+		TableHeader: Style{Fg: "white", Bg: "darkcyan"},
+		Keys:        "yellow",
+		Border:      "dodgerblue",
+		Phases: map[string]string{
+			"Ready":       "green",
+			"Succeeded":   "green",
+			"Running":     "yellow",
+			"Busy":        "yellow",
+			"Starting":    "yellow",
+			"Pending":     "white",
+			"Scheduled":   "white",
+			"Failed":      "red",
+			"Terminating": "gray",
+			"Terminated":  "gray",
+		},
+	}
+}
+
+// DefaultSkinPath returns the path of the skin file, ~/.orca/skin.yaml.
+func DefaultSkinPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join("/tmp", "orca", "skin.yaml")
+	}
+	return filepath.Join(home, ".orca", "skin.yaml")
+}
+
+// LoadSkin reads the skin file at path over the default skin, so a file
+// only needs the colors it changes. A missing file yields the default skin.
+func LoadSkin(path string) (*Skin, error) {
+	skin := DefaultSkin()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return skin, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading skin %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, skin); err != nil {
+		return nil, fmt.Errorf("parsing skin %s: %w", path, err)
+	}
+	if err := skin.validate(); err != nil {
+		return nil, fmt.Errorf("invalid skin %s: %w", path, err)
+	}
+	return skin, nil
+}
+
+// validate checks that every color of the skin is known.
+func (s *Skin) validate() error {
+	colors := map[string]string{
+		"text":           s.Text,
+		"background":     s.Background,
+		"header.fg":      s.Header.Fg,
+		"header.bg":      s.Header.Bg,
+		"footer.fg":      s.Footer.Fg,
+		"footer.bg":      s.Footer.Bg,
+		"tableHeader.fg": s.TableHeader.Fg,
+		"tableHeader.bg": s.TableHeader.Bg,
+		"keys":           s.Keys,
+		"selection.fg":   s.Selection.Fg,
+		"selection.bg":   s.Selection.Bg,
+		"border":         s.Border,
+	}
+	for phase, c := range s.Phases {
+		colors["phases."+phase] = c
+	}
+	fields := make([]string, 0, len(colors))
+	for f := range colors {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		c := strings.ToLower(colors[f])
+		if c != "" && c != "default" && tcell.GetColor(c) == tcell.ColorDefault {
+			return fmt.Errorf("unknown color %q for %s", colors[f], f)
+		}
+	}
+	return nil
+}
+
+// apply makes the skin's text and background colors the defaults of new
+// primitives. It must run before any primitive is created.
+func (s *Skin) apply() {
+	tview.Styles.PrimaryTextColor = color(s.Text)
+	tview.Styles.PrimitiveBackgroundColor = color(s.Background)
+	tview.Styles.BorderColor = color(s.Border)
+	tview.Styles.TitleColor = color(s.Text)
+	tview.Styles.GraphicsColor = color(s.Border)
+}
+
+// phaseColorName returns the tview color tag name for a phase string.
+func (s *Skin) phaseColorName(phase string) string {
+	if c, ok := s.Phases[phase]; ok && c != "" {
+		return c
+	}
+	return tag(s.Text)
+}
+
+// phaseColor returns the tcell color appropriate for a phase string.
+func (s *Skin) phaseColor(phase string) tcell.Color {
+	return color(s.phaseColorName(phase))
+}
+
+// hints renders key hints for a bar, given as key, label pairs.
+func (s *Skin) hints(bar Style, pairs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		fmt.Fprintf(&b, "[%s]<%s>[%s]%s", tag(s.Keys), pairs[i], tag(bar.Fg), pairs[i+1])
+	}
+	return b.String()
+}
+
+// selectedStyle is the style of the selected row or node. It is
+// tcell.StyleDefault, which tview reverses, when the skin sets no selection.
+func (s *Skin) selectedStyle() tcell.Style {
+	if s.Selection == (Style{}) {
+		return tcell.StyleDefault
+	}
+	return tcell.StyleDefault.Foreground(color(s.Selection.Fg)).Background(color(s.Selection.Bg))
+}
+
+// tag returns a skin color for use in a tview color tag; unset colors
+// reset to the primitive's own color.
+func tag(name string) string {
+	if name == "" {
+		return "-"
+	}
+	return name
+}
+
+// color parses a skin color; unset colors are the terminal's default.
+func color(name string) tcell.Color {
+	return tcell.GetColor(strings.ToLower(name))
+}
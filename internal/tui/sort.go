@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// sortKeys maps the Shift+key sort bindings to the column they sort by.
+var sortKeys = map[rune]string{
+	'N': "NAME",
+	'A': "AGE",
+	'P': "PHASE",
+	'T': "ACTIVE-TASKS",
+	'R': "REPLICAS",
+}
+
+// sortState is the sort order of a view.
+type sortState struct {
+	column string
+	desc   bool
+}
+
+// toggleSort sorts the current view by column, or reverses the order if it
+// is sorted by column already. Views without the column are left alone.
+func (a *App) toggleSort(column string) {
+	found := false
+	for _, h := range a.headers {
+		if h == column {
+			found = true
+		}
+	}
+	if !found {
+		return
+	}
+
+	a.mu.Lock()
+	s := a.sorts[a.currentView]
+	if s.column == column {
+		s.desc = !s.desc
+	} else {
+		s = sortState{column: column}
+	}
+	a.sorts[a.currentView] = s
+	a.mu.Unlock()
+
+	a.updateTable()
+}
+
+// sortTable reorders the table's rows by the current view's sort column.
+// Cells sort by their reference when they have one (see cellKey), else by
+// their text.
+func (a *App) sortTable() {
+	a.mu.Lock()
+	s, ok := a.sorts[a.currentView]
+	a.mu.Unlock()
+	if !ok {
+		return
+	}
+	col := -1
+	for i, h := range a.headers {
+		if h == s.column {
+			col = i
+		}
+	}
+	if col < 0 {
+		return
+	}
+
+	n, cols := a.table.GetRowCount(), a.table.GetColumnCount()
+	rows := make([][]*tview.TableCell, 0, n)
+	for r := 1; r < n; r++ {
+		row := make([]*tview.TableCell, cols)
+		for c := range row {
+			row[c] = a.table.GetCell(r, c)
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		cmp := compareCells(rows[i][col], rows[j][col])
+		if s.desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	for r, row := range rows {
+		for c, cell := range row {
+			a.table.SetCell(r+1, c, cell)
+		}
+	}
+}
+
+// compareCells orders two cells of a column: numbers numerically, creation
+// times youngest first, anything else by plain text.
+func compareCells(x, y *tview.TableCell) int {
+	switch xv := x.GetReference().(type) {
+	case int:
+		if yv, ok := y.GetReference().(int); ok {
+			return xv - yv
+		}
+	case time.Time:
+		if yv, ok := y.GetReference().(time.Time); ok {
+			return yv.Compare(xv)
+		}
+	}
+	return strings.Compare(strings.ToLower(cellText(x)), strings.ToLower(cellText(y)))
+}
+
+// headerLabel returns the header text for column, with an arrow when the
+// current view is sorted by it.
+func (a *App) headerLabel(column string) string {
+	a.mu.Lock()
+	s, ok := a.sorts[a.currentView]
+	a.mu.Unlock()
+	if !ok || s.column != column {
+		return column
+	}
+	if s.desc {
+		return column + "↓"
+	}
+	return column + "↑"
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showStream replaces the table with a pane showing what run writes to it,
+// as k9s does for logs. run is started in its own goroutine and stopped
+// through its context when the pane is closed. The pane scrolls along with
+// new lines until autoscroll is switched off or the user scrolls up.
+func (a *App) showStream(title string, run func(ctx context.Context, w io.Writer) error) {
+	a.hideDescribe()
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false)
+	// Lines are written from the stream goroutine; scrolling and drawing
+	// happen on the UI goroutine.
+	view.SetChangedFunc(func() {
+		a.app.QueueUpdateDraw(func() {
+			if a.streamFollow && a.streamView == view {
+				view.ScrollToEnd()
+			}
+		})
+	})
+	view.SetBorder(true).
+		SetTitle(" " + tview.Escape(title) + " ").
+		SetBorderColor(color(a.skin.Border))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.streamView = view
+	a.streamCancel = cancel
+	a.streamOpen = true
+	a.streamFollow = true
+	a.streamWrap = false
+
+	a.layout.Clear()
+	a.layout.AddItem(view, 0, 1, true)
+	a.app.SetFocus(view)
+	a.updateFooter()
+
+	go func() {
+		err := run(ctx, view)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(view, "[red]%s[-]\n", tview.Escape(fmt.Sprintf("Stream failed: %v", err)))
+		} else {
+			fmt.Fprintln(view, "[gray]-- stream ended --[-]")
+		}
+	}()
+}
+
+// hideStream stops the stream and brings back the table.
+func (a *App) hideStream() {
+	if !a.streamOpen {
+		return
+	}
+	a.streamCancel()
+	a.streamOpen = false
+	a.streamView = nil
+
+	a.layout.Clear()
+	a.layout.AddItem(a.mainView(), 0, 1, true)
+	a.app.SetFocus(a.mainView())
+	a.updateFooter()
+}
+
+// handleStreamKey handles a key press while the stream pane is open. Keys it
+// does not use go to the pane itself for scrolling.
+func (a *App) handleStreamKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		a.hideStream()
+		return nil
+	case tcell.KeyUp, tcell.KeyPgUp, tcell.KeyHome:
+		// Scrolling back pauses autoscroll, as in k9s.
+		a.setStreamFollow(false)
+		return event
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'q':
+			a.hideStream()
+			return nil
+		case 's':
+			a.setStreamFollow(!a.streamFollow)
+			return nil
+		case 'w':
+			a.streamWrap = !a.streamWrap
+			a.streamView.SetWrap(a.streamWrap)
+			return nil
+		case 'c':
+			a.streamView.Clear()
+			return nil
+		case 'k', 'g':
+			a.setStreamFollow(false)
+			return event
+		}
+	}
+	return event
+}
+
+// setStreamFollow switches autoscroll of the stream pane on or off.
+func (a *App) setStreamFollow(follow bool) {
+	if a.streamFollow == follow {
+		return
+	}
+	a.streamFollow = follow
+	if follow {
+		a.streamView.ScrollToEnd()
+	}
+	a.updateFooter()
+}
@@ -0,0 +1,242 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+)
+
+// refreshIntervals are the refresh intervals to choose from with +/-. The
+// interval is the most a watched view is redrawn and how often views are
+// polled from servers without the watch endpoint.
+var refreshIntervals = []time.Duration{
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+}
+
+// defaultRefreshInterval is the refresh interval the TUI starts with.
+const defaultRefreshInterval = 2 * time.Second
+
+// viewKinds maps each view to the kind of resource it lists. The xray and
+// metrics views show several kinds and watch them all.
+var viewKinds = map[string]string{
+	"pods":     v1alpha1.KindAgentPod,
+	"pools":    v1alpha1.KindAgentPool,
+	"tasks":    v1alpha1.KindDevTask,
+	"projects": v1alpha1.KindProject,
+	"xray":     "",
+	"metrics":  "",
+}
+
+// startWatch (re)starts keeping the current view up to date: it lists the
+// view's resources, then applies the changes the server streams for them.
+// While paused the view is only listed once.
+func (a *App) startWatch() {
+	if a.watchCancel != nil {
+		a.watchCancel()
+	}
+	if a.paused {
+		a.watchCancel = nil
+		go func() {
+			a.refresh()
+			a.app.QueueUpdateDraw(a.updateTable)
+		}()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.watchCancel = cancel
+
+	a.mu.Lock()
+	view := a.currentView
+	project := a.currentProject
+	c := a.client
+	a.mu.Unlock()
+
+	go a.watchView(ctx, c, view, project)
+	if view == "metrics" {
+		go a.tickMetrics(ctx)
+	}
+}
+
+// watchView follows the changes to the resources of view until ctx is
+// cancelled. After a broken stream it lists the resources again and
+// reconnects, backing off up to 30s. Servers without the watch endpoint are
+// polled instead.
+func (a *App) watchView(ctx context.Context, c client.Interface, view, project string) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		// A change made between the list and the start of the watch is
+		// only seen with the resource's next change or a manual refresh.
+		a.refresh()
+		a.app.QueueUpdateDraw(a.updateTable)
+
+		err := c.Watch(ctx, viewKinds[view], project, func(ev v1alpha1.WatchEvent) {
+			backoff = time.Second
+			a.app.QueueUpdateDraw(func() {
+				if ctx.Err() == nil && a.applyWatchEvent(ev) {
+					a.scheduleUpdate()
+				}
+			})
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if client.IsNotFound(err) {
+			a.poll(ctx)
+			return
+		}
+		if err != nil {
+			a.mu.Lock()
+			a.lastErr = err
+			a.mu.Unlock()
+			a.app.QueueUpdateDraw(a.updateTable)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, 30*time.Second)
+	}
+}
+
+// poll refreshes the current view every refresh interval until ctx is
+// cancelled.
+func (a *App) poll(ctx context.Context) {
+	for {
+		a.mu.Lock()
+		interval := a.interval
+		a.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+			a.refresh()
+			a.app.QueueUpdateDraw(a.updateTable)
+		}
+	}
+}
+
+// scheduleUpdate redraws the table for a change, at most once per refresh
+// interval: right away when the last redraw is long enough ago, else when it
+// will be. Must be called on the UI goroutine.
+func (a *App) scheduleUpdate() {
+	if a.updatePending {
+		return
+	}
+	a.mu.Lock()
+	wait := a.interval - time.Since(a.lastUpdate)
+	a.mu.Unlock()
+	if wait <= 0 {
+		a.lastUpdate = time.Now()
+		a.updateTable()
+		return
+	}
+	a.updatePending = true
+	time.AfterFunc(wait, func() {
+		a.app.QueueUpdateDraw(func() {
+			a.updatePending = false
+			a.lastUpdate = time.Now()
+			a.updateTable()
+		})
+	})
+}
+
+// togglePause stops or resumes keeping the view up to date.
+func (a *App) togglePause() {
+	a.paused = !a.paused
+	if a.paused {
+		if a.watchCancel != nil {
+			a.watchCancel()
+			a.watchCancel = nil
+		}
+	} else {
+		a.startWatch()
+	}
+	a.updateHeader()
+}
+
+// stepInterval moves the refresh interval delta steps along
+// refreshIntervals.
+func (a *App) stepInterval(delta int) {
+	a.mu.Lock()
+	i := 0
+	for i < len(refreshIntervals)-1 && refreshIntervals[i] < a.interval {
+		i++
+	}
+	i = max(0, min(i+delta, len(refreshIntervals)-1))
+	a.interval = refreshIntervals[i]
+	a.mu.Unlock()
+	a.updateHeader()
+}
+
+// applyWatchEvent updates the cached resources with ev. It reports whether
+// the cache changed.
+func (a *App) applyWatchEvent(ev v1alpha1.WatchEvent) bool {
+	raw, ok := ev.Object.(json.RawMessage)
+	if !ok {
+		return false
+	}
+	deleted := ev.Type == v1alpha1.EventDeleted
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch ev.Kind {
+	case v1alpha1.KindAgentPod:
+		var pod v1alpha1.AgentPod
+		if json.Unmarshal(raw, &pod) != nil {
+			return false
+		}
+		a.pods = applyChange(a.pods, pod, func(p *v1alpha1.AgentPod) *v1alpha1.ObjectMeta { return &p.Metadata }, deleted)
+	case v1alpha1.KindAgentPool:
+		var pool v1alpha1.AgentPool
+		if json.Unmarshal(raw, &pool) != nil {
+			return false
+		}
+		a.pools = applyChange(a.pools, pool, func(p *v1alpha1.AgentPool) *v1alpha1.ObjectMeta { return &p.Metadata }, deleted)
+	case v1alpha1.KindDevTask:
+		var task v1alpha1.DevTask
+		if json.Unmarshal(raw, &task) != nil {
+			return false
+		}
+		a.tasks = applyChange(a.tasks, task, func(t *v1alpha1.DevTask) *v1alpha1.ObjectMeta { return &t.Metadata }, deleted)
+	case v1alpha1.KindProject:
+		var proj v1alpha1.Project
+		if json.Unmarshal(raw, &proj) != nil {
+			return false
+		}
+		a.projects = applyChange(a.projects, proj, func(p *v1alpha1.Project) *v1alpha1.ObjectMeta { return &p.Metadata }, deleted)
+	default:
+		return false
+	}
+	a.lastErr = nil
+	return true
+}
+
+// applyChange returns a copy of items with obj added, replaced or, when
+// deleted is set, removed. Items are matched by name and project. The copy
+// keeps renders of the old slice safe.
+func applyChange[T any](items []T, obj T, meta func(*T) *v1alpha1.ObjectMeta, deleted bool) []T {
+	m := meta(&obj)
+	out := make([]T, 0, len(items)+1)
+	found := false
+	for i := range items {
+		im := meta(&items[i])
+		if im.Name != m.Name || im.Project != m.Project {
+			out = append(out, items[i])
+			continue
+		}
+		found = true
+		if !deleted {
+			out = append(out, obj)
+		}
+	}
+	if !found && !deleted {
+		out = append(out, obj)
+	}
+	return out
+}
@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+)
+
+// newTree creates the tree of the xray view. Enter expands or collapses the
+// selected node.
+func (a *App) newTree() *tview.TreeView {
+	root := tview.NewTreeNode("")
+	tree := tview.NewTreeView().
+		SetRoot(root).
+		SetCurrentNode(root).
+		SetTopLevel(1) // the root is only a container
+	tree.SetBorderPadding(0, 0, 1, 1)
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		if len(node.GetChildren()) == 0 {
+			return
+		}
+		node.SetExpanded(!node.IsExpanded())
+		if key, ok := node.GetReference().(string); ok {
+			a.collapsed[key] = !node.IsExpanded()
+		}
+	})
+	return tree
+}
+
+// mainView returns what shows the current view: the xray tree, the metrics
+// dashboard or the table.
+func (a *App) mainView() tview.Primitive {
+	switch a.currentView {
+	case "xray":
+		return a.tree
+	case "metrics":
+		return a.metricsView
+	}
+	return a.table
+}
+
+// refreshWorkloads lists the pools, pods and tasks, for the views that show
+// all of them.
+func (a *App) refreshWorkloads(c client.Interface, project string) {
+	pools, err := c.ListAgentPools(project)
+	var (
+		pods  []v1alpha1.AgentPod
+		tasks []v1alpha1.DevTask
+	)
+	if err == nil {
+		pods, err = c.ListAgentPods(project)
+	}
+	if err == nil {
+		tasks, err = c.ListDevTasks(project)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastErr = err
+	if err != nil {
+		return
+	}
+	a.pools, a.pods, a.tasks = pools, pods, tasks
+}
+
+// updateTree rebuilds the xray tree: pools with the pods they own, pods
+// without a pool next to them, and under each pod the tasks it is running.
+// Nodes keep their expanded state and the selection stays on the same node.
+// With a filter only the branches with a matching node are shown.
+func (a *App) updateTree(filter string) {
+	a.headers = nil
+
+	selected := ""
+	if node := a.tree.GetCurrentNode(); node != nil {
+		selected, _ = node.GetReference().(string)
+	}
+
+	a.mu.Lock()
+	pools, pods, tasks := a.pools, a.pods, a.tasks
+	err := a.lastErr
+	a.mu.Unlock()
+
+	root := a.tree.GetRoot()
+	root.ClearChildren()
+	if err != nil {
+		root.AddChild(tview.NewTreeNode(fmt.Sprintf("Error: %v", err)).
+			SetColor(tcell.ColorRed).SetSelectable(false))
+		return
+	}
+
+	running := make(map[string][]v1alpha1.DevTask)
+	for _, t := range tasks {
+		switch t.Status.Phase {
+		case v1alpha1.TaskScheduled, v1alpha1.TaskRunning:
+			key := t.Metadata.Project + "/" + t.Status.AssignedPod
+			running[key] = append(running[key], t)
+		}
+	}
+	// podNode returns the node of pod p, or nil when neither p nor its
+	// tasks match the filter and its pool did not either.
+	podNode := func(p v1alpha1.AgentPod, poolMatched bool) *tview.TreeNode {
+		key := p.Metadata.Project + "/" + p.Metadata.Name
+		node := a.treeNode("pod/"+key, fmt.Sprintf("[aqua]pod[-] %s [%s]%s[-] %s",
+			tview.Escape(p.Metadata.Name), a.skin.phaseColorName(string(p.Status.Phase)), p.Status.Phase, tview.Escape(p.Spec.Model)))
+		matched := poolMatched || matchesFilter(filter, p.Metadata.Name, p.Metadata.Project, p.Spec.Model, string(p.Status.Phase))
+		for _, t := range running[key] {
+			if !matched && !matchesFilter(filter, t.Metadata.Name, string(t.Status.Phase), t.Spec.Prompt) {
+				continue
+			}
+			node.AddChild(a.treeNode("task/"+t.Metadata.Project+"/"+t.Metadata.Name, fmt.Sprintf("[fuchsia]task[-] %s [%s]%s[-] [gray]%s[-]",
+				tview.Escape(t.Metadata.Name), a.skin.phaseColorName(string(t.Status.Phase)), t.Status.Phase, tview.Escape(oneLine(t.Spec.Prompt, 60)))))
+		}
+		if !matched && len(node.GetChildren()) == 0 {
+			return nil
+		}
+		return node
+	}
+
+	owned := make(map[string][]v1alpha1.AgentPod)
+	var standalone []v1alpha1.AgentPod
+	for _, p := range pods {
+		if p.OwnerPool() == "" {
+			standalone = append(standalone, p)
+			continue
+		}
+		key := p.Metadata.Project + "/" + p.OwnerPool()
+		owned[key] = append(owned[key], p)
+	}
+
+	// Sort a copy: the cached slice may be in use by another render.
+	pools = append([]v1alpha1.AgentPool(nil), pools...)
+	sort.SliceStable(pools, func(i, j int) bool {
+		if pools[i].Metadata.Project != pools[j].Metadata.Project {
+			return pools[i].Metadata.Project < pools[j].Metadata.Project
+		}
+		return pools[i].Metadata.Name < pools[j].Metadata.Name
+	})
+	for _, p := range pools {
+		key := p.Metadata.Project + "/" + p.Metadata.Name
+		node := a.treeNode("pool/"+key, fmt.Sprintf("[yellow]pool[-] %s [gray](%s)[-] %d/%d ready, %d busy",
+			tview.Escape(p.Metadata.Name), tview.Escape(p.Metadata.Project), p.Status.ReadyReplicas, p.Spec.Replicas, p.Status.BusyReplicas))
+		matched := matchesFilter(filter, p.Metadata.Name, p.Metadata.Project)
+		for _, pod := range owned[key] {
+			if child := podNode(pod, matched); child != nil {
+				node.AddChild(child)
+			}
+		}
+		if matched || len(node.GetChildren()) > 0 {
+			root.AddChild(node)
+		}
+	}
+	for _, p := range standalone {
+		if node := podNode(p, false); node != nil {
+			root.AddChild(node)
+		}
+	}
+
+	if len(root.GetChildren()) == 0 {
+		root.AddChild(tview.NewTreeNode("No pools or pods.").
+			SetColor(tcell.ColorGray).SetSelectable(false))
+	}
+
+	a.tree.SetCurrentNode(root)
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if key, ok := node.GetReference().(string); ok && key == selected {
+			a.tree.SetCurrentNode(node)
+			return false
+		}
+		return true
+	})
+	if a.tree.GetCurrentNode() == root {
+		a.tree.SetCurrentNode(root.GetChildren()[0])
+	}
+}
+
+// treeNode creates a node of the xray tree. key identifies it across
+// rebuilds and is used to restore its expanded state.
+func (a *App) treeNode(key, text string) *tview.TreeNode {
+	node := tview.NewTreeNode(text).
+		SetReference(key).
+		SetExpanded(!a.collapsed[key])
+	if a.skin.Selection != (Style{}) {
+		node.SetSelectedTextStyle(a.skin.selectedStyle())
+	}
+	return node
+}
@@ -0,0 +1,23 @@
+// Package version reports the version of the orca binary.
+package version
+
+import "runtime/debug"
+
+// Version is the release version, set at build time with
+//
+//	-ldflags "-X github.com/klubi/orca/internal/version.Version=v1.2.3"
+//
+// When unset, Get falls back to the module version Go recorded.
+var Version = ""
+
+// Get returns the version of the running binary: Version, else the version
+// `go install` recorded, else "dev".
+func Get() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
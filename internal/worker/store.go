@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+)
+
+// errUnsupported is returned by the operations a worker's runtime never
+// performs on the control plane's store.
+var errUnsupported = errors.New("not supported by a worker's store")
+
+// apiStore is the store.Store a worker's runtime runs against: reads go to
+// the API server and writes replace the status of the object, the only part
+// of pods and tasks the runtime changes.
+type apiStore struct {
+	client client.Interface
+}
+
+// newAPIStore returns a store backed by the API server c talks to.
+func newAPIStore(c client.Interface) store.Store {
+	return &apiStore{client: c}
+}
+
+// splitKey splits a key of the form /<kind>/<project>/<name>.
+func splitKey(key string) (kind, project, name string, err error) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 4 || parts[0] != "" || parts[1] == "" || parts[3] == "" {
+		return "", "", "", fmt.Errorf("malformed key %q", key)
+	}
+	return parts[1], parts[2], parts[3], nil
+}
+
+func (s *apiStore) Get(key string, target interface{}) error {
+	kind, project, name, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Get(kind, name, project, target); err != nil {
+		if client.IsNotFound(err) {
+			return store.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *apiStore) Update(key string, value interface{}) error {
+	kind, project, name, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var obj struct {
+		Status json.RawMessage `json:"status"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return err
+	}
+	if err := s.client.UpdateStatus(kind, name, project, obj.Status, nil); err != nil {
+		if client.IsNotFound(err) {
+			return store.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *apiStore) Create(string, interface{}) error { return errUnsupported }
+func (s *apiStore) Delete(string) error              { return errUnsupported }
+
+func (s *apiStore) UpdateIfVersion(string, interface{}, string) error { return errUnsupported }
+
+func (s *apiStore) List(string, func() interface{}) ([]interface{}, error) {
+	return nil, errUnsupported
+}
+
+// Watch returns a closed channel: a worker polls the API server instead.
+func (s *apiStore) Watch(string) (<-chan v1alpha1.WatchEvent, func()) {
+	ch := make(chan v1alpha1.WatchEvent)
+	close(ch)
+	return ch, func() {}
+}
+
+func (s *apiStore) Snapshot() (map[string][]byte, error) { return nil, errUnsupported }
+func (s *apiStore) Restore(map[string][]byte) error      { return errUnsupported }
+func (s *apiStore) Close() error                         { return nil }
@@ -0,0 +1,390 @@
+// Package worker implements `orca agent`: a process on a machine other than
+// the control plane's that registers an AgentNode, starts the AgentPods
+// assigned to the node and executes the tasks scheduled on them. It only
+// talks to the control plane through its API.
+//
+// The tasks' artifacts and the pods' logs stay on the worker's machine; the
+// control plane cannot serve them.
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	"github.com/klubi/orca/internal/version"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+)
+
+// Worker runs the pods of one AgentNode.
+type Worker struct {
+	name     string
+	client   client.Interface
+	runtime  *agent.Runtime
+	interval time.Duration
+	logger   *zap.Logger
+
+	mu sync.Mutex
+	// started holds the keys of the pods this process started; pods of the
+	// node that are Ready without being in it were started by a previous
+	// worker process and are started again.
+	started map[string]bool
+	// running maps the keys of the tasks this process is executing to the
+	// keys of their pods.
+	running map[string]string
+	wg      sync.WaitGroup
+}
+
+// New returns a worker for the node called name that polls the control
+// plane every interval, which is also how often it reports its heartbeat.
+// The tasks are executed by executor with the agent settings of cfg.
+func New(name string, c client.Interface, executor *agent.Executor, cfg *config.Config, interval time.Duration, logger *zap.Logger) *Worker {
+	return &Worker{
+		name:     name,
+		client:   c,
+		runtime:  agent.NewRuntime(newAPIStore(c), executor, cfg, logger.Named("runtime")),
+		interval: interval,
+		logger:   logger,
+		started:  make(map[string]bool),
+		running:  make(map[string]string),
+	}
+}
+
+// Run registers the node and keeps its pods and tasks going until ctx is
+// done. It then waits for the tasks being executed, which ctx cancels, and
+// marks the node NotReady.
+func (w *Worker) Run(ctx context.Context) error {
+	if err := w.register(); err != nil {
+		return err
+	}
+	w.logger.Info("agent node registered", zap.String("node", w.name))
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.sync(ctx); err != nil {
+			w.logger.Error("sync failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			w.wg.Wait()
+			if err := w.setStatus(v1alpha1.NodeNotReady, 0); err != nil {
+				return fmt.Errorf("marking node NotReady: %w", err)
+			}
+			w.logger.Info("agent node stopped", zap.String("node", w.name))
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// register creates the node unless it exists, e.g. from an earlier run.
+func (w *Worker) register() error {
+	_, err := w.client.GetAgentNode(w.name)
+	if client.IsNotFound(err) {
+		_, err = w.client.CreateAgentNode(&v1alpha1.AgentNode{
+			Metadata: v1alpha1.ObjectMeta{Name: w.name},
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("registering node %q: %w", w.name, err)
+	}
+	return nil
+}
+
+// setStatus reports the node's phase and pod count with a new heartbeat.
+func (w *Worker) setStatus(phase v1alpha1.NodePhase, pods int) error {
+	hostname, _ := os.Hostname()
+	now := time.Now()
+	status := v1alpha1.AgentNodeStatus{
+		Phase:         phase,
+		Hostname:      hostname,
+		Version:       version.Get(),
+		Pods:          pods,
+		LastHeartbeat: &now,
+	}
+	return w.client.UpdateStatus(v1alpha1.KindAgentNode, w.name, "", &status, nil)
+}
+
+// sync starts the node's pending pods, refreshes the heartbeats of its
+// running ones, launches the tasks scheduled on them, tears down the deleted
+// ones and reports the node's status.
+func (w *Worker) sync(ctx context.Context) error {
+	node, err := w.client.GetAgentNode(w.name)
+	if err != nil {
+		return fmt.Errorf("getting node: %w", err)
+	}
+
+	allPods, err := w.client.ListAgentPods("")
+	if err != nil {
+		return fmt.Errorf("listing pods: %w", err)
+	}
+	pods := make(map[string]*v1alpha1.AgentPod)
+	running := 0
+	for i := range allPods {
+		pod := &allPods[i]
+		if pod.Spec.NodeName != w.name {
+			continue
+		}
+		key := store.ResourceKey(v1alpha1.KindAgentPod, pod.Metadata.Project, pod.Metadata.Name)
+		pods[key] = pod
+		switch pod.Status.Phase {
+		case v1alpha1.PodStarting, v1alpha1.PodReady, v1alpha1.PodBusy:
+			running++
+		}
+	}
+
+	for key, pod := range pods {
+		w.mu.Lock()
+		started := w.started[key]
+		w.mu.Unlock()
+
+		// A deleted pod is torn down once its tasks have stopped, below.
+		if pod.Metadata.DeletionTimestamp != nil {
+			continue
+		}
+		switch pod.Status.Phase {
+		case v1alpha1.PodPending:
+			if node.Spec.MaxPods > 0 && running >= node.Spec.MaxPods {
+				continue
+			}
+			running++
+			w.startPod(ctx, key, pod)
+		case v1alpha1.PodStarting, v1alpha1.PodReady, v1alpha1.PodBusy:
+			if !started {
+				pod.Status.ActiveTasks = 0
+				w.startPod(ctx, key, pod)
+				continue
+			}
+			if err := w.runtime.Heartbeat(pod.Metadata.Name, pod.Metadata.Project); err != nil {
+				w.logger.Warn("heartbeat failed", zap.String("pod", pod.Metadata.Name), zap.Error(err))
+			}
+		default:
+			w.mu.Lock()
+			delete(w.started, key)
+			w.mu.Unlock()
+		}
+	}
+
+	if err := w.syncTasks(ctx, pods); err != nil {
+		return err
+	}
+	for key, pod := range pods {
+		if pod.Metadata.DeletionTimestamp != nil {
+			w.teardown(ctx, key, pod)
+		}
+	}
+	if err := w.setStatus(v1alpha1.NodeReady, running); err != nil {
+		return fmt.Errorf("reporting node status: %w", err)
+	}
+	return nil
+}
+
+// startPod starts pod, adding the runtime finalizer first so that the pod
+// is not removed before the worker has stopped it.
+func (w *Worker) startPod(ctx context.Context, key string, pod *v1alpha1.AgentPod) {
+	if !pod.Metadata.HasFinalizer(v1alpha1.FinalizerRuntime) {
+		if err := w.setFinalizers(v1alpha1.KindAgentPod, &pod.Metadata, append(slices.Clone(pod.Metadata.Finalizers), v1alpha1.FinalizerRuntime)); err != nil {
+			w.logger.Error("failed to add the runtime finalizer", zap.String("pod", pod.Metadata.Name), zap.Error(err))
+			return
+		}
+	}
+	if err := w.runtime.StartPod(ctx, pod); err != nil {
+		w.logger.Error("failed to start pod", zap.String("pod", pod.Metadata.Name), zap.Error(err))
+		return
+	}
+	w.mu.Lock()
+	w.started[key] = true
+	w.mu.Unlock()
+}
+
+// syncTasks launches the tasks scheduled on the node's pods, fails the ones
+// a previous worker process left Running, cancels the executions of tasks
+// cancelled through the API or running on deleted pods, and tears down
+// deleted tasks.
+func (w *Worker) syncTasks(ctx context.Context, pods map[string]*v1alpha1.AgentPod) error {
+	// Tasks whose execution ends while they are listed may show as Running
+	// although they are done; taking the executing ones first keeps those
+	// from being seen as interrupted.
+	w.mu.Lock()
+	executing := make(map[string]bool, len(w.running))
+	for key := range w.running {
+		executing[key] = true
+	}
+	w.mu.Unlock()
+
+	tasks, err := w.client.ListDevTasks("")
+	if err != nil {
+		return fmt.Errorf("listing tasks: %w", err)
+	}
+	for i := range tasks {
+		task := &tasks[i]
+		pod, ok := pods[store.ResourceKey(v1alpha1.KindAgentPod, task.Metadata.Project, task.Status.AssignedPod)]
+		if !ok {
+			continue
+		}
+		key := store.ResourceKey(v1alpha1.KindDevTask, task.Metadata.Project, task.Metadata.Name)
+		running := executing[key]
+
+		switch {
+		case task.Metadata.DeletionTimestamp != nil:
+			w.finalizeTask(task, running)
+		case running && (task.Status.Cancelled || pod.Metadata.DeletionTimestamp != nil):
+			w.runtime.CancelTask(task.Metadata.Project, task.Metadata.Name)
+		case running:
+		case pod.Metadata.DeletionTimestamp != nil:
+			// No task is launched on a pod being deleted.
+		case task.Status.Phase == v1alpha1.TaskScheduled:
+			if pod.Status.Phase != v1alpha1.PodReady && pod.Status.Phase != v1alpha1.PodBusy {
+				continue
+			}
+			w.execute(ctx, key, task, pod)
+		case task.Status.Phase == v1alpha1.TaskRunning:
+			w.interrupt(task)
+		}
+	}
+	return nil
+}
+
+// execute marks task Running, so that it is not launched twice, and runs
+// it on pod in the background. The execution finalizer, added first, keeps
+// the task until the worker has stopped its execution.
+func (w *Worker) execute(ctx context.Context, key string, task *v1alpha1.DevTask, pod *v1alpha1.AgentPod) {
+	if !task.Metadata.HasFinalizer(v1alpha1.FinalizerExecution) {
+		if err := w.setFinalizers(v1alpha1.KindDevTask, &task.Metadata, append(slices.Clone(task.Metadata.Finalizers), v1alpha1.FinalizerExecution)); err != nil {
+			w.logger.Error("failed to add the execution finalizer", zap.String("task", task.Metadata.Name), zap.Error(err))
+			return
+		}
+	}
+	task.Status.Phase = v1alpha1.TaskRunning
+	if err := w.client.UpdateStatus(v1alpha1.KindDevTask, task.Metadata.Name, task.Metadata.Project, &task.Status, nil); err != nil {
+		w.logger.Error("failed to mark task Running", zap.String("task", task.Metadata.Name), zap.Error(err))
+		return
+	}
+
+	w.logger.Info("launching task execution",
+		zap.String("task", task.Metadata.Name),
+		zap.String("pod", pod.Metadata.Name),
+	)
+	w.mu.Lock()
+	w.running[key] = store.ResourceKey(v1alpha1.KindAgentPod, pod.Metadata.Project, pod.Metadata.Name)
+	w.mu.Unlock()
+	// Each execution updates the pod's status through its own copy, as the
+	// DevTask controller's do.
+	podCopy := *pod
+	pod = &podCopy
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer func() {
+			w.mu.Lock()
+			delete(w.running, key)
+			w.mu.Unlock()
+		}()
+		if err := w.runtime.ExecuteTask(ctx, task, pod); err != nil {
+			w.logger.Error("runtime.ExecuteTask returned error",
+				zap.String("task", task.Metadata.Name),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// interrupt fails a task that is Running on the node without this process
+// executing it, so that the DevTask controller can retry it.
+func (w *Worker) interrupt(task *v1alpha1.DevTask) {
+	task.Status.Phase = v1alpha1.TaskFailed
+	task.Status.Error = "interrupted: agent worker restarted while the task was running"
+	task.Status.FinishedAt = time.Now()
+	v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(
+		v1alpha1.ConditionComplete, v1alpha1.ConditionFalse, v1alpha1.ReasonInterrupted, task.Status.Error))
+	if err := w.client.UpdateStatus(v1alpha1.KindDevTask, task.Metadata.Name, task.Metadata.Project, &task.Status, nil); err != nil {
+		w.logger.Error("failed to fail interrupted task", zap.String("task", task.Metadata.Name), zap.Error(err))
+	}
+}
+
+// finalizeTask tears down a deleted task on the node: it cancels the
+// execution while running, and once it has stopped removes the task's
+// artifacts and clears the execution finalizer, which removes the task.
+func (w *Worker) finalizeTask(task *v1alpha1.DevTask, running bool) {
+	if running {
+		w.runtime.CancelTask(task.Metadata.Project, task.Metadata.Name)
+		return
+	}
+	if !task.Metadata.HasFinalizer(v1alpha1.FinalizerExecution) {
+		return
+	}
+	if err := w.runtime.RemoveArtifacts(task.Metadata.Project, task.Metadata.Name); err != nil {
+		w.logger.Warn("removing task artifacts", zap.String("task", task.Metadata.Name), zap.Error(err))
+	}
+	finalizers := slices.DeleteFunc(slices.Clone(task.Metadata.Finalizers), func(f string) bool { return f == v1alpha1.FinalizerExecution })
+	if err := w.setFinalizers(v1alpha1.KindDevTask, &task.Metadata, finalizers); err != nil {
+		w.logger.Error("failed to clear the execution finalizer", zap.String("task", task.Metadata.Name), zap.Error(err))
+		return
+	}
+	w.logger.Info("deleted task torn down", zap.String("task", task.Metadata.Name))
+}
+
+// teardown stops a deleted pod of the node once no task is executing on it
+// and then clears the runtime finalizer, which removes the pod.
+func (w *Worker) teardown(ctx context.Context, key string, pod *v1alpha1.AgentPod) {
+	if !pod.Metadata.HasFinalizer(v1alpha1.FinalizerRuntime) {
+		return
+	}
+	busy := false
+	w.mu.Lock()
+	for _, podKey := range w.running {
+		busy = busy || podKey == key
+	}
+	w.mu.Unlock()
+	if busy {
+		// syncTasks has cancelled the executions; they write their results
+		// before the pod goes.
+		return
+	}
+
+	if err := w.runtime.StopPod(ctx, pod.Metadata.Name, pod.Metadata.Project); err != nil {
+		w.logger.Error("failed to stop deleted pod", zap.String("pod", pod.Metadata.Name), zap.Error(err))
+		return
+	}
+	w.mu.Lock()
+	delete(w.started, key)
+	w.mu.Unlock()
+
+	finalizers := slices.DeleteFunc(slices.Clone(pod.Metadata.Finalizers), func(f string) bool { return f == v1alpha1.FinalizerRuntime })
+	if err := w.setFinalizers(v1alpha1.KindAgentPod, &pod.Metadata, finalizers); err != nil {
+		w.logger.Error("failed to clear the runtime finalizer", zap.String("pod", pod.Metadata.Name), zap.Error(err))
+		return
+	}
+	w.logger.Info("deleted pod stopped", zap.String("pod", pod.Metadata.Name))
+}
+
+// setFinalizers replaces the finalizers of the object of kind with metadata
+// meta. An update could not clear the last one: the server keeps the
+// finalizers of an update without any.
+func (w *Worker) setFinalizers(kind string, meta *v1alpha1.ObjectMeta, finalizers []string) error {
+	if finalizers == nil {
+		finalizers = []string{}
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"finalizers": finalizers},
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.client.Patch(kind, meta.Name, meta.Project, client.MergePatchType, patch, nil); err != nil {
+		return err
+	}
+	meta.Finalizers = finalizers
+	return nil
+}
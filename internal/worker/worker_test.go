@@ -0,0 +1,193 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/klubi/orca/internal/agent"
+	"github.com/klubi/orca/internal/config"
+	"github.com/klubi/orca/internal/store"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+	"github.com/klubi/orca/pkg/client/fake"
+)
+
+func pendingPod(name, node string) *v1alpha1.AgentPod {
+	return &v1alpha1.AgentPod{
+		Metadata: v1alpha1.ObjectMeta{Name: name, Project: "demo"},
+		Spec:     v1alpha1.AgentPodSpec{Model: "claude-sonnet-4-20250514", NodeName: node},
+		Status:   v1alpha1.AgentPodStatus{Phase: v1alpha1.PodPending},
+	}
+}
+
+func newTestWorker(t *testing.T, c *fake.Client) *Worker {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Store.DataDir = t.TempDir()
+	w := New("node-1", c, agent.NewExecutor("claude", zap.NewNop()), cfg, time.Second, zap.NewNop())
+	if err := w.register(); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestSyncStartsAssignedPods(t *testing.T) {
+	c := fake.NewClient(
+		pendingPod("local", ""),
+		pendingPod("mine", "node-1"),
+		pendingPod("other", "node-2"),
+		&v1alpha1.DevTask{
+			Metadata: v1alpha1.ObjectMeta{Name: "orphan", Project: "demo"},
+			Spec:     v1alpha1.DevTaskSpec{Prompt: "hi"},
+			Status:   v1alpha1.DevTaskStatus{Phase: v1alpha1.TaskRunning, AssignedPod: "mine"},
+		},
+	)
+	w := newTestWorker(t, c)
+	if err := w.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]v1alpha1.AgentPodPhase{
+		"local": v1alpha1.PodPending,
+		"mine":  v1alpha1.PodReady,
+		"other": v1alpha1.PodPending,
+	} {
+		pod, err := c.GetAgentPod(name, "demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pod.Status.Phase != want {
+			t.Errorf("pod %s is %s, want %s", name, pod.Status.Phase, want)
+		}
+	}
+
+	node, err := c.GetAgentNode("node-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Status.Phase != v1alpha1.NodeReady || node.Status.Pods != 1 || node.Status.LastHeartbeat == nil {
+		t.Errorf("node status = %+v, want Ready with 1 pod and a heartbeat", node.Status)
+	}
+
+	task, err := c.GetDevTask("orphan", "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status.Phase != v1alpha1.TaskFailed || !strings.HasPrefix(task.Status.Error, "interrupted") {
+		t.Errorf("orphaned task is %s (%q), want Failed as interrupted", task.Status.Phase, task.Status.Error)
+	}
+}
+
+func TestSyncHonorsMaxPods(t *testing.T) {
+	c := fake.NewClient(pendingPod("a", "node-1"), pendingPod("b", "node-1"))
+	w := newTestWorker(t, c)
+	node, _ := c.GetAgentNode("node-1")
+	node.Spec.MaxPods = 1
+	if _, err := c.UpdateAgentNode(node); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ready := 0
+	for _, name := range []string{"a", "b"} {
+		pod, err := c.GetAgentPod(name, "demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pod.Status.Phase == v1alpha1.PodReady {
+			ready++
+		}
+	}
+	if ready != 1 {
+		t.Errorf("%d pods started, want 1", ready)
+	}
+}
+
+func TestSyncTearsDownDeletedWorkloads(t *testing.T) {
+	c := fake.NewClient(
+		pendingPod("mine", "node-1"),
+		&v1alpha1.DevTask{
+			Metadata: v1alpha1.ObjectMeta{Name: "done", Project: "demo", Finalizers: []string{v1alpha1.FinalizerExecution}},
+			Spec:     v1alpha1.DevTaskSpec{Prompt: "hi"},
+			Status:   v1alpha1.DevTaskStatus{Phase: v1alpha1.TaskSucceeded, AssignedPod: "mine"},
+		},
+	)
+	w := newTestWorker(t, c)
+	if err := w.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	pod, err := c.GetAgentPod("mine", "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pod.Status.Phase != v1alpha1.PodReady || !pod.Metadata.HasFinalizer(v1alpha1.FinalizerRuntime) {
+		t.Fatalf("started pod is %s with finalizers %v, want Ready with the runtime finalizer", pod.Status.Phase, pod.Metadata.Finalizers)
+	}
+
+	dir := w.runtime.ArtifactDir("demo", "done")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteDevTask("done", "demo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteAgentPod("mine", "demo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetAgentPod("mine", "demo"); err != nil {
+		t.Fatalf("a deleted pod with a finalizer is gone before the worker stopped it: %v", err)
+	}
+
+	if err := w.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetDevTask("done", "demo"); !client.IsNotFound(err) {
+		t.Errorf("getting the torn down task: %v, want not found", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("artifacts of the torn down task: %v, want them removed", err)
+	}
+	if _, err := c.GetAgentPod("mine", "demo"); !client.IsNotFound(err) {
+		t.Errorf("getting the stopped pod: %v, want not found", err)
+	}
+	if w.started[store.ResourceKey(v1alpha1.KindAgentPod, "demo", "mine")] {
+		t.Error("the stopped pod is still recorded as started")
+	}
+}
+
+func TestExecuteAddsFinalizer(t *testing.T) {
+	c := fake.NewClient(pendingPod("mine", "node-1"))
+	w := newTestWorker(t, c)
+	if err := w.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add(&v1alpha1.DevTask{
+		Metadata: v1alpha1.ObjectMeta{Name: "fix", Project: "demo"},
+		Spec:     v1alpha1.DevTaskSpec{Prompt: "hi"},
+		Status:   v1alpha1.DevTaskStatus{Phase: v1alpha1.TaskScheduled, AssignedPod: "mine"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The execution, which the finalizer outlives, is cut short.
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := w.sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	w.wg.Wait()
+
+	task, err := c.GetDevTask("fix", "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status.Phase == v1alpha1.TaskScheduled || !task.Metadata.HasFinalizer(v1alpha1.FinalizerExecution) {
+		t.Errorf("launched task is %s with finalizers %v, want it launched with the execution finalizer", task.Status.Phase, task.Metadata.Finalizers)
+	}
+}
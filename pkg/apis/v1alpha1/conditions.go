@@ -0,0 +1,114 @@
+package v1alpha1
+
+import "time"
+
+// ConditionStatus is the status of a Condition.
+type ConditionStatus string
+
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// Condition types.
+const (
+	// ConditionReady is True on an AgentPod that can run tasks.
+	ConditionReady = "Ready"
+	// ConditionAvailable is True on an AgentPool whose desired replicas are
+	// all Ready or Busy.
+	ConditionAvailable = "Available"
+	// ConditionProgressing is True on an AgentPool while a rolling update
+	// replaces pods created from an outdated template.
+	ConditionProgressing = "Progressing"
+	// ConditionSchedulable is True on a DevTask assigned to a pod, and False
+	// while no pod can take it.
+	ConditionSchedulable = "Schedulable"
+	// ConditionComplete is True on a DevTask that succeeded and False on one
+	// that failed.
+	ConditionComplete = "Complete"
+)
+
+// Condition reasons.
+const (
+	ReasonStarting         = "Starting"
+	ReasonStarted          = "Started"
+	ReasonHeartbeatTimeout = "HeartbeatTimeout"
+	ReasonRestarting       = "Restarting"
+	ReasonTerminating      = "Terminating"
+	ReasonTerminated       = "Terminated"
+
+	ReasonReplicasAvailable   = "MinimumReplicasAvailable"
+	ReasonReplicasUnavailable = "ReplicasUnavailable"
+	ReasonRollingUpdate       = "RollingUpdate"
+	ReasonUpToDate            = "UpToDate"
+
+	ReasonScheduled      = "Scheduled"
+	ReasonNoCapacity     = "NoCapacity"
+	ReasonBudgetExceeded = "BudgetExceeded"
+	ReasonPodNotFound    = "PodNotFound"
+	ReasonSucceeded      = "Succeeded"
+	ReasonFailed         = "Failed"
+	ReasonCancelled      = "Cancelled"
+	ReasonInterrupted    = "Interrupted"
+	ReasonRetryPending   = "RetryPending"
+)
+
+// Condition is one aspect of the state of a resource, such as whether a
+// task is schedulable, with a machine-readable reason. Unlike a phase,
+// several conditions hold at once.
+type Condition struct {
+	Type   string          `json:"type" yaml:"type"`
+	Status ConditionStatus `json:"status" yaml:"status"`
+	// Reason is a CamelCase code for the status, e.g. "NoCapacity".
+	Reason  string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// LastTransitionTime is when the status last changed.
+	LastTransitionTime time.Time `json:"lastTransitionTime,omitempty" yaml:"lastTransitionTime,omitempty"`
+}
+
+// NewCondition returns a condition of type t.
+func NewCondition(t string, status ConditionStatus, reason, message string) Condition {
+	return Condition{Type: t, Status: status, Reason: reason, Message: message}
+}
+
+// FindCondition returns the condition of type t in conds, or nil.
+func FindCondition(conds []Condition, t string) *Condition {
+	for i := range conds {
+		if conds[i].Type == t {
+			return &conds[i]
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue reports whether conds has a condition of type t with
+// status True.
+func IsConditionTrue(conds []Condition, t string) bool {
+	c := FindCondition(conds, t)
+	return c != nil && c.Status == ConditionTrue
+}
+
+// SetCondition adds c to conds, replacing the condition of the same type,
+// and reports whether anything changed. LastTransitionTime is kept unless
+// the status changes, when it is set to now unless c has one.
+func SetCondition(conds *[]Condition, c Condition) bool {
+	existing := FindCondition(*conds, c.Type)
+	if existing == nil {
+		if c.LastTransitionTime.IsZero() {
+			c.LastTransitionTime = time.Now()
+		}
+		*conds = append(*conds, c)
+		return true
+	}
+	if existing.Status == c.Status && existing.Reason == c.Reason && existing.Message == c.Message {
+		return false
+	}
+	if existing.Status == c.Status {
+		c.LastTransitionTime = existing.LastTransitionTime
+	} else if c.LastTransitionTime.IsZero() {
+		c.LastTransitionTime = time.Now()
+	}
+	*existing = c
+	return true
+}
@@ -1,7 +1,14 @@
 // Package v1alpha1 defines all Orca resource types.
 package v1alpha1
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
 
 const (
 	APIVersion = "orca.dev/v1alpha1"
@@ -13,6 +20,53 @@ const (
 	KindAgentPod  = "AgentPod"
 	KindAgentPool = "AgentPool"
 	KindDevTask   = "DevTask"
+	KindSecret    = "Secret"
+	KindConfigMap = "ConfigMap"
+	KindEvent     = "Event"
+
+	KindModelProvider  = "ModelProvider"
+	KindToolDefinition = "ToolDefinition"
+
+	KindAgentPoolAutoscaler = "AgentPoolAutoscaler"
+
+	KindAgentNode = "AgentNode"
+
+	KindAuditEntry = "AuditEntry"
+)
+
+// ClusterScoped reports whether objects of kind belong to no project.
+func ClusterScoped(kind string) bool {
+	return kind == KindProject || kind == KindAgentNode || kind == KindAuditEntry
+}
+
+// Well-known labels
+const (
+	// LabelTemplateHash is set on pool-managed pods to the hash of the pool
+	// template they were created from; pods with an outdated hash are
+	// replaced by a rolling update.
+	LabelTemplateHash = "orca.dev/template-hash"
+	// LabelRestartedAt is set on a pool template by `orca rollout restart`
+	// to force a rolling replacement of every pod.
+	LabelRestartedAt = "orca.dev/restarted-at"
+)
+
+// Well-known finalizers
+const (
+	// FinalizerRuntime is set on the pods the local runtime starts, so that
+	// deleting one stops its runtime before the pod is removed.
+	FinalizerRuntime = "orca.dev/runtime"
+	// FinalizerExecution is set on the tasks the local runtime executes, so
+	// that deleting one cancels its execution and removes its artifacts
+	// before the task is removed.
+	FinalizerExecution = "orca.dev/execution"
+)
+
+// Well-known annotations
+const (
+	// AnnotationTraceParent carries the W3C trace context of the API request
+	// that created a task, so that its reconciles and execution join the
+	// request's trace.
+	AnnotationTraceParent = "orca.dev/traceparent"
 )
 
 // TypeMeta describes the API version and kind of a resource.
@@ -23,14 +77,104 @@ type TypeMeta struct {
 
 // ObjectMeta holds metadata common to all resources.
 type ObjectMeta struct {
-	Name      string            `json:"name" yaml:"name"`
-	Project   string            `json:"project,omitempty" yaml:"project,omitempty"`
-	Labels    map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
-	UID       string            `json:"uid,omitempty" yaml:"uid,omitempty"`
-	CreatedAt time.Time         `json:"createdAt,omitempty" yaml:"createdAt,omitempty"`
-	UpdatedAt time.Time         `json:"updatedAt,omitempty" yaml:"updatedAt,omitempty"`
+	Name    string            `json:"name" yaml:"name"`
+	Project string            `json:"project,omitempty" yaml:"project,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Annotations hold arbitrary non-identifying data for tools and users.
+	// Unlike labels, they cannot be selected on.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	UID         string            `json:"uid,omitempty" yaml:"uid,omitempty"`
+	// Generation is set by the store: 1 on create, then bumped by every
+	// write that changes anything but the metadata and status, i.e. the
+	// desired state.
+	Generation int64 `json:"generation,omitempty" yaml:"generation,omitempty"`
+	// ResourceVersion is set by the store to an opaque value that changes
+	// on every write of the object.
+	ResourceVersion string    `json:"resourceVersion,omitempty" yaml:"resourceVersion,omitempty"`
+	CreatedAt       time.Time `json:"createdAt,omitempty" yaml:"createdAt,omitempty"`
+	UpdatedAt       time.Time `json:"updatedAt,omitempty" yaml:"updatedAt,omitempty"`
+	// Finalizers name the teardown that must happen before the object is
+	// removed. Deleting an object with finalizers only sets its
+	// DeletionTimestamp; the store removes it once the controllers that
+	// set them have cleared them all.
+	Finalizers []string `json:"finalizers,omitempty" yaml:"finalizers,omitempty"`
+	// DeletionTimestamp is set by the store when an object with finalizers
+	// is deleted. Once set it cannot be cleared.
+	DeletionTimestamp *time.Time `json:"deletionTimestamp,omitempty" yaml:"deletionTimestamp,omitempty"`
+	// OwnerReferences list the objects, in the same project, this one
+	// depends on. The garbage collector deletes an object once its
+	// controller is gone.
+	OwnerReferences []OwnerReference `json:"ownerReferences,omitempty" yaml:"ownerReferences,omitempty"`
 }
 
+// OwnerReference identifies an owner of an object, in the object's project.
+type OwnerReference struct {
+	Kind string `json:"kind" yaml:"kind"`
+	Name string `json:"name" yaml:"name"`
+	// UID tells the owner apart from a later object of the same name.
+	UID string `json:"uid,omitempty" yaml:"uid,omitempty"`
+	// Controller marks the owner that manages the object. An object has
+	// at most one.
+	Controller bool `json:"controller,omitempty" yaml:"controller,omitempty"`
+}
+
+// NewControllerRef returns a reference to owner, of kind, as the
+// controller of the objects it manages.
+func NewControllerRef(kind string, owner *ObjectMeta) OwnerReference {
+	return OwnerReference{Kind: kind, Name: owner.Name, UID: owner.UID, Controller: true}
+}
+
+// ControllerRef returns the owner reference of m's controller, or nil if m
+// has none.
+func (m *ObjectMeta) ControllerRef() *OwnerReference {
+	for i := range m.OwnerReferences {
+		if m.OwnerReferences[i].Controller {
+			return &m.OwnerReferences[i]
+		}
+	}
+	return nil
+}
+
+// HasFinalizer reports whether m has the finalizer f.
+func (m *ObjectMeta) HasFinalizer(f string) bool {
+	return slices.Contains(m.Finalizers, f)
+}
+
+// AddFinalizer adds the finalizer f to m, unless m has it already.
+func (m *ObjectMeta) AddFinalizer(f string) {
+	if !m.HasFinalizer(f) {
+		m.Finalizers = append(m.Finalizers, f)
+	}
+}
+
+// RemoveFinalizer removes the finalizer f from m. It reports whether m had
+// it.
+func (m *ObjectMeta) RemoveFinalizer(f string) bool {
+	i := slices.Index(m.Finalizers, f)
+	if i < 0 {
+		return false
+	}
+	m.Finalizers = slices.Delete(m.Finalizers, i, i+1)
+	return true
+}
+
+// Object is implemented by every resource kind stored with ObjectMeta.
+type Object interface {
+	GetObjectMeta() *ObjectMeta
+}
+
+func (p *Project) GetObjectMeta() *ObjectMeta             { return &p.Metadata }
+func (p *AgentPod) GetObjectMeta() *ObjectMeta            { return &p.Metadata }
+func (p *AgentPool) GetObjectMeta() *ObjectMeta           { return &p.Metadata }
+func (a *AgentPoolAutoscaler) GetObjectMeta() *ObjectMeta { return &a.Metadata }
+func (t *DevTask) GetObjectMeta() *ObjectMeta             { return &t.Metadata }
+func (s *Secret) GetObjectMeta() *ObjectMeta              { return &s.Metadata }
+func (c *ConfigMap) GetObjectMeta() *ObjectMeta           { return &c.Metadata }
+func (m *ModelProvider) GetObjectMeta() *ObjectMeta       { return &m.Metadata }
+func (t *ToolDefinition) GetObjectMeta() *ObjectMeta      { return &t.Metadata }
+func (e *Event) GetObjectMeta() *ObjectMeta               { return &e.Metadata }
+func (n *AgentNode) GetObjectMeta() *ObjectMeta           { return &n.Metadata }
+
 // -------------------------------------------------------
 // Project
 // -------------------------------------------------------
@@ -46,6 +190,87 @@ type Project struct {
 type ProjectSpec struct {
 	Description string `json:"description,omitempty" yaml:"description,omitempty"`
 	Path        string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Defaults fill the fields that the resources created in the project
+	// leave empty, before the server's own defaults.
+	Defaults ProjectDefaults `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// Policy restricts what the resources of the project may use. It is
+	// enforced when they are created or updated.
+	Policy ProjectPolicy `json:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// ProjectDefaults are the values a project gives the resources created in
+// it that do not set their own.
+type ProjectDefaults struct {
+	// Model and MaxTokens default the spec of AgentPods and of the pod
+	// template of AgentPools.
+	Model     string `json:"model,omitempty" yaml:"model,omitempty"`
+	MaxTokens int    `json:"maxTokens,omitempty" yaml:"maxTokens,omitempty"`
+	// Labels are added to every resource of the project that does not
+	// already have a label with the same key.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// ProjectPolicy holds the limits of a project.
+type ProjectPolicy struct {
+	// AllowedModels, when set, are the only models the project's pods, pool
+	// templates and tasks' preferredModel may name.
+	AllowedModels []string `json:"allowedModels,omitempty" yaml:"allowedModels,omitempty"`
+	// TokenBudget and CostBudgetUSD cap the tokens and the cost of the
+	// tasks in the project, summed over their last attempts (0 = no cap).
+	// Once either is spent, no new task is accepted or scheduled.
+	TokenBudget   int     `json:"tokenBudget,omitempty" yaml:"tokenBudget,omitempty"`
+	CostBudgetUSD float64 `json:"costBudgetUSD,omitempty" yaml:"costBudgetUSD,omitempty"`
+}
+
+// AllowsModel reports whether the policy lets resources use model. An
+// empty model, left to the server default, is always allowed.
+func (p *ProjectPolicy) AllowsModel(model string) bool {
+	if model == "" || len(p.AllowedModels) == 0 {
+		return true
+	}
+	for _, m := range p.AllowedModels {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// -------------------------------------------------------
+// AgentNode
+// -------------------------------------------------------
+
+// NodePhase represents whether an AgentNode is taking work.
+type NodePhase string
+
+const (
+	NodeReady    NodePhase = "Ready"
+	NodeNotReady NodePhase = "NotReady"
+)
+
+// AgentNode is a machine running `orca agent`, which executes the pods
+// assigned to it (spec.nodeName) instead of the control plane. Like
+// Projects, nodes belong to no project. A worker registers its node when it
+// starts and keeps its heartbeat current while it runs.
+type AgentNode struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Metadata ObjectMeta      `json:"metadata" yaml:"metadata"`
+	Spec     AgentNodeSpec   `json:"spec" yaml:"spec"`
+	Status   AgentNodeStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+type AgentNodeSpec struct {
+	// MaxPods caps how many pods the node runs at once (0 = unlimited).
+	MaxPods int `json:"maxPods,omitempty" yaml:"maxPods,omitempty"`
+}
+
+type AgentNodeStatus struct {
+	Phase    NodePhase `json:"phase,omitempty" yaml:"phase,omitempty"`
+	Hostname string    `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	Version  string    `json:"version,omitempty" yaml:"version,omitempty"`
+	// Pods is the number of pods the node is running.
+	Pods          int        `json:"pods" yaml:"pods"`
+	LastHeartbeat *time.Time `json:"lastHeartbeat,omitempty" yaml:"lastHeartbeat,omitempty"`
 }
 
 // -------------------------------------------------------
@@ -56,23 +281,62 @@ type ProjectSpec struct {
 type AgentPodPhase string
 
 const (
-	PodPending      AgentPodPhase = "Pending"
-	PodStarting     AgentPodPhase = "Starting"
-	PodReady        AgentPodPhase = "Ready"
-	PodBusy         AgentPodPhase = "Busy"
-	PodFailed       AgentPodPhase = "Failed"
-	PodTerminating  AgentPodPhase = "Terminating"
-	PodTerminated   AgentPodPhase = "Terminated"
+	PodPending     AgentPodPhase = "Pending"
+	PodStarting    AgentPodPhase = "Starting"
+	PodReady       AgentPodPhase = "Ready"
+	PodBusy        AgentPodPhase = "Busy"
+	PodFailed      AgentPodPhase = "Failed"
+	PodTerminating AgentPodPhase = "Terminating"
+	PodTerminated  AgentPodPhase = "Terminated"
+)
+
+// PermissionMode mirrors the Claude CLI --permission-mode values.
+type PermissionMode string
+
+const (
+	PermissionDefault     PermissionMode = "default"
+	PermissionAcceptEdits PermissionMode = "acceptEdits"
+	PermissionPlan        PermissionMode = "plan"
+	PermissionBypass      PermissionMode = "bypassPermissions"
 )
 
 // AgentPod represents a running AI agent instance.
 type AgentPod struct {
 	TypeMeta `json:",inline" yaml:",inline"`
-	Metadata ObjectMeta   `json:"metadata" yaml:"metadata"`
-	Spec     AgentPodSpec `json:"spec" yaml:"spec"`
+	Metadata ObjectMeta     `json:"metadata" yaml:"metadata"`
+	Spec     AgentPodSpec   `json:"spec" yaml:"spec"`
 	Status   AgentPodStatus `json:"status,omitempty" yaml:"status,omitempty"`
 }
 
+// OwnerPool returns the name of the AgentPool that created the pod, or ""
+// if it is standalone.
+func (p *AgentPod) OwnerPool() string {
+	if ref := p.Metadata.ControllerRef(); ref != nil && ref.Kind == KindAgentPool {
+		return ref.Name
+	}
+	return ""
+}
+
+// UnmarshalJSON decodes a pod, turning the spec.ownerPool that linked pods
+// to their pool before owner references into the pod's controller
+// reference.
+func (p *AgentPod) UnmarshalJSON(data []byte) error {
+	type plain AgentPod
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+	var legacy struct {
+		Spec struct {
+			OwnerPool string `json:"ownerPool"`
+		} `json:"spec"`
+	}
+	if json.Unmarshal(data, &legacy) == nil && legacy.Spec.OwnerPool != "" && p.Metadata.ControllerRef() == nil {
+		p.Metadata.OwnerReferences = append(p.Metadata.OwnerReferences,
+			OwnerReference{Kind: KindAgentPool, Name: legacy.Spec.OwnerPool, Controller: true})
+	}
+	return nil
+}
+
 type AgentPodSpec struct {
 	Model          string   `json:"model" yaml:"model"`
 	SystemPrompt   string   `json:"systemPrompt,omitempty" yaml:"systemPrompt,omitempty"`
@@ -81,18 +345,106 @@ type AgentPodSpec struct {
 	MaxTokens      int      `json:"maxTokens,omitempty" yaml:"maxTokens,omitempty"`
 	Tools          []string `json:"tools,omitempty" yaml:"tools,omitempty"`
 	RestartPolicy  string   `json:"restartPolicy,omitempty" yaml:"restartPolicy,omitempty"`
-	// OwnerPool tracks which AgentPool created this pod (empty if standalone).
-	OwnerPool string `json:"ownerPool,omitempty" yaml:"ownerPool,omitempty"`
+	// DisallowedTools lists tools the agent must never use, even if the
+	// permission mode would otherwise allow them.
+	DisallowedTools []string `json:"disallowedTools,omitempty" yaml:"disallowedTools,omitempty"`
+	// MaxTurns caps the number of agentic turns per task (0 = CLI default).
+	MaxTurns int `json:"maxTurns,omitempty" yaml:"maxTurns,omitempty"`
+	// PermissionMode controls how the agent may use tools without asking.
+	PermissionMode PermissionMode `json:"permissionMode,omitempty" yaml:"permissionMode,omitempty"`
+	// WarmSessions is the number of CLI processes kept pre-started for this
+	// pod so tasks do not pay the CLI cold-start cost.
+	WarmSessions int `json:"warmSessions,omitempty" yaml:"warmSessions,omitempty"`
+	// MaxRequestsPerMinute caps how many Claude calls this pod starts in any
+	// one-minute window (0 = unlimited). Excess tasks wait for a free slot.
+	MaxRequestsPerMinute int `json:"maxRequestsPerMinute,omitempty" yaml:"maxRequestsPerMinute,omitempty"`
+	// Provider names the ModelProvider in the pod's project that serves the
+	// pod's requests. Empty uses the claude CLI configured on the server.
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	// Env lists environment variables injected into the agent process.
+	Env []EnvVar `json:"env,omitempty" yaml:"env,omitempty"`
+	// EnvFrom imports every key of the referenced Secrets and ConfigMaps as
+	// environment variables.
+	EnvFrom []EnvFromSource `json:"envFrom,omitempty" yaml:"envFrom,omitempty"`
+	// Volumes expose the keys of Secrets and ConfigMaps to the agent as
+	// files, one per key.
+	Volumes []Volume `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	// NodeName assigns the pod to the AgentNode of that name, whose worker
+	// starts it and runs its tasks. Empty runs it on the control plane.
+	NodeName string `json:"nodeName,omitempty" yaml:"nodeName,omitempty"`
+}
+
+// EnvVar is a single environment variable for an agent process. Either Value
+// or ValueFrom must be set.
+type EnvVar struct {
+	Name      string        `json:"name" yaml:"name"`
+	Value     string        `json:"value,omitempty" yaml:"value,omitempty"`
+	ValueFrom *EnvVarSource `json:"valueFrom,omitempty" yaml:"valueFrom,omitempty"`
+}
+
+// EnvVarSource selects the source of an environment variable's value.
+// Exactly one field must be set.
+type EnvVarSource struct {
+	SecretKeyRef    *SecretKeySelector    `json:"secretKeyRef,omitempty" yaml:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *ConfigMapKeySelector `json:"configMapKeyRef,omitempty" yaml:"configMapKeyRef,omitempty"`
+}
+
+// SecretKeySelector references a single key of a Secret in the pod's project.
+type SecretKeySelector struct {
+	Name string `json:"name" yaml:"name"`
+	Key  string `json:"key" yaml:"key"`
+}
+
+// EnvFromSource imports all keys of a Secret or ConfigMap, optionally
+// prefixed. Exactly one of SecretRef and ConfigMapRef must be set.
+type EnvFromSource struct {
+	Prefix       string              `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	SecretRef    *SecretReference    `json:"secretRef,omitempty" yaml:"secretRef,omitempty"`
+	ConfigMapRef *ConfigMapReference `json:"configMapRef,omitempty" yaml:"configMapRef,omitempty"`
+}
+
+// SecretReference names a Secret in the pod's project.
+type SecretReference struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+// ConfigMapKeySelector references a single key of a ConfigMap in the pod's
+// project.
+type ConfigMapKeySelector struct {
+	Name string `json:"name" yaml:"name"`
+	Key  string `json:"key" yaml:"key"`
+}
+
+// ConfigMapReference names a ConfigMap in the pod's project.
+type ConfigMapReference struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+// Volume makes the keys of a Secret or ConfigMap in the pod's project
+// available to the agent as files named after the keys. The agent is told
+// the directory holding them. Exactly one of Secret and ConfigMap must be
+// set.
+type Volume struct {
+	Name      string              `json:"name" yaml:"name"`
+	Secret    *SecretReference    `json:"secret,omitempty" yaml:"secret,omitempty"`
+	ConfigMap *ConfigMapReference `json:"configMap,omitempty" yaml:"configMap,omitempty"`
 }
 
 type AgentPodStatus struct {
-	Phase           AgentPodPhase `json:"phase" yaml:"phase"`
-	ActiveTasks     int           `json:"activeTasks" yaml:"activeTasks"`
-	CompletedTasks  int           `json:"completedTasks" yaml:"completedTasks"`
-	FailedTasks     int           `json:"failedTasks" yaml:"failedTasks"`
-	LastHeartbeat   time.Time     `json:"lastHeartbeat,omitempty" yaml:"lastHeartbeat,omitempty"`
-	Message         string        `json:"message,omitempty" yaml:"message,omitempty"`
-	StartedAt       time.Time     `json:"startedAt,omitempty" yaml:"startedAt,omitempty"`
+	Phase          AgentPodPhase `json:"phase" yaml:"phase"`
+	ActiveTasks    int           `json:"activeTasks" yaml:"activeTasks"`
+	CompletedTasks int           `json:"completedTasks" yaml:"completedTasks"`
+	FailedTasks    int           `json:"failedTasks" yaml:"failedTasks"`
+	LastHeartbeat  time.Time     `json:"lastHeartbeat,omitempty" yaml:"lastHeartbeat,omitempty"`
+	Message        string        `json:"message,omitempty" yaml:"message,omitempty"`
+	StartedAt      time.Time     `json:"startedAt,omitempty" yaml:"startedAt,omitempty"`
+	// TokensIn, TokensOut and CostUSD accumulate the usage of every task
+	// run on the pod.
+	TokensIn  int     `json:"tokensIn,omitempty" yaml:"tokensIn,omitempty"`
+	TokensOut int     `json:"tokensOut,omitempty" yaml:"tokensOut,omitempty"`
+	CostUSD   float64 `json:"costUSD,omitempty" yaml:"costUSD,omitempty"`
+	// Conditions detail the phase; see ConditionReady.
+	Conditions []Condition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
 }
 
 // -------------------------------------------------------
@@ -102,8 +454,8 @@ type AgentPodStatus struct {
 // AgentPool manages a group of identical AgentPods.
 type AgentPool struct {
 	TypeMeta `json:",inline" yaml:",inline"`
-	Metadata ObjectMeta    `json:"metadata" yaml:"metadata"`
-	Spec     AgentPoolSpec `json:"spec" yaml:"spec"`
+	Metadata ObjectMeta      `json:"metadata" yaml:"metadata"`
+	Spec     AgentPoolSpec   `json:"spec" yaml:"spec"`
 	Status   AgentPoolStatus `json:"status,omitempty" yaml:"status,omitempty"`
 }
 
@@ -111,6 +463,23 @@ type AgentPoolSpec struct {
 	Replicas int               `json:"replicas" yaml:"replicas"`
 	Selector map[string]string `json:"selector,omitempty" yaml:"selector,omitempty"`
 	Template AgentPodTemplate  `json:"template" yaml:"template"`
+	// PropagateLabels are the keys of the pool's labels that are copied to
+	// the pods it creates and to the tasks scheduled on them, so they can be
+	// filtered and reported on together. Labels the pod template or the
+	// task already set are kept.
+	PropagateLabels []string `json:"propagateLabels,omitempty" yaml:"propagateLabels,omitempty"`
+}
+
+// PropagatedLabels returns the labels of the pool listed in
+// Spec.PropagateLabels that it has.
+func (p *AgentPool) PropagatedLabels() map[string]string {
+	labels := make(map[string]string, len(p.Spec.PropagateLabels))
+	for _, k := range p.Spec.PropagateLabels {
+		if v, ok := p.Metadata.Labels[k]; ok {
+			labels[k] = v
+		}
+	}
+	return labels
 }
 
 type AgentPodTemplate struct {
@@ -118,10 +487,78 @@ type AgentPodTemplate struct {
 	Spec     AgentPodSpec `json:"spec" yaml:"spec"`
 }
 
+// Hash returns a short, stable hash of the template, used to tell pods
+// created from it apart from pods created from earlier versions.
+func (t *AgentPodTemplate) Hash() string {
+	raw, _ := json.Marshal(t)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])[:10]
+}
+
 type AgentPoolStatus struct {
 	Replicas      int `json:"replicas" yaml:"replicas"`
 	ReadyReplicas int `json:"readyReplicas" yaml:"readyReplicas"`
 	BusyReplicas  int `json:"busyReplicas" yaml:"busyReplicas"`
+	// UpdatedReplicas counts pods created from the current template.
+	UpdatedReplicas int `json:"updatedReplicas" yaml:"updatedReplicas"`
+	// TemplateHash identifies the current template (see LabelTemplateHash).
+	TemplateHash string `json:"templateHash,omitempty" yaml:"templateHash,omitempty"`
+	// ObservedGeneration is the generation of the pool this status was
+	// computed for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" yaml:"observedGeneration,omitempty"`
+	// Conditions are ConditionAvailable and ConditionProgressing.
+	Conditions []Condition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
+// -------------------------------------------------------
+// AgentPoolAutoscaler (HorizontalPodAutoscaler equivalent)
+// -------------------------------------------------------
+
+// AgentPoolAutoscaler keeps the replica count of an AgentPool between
+// MinReplicas and MaxReplicas, sized to the tasks waiting for or running on
+// its pods.
+type AgentPoolAutoscaler struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Metadata ObjectMeta                `json:"metadata" yaml:"metadata"`
+	Spec     AgentPoolAutoscalerSpec   `json:"spec" yaml:"spec"`
+	Status   AgentPoolAutoscalerStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+type AgentPoolAutoscalerSpec struct {
+	// Pool is the AgentPool, in the autoscaler's project, to scale.
+	Pool        string `json:"pool" yaml:"pool"`
+	MinReplicas int    `json:"minReplicas" yaml:"minReplicas"`
+	MaxReplicas int    `json:"maxReplicas" yaml:"maxReplicas"`
+	// ScaleDownDelaySeconds is how long after the last scaling the pool may
+	// be scaled down (0 = 300), so bursts of tasks don't make it flap.
+	ScaleDownDelaySeconds int `json:"scaleDownDelaySeconds,omitempty" yaml:"scaleDownDelaySeconds,omitempty"`
+}
+
+// Validate checks that the spec names a pool and a usable replica range.
+func (s *AgentPoolAutoscalerSpec) Validate() error {
+	switch {
+	case s.Pool == "":
+		return fmt.Errorf("pool must not be empty")
+	case s.MinReplicas < 0:
+		return fmt.Errorf("minReplicas must be >= 0, got %d", s.MinReplicas)
+	case s.MaxReplicas < 1:
+		return fmt.Errorf("maxReplicas must be >= 1, got %d", s.MaxReplicas)
+	case s.MinReplicas > s.MaxReplicas:
+		return fmt.Errorf("minReplicas (%d) must not exceed maxReplicas (%d)", s.MinReplicas, s.MaxReplicas)
+	case s.ScaleDownDelaySeconds < 0:
+		return fmt.Errorf("scaleDownDelaySeconds must be >= 0, got %d", s.ScaleDownDelaySeconds)
+	}
+	return nil
+}
+
+type AgentPoolAutoscalerStatus struct {
+	CurrentReplicas int       `json:"currentReplicas" yaml:"currentReplicas"`
+	DesiredReplicas int       `json:"desiredReplicas" yaml:"desiredReplicas"`
+	LastScaleTime   time.Time `json:"lastScaleTime,omitempty" yaml:"lastScaleTime,omitempty"`
+	Message         string    `json:"message,omitempty" yaml:"message,omitempty"`
+	// ObservedGeneration is the generation of the autoscaler this status
+	// was computed for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" yaml:"observedGeneration,omitempty"`
 }
 
 // -------------------------------------------------------
@@ -142,8 +579,8 @@ const (
 // DevTask represents a development task to be executed by an agent.
 type DevTask struct {
 	TypeMeta `json:",inline" yaml:",inline"`
-	Metadata ObjectMeta  `json:"metadata" yaml:"metadata"`
-	Spec     DevTaskSpec `json:"spec" yaml:"spec"`
+	Metadata ObjectMeta    `json:"metadata" yaml:"metadata"`
+	Spec     DevTaskSpec   `json:"spec" yaml:"spec"`
 	Status   DevTaskStatus `json:"status,omitempty" yaml:"status,omitempty"`
 }
 
@@ -154,6 +591,23 @@ type DevTaskSpec struct {
 	MaxRetries           int      `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
 	TimeoutSeconds       int      `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
 	DependsOn            []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	// OutputSchema is a JSON Schema the agent's answer must satisfy. When set,
+	// the parsed answer is stored in Status.StructuredOutput.
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty" yaml:"outputSchema,omitempty"`
+	// PromptTemplate is a Go text/template rendered with Params (e.g.
+	// "Review {{.file}}") to produce the prompt. It takes precedence over Prompt.
+	PromptTemplate string            `json:"promptTemplate,omitempty" yaml:"promptTemplate,omitempty"`
+	Params         map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+	// Pool restricts scheduling to the pods of this AgentPool.
+	Pool string `json:"pool,omitempty" yaml:"pool,omitempty"`
+	// Pod pins the task to the AgentPod of this name.
+	Pod string `json:"pod,omitempty" yaml:"pod,omitempty"`
+	// SessionID resumes an earlier task's Claude CLI session (its
+	// status.sessionID), continuing that conversation.
+	SessionID string `json:"sessionID,omitempty" yaml:"sessionID,omitempty"`
+	// Priority orders pending tasks: higher-priority tasks are reconciled
+	// and given free pods first. It defaults to 0 and may be negative.
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
 }
 
 type DevTaskStatus struct {
@@ -162,8 +616,273 @@ type DevTaskStatus struct {
 	Retries     int          `json:"retries" yaml:"retries"`
 	Output      string       `json:"output,omitempty" yaml:"output,omitempty"`
 	Error       string       `json:"error,omitempty" yaml:"error,omitempty"`
-	StartedAt   time.Time    `json:"startedAt,omitempty" yaml:"startedAt,omitempty"`
-	FinishedAt  time.Time    `json:"finishedAt,omitempty" yaml:"finishedAt,omitempty"`
+	// Cancelled is set when the task failed because it was cancelled.
+	// Cancelled tasks are not retried automatically.
+	Cancelled bool `json:"cancelled,omitempty" yaml:"cancelled,omitempty"`
+	// StructuredOutput holds the validated JSON answer for tasks with an OutputSchema.
+	StructuredOutput interface{} `json:"structuredOutput,omitempty" yaml:"structuredOutput,omitempty"`
+	StartedAt        time.Time   `json:"startedAt,omitempty" yaml:"startedAt,omitempty"`
+	FinishedAt       time.Time   `json:"finishedAt,omitempty" yaml:"finishedAt,omitempty"`
+	// Steps is the turn-by-turn trace of the last execution attempt.
+	Steps []TaskStep `json:"steps,omitempty" yaml:"steps,omitempty"`
+	// Model is the model the last attempt ran with.
+	Model string `json:"model,omitempty" yaml:"model,omitempty"`
+	// TokensIn, TokensOut and CostUSD are the usage of the last attempt.
+	TokensIn  int     `json:"tokensIn,omitempty" yaml:"tokensIn,omitempty"`
+	TokensOut int     `json:"tokensOut,omitempty" yaml:"tokensOut,omitempty"`
+	CostUSD   float64 `json:"costUSD,omitempty" yaml:"costUSD,omitempty"`
+	// SessionID identifies the Claude CLI session of the last attempt.
+	SessionID string `json:"sessionID,omitempty" yaml:"sessionID,omitempty"`
+	// Conditions are ConditionSchedulable and ConditionComplete.
+	Conditions []Condition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
+// TaskStepType identifies what an agent did in a TaskStep.
+type TaskStepType string
+
+const (
+	StepMessage    TaskStepType = "Message"
+	StepToolCall   TaskStepType = "ToolCall"
+	StepToolResult TaskStepType = "ToolResult"
+)
+
+// TaskStep is one entry in an agent's execution trace.
+type TaskStep struct {
+	// Turn is the 1-based assistant turn the step belongs to.
+	Turn int          `json:"turn" yaml:"turn"`
+	Type TaskStepType `json:"type" yaml:"type"`
+	// Tool is the tool name for ToolCall and ToolResult steps.
+	Tool string `json:"tool,omitempty" yaml:"tool,omitempty"`
+	// Input is the JSON-encoded tool input for ToolCall steps.
+	Input string `json:"input,omitempty" yaml:"input,omitempty"`
+	// Text is the message text or tool result, truncated for storage.
+	Text    string `json:"text,omitempty" yaml:"text,omitempty"`
+	IsError bool   `json:"isError,omitempty" yaml:"isError,omitempty"`
+	// TokensIn and TokensOut are the usage of the turn, recorded on its
+	// first step.
+	TokensIn  int `json:"tokensIn,omitempty" yaml:"tokensIn,omitempty"`
+	TokensOut int `json:"tokensOut,omitempty" yaml:"tokensOut,omitempty"`
+}
+
+// -------------------------------------------------------
+// Secret
+// -------------------------------------------------------
+
+// Secret holds sensitive values (API tokens, credentials) that are injected
+// into agent processes through AgentPodSpec.Env, EnvFrom and Volumes.
+type Secret struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Metadata ObjectMeta `json:"metadata" yaml:"metadata"`
+	// Data holds base64-encoded values.
+	Data map[string]string `json:"data,omitempty" yaml:"data,omitempty"`
+	// StringData holds plain-text values. It is merged into Data (base64
+	// encoded) when the Secret is stored and never returned by the server.
+	StringData map[string]string `json:"stringData,omitempty" yaml:"stringData,omitempty"`
+}
+
+// -------------------------------------------------------
+// ConfigMap
+// -------------------------------------------------------
+
+// ConfigMap holds non-sensitive configuration, such as shared prompts or
+// settings files, that is injected into agent processes through
+// AgentPodSpec.Env, EnvFrom and Volumes.
+type ConfigMap struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Metadata ObjectMeta `json:"metadata" yaml:"metadata"`
+	// Data holds plain-text values.
+	Data map[string]string `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+// -------------------------------------------------------
+// ModelProvider
+// -------------------------------------------------------
+
+// ProviderType selects the backend that executes a ModelProvider's requests.
+type ProviderType string
+
+const (
+	// ProviderClaudeCLI runs the claude CLI, with tools and agentic turns.
+	ProviderClaudeCLI ProviderType = "claude-cli"
+	// ProviderAnthropicAPI calls the Anthropic Messages API.
+	ProviderAnthropicAPI ProviderType = "anthropic-api"
+	// ProviderOpenAI calls an OpenAI-compatible chat completions API.
+	ProviderOpenAI ProviderType = "openai"
+	// ProviderOllama calls the OpenAI-compatible API of an Ollama server.
+	ProviderOllama ProviderType = "ollama"
+)
+
+// ModelProvider describes a model backend shared by the pods of a project
+// that reference it through AgentPodSpec.Provider.
+type ModelProvider struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Metadata ObjectMeta        `json:"metadata" yaml:"metadata"`
+	Spec     ModelProviderSpec `json:"spec" yaml:"spec"`
+}
+
+type ModelProviderSpec struct {
+	Type ProviderType `json:"type" yaml:"type"`
+	// Endpoint is the base URL of the provider's API. Empty uses the
+	// type's public endpoint (http://localhost:11434/v1 for ollama); for
+	// claude-cli it is passed to the CLI as ANTHROPIC_BASE_URL.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// CredentialsSecret selects the key of a Secret in the provider's
+	// project holding the API key. For claude-cli it is passed to the CLI as
+	// ANTHROPIC_API_KEY instead of using the local subscription.
+	CredentialsSecret *SecretKeySelector `json:"credentialsSecret,omitempty" yaml:"credentialsSecret,omitempty"`
+	// Costs prices the provider's models. A task run on a listed model is
+	// charged from this table instead of the cost the backend reports.
+	Costs []ModelCost `json:"costs,omitempty" yaml:"costs,omitempty"`
+	// MaxRequestsPerMinute caps how many requests all pods using the
+	// provider start in any one-minute window (0 = unlimited).
+	MaxRequestsPerMinute int `json:"maxRequestsPerMinute,omitempty" yaml:"maxRequestsPerMinute,omitempty"`
+}
+
+// ModelCost is the price of a model in USD per million tokens.
+type ModelCost struct {
+	Model         string  `json:"model" yaml:"model"`
+	InputPerMTok  float64 `json:"inputPerMTok" yaml:"inputPerMTok"`
+	OutputPerMTok float64 `json:"outputPerMTok" yaml:"outputPerMTok"`
+}
+
+// Cost returns the price of a call to model using the given tokens, and
+// whether the table lists model.
+func (s *ModelProviderSpec) Cost(model string, tokensIn, tokensOut int) (float64, bool) {
+	for _, c := range s.Costs {
+		if c.Model == model {
+			return (float64(tokensIn)*c.InputPerMTok + float64(tokensOut)*c.OutputPerMTok) / 1e6, true
+		}
+	}
+	return 0, false
+}
+
+// -------------------------------------------------------
+// ToolDefinition
+// -------------------------------------------------------
+
+// ToolDefinition names a tool that pods of its project may list in
+// AgentPodSpec.Tools and DisallowedTools, and binds it to what implements
+// it: Claude CLI tools, a shell command or an MCP server. A definition
+// overrides the built-in tool of the same name.
+type ToolDefinition struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Metadata ObjectMeta         `json:"metadata" yaml:"metadata"`
+	Spec     ToolDefinitionSpec `json:"spec" yaml:"spec"`
+}
+
+// ToolDefinitionSpec binds a tool. Exactly one of CLITools, Command and MCP
+// must be set.
+type ToolDefinitionSpec struct {
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// CLITools lists the Claude CLI tools implementing the tool, such as
+	// "Grep" or "Bash(git:*)".
+	CLITools []string `json:"cliTools,omitempty" yaml:"cliTools,omitempty"`
+	// Command is a command line prefix the agent may run through Bash,
+	// such as "make test".
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	// MCP is an MCP server whose tools the agent may use.
+	MCP *MCPServer `json:"mcp,omitempty" yaml:"mcp,omitempty"`
+	// Sandbox restricts the pods that use the tool.
+	Sandbox *ToolSandbox `json:"sandbox,omitempty" yaml:"sandbox,omitempty"`
+}
+
+// MCPServer describes an MCP server, started by the agent as a command or
+// reached at a URL. Exactly one of Command and URL must be set.
+type MCPServer struct {
+	Command string            `json:"command,omitempty" yaml:"command,omitempty"`
+	Args    []string          `json:"args,omitempty" yaml:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	URL     string            `json:"url,omitempty" yaml:"url,omitempty"`
+	// Tools limits the server's tools the agent may use (empty = all).
+	Tools []string `json:"tools,omitempty" yaml:"tools,omitempty"`
+}
+
+// ToolSandbox restricts every pod that uses a tool.
+type ToolSandbox struct {
+	// Deny lists Claude CLI tools or patterns, such as "Bash(git push:*)",
+	// that the pod must not use.
+	Deny []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+	// Paths lists directories outside the working directory the tool
+	// needs; the agent is given access to them.
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+}
+
+// -------------------------------------------------------
+// Event
+// -------------------------------------------------------
+
+// Event severities.
+const (
+	EventTypeNormal  = "Normal"
+	EventTypeWarning = "Warning"
+)
+
+// Event records something that happened to a resource, such as a task being
+// scheduled or failing to schedule. Repeated identical events are aggregated
+// into one Event with an increasing Count.
+type Event struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Metadata ObjectMeta `json:"metadata" yaml:"metadata"`
+	// InvolvedObject is the resource the event is about.
+	InvolvedObject ObjectReference `json:"involvedObject" yaml:"involvedObject"`
+	// Type is EventTypeNormal or EventTypeWarning.
+	Type string `json:"type" yaml:"type"`
+	// Reason is a short CamelCase code, e.g. "FailedScheduling".
+	Reason  string `json:"reason" yaml:"reason"`
+	Message string `json:"message" yaml:"message"`
+	// Source is the component that reported the event.
+	Source         string    `json:"source,omitempty" yaml:"source,omitempty"`
+	Count          int       `json:"count" yaml:"count"`
+	FirstTimestamp time.Time `json:"firstTimestamp" yaml:"firstTimestamp"`
+	LastTimestamp  time.Time `json:"lastTimestamp" yaml:"lastTimestamp"`
+}
+
+// AuditEntry records a mutating API request: who made it, what it changed
+// and when. The API server writes one per request when auditing is on.
+type AuditEntry struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	Metadata ObjectMeta `json:"metadata" yaml:"metadata"`
+	// User is the name of the bearer token the request carried, or
+	// "anonymous" when the server does not authenticate requests.
+	User string `json:"user" yaml:"user"`
+	// RequestID is the X-Request-ID of the request, which its log line and
+	// the controllers' logs of the changes it made carry too.
+	RequestID string `json:"requestID,omitempty" yaml:"requestID,omitempty"`
+	// Verb is create, update, patch, delete, deletecollection or apply.
+	Verb string `json:"verb" yaml:"verb"`
+	// Subresource is the part of the object the request addressed, e.g.
+	// status, scale or retry, if not all of it.
+	Subresource string `json:"subresource,omitempty" yaml:"subresource,omitempty"`
+	Method      string `json:"method" yaml:"method"`
+	Path        string `json:"path" yaml:"path"`
+	// Object is the resource the request addressed, when it addressed one.
+	Object     ObjectReference `json:"object" yaml:"object"`
+	StatusCode int             `json:"statusCode" yaml:"statusCode"`
+	// Diff is a JSON merge patch (RFC 7386) from the object before the
+	// request to the object after it: the whole object when the request
+	// created it, empty when it deleted it or changed nothing. Secret
+	// values are replaced by their hashes.
+	Diff      map[string]interface{} `json:"diff,omitempty" yaml:"diff,omitempty"`
+	Timestamp time.Time              `json:"timestamp" yaml:"timestamp"`
+}
+
+// DeletionPropagation says what deleting a Project does to the resources
+// in it.
+type DeletionPropagation string
+
+const (
+	// PropagationDelete deletes the project's tasks, autoscalers, pools and
+	// pods along with it. It is the default.
+	PropagationDelete DeletionPropagation = "delete"
+	// PropagationOrphan leaves them in place.
+	PropagationOrphan DeletionPropagation = "orphan"
+)
+
+// ObjectReference identifies a resource.
+type ObjectReference struct {
+	Kind    string `json:"kind" yaml:"kind"`
+	Name    string `json:"name" yaml:"name"`
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
 }
 
 // -------------------------------------------------------
@@ -181,10 +900,13 @@ const (
 
 // WatchEvent is emitted when a resource changes in the store.
 type WatchEvent struct {
-	Type     EventType
-	Kind     string
-	Key      string
-	Object   interface{}
+	Type   EventType   `json:"type"`
+	Kind   string      `json:"kind"`
+	Key    string      `json:"key"`
+	Object interface{} `json:"object,omitempty"`
+	// RequestID is the X-Request-ID of the API request that made the
+	// change, if an API request made it.
+	RequestID string `json:"requestID,omitempty"`
 }
 
 // -------------------------------------------------------
@@ -198,3 +920,20 @@ type LogEntry struct {
 	Level     string    `json:"level"`
 	Message   string    `json:"message"`
 }
+
+// TaskStreamEvent is one event of a running task's live output: either a
+// step the agent just took or, as the last event, the phase the attempt
+// finished in.
+type TaskStreamEvent struct {
+	Step  *TaskStep    `json:"step,omitempty"`
+	Phase DevTaskPhase `json:"phase,omitempty"`
+}
+
+// Artifact describes a file a task saved to its artifact directory. The
+// server keeps artifacts after the task finishes.
+type Artifact struct {
+	// Name is the file's path relative to the artifact directory.
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
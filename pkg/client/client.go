@@ -2,35 +2,115 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/klubi/orca/internal/version"
 	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
 )
 
 // Client communicates with the Orca API server.
 type Client struct {
 	baseURL    string
+	token      string
 	httpClient *http.Client
+	retry      RetryPolicy
+	userAgent  string
+
+	// The transport options, collected until New builds the transport.
+	tlsConfig      *tls.Config
+	proxy          func(*http.Request) (*url.URL, error)
+	maxIdlePerHost int
+	transport      http.RoundTripper
 }
 
+// defaultMaxIdleConnsPerHost is how many idle connections to the server a
+// client keeps open for reuse, up from net/http's 2, so concurrent requests
+// such as the TUI's refreshes don't keep dialing.
+const defaultMaxIdleConnsPerHost = 16
+
 // New creates a new Orca API client pointing at the given base URL
-// (e.g. "http://localhost:8080").
-func New(baseURL string) *Client {
-	return &Client{
-		baseURL:    baseURL,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+// (e.g. "http://localhost:8080"), configured by opts.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:        baseURL,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+		retry:          DefaultRetryPolicy(),
+		userAgent:      DefaultUserAgent(),
+		maxIdlePerHost: defaultMaxIdleConnsPerHost,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.httpClient.Transport = c.buildTransport()
+	return c
+}
+
+// buildTransport returns the transport set with WithTransport or, by
+// default, a pooling transport with the TLS and proxy options applied.
+func (c *Client) buildTransport() http.RoundTripper {
+	if c.transport != nil {
+		return c.transport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = c.maxIdlePerHost
+	transport.MaxIdleConns = max(transport.MaxIdleConns, c.maxIdlePerHost)
+	if c.tlsConfig != nil {
+		transport.TLSClientConfig = c.tlsConfig
+	}
+	if c.proxy != nil {
+		transport.Proxy = c.proxy
 	}
+	return transport
+}
+
+// DefaultUserAgent is the User-Agent header new clients send,
+// "orca/<version>".
+func DefaultUserAgent() string {
+	return "orca/" + version.Get()
+}
+
+// SetToken makes the client send token as a bearer token on every request.
+// An empty token disables the Authorization header.
+func (c *Client) SetToken(token string) {
+	c.token = token
 }
 
 // ---------------------------------------------------------------------------
 // Internal helpers
 // ---------------------------------------------------------------------------
 
+// do sends req with the client's credentials attached.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return c.send(c.httpClient, req)
+}
+
+// send is do with an explicit HTTP client, e.g. one without a timeout for
+// long-lived streams.
+func (c *Client) send(hc *http.Client, req *http.Request) (*http.Response, error) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	return resp, nil
+}
+
 // doRequest builds and executes an HTTP request.
 // If body is non-nil it is JSON-encoded and sent as the request body.
 func (c *Client) doRequest(method, path string, body interface{}) (*http.Response, error) {
@@ -52,37 +132,130 @@ func (c *Client) doRequest(method, path string, body interface{}) (*http.Respons
 	}
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
-	}
-	return resp, nil
+	return c.do(req)
 }
 
 // doJSON executes a request, checks for a 2xx status, and JSON-decodes
-// the response body into target (when target is non-nil).
+// the response body into target (when target is non-nil). Requests that
+// fail transiently are retried as the client's RetryPolicy allows.
 func (c *Client) doJSON(method, path string, body interface{}, target interface{}) error {
+	_, err := c.doJSONResponse(method, path, body, target)
+	return err
+}
+
+// doJSONResponse is doJSON that also returns the successful response, for
+// its status and headers; its body has been read.
+func (c *Client) doJSONResponse(method, path string, body interface{}, target interface{}) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, respBody, err := c.fetch(method, path, body)
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		if err == nil && status >= 200 && status < 300 {
+			if target != nil && len(respBody) > 0 {
+				if err := json.Unmarshal(respBody, target); err != nil {
+					return nil, fmt.Errorf("decode response body: %w", err)
+				}
+			}
+			return resp, nil
+		}
+
+		if attempt < c.retry.MaxRetries && retryable(method, err, status) {
+			time.Sleep(c.retry.backoff(attempt, resp))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, newAPIError(status, respBody)
+	}
+}
+
+// fetch executes a request and reads the whole response body.
+func (c *Client) fetch(method, path string, body interface{}) (*http.Response, []byte, error) {
 	resp, err := c.doRequest(method, path, body)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("read response body: %w", err)
+		return resp, nil, fmt.Errorf("read response body: %w", err)
 	}
+	return resp, respBody, nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+// ListOption narrows down the results of a List call.
+type ListOption func(url.Values)
+
+// WithLabelSelector limits a List call to the objects whose labels match
+// selector, e.g. "batch=nightly,team!=web".
+func WithLabelSelector(selector string) ListOption {
+	return func(q url.Values) {
+		if selector != "" {
+			q.Set("labelSelector", selector)
+		}
 	}
+}
 
-	if target != nil && len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, target); err != nil {
-			return fmt.Errorf("decode response body: %w", err)
+// WithPhases limits a DevTask List or DeleteCollection call to tasks in one
+// of phases.
+func WithPhases(phases ...v1alpha1.DevTaskPhase) ListOption {
+	return func(q url.Values) {
+		if len(phases) > 0 {
+			names := make([]string, len(phases))
+			for i, p := range phases {
+				names[i] = string(p)
+			}
+			q.Set("phase", strings.Join(names, ","))
 		}
 	}
-	return nil
+}
+
+// WithOlderThan limits a DevTask DeleteCollection call to tasks that
+// finished (or, if unfinished, were created) at least d ago.
+func WithOlderThan(d time.Duration) ListOption {
+	return func(q url.Values) {
+		if d > 0 {
+			q.Set("olderThan", d.String())
+		}
+	}
+}
+
+// WithDryRun makes a DeleteCollection call report what it would delete
+// without deleting anything.
+func WithDryRun() ListOption {
+	return func(q url.Values) {
+		q.Set("dryRun", "true")
+	}
+}
+
+// WithPropagation sets what a DeleteProject call does to the resources in
+// the project.
+func WithPropagation(p v1alpha1.DeletionPropagation) ListOption {
+	return func(q url.Values) {
+		if p != "" {
+			q.Set("propagation", string(p))
+		}
+	}
+}
+
+// listPath builds the path listing resource, optionally limited to project.
+func listPath(resource, project string, opts []ListOption) string {
+	q := url.Values{}
+	if project != "" {
+		q.Set("project", project)
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	path := "/api/v1alpha1/" + resource
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	return path
 }
 
 // ---------------------------------------------------------------------------
@@ -104,6 +277,28 @@ func (c *Client) Healthz() error {
 	return nil
 }
 
+// Readyz checks whether the control plane can execute tasks. The error
+// carries the server's reason when it is not ready.
+func (c *Client) Readyz() error {
+	resp, err := c.doRequest(http.MethodGet, "/readyz", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var status struct {
+			Reason string `json:"reason"`
+		}
+		if json.Unmarshal(body, &status) == nil && status.Reason != "" {
+			return fmt.Errorf("not ready: %s", status.Reason)
+		}
+		return fmt.Errorf("readyz failed (status %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // Projects
 // ---------------------------------------------------------------------------
@@ -127,9 +322,9 @@ func (c *Client) GetProject(name string) (*v1alpha1.Project, error) {
 }
 
 // ListProjects returns all projects.
-func (c *Client) ListProjects() ([]v1alpha1.Project, error) {
+func (c *Client) ListProjects(opts ...ListOption) ([]v1alpha1.Project, error) {
 	var out []v1alpha1.Project
-	if err := c.doJSON(http.MethodGet, "/api/v1alpha1/projects", nil, &out); err != nil {
+	if err := c.doJSON(http.MethodGet, listPath("projects", "", opts), nil, &out); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -145,9 +340,19 @@ func (c *Client) UpdateProject(p *v1alpha1.Project) (*v1alpha1.Project, error) {
 	return &out, nil
 }
 
-// DeleteProject removes a project by name.
-func (c *Client) DeleteProject(name string) error {
-	return c.doJSON(http.MethodDelete, fmt.Sprintf("/api/v1alpha1/projects/%s", name), nil, nil)
+// DeleteProject removes a project by name, along with its tasks,
+// autoscalers, pools and pods unless opts include WithPropagation(
+// v1alpha1.PropagationOrphan).
+func (c *Client) DeleteProject(name string, opts ...ListOption) error {
+	q := url.Values{}
+	for _, opt := range opts {
+		opt(q)
+	}
+	path := fmt.Sprintf("/api/v1alpha1/projects/%s", name)
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	return c.doJSON(http.MethodDelete, path, nil, nil)
 }
 
 // ---------------------------------------------------------------------------
@@ -175,10 +380,9 @@ func (c *Client) GetAgentPod(name, project string) (*v1alpha1.AgentPod, error) {
 }
 
 // ListAgentPods returns all agent pods in a project.
-func (c *Client) ListAgentPods(project string) ([]v1alpha1.AgentPod, error) {
+func (c *Client) ListAgentPods(project string, opts ...ListOption) ([]v1alpha1.AgentPod, error) {
 	var out []v1alpha1.AgentPod
-	path := fmt.Sprintf("/api/v1alpha1/agentpods?project=%s", project)
-	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+	if err := c.doJSON(http.MethodGet, listPath("agentpods", project, opts), nil, &out); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -225,10 +429,9 @@ func (c *Client) GetAgentPool(name, project string) (*v1alpha1.AgentPool, error)
 }
 
 // ListAgentPools returns all agent pools in a project.
-func (c *Client) ListAgentPools(project string) ([]v1alpha1.AgentPool, error) {
+func (c *Client) ListAgentPools(project string, opts ...ListOption) ([]v1alpha1.AgentPool, error) {
 	var out []v1alpha1.AgentPool
-	path := fmt.Sprintf("/api/v1alpha1/agentpools?project=%s", project)
-	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+	if err := c.doJSON(http.MethodGet, listPath("agentpools", project, opts), nil, &out); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -261,6 +464,312 @@ func (c *Client) ScaleAgentPool(name, project string, replicas int) (*v1alpha1.A
 	return &out, nil
 }
 
+// ScaleAgentPoolFrom adjusts the replica count of an agent pool only if it is
+// still current. The server rejects the request with 409 Conflict when the
+// pool's replica count differs, so concurrent scalings don't overwrite each
+// other.
+func (c *Client) ScaleAgentPoolFrom(name, project string, current, replicas int) (*v1alpha1.AgentPool, error) {
+	var out v1alpha1.AgentPool
+	path := fmt.Sprintf("/api/v1alpha1/agentpools/%s/scale?project=%s", name, project)
+	body := map[string]int{"replicas": replicas, "currentReplicas": current}
+	if err := c.doJSON(http.MethodPut, path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ---------------------------------------------------------------------------
+// AgentPoolAutoscalers
+// ---------------------------------------------------------------------------
+
+// CreateAutoscaler creates a new agent pool autoscaler in the given project.
+func (c *Client) CreateAutoscaler(as *v1alpha1.AgentPoolAutoscaler) (*v1alpha1.AgentPoolAutoscaler, error) {
+	var out v1alpha1.AgentPoolAutoscaler
+	path := fmt.Sprintf("/api/v1alpha1/autoscalers?project=%s", as.Metadata.Project)
+	if err := c.doJSON(http.MethodPost, path, as, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAutoscaler retrieves an autoscaler by name within a project.
+func (c *Client) GetAutoscaler(name, project string) (*v1alpha1.AgentPoolAutoscaler, error) {
+	var out v1alpha1.AgentPoolAutoscaler
+	path := fmt.Sprintf("/api/v1alpha1/autoscalers/%s?project=%s", name, project)
+	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAutoscalers returns all autoscalers in a project.
+func (c *Client) ListAutoscalers(project string, opts ...ListOption) ([]v1alpha1.AgentPoolAutoscaler, error) {
+	var out []v1alpha1.AgentPoolAutoscaler
+	if err := c.doJSON(http.MethodGet, listPath("autoscalers", project, opts), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateAutoscaler updates an existing autoscaler.
+func (c *Client) UpdateAutoscaler(as *v1alpha1.AgentPoolAutoscaler) (*v1alpha1.AgentPoolAutoscaler, error) {
+	var out v1alpha1.AgentPoolAutoscaler
+	path := fmt.Sprintf("/api/v1alpha1/autoscalers/%s?project=%s", as.Metadata.Name, as.Metadata.Project)
+	if err := c.doJSON(http.MethodPut, path, as, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAutoscaler removes an autoscaler by name within a project.
+func (c *Client) DeleteAutoscaler(name, project string) error {
+	path := fmt.Sprintf("/api/v1alpha1/autoscalers/%s?project=%s", name, project)
+	return c.doJSON(http.MethodDelete, path, nil, nil)
+}
+
+// ---------------------------------------------------------------------------
+// Secrets
+// ---------------------------------------------------------------------------
+
+// CreateSecret creates a new secret in the given project. StringData is
+// merged into Data by the server.
+func (c *Client) CreateSecret(sec *v1alpha1.Secret) (*v1alpha1.Secret, error) {
+	var out v1alpha1.Secret
+	path := fmt.Sprintf("/api/v1alpha1/secrets?project=%s", sec.Metadata.Project)
+	if err := c.doJSON(http.MethodPost, path, sec, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSecret retrieves a secret by name within a project.
+func (c *Client) GetSecret(name, project string) (*v1alpha1.Secret, error) {
+	var out v1alpha1.Secret
+	path := fmt.Sprintf("/api/v1alpha1/secrets/%s?project=%s", name, project)
+	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSecrets returns all secrets in a project.
+func (c *Client) ListSecrets(project string, opts ...ListOption) ([]v1alpha1.Secret, error) {
+	var out []v1alpha1.Secret
+	if err := c.doJSON(http.MethodGet, listPath("secrets", project, opts), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateSecret updates an existing secret.
+func (c *Client) UpdateSecret(sec *v1alpha1.Secret) (*v1alpha1.Secret, error) {
+	var out v1alpha1.Secret
+	path := fmt.Sprintf("/api/v1alpha1/secrets/%s?project=%s", sec.Metadata.Name, sec.Metadata.Project)
+	if err := c.doJSON(http.MethodPut, path, sec, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteSecret removes a secret by name within a project.
+func (c *Client) DeleteSecret(name, project string) error {
+	path := fmt.Sprintf("/api/v1alpha1/secrets/%s?project=%s", name, project)
+	return c.doJSON(http.MethodDelete, path, nil, nil)
+}
+
+// ---------------------------------------------------------------------------
+// ConfigMaps
+// ---------------------------------------------------------------------------
+
+// CreateConfigMap creates a new config map in the given project.
+func (c *Client) CreateConfigMap(cm *v1alpha1.ConfigMap) (*v1alpha1.ConfigMap, error) {
+	var out v1alpha1.ConfigMap
+	path := fmt.Sprintf("/api/v1alpha1/configmaps?project=%s", cm.Metadata.Project)
+	if err := c.doJSON(http.MethodPost, path, cm, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetConfigMap retrieves a config map by name within a project.
+func (c *Client) GetConfigMap(name, project string) (*v1alpha1.ConfigMap, error) {
+	var out v1alpha1.ConfigMap
+	path := fmt.Sprintf("/api/v1alpha1/configmaps/%s?project=%s", name, project)
+	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListConfigMaps returns all config maps in a project.
+func (c *Client) ListConfigMaps(project string, opts ...ListOption) ([]v1alpha1.ConfigMap, error) {
+	var out []v1alpha1.ConfigMap
+	if err := c.doJSON(http.MethodGet, listPath("configmaps", project, opts), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateConfigMap updates an existing config map.
+func (c *Client) UpdateConfigMap(cm *v1alpha1.ConfigMap) (*v1alpha1.ConfigMap, error) {
+	var out v1alpha1.ConfigMap
+	path := fmt.Sprintf("/api/v1alpha1/configmaps/%s?project=%s", cm.Metadata.Name, cm.Metadata.Project)
+	if err := c.doJSON(http.MethodPut, path, cm, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteConfigMap removes a config map by name within a project.
+func (c *Client) DeleteConfigMap(name, project string) error {
+	path := fmt.Sprintf("/api/v1alpha1/configmaps/%s?project=%s", name, project)
+	return c.doJSON(http.MethodDelete, path, nil, nil)
+}
+
+// ---------------------------------------------------------------------------
+// ModelProviders
+// ---------------------------------------------------------------------------
+
+// CreateModelProvider creates a new model provider in the given project.
+func (c *Client) CreateModelProvider(mp *v1alpha1.ModelProvider) (*v1alpha1.ModelProvider, error) {
+	var out v1alpha1.ModelProvider
+	path := fmt.Sprintf("/api/v1alpha1/modelproviders?project=%s", mp.Metadata.Project)
+	if err := c.doJSON(http.MethodPost, path, mp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetModelProvider retrieves a model provider by name within a project.
+func (c *Client) GetModelProvider(name, project string) (*v1alpha1.ModelProvider, error) {
+	var out v1alpha1.ModelProvider
+	path := fmt.Sprintf("/api/v1alpha1/modelproviders/%s?project=%s", name, project)
+	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListModelProviders returns all model providers in a project.
+func (c *Client) ListModelProviders(project string, opts ...ListOption) ([]v1alpha1.ModelProvider, error) {
+	var out []v1alpha1.ModelProvider
+	if err := c.doJSON(http.MethodGet, listPath("modelproviders", project, opts), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateModelProvider updates an existing model provider.
+func (c *Client) UpdateModelProvider(mp *v1alpha1.ModelProvider) (*v1alpha1.ModelProvider, error) {
+	var out v1alpha1.ModelProvider
+	path := fmt.Sprintf("/api/v1alpha1/modelproviders/%s?project=%s", mp.Metadata.Name, mp.Metadata.Project)
+	if err := c.doJSON(http.MethodPut, path, mp, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteModelProvider removes a model provider by name within a project.
+func (c *Client) DeleteModelProvider(name, project string) error {
+	path := fmt.Sprintf("/api/v1alpha1/modelproviders/%s?project=%s", name, project)
+	return c.doJSON(http.MethodDelete, path, nil, nil)
+}
+
+// ---------------------------------------------------------------------------
+// ToolDefinitions
+// ---------------------------------------------------------------------------
+
+// CreateToolDefinition creates a new tool definition in the given project.
+func (c *Client) CreateToolDefinition(td *v1alpha1.ToolDefinition) (*v1alpha1.ToolDefinition, error) {
+	var out v1alpha1.ToolDefinition
+	path := fmt.Sprintf("/api/v1alpha1/tooldefinitions?project=%s", td.Metadata.Project)
+	if err := c.doJSON(http.MethodPost, path, td, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetToolDefinition retrieves a tool definition by name within a project.
+func (c *Client) GetToolDefinition(name, project string) (*v1alpha1.ToolDefinition, error) {
+	var out v1alpha1.ToolDefinition
+	path := fmt.Sprintf("/api/v1alpha1/tooldefinitions/%s?project=%s", name, project)
+	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListToolDefinitions returns all tool definitions in a project.
+func (c *Client) ListToolDefinitions(project string, opts ...ListOption) ([]v1alpha1.ToolDefinition, error) {
+	var out []v1alpha1.ToolDefinition
+	if err := c.doJSON(http.MethodGet, listPath("tooldefinitions", project, opts), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateToolDefinition updates an existing tool definition.
+func (c *Client) UpdateToolDefinition(td *v1alpha1.ToolDefinition) (*v1alpha1.ToolDefinition, error) {
+	var out v1alpha1.ToolDefinition
+	path := fmt.Sprintf("/api/v1alpha1/tooldefinitions/%s?project=%s", td.Metadata.Name, td.Metadata.Project)
+	if err := c.doJSON(http.MethodPut, path, td, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteToolDefinition removes a tool definition by name within a project.
+func (c *Client) DeleteToolDefinition(name, project string) error {
+	path := fmt.Sprintf("/api/v1alpha1/tooldefinitions/%s?project=%s", name, project)
+	return c.doJSON(http.MethodDelete, path, nil, nil)
+}
+
+// ---------------------------------------------------------------------------
+// AgentNodes
+// ---------------------------------------------------------------------------
+
+// CreateAgentNode registers a new agent node.
+func (c *Client) CreateAgentNode(n *v1alpha1.AgentNode) (*v1alpha1.AgentNode, error) {
+	var out v1alpha1.AgentNode
+	if err := c.doJSON(http.MethodPost, "/api/v1alpha1/agentnodes", n, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAgentNode retrieves an agent node by name.
+func (c *Client) GetAgentNode(name string) (*v1alpha1.AgentNode, error) {
+	var out v1alpha1.AgentNode
+	if err := c.doJSON(http.MethodGet, fmt.Sprintf("/api/v1alpha1/agentnodes/%s", name), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAgentNodes returns all agent nodes.
+func (c *Client) ListAgentNodes(opts ...ListOption) ([]v1alpha1.AgentNode, error) {
+	var out []v1alpha1.AgentNode
+	if err := c.doJSON(http.MethodGet, listPath("agentnodes", "", opts), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateAgentNode updates the spec of an existing agent node.
+func (c *Client) UpdateAgentNode(n *v1alpha1.AgentNode) (*v1alpha1.AgentNode, error) {
+	var out v1alpha1.AgentNode
+	path := fmt.Sprintf("/api/v1alpha1/agentnodes/%s", n.Metadata.Name)
+	if err := c.doJSON(http.MethodPut, path, n, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAgentNode removes an agent node by name.
+func (c *Client) DeleteAgentNode(name string) error {
+	return c.doJSON(http.MethodDelete, fmt.Sprintf("/api/v1alpha1/agentnodes/%s", name), nil, nil)
+}
+
 // ---------------------------------------------------------------------------
 // DevTasks
 // ---------------------------------------------------------------------------
@@ -286,10 +795,9 @@ func (c *Client) GetDevTask(name, project string) (*v1alpha1.DevTask, error) {
 }
 
 // ListDevTasks returns all development tasks in a project.
-func (c *Client) ListDevTasks(project string) ([]v1alpha1.DevTask, error) {
+func (c *Client) ListDevTasks(project string, opts ...ListOption) ([]v1alpha1.DevTask, error) {
 	var out []v1alpha1.DevTask
-	path := fmt.Sprintf("/api/v1alpha1/devtasks?project=%s", project)
-	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+	if err := c.doJSON(http.MethodGet, listPath("devtasks", project, opts), nil, &out); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -311,16 +819,282 @@ func (c *Client) DeleteDevTask(name, project string) error {
 	return c.doJSON(http.MethodDelete, path, nil, nil)
 }
 
+// RetryDevTask resets a Failed task to Pending so it runs again.
+func (c *Client) RetryDevTask(name, project string) (*v1alpha1.DevTask, error) {
+	var out v1alpha1.DevTask
+	path := fmt.Sprintf("/api/v1alpha1/devtasks/%s/retry?project=%s", name, project)
+	if err := c.doJSON(http.MethodPost, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelDevTask cancels a task that has not finished; it then fails. The
+// execution of a Running task is stopped asynchronously, so the returned
+// task may still be Running.
+func (c *Client) CancelDevTask(name, project string) (*v1alpha1.DevTask, error) {
+	var out v1alpha1.DevTask
+	path := fmt.Sprintf("/api/v1alpha1/devtasks/%s/cancel?project=%s", name, project)
+	if err := c.doJSON(http.MethodPost, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteDevTasks deletes the tasks of a project matching opts (see
+// WithLabelSelector, WithPhases and WithOlderThan) and returns their names.
+func (c *Client) DeleteDevTasks(project string, opts ...ListOption) ([]string, error) {
+	var out struct {
+		Deleted []string `json:"deleted"`
+	}
+	if err := c.doJSON(http.MethodDelete, listPath("devtasks", project, opts), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Deleted, nil
+}
+
+// FollowTask streams a task's steps to fn while it runs, ending with an event
+// carrying the phase the attempt finished in. For a finished task the
+// recorded steps and phase are delivered. It returns when ctx is cancelled
+// (with a nil error) or the stream ends.
+func (c *Client) FollowTask(ctx context.Context, name, project string, fn func(v1alpha1.TaskStreamEvent)) error {
+	path := fmt.Sprintf("/api/v1alpha1/devtasks/%s/stream?project=%s", name, project)
+	return c.stream(ctx, path, func(data []byte) error {
+		var ev v1alpha1.TaskStreamEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return fmt.Errorf("decode task event: %w", err)
+		}
+		fn(ev)
+		return nil
+	})
+}
+
+// ListArtifacts returns the files a task saved as artifacts.
+func (c *Client) ListArtifacts(taskName, project string) ([]v1alpha1.Artifact, error) {
+	var out []v1alpha1.Artifact
+	path := fmt.Sprintf("/api/v1alpha1/devtasks/%s/artifacts?project=%s", taskName, project)
+	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DownloadArtifact writes the content of the task's artifact name, as
+// reported by ListArtifacts, to w.
+func (c *Client) DownloadArtifact(taskName, project, name string, w io.Writer) error {
+	segments := strings.Split(name, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	path := fmt.Sprintf("/api/v1alpha1/devtasks/%s/artifacts/%s?project=%s", taskName, strings.Join(segments, "/"), project)
+
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	// Large artifacts may take longer than the regular request timeout.
+	resp, err := c.send(&http.Client{Transport: c.httpClient.Transport}, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return newAPIError(resp.StatusCode, body)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("read artifact: %w", err)
+	}
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // Apply (generic create-or-update)
 // ---------------------------------------------------------------------------
 
-// Apply sends a resource to the server's apply endpoint, which performs a
-// create-or-update operation. The returned interface{} contains the
-// server's response decoded as a raw JSON map.
-func (c *Client) Apply(resource interface{}) (interface{}, error) {
-	var out interface{}
-	if err := c.doJSON(http.MethodPost, "/api/v1alpha1/apply", resource, &out); err != nil {
+// ApplyInto sends resource, any Orca resource, to the server's apply
+// endpoint, which creates it or, if it exists, updates it. The object as
+// stored is decoded into out (when non-nil), and created reports whether it
+// was new. With dryRun nothing is persisted and out shows the object as it
+// would be stored. Apply and ApplyDryRun are the typed forms.
+func (c *Client) ApplyInto(resource, out interface{}, dryRun bool) (created bool, err error) {
+	path := "/api/v1alpha1/apply"
+	if dryRun {
+		path += "?dryRun=true"
+	}
+	resp, err := c.doJSONResponse(http.MethodPost, path, resource, out)
+	if err != nil {
+		return false, err
+	}
+	return resp.StatusCode == http.StatusCreated, nil
+}
+
+// Apply creates obj or, if it exists, updates it, and returns the object as
+// the server stored it and whether it was created. T is one of the resource
+// types, e.g. v1alpha1.AgentPool.
+func Apply[T any](c Interface, obj *T) (*T, bool, error) {
+	out := new(T)
+	created, err := c.ApplyInto(obj, out, false)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, created, nil
+}
+
+// ApplyDryRun is Apply without persisting anything: it returns the object
+// as Apply would store it and whether Apply would create it.
+func ApplyDryRun[T any](c Interface, obj *T) (*T, bool, error) {
+	out := new(T)
+	created, err := c.ApplyInto(obj, out, true)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, created, nil
+}
+
+// Get retrieves the named resource into out. kind is a kind such as
+// "AgentPool" or a path segment such as "agentpools"; project is ignored
+// for projects.
+func (c *Client) Get(kind, name, project string, out interface{}) error {
+	return c.doJSON(http.MethodGet, objectPath(kind, name, project, ""), nil, out)
+}
+
+// DeleteCollection deletes the objects of kind in project matching opts
+// (see WithLabelSelector and WithDryRun) and returns their names, and the
+// names of those of them left for their finalizers to remove. kind is a kind
+// such as "AgentPod" or a path segment such as "agentpods"; project is
+// ignored for agent nodes. DevTasks take the further options of
+// DeleteDevTasks.
+func (c *Client) DeleteCollection(kind, project string, opts ...ListOption) (deleted, pending []string, err error) {
+	resource := resourcePath(kind)
+	if resource == "agentnodes" {
+		project = ""
+	}
+	var out struct {
+		Deleted []string `json:"deleted"`
+		Pending []string `json:"pending"`
+	}
+	if err := c.doJSON(http.MethodDelete, listPath(resource, project, opts), nil, &out); err != nil {
+		return nil, nil, err
+	}
+	return out.Deleted, out.Pending, nil
+}
+
+// ---------------------------------------------------------------------------
+// Patch
+// ---------------------------------------------------------------------------
+
+// PatchType selects how the server interprets a patch document.
+type PatchType string
+
+const (
+	// MergePatchType is an RFC 7386 JSON merge patch.
+	MergePatchType PatchType = "application/merge-patch+json"
+	// JSONPatchType is an RFC 6902 JSON patch (a list of operations).
+	JSONPatchType PatchType = "application/json-patch+json"
+)
+
+// Patch applies patch to the named resource and decodes the patched object
+// into out (when non-nil). kind is a kind such as "AgentPool" or a path
+// segment such as "agentpools"; project is ignored for projects.
+func (c *Client) Patch(kind, name, project string, pt PatchType, patch []byte, out interface{}) error {
+	path := objectPath(kind, name, project, "")
+
+	req, err := http.NewRequest(http.MethodPatch, c.baseURL+path, bytes.NewReader(patch))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", string(pt))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp.StatusCode, respBody)
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("decode response body: %w", err)
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Events
+// ---------------------------------------------------------------------------
+
+// ListEvents returns events oldest first. An empty project lists all
+// projects; kind and name, when set, select events about one object.
+func (c *Client) ListEvents(project, kind, name string) ([]v1alpha1.Event, error) {
+	q := url.Values{}
+	if project != "" {
+		q.Set("project", project)
+	}
+	if kind != "" {
+		q.Set("kind", kind)
+	}
+	if name != "" {
+		q.Set("name", name)
+	}
+	var out []v1alpha1.Event
+	path := "/api/v1alpha1/events"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ---------------------------------------------------------------------------
+// Audit
+// ---------------------------------------------------------------------------
+
+// AuditOptions selects the entries returned by ListAuditEntries. Empty
+// fields select every entry.
+type AuditOptions struct {
+	User    string
+	Kind    string
+	Project string
+	Name    string
+	// Since returns only entries newer than this duration when positive.
+	Since time.Duration
+	// Limit returns only the newest Limit entries when positive.
+	Limit int
+}
+
+// ListAuditEntries returns the server's audit entries oldest first. It
+// fails with 404 Not Found when the server does not audit requests.
+func (c *Client) ListAuditEntries(opts AuditOptions) ([]v1alpha1.AuditEntry, error) {
+	q := url.Values{}
+	for param, v := range map[string]string{"user": opts.User, "kind": opts.Kind, "project": opts.Project, "name": opts.Name} {
+		if v != "" {
+			q.Set(param, v)
+		}
+	}
+	if opts.Since > 0 {
+		q.Set("since", opts.Since.String())
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var out []v1alpha1.AuditEntry
+	path := "/api/v1alpha1/audit"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -330,12 +1104,285 @@ func (c *Client) Apply(resource interface{}) (interface{}, error) {
 // Logs
 // ---------------------------------------------------------------------------
 
+// LogOptions limits the entries returned by GetLogs.
+type LogOptions struct {
+	// Tail returns only the last Tail entries when positive.
+	Tail int
+	// Since returns only entries newer than this duration when positive.
+	Since time.Duration
+}
+
+// logsQuery encodes project and opts as logs endpoint query parameters.
+func logsQuery(project string, opts LogOptions) url.Values {
+	q := url.Values{"project": {project}}
+	if opts.Tail > 0 {
+		q.Set("tail", strconv.Itoa(opts.Tail))
+	}
+	if opts.Since > 0 {
+		q.Set("since", opts.Since.String())
+	}
+	return q
+}
+
 // GetLogs retrieves log entries for an agent pod.
-func (c *Client) GetLogs(podName, project string) ([]v1alpha1.LogEntry, error) {
+func (c *Client) GetLogs(podName, project string, opts LogOptions) ([]v1alpha1.LogEntry, error) {
 	var out []v1alpha1.LogEntry
-	path := fmt.Sprintf("/api/v1alpha1/agentpods/%s/logs?project=%s", podName, project)
+	q := logsQuery(project, opts)
+	path := fmt.Sprintf("/api/v1alpha1/agentpods/%s/logs?%s", podName, q.Encode())
 	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
 		return nil, err
 	}
 	return out, nil
 }
+
+// FollowLogs streams an agent pod's log entries to fn: first the entries
+// selected by opts, then new entries as the server records them. It returns
+// when ctx is cancelled (with a nil error) or the stream ends.
+func (c *Client) FollowLogs(ctx context.Context, podName, project string, opts LogOptions, fn func(v1alpha1.LogEntry)) error {
+	q := logsQuery(project, opts)
+	q.Set("follow", "true")
+	path := fmt.Sprintf("/api/v1alpha1/agentpods/%s/logs?%s", podName, q.Encode())
+
+	return c.stream(ctx, path, func(data []byte) error {
+		var entry v1alpha1.LogEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return fmt.Errorf("decode log entry: %w", err)
+		}
+		fn(entry)
+		return nil
+	})
+}
+
+// StreamLogEntries follows an agent pod's log like FollowLogs, delivering
+// the entries on the returned channel instead. It returns once the server
+// has accepted the request, so a missing pod is reported right away. The
+// channel is closed when ctx is cancelled or the stream ends.
+func (c *Client) StreamLogEntries(ctx context.Context, podName, project string, opts LogOptions) (<-chan v1alpha1.LogEntry, error) {
+	q := logsQuery(project, opts)
+	q.Set("follow", "true")
+	resp, err := c.openStream(ctx, fmt.Sprintf("/api/v1alpha1/agentpods/%s/logs?%s", podName, q.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(chan v1alpha1.LogEntry)
+	go func() {
+		defer close(entries)
+		defer resp.Body.Close()
+		_ = readEvents(resp.Body, func(data []byte) error {
+			var entry v1alpha1.LogEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return fmt.Errorf("decode log entry: %w", err)
+			}
+			select {
+			case entries <- entry:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return entries, nil
+}
+
+// StreamLogs follows an agent pod's log as plain text, one line per entry:
+// its RFC 3339 timestamp, level and message. Reading blocks until the next
+// entry; closing the reader ends the stream.
+func (c *Client) StreamLogs(podName, project string, opts LogOptions) (io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	entries, err := c.StreamLogEntries(ctx, podName, project, opts)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		for entry := range entries {
+			line := fmt.Sprintf("%s %-5s %s\n", entry.Timestamp.Format(time.RFC3339), entry.Level, entry.Message)
+			if _, err := io.WriteString(pw, line); err != nil {
+				cancel()
+			}
+		}
+		pw.Close()
+	}()
+	return &logReader{PipeReader: pr, cancel: cancel}, nil
+}
+
+// logReader is the reader of StreamLogs; closing it stops the stream.
+type logReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (r *logReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+// Watch streams changes to the resources of kind (all kinds when empty) in
+// project (all projects when empty) to fn. Each event's Object holds the
+// resource as a json.RawMessage. Watch returns when ctx is cancelled (with a
+// nil error) or the stream ends.
+func (c *Client) Watch(ctx context.Context, kind, project string, fn func(v1alpha1.WatchEvent)) error {
+	q := url.Values{}
+	if kind != "" {
+		q.Set("kind", kind)
+	}
+	if project != "" {
+		q.Set("project", project)
+	}
+	path := "/api/v1alpha1/watch"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	return c.stream(ctx, path, func(data []byte) error {
+		var ev struct {
+			Type   v1alpha1.EventType `json:"type"`
+			Kind   string             `json:"kind"`
+			Key    string             `json:"key"`
+			Object json.RawMessage    `json:"object"`
+		}
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return fmt.Errorf("decode watch event: %w", err)
+		}
+		fn(v1alpha1.WatchEvent{Type: ev.Type, Kind: ev.Kind, Key: ev.Key, Object: ev.Object})
+		return nil
+	})
+}
+
+// stream GETs a server-sent event stream and passes each event's data to fn.
+// It returns when ctx is cancelled (with a nil error), the stream ends or fn
+// fails.
+func (c *Client) stream(ctx context.Context, path string, fn func(data []byte) error) error {
+	resp, err := c.openStream(ctx, path)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = readEvents(resp.Body, fn)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// openStream GETs a server-sent event stream and returns the response once
+// the server has accepted the request. The caller must close its body.
+func (c *Client) openStream(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// The stream outlives the regular request timeout.
+	resp, err := c.send(&http.Client{Transport: c.httpClient.Transport}, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+	return resp, nil
+}
+
+// readEvents calls fn with the data of each server-sent event read from r,
+// until r is exhausted or fn fails. Comments and other fields are ignored.
+func readEvents(r io.Reader, fn func(data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		switch {
+		case len(line) == 0:
+			if data != nil {
+				if err := fn(data); err != nil {
+					return err
+				}
+				data = nil
+			}
+		case bytes.HasPrefix(line, []byte("data:")):
+			chunk := bytes.TrimPrefix(bytes.TrimPrefix(line, []byte("data:")), []byte(" "))
+			if data != nil {
+				data = append(data, '\n')
+			}
+			data = append(data, chunk...)
+		}
+	}
+	return scanner.Err()
+}
+
+// ---------------------------------------------------------------------------
+// Admin
+// ---------------------------------------------------------------------------
+
+// Backup writes a snapshot of the server's whole store to w as a gzipped
+// tarball. With resourcesOnly it writes a YAML bundle of the declared
+// resources instead, which can be applied to any server.
+func (c *Client) Backup(w io.Writer, resourcesOnly bool) error {
+	path := "/api/v1alpha1/admin/backup"
+	if resourcesOnly {
+		path += "?resourcesOnly=true"
+	}
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	// A large store may take longer than the regular request timeout.
+	resp, err := c.send(&http.Client{Transport: c.httpClient.Transport}, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return newAPIError(resp.StatusCode, body)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("read backup: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the server's whole store with the contents of a tarball
+// written by Backup and returns the number of stored objects.
+func (c *Client) Restore(r io.Reader) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/api/v1alpha1/admin/restore", r)
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.send(&http.Client{Transport: c.httpClient.Transport}, req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, newAPIError(resp.StatusCode, body)
+	}
+	var out struct {
+		Restored int `json:"restored"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return 0, fmt.Errorf("decode response body: %w", err)
+	}
+	return out.Restored, nil
+}
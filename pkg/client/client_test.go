@@ -0,0 +1,127 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestApply(t *testing.T) {
+	stored := map[string]bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var pool v1alpha1.AgentPool
+		_ = json.NewDecoder(r.Body).Decode(&pool)
+		pool.Metadata.UID = "uid-" + pool.Metadata.Name
+		status := http.StatusOK
+		if !stored[pool.Metadata.Name] {
+			status = http.StatusCreated
+		}
+		if r.URL.Query().Get("dryRun") != "true" {
+			stored[pool.Metadata.Name] = true
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(pool)
+	}))
+	defer srv.Close()
+	c := New(srv.URL)
+
+	pool := &v1alpha1.AgentPool{}
+	pool.Kind = v1alpha1.KindAgentPool
+	pool.Metadata.Name = "workers"
+
+	for _, tt := range []struct {
+		name        string
+		apply       func(Interface, *v1alpha1.AgentPool) (*v1alpha1.AgentPool, bool, error)
+		wantCreated bool
+	}{
+		{"dry run", ApplyDryRun[v1alpha1.AgentPool], true},
+		{"create", Apply[v1alpha1.AgentPool], true},
+		{"update", Apply[v1alpha1.AgentPool], false},
+	} {
+		got, created, err := tt.apply(c, pool)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if created != tt.wantCreated || got.Metadata.UID != "uid-workers" {
+			t.Errorf("%s: got %+v, created %v; want the stored pool, created %v", tt.name, got.Metadata, created, tt.wantCreated)
+		}
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	closed := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1alpha1/agentpods/worker-1/logs" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"agentpod not found"}`)
+			return
+		}
+		if q := r.URL.Query(); q.Get("follow") != "true" || q.Get("tail") != "5" {
+			t.Errorf("query = %s, want follow=true and tail=5", q.Encode())
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		for i, msg := range []string{"starting", "ready"} {
+			data, _ := json.Marshal(v1alpha1.LogEntry{Timestamp: at.Add(time.Duration(i) * time.Second), Level: "INFO", Message: msg})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		close(closed)
+	}))
+	defer srv.Close()
+	c := New(srv.URL)
+
+	if _, err := c.StreamLogs("nope", "default", LogOptions{}); !IsNotFound(err) {
+		t.Errorf("streaming a missing pod returned %v, want not found", err)
+	}
+
+	rc, err := c.StreamLogs("worker-1", "default", LogOptions{Tail: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bufio.NewScanner(rc)
+	for _, want := range []string{
+		"2026-01-02T03:04:05Z INFO  starting",
+		"2026-01-02T03:04:06Z INFO  ready",
+	} {
+		if !lines.Scan() {
+			t.Fatalf("stream ended before %q: %v", want, lines.Err())
+		}
+		if got := lines.Text(); got != want {
+			t.Errorf("line = %q, want %q", got, want)
+		}
+	}
+
+	// Closing the reader ends the request.
+	rc.Close()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("closing the reader did not end the stream")
+	}
+}
+
+func TestObjectPath(t *testing.T) {
+	tests := []struct {
+		kind, name, project, sub string
+		want                     string
+	}{
+		{"AgentPool", "workers", "default", "scale", "/api/v1alpha1/agentpools/workers/scale?project=default"},
+		{"devtasks", "fix", "web app", "", "/api/v1alpha1/devtasks/fix?project=web+app"},
+		{"AgentPoolAutoscaler", "busy", "default", "status", "/api/v1alpha1/autoscalers/busy/status?project=default"},
+		{"Project", "default", "ignored", "status", "/api/v1alpha1/projects/default/status"},
+		{"Secret", "token", "default", "", "/api/v1alpha1/secrets/token?project=default"},
+	}
+	for _, tt := range tests {
+		if got := objectPath(tt.kind, tt.name, tt.project, tt.sub); got != tt.want {
+			t.Errorf("objectPath(%q, %q, %q, %q) = %q, want %q", tt.kind, tt.name, tt.project, tt.sub, got, tt.want)
+		}
+	}
+}
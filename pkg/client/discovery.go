@@ -0,0 +1,161 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Verbs a server can support on a resource, as reported by Discover.
+const (
+	VerbList             = "list"
+	VerbCreate           = "create"
+	VerbDeleteCollection = "deletecollection"
+	VerbGet              = "get"
+	VerbUpdate           = "update"
+	VerbPatch            = "patch"
+	VerbDelete           = "delete"
+)
+
+// collectionVerbs and objectVerbs map the methods of the collection and
+// object paths of a resource to verbs.
+var (
+	collectionVerbs = map[string]string{"get": VerbList, "post": VerbCreate, "delete": VerbDeleteCollection}
+	objectVerbs     = map[string]string{"get": VerbGet, "put": VerbUpdate, "patch": VerbPatch, "delete": VerbDelete}
+)
+
+// ServerVersion is the build of an Orca server and the API version it
+// serves.
+type ServerVersion struct {
+	Version    string `json:"version"`
+	APIVersion string `json:"apiVersion"`
+	GoVersion  string `json:"goVersion"`
+}
+
+// APIResource is a resource served by the API.
+type APIResource struct {
+	// Name is the resource's path segment, e.g. "devtasks".
+	Name string
+	Kind string
+	// ProjectScoped is whether objects of the resource live in a project.
+	ProjectScoped bool
+	// Verbs and Subresources are sorted.
+	Verbs        []string
+	Subresources []string
+}
+
+// Discovery is what a server reports it supports.
+type Discovery struct {
+	// Version is the server's build, from its OpenAPI document.
+	Version   string
+	Resources []APIResource
+}
+
+// Resource returns the resource with the given kind or path segment,
+// matched case-insensitively.
+func (d *Discovery) Resource(kind string) (APIResource, bool) {
+	for _, r := range d.Resources {
+		if strings.EqualFold(r.Kind, kind) || strings.EqualFold(r.Name, kind) {
+			return r, true
+		}
+	}
+	return APIResource{}, false
+}
+
+// Supports reports whether the server supports verb, e.g. VerbPatch, on the
+// resource with the given kind or path segment.
+func (d *Discovery) Supports(kind, verb string) bool {
+	r, ok := d.Resource(kind)
+	return ok && slices.Contains(r.Verbs, verb)
+}
+
+// HasSubresource reports whether the server serves subresource, e.g.
+// "status" or "scale", for the resource with the given kind or path segment.
+func (d *Discovery) HasSubresource(kind, subresource string) bool {
+	r, ok := d.Resource(kind)
+	return ok && slices.Contains(r.Subresources, subresource)
+}
+
+// ServerVersion fetches the server's build and API version.
+func (c *Client) ServerVersion() (*ServerVersion, error) {
+	var out ServerVersion
+	if err := c.doJSON(http.MethodGet, "/version", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// openAPIDoc is the part of the server's OpenAPI document Discover reads.
+type openAPIDoc struct {
+	Info struct {
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+// Discover fetches the server's OpenAPI document and reports the resources
+// it serves with their verbs and subresources. Tooling can use it to check
+// for a feature before relying on it; servers that predate it answer with a
+// 404 APIError.
+func (c *Client) Discover() (*Discovery, error) {
+	var doc openAPIDoc
+	if err := c.doJSON(http.MethodGet, "/openapi", nil, &doc); err != nil {
+		return nil, err
+	}
+	return discover(&doc), nil
+}
+
+func discover(doc *openAPIDoc) *Discovery {
+	byName := map[string]*APIResource{}
+	for path, item := range doc.Paths {
+		var kind, scope string
+		if raw, ok := item["x-orca-kind"]; !ok || json.Unmarshal(raw, &kind) != nil {
+			continue
+		}
+		if raw, ok := item["x-orca-scope"]; ok {
+			_ = json.Unmarshal(raw, &scope)
+		}
+		rest, ok := strings.CutPrefix(path, "/api/v1alpha1/")
+		if !ok {
+			continue
+		}
+		segments := strings.Split(rest, "/")
+
+		r := byName[segments[0]]
+		if r == nil {
+			r = &APIResource{Name: segments[0], Kind: kind, ProjectScoped: scope != "cluster"}
+			byName[segments[0]] = r
+		}
+
+		switch len(segments) {
+		case 1:
+			r.Verbs = appendVerbs(r.Verbs, item, collectionVerbs)
+		case 2:
+			r.Verbs = appendVerbs(r.Verbs, item, objectVerbs)
+		default:
+			if !slices.Contains(r.Subresources, segments[2]) {
+				r.Subresources = append(r.Subresources, segments[2])
+			}
+		}
+	}
+
+	d := &Discovery{Version: doc.Info.Version}
+	for _, r := range byName {
+		sort.Strings(r.Verbs)
+		sort.Strings(r.Subresources)
+		d.Resources = append(d.Resources, *r)
+	}
+	sort.Slice(d.Resources, func(i, j int) bool { return d.Resources[i].Name < d.Resources[j].Name })
+	return d
+}
+
+func appendVerbs(verbs []string, item map[string]json.RawMessage, methods map[string]string) []string {
+	for method, verb := range methods {
+		if _, ok := item[method]; ok {
+			verbs = append(verbs, verb)
+		}
+	}
+	return verbs
+}
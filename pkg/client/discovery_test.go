@@ -0,0 +1,66 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+const testOpenAPI = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Orca API", "version": "v1.2.0"},
+  "paths": {
+    "/healthz": {"get": {}},
+    "/api/v1alpha1/watch": {"get": {}},
+    "/api/v1alpha1/projects": {"get": {}, "post": {}, "x-orca-kind": "Project", "x-orca-scope": "cluster"},
+    "/api/v1alpha1/devtasks": {"get": {}, "post": {}, "delete": {}, "x-orca-kind": "DevTask", "x-orca-scope": "project"},
+    "/api/v1alpha1/devtasks/{name}": {"get": {}, "put": {}, "delete": {}, "x-orca-kind": "DevTask", "x-orca-scope": "project"},
+    "/api/v1alpha1/devtasks/{name}/retry": {"post": {}, "x-orca-kind": "DevTask", "x-orca-scope": "project"},
+    "/api/v1alpha1/devtasks/{name}/artifacts/{artifact}": {"get": {}, "x-orca-kind": "DevTask", "x-orca-scope": "project"}
+  }
+}`
+
+func TestDiscover(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openapi" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(testOpenAPI))
+	}))
+	defer srv.Close()
+
+	d, err := New(srv.URL).Discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Version != "v1.2.0" || len(d.Resources) != 2 {
+		t.Fatalf("got version %q and %d resources, want v1.2.0 and 2", d.Version, len(d.Resources))
+	}
+
+	tasks, ok := d.Resource("devtask")
+	if !ok {
+		t.Fatal("no devtasks resource")
+	}
+	wantVerbs := []string{"create", "delete", "deletecollection", "get", "list", "update"}
+	if !slices.Equal(tasks.Verbs, wantVerbs) || !tasks.ProjectScoped {
+		t.Errorf("devtasks = %+v, want verbs %v, project scoped", tasks, wantVerbs)
+	}
+	if !slices.Equal(tasks.Subresources, []string{"artifacts", "retry"}) {
+		t.Errorf("subresources = %v", tasks.Subresources)
+	}
+	if d.Supports("devtasks", VerbPatch) {
+		t.Error("patch reported as supported")
+	}
+	if !d.HasSubresource("DevTask", "retry") || d.HasSubresource("Project", "retry") {
+		t.Error("HasSubresource disagrees with the document")
+	}
+	if p, _ := d.Resource("Project"); p.ProjectScoped {
+		t.Error("projects reported as project scoped")
+	}
+
+	if _, err := New(srv.URL).ServerVersion(); !IsNotFound(err) {
+		t.Errorf("ServerVersion against an old server: err = %v, want not found", err)
+	}
+}
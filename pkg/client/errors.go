@@ -0,0 +1,65 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when the API server answers with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	// Message is the error the server reported, or the response body when
+	// it did not answer with its {"error": "..."} envelope.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// newAPIError builds the APIError for a response with status and body.
+func newAPIError(status int, body []byte) *APIError {
+	var envelope struct {
+		Error string `json:"error"`
+	}
+	msg := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != "" {
+		msg = envelope.Error
+	}
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	return &APIError{StatusCode: status, Message: msg}
+}
+
+// IsNotFound reports whether err is an APIError for a missing resource.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an APIError for a resource that already
+// exists or is not in a state the request can apply to.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnauthorized reports whether err is an APIError for a request without
+// valid credentials.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsInvalid reports whether err is an APIError for a resource that fails
+// validation.
+func IsInvalid(err error) bool {
+	return hasStatus(err, http.StatusUnprocessableEntity)
+}
+
+// hasStatus reports whether err is an APIError with status.
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
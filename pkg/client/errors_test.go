@@ -0,0 +1,52 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"envelope", http.StatusNotFound, `{"error":"devtask not found"}` + "\n", "devtask not found"},
+		{"plain body", http.StatusBadGateway, "upstream down\n", "upstream down"},
+		{"empty body", http.StatusUnauthorized, "", "Unauthorized"},
+		{"other json", http.StatusBadRequest, `{"message":"x"}`, `{"message":"x"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newAPIError(tt.status, []byte(tt.body))
+			if err.StatusCode != tt.status || err.Message != tt.want {
+				t.Errorf("got {%d, %q}, want {%d, %q}", err.StatusCode, err.Message, tt.status, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorHelpers(t *testing.T) {
+	notFound := fmt.Errorf("getting task: %w", newAPIError(http.StatusNotFound, nil))
+	conflict := newAPIError(http.StatusConflict, nil)
+	unauthorized := newAPIError(http.StatusUnauthorized, nil)
+	invalid := newAPIError(http.StatusUnprocessableEntity, nil)
+
+	if !IsNotFound(notFound) || IsConflict(notFound) || IsUnauthorized(notFound) {
+		t.Errorf("wrapped 404 misclassified")
+	}
+	if !IsConflict(conflict) || IsNotFound(conflict) {
+		t.Errorf("409 misclassified")
+	}
+	if !IsUnauthorized(unauthorized) || IsConflict(unauthorized) {
+		t.Errorf("401 misclassified")
+	}
+	if !IsInvalid(invalid) || IsConflict(invalid) {
+		t.Errorf("422 misclassified")
+	}
+	if IsNotFound(fmt.Errorf("status 404")) {
+		t.Errorf("untyped error reported as not found")
+	}
+}
@@ -0,0 +1,1607 @@
+// Package fake provides an in-memory client.Interface for tests, so code
+// built on the client can run without a live server.
+//
+// The fake keeps its objects in a MemoryStore and mirrors what the API
+// server does with a request: it defaults new objects, enforces the retry,
+// cancel and scale rules, and applies list options. No controllers run, so
+// nothing reconciles: a task stays Pending, and a pool has no pods, until
+// the test changes them.
+package fake
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/klubi/orca/internal/policy"
+	"github.com/klubi/orca/internal/store"
+	"github.com/klubi/orca/internal/version"
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+	"github.com/klubi/orca/pkg/labels"
+	"github.com/klubi/orca/pkg/manifest"
+	"github.com/klubi/orca/pkg/validation"
+)
+
+// Client is an in-memory client.Interface. The zero value is not usable;
+// create one with NewClient.
+type Client struct {
+	store *store.MemoryStore
+}
+
+var _ client.Interface = (*Client)(nil)
+
+// NewClient returns a fake client holding objects, stored as given, without
+// the defaults Create would fill in. It panics if an object is not an Orca
+// resource, as tests build their objects statically.
+func NewClient(objects ...interface{}) *Client {
+	c := &Client{store: store.NewMemoryStore()}
+	for _, obj := range objects {
+		if err := c.Add(obj); err != nil {
+			panic(err)
+		}
+	}
+	return c
+}
+
+// Add stores obj, a resource such as *v1alpha1.DevTask or v1alpha1.Event, as
+// is, replacing any object of the same kind and name.
+func (c *Client) Add(obj interface{}) error {
+	kind, meta, err := identify(obj)
+	if err != nil {
+		return err
+	}
+	key := store.ResourceKey(kind, meta.Project, meta.Name)
+	if v1alpha1.ClusterScoped(kind) {
+		key = store.ResourceKey(kind, "", meta.Name)
+	}
+	err = c.store.Update(key, obj)
+	if err == store.ErrNotFound {
+		err = c.store.Create(key, obj)
+	}
+	return err
+}
+
+// identify returns the kind and metadata of obj from its JSON form.
+func identify(obj interface{}) (string, v1alpha1.ObjectMeta, error) {
+	var head struct {
+		Kind     string              `json:"kind"`
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+	}
+	raw, err := json.Marshal(obj)
+	if err == nil {
+		err = json.Unmarshal(raw, &head)
+	}
+	if err != nil {
+		return "", head.Metadata, fmt.Errorf("fake: %T is not a resource: %w", obj, err)
+	}
+	if head.Kind == "" {
+		head.Kind = kindOf(obj)
+	}
+	if head.Kind == "" || head.Metadata.Name == "" {
+		return "", head.Metadata, fmt.Errorf("fake: %T has no kind or name", obj)
+	}
+	return head.Kind, head.Metadata, nil
+}
+
+// kindOf returns the kind of the typed resources, which may be built
+// without their TypeMeta.
+func kindOf(obj interface{}) string {
+	switch obj.(type) {
+	case v1alpha1.Project, *v1alpha1.Project:
+		return v1alpha1.KindProject
+	case v1alpha1.AgentPod, *v1alpha1.AgentPod:
+		return v1alpha1.KindAgentPod
+	case v1alpha1.AgentPool, *v1alpha1.AgentPool:
+		return v1alpha1.KindAgentPool
+	case v1alpha1.AgentPoolAutoscaler, *v1alpha1.AgentPoolAutoscaler:
+		return v1alpha1.KindAgentPoolAutoscaler
+	case v1alpha1.DevTask, *v1alpha1.DevTask:
+		return v1alpha1.KindDevTask
+	case v1alpha1.Secret, *v1alpha1.Secret:
+		return v1alpha1.KindSecret
+	case v1alpha1.ConfigMap, *v1alpha1.ConfigMap:
+		return v1alpha1.KindConfigMap
+	case v1alpha1.ModelProvider, *v1alpha1.ModelProvider:
+		return v1alpha1.KindModelProvider
+	case v1alpha1.ToolDefinition, *v1alpha1.ToolDefinition:
+		return v1alpha1.KindToolDefinition
+	case v1alpha1.Event, *v1alpha1.Event:
+		return v1alpha1.KindEvent
+	case v1alpha1.AgentNode, *v1alpha1.AgentNode:
+		return v1alpha1.KindAgentNode
+	case v1alpha1.AuditEntry, *v1alpha1.AuditEntry:
+		return v1alpha1.KindAuditEntry
+	}
+	return ""
+}
+
+// resourceKinds maps the API path segments to the kinds they serve.
+var resourceKinds = map[string]string{
+	"projects":        v1alpha1.KindProject,
+	"agentpods":       v1alpha1.KindAgentPod,
+	"agentpools":      v1alpha1.KindAgentPool,
+	"autoscalers":     v1alpha1.KindAgentPoolAutoscaler,
+	"devtasks":        v1alpha1.KindDevTask,
+	"secrets":         v1alpha1.KindSecret,
+	"configmaps":      v1alpha1.KindConfigMap,
+	"modelproviders":  v1alpha1.KindModelProvider,
+	"tooldefinitions": v1alpha1.KindToolDefinition,
+	"agentnodes":      v1alpha1.KindAgentNode,
+}
+
+// apiError returns the error the client reports for a response with status
+// and msg.
+func apiError(status int, msg string) error {
+	return &client.APIError{StatusCode: status, Message: msg}
+}
+
+func notFound(kind string) error {
+	return apiError(http.StatusNotFound, strings.ToLower(kind)+" not found")
+}
+
+func unsupported(method string) error {
+	return apiError(http.StatusNotImplemented, "the fake client does not support "+method)
+}
+
+// ---------------------------------------------------------------------------
+// Generic CRUD
+// ---------------------------------------------------------------------------
+
+// get reads the object of kind named name in project into out.
+func (c *Client) get(kind, project, name string, out interface{}) error {
+	if !v1alpha1.ClusterScoped(kind) && project == "" {
+		return apiError(http.StatusBadRequest, "project query param is required")
+	}
+	if err := c.store.Get(store.ResourceKey(kind, project, name), out); err != nil {
+		if err == store.ErrNotFound {
+			return notFound(kind)
+		}
+		return err
+	}
+	return nil
+}
+
+// admit defaults obj, an object of kind, and checks it against the schema
+// of its kind and the policy of its project, as the server does.
+func (c *Client) admit(kind string, obj interface{}) error {
+	manifest.ApplyDefaults(obj)
+	if err := validation.Validate(obj); err != nil {
+		return apiError(http.StatusUnprocessableEntity, err.Error())
+	}
+	o, ok := obj.(v1alpha1.Object)
+	if !ok || v1alpha1.ClusterScoped(kind) {
+		return nil
+	}
+	p, err := policy.Project(c.store, o.GetObjectMeta().Project)
+	if err != nil {
+		return err
+	}
+	policy.ApplyDefaults(p, obj)
+	if err := policy.Check(p, obj); err != nil {
+		return apiError(http.StatusForbidden, err.Error())
+	}
+	return nil
+}
+
+// admitNewTask checks that the project of a task being created has budget
+// left, as the server does.
+func (c *Client) admitNewTask(project string) error {
+	p, err := policy.Project(c.store, project)
+	if err != nil {
+		return err
+	}
+	if err := policy.CheckBudget(c.store, p); err != nil {
+		var violation *policy.Violation
+		if errors.As(err, &violation) {
+			return apiError(http.StatusForbidden, err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// create stores a new object of kind, defaulted as the server does.
+func (c *Client) create(kind string, meta *v1alpha1.ObjectMeta, obj interface{}) error {
+	if err := c.admit(kind, obj); err != nil {
+		return err
+	}
+	meta.UID = uuid.New().String()
+	now := time.Now()
+	meta.CreatedAt = now
+	meta.UpdatedAt = now
+	if err := c.store.Create(store.ResourceKey(kind, meta.Project, meta.Name), obj); err != nil {
+		if err == store.ErrAlreadyExists {
+			return apiError(http.StatusConflict, strings.ToLower(kind)+" already exists")
+		}
+		return err
+	}
+	return nil
+}
+
+// update replaces an object of kind, keeping its identity and filling
+// defaults as the server does. Like the server, it refuses the update if
+// the object carries a resourceVersion other than the stored one.
+func (c *Client) update(kind string, meta *v1alpha1.ObjectMeta, obj interface{}) error {
+	if err := c.admit(kind, obj); err != nil {
+		return err
+	}
+	var existing struct {
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+	}
+	if err := c.get(kind, meta.Project, meta.Name, &existing); err != nil {
+		return err
+	}
+	meta.UID = existing.Metadata.UID
+	meta.CreatedAt = existing.Metadata.CreatedAt
+	meta.UpdatedAt = time.Now()
+	if meta.Finalizers == nil && (kind == v1alpha1.KindAgentPod || kind == v1alpha1.KindDevTask) {
+		meta.Finalizers = existing.Metadata.Finalizers
+	}
+	if meta.OwnerReferences == nil && kind == v1alpha1.KindAgentPod {
+		meta.OwnerReferences = existing.Metadata.OwnerReferences
+	}
+	err := c.store.UpdateIfVersion(store.ResourceKey(kind, meta.Project, meta.Name), obj, meta.ResourceVersion)
+	if err == store.ErrConflict {
+		return apiError(http.StatusConflict, "the object has been modified; get the latest version and try again")
+	}
+	return err
+}
+
+// delete removes the object of kind named name in project.
+func (c *Client) delete(kind, project, name string) error {
+	if err := c.store.Delete(store.ResourceKey(kind, project, name)); err != nil {
+		if err == store.ErrNotFound {
+			return notFound(kind)
+		}
+		return err
+	}
+	return nil
+}
+
+// remains reports whether the object of kind named name in project is still
+// stored, e.g. because a deletion left it for its finalizers to remove.
+func (c *Client) remains(kind, project, name string) bool {
+	var obj struct {
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+	}
+	return c.store.Get(store.ResourceKey(kind, project, name), &obj) == nil
+}
+
+// list returns the objects of kind in project (all projects when empty)
+// that match opts, ordered by project and name, as the server lists them.
+func list[T any](c *Client, kind, project string, opts []client.ListOption, meta func(*T) *v1alpha1.ObjectMeta) ([]T, error) {
+	q := url.Values{}
+	for _, opt := range opts {
+		opt(q)
+	}
+	sel, err := labels.Parse(q.Get("labelSelector"))
+	if err != nil {
+		return nil, apiError(http.StatusBadRequest, err.Error())
+	}
+	fields, err := labels.Parse(q.Get("fieldSelector"))
+	if err != nil {
+		return nil, apiError(http.StatusBadRequest, "invalid field selector: "+err.Error())
+	}
+	var phases []string
+	if v := q.Get("phase"); v != "" {
+		phases = strings.Split(v, ",")
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	after, _ := base64.RawURLEncoding.DecodeString(q.Get("continue"))
+
+	prefix := "/" + kind + "/"
+	if project != "" {
+		prefix += project + "/"
+	}
+	items, err := c.store.List(prefix, func() interface{} { return new(T) })
+	if err != nil {
+		return nil, err
+	}
+
+	key := func(obj *T) string {
+		m := meta(obj)
+		return m.Project + "/" + m.Name
+	}
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		obj := item.(*T)
+		if !sel.Matches(meta(obj).Labels) || len(after) > 0 && key(obj) <= string(after) {
+			continue
+		}
+		if !fields.Empty() || len(phases) > 0 {
+			set := labels.Fields(obj)
+			if !fields.Matches(set) || len(phases) > 0 && !slices.Contains(phases, set["status.phase"]) {
+				continue
+			}
+		}
+		out = append(out, *obj)
+	}
+	sort.Slice(out, func(i, j int) bool { return key(&out[i]) < key(&out[j]) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// all iterates over the result of list, which the fake returns in one go.
+func all[T any](objs []T, err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		for _, obj := range objs {
+			if !yield(obj, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Health and discovery
+// ---------------------------------------------------------------------------
+
+// Healthz always succeeds.
+func (c *Client) Healthz() error { return nil }
+
+// Readyz always succeeds.
+func (c *Client) Readyz() error { return nil }
+
+// ServerVersion reports the version of this build.
+func (c *Client) ServerVersion() (*client.ServerVersion, error) {
+	return &client.ServerVersion{
+		Version:    version.Get(),
+		APIVersion: v1alpha1.APIVersion,
+		GoVersion:  runtime.Version(),
+	}, nil
+}
+
+// Discover reports what the fake supports, which is what the server does
+// except for patch.
+func (c *Client) Discover() (*client.Discovery, error) {
+	crud := []string{
+		client.VerbCreate, client.VerbDelete, client.VerbGet,
+		client.VerbList, client.VerbUpdate,
+	}
+	resource := func(name, kind string, extraVerbs []string, subresources ...string) client.APIResource {
+		verbs := append(slices.Clone(crud), extraVerbs...)
+		sort.Strings(verbs)
+		return client.APIResource{
+			Name:          name,
+			Kind:          kind,
+			ProjectScoped: !v1alpha1.ClusterScoped(kind),
+			Verbs:         verbs,
+			Subresources:  subresources,
+		}
+	}
+	return &client.Discovery{
+		Version: version.Get(),
+		Resources: []client.APIResource{
+			resource("agentpods", v1alpha1.KindAgentPod, nil, "logs", "status"),
+			resource("agentpools", v1alpha1.KindAgentPool, nil, "scale", "status"),
+			resource("autoscalers", v1alpha1.KindAgentPoolAutoscaler, nil, "status"),
+			resource("devtasks", v1alpha1.KindDevTask, []string{client.VerbDeleteCollection},
+				"artifacts", "cancel", "retry", "status", "stream"),
+			{Name: "events", Kind: v1alpha1.KindEvent, ProjectScoped: true, Verbs: []string{client.VerbList}},
+			resource("projects", v1alpha1.KindProject, nil, "status"),
+			resource("agentnodes", v1alpha1.KindAgentNode, nil, "status"),
+			resource("secrets", v1alpha1.KindSecret, nil),
+			resource("configmaps", v1alpha1.KindConfigMap, nil),
+			resource("modelproviders", v1alpha1.KindModelProvider, nil),
+			resource("tooldefinitions", v1alpha1.KindToolDefinition, nil),
+		},
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// Projects
+// ---------------------------------------------------------------------------
+
+func projectMeta(p *v1alpha1.Project) *v1alpha1.ObjectMeta { return &p.Metadata }
+
+func (c *Client) CreateProject(p *v1alpha1.Project) (*v1alpha1.Project, error) {
+	out := *p
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindProject
+	out.Metadata.Project = ""
+	out.Status = "Active"
+	if err := c.create(v1alpha1.KindProject, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetProject(name string) (*v1alpha1.Project, error) {
+	var out v1alpha1.Project
+	if err := c.get(v1alpha1.KindProject, "", name, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListProjects(opts ...client.ListOption) ([]v1alpha1.Project, error) {
+	return list(c, v1alpha1.KindProject, "", opts, projectMeta)
+}
+
+func (c *Client) AllProjects(opts ...client.ListOption) iter.Seq2[v1alpha1.Project, error] {
+	return all(c.ListProjects(opts...))
+}
+
+func (c *Client) UpdateProject(p *v1alpha1.Project) (*v1alpha1.Project, error) {
+	out := *p
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindProject
+	out.Metadata.Project = ""
+	if err := c.update(v1alpha1.KindProject, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteProject deletes the project and, as the server does unless opts
+// include WithPropagation(v1alpha1.PropagationOrphan), its tasks,
+// autoscalers, pools and pods.
+func (c *Client) DeleteProject(name string, opts ...client.ListOption) error {
+	q := url.Values{}
+	for _, opt := range opts {
+		opt(q)
+	}
+	switch propagation := v1alpha1.DeletionPropagation(q.Get("propagation")); propagation {
+	case "", v1alpha1.PropagationDelete:
+		if err := c.get(v1alpha1.KindProject, "", name, &v1alpha1.Project{}); err != nil {
+			return err
+		}
+		keys, err := c.store.Snapshot()
+		if err != nil {
+			return err
+		}
+		for key := range keys {
+			for _, kind := range []string{v1alpha1.KindDevTask, v1alpha1.KindAgentPoolAutoscaler, v1alpha1.KindAgentPool, v1alpha1.KindAgentPod} {
+				if strings.HasPrefix(key, "/"+kind+"/"+name+"/") {
+					_ = c.store.Delete(key)
+				}
+			}
+		}
+	case v1alpha1.PropagationOrphan:
+	default:
+		return apiError(http.StatusBadRequest, fmt.Sprintf("invalid propagation %q (want delete or orphan)", propagation))
+	}
+	return c.delete(v1alpha1.KindProject, "", name)
+}
+
+// ---------------------------------------------------------------------------
+// AgentPods
+// ---------------------------------------------------------------------------
+
+func podMeta(p *v1alpha1.AgentPod) *v1alpha1.ObjectMeta { return &p.Metadata }
+
+func (c *Client) CreateAgentPod(pod *v1alpha1.AgentPod) (*v1alpha1.AgentPod, error) {
+	out := *pod
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindAgentPod
+	out.Status.Phase = v1alpha1.PodPending
+	if err := c.create(v1alpha1.KindAgentPod, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetAgentPod(name, project string) (*v1alpha1.AgentPod, error) {
+	var out v1alpha1.AgentPod
+	if err := c.get(v1alpha1.KindAgentPod, project, name, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListAgentPods(project string, opts ...client.ListOption) ([]v1alpha1.AgentPod, error) {
+	return list(c, v1alpha1.KindAgentPod, project, opts, podMeta)
+}
+
+func (c *Client) AllAgentPods(project string, opts ...client.ListOption) iter.Seq2[v1alpha1.AgentPod, error] {
+	return all(c.ListAgentPods(project, opts...))
+}
+
+func (c *Client) UpdateAgentPod(pod *v1alpha1.AgentPod) (*v1alpha1.AgentPod, error) {
+	out := *pod
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindAgentPod
+	if err := c.update(v1alpha1.KindAgentPod, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteAgentPod(name, project string) error {
+	return c.delete(v1alpha1.KindAgentPod, project, name)
+}
+
+// GetLogs returns no entries, as no pods run.
+func (c *Client) GetLogs(podName, project string, opts client.LogOptions) ([]v1alpha1.LogEntry, error) {
+	if _, err := c.GetAgentPod(podName, project); err != nil {
+		return nil, err
+	}
+	return []v1alpha1.LogEntry{}, nil
+}
+
+// FollowLogs delivers no entries, as no pods run, and returns when ctx is
+// cancelled.
+func (c *Client) FollowLogs(ctx context.Context, podName, project string, opts client.LogOptions, fn func(v1alpha1.LogEntry)) error {
+	if _, err := c.GetAgentPod(podName, project); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// StreamLogEntries delivers no entries, as no pods run; the channel is
+// closed when ctx is cancelled.
+func (c *Client) StreamLogEntries(ctx context.Context, podName, project string, opts client.LogOptions) (<-chan v1alpha1.LogEntry, error) {
+	if _, err := c.GetAgentPod(podName, project); err != nil {
+		return nil, err
+	}
+	entries := make(chan v1alpha1.LogEntry)
+	go func() {
+		<-ctx.Done()
+		close(entries)
+	}()
+	return entries, nil
+}
+
+// StreamLogs returns a reader without data, as no pods run, that blocks
+// until it is closed.
+func (c *Client) StreamLogs(podName, project string, opts client.LogOptions) (io.ReadCloser, error) {
+	if _, err := c.GetAgentPod(podName, project); err != nil {
+		return nil, err
+	}
+	pr, _ := io.Pipe()
+	return pr, nil
+}
+
+// ---------------------------------------------------------------------------
+// AgentPools
+// ---------------------------------------------------------------------------
+
+func poolMeta(p *v1alpha1.AgentPool) *v1alpha1.ObjectMeta { return &p.Metadata }
+
+func (c *Client) CreateAgentPool(pool *v1alpha1.AgentPool) (*v1alpha1.AgentPool, error) {
+	out := *pool
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindAgentPool
+	out.Status.Replicas = 0
+	out.Status.ReadyReplicas = 0
+	out.Status.BusyReplicas = 0
+	if err := c.create(v1alpha1.KindAgentPool, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetAgentPool(name, project string) (*v1alpha1.AgentPool, error) {
+	var out v1alpha1.AgentPool
+	if err := c.get(v1alpha1.KindAgentPool, project, name, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListAgentPools(project string, opts ...client.ListOption) ([]v1alpha1.AgentPool, error) {
+	return list(c, v1alpha1.KindAgentPool, project, opts, poolMeta)
+}
+
+func (c *Client) AllAgentPools(project string, opts ...client.ListOption) iter.Seq2[v1alpha1.AgentPool, error] {
+	return all(c.ListAgentPools(project, opts...))
+}
+
+func (c *Client) UpdateAgentPool(pool *v1alpha1.AgentPool) (*v1alpha1.AgentPool, error) {
+	out := *pool
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindAgentPool
+	if err := c.update(v1alpha1.KindAgentPool, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteAgentPool(name, project string) error {
+	return c.delete(v1alpha1.KindAgentPool, project, name)
+}
+
+func (c *Client) ScaleAgentPool(name, project string, replicas int) (*v1alpha1.AgentPool, error) {
+	return c.scale(name, project, nil, replicas)
+}
+
+func (c *Client) ScaleAgentPoolFrom(name, project string, current, replicas int) (*v1alpha1.AgentPool, error) {
+	return c.scale(name, project, &current, replicas)
+}
+
+func (c *Client) scale(name, project string, current *int, replicas int) (*v1alpha1.AgentPool, error) {
+	if replicas < 0 {
+		return nil, apiError(http.StatusBadRequest, "replicas must be >= 0")
+	}
+	pool, err := c.GetAgentPool(name, project)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && *current != pool.Spec.Replicas {
+		return nil, apiError(http.StatusConflict, fmt.Sprintf("expected %d replicas, agentpool has %d", *current, pool.Spec.Replicas))
+	}
+	pool.Spec.Replicas = replicas
+	pool.Metadata.UpdatedAt = time.Now()
+	if err := c.store.Update(store.ResourceKey(v1alpha1.KindAgentPool, project, name), pool); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+// ---------------------------------------------------------------------------
+// AgentPoolAutoscalers
+// ---------------------------------------------------------------------------
+
+func autoscalerMeta(as *v1alpha1.AgentPoolAutoscaler) *v1alpha1.ObjectMeta { return &as.Metadata }
+
+func (c *Client) CreateAutoscaler(as *v1alpha1.AgentPoolAutoscaler) (*v1alpha1.AgentPoolAutoscaler, error) {
+	out := *as
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindAgentPoolAutoscaler
+	out.Status = v1alpha1.AgentPoolAutoscalerStatus{}
+	if err := c.create(v1alpha1.KindAgentPoolAutoscaler, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetAutoscaler(name, project string) (*v1alpha1.AgentPoolAutoscaler, error) {
+	var out v1alpha1.AgentPoolAutoscaler
+	if err := c.get(v1alpha1.KindAgentPoolAutoscaler, project, name, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListAutoscalers(project string, opts ...client.ListOption) ([]v1alpha1.AgentPoolAutoscaler, error) {
+	return list(c, v1alpha1.KindAgentPoolAutoscaler, project, opts, autoscalerMeta)
+}
+
+func (c *Client) AllAutoscalers(project string, opts ...client.ListOption) iter.Seq2[v1alpha1.AgentPoolAutoscaler, error] {
+	return all(c.ListAutoscalers(project, opts...))
+}
+
+func (c *Client) UpdateAutoscaler(as *v1alpha1.AgentPoolAutoscaler) (*v1alpha1.AgentPoolAutoscaler, error) {
+	out := *as
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindAgentPoolAutoscaler
+	if err := c.update(v1alpha1.KindAgentPoolAutoscaler, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteAutoscaler(name, project string) error {
+	return c.delete(v1alpha1.KindAgentPoolAutoscaler, project, name)
+}
+
+// ---------------------------------------------------------------------------
+// Secrets
+// ---------------------------------------------------------------------------
+
+func secretMeta(s *v1alpha1.Secret) *v1alpha1.ObjectMeta { return &s.Metadata }
+
+// normalizeSecret merges StringData into Data, as the server does.
+func normalizeSecret(sec *v1alpha1.Secret) error {
+	for k, v := range sec.Data {
+		if _, err := base64.StdEncoding.DecodeString(v); err != nil {
+			return apiError(http.StatusBadRequest, fmt.Sprintf("secret data %q is not valid base64: %v", k, err))
+		}
+	}
+	if len(sec.StringData) > 0 {
+		data := make(map[string]string, len(sec.Data)+len(sec.StringData))
+		for k, v := range sec.Data {
+			data[k] = v
+		}
+		for k, v := range sec.StringData {
+			data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+		}
+		sec.Data = data
+	}
+	sec.StringData = nil
+	return nil
+}
+
+func (c *Client) CreateSecret(sec *v1alpha1.Secret) (*v1alpha1.Secret, error) {
+	out := *sec
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindSecret
+	if err := normalizeSecret(&out); err != nil {
+		return nil, err
+	}
+	if err := c.create(v1alpha1.KindSecret, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetSecret(name, project string) (*v1alpha1.Secret, error) {
+	var out v1alpha1.Secret
+	if err := c.get(v1alpha1.KindSecret, project, name, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListSecrets(project string, opts ...client.ListOption) ([]v1alpha1.Secret, error) {
+	return list(c, v1alpha1.KindSecret, project, opts, secretMeta)
+}
+
+func (c *Client) AllSecrets(project string, opts ...client.ListOption) iter.Seq2[v1alpha1.Secret, error] {
+	return all(c.ListSecrets(project, opts...))
+}
+
+func (c *Client) UpdateSecret(sec *v1alpha1.Secret) (*v1alpha1.Secret, error) {
+	out := *sec
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindSecret
+	if err := normalizeSecret(&out); err != nil {
+		return nil, err
+	}
+	if err := c.update(v1alpha1.KindSecret, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteSecret(name, project string) error {
+	return c.delete(v1alpha1.KindSecret, project, name)
+}
+
+// ---------------------------------------------------------------------------
+// ConfigMaps
+// ---------------------------------------------------------------------------
+
+func configMapMeta(cm *v1alpha1.ConfigMap) *v1alpha1.ObjectMeta { return &cm.Metadata }
+
+func (c *Client) CreateConfigMap(cm *v1alpha1.ConfigMap) (*v1alpha1.ConfigMap, error) {
+	out := *cm
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindConfigMap
+	if err := c.create(v1alpha1.KindConfigMap, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetConfigMap(name, project string) (*v1alpha1.ConfigMap, error) {
+	var out v1alpha1.ConfigMap
+	if err := c.get(v1alpha1.KindConfigMap, project, name, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListConfigMaps(project string, opts ...client.ListOption) ([]v1alpha1.ConfigMap, error) {
+	return list(c, v1alpha1.KindConfigMap, project, opts, configMapMeta)
+}
+
+func (c *Client) AllConfigMaps(project string, opts ...client.ListOption) iter.Seq2[v1alpha1.ConfigMap, error] {
+	return all(c.ListConfigMaps(project, opts...))
+}
+
+func (c *Client) UpdateConfigMap(cm *v1alpha1.ConfigMap) (*v1alpha1.ConfigMap, error) {
+	out := *cm
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindConfigMap
+	if err := c.update(v1alpha1.KindConfigMap, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteConfigMap(name, project string) error {
+	return c.delete(v1alpha1.KindConfigMap, project, name)
+}
+
+// ---------------------------------------------------------------------------
+// ModelProviders
+// ---------------------------------------------------------------------------
+
+func modelProviderMeta(mp *v1alpha1.ModelProvider) *v1alpha1.ObjectMeta { return &mp.Metadata }
+
+func (c *Client) CreateModelProvider(mp *v1alpha1.ModelProvider) (*v1alpha1.ModelProvider, error) {
+	out := *mp
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindModelProvider
+	if err := c.create(v1alpha1.KindModelProvider, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetModelProvider(name, project string) (*v1alpha1.ModelProvider, error) {
+	var out v1alpha1.ModelProvider
+	if err := c.get(v1alpha1.KindModelProvider, project, name, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListModelProviders(project string, opts ...client.ListOption) ([]v1alpha1.ModelProvider, error) {
+	return list(c, v1alpha1.KindModelProvider, project, opts, modelProviderMeta)
+}
+
+func (c *Client) AllModelProviders(project string, opts ...client.ListOption) iter.Seq2[v1alpha1.ModelProvider, error] {
+	return all(c.ListModelProviders(project, opts...))
+}
+
+func (c *Client) UpdateModelProvider(mp *v1alpha1.ModelProvider) (*v1alpha1.ModelProvider, error) {
+	out := *mp
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindModelProvider
+	if err := c.update(v1alpha1.KindModelProvider, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteModelProvider(name, project string) error {
+	return c.delete(v1alpha1.KindModelProvider, project, name)
+}
+
+// ---------------------------------------------------------------------------
+// ToolDefinitions
+// ---------------------------------------------------------------------------
+
+func toolDefinitionMeta(td *v1alpha1.ToolDefinition) *v1alpha1.ObjectMeta { return &td.Metadata }
+
+func (c *Client) CreateToolDefinition(td *v1alpha1.ToolDefinition) (*v1alpha1.ToolDefinition, error) {
+	out := *td
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindToolDefinition
+	if err := c.create(v1alpha1.KindToolDefinition, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetToolDefinition(name, project string) (*v1alpha1.ToolDefinition, error) {
+	var out v1alpha1.ToolDefinition
+	if err := c.get(v1alpha1.KindToolDefinition, project, name, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListToolDefinitions(project string, opts ...client.ListOption) ([]v1alpha1.ToolDefinition, error) {
+	return list(c, v1alpha1.KindToolDefinition, project, opts, toolDefinitionMeta)
+}
+
+func (c *Client) AllToolDefinitions(project string, opts ...client.ListOption) iter.Seq2[v1alpha1.ToolDefinition, error] {
+	return all(c.ListToolDefinitions(project, opts...))
+}
+
+func (c *Client) UpdateToolDefinition(td *v1alpha1.ToolDefinition) (*v1alpha1.ToolDefinition, error) {
+	out := *td
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindToolDefinition
+	if err := c.update(v1alpha1.KindToolDefinition, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteToolDefinition(name, project string) error {
+	return c.delete(v1alpha1.KindToolDefinition, project, name)
+}
+
+// ---------------------------------------------------------------------------
+// AgentNodes
+// ---------------------------------------------------------------------------
+
+func nodeMeta(n *v1alpha1.AgentNode) *v1alpha1.ObjectMeta { return &n.Metadata }
+
+func (c *Client) CreateAgentNode(n *v1alpha1.AgentNode) (*v1alpha1.AgentNode, error) {
+	out := *n
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindAgentNode
+	out.Metadata.Project = ""
+	if out.Status.Phase == "" {
+		out.Status.Phase = v1alpha1.NodeNotReady
+	}
+	if err := c.create(v1alpha1.KindAgentNode, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetAgentNode(name string) (*v1alpha1.AgentNode, error) {
+	var out v1alpha1.AgentNode
+	if err := c.get(v1alpha1.KindAgentNode, "", name, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListAgentNodes(opts ...client.ListOption) ([]v1alpha1.AgentNode, error) {
+	return list(c, v1alpha1.KindAgentNode, "", opts, nodeMeta)
+}
+
+func (c *Client) AllAgentNodes(opts ...client.ListOption) iter.Seq2[v1alpha1.AgentNode, error] {
+	return all(c.ListAgentNodes(opts...))
+}
+
+func (c *Client) UpdateAgentNode(n *v1alpha1.AgentNode) (*v1alpha1.AgentNode, error) {
+	existing, err := c.GetAgentNode(n.Metadata.Name)
+	if err != nil {
+		return nil, err
+	}
+	out := *n
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindAgentNode
+	out.Metadata.Project = ""
+	out.Status = existing.Status
+	if err := c.update(v1alpha1.KindAgentNode, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteAgentNode(name string) error {
+	return c.delete(v1alpha1.KindAgentNode, "", name)
+}
+
+// ---------------------------------------------------------------------------
+// DevTasks
+// ---------------------------------------------------------------------------
+
+func taskMeta(t *v1alpha1.DevTask) *v1alpha1.ObjectMeta { return &t.Metadata }
+
+func (c *Client) CreateDevTask(task *v1alpha1.DevTask) (*v1alpha1.DevTask, error) {
+	out := *task
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindDevTask
+	out.Status.Phase = v1alpha1.TaskPending
+	if out.Metadata.Project == "" {
+		out.Metadata.Project = manifest.DefaultValues.Project
+	}
+	if err := c.admitNewTask(out.Metadata.Project); err != nil {
+		return nil, err
+	}
+	if err := c.create(v1alpha1.KindDevTask, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetDevTask(name, project string) (*v1alpha1.DevTask, error) {
+	var out v1alpha1.DevTask
+	if err := c.get(v1alpha1.KindDevTask, project, name, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListDevTasks(project string, opts ...client.ListOption) ([]v1alpha1.DevTask, error) {
+	return list(c, v1alpha1.KindDevTask, project, opts, taskMeta)
+}
+
+func (c *Client) AllDevTasks(project string, opts ...client.ListOption) iter.Seq2[v1alpha1.DevTask, error] {
+	return all(c.ListDevTasks(project, opts...))
+}
+
+func (c *Client) UpdateDevTask(task *v1alpha1.DevTask) (*v1alpha1.DevTask, error) {
+	out := *task
+	out.APIVersion = v1alpha1.APIVersion
+	out.Kind = v1alpha1.KindDevTask
+	if err := c.update(v1alpha1.KindDevTask, &out.Metadata, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteDevTask(name, project string) error {
+	return c.delete(v1alpha1.KindDevTask, project, name)
+}
+
+// DeleteDevTasks deletes the tasks of project matching opts, which may
+// include WithOlderThan and WithDryRun, and returns their names in order.
+func (c *Client) DeleteDevTasks(project string, opts ...client.ListOption) ([]string, error) {
+	deleted, _, err := c.deleteDevTasks(project, opts...)
+	return deleted, err
+}
+
+// deleteDevTasks is DeleteDevTasks that also returns the names of the tasks
+// left for their finalizers to remove.
+func (c *Client) deleteDevTasks(project string, opts ...client.ListOption) (deleted, pending []string, err error) {
+	if project == "" {
+		return nil, nil, apiError(http.StatusBadRequest, "project query param is required")
+	}
+	q := url.Values{}
+	for _, opt := range opts {
+		opt(q)
+	}
+	var cutoff time.Time
+	if v := q.Get("olderThan"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, nil, apiError(http.StatusBadRequest, fmt.Sprintf("invalid olderThan %q", v))
+		}
+		cutoff = time.Now().Add(-d)
+	}
+	dryRun := q.Get("dryRun") != "" && q.Get("dryRun") != "false"
+
+	tasks, err := c.ListDevTasks(project, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	deleted, pending = make([]string, 0), make([]string, 0)
+	for _, task := range tasks {
+		if !cutoff.IsZero() {
+			at := task.Status.FinishedAt
+			if at.IsZero() {
+				at = task.Metadata.CreatedAt
+			}
+			if at.After(cutoff) {
+				continue
+			}
+		}
+		if !dryRun {
+			if err := c.DeleteDevTask(task.Metadata.Name, project); err != nil && !client.IsNotFound(err) {
+				return nil, nil, err
+			}
+			if c.remains(v1alpha1.KindDevTask, project, task.Metadata.Name) {
+				pending = append(pending, task.Metadata.Name)
+			}
+		}
+		deleted = append(deleted, task.Metadata.Name)
+	}
+	return deleted, pending, nil
+}
+
+func (c *Client) RetryDevTask(name, project string) (*v1alpha1.DevTask, error) {
+	task, err := c.GetDevTask(name, project)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status.Phase != v1alpha1.TaskFailed {
+		return nil, apiError(http.StatusConflict, fmt.Sprintf("devtask is %s; only Failed devtasks can be retried", task.Status.Phase))
+	}
+	task.Status.Phase = v1alpha1.TaskPending
+	task.Status.AssignedPod = ""
+	task.Status.Error = ""
+	task.Status.Cancelled = false
+	task.Status.StartedAt = time.Time{}
+	task.Status.FinishedAt = time.Time{}
+	task.Status.Conditions = nil
+	task.Metadata.UpdatedAt = time.Now()
+	if err := c.store.Update(store.ResourceKey(v1alpha1.KindDevTask, project, name), task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// CancelDevTask fails an unfinished task right away; with no execution to
+// stop, a Running task is cancelled like a Pending one.
+func (c *Client) CancelDevTask(name, project string) (*v1alpha1.DevTask, error) {
+	task, err := c.GetDevTask(name, project)
+	if err != nil {
+		return nil, err
+	}
+	switch task.Status.Phase {
+	case v1alpha1.TaskSucceeded, v1alpha1.TaskFailed:
+		return nil, apiError(http.StatusConflict, fmt.Sprintf("devtask already %s", task.Status.Phase))
+	}
+	now := time.Now()
+	task.Status.Phase = v1alpha1.TaskFailed
+	task.Status.Error = "task cancelled"
+	task.Status.Cancelled = true
+	task.Status.FinishedAt = now
+	v1alpha1.SetCondition(&task.Status.Conditions, v1alpha1.NewCondition(
+		v1alpha1.ConditionComplete, v1alpha1.ConditionFalse, v1alpha1.ReasonCancelled, task.Status.Error))
+	task.Metadata.UpdatedAt = now
+	if err := c.store.Update(store.ResourceKey(v1alpha1.KindDevTask, project, name), task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// FollowTask delivers the phase of a finished task. Tasks never run in the
+// fake, so for an unfinished one it waits for ctx to be cancelled.
+func (c *Client) FollowTask(ctx context.Context, name, project string, fn func(v1alpha1.TaskStreamEvent)) error {
+	task, err := c.GetDevTask(name, project)
+	if err != nil {
+		return err
+	}
+	switch task.Status.Phase {
+	case v1alpha1.TaskSucceeded, v1alpha1.TaskFailed:
+		fn(v1alpha1.TaskStreamEvent{Phase: task.Status.Phase})
+		return nil
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// ListArtifacts returns no artifacts, as tasks never run in the fake.
+func (c *Client) ListArtifacts(taskName, project string) ([]v1alpha1.Artifact, error) {
+	if _, err := c.GetDevTask(taskName, project); err != nil {
+		return nil, err
+	}
+	return []v1alpha1.Artifact{}, nil
+}
+
+// DownloadArtifact fails with a not-found error, as tasks never run in the
+// fake.
+func (c *Client) DownloadArtifact(taskName, project, name string, w io.Writer) error {
+	if _, err := c.GetDevTask(taskName, project); err != nil {
+		return err
+	}
+	return apiError(http.StatusNotFound, "artifact not found")
+}
+
+// ---------------------------------------------------------------------------
+// Generic
+// ---------------------------------------------------------------------------
+
+// ApplyInto creates or updates resource, one of the typed resources or its
+// JSON-shaped map, and decodes the stored object into out.
+func (c *Client) ApplyInto(resource, out interface{}, dryRun bool) (bool, error) {
+	return c.apply(resource, out, dryRun)
+}
+
+func (c *Client) apply(resource, out interface{}, dryRun bool) (bool, error) {
+	kind, _, err := identify(resource)
+	if err != nil {
+		return false, apiError(http.StatusBadRequest, "cannot determine resource kind: "+err.Error())
+	}
+	obj, typeMeta, meta := newObject(kind)
+	if obj == nil {
+		return false, apiError(http.StatusBadRequest, fmt.Sprintf("unsupported kind %q", kind))
+	}
+	raw, err := json.Marshal(resource)
+	if err == nil {
+		err = json.Unmarshal(raw, obj)
+	}
+	if err != nil {
+		return false, apiError(http.StatusBadRequest, err.Error())
+	}
+	if v1alpha1.ClusterScoped(kind) {
+		meta.Project = ""
+	}
+	if err := c.admit(kind, obj); err != nil {
+		return false, err
+	}
+	if sec, ok := obj.(*v1alpha1.Secret); ok {
+		if err := normalizeSecret(sec); err != nil {
+			return false, err
+		}
+	}
+
+	typeMeta.APIVersion = v1alpha1.APIVersion
+	typeMeta.Kind = kind
+	key := store.ResourceKey(kind, meta.Project, meta.Name)
+	var existing struct {
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+	}
+	exists := c.store.Get(key, &existing) == nil
+	if kind == v1alpha1.KindDevTask && !exists {
+		if err := c.admitNewTask(meta.Project); err != nil {
+			return false, err
+		}
+	}
+	now := time.Now()
+	if exists {
+		meta.UID = existing.Metadata.UID
+		meta.CreatedAt = existing.Metadata.CreatedAt
+		if meta.Finalizers == nil && (kind == v1alpha1.KindAgentPod || kind == v1alpha1.KindDevTask) {
+			meta.Finalizers = existing.Metadata.Finalizers
+		}
+		if meta.OwnerReferences == nil && kind == v1alpha1.KindAgentPod {
+			meta.OwnerReferences = existing.Metadata.OwnerReferences
+		}
+	} else {
+		meta.UID = uuid.New().String()
+		meta.CreatedAt = now
+	}
+	meta.UpdatedAt = now
+
+	if !dryRun {
+		if exists {
+			err = c.store.UpdateIfVersion(key, obj, meta.ResourceVersion)
+		} else {
+			err = c.store.Create(key, obj)
+		}
+		if err == store.ErrConflict {
+			return false, apiError(http.StatusConflict, "the object has been modified; get the latest version and try again")
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	if out != nil {
+		raw, _ = json.Marshal(obj)
+		if err := json.Unmarshal(raw, out); err != nil {
+			return false, err
+		}
+	}
+	return !exists, nil
+}
+
+// newObject returns a new object of kind with its type and object metadata,
+// or nil for kinds the fake does not know.
+func newObject(kind string) (interface{}, *v1alpha1.TypeMeta, *v1alpha1.ObjectMeta) {
+	switch kind {
+	case v1alpha1.KindProject:
+		obj := &v1alpha1.Project{}
+		return obj, &obj.TypeMeta, &obj.Metadata
+	case v1alpha1.KindAgentPod:
+		obj := &v1alpha1.AgentPod{}
+		return obj, &obj.TypeMeta, &obj.Metadata
+	case v1alpha1.KindAgentPool:
+		obj := &v1alpha1.AgentPool{}
+		return obj, &obj.TypeMeta, &obj.Metadata
+	case v1alpha1.KindAgentPoolAutoscaler:
+		obj := &v1alpha1.AgentPoolAutoscaler{}
+		return obj, &obj.TypeMeta, &obj.Metadata
+	case v1alpha1.KindDevTask:
+		obj := &v1alpha1.DevTask{}
+		return obj, &obj.TypeMeta, &obj.Metadata
+	case v1alpha1.KindSecret:
+		obj := &v1alpha1.Secret{}
+		return obj, &obj.TypeMeta, &obj.Metadata
+	case v1alpha1.KindConfigMap:
+		obj := &v1alpha1.ConfigMap{}
+		return obj, &obj.TypeMeta, &obj.Metadata
+	case v1alpha1.KindModelProvider:
+		obj := &v1alpha1.ModelProvider{}
+		return obj, &obj.TypeMeta, &obj.Metadata
+	case v1alpha1.KindToolDefinition:
+		obj := &v1alpha1.ToolDefinition{}
+		return obj, &obj.TypeMeta, &obj.Metadata
+	case v1alpha1.KindAgentNode:
+		obj := &v1alpha1.AgentNode{}
+		return obj, &obj.TypeMeta, &obj.Metadata
+	}
+	return nil, nil, nil
+}
+
+// kindFor returns the kind that kind, a kind or an API path segment, names.
+func kindFor(kind string) (string, bool) {
+	lower := strings.ToLower(kind)
+	for resource, k := range resourceKinds {
+		if lower == resource || lower == strings.ToLower(k) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// Get retrieves the named resource into out. kind is a kind such as
+// "AgentPool" or a path segment such as "agentpools".
+func (c *Client) Get(kind, name, project string, out interface{}) error {
+	k, ok := kindFor(kind)
+	if !ok {
+		return apiError(http.StatusNotFound, "404 page not found")
+	}
+	if v1alpha1.ClusterScoped(k) {
+		project = ""
+	}
+	return c.get(k, project, name, out)
+}
+
+// DeleteCollection deletes the objects of kind in project matching the label
+// selector of opts, or none with dry run, and returns their names and those
+// of them left for their finalizers to remove. DevTasks are deleted as
+// DeleteDevTasks deletes them.
+func (c *Client) DeleteCollection(kind, project string, opts ...client.ListOption) (deleted, pending []string, err error) {
+	k, ok := kindFor(kind)
+	if !ok || k == v1alpha1.KindProject {
+		return nil, nil, apiError(http.StatusMethodNotAllowed, "405 method not allowed")
+	}
+	if k == v1alpha1.KindDevTask {
+		return c.deleteDevTasks(project, opts...)
+	}
+	if v1alpha1.ClusterScoped(k) {
+		project = ""
+	} else if project == "" {
+		return nil, nil, apiError(http.StatusBadRequest, "project query param is required")
+	}
+	q := url.Values{}
+	for _, opt := range opts {
+		opt(q)
+	}
+	dryRun := q.Get("dryRun") != "" && q.Get("dryRun") != "false"
+
+	type metaOnly struct {
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+	}
+	objs, err := list(c, k, project, []client.ListOption{client.WithLabelSelector(q.Get("labelSelector"))},
+		func(o *metaOnly) *v1alpha1.ObjectMeta { return &o.Metadata })
+	if err != nil {
+		return nil, nil, err
+	}
+	deleted, pending = make([]string, 0, len(objs)), make([]string, 0)
+	for _, obj := range objs {
+		if !dryRun {
+			if err := c.delete(k, project, obj.Metadata.Name); err != nil && !client.IsNotFound(err) {
+				return nil, nil, err
+			}
+			if c.remains(k, project, obj.Metadata.Name) {
+				pending = append(pending, obj.Metadata.Name)
+			}
+		}
+		deleted = append(deleted, obj.Metadata.Name)
+	}
+	return deleted, pending, nil
+}
+
+// GetSubresource supports the status subresource.
+func (c *Client) GetSubresource(kind, name, project, subresource string, out interface{}) error {
+	if subresource != "status" {
+		return apiError(http.StatusNotFound, "404 page not found")
+	}
+	var doc struct {
+		Status json.RawMessage `json:"status"`
+	}
+	if err := c.Get(kind, name, project, &doc); err != nil {
+		return err
+	}
+	if out == nil || len(doc.Status) == 0 {
+		return nil
+	}
+	return json.Unmarshal(doc.Status, out)
+}
+
+// UpdateSubresource supports the status subresource of every kind and the
+// scale subresource of agent pools.
+func (c *Client) UpdateSubresource(kind, name, project, subresource string, body, out interface{}) error {
+	k, ok := kindFor(kind)
+	switch {
+	case ok && subresource == "scale" && k == v1alpha1.KindAgentPool:
+		var scale struct {
+			Replicas int `json:"replicas"`
+		}
+		if err := convert(body, &scale); err != nil {
+			return apiError(http.StatusBadRequest, err.Error())
+		}
+		pool, err := c.ScaleAgentPool(name, project, scale.Replicas)
+		if err != nil || out == nil {
+			return err
+		}
+		return convert(pool, out)
+	case !ok || subresource != "status":
+		return apiError(http.StatusNotFound, "404 page not found")
+	}
+
+	if v1alpha1.ClusterScoped(k) {
+		project = ""
+	}
+	var doc map[string]interface{}
+	if err := c.get(k, project, name, &doc); err != nil {
+		return err
+	}
+	var status interface{}
+	if err := convert(body, &status); err != nil {
+		return apiError(http.StatusBadRequest, err.Error())
+	}
+	doc["status"] = status
+	if meta, ok := doc["metadata"].(map[string]interface{}); ok {
+		meta["updatedAt"] = time.Now()
+	}
+	obj, _, _ := newObject(k)
+	if err := convert(doc, obj); err != nil {
+		return apiError(http.StatusUnprocessableEntity, "invalid status: "+err.Error())
+	}
+	if err := c.store.Update(store.ResourceKey(k, project, name), obj); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return c.GetSubresource(k, name, project, "status", out)
+}
+
+func (c *Client) GetStatus(kind, name, project string, out interface{}) error {
+	return c.GetSubresource(kind, name, project, "status", out)
+}
+
+func (c *Client) UpdateStatus(kind, name, project string, status, out interface{}) error {
+	return c.UpdateSubresource(kind, name, project, "status", status, out)
+}
+
+func (c *Client) Scale(kind, name, project string, replicas int, out interface{}) error {
+	return c.UpdateSubresource(kind, name, project, "scale", map[string]int{"replicas": replicas}, out)
+}
+
+// convert copies in into out through their JSON form.
+func convert(in, out interface{}) error {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// Patch applies a JSON merge patch to the named resource, keeping its
+// identity as the server does. JSON patches are not supported; they fail
+// with 501 Not Implemented.
+func (c *Client) Patch(resource, name, project string, pt client.PatchType, patch []byte, out interface{}) error {
+	if pt != client.MergePatchType {
+		return unsupported("Patch with " + string(pt))
+	}
+	k, ok := kindFor(resource)
+	if !ok {
+		return apiError(http.StatusNotFound, "404 page not found")
+	}
+	if v1alpha1.ClusterScoped(k) {
+		project = ""
+	}
+	var doc map[string]interface{}
+	if err := c.get(k, project, name, &doc); err != nil {
+		return err
+	}
+	var p interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return apiError(http.StatusBadRequest, "invalid merge patch: "+err.Error())
+	}
+
+	obj, _, meta := newObject(k)
+	if err := convert(mergePatch(doc, p), obj); err != nil {
+		return apiError(http.StatusUnprocessableEntity, "patched object is invalid: "+err.Error())
+	}
+	var existing struct {
+		Metadata v1alpha1.ObjectMeta `json:"metadata"`
+	}
+	if err := convert(doc, &existing); err != nil {
+		return err
+	}
+	meta.Name, meta.Project = existing.Metadata.Name, existing.Metadata.Project
+	meta.UID, meta.CreatedAt = existing.Metadata.UID, existing.Metadata.CreatedAt
+	meta.UpdatedAt = time.Now()
+	if err := c.admit(k, obj); err != nil {
+		return err
+	}
+	if err := c.store.Update(store.ResourceKey(k, project, name), obj); err != nil {
+		if err == store.ErrNotFound {
+			return notFound(k)
+		}
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return convert(obj, out)
+}
+
+// mergePatch applies the RFC 7386 merge patch to target.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+// ---------------------------------------------------------------------------
+// Events and watches
+// ---------------------------------------------------------------------------
+
+// ListEvents returns the events added with Add, oldest first.
+func (c *Client) ListEvents(project, kind, name string) ([]v1alpha1.Event, error) {
+	prefix := "/" + v1alpha1.KindEvent + "/"
+	if project != "" {
+		prefix += project + "/"
+	}
+	items, err := c.store.List(prefix, func() interface{} { return &v1alpha1.Event{} })
+	if err != nil {
+		return nil, err
+	}
+	out := make([]v1alpha1.Event, 0, len(items))
+	for _, item := range items {
+		ev := item.(*v1alpha1.Event)
+		if kind != "" && ev.InvolvedObject.Kind != kind || name != "" && ev.InvolvedObject.Name != name {
+			continue
+		}
+		out = append(out, *ev)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastTimestamp.Before(out[j].LastTimestamp) })
+	return out, nil
+}
+
+// ListAuditEntries returns the audit entries added with Add, oldest first.
+// The fake does not record entries of its own.
+func (c *Client) ListAuditEntries(opts client.AuditOptions) ([]v1alpha1.AuditEntry, error) {
+	items, err := c.store.List("/"+v1alpha1.KindAuditEntry+"/", func() interface{} { return &v1alpha1.AuditEntry{} })
+	if err != nil {
+		return nil, err
+	}
+	var since time.Time
+	if opts.Since > 0 {
+		since = time.Now().Add(-opts.Since)
+	}
+	out := make([]v1alpha1.AuditEntry, 0, len(items))
+	for _, item := range items {
+		e := item.(*v1alpha1.AuditEntry)
+		if opts.User != "" && e.User != opts.User || opts.Kind != "" && e.Object.Kind != opts.Kind ||
+			opts.Project != "" && e.Object.Project != opts.Project || opts.Name != "" && e.Object.Name != opts.Name ||
+			e.Timestamp.Before(since) {
+			continue
+		}
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+	if opts.Limit > 0 && len(out) > opts.Limit {
+		out = out[len(out)-opts.Limit:]
+	}
+	return out, nil
+}
+
+// Watch streams the changes made through the fake to fn until ctx is
+// cancelled. Each event's Object holds the resource as a json.RawMessage,
+// as with the real client.
+func (c *Client) Watch(ctx context.Context, kind, project string, fn func(v1alpha1.WatchEvent)) error {
+	prefix := "/"
+	if kind != "" {
+		prefix += kind + "/"
+		if project != "" {
+			prefix += project + "/"
+		}
+	}
+	events, cancel := c.store.Watch(prefix)
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			if kind == "" && project != "" && !strings.HasPrefix(ev.Key, "/"+ev.Kind+"/"+project+"/") {
+				continue
+			}
+			raw, err := json.Marshal(ev.Object)
+			if err != nil {
+				return err
+			}
+			ev.Object = json.RawMessage(raw)
+			fn(ev)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Admin
+// ---------------------------------------------------------------------------
+
+// Backup is not supported by the fake; it fails with 501 Not Implemented.
+func (c *Client) Backup(w io.Writer, resourcesOnly bool) error {
+	return unsupported("Backup")
+}
+
+// Restore is not supported by the fake; it fails with 501 Not Implemented.
+func (c *Client) Restore(r io.Reader) (int, error) {
+	return 0, unsupported("Restore")
+}
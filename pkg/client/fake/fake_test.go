@@ -0,0 +1,282 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+	"github.com/klubi/orca/pkg/client"
+)
+
+func task(name string, phase v1alpha1.DevTaskPhase, labels map[string]string) *v1alpha1.DevTask {
+	t := &v1alpha1.DevTask{}
+	t.Metadata.Name = name
+	t.Metadata.Project = "default"
+	t.Metadata.Labels = labels
+	t.Spec.Prompt = "do " + name
+	t.Status.Phase = phase
+	return t
+}
+
+func TestDevTasks(t *testing.T) {
+	c := NewClient(
+		task("b", v1alpha1.TaskFailed, map[string]string{"batch": "x"}),
+		task("a", v1alpha1.TaskSucceeded, map[string]string{"batch": "x"}),
+		task("c", v1alpha1.TaskRunning, nil),
+	)
+
+	created, err := c.CreateDevTask(task("d", v1alpha1.TaskFailed, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Status.Phase != v1alpha1.TaskPending || created.Metadata.UID == "" || created.Kind != v1alpha1.KindDevTask {
+		t.Errorf("created task = %+v, want a defaulted Pending task", created)
+	}
+	if _, err := c.CreateDevTask(task("d", "", nil)); !client.IsConflict(err) {
+		t.Errorf("creating a duplicate returned %v, want a conflict", err)
+	}
+	if _, err := c.GetDevTask("nope", "default"); !client.IsNotFound(err) {
+		t.Errorf("getting a missing task returned %v, want not found", err)
+	}
+
+	names := func(tasks []v1alpha1.DevTask) []string {
+		out := make([]string, len(tasks))
+		for i, t := range tasks {
+			out[i] = t.Metadata.Name
+		}
+		return out
+	}
+	tests := []struct {
+		name string
+		opts []client.ListOption
+		want string
+	}{
+		{"all", nil, "[a b c d]"},
+		{"labels", []client.ListOption{client.WithLabelSelector("batch=x")}, "[a b]"},
+		{"fields", []client.ListOption{client.WithFieldSelector("status.phase!=Pending")}, "[a b c]"},
+		{"phases", []client.ListOption{client.WithPhases(v1alpha1.TaskFailed, v1alpha1.TaskRunning)}, "[b c]"},
+		{"limit", []client.ListOption{client.WithLimit(3)}, "[a b c]"},
+	}
+	for _, tt := range tests {
+		tasks, err := c.ListDevTasks("default", tt.opts...)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := fmt.Sprint(names(tasks)); got != tt.want {
+			t.Errorf("%s: listed %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := c.RetryDevTask("a", "default"); !client.IsConflict(err) {
+		t.Errorf("retrying a Succeeded task returned %v, want a conflict", err)
+	}
+	retried, err := c.RetryDevTask("b", "default")
+	if err != nil || retried.Status.Phase != v1alpha1.TaskPending {
+		t.Errorf("retry = %v, %v, want a Pending task", retried, err)
+	}
+	cancelled, err := c.CancelDevTask("c", "default")
+	if err != nil || cancelled.Status.Phase != v1alpha1.TaskFailed || !cancelled.Status.Cancelled {
+		t.Errorf("cancel = %v, %v, want a cancelled Failed task", cancelled, err)
+	}
+	if c := v1alpha1.FindCondition(cancelled.Status.Conditions, v1alpha1.ConditionComplete); c == nil ||
+		c.Status != v1alpha1.ConditionFalse || c.Reason != v1alpha1.ReasonCancelled || c.LastTransitionTime.IsZero() {
+		t.Errorf("cancelled task conditions = %+v, want Complete=False/Cancelled", cancelled.Status.Conditions)
+	}
+
+	deleted, err := c.DeleteDevTasks("default", client.WithLabelSelector("batch=x"))
+	if err != nil || fmt.Sprint(deleted) != "[a b]" {
+		t.Errorf("DeleteDevTasks = %v, %v, want [a b]", deleted, err)
+	}
+	var left []string
+	for task, err := range c.AllDevTasks("") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		left = append(left, task.Metadata.Name)
+	}
+	if got := fmt.Sprint(left); got != "[c d]" {
+		t.Errorf("tasks left = %s, want [c d]", got)
+	}
+}
+
+func TestApplyAndWatch(t *testing.T) {
+	c := NewClient()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan v1alpha1.WatchEvent, 4)
+	done := make(chan error)
+	go func() {
+		done <- c.Watch(ctx, v1alpha1.KindAgentPool, "default", func(ev v1alpha1.WatchEvent) { events <- ev })
+	}()
+	// Let the watch register before changing anything.
+	time.Sleep(20 * time.Millisecond)
+
+	pool := map[string]interface{}{
+		"kind":     v1alpha1.KindAgentPool,
+		"metadata": map[string]interface{}{"name": "workers", "project": "default"},
+		"spec":     map[string]interface{}{"replicas": 2},
+	}
+	if created, err := c.ApplyInto(pool, nil, true); err != nil || !created {
+		t.Fatalf("dry run = %v, %v, want a creation", created, err)
+	}
+	if _, err := c.GetAgentPool("workers", "default"); !client.IsNotFound(err) {
+		t.Fatalf("dry run stored the pool: %v", err)
+	}
+	applied, created, err := client.Apply(c, &v1alpha1.AgentPool{})
+	if err == nil {
+		t.Errorf("applying a nameless pool returned %v, want an error", applied)
+	}
+	if _, err := c.ApplyInto(pool, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	typed := &v1alpha1.AgentPool{}
+	typed.Metadata.Name = "workers"
+	typed.Metadata.Project = "default"
+	typed.Spec.Replicas = 2
+	applied, created, err = client.Apply(c, typed)
+	if err != nil || created || applied.Metadata.UID == "" {
+		t.Fatalf("re-apply = %+v, created %v, %v; want an update", applied, created, err)
+	}
+	scaled, err := c.ScaleAgentPoolFrom("workers", "default", 2, 5)
+	if err != nil || scaled.Spec.Replicas != 5 {
+		t.Fatalf("scale = %v, %v, want 5 replicas", scaled, err)
+	}
+	if _, err := c.ScaleAgentPoolFrom("workers", "default", 2, 1); !client.IsConflict(err) {
+		t.Errorf("scaling from a stale count returned %v, want a conflict", err)
+	}
+
+	for _, want := range []v1alpha1.EventType{v1alpha1.EventAdded, v1alpha1.EventModified} {
+		select {
+		case ev := <-events:
+			var got v1alpha1.AgentPool
+			if ev.Type != want || json.Unmarshal(ev.Object.(json.RawMessage), &got) != nil || got.Metadata.Name != "workers" {
+				t.Errorf("event = %s %v, want %s of workers", ev.Type, ev.Object, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s event", want)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch returned %v, want nil", err)
+	}
+}
+
+func TestSubresources(t *testing.T) {
+	pool := &v1alpha1.AgentPool{}
+	pool.Metadata.Name = "workers"
+	pool.Metadata.Project = "default"
+	c := NewClient(pool, task("fix", v1alpha1.TaskPending, nil))
+
+	var status v1alpha1.DevTaskStatus
+	if err := c.UpdateStatus("DevTask", "fix", "default", v1alpha1.DevTaskStatus{Phase: v1alpha1.TaskRunning}, &status); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GetStatus("devtasks", "fix", "default", &status); err != nil || status.Phase != v1alpha1.TaskRunning {
+		t.Errorf("status = %+v, %v, want Running", status, err)
+	}
+
+	var scaled v1alpha1.AgentPool
+	if err := c.Scale("AgentPool", "workers", "default", 3, &scaled); err != nil || scaled.Spec.Replicas != 3 {
+		t.Errorf("scaled pool = %+v, %v, want 3 replicas", scaled.Spec, err)
+	}
+	if err := c.Scale("DevTask", "fix", "default", 3, nil); !client.IsNotFound(err) {
+		t.Errorf("scaling a task returned %v, want not found", err)
+	}
+}
+
+func TestSecretsAndConfigMaps(t *testing.T) {
+	c := NewClient()
+
+	sec := &v1alpha1.Secret{StringData: map[string]string{"token": "abc"}}
+	sec.Metadata.Name = "gh"
+	created, err := c.CreateSecret(sec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Data["token"] != "YWJj" || created.StringData != nil || created.Metadata.Project != "default" {
+		t.Errorf("created secret = %+v, want stringData merged into data", created)
+	}
+	sec.Data = map[string]string{"bad": "not base64!"}
+	if _, err := c.UpdateSecret(sec); err == nil {
+		t.Error("updating a secret with invalid data succeeded")
+	}
+
+	var applied v1alpha1.Secret
+	if _, err := c.ApplyInto(map[string]interface{}{
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "gh"},
+		"stringData": map[string]interface{}{"user": "orca"},
+	}, &applied, false); err != nil {
+		t.Fatal(err)
+	}
+	if applied.Data["user"] != "b3JjYQ==" || applied.Metadata.UID != created.Metadata.UID {
+		t.Errorf("applied secret = %+v", applied)
+	}
+
+	cm := &v1alpha1.ConfigMap{Data: map[string]string{"review.md": "Be thorough."}}
+	cm.Metadata.Name = "prompts"
+	if _, err := c.CreateConfigMap(cm); err != nil {
+		t.Fatal(err)
+	}
+	cms, err := c.ListConfigMaps("default")
+	if err != nil || len(cms) != 1 || cms[0].Kind != v1alpha1.KindConfigMap {
+		t.Errorf("ListConfigMaps = %+v, %v", cms, err)
+	}
+	if err := c.DeleteConfigMap("prompts", "default"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetConfigMap("prompts", "default"); !client.IsNotFound(err) {
+		t.Errorf("getting a deleted configmap returned %v, want not found", err)
+	}
+
+	for _, name := range []string{"b", "a", "c"} {
+		cm := &v1alpha1.ConfigMap{}
+		cm.Metadata.Name = name
+		if name != "c" {
+			cm.Metadata.Labels = map[string]string{"tier": "cache"}
+		}
+		if _, err := c.CreateConfigMap(cm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deleted, _, err := c.DeleteCollection("configmaps", "default", client.WithLabelSelector("tier=cache"), client.WithDryRun())
+	if err != nil || fmt.Sprint(deleted) != "[a b]" {
+		t.Errorf("DeleteCollection with dry run = %v, %v, want [a b]", deleted, err)
+	}
+	deleted, pending, err := c.DeleteCollection(v1alpha1.KindConfigMap, "default", client.WithLabelSelector("tier=cache"))
+	if err != nil || fmt.Sprint(deleted) != "[a b]" || len(pending) != 0 {
+		t.Errorf("DeleteCollection = %v, %v, %v, want [a b] and nothing pending", deleted, pending, err)
+	}
+	if cms, err := c.ListConfigMaps("default"); err != nil || len(cms) != 1 || cms[0].Metadata.Name != "c" {
+		t.Errorf("config maps left = %+v, %v, want c", cms, err)
+	}
+}
+
+func TestDeleteProject(t *testing.T) {
+	project := &v1alpha1.Project{Metadata: v1alpha1.ObjectMeta{Name: "default"}}
+	c := NewClient(project, task("a", v1alpha1.TaskPending, nil))
+	if err := c.DeleteProject("default", client.WithPropagation(v1alpha1.PropagationOrphan)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetDevTask("a", "default"); err != nil {
+		t.Errorf("task of a project deleted with orphan propagation: %v, want it kept", err)
+	}
+
+	if err := c.Add(project); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteProject("default"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetDevTask("a", "default"); !client.IsNotFound(err) {
+		t.Errorf("task of a deleted project: %v, want it deleted", err)
+	}
+	if err := c.DeleteProject("default"); !client.IsNotFound(err) {
+		t.Errorf("deleting a missing project returned %v, want not found", err)
+	}
+}
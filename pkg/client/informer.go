@@ -0,0 +1,390 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+const (
+	// informerResyncPeriod is how often an informer lists its resources
+	// again while watching, to catch up on changes a watch may have missed.
+	informerResyncPeriod = 5 * time.Minute
+	// informerPollInterval is how often an informer lists its resources
+	// from servers without the watch endpoint.
+	informerPollInterval = 5 * time.Second
+)
+
+// EventHandler is told about the changes an Informer sees. Unset funcs are
+// skipped. Handlers run one at a time, on the goroutine of Informer.Run.
+type EventHandler[T any] struct {
+	OnAdd    func(obj T)
+	OnUpdate func(oldObj, newObj T)
+	OnDelete func(obj T)
+}
+
+// IndexFunc returns the values an object is indexed under, e.g. its labels'
+// values or its assigned pod.
+type IndexFunc[T any] func(obj T) []string
+
+// Informer keeps a local, indexed copy of the resources of one kind in one
+// project (all projects when empty) and tells registered handlers about
+// every change. It lists the resources once, then applies the changes the
+// watch endpoint streams, so consumers read from memory instead of listing
+// the resources over and over.
+//
+// After a broken stream the resources are listed again, backing off up to
+// 30s, and the differences are dispatched as changes. A change made between
+// a list and the start of the watch is caught by the next resync, every
+// informerResyncPeriod. Servers without the watch endpoint are polled.
+type Informer[T any] struct {
+	client  Interface
+	kind    string
+	project string
+	list    func(project string) ([]T, error)
+	meta    func(obj *T) *v1alpha1.ObjectMeta
+
+	// dispatchMu serializes changing the cache with calling the handlers,
+	// so a handler added late sees every object exactly once.
+	dispatchMu sync.Mutex
+	handlers   []EventHandler[T]
+
+	mu       sync.RWMutex
+	items    map[string]T
+	indexers map[string]IndexFunc[T]
+	indices  map[string]map[string]map[string]bool // index -> value -> item keys
+
+	syncOnce sync.Once
+	synced   chan struct{}
+}
+
+// NewInformer creates an informer for the resources of kind in project,
+// listed with list. meta returns an object's metadata, which identifies it.
+// Most callers want one of the typed constructors such as
+// NewDevTaskInformer.
+func NewInformer[T any](c Interface, kind, project string, list func(project string) ([]T, error), meta func(obj *T) *v1alpha1.ObjectMeta) *Informer[T] {
+	return &Informer[T]{
+		client:   c,
+		kind:     kind,
+		project:  project,
+		list:     list,
+		meta:     meta,
+		items:    make(map[string]T),
+		indexers: make(map[string]IndexFunc[T]),
+		indices:  make(map[string]map[string]map[string]bool),
+		synced:   make(chan struct{}),
+	}
+}
+
+// NewProjectInformer creates an informer for all projects.
+func NewProjectInformer(c Interface) *Informer[v1alpha1.Project] {
+	return NewInformer(c, v1alpha1.KindProject, "",
+		func(string) ([]v1alpha1.Project, error) { return c.ListProjects() },
+		func(p *v1alpha1.Project) *v1alpha1.ObjectMeta { return &p.Metadata })
+}
+
+// NewAgentPodInformer creates an informer for the agent pods of project.
+func NewAgentPodInformer(c Interface, project string) *Informer[v1alpha1.AgentPod] {
+	return NewInformer(c, v1alpha1.KindAgentPod, project,
+		func(project string) ([]v1alpha1.AgentPod, error) { return c.ListAgentPods(project) },
+		func(p *v1alpha1.AgentPod) *v1alpha1.ObjectMeta { return &p.Metadata })
+}
+
+// NewAgentPoolInformer creates an informer for the agent pools of project.
+func NewAgentPoolInformer(c Interface, project string) *Informer[v1alpha1.AgentPool] {
+	return NewInformer(c, v1alpha1.KindAgentPool, project,
+		func(project string) ([]v1alpha1.AgentPool, error) { return c.ListAgentPools(project) },
+		func(p *v1alpha1.AgentPool) *v1alpha1.ObjectMeta { return &p.Metadata })
+}
+
+// NewDevTaskInformer creates an informer for the tasks of project.
+func NewDevTaskInformer(c Interface, project string) *Informer[v1alpha1.DevTask] {
+	return NewInformer(c, v1alpha1.KindDevTask, project,
+		func(project string) ([]v1alpha1.DevTask, error) { return c.ListDevTasks(project) },
+		func(t *v1alpha1.DevTask) *v1alpha1.ObjectMeta { return &t.Metadata })
+}
+
+// AddEventHandler registers h. Objects already in the cache are passed to
+// its OnAdd first. Handlers must not call AddEventHandler.
+func (inf *Informer[T]) AddEventHandler(h EventHandler[T]) {
+	inf.dispatchMu.Lock()
+	defer inf.dispatchMu.Unlock()
+	inf.handlers = append(inf.handlers, h)
+	if h.OnAdd != nil {
+		for _, obj := range inf.List() {
+			h.OnAdd(obj)
+		}
+	}
+}
+
+// AddIndexer indexes the cache by fn under name, for ByIndex.
+func (inf *Informer[T]) AddIndexer(name string, fn IndexFunc[T]) {
+	inf.mu.Lock()
+	defer inf.mu.Unlock()
+	inf.indexers[name] = fn
+	inf.indices[name] = make(map[string]map[string]bool)
+	for key, obj := range inf.items {
+		inf.index(name, key, obj)
+	}
+}
+
+// Run fills the cache and keeps it up to date until ctx is cancelled, then
+// returns nil. It gives up on errors retrying cannot fix, such as a list the
+// server rejects as unauthorized, and returns them.
+func (inf *Informer[T]) Run(ctx context.Context) error {
+	backoff := time.Second
+	for {
+		err := inf.relist()
+		if err == nil {
+			wctx, cancel := context.WithTimeout(ctx, informerResyncPeriod)
+			err = inf.client.Watch(wctx, inf.kind, inf.project, func(ev v1alpha1.WatchEvent) {
+				backoff = time.Second
+				inf.apply(ev)
+			})
+			resync := wctx.Err() != nil
+			cancel()
+			if ctx.Err() != nil {
+				return nil
+			}
+			if IsNotFound(err) {
+				return inf.poll(ctx)
+			}
+			if resync {
+				continue
+			}
+		} else if permanent(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, 30*time.Second)
+	}
+}
+
+// permanent reports whether err is an API error that retrying the same
+// request cannot fix.
+func permanent(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 &&
+		apiErr.StatusCode != 408 && apiErr.StatusCode != 429
+}
+
+// poll lists the resources every informerPollInterval until ctx is
+// cancelled.
+func (inf *Informer[T]) poll(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(informerPollInterval):
+		}
+		if err := inf.relist(); err != nil && permanent(err) {
+			return err
+		}
+	}
+}
+
+// HasSynced reports whether the cache has been filled by a first list.
+func (inf *Informer[T]) HasSynced() bool {
+	select {
+	case <-inf.synced:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitForSync blocks until the cache has been filled by a first list. It
+// returns false if ctx is cancelled first.
+func (inf *Informer[T]) WaitForSync(ctx context.Context) bool {
+	select {
+	case <-inf.synced:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// List returns the cached objects, ordered by project and name.
+func (inf *Informer[T]) List() []T {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	keys := make([]string, 0, len(inf.items))
+	for key := range inf.items {
+		keys = append(keys, key)
+	}
+	return inf.objects(keys)
+}
+
+// Get returns the cached object named name in project; use an empty project
+// for projects themselves.
+func (inf *Informer[T]) Get(project, name string) (T, bool) {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	obj, ok := inf.items[project+"/"+name]
+	return obj, ok
+}
+
+// ByIndex returns the cached objects indexed under value by the indexer
+// name, ordered by project and name.
+func (inf *Informer[T]) ByIndex(name, value string) []T {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	keys := make([]string, 0, len(inf.indices[name][value]))
+	for key := range inf.indices[name][value] {
+		keys = append(keys, key)
+	}
+	return inf.objects(keys)
+}
+
+// objects returns the cached objects of keys, sorted. inf.mu must be held.
+func (inf *Informer[T]) objects(keys []string) []T {
+	sort.Strings(keys)
+	out := make([]T, len(keys))
+	for i, key := range keys {
+		out[i] = inf.items[key]
+	}
+	return out
+}
+
+// relist replaces the cache with a fresh list and dispatches the
+// differences.
+func (inf *Informer[T]) relist() error {
+	objs, err := inf.list(inf.project)
+	if err != nil {
+		return err
+	}
+
+	inf.dispatchMu.Lock()
+	defer inf.dispatchMu.Unlock()
+
+	seen := make(map[string]bool, len(objs))
+	for _, obj := range objs {
+		key := inf.key(&obj)
+		seen[key] = true
+		inf.upsert(key, obj)
+	}
+	inf.mu.RLock()
+	var gone []string
+	for key := range inf.items {
+		if !seen[key] {
+			gone = append(gone, key)
+		}
+	}
+	inf.mu.RUnlock()
+	for _, key := range gone {
+		inf.remove(key)
+	}
+
+	inf.syncOnce.Do(func() { close(inf.synced) })
+	return nil
+}
+
+// apply updates the cache with a watch event and dispatches it.
+func (inf *Informer[T]) apply(ev v1alpha1.WatchEvent) {
+	raw, ok := ev.Object.(json.RawMessage)
+	if !ok {
+		return
+	}
+	var obj T
+	if json.Unmarshal(raw, &obj) != nil {
+		return
+	}
+	key := inf.key(&obj)
+
+	inf.dispatchMu.Lock()
+	defer inf.dispatchMu.Unlock()
+	if ev.Type == v1alpha1.EventDeleted {
+		inf.remove(key)
+		return
+	}
+	inf.upsert(key, obj)
+}
+
+// upsert stores obj under key and dispatches it as an addition or, if it
+// changed, an update. inf.dispatchMu must be held.
+func (inf *Informer[T]) upsert(key string, obj T) {
+	inf.mu.Lock()
+	old, existed := inf.items[key]
+	if existed && reflect.DeepEqual(old, obj) {
+		inf.mu.Unlock()
+		return
+	}
+	if existed {
+		inf.unindex(key, old)
+	}
+	inf.items[key] = obj
+	for name := range inf.indexers {
+		inf.index(name, key, obj)
+	}
+	inf.mu.Unlock()
+
+	for _, h := range inf.handlers {
+		switch {
+		case existed && h.OnUpdate != nil:
+			h.OnUpdate(old, obj)
+		case !existed && h.OnAdd != nil:
+			h.OnAdd(obj)
+		}
+	}
+}
+
+// remove drops the object of key and dispatches its deletion.
+// inf.dispatchMu must be held.
+func (inf *Informer[T]) remove(key string) {
+	inf.mu.Lock()
+	old, existed := inf.items[key]
+	if !existed {
+		inf.mu.Unlock()
+		return
+	}
+	inf.unindex(key, old)
+	delete(inf.items, key)
+	inf.mu.Unlock()
+
+	for _, h := range inf.handlers {
+		if h.OnDelete != nil {
+			h.OnDelete(old)
+		}
+	}
+}
+
+// index adds the object of key to the index name. inf.mu must be held.
+func (inf *Informer[T]) index(name, key string, obj T) {
+	for _, value := range inf.indexers[name](obj) {
+		keys := inf.indices[name][value]
+		if keys == nil {
+			keys = make(map[string]bool)
+			inf.indices[name][value] = keys
+		}
+		keys[key] = true
+	}
+}
+
+// unindex removes the object of key from every index. inf.mu must be held.
+func (inf *Informer[T]) unindex(key string, obj T) {
+	for name, fn := range inf.indexers {
+		for _, value := range fn(obj) {
+			delete(inf.indices[name][value], key)
+			if len(inf.indices[name][value]) == 0 {
+				delete(inf.indices[name], value)
+			}
+		}
+	}
+}
+
+// key identifies obj in the cache as project/name.
+func (inf *Informer[T]) key(obj *T) string {
+	m := inf.meta(obj)
+	return m.Project + "/" + m.Name
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func testTask(name, pod string) v1alpha1.DevTask {
+	var t v1alpha1.DevTask
+	t.Kind = v1alpha1.KindDevTask
+	t.Metadata.Name = name
+	t.Metadata.Project = "default"
+	t.Status.AssignedPod = pod
+	return t
+}
+
+// informerServer serves a fixed task list and streams the events sent on
+// events to the first watch.
+func informerServer(t *testing.T, tasks []v1alpha1.DevTask, events <-chan v1alpha1.WatchEvent) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1alpha1/devtasks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(tasks)
+	})
+	mux.HandleFunc("/api/v1alpha1/watch", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("kind"); got != v1alpha1.KindDevTask {
+			t.Errorf("watch kind = %q, want %q", got, v1alpha1.KindDevTask)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case ev := <-events:
+				data, _ := json.Marshal(ev)
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				w.(http.Flusher).Flush()
+			}
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestInformer(t *testing.T) {
+	events := make(chan v1alpha1.WatchEvent)
+	srv := informerServer(t, []v1alpha1.DevTask{testTask("a", "pod-1"), testTask("b", "pod-1")}, events)
+
+	inf := NewDevTaskInformer(New(srv.URL), "default")
+	inf.AddIndexer("pod", func(t v1alpha1.DevTask) []string { return []string{t.Status.AssignedPod} })
+
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+	record := func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		log = append(log, fmt.Sprintf(format, args...))
+	}
+	inf.AddEventHandler(EventHandler[v1alpha1.DevTask]{
+		OnAdd:    func(t v1alpha1.DevTask) { record("add %s", t.Metadata.Name) },
+		OnUpdate: func(_, t v1alpha1.DevTask) { record("update %s", t.Metadata.Name) },
+		OnDelete: func(t v1alpha1.DevTask) { record("delete %s", t.Metadata.Name) },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- inf.Run(ctx) }()
+
+	if !inf.WaitForSync(ctx) {
+		t.Fatal("informer did not sync")
+	}
+	if got := len(inf.ByIndex("pod", "pod-1")); got != 2 {
+		t.Errorf("ByIndex(pod-1) = %d tasks, want 2", got)
+	}
+
+	send := func(typ v1alpha1.EventType, task v1alpha1.DevTask) {
+		raw, _ := json.Marshal(task)
+		events <- v1alpha1.WatchEvent{Type: typ, Kind: v1alpha1.KindDevTask, Key: "/DevTask/default/" + task.Metadata.Name, Object: json.RawMessage(raw)}
+	}
+	send(v1alpha1.EventModified, testTask("a", "pod-2"))
+	send(v1alpha1.EventDeleted, testTask("b", "pod-1"))
+	send(v1alpha1.EventAdded, testTask("c", "pod-2"))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := inf.Get("default", "c"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("added task never reached the cache")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, ok := inf.Get("default", "b"); ok {
+		t.Error("deleted task b still cached")
+	}
+	if got := len(inf.ByIndex("pod", "pod-1")); got != 0 {
+		t.Errorf("ByIndex(pod-1) = %d tasks, want 0", got)
+	}
+	byPod := inf.ByIndex("pod", "pod-2")
+	if len(byPod) != 2 || byPod[0].Metadata.Name != "a" || byPod[1].Metadata.Name != "c" {
+		t.Errorf("ByIndex(pod-2) = %v, want tasks a and c", byPod)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run returned %v, want nil", err)
+	}
+
+	want := []string{"add a", "add b", "update a", "delete b", "add c"}
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(log) != fmt.Sprint(want) {
+		t.Errorf("handler calls = %v, want %v", log, want)
+	}
+}
+
+func TestInformerUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"missing token"}`)
+	}))
+	defer srv.Close()
+
+	err := NewDevTaskInformer(New(srv.URL), "default").Run(context.Background())
+	if !IsUnauthorized(err) {
+		t.Errorf("Run returned %v, want an unauthorized error", err)
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// Interface is the Orca API as the client exposes it. *Client implements it
+// against a live server; the fake package implements it in memory, for
+// tests. Code that takes an Interface instead of a *Client can be tested
+// without a server.
+type Interface interface {
+	Healthz() error
+	Readyz() error
+	ServerVersion() (*ServerVersion, error)
+	Discover() (*Discovery, error)
+
+	CreateProject(p *v1alpha1.Project) (*v1alpha1.Project, error)
+	GetProject(name string) (*v1alpha1.Project, error)
+	ListProjects(opts ...ListOption) ([]v1alpha1.Project, error)
+	AllProjects(opts ...ListOption) iter.Seq2[v1alpha1.Project, error]
+	UpdateProject(p *v1alpha1.Project) (*v1alpha1.Project, error)
+	DeleteProject(name string, opts ...ListOption) error
+
+	CreateAgentPod(pod *v1alpha1.AgentPod) (*v1alpha1.AgentPod, error)
+	GetAgentPod(name, project string) (*v1alpha1.AgentPod, error)
+	ListAgentPods(project string, opts ...ListOption) ([]v1alpha1.AgentPod, error)
+	AllAgentPods(project string, opts ...ListOption) iter.Seq2[v1alpha1.AgentPod, error]
+	UpdateAgentPod(pod *v1alpha1.AgentPod) (*v1alpha1.AgentPod, error)
+	DeleteAgentPod(name, project string) error
+	GetLogs(podName, project string, opts LogOptions) ([]v1alpha1.LogEntry, error)
+	FollowLogs(ctx context.Context, podName, project string, opts LogOptions, fn func(v1alpha1.LogEntry)) error
+	StreamLogEntries(ctx context.Context, podName, project string, opts LogOptions) (<-chan v1alpha1.LogEntry, error)
+	StreamLogs(podName, project string, opts LogOptions) (io.ReadCloser, error)
+
+	CreateAgentPool(pool *v1alpha1.AgentPool) (*v1alpha1.AgentPool, error)
+	GetAgentPool(name, project string) (*v1alpha1.AgentPool, error)
+	ListAgentPools(project string, opts ...ListOption) ([]v1alpha1.AgentPool, error)
+	AllAgentPools(project string, opts ...ListOption) iter.Seq2[v1alpha1.AgentPool, error]
+	UpdateAgentPool(pool *v1alpha1.AgentPool) (*v1alpha1.AgentPool, error)
+	DeleteAgentPool(name, project string) error
+	ScaleAgentPool(name, project string, replicas int) (*v1alpha1.AgentPool, error)
+	ScaleAgentPoolFrom(name, project string, current, replicas int) (*v1alpha1.AgentPool, error)
+
+	CreateAutoscaler(as *v1alpha1.AgentPoolAutoscaler) (*v1alpha1.AgentPoolAutoscaler, error)
+	GetAutoscaler(name, project string) (*v1alpha1.AgentPoolAutoscaler, error)
+	ListAutoscalers(project string, opts ...ListOption) ([]v1alpha1.AgentPoolAutoscaler, error)
+	AllAutoscalers(project string, opts ...ListOption) iter.Seq2[v1alpha1.AgentPoolAutoscaler, error]
+	UpdateAutoscaler(as *v1alpha1.AgentPoolAutoscaler) (*v1alpha1.AgentPoolAutoscaler, error)
+	DeleteAutoscaler(name, project string) error
+
+	CreateSecret(sec *v1alpha1.Secret) (*v1alpha1.Secret, error)
+	GetSecret(name, project string) (*v1alpha1.Secret, error)
+	ListSecrets(project string, opts ...ListOption) ([]v1alpha1.Secret, error)
+	AllSecrets(project string, opts ...ListOption) iter.Seq2[v1alpha1.Secret, error]
+	UpdateSecret(sec *v1alpha1.Secret) (*v1alpha1.Secret, error)
+	DeleteSecret(name, project string) error
+
+	CreateConfigMap(cm *v1alpha1.ConfigMap) (*v1alpha1.ConfigMap, error)
+	GetConfigMap(name, project string) (*v1alpha1.ConfigMap, error)
+	ListConfigMaps(project string, opts ...ListOption) ([]v1alpha1.ConfigMap, error)
+	AllConfigMaps(project string, opts ...ListOption) iter.Seq2[v1alpha1.ConfigMap, error]
+	UpdateConfigMap(cm *v1alpha1.ConfigMap) (*v1alpha1.ConfigMap, error)
+	DeleteConfigMap(name, project string) error
+
+	CreateModelProvider(mp *v1alpha1.ModelProvider) (*v1alpha1.ModelProvider, error)
+	GetModelProvider(name, project string) (*v1alpha1.ModelProvider, error)
+	ListModelProviders(project string, opts ...ListOption) ([]v1alpha1.ModelProvider, error)
+	AllModelProviders(project string, opts ...ListOption) iter.Seq2[v1alpha1.ModelProvider, error]
+	UpdateModelProvider(mp *v1alpha1.ModelProvider) (*v1alpha1.ModelProvider, error)
+	DeleteModelProvider(name, project string) error
+
+	CreateToolDefinition(td *v1alpha1.ToolDefinition) (*v1alpha1.ToolDefinition, error)
+	GetToolDefinition(name, project string) (*v1alpha1.ToolDefinition, error)
+	ListToolDefinitions(project string, opts ...ListOption) ([]v1alpha1.ToolDefinition, error)
+	AllToolDefinitions(project string, opts ...ListOption) iter.Seq2[v1alpha1.ToolDefinition, error]
+	UpdateToolDefinition(td *v1alpha1.ToolDefinition) (*v1alpha1.ToolDefinition, error)
+	DeleteToolDefinition(name, project string) error
+
+	CreateAgentNode(n *v1alpha1.AgentNode) (*v1alpha1.AgentNode, error)
+	GetAgentNode(name string) (*v1alpha1.AgentNode, error)
+	ListAgentNodes(opts ...ListOption) ([]v1alpha1.AgentNode, error)
+	AllAgentNodes(opts ...ListOption) iter.Seq2[v1alpha1.AgentNode, error]
+	UpdateAgentNode(n *v1alpha1.AgentNode) (*v1alpha1.AgentNode, error)
+	DeleteAgentNode(name string) error
+
+	CreateDevTask(task *v1alpha1.DevTask) (*v1alpha1.DevTask, error)
+	GetDevTask(name, project string) (*v1alpha1.DevTask, error)
+	ListDevTasks(project string, opts ...ListOption) ([]v1alpha1.DevTask, error)
+	AllDevTasks(project string, opts ...ListOption) iter.Seq2[v1alpha1.DevTask, error]
+	UpdateDevTask(task *v1alpha1.DevTask) (*v1alpha1.DevTask, error)
+	DeleteDevTask(name, project string) error
+	DeleteDevTasks(project string, opts ...ListOption) ([]string, error)
+	RetryDevTask(name, project string) (*v1alpha1.DevTask, error)
+	CancelDevTask(name, project string) (*v1alpha1.DevTask, error)
+	FollowTask(ctx context.Context, name, project string, fn func(v1alpha1.TaskStreamEvent)) error
+	ListArtifacts(taskName, project string) ([]v1alpha1.Artifact, error)
+	DownloadArtifact(taskName, project, name string, w io.Writer) error
+
+	ApplyInto(resource, out interface{}, dryRun bool) (created bool, err error)
+	Get(kind, name, project string, out interface{}) error
+	DeleteCollection(kind, project string, opts ...ListOption) (deleted, pending []string, err error)
+	Patch(kind, name, project string, pt PatchType, patch []byte, out interface{}) error
+	GetSubresource(kind, name, project, subresource string, out interface{}) error
+	UpdateSubresource(kind, name, project, subresource string, body, out interface{}) error
+	GetStatus(kind, name, project string, out interface{}) error
+	UpdateStatus(kind, name, project string, status, out interface{}) error
+	Scale(kind, name, project string, replicas int, out interface{}) error
+
+	ListEvents(project, kind, name string) ([]v1alpha1.Event, error)
+	ListAuditEntries(opts AuditOptions) ([]v1alpha1.AuditEntry, error)
+	Watch(ctx context.Context, kind, project string, fn func(v1alpha1.WatchEvent)) error
+
+	Backup(w io.Writer, resourcesOnly bool) error
+	Restore(r io.Reader) (int, error)
+}
+
+var _ Interface = (*Client)(nil)
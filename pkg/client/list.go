@@ -0,0 +1,174 @@
+package client
+
+import (
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// defaultPageSize is the page size of the All* iterators when no WithLimit
+// option is given.
+const defaultPageSize = 100
+
+// ListOptions gathers the common list parameters in one value; pass it to a
+// List call with WithListOptions. Zero fields are left out.
+type ListOptions struct {
+	// LabelSelector selects objects by label, e.g. "batch=nightly,team!=web".
+	LabelSelector string
+	// FieldSelector selects objects by field, addressed by its dotted JSON
+	// path, e.g. "status.phase=Running,spec.pool!=default".
+	FieldSelector string
+	// Limit caps the number of objects returned; Continue, taken from the
+	// previous page, fetches the next one.
+	Limit    int
+	Continue string
+}
+
+// WithListOptions applies every set field of o.
+func WithListOptions(o ListOptions) ListOption {
+	return func(q url.Values) {
+		for _, opt := range []ListOption{
+			WithLabelSelector(o.LabelSelector),
+			WithFieldSelector(o.FieldSelector),
+			WithLimit(o.Limit),
+			WithContinue(o.Continue),
+		} {
+			opt(q)
+		}
+	}
+}
+
+// WithFieldSelector limits a List call to the objects whose fields match
+// selector. Fields are addressed by their dotted JSON path and use the label
+// selector syntax, e.g. "status.phase=Running,metadata.name!=scratch".
+func WithFieldSelector(selector string) ListOption {
+	return func(q url.Values) {
+		if selector != "" {
+			q.Set("fieldSelector", selector)
+		}
+	}
+}
+
+// WithLimit makes a List call return at most n objects, ordered by project
+// and name. ListPage returns the token for the rest.
+func WithLimit(n int) ListOption {
+	return func(q url.Values) {
+		if n > 0 {
+			q.Set("limit", strconv.Itoa(n))
+		}
+	}
+}
+
+// WithContinue makes a List call resume after the page that returned token.
+func WithContinue(token string) ListOption {
+	return func(q url.Values) {
+		if token != "" {
+			q.Set("continue", token)
+		}
+	}
+}
+
+// ListPage lists one page of resource (the path segment, e.g. "devtasks")
+// in project and returns it with the continue token for the next page,
+// which is empty after the last one. Page size and position come from
+// WithLimit and WithContinue.
+func ListPage[T any](c *Client, resource, project string, opts ...ListOption) ([]T, string, error) {
+	var out []T
+	resp, err := c.doJSONResponse(http.MethodGet, listPath(resource, project, opts), nil, &out)
+	if err != nil {
+		return nil, "", err
+	}
+	return out, resp.Header.Get("X-Continue"), nil
+}
+
+// listAll iterates over every object of resource in project, listing it a
+// page at a time. Iteration stops after the first error.
+func listAll[T any](c *Client, resource, project string, opts []ListOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		token := ""
+		for {
+			page := make([]ListOption, 0, len(opts)+2)
+			page = append(page, WithLimit(defaultPageSize))
+			page = append(page, opts...)
+			page = append(page, WithContinue(token))
+
+			items, next, err := ListPage[T](c, resource, project, page...)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+			token = next
+		}
+	}
+}
+
+// AllProjects iterates over all projects, listing them a page at a time.
+func (c *Client) AllProjects(opts ...ListOption) iter.Seq2[v1alpha1.Project, error] {
+	return listAll[v1alpha1.Project](c, "projects", "", opts)
+}
+
+// AllAgentNodes iterates over all agent nodes, listing them a page at a
+// time.
+func (c *Client) AllAgentNodes(opts ...ListOption) iter.Seq2[v1alpha1.AgentNode, error] {
+	return listAll[v1alpha1.AgentNode](c, "agentnodes", "", opts)
+}
+
+// AllAgentPods iterates over the agent pods of project, or of all projects
+// when empty, listing them a page at a time.
+func (c *Client) AllAgentPods(project string, opts ...ListOption) iter.Seq2[v1alpha1.AgentPod, error] {
+	return listAll[v1alpha1.AgentPod](c, "agentpods", project, opts)
+}
+
+// AllAgentPools iterates over the agent pools of project, or of all
+// projects when empty, listing them a page at a time.
+func (c *Client) AllAgentPools(project string, opts ...ListOption) iter.Seq2[v1alpha1.AgentPool, error] {
+	return listAll[v1alpha1.AgentPool](c, "agentpools", project, opts)
+}
+
+// AllAutoscalers iterates over the autoscalers of project, or of all
+// projects when empty, listing them a page at a time.
+func (c *Client) AllAutoscalers(project string, opts ...ListOption) iter.Seq2[v1alpha1.AgentPoolAutoscaler, error] {
+	return listAll[v1alpha1.AgentPoolAutoscaler](c, "autoscalers", project, opts)
+}
+
+// AllSecrets iterates over the secrets of project, or of all projects when
+// empty, listing them a page at a time.
+func (c *Client) AllSecrets(project string, opts ...ListOption) iter.Seq2[v1alpha1.Secret, error] {
+	return listAll[v1alpha1.Secret](c, "secrets", project, opts)
+}
+
+// AllConfigMaps iterates over the config maps of project, or of all
+// projects when empty, listing them a page at a time.
+func (c *Client) AllConfigMaps(project string, opts ...ListOption) iter.Seq2[v1alpha1.ConfigMap, error] {
+	return listAll[v1alpha1.ConfigMap](c, "configmaps", project, opts)
+}
+
+// AllModelProviders iterates over the model providers of project, or of all
+// projects when empty, listing them a page at a time.
+func (c *Client) AllModelProviders(project string, opts ...ListOption) iter.Seq2[v1alpha1.ModelProvider, error] {
+	return listAll[v1alpha1.ModelProvider](c, "modelproviders", project, opts)
+}
+
+// AllToolDefinitions iterates over the tool definitions of project, or of
+// all projects when empty, listing them a page at a time.
+func (c *Client) AllToolDefinitions(project string, opts ...ListOption) iter.Seq2[v1alpha1.ToolDefinition, error] {
+	return listAll[v1alpha1.ToolDefinition](c, "tooldefinitions", project, opts)
+}
+
+// AllDevTasks iterates over the tasks of project, or of all projects when
+// empty, listing them a page at a time.
+func (c *Client) AllDevTasks(project string, opts ...ListOption) iter.Seq2[v1alpha1.DevTask, error] {
+	return listAll[v1alpha1.DevTask](c, "devtasks", project, opts)
+}
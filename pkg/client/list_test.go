@@ -0,0 +1,67 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	v1alpha1 "github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestAllDevTasks(t *testing.T) {
+	var tasks []v1alpha1.DevTask
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		tasks = append(tasks, testTask(name, ""))
+	}
+
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		queries = append(queries, q.Encode())
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		start, _ := strconv.Atoi(q.Get("continue"))
+		end := min(start+limit, len(tasks))
+		if end < len(tasks) {
+			w.Header().Set("X-Continue", strconv.Itoa(end))
+		}
+		_ = json.NewEncoder(w).Encode(tasks[start:end])
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	var names []string
+	for task, err := range c.AllDevTasks("default", WithListOptions(ListOptions{FieldSelector: "status.phase=Pending", Limit: 2})) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, task.Metadata.Name)
+	}
+	if got := len(names); got != len(tasks) {
+		t.Fatalf("iterated over %v, want all %d tasks", names, len(tasks))
+	}
+
+	want := []string{
+		"fieldSelector=status.phase%3DPending&limit=2&project=default",
+		"continue=2&fieldSelector=status.phase%3DPending&limit=2&project=default",
+		"continue=4&fieldSelector=status.phase%3DPending&limit=2&project=default",
+	}
+	if len(queries) != len(want) {
+		t.Fatalf("queries = %q, want %q", queries, want)
+	}
+	for i := range want {
+		if queries[i] != want[i] {
+			t.Errorf("query %d = %q, want %q", i, queries[i], want[i])
+		}
+	}
+
+	// Stopping early fetches no further pages.
+	queries = nil
+	for range c.AllDevTasks("default", WithLimit(2)) {
+		break
+	}
+	if len(queries) != 1 {
+		t.Errorf("breaking out fetched %d pages, want 1", len(queries))
+	}
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures a Client created with New.
+type Option func(*Client)
+
+// WithToken makes the client send token as a bearer token on every request.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithTLSConfig makes the client use cfg for https servers, e.g. to trust a
+// private certificate authority. Client certificates added with
+// WithClientCert are kept.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		var certs []tls.Certificate
+		if c.tlsConfig != nil {
+			certs = c.tlsConfig.Certificates
+		}
+		c.tlsConfig = cfg.Clone()
+		c.tlsConfig.Certificates = append(c.tlsConfig.Certificates, certs...)
+	}
+}
+
+// WithClientCert makes the client present cert to servers that ask for a
+// client certificate. Load it with tls.LoadX509KeyPair.
+func WithClientCert(cert tls.Certificate) Option {
+	return func(c *Client) {
+		if c.tlsConfig == nil {
+			c.tlsConfig = &tls.Config{}
+		}
+		c.tlsConfig.Certificates = append(c.tlsConfig.Certificates, cert)
+	}
+}
+
+// WithTimeout bounds each request, including reading the response, to d;
+// zero means no limit, e.g. for large Apply calls. Streams such as Watch are
+// not bounded. The default is 30s.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = d
+	}
+}
+
+// WithRetryPolicy replaces the default retry policy, DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// WithUserAgent replaces the User-Agent header, DefaultUserAgent, e.g. with
+// a tool's own name and version. An empty agent sends Go's default.
+func WithUserAgent(agent string) Option {
+	return func(c *Client) {
+		c.userAgent = agent
+	}
+}
+
+// WithProxy routes requests through the proxy that proxy returns for them,
+// e.g. http.ProxyURL(u). By default the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables are followed.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *Client) {
+		c.proxy = proxy
+	}
+}
+
+// WithMaxIdleConnsPerHost sets how many idle keep-alive connections to the
+// server the client keeps for reuse. The default is 16.
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(c *Client) {
+		c.maxIdlePerHost = n
+	}
+}
+
+// WithTransport sends requests through rt instead of the client's own
+// pooling transport, e.g. to record or stub them in tests or to add
+// middleware. The TLS, proxy and connection pool options are then up to rt
+// and are ignored.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptionsTLS(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]interface{}{})
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // the rejected handshake below
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	// The test server's own certificate serves as the client certificate.
+	clientCert := srv.TLS.Certificates[0]
+
+	// Trust the server's CA, present a client certificate and a token.
+	c := New(srv.URL,
+		WithClientCert(clientCert),
+		WithTLSConfig(&tls.Config{RootCAs: roots}),
+		WithToken("secret"),
+		WithTimeout(5*time.Second),
+	)
+	if _, err := c.ListProjects(); err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer secret")
+	}
+
+	// Without the CA the server's certificate is rejected.
+	c = New(srv.URL, WithRetryPolicy(RetryPolicy{}))
+	if _, err := c.ListProjects(); err == nil {
+		t.Error("expected an unknown-authority error without WithTLSConfig")
+	}
+}
+
+// roundTripFunc is an http.RoundTripper that calls itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestOptionsTransport(t *testing.T) {
+	var got *http.Request
+	stub := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		got = req
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("[]")),
+		}, nil
+	})
+
+	c := New("http://orca.invalid", WithTransport(stub))
+	if _, err := c.ListProjects(); err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	if ua := got.Header.Get("User-Agent"); !strings.HasPrefix(ua, "orca/") {
+		t.Errorf("User-Agent = %q, want orca/<version>", ua)
+	}
+
+	c = New("http://orca.invalid", WithTransport(stub), WithUserAgent("nightly-bot/1.0"))
+	if _, err := c.ListProjects(); err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	if ua := got.Header.Get("User-Agent"); ua != "nightly-bot/1.0" {
+		t.Errorf("User-Agent = %q, want nightly-bot/1.0", ua)
+	}
+}
+
+func TestOptionsProxy(t *testing.T) {
+	var gotURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxied request carries the absolute target URL.
+		gotURL = r.URL.String()
+		_ = json.NewEncoder(w).Encode([]interface{}{})
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	c := New("http://orca.invalid:7117", WithProxy(http.ProxyURL(proxyURL)), WithMaxIdleConnsPerHost(4))
+	if _, err := c.ListProjects(); err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	if want := "http://orca.invalid:7117/api/v1alpha1/projects"; gotURL != want {
+		t.Errorf("proxied URL = %q, want %q", gotURL, want)
+	}
+	if n := c.httpClient.Transport.(*http.Transport).MaxIdleConnsPerHost; n != 4 {
+		t.Errorf("MaxIdleConnsPerHost = %d, want 4", n)
+	}
+}
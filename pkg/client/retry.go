@@ -0,0 +1,80 @@
+package client
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how the client retries requests that fail
+// transiently. Idempotent requests (GET, HEAD, PUT, DELETE) are retried
+// after a connection error or a 5xx or 429 Too Many Requests response, and
+// all others after a 429 only, which the server sends without acting on the
+// request; everything else fails right away.
+type RetryPolicy struct {
+	// MaxRetries is how many times a request is retried after its first
+	// attempt. Zero disables retries.
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry. It doubles with
+	// every retry, up to MaxBackoff, and is jittered.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of new clients: three retries,
+// starting 200ms apart.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+// idempotent reports whether a request with method can safely be sent
+// twice.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryable reports whether a request with method that ended with err, or
+// else with status, is worth retrying.
+func retryable(method string, err error, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	if !idempotent(method) {
+		return false
+	}
+	if err != nil {
+		// Connection errors are url.Errors; a timeout already waited long
+		// enough.
+		var urlErr *url.Error
+		return errors.As(err, &urlErr) && !urlErr.Timeout()
+	}
+	return status >= 500
+}
+
+// backoff returns the wait before retry number attempt (from 0). A
+// Retry-After header in seconds, as sent with 429 and 503 responses, is
+// honored up to MaxBackoff.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			return min(time.Duration(secs)*time.Second, p.MaxBackoff)
+		}
+	}
+	d := p.InitialBackoff << attempt
+	if d <= 0 || d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	// Wait between half and all of d, so clients don't retry in lockstep.
+	return d/2 + rand.N(d/2+1)
+}
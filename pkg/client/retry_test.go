@@ -0,0 +1,79 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int // the responses, in order; the last one repeats
+		wantErr  bool
+		wantHits int32
+	}{
+		{"get recovers from 503", http.MethodGet, []int{503, 503, 200}, false, 3},
+		{"get gives up", http.MethodGet, []int{500}, true, 3},
+		{"get retries 429", http.MethodGet, []int{429, 200}, false, 2},
+		{"put recovers", http.MethodPut, []int{502, 200}, false, 2},
+		{"post is not retried", http.MethodPost, []int{503, 200}, true, 1},
+		{"post retries 429", http.MethodPost, []int{429, 201}, false, 2},
+		{"404 is not retried", http.MethodGet, []int{404, 200}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(hits.Add(1)) - 1
+				status := tt.statuses[min(n, len(tt.statuses)-1)]
+				w.WriteHeader(status)
+				fmt.Fprintf(w, `{"error":"status %d"}`, status)
+			}))
+			defer srv.Close()
+
+			c := New(srv.URL, WithRetryPolicy(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}))
+			err := c.doJSON(tt.method, "/x", nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("server hit %d times, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestRetryConnectionError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	addr := srv.URL
+	srv.Close()
+
+	c := New(addr, WithRetryPolicy(RetryPolicy{MaxRetries: 2, InitialBackoff: 20 * time.Millisecond, MaxBackoff: 20 * time.Millisecond}))
+	start := time.Now()
+	if _, err := c.ListProjects(); err == nil {
+		t.Fatal("expected an error from a closed server")
+	}
+	// Two retries wait at least half their 20ms backoff each.
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("gave up after %v; connection errors should be retried", elapsed)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, limit := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		if d := p.backoff(attempt, nil); d < limit/2 || d > limit {
+			t.Errorf("backoff(%d) = %v, want within [%v, %v]", attempt, d, limit/2, limit)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {"3"}}}
+	if d := p.backoff(0, resp); d != time.Second {
+		t.Errorf("backoff with Retry-After 3 = %v, want it capped at 1s", d)
+	}
+}
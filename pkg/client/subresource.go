@@ -0,0 +1,68 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// resourcePath returns the API path segment of kind, which may be a kind
+// such as "AgentPool" or already a path segment such as "agentpools".
+// Kinds the client does not know yet are pluralized, so new server
+// resources work without a client change.
+func resourcePath(kind string) string {
+	lower := strings.ToLower(kind)
+	if lower == "agentpoolautoscaler" || lower == "agentpoolautoscalers" {
+		return "autoscalers"
+	}
+	if !strings.HasSuffix(lower, "s") {
+		lower += "s"
+	}
+	return lower
+}
+
+// objectPath builds the path of the named object of kind or, when
+// subresource is set, of that subresource of the object. project is
+// ignored for projects and agent nodes.
+func objectPath(kind, name, project, subresource string) string {
+	resource := resourcePath(kind)
+	path := "/api/v1alpha1/" + resource + "/" + url.PathEscape(name)
+	if subresource != "" {
+		path += "/" + subresource
+	}
+	if resource != "projects" && resource != "agentnodes" {
+		path += "?project=" + url.QueryEscape(project)
+	}
+	return path
+}
+
+// GetSubresource reads a subresource of the named object, e.g. "status",
+// into out. kind is a kind such as "DevTask" or a path segment such as
+// "devtasks".
+func (c *Client) GetSubresource(kind, name, project, subresource string, out interface{}) error {
+	return c.doJSON(http.MethodGet, objectPath(kind, name, project, subresource), nil, out)
+}
+
+// UpdateSubresource replaces a subresource of the named object with body
+// and decodes the server's answer into out (when non-nil).
+func (c *Client) UpdateSubresource(kind, name, project, subresource string, body, out interface{}) error {
+	return c.doJSON(http.MethodPut, objectPath(kind, name, project, subresource), body, out)
+}
+
+// GetStatus reads the status of the named object into out, e.g. a
+// *v1alpha1.DevTaskStatus.
+func (c *Client) GetStatus(kind, name, project string, out interface{}) error {
+	return c.GetSubresource(kind, name, project, "status", out)
+}
+
+// UpdateStatus replaces the status of the named object, leaving its spec
+// alone, and decodes the stored status into out (when non-nil).
+func (c *Client) UpdateStatus(kind, name, project string, status, out interface{}) error {
+	return c.UpdateSubresource(kind, name, project, "status", status, out)
+}
+
+// Scale sets the replica count of the named object, e.g. an AgentPool, and
+// decodes the scaled object into out (when non-nil).
+func (c *Client) Scale(kind, name, project string, replicas int, out interface{}) error {
+	return c.UpdateSubresource(kind, name, project, "scale", map[string]int{"replicas": replicas}, out)
+}
@@ -0,0 +1,41 @@
+package labels
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Fields flattens the JSON form of obj into the set a field selector
+// matches against, keyed by dotted path, e.g. "metadata.name" or
+// "status.phase". Only strings, numbers and booleans are fields.
+func Fields(obj interface{}) map[string]string {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil
+	}
+	fields := make(map[string]string)
+	flatten(fields, "", doc)
+	return fields
+}
+
+func flatten(fields map[string]string, prefix string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flatten(fields, k, child)
+		}
+	case string:
+		fields[prefix] = v
+	case float64:
+		fields[prefix] = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		fields[prefix] = strconv.FormatBool(v)
+	}
+}
@@ -0,0 +1,119 @@
+// Package labels implements label selectors, which select resources by their
+// metadata labels.
+package labels
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operator is the comparison a Requirement makes.
+type Operator string
+
+const (
+	Equals       Operator = "="
+	NotEquals    Operator = "!="
+	Exists       Operator = "exists"
+	DoesNotExist Operator = "!"
+)
+
+// Requirement is a single condition on one label.
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Value    string
+}
+
+// Matches reports whether labels satisfy the requirement.
+func (r Requirement) Matches(labels map[string]string) bool {
+	v, ok := labels[r.Key]
+	switch r.Operator {
+	case Equals:
+		return ok && v == r.Value
+	case NotEquals:
+		return !ok || v != r.Value
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	}
+	return false
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case Exists:
+		return r.Key
+	case DoesNotExist:
+		return "!" + r.Key
+	}
+	return r.Key + string(r.Operator) + r.Value
+}
+
+// Selector is a set of requirements that must all hold. The empty selector
+// matches everything.
+type Selector []Requirement
+
+// Matches reports whether labels satisfy every requirement of s.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty reports whether s selects everything.
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+func (s Selector) String() string {
+	parts := make([]string, len(s))
+	for i, r := range s {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// Parse parses a comma-separated list of requirements, each one of
+// "key=value" (or "key==value"), "key!=value", "key" (the label is set) and
+// "!key" (the label is not set).
+func Parse(s string) (Selector, error) {
+	var sel Selector
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var r Requirement
+		switch {
+		case strings.Contains(part, "!="):
+			key, value, _ := strings.Cut(part, "!=")
+			r = Requirement{Key: key, Operator: NotEquals, Value: value}
+		case strings.Contains(part, "=="):
+			key, value, _ := strings.Cut(part, "==")
+			r = Requirement{Key: key, Operator: Equals, Value: value}
+		case strings.Contains(part, "="):
+			key, value, _ := strings.Cut(part, "=")
+			r = Requirement{Key: key, Operator: Equals, Value: value}
+		case strings.HasPrefix(part, "!"):
+			r = Requirement{Key: strings.TrimPrefix(part, "!"), Operator: DoesNotExist}
+		default:
+			r = Requirement{Key: part, Operator: Exists}
+		}
+
+		r.Key = strings.TrimSpace(r.Key)
+		r.Value = strings.TrimSpace(r.Value)
+		if r.Key == "" || strings.ContainsAny(r.Key, "=! ") {
+			return nil, fmt.Errorf("invalid label selector requirement %q", part)
+		}
+		if strings.ContainsAny(r.Value, "=! ") {
+			return nil, fmt.Errorf("invalid label selector requirement %q", part)
+		}
+		sel = append(sel, r)
+	}
+	return sel, nil
+}
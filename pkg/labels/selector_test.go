@@ -0,0 +1,54 @@
+package labels
+
+import "testing"
+
+func TestParseAndMatch(t *testing.T) {
+	labels := map[string]string{"batch": "nightly", "team": "web"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"batch=nightly", true},
+		{"batch==nightly", true},
+		{"batch=weekly", false},
+		{"batch!=weekly", true},
+		{"batch!=nightly", false},
+		{"missing!=x", true},
+		{"team", true},
+		{"missing", false},
+		{"!missing", true},
+		{"!team", false},
+		{"batch=nightly, team=web", true},
+		{"batch=nightly,team=api", false},
+	}
+	for _, tt := range tests {
+		sel, err := Parse(tt.selector)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.selector, err)
+			continue
+		}
+		if got := sel.Matches(labels); got != tt.want {
+			t.Errorf("%q matches = %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{"=x", "!=x", "!", "a=b=c", "a b=c"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q): expected an error", s)
+		}
+	}
+}
+
+func TestSelectorString(t *testing.T) {
+	sel, err := Parse("a=1,b!=2,c,!d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sel.String(); got != "a=1,b!=2,c,!d" {
+		t.Errorf("String() = %q", got)
+	}
+}
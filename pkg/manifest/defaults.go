@@ -0,0 +1,95 @@
+package manifest
+
+import "github.com/klubi/orca/pkg/apis/v1alpha1"
+
+// DefaultProject is the project of resources that don't name one.
+const DefaultProject = "default"
+
+// Defaults are the values ApplyDefaults fills into fields left empty.
+type Defaults struct {
+	Project        string
+	MaxConcurrency int
+	// MaxTokens is the server's agent.defaultMaxTokens. Zero leaves
+	// maxTokens unset, for the server to fill in.
+	MaxTokens     int
+	RestartPolicy string
+}
+
+// DefaultValues are the defaults ApplyDefaults uses. MaxTokens depends on
+// the server's configuration, so it is left to the server.
+var DefaultValues = Defaults{
+	Project:        DefaultProject,
+	MaxConcurrency: 1,
+	RestartPolicy:  "OnFailure",
+}
+
+// ApplyDefaults fills the fields of resource left empty with DefaultValues:
+// the apiVersion and kind, the project of project-scoped kinds and, for
+// AgentPods and the pod template of AgentPools, maxConcurrency and
+// restartPolicy. The parser, the API server and apply all default resources
+// this way, so a resource means the same wherever it is created from.
+func ApplyDefaults(resource interface{}) {
+	DefaultValues.Apply(resource)
+}
+
+// Apply fills the fields of resource left empty with d, as ApplyDefaults
+// does. Zero fields of d are not applied.
+func (d Defaults) Apply(resource interface{}) {
+	switch r := resource.(type) {
+	case *v1alpha1.Project:
+		d.typeMeta(&r.TypeMeta, v1alpha1.KindProject)
+	case *v1alpha1.AgentPod:
+		d.typeMeta(&r.TypeMeta, v1alpha1.KindAgentPod)
+		d.project(&r.Metadata)
+		d.podSpec(&r.Spec)
+	case *v1alpha1.AgentPool:
+		d.typeMeta(&r.TypeMeta, v1alpha1.KindAgentPool)
+		d.project(&r.Metadata)
+		d.podSpec(&r.Spec.Template.Spec)
+	case *v1alpha1.DevTask:
+		d.typeMeta(&r.TypeMeta, v1alpha1.KindDevTask)
+		d.project(&r.Metadata)
+	case *v1alpha1.Secret:
+		d.typeMeta(&r.TypeMeta, v1alpha1.KindSecret)
+		d.project(&r.Metadata)
+	case *v1alpha1.ConfigMap:
+		d.typeMeta(&r.TypeMeta, v1alpha1.KindConfigMap)
+		d.project(&r.Metadata)
+	case *v1alpha1.ModelProvider:
+		d.typeMeta(&r.TypeMeta, v1alpha1.KindModelProvider)
+		d.project(&r.Metadata)
+	case *v1alpha1.ToolDefinition:
+		d.typeMeta(&r.TypeMeta, v1alpha1.KindToolDefinition)
+		d.project(&r.Metadata)
+	case *v1alpha1.AgentPoolAutoscaler:
+		d.typeMeta(&r.TypeMeta, v1alpha1.KindAgentPoolAutoscaler)
+		d.project(&r.Metadata)
+	}
+}
+
+func (d Defaults) typeMeta(t *v1alpha1.TypeMeta, kind string) {
+	if t.APIVersion == "" {
+		t.APIVersion = v1alpha1.APIVersion
+	}
+	if t.Kind == "" {
+		t.Kind = kind
+	}
+}
+
+func (d Defaults) project(m *v1alpha1.ObjectMeta) {
+	if m.Project == "" {
+		m.Project = d.Project
+	}
+}
+
+func (d Defaults) podSpec(s *v1alpha1.AgentPodSpec) {
+	if s.MaxConcurrency == 0 {
+		s.MaxConcurrency = d.MaxConcurrency
+	}
+	if s.MaxTokens == 0 {
+		s.MaxTokens = d.MaxTokens
+	}
+	if s.RestartPolicy == "" {
+		s.RestartPolicy = d.RestartPolicy
+	}
+}
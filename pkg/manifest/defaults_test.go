@@ -0,0 +1,55 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestParseAppliesDefaults(t *testing.T) {
+	resources, err := ParseBytes([]byte(`
+kind: AgentPool
+metadata:
+  name: reviewers
+spec:
+  replicas: 2
+  template:
+    spec:
+      model: claude-sonnet
+      maxConcurrency: 3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := resources[0].(*v1alpha1.AgentPool)
+	if pool.APIVersion != v1alpha1.APIVersion || pool.Metadata.Project != DefaultProject {
+		t.Errorf("apiVersion %q, project %q", pool.APIVersion, pool.Metadata.Project)
+	}
+	spec := pool.Spec.Template.Spec
+	if spec.MaxConcurrency != 3 || spec.RestartPolicy != "OnFailure" || spec.MaxTokens != 0 {
+		t.Errorf("template spec = %+v", spec)
+	}
+}
+
+func TestDefaultsApply(t *testing.T) {
+	d := DefaultValues
+	d.MaxTokens = 8192
+
+	pod := &v1alpha1.AgentPod{
+		Metadata: v1alpha1.ObjectMeta{Name: "a", Project: "web"},
+		Spec:     v1alpha1.AgentPodSpec{RestartPolicy: "Never"},
+	}
+	d.Apply(pod)
+	if pod.Kind != v1alpha1.KindAgentPod || pod.Metadata.Project != "web" {
+		t.Errorf("kind %q, project %q", pod.Kind, pod.Metadata.Project)
+	}
+	if pod.Spec.MaxConcurrency != 1 || pod.Spec.MaxTokens != 8192 || pod.Spec.RestartPolicy != "Never" {
+		t.Errorf("spec = %+v", pod.Spec)
+	}
+
+	project := &v1alpha1.Project{Metadata: v1alpha1.ObjectMeta{Name: "web"}}
+	d.Apply(project)
+	if project.Metadata.Project != "" {
+		t.Errorf("project-scoped default set on a Project: %q", project.Metadata.Project)
+	}
+}
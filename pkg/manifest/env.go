@@ -0,0 +1,82 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ExpandEnv replaces ${VAR} and ${VAR:-default} in data with the values
+// lookup returns, usually os.LookupEnv. The default is used when VAR is
+// unset or empty. A variable that is unset and has no default is an error,
+// so a manifest is never applied with a value silently left blank. $${ is
+// an escaped, literal ${, and a $ not followed by { is left as is.
+func ExpandEnv(data []byte, lookup func(string) (string, bool)) ([]byte, error) {
+	var out bytes.Buffer
+	line := 1
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c == '\n' {
+			line++
+		}
+		if c != '$' {
+			out.WriteByte(c)
+			continue
+		}
+		if bytes.HasPrefix(data[i+1:], []byte("${")) {
+			out.WriteString("${")
+			i += 2
+			continue
+		}
+		if i+1 >= len(data) || data[i+1] != '{' {
+			out.WriteByte(c)
+			continue
+		}
+
+		end := bytes.IndexByte(data[i+2:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: unterminated ${", line)
+		}
+		expr := string(data[i+2 : i+2+end])
+		name, def, hasDefault := cutDefault(expr)
+		if !validEnvName(name) {
+			return nil, fmt.Errorf("line %d: invalid variable reference ${%s}", line, expr)
+		}
+
+		value, ok := lookup(name)
+		switch {
+		case hasDefault && value == "":
+			value = def
+		case !ok:
+			return nil, fmt.Errorf("line %d: variable %s is not set", line, name)
+		}
+		out.WriteString(value)
+		i += 2 + end
+	}
+	return out.Bytes(), nil
+}
+
+// cutDefault splits "NAME:-default" into its name and default.
+func cutDefault(expr string) (name, def string, ok bool) {
+	for i := 0; i+1 < len(expr); i++ {
+		if expr[i] == ':' && expr[i+1] == '-' {
+			return expr[:i], expr[i+2:], true
+		}
+	}
+	return expr, "", false
+}
+
+// validEnvName reports whether name is a shell variable name.
+func validEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
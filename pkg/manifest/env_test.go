@@ -0,0 +1,76 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"MODEL": "claude-opus-4-20250514", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: "model: ${MODEL}", want: "model: claude-opus-4-20250514"},
+		{input: "replicas: ${REPLICAS:-2}", want: "replicas: 2"},
+		{input: "path: ${EMPTY:-/srv}", want: "path: /srv"},
+		{input: "path: ${EMPTY}", want: "path: "},
+		{input: "prompt: cost is $5, see $HOME and $${MODEL}", want: "prompt: cost is $5, see $HOME and ${MODEL}"},
+		{input: "a: 1\nb: ${MISSING}", wantErr: "line 2: variable MISSING is not set"},
+		{input: "a: ${MODEL", wantErr: "unterminated"},
+		{input: "a: ${1X}", wantErr: "invalid variable reference"},
+	}
+	for _, tt := range tests {
+		got, err := ExpandEnv([]byte(tt.input), lookup)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ExpandEnv(%q): expected error containing %q, got %v", tt.input, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ExpandEnv(%q): unexpected error: %v", tt.input, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("ExpandEnv(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseWithEnv(t *testing.T) {
+	input := []byte(`kind: AgentPool
+metadata:
+  name: ${POOL:-workers}
+spec:
+  replicas: ${REPLICAS}
+`)
+	lookup := func(name string) (string, bool) {
+		if name == "REPLICAS" {
+			return "3", true
+		}
+		return "", false
+	}
+
+	resources, err := ParseBytes(input, WithEnv(lookup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool := resources[0].(*v1alpha1.AgentPool)
+	if pool.Metadata.Name != "workers" || pool.Spec.Replicas != 3 {
+		t.Errorf("expected workers with 3 replicas, got %s with %d", pool.Metadata.Name, pool.Spec.Replicas)
+	}
+
+	// Without the option the references are left alone.
+	if _, err := ParseBytes(input); err == nil {
+		t.Error("expected an error decoding ${REPLICAS} as replicas")
+	}
+}
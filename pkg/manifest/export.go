@@ -0,0 +1,66 @@
+package manifest
+
+import (
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Export converts a resource to a generic map without the fields the server
+// populates: its status and the serverMetadata of its metadata. The result
+// can be applied again as a manifest.
+func Export(resource interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	delete(m, "status")
+	stripMetadata(m)
+	return m, nil
+}
+
+// serverMetadata are the metadata fields the server and its controllers
+// populate.
+var serverMetadata = []string{"uid", "generation", "resourceVersion", "createdAt", "updatedAt", "finalizers", "deletionTimestamp"}
+
+// stripMetadata removes the serverMetadata from every metadata object in m,
+// including embedded ones such as a pool's pod template. Embedded metadata
+// left empty is removed altogether.
+func stripMetadata(m map[string]interface{}) {
+	for key, val := range m {
+		child, ok := val.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if key == "metadata" {
+			for _, field := range serverMetadata {
+				delete(child, field)
+			}
+			if name, _ := child["name"].(string); name == "" {
+				delete(child, "name")
+			}
+			if len(child) == 0 {
+				delete(m, key)
+			}
+			continue
+		}
+		stripMetadata(child)
+	}
+}
+
+// WriteYAML writes docs to w as a multi-document YAML stream.
+func WriteYAML(w io.Writer, docs []interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
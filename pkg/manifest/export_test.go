@@ -0,0 +1,48 @@
+package manifest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestExportStripsServerFields(t *testing.T) {
+	now := time.Now()
+	pool := &v1alpha1.AgentPool{
+		TypeMeta: v1alpha1.TypeMeta{APIVersion: v1alpha1.APIVersion, Kind: v1alpha1.KindAgentPool},
+		Metadata: v1alpha1.ObjectMeta{
+			Name:      "reviewers",
+			Project:   "default",
+			UID:       "1234",
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		Spec: v1alpha1.AgentPoolSpec{
+			Replicas: 2,
+			Template: v1alpha1.AgentPodTemplate{Spec: v1alpha1.AgentPodSpec{Model: "claude-sonnet"}},
+		},
+		Status: v1alpha1.AgentPoolStatus{ReadyReplicas: 2},
+	}
+
+	m, err := Export(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["status"]; ok {
+		t.Error("status was not stripped")
+	}
+	meta := m["metadata"].(map[string]interface{})
+	for _, field := range []string{"uid", "createdAt", "updatedAt"} {
+		if _, ok := meta[field]; ok {
+			t.Errorf("metadata.%s was not stripped", field)
+		}
+	}
+	if meta["name"] != "reviewers" || meta["project"] != "default" {
+		t.Errorf("unexpected metadata %v", meta)
+	}
+	template := m["spec"].(map[string]interface{})["template"].(map[string]interface{})
+	if _, ok := template["metadata"]; ok {
+		t.Errorf("empty template metadata was kept: %v", template)
+	}
+}
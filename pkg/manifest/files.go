@@ -0,0 +1,135 @@
+package manifest
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extensions are the file extensions of the manifests ParseFiles picks up
+// from directories.
+var Extensions = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+
+// Recursive makes ParseFiles descend into subdirectories.
+func Recursive() Option {
+	return func(o *options) {
+		o.recursive = true
+	}
+}
+
+// ParseReader reads a manifest from r, e.g. stdin, and parses it as
+// ParseBytes does.
+func ParseReader(r io.Reader, opts ...Option) ([]interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	return ParseBytes(data, opts...)
+}
+
+// ParseFiles parses every manifest named by paths, in order. A path may be
+// a file, a glob pattern, an overlay (see Overlay) or a directory, whose
+// .yaml, .yml and .json files are read in lexical order; subdirectories
+// too with Recursive. A file named by several paths is read once.
+func ParseFiles(paths []string, opts ...Option) ([]interface{}, error) {
+	files, err := expandPaths(paths, newOptions(opts).recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []interface{}
+	for _, f := range files {
+		var parsed []interface{}
+		if IsOverlay(f) {
+			parsed, err = LoadOverlay(f, opts...)
+		} else {
+			parsed, err = ParseFile(f, opts...)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing manifest %s: %w", f, err)
+		}
+		resources = append(resources, parsed...)
+	}
+	return resources, nil
+}
+
+// expandPaths resolves globs and directories into a de-duplicated list of
+// files and overlays, keeping the order of paths.
+func expandPaths(paths []string, recursive bool) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+
+	for _, p := range paths {
+		matches := []string{p}
+		if strings.ContainsAny(p, "*?[") {
+			var err error
+			matches, err = filepath.Glob(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q", p)
+			}
+		}
+
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", m, err)
+			}
+			if !info.IsDir() || IsOverlay(m) {
+				add(m)
+				continue
+			}
+			dirFiles, err := filesInDir(m, recursive)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range dirFiles {
+				add(f)
+			}
+		}
+	}
+	return files, nil
+}
+
+// filesInDir lists the manifest files in dir in lexical order. When
+// recursive, overlays found below dir are listed as a whole.
+func filesInDir(dir string, recursive bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			if !recursive {
+				return filepath.SkipDir
+			}
+			if IsOverlay(path) {
+				files = append(files, path)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != OverlayFile && Extensions[strings.ToLower(filepath.Ext(path))] {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading directory %s: %w", dir, err)
+	}
+	return files, nil
+}
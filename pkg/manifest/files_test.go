@@ -0,0 +1,68 @@
+package manifest
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func names(t *testing.T, resources []interface{}) string {
+	t.Helper()
+	var out []string
+	for _, r := range resources {
+		_, name := Identity(r)
+		out = append(out, name)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestParseFiles(t *testing.T) {
+	dir := t.TempDir()
+	project := func(name string) string { return "kind: Project\nmetadata:\n  name: " + name + "\n" }
+	writeFiles(t, dir, map[string]string{
+		"b.yaml":               project("b"),
+		"a.yml":                project("a"),
+		"c.json":               `{"kind": "Project", "metadata": {"name": "c"}}`,
+		"notes.txt":            "not a manifest",
+		"sub/d.yaml":           project("d"),
+		"sub/env/overlay.yaml": "resources: [../d.yaml]\nlabels: {env: prod}\n",
+	})
+
+	tests := []struct {
+		name  string
+		paths []string
+		opts  []Option
+		want  string
+	}{
+		{"directory", []string{dir}, nil, "a,b,c"},
+		{"recursive", []string{dir}, []Option{Recursive()}, "a,b,c,d,d"},
+		{"glob", []string{filepath.Join(dir, "*.y*ml")}, nil, "a,b"},
+		{"order and duplicates", []string{filepath.Join(dir, "c.json"), dir}, nil, "c,a,b"},
+		{"overlay", []string{filepath.Join(dir, "sub", "env")}, nil, "d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources, err := ParseFiles(tt.paths, tt.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := names(t, resources); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ParseFiles([]string{filepath.Join(dir, "*.toml")}); err == nil {
+		t.Error("expected an error for a glob matching nothing")
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	resources, err := ParseReader(strings.NewReader("kind: Project\nmetadata:\n  name: stdin\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := names(t, resources); got != "stdin" {
+		t.Errorf("expected stdin, got %s", got)
+	}
+}
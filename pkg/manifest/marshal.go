@@ -0,0 +1,108 @@
+package manifest
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalOption configures Marshal.
+type MarshalOption func(*marshalOptions)
+
+type marshalOptions struct {
+	omitStatus       bool
+	omitServerFields bool
+}
+
+// OmitStatus leaves the status of each resource out.
+func OmitStatus() MarshalOption {
+	return func(o *marshalOptions) {
+		o.omitStatus = true
+	}
+}
+
+// OmitServerFields leaves out every field the server populates, as Export
+// does: the status, and the uid, generation, resourceVersion, finalizers
+// and timestamps of all metadata. The output
+// can be applied again as a manifest.
+func OmitServerFields() MarshalOption {
+	return func(o *marshalOptions) {
+		o.omitStatus = true
+		o.omitServerFields = true
+	}
+}
+
+// Marshal writes resources to w as a multi-document YAML stream. Fields
+// keep the order of their Go struct, apiVersion and kind first, so that the
+// output is stable and reads like a hand-written manifest; empty optional
+// fields are left out.
+func Marshal(resources []interface{}, w io.Writer, opts ...MarshalOption) error {
+	var o marshalOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	for _, r := range resources {
+		var node yaml.Node
+		if err := node.Encode(r); err != nil {
+			kind, name := Identity(r)
+			return fmt.Errorf("encoding %s %s: %w", kind, name, err)
+		}
+		if o.omitStatus {
+			removeKey(&node, "status")
+		}
+		if o.omitServerFields {
+			stripNodeMetadata(&node)
+		}
+		if err := enc.Encode(&node); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// removeKey deletes key from the mapping node m.
+func removeKey(m *yaml.Node, key string) {
+	if m.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// stripNodeMetadata is stripMetadata for a YAML node.
+func stripNodeMetadata(m *yaml.Node) {
+	if m.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key, val := m.Content[i], m.Content[i+1]
+		if val.Kind != yaml.MappingNode {
+			continue
+		}
+		if key.Value != "metadata" {
+			stripNodeMetadata(val)
+			continue
+		}
+		for _, field := range serverMetadata {
+			removeKey(val, field)
+		}
+		for j := 0; j+1 < len(val.Content); j += 2 {
+			if val.Content[j].Value == "name" && val.Content[j+1].Value == "" {
+				removeKey(val, "name")
+				break
+			}
+		}
+		if len(val.Content) == 0 {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			i -= 2
+		}
+	}
+}
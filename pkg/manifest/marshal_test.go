@@ -0,0 +1,80 @@
+package manifest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestMarshal(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	pool := &v1alpha1.AgentPool{
+		TypeMeta: v1alpha1.TypeMeta{APIVersion: v1alpha1.APIVersion, Kind: v1alpha1.KindAgentPool},
+		Metadata: v1alpha1.ObjectMeta{Name: "reviewers", Project: "web", UID: "1234", CreatedAt: now},
+		Spec: v1alpha1.AgentPoolSpec{
+			Replicas: 2,
+			Template: v1alpha1.AgentPodTemplate{Spec: v1alpha1.AgentPodSpec{Model: "claude-sonnet"}},
+		},
+		Status: v1alpha1.AgentPoolStatus{ReadyReplicas: 2},
+	}
+	project := &v1alpha1.Project{
+		TypeMeta: v1alpha1.TypeMeta{APIVersion: v1alpha1.APIVersion, Kind: v1alpha1.KindProject},
+		Metadata: v1alpha1.ObjectMeta{Name: "web"},
+		Status:   "Active",
+	}
+	resources := []interface{}{project, pool}
+
+	var full bytes.Buffer
+	if err := Marshal(resources, &full); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"uid: \"1234\"", "readyReplicas: 2", "status: Active"} {
+		if !strings.Contains(full.String(), want) {
+			t.Errorf("expected %q in\n%s", want, full.String())
+		}
+	}
+
+	var noStatus bytes.Buffer
+	if err := Marshal(resources, &noStatus, OmitStatus()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(noStatus.String(), "status:") || !strings.Contains(noStatus.String(), "uid:") {
+		t.Errorf("expected only the status to be omitted:\n%s", noStatus.String())
+	}
+
+	var exported bytes.Buffer
+	if err := Marshal(resources, &exported, OmitServerFields()); err != nil {
+		t.Fatal(err)
+	}
+	want := `apiVersion: orca.dev/v1alpha1
+kind: Project
+metadata:
+  name: web
+spec: {}
+---
+apiVersion: orca.dev/v1alpha1
+kind: AgentPool
+metadata:
+  name: reviewers
+  project: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      model: claude-sonnet
+`
+	if exported.String() != want {
+		t.Errorf("unexpected export:\n%s\nwant:\n%s", exported.String(), want)
+	}
+
+	parsed, err := ParseBytes(exported.Bytes())
+	if err != nil {
+		t.Fatalf("exported output does not parse: %v", err)
+	}
+	if got := parsed[1].(*v1alpha1.AgentPool); got.Spec.Replicas != 2 || got.Metadata.Project != "web" {
+		t.Errorf("round trip lost fields: %+v", got)
+	}
+}
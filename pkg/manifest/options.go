@@ -0,0 +1,45 @@
+package manifest
+
+// Option configures how ParseFile and ParseBytes read a manifest.
+type Option func(*options)
+
+type options struct {
+	envLookup func(string) (string, bool)
+	values    map[string]interface{}
+	strict    bool
+	recursive bool
+}
+
+// WithEnv expands ${VAR} and ${VAR:-default} references in the manifest
+// before parsing it, looking variables up with lookup, e.g. os.LookupEnv.
+// See ExpandEnv.
+func WithEnv(lookup func(string) (string, bool)) Option {
+	return func(o *options) {
+		o.envLookup = lookup
+	}
+}
+
+// WithValues renders the manifest as a Go template with values as .Values
+// before parsing it, as Render does. Environment variables are expanded
+// after rendering.
+func WithValues(values map[string]interface{}) Option {
+	return func(o *options) {
+		o.values = values
+	}
+}
+
+// Strict makes fields that the resource's type does not have an error, so
+// that a typo such as maxConcurency is reported instead of ignored.
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,60 @@
+package manifest
+
+import (
+	"sort"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// kindOrder ranks kinds so that dependencies are applied first: Projects
+// before everything in them, Secrets, ConfigMaps, ModelProviders and
+// ToolDefinitions before the pods that reference them, and agents before the tasks that run on them and the
+// autoscalers that scale them.
+var kindOrder = map[string]int{
+	v1alpha1.KindProject:             0,
+	v1alpha1.KindSecret:              1,
+	v1alpha1.KindConfigMap:           1,
+	v1alpha1.KindModelProvider:       1,
+	v1alpha1.KindToolDefinition:      1,
+	v1alpha1.KindAgentPool:           2,
+	v1alpha1.KindAgentPod:            2,
+	v1alpha1.KindDevTask:             3,
+	v1alpha1.KindAgentPoolAutoscaler: 3,
+}
+
+// SortByDependency orders parsed resources so that dependencies come first,
+// keeping the declared order within a kind.
+func SortByDependency(resources []interface{}) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		ki, _ := Identity(resources[i])
+		kj, _ := Identity(resources[j])
+		return kindOrder[ki] < kindOrder[kj]
+	})
+}
+
+// Identity returns the kind and name of a typed resource as returned by
+// ParseBytes.
+func Identity(resource interface{}) (kind, name string) {
+	switch r := resource.(type) {
+	case *v1alpha1.Project:
+		return r.Kind, r.Metadata.Name
+	case *v1alpha1.AgentPod:
+		return r.Kind, r.Metadata.Name
+	case *v1alpha1.AgentPool:
+		return r.Kind, r.Metadata.Name
+	case *v1alpha1.DevTask:
+		return r.Kind, r.Metadata.Name
+	case *v1alpha1.Secret:
+		return r.Kind, r.Metadata.Name
+	case *v1alpha1.ConfigMap:
+		return r.Kind, r.Metadata.Name
+	case *v1alpha1.ModelProvider:
+		return r.Kind, r.Metadata.Name
+	case *v1alpha1.ToolDefinition:
+		return r.Kind, r.Metadata.Name
+	case *v1alpha1.AgentPoolAutoscaler:
+		return r.Kind, r.Metadata.Name
+	default:
+		return "Unknown", "unknown"
+	}
+}
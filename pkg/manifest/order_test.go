@@ -0,0 +1,51 @@
+package manifest
+
+import (
+	"testing"
+)
+
+func TestSortByDependency(t *testing.T) {
+	resources, err := ParseBytes([]byte(`
+apiVersion: orca.dev/v1alpha1
+kind: DevTask
+metadata:
+  name: t1
+spec:
+  prompt: "hello"
+---
+apiVersion: orca.dev/v1alpha1
+kind: AgentPool
+metadata:
+  name: p1
+spec:
+  replicas: 1
+  template:
+    spec:
+      model: sonnet
+---
+apiVersion: orca.dev/v1alpha1
+kind: Project
+metadata:
+  name: proj
+---
+apiVersion: orca.dev/v1alpha1
+kind: DevTask
+metadata:
+  name: t2
+spec:
+  prompt: "world"
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	SortByDependency(resources)
+
+	want := []string{"Project/proj", "AgentPool/p1", "DevTask/t1", "DevTask/t2"}
+	for i, r := range resources {
+		kind, name := Identity(r)
+		if got := kind + "/" + name; got != want[i] {
+			t.Errorf("resource %d: expected %s, got %s", i, want[i], got)
+		}
+	}
+}
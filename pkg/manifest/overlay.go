@@ -0,0 +1,250 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OverlayFile is the name of the file that makes a directory an overlay.
+const OverlayFile = "overlay.yaml"
+
+// Overlay layers changes over base manifests, so that variants of the same
+// resources, e.g. per environment, don't repeat whole files:
+//
+//	resources:
+//	  - ../base
+//	patches:
+//	  - replicas.yaml
+//	labels:
+//	  env: prod
+type Overlay struct {
+	// Resources are the base manifests, relative to the overlay file: files,
+	// directories of .yaml, .yml and .json files, or other overlays.
+	Resources []string `yaml:"resources"`
+	// Patches are files of partial manifests, relative to the overlay file.
+	// Each is merged into the base resource with the same kind, name and,
+	// when it sets one, project, as a JSON merge patch (RFC 7386): maps are
+	// merged, null removes a field and anything else replaces it.
+	Patches []string `yaml:"patches"`
+	// Labels are set on every resource.
+	Labels map[string]string `yaml:"labels"`
+}
+
+// IsOverlay reports whether path is an overlay file or a directory with
+// one.
+func IsOverlay(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if !info.IsDir() {
+		return filepath.Base(path) == OverlayFile
+	}
+	_, err = os.Stat(filepath.Join(path, OverlayFile))
+	return err == nil
+}
+
+// LoadOverlay reads the overlay at path, an overlay file or a directory
+// with one, and returns its base resources with the patches and labels
+// applied. opts apply to every manifest read, patches included.
+func LoadOverlay(path string, opts ...Option) ([]interface{}, error) {
+	return loadOverlay(path, opts, map[string]bool{})
+}
+
+func loadOverlay(path string, opts []Option, visiting map[string]bool) ([]interface{}, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, OverlayFile)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if visiting[abs] {
+		return nil, fmt.Errorf("overlay %s includes itself", path)
+	}
+	visiting[abs] = true
+	defer delete(visiting, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading overlay %s: %w", path, err)
+	}
+	var overlay Overlay
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("decoding overlay %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	strict := newOptions(opts).strict
+
+	var resources []interface{}
+	for _, base := range overlay.Resources {
+		parsed, err := loadBase(filepath.Join(dir, base), opts, visiting)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, parsed...)
+	}
+
+	for _, patchFile := range overlay.Patches {
+		patches, err := loadPatches(filepath.Join(dir, patchFile), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range patches {
+			if err := applyPatch(resources, p, strict); err != nil {
+				return nil, fmt.Errorf("patch %s: %w", patchFile, err)
+			}
+		}
+	}
+
+	if len(overlay.Labels) > 0 {
+		labels := map[string]interface{}{}
+		for k, v := range overlay.Labels {
+			labels[k] = v
+		}
+		patch := map[string]interface{}{"metadata": map[string]interface{}{"labels": labels}}
+		for i, r := range resources {
+			if resources[i], err = mergeResource(r, patch, strict); err != nil {
+				return nil, fmt.Errorf("overlay %s: %w", path, err)
+			}
+		}
+	}
+	return resources, nil
+}
+
+// loadBase reads one entry of an overlay's resources.
+func loadBase(path string, opts []Option, visiting map[string]bool) ([]interface{}, error) {
+	if IsOverlay(path) {
+		return loadOverlay(path, opts, visiting)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading base %s: %w", path, err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filesInDir(path, false); err != nil {
+			return nil, err
+		}
+	}
+
+	var resources []interface{}
+	for _, f := range files {
+		parsed, err := ParseFile(f, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("parsing manifest %s: %w", f, err)
+		}
+		resources = append(resources, parsed...)
+	}
+	return resources, nil
+}
+
+// loadPatches reads the documents of a patch file as generic maps. Patches
+// are partial, so they are neither typed nor validated until merged.
+func loadPatches(path string, opts []Option) ([]map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading patch %s: %w", path, err)
+	}
+	if data, err = preprocess(data, newOptions(opts)); err != nil {
+		return nil, err
+	}
+
+	var patches []map[string]interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("decoding patch %s: %w", path, err)
+		}
+		if len(doc) > 0 {
+			patches = append(patches, doc)
+		}
+	}
+	return patches, nil
+}
+
+// applyPatch merges patch into the resource it names.
+func applyPatch(resources []interface{}, patch map[string]interface{}, strict bool) error {
+	kind, _ := patch["kind"].(string)
+	meta, _ := patch["metadata"].(map[string]interface{})
+	name, _ := meta["name"].(string)
+	project, _ := meta["project"].(string)
+	if kind == "" || name == "" {
+		return fmt.Errorf("a patch needs a kind and metadata.name")
+	}
+
+	for i, r := range resources {
+		k, n := Identity(r)
+		if k != kind || n != name || (project != "" && projectOf(r) != project) {
+			continue
+		}
+		merged, err := mergeResource(r, patch, strict)
+		if err != nil {
+			return err
+		}
+		resources[i] = merged
+		return nil
+	}
+	return fmt.Errorf("no %s named %q to patch", kind, name)
+}
+
+// projectOf returns the project of a parsed resource.
+func projectOf(resource interface{}) string {
+	var r struct {
+		Metadata struct {
+			Project string `json:"project"`
+		} `json:"metadata"`
+	}
+	raw, _ := json.Marshal(resource)
+	_ = json.Unmarshal(raw, &r)
+	return r.Metadata.Project
+}
+
+// mergeResource returns resource with patch merged in, decoded and
+// validated again.
+func mergeResource(resource interface{}, patch map[string]interface{}, strict bool) (interface{}, error) {
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if raw, err = json.Marshal(mergePatch(doc, patch)); err != nil {
+		return nil, err
+	}
+	kind, _ := Identity(resource)
+	return decodeResource(kind, jsonDecoder(raw, strict))
+}
+
+// mergePatch implements RFC 7386: objects are merged recursively, null
+// deletes a member and any other value replaces the target.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = mergePatch(targetObj[k], v)
+	}
+	return targetObj
+}
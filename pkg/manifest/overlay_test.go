@@ -0,0 +1,109 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadOverlay(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"base/pool.yaml": `kind: AgentPool
+metadata:
+  name: reviewers
+  project: web
+  labels:
+    team: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      model: claude-sonnet-4-20250514
+      systemPrompt: review carefully
+`,
+		"base/project.yaml": "kind: Project\nmetadata:\n  name: web\n",
+		"prod/overlay.yaml": `resources:
+  - ../base
+patches:
+  - pool.yaml
+labels:
+  env: prod
+`,
+		"prod/pool.yaml": `kind: AgentPool
+metadata:
+  name: reviewers
+spec:
+  replicas: 4
+  template:
+    spec:
+      model: claude-opus-4-20250514
+      systemPrompt: null
+`,
+	})
+
+	if !IsOverlay(filepath.Join(dir, "prod")) || IsOverlay(filepath.Join(dir, "base")) {
+		t.Fatal("IsOverlay should only report the prod directory")
+	}
+
+	resources, err := LoadOverlay(filepath.Join(dir, "prod"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(resources))
+	}
+
+	pool, ok := resources[0].(*v1alpha1.AgentPool)
+	if !ok {
+		t.Fatalf("expected resource[0] to be *v1alpha1.AgentPool, got %T", resources[0])
+	}
+	if pool.Spec.Replicas != 4 {
+		t.Errorf("expected 4 replicas, got %d", pool.Spec.Replicas)
+	}
+	if pool.Spec.Template.Spec.Model != "claude-opus-4-20250514" {
+		t.Errorf("expected the patched model, got %s", pool.Spec.Template.Spec.Model)
+	}
+	if pool.Spec.Template.Spec.SystemPrompt != "" {
+		t.Errorf("expected null to remove systemPrompt, got %q", pool.Spec.Template.Spec.SystemPrompt)
+	}
+	if pool.Metadata.Labels["team"] != "web" || pool.Metadata.Labels["env"] != "prod" {
+		t.Errorf("expected team=web and env=prod labels, got %v", pool.Metadata.Labels)
+	}
+	if proj := resources[1].(*v1alpha1.Project); proj.Metadata.Labels["env"] != "prod" {
+		t.Errorf("expected env=prod on the project, got %v", proj.Metadata.Labels)
+	}
+}
+
+func TestLoadOverlayErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"base.yaml":            "kind: Project\nmetadata:\n  name: web\n",
+		"missing/overlay.yaml": "resources: [../base.yaml]\npatches: [patch.yaml]\n",
+		"missing/patch.yaml":   "kind: Project\nmetadata:\n  name: api\n",
+		"loop/overlay.yaml":    "resources: [.]\n",
+	})
+
+	if _, err := LoadOverlay(filepath.Join(dir, "missing")); err == nil || !strings.Contains(err.Error(), `no Project named "api"`) {
+		t.Errorf("expected an unmatched patch error, got %v", err)
+	}
+	if _, err := LoadOverlay(filepath.Join(dir, "loop")); err == nil || !strings.Contains(err.Error(), "includes itself") {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+}
@@ -1,47 +1,96 @@
-// Package manifest provides YAML manifest parsing for Orca resources.
+// Package manifest provides YAML and JSON manifest parsing for Orca
+// resources.
 package manifest
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/klubi/orca/pkg/apis/v1alpha1"
 	"gopkg.in/yaml.v3"
 )
 
-// ParseFile reads a YAML file at the given path and parses it into typed
-// Orca resources. Multi-document YAML (separated by ---) is supported.
-func ParseFile(path string) ([]interface{}, error) {
+// ParseFile reads a YAML or JSON file at the given path and parses it into
+// typed Orca resources. Multi-document YAML (separated by ---) and JSON
+// arrays of objects are supported. Files with a .json extension are always
+// read as JSON; others as ParseBytes reads them.
+func ParseFile(path string, opts ...Option) ([]interface{}, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading manifest file %s: %w", path, err)
 	}
-	return ParseBytes(data)
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseBytes(data, opts...)
+	}
+	o := newOptions(opts)
+	data, err = preprocess(data, o)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSON(data, o)
+}
+
+// ParseBytes parses raw YAML or JSON bytes into typed Orca resources.
+// Multi-document YAML (separated by ---) is supported. Data starting with
+// { or [ is read as JSON: one or more objects, or arrays of objects. If it
+// is not valid JSON it is read as YAML, which has a flow style that looks
+// the same.
+func ParseBytes(data []byte, opts ...Option) ([]interface{}, error) {
+	o := newOptions(opts)
+	data, err := preprocess(data, o)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		resources, err := parseJSON(data, o)
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			return resources, err
+		}
+	}
+	return parseDocuments(data, o)
 }
 
-// ParseBytes parses raw YAML bytes into typed Orca resources.
-// Multi-document YAML (separated by ---) is supported.
-func ParseBytes(data []byte) ([]interface{}, error) {
-	return parseDocuments(data)
+// preprocess rewrites the raw manifest as o asks before it is parsed.
+func preprocess(data []byte, o *options) ([]byte, error) {
+	if o.values != nil {
+		rendered, err := renderTemplate("manifest", data, o.values)
+		if err != nil {
+			return nil, err
+		}
+		data = rendered
+	}
+	if o.envLookup != nil {
+		expanded, err := ExpandEnv(data, o.envLookup)
+		if err != nil {
+			return nil, fmt.Errorf("expanding environment variables: %w", err)
+		}
+		data = expanded
+	}
+	return data, nil
 }
 
 // parseDocuments splits multi-document YAML and decodes each document into
 // its concrete Orca resource type.
-func parseDocuments(data []byte) ([]interface{}, error) {
+func parseDocuments(data []byte, o *options) ([]interface{}, error) {
 	var resources []interface{}
 
 	decoder := yaml.NewDecoder(bytes.NewReader(data))
 
-	for {
+	for document := 1; ; document++ {
 		// Decode into a generic yaml.Node so we can re-decode it.
 		var node yaml.Node
 		if err := decoder.Decode(&node); err != nil {
 			if err == io.EOF {
 				break
 			}
-			return nil, fmt.Errorf("decoding yaml document: %w", err)
+			return nil, fmt.Errorf("decoding yaml document %d: %w", document, err)
 		}
 
 		// Skip empty documents.
@@ -61,103 +110,160 @@ func parseDocuments(data []byte) ([]interface{}, error) {
 		}
 
 		// Second pass: decode into the concrete type based on Kind.
-		resource, err := decodeResource(&node, meta.Kind)
+		resource, err := decodeResource(meta.Kind, yamlDecoder(&node, o.strict))
 		if err != nil {
-			return nil, err
+			return nil, documentError(err, document, &node)
 		}
+		resources = append(resources, resource)
+	}
 
-		// Set default APIVersion if empty.
-		setDefaultAPIVersion(resource)
+	return resources, nil
+}
 
-		// Validate required fields.
-		if err := validateResource(resource); err != nil {
-			return nil, err
+// parseJSON decodes a stream of JSON values, each an object or an array of
+// objects, into their concrete Orca resource types.
+func parseJSON(data []byte, o *options) ([]interface{}, error) {
+	var resources []interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	document := 0
+
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("decoding json document: %w", err)
 		}
 
-		resources = append(resources, resource)
+		objects := []json.RawMessage{raw}
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			if err := json.Unmarshal(raw, &objects); err != nil {
+				return nil, fmt.Errorf("decoding json array: %w", err)
+			}
+		}
+
+		for _, obj := range objects {
+			document++
+			var meta v1alpha1.TypeMeta
+			if err := json.Unmarshal(obj, &meta); err != nil {
+				return nil, fmt.Errorf("decoding type meta: %w", err)
+			}
+
+			// Skip empty objects, as parseDocuments skips empty documents.
+			if meta.Kind == "" && meta.APIVersion == "" {
+				continue
+			}
+
+			resource, err := decodeResource(meta.Kind, jsonDecoder(obj, o.strict))
+			if err != nil {
+				return nil, documentError(err, document, nil)
+			}
+			resources = append(resources, resource)
+		}
 	}
 
 	return resources, nil
 }
 
-// decodeResource unmarshals a yaml.Node into the correct concrete type
-// based on the resource Kind.
-func decodeResource(node *yaml.Node, kind string) (interface{}, error) {
+// documentError places err, from decodeResource, in the input: a
+// *ValidationError gets the document index and field lines, other errors
+// the document index. node is nil for JSON.
+func documentError(err error, document int, node *yaml.Node) error {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		verr.locate(document, node)
+		return verr
+	}
+	return fmt.Errorf("document %d: %w", document, err)
+}
+
+// decodeResource decodes a document into the correct concrete type based on
+// the resource Kind, applies the defaults and validates it. decode is
+// from yamlDecoder or jsonDecoder.
+func decodeResource(kind string, decode func(interface{}) error) (interface{}, error) {
+	resource, err := decodeKind(kind, decode)
+	if err != nil {
+		return nil, err
+	}
+
+	ApplyDefaults(resource)
+
+	// Validate required fields.
+	if err := validateResource(resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// decodeKind decodes a document into the concrete type of kind.
+func decodeKind(kind string, decode func(interface{}) error) (interface{}, error) {
 	switch kind {
 	case v1alpha1.KindProject:
 		var r v1alpha1.Project
-		if err := node.Decode(&r); err != nil {
+		if err := decode(&r); err != nil {
 			return nil, fmt.Errorf("decoding Project: %w", err)
 		}
 		return &r, nil
 
 	case v1alpha1.KindAgentPod:
 		var r v1alpha1.AgentPod
-		if err := node.Decode(&r); err != nil {
+		if err := decode(&r); err != nil {
 			return nil, fmt.Errorf("decoding AgentPod: %w", err)
 		}
 		return &r, nil
 
 	case v1alpha1.KindAgentPool:
 		var r v1alpha1.AgentPool
-		if err := node.Decode(&r); err != nil {
+		if err := decode(&r); err != nil {
 			return nil, fmt.Errorf("decoding AgentPool: %w", err)
 		}
 		return &r, nil
 
 	case v1alpha1.KindDevTask:
 		var r v1alpha1.DevTask
-		if err := node.Decode(&r); err != nil {
+		if err := decode(&r); err != nil {
 			return nil, fmt.Errorf("decoding DevTask: %w", err)
 		}
 		return &r, nil
 
-	default:
-		return nil, fmt.Errorf("unknown resource kind: %q", kind)
-	}
-}
-
-// setDefaultAPIVersion sets the APIVersion to the default value if it is empty.
-func setDefaultAPIVersion(resource interface{}) {
-	switch r := resource.(type) {
-	case *v1alpha1.Project:
-		if r.APIVersion == "" {
-			r.APIVersion = v1alpha1.APIVersion
-		}
-	case *v1alpha1.AgentPod:
-		if r.APIVersion == "" {
-			r.APIVersion = v1alpha1.APIVersion
-		}
-	case *v1alpha1.AgentPool:
-		if r.APIVersion == "" {
-			r.APIVersion = v1alpha1.APIVersion
+	case v1alpha1.KindSecret:
+		var r v1alpha1.Secret
+		if err := decode(&r); err != nil {
+			return nil, fmt.Errorf("decoding Secret: %w", err)
 		}
-	case *v1alpha1.DevTask:
-		if r.APIVersion == "" {
-			r.APIVersion = v1alpha1.APIVersion
-		}
-	}
-}
+		return &r, nil
 
-// validateResource checks that required fields are set on the resource.
-func validateResource(resource interface{}) error {
-	switch r := resource.(type) {
-	case *v1alpha1.Project:
-		if r.Metadata.Name == "" {
-			return fmt.Errorf("validation failed: Project name must not be empty")
+	case v1alpha1.KindConfigMap:
+		var r v1alpha1.ConfigMap
+		if err := decode(&r); err != nil {
+			return nil, fmt.Errorf("decoding ConfigMap: %w", err)
 		}
-	case *v1alpha1.AgentPod:
-		if r.Metadata.Name == "" {
-			return fmt.Errorf("validation failed: AgentPod name must not be empty")
+		return &r, nil
+
+	case v1alpha1.KindModelProvider:
+		var r v1alpha1.ModelProvider
+		if err := decode(&r); err != nil {
+			return nil, fmt.Errorf("decoding ModelProvider: %w", err)
 		}
-	case *v1alpha1.AgentPool:
-		if r.Metadata.Name == "" {
-			return fmt.Errorf("validation failed: AgentPool name must not be empty")
+		return &r, nil
+
+	case v1alpha1.KindToolDefinition:
+		var r v1alpha1.ToolDefinition
+		if err := decode(&r); err != nil {
+			return nil, fmt.Errorf("decoding ToolDefinition: %w", err)
 		}
-	case *v1alpha1.DevTask:
-		if r.Metadata.Name == "" {
-			return fmt.Errorf("validation failed: DevTask name must not be empty")
+		return &r, nil
+
+	case v1alpha1.KindAgentPoolAutoscaler:
+		var r v1alpha1.AgentPoolAutoscaler
+		if err := decode(&r); err != nil {
+			return nil, fmt.Errorf("decoding AgentPoolAutoscaler: %w", err)
 		}
+		return &r, nil
+
+	default:
+		return nil, fmt.Errorf("unknown resource kind: %q", kind)
 	}
-	return nil
 }
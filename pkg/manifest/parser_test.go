@@ -57,6 +57,10 @@ kind: AgentPod
 metadata:
   name: coder-1
   project: my-project
+  ownerReferences:
+    - kind: AgentPool
+      name: pool-1
+      controller: true
 spec:
   model: claude-sonnet-4-20250514
   systemPrompt: "You are a coding assistant."
@@ -69,7 +73,6 @@ spec:
     - bash
     - editor
   restartPolicy: OnFailure
-  ownerPool: pool-1
 `)
 	resources, err := ParseBytes(yaml)
 	if err != nil {
@@ -118,8 +121,8 @@ spec:
 	if pod.Spec.RestartPolicy != "OnFailure" {
 		t.Errorf("expected restartPolicy OnFailure, got %s", pod.Spec.RestartPolicy)
 	}
-	if pod.Spec.OwnerPool != "pool-1" {
-		t.Errorf("expected ownerPool pool-1, got %s", pod.Spec.OwnerPool)
+	if pod.OwnerPool() != "pool-1" {
+		t.Errorf("expected owner pool pool-1, got %s", pod.OwnerPool())
 	}
 }
 
@@ -434,3 +437,237 @@ spec:
 		t.Errorf("expected model claude-sonnet-4-20250514, got %s", pod.Spec.Model)
 	}
 }
+
+func TestParseSecretAndPodEnv(t *testing.T) {
+	yaml := []byte(`
+apiVersion: orca.dev/v1alpha1
+kind: Secret
+metadata:
+  name: gh
+  project: my-project
+stringData:
+  GITHUB_TOKEN: ghp_example
+---
+apiVersion: orca.dev/v1alpha1
+kind: AgentPod
+metadata:
+  name: coder-1
+  project: my-project
+spec:
+  model: claude-sonnet
+  env:
+    - name: LOG_LEVEL
+      value: debug
+    - name: TOKEN
+      valueFrom:
+        secretKeyRef:
+          name: gh
+          key: GITHUB_TOKEN
+  envFrom:
+    - prefix: GH_
+      secretRef:
+        name: gh
+`)
+	resources, err := ParseBytes(yaml)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(resources))
+	}
+
+	sec, ok := resources[0].(*v1alpha1.Secret)
+	if !ok {
+		t.Fatalf("expected *v1alpha1.Secret, got %T", resources[0])
+	}
+	if sec.StringData["GITHUB_TOKEN"] != "ghp_example" {
+		t.Errorf("expected stringData GITHUB_TOKEN=ghp_example, got %q", sec.StringData["GITHUB_TOKEN"])
+	}
+
+	pod, ok := resources[1].(*v1alpha1.AgentPod)
+	if !ok {
+		t.Fatalf("expected *v1alpha1.AgentPod, got %T", resources[1])
+	}
+	if len(pod.Spec.Env) != 2 {
+		t.Fatalf("expected 2 env vars, got %d", len(pod.Spec.Env))
+	}
+	if pod.Spec.Env[0].Name != "LOG_LEVEL" || pod.Spec.Env[0].Value != "debug" {
+		t.Errorf("unexpected first env var: %+v", pod.Spec.Env[0])
+	}
+	ref := pod.Spec.Env[1].ValueFrom
+	if ref == nil || ref.SecretKeyRef == nil || ref.SecretKeyRef.Name != "gh" || ref.SecretKeyRef.Key != "GITHUB_TOKEN" {
+		t.Errorf("unexpected secretKeyRef: %+v", ref)
+	}
+	if len(pod.Spec.EnvFrom) != 1 || pod.Spec.EnvFrom[0].Prefix != "GH_" || pod.Spec.EnvFrom[0].SecretRef.Name != "gh" {
+		t.Errorf("unexpected envFrom: %+v", pod.Spec.EnvFrom)
+	}
+}
+
+func TestParsePermissionMode(t *testing.T) {
+	yaml := []byte(`
+kind: AgentPod
+metadata:
+  name: agentic
+spec:
+  model: claude-sonnet
+  maxTurns: 12
+  permissionMode: acceptEdits
+`)
+	resources, err := ParseBytes(yaml)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pod := resources[0].(*v1alpha1.AgentPod)
+	if pod.Spec.MaxTurns != 12 {
+		t.Errorf("expected maxTurns 12, got %d", pod.Spec.MaxTurns)
+	}
+	if pod.Spec.PermissionMode != v1alpha1.PermissionAcceptEdits {
+		t.Errorf("expected permissionMode acceptEdits, got %s", pod.Spec.PermissionMode)
+	}
+
+	_, err = ParseBytes([]byte(`
+kind: AgentPod
+metadata:
+  name: agentic
+spec:
+  permissionMode: yolo
+`))
+	if err == nil {
+		t.Fatal("expected error for unknown permissionMode, got nil")
+	}
+}
+
+func TestParseAgentPoolAutoscaler(t *testing.T) {
+	yaml := []byte(`
+kind: AgentPoolAutoscaler
+metadata:
+  name: reviewers
+  project: web
+spec:
+  pool: reviewers
+  minReplicas: 1
+  maxReplicas: 10
+`)
+	resources, err := ParseBytes(yaml)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	as, ok := resources[0].(*v1alpha1.AgentPoolAutoscaler)
+	if !ok {
+		t.Fatalf("expected *v1alpha1.AgentPoolAutoscaler, got %T", resources[0])
+	}
+	if as.Spec.Pool != "reviewers" || as.Spec.MinReplicas != 1 || as.Spec.MaxReplicas != 10 {
+		t.Errorf("unexpected spec: %+v", as.Spec)
+	}
+
+	_, err = ParseBytes([]byte(`
+kind: AgentPoolAutoscaler
+metadata:
+  name: reviewers
+spec:
+  pool: reviewers
+  minReplicas: 5
+  maxReplicas: 2
+`))
+	if err == nil {
+		t.Fatal("expected error for minReplicas above maxReplicas, got nil")
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "single object",
+			input: `{"kind": "Project", "metadata": {"name": "json-project"}}`,
+			want:  []string{"json-project"},
+		},
+		{
+			name: "array",
+			input: `[
+	{"apiVersion": "orca.dev/v1alpha1", "kind": "Project", "metadata": {"name": "a"}},
+	{},
+	{"kind": "AgentPod", "metadata": {"name": "b", "project": "a"}, "spec": {"model": "claude-sonnet-4-20250514"}}
+]`,
+			want: []string{"a", "b"},
+		},
+		{
+			name:  "stream",
+			input: `{"kind": "Project", "metadata": {"name": "a"}} [{"kind": "Project", "metadata": {"name": "b"}}]`,
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "yaml flow style",
+			input: `{kind: Project, metadata: {name: flow}}`,
+			want:  []string{"flow"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources, err := ParseBytes([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, r := range resources {
+				switch r := r.(type) {
+				case *v1alpha1.Project:
+					if r.APIVersion != v1alpha1.APIVersion {
+						t.Errorf("expected default apiVersion, got %q", r.APIVersion)
+					}
+					names = append(names, r.Metadata.Name)
+				case *v1alpha1.AgentPod:
+					names = append(names, r.Metadata.Name)
+				default:
+					t.Errorf("unexpected resource %T", r)
+				}
+			}
+			if len(names) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, names)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Errorf("expected %v, got %v", tt.want, names)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLegacyOwnerPool(t *testing.T) {
+	resources, err := ParseBytes([]byte(`{"kind": "AgentPod", "metadata": {"name": "p-1", "project": "a"}, "spec": {"model": "claude-sonnet-4-20250514", "ownerPool": "p"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pod := resources[0].(*v1alpha1.AgentPod)
+	ref := pod.Metadata.ControllerRef()
+	if ref == nil || ref.Kind != v1alpha1.KindAgentPool || ref.Name != "p" {
+		t.Errorf("expected spec.ownerPool to become a controller reference, got %+v", pod.Metadata.OwnerReferences)
+	}
+}
+
+func TestParseJSONFile(t *testing.T) {
+	path := t.TempDir() + "/pool.json"
+	content := []byte(`{"kind": "AgentPool", "metadata": {"name": "pool", "project": "p"}, "spec": {"replicas": 2}}`)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	resources, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool, ok := resources[0].(*v1alpha1.AgentPool)
+	if !ok || pool.Spec.Replicas != 2 {
+		t.Fatalf("expected a pool with 2 replicas, got %+v", resources[0])
+	}
+
+	if err := os.WriteFile(path, []byte(`{kind: Project}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFile(path); err == nil {
+		t.Error("expected an error for invalid JSON in a .json file")
+	}
+}
@@ -0,0 +1,100 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Render executes the manifest at templatePath as a Go template with the
+// values in the YAML file at valuesPath, Helm-style: a value is referenced
+// as {{ .Values.replicas }}. It returns the rendered manifest, ready for
+// ParseBytes.
+func Render(templatePath, valuesPath string) ([]byte, error) {
+	values, err := LoadValues(valuesPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest file %s: %w", templatePath, err)
+	}
+	return renderTemplate(filepath.Base(templatePath), data, values)
+}
+
+// LoadValues reads a YAML values file for Render or WithValues.
+func LoadValues(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading values file %s: %w", path, err)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("decoding values file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// templateFuncs are the functions available to manifest templates besides
+// the text/template builtins.
+var templateFuncs = template.FuncMap{
+	// default returns def when value is missing or empty:
+	// {{ .Values.model | default "claude-sonnet-4-20250514" }}.
+	"default": func(def, value interface{}) interface{} {
+		if isEmptyValue(value) {
+			return def
+		}
+		return value
+	},
+	// required fails rendering with msg when value is missing or empty.
+	"required": func(msg string, value interface{}) (interface{}, error) {
+		if isEmptyValue(value) {
+			return nil, fmt.Errorf("%s", msg)
+		}
+		return value, nil
+	},
+	// quote renders value as a double-quoted YAML string.
+	"quote": func(value interface{}) string {
+		return strconv.Quote(fmt.Sprint(value))
+	},
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case float64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// renderTemplate executes data as a template named name with values as
+// .Values. Missing values render as empty, as in Helm, rather than as
+// "<no value>".
+func renderTemplate(name string, data []byte, values map[string]interface{}) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, map[string]interface{}{"Values": values}); err != nil {
+		return nil, fmt.Errorf("rendering template: %w", err)
+	}
+	return bytes.ReplaceAll(out.Bytes(), []byte("<no value>"), nil), nil
+}
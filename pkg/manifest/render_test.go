@@ -0,0 +1,73 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	tplPath := filepath.Join(dir, "pool.tpl.yaml")
+	valuesPath := filepath.Join(dir, "values.yaml")
+
+	tpl := `kind: AgentPool
+metadata:
+  name: {{ required "name is required" .Values.name }}
+  project: {{ .Values.project | default "default" }}
+spec:
+  replicas: {{ .Values.replicas }}
+  template:
+    spec:
+      model: {{ .Values.model | quote }}
+      systemPrompt: {{ .Values.missing }}
+`
+	if err := os.WriteFile(tplPath, []byte(tpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(valuesPath, []byte("name: reviewers\nreplicas: 3\nmodel: claude-opus-4-20250514\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Render(tplPath, valuesPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "<no value>") {
+		t.Errorf("missing value rendered as <no value>:\n%s", data)
+	}
+	resources, err := ParseBytes(data)
+	if err != nil {
+		t.Fatalf("parsing rendered manifest: %v\n%s", err, data)
+	}
+	pool := resources[0].(*v1alpha1.AgentPool)
+	if pool.Metadata.Name != "reviewers" || pool.Metadata.Project != "default" || pool.Spec.Replicas != 3 {
+		t.Errorf("unexpected pool metadata %+v, replicas %d", pool.Metadata, pool.Spec.Replicas)
+	}
+	if pool.Spec.Template.Spec.Model != "claude-opus-4-20250514" {
+		t.Errorf("expected model claude-opus-4-20250514, got %s", pool.Spec.Template.Spec.Model)
+	}
+
+	if err := os.WriteFile(valuesPath, []byte("replicas: 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Render(tplPath, valuesPath); err == nil || !strings.Contains(err.Error(), "name is required") {
+		t.Errorf("expected the required error, got %v", err)
+	}
+}
+
+func TestParseWithValues(t *testing.T) {
+	input := []byte("kind: Project\nmetadata:\n  name: {{ .Values.env }}-${SUFFIX}\n")
+	lookup := func(string) (string, bool) { return "x", true }
+
+	resources, err := ParseBytes(input, WithValues(map[string]interface{}{"env": "prod"}), WithEnv(lookup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name := resources[0].(*v1alpha1.Project).Metadata.Name; name != "prod-x" {
+		t.Errorf("expected prod-x, got %s", name)
+	}
+}
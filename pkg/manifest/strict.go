@@ -0,0 +1,140 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// yamlDecoder returns a decode function for node for decodeResource. When
+// strict, fields the target type does not have are an error.
+func yamlDecoder(node *yaml.Node, strict bool) func(interface{}) error {
+	return func(v interface{}) error {
+		if strict {
+			if err := checkKnownFields(node, reflect.TypeOf(v)); err != nil {
+				return err
+			}
+		}
+		return node.Decode(v)
+	}
+}
+
+// jsonDecoder is yamlDecoder for a JSON object.
+func jsonDecoder(data []byte, strict bool) func(interface{}) error {
+	return func(v interface{}) error {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if strict {
+			dec.DisallowUnknownFields()
+		}
+		return dec.Decode(v)
+	}
+}
+
+// checkKnownFields returns an error listing every field of node, a YAML
+// document or value, that has no counterpart in t, with its line number.
+func checkKnownFields(node *yaml.Node, t reflect.Type) error {
+	var unknown []error
+	walkFields(node, t, "", &unknown)
+	return errors.Join(unknown...)
+}
+
+func walkFields(node *yaml.Node, t reflect.Type, path string, unknown *[]error) {
+	for node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Types that decode themselves, such as time.Time, are opaque.
+	if p := reflect.PointerTo(t); p.Implements(yamlUnmarshalerType) || p.Implements(textUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				continue
+			}
+			ft, ok := fields[key.Value]
+			if !ok {
+				*unknown = append(*unknown, fmt.Errorf("line %d: unknown field %q", key.Line, joinPath(path, key.Value)))
+				continue
+			}
+			walkFields(value, ft, joinPath(path, key.Value), unknown)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkFields(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value), unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			walkFields(item, t.Elem(), path+"["+strconv.Itoa(i)+"]", unknown)
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of struct type t to their field types, the
+// way yaml.v3 names them: by tag, else the lowercased field name, with
+// inline structs flattened.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if strings.Contains(","+opts+",", ",inline,") && ft.Kind() == reflect.Struct {
+			for k, v := range yamlFields(ft) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,49 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStrict(t *testing.T) {
+	input := []byte(`kind: AgentPool
+metadata:
+  name: reviewers
+  createdAt: 2026-01-02T15:04:05Z
+  labels:
+    anything: goes
+spec:
+  replicas: 2
+  maxConcurency: 3
+  template:
+    spec:
+      modle: claude-sonnet-4-20250514
+`)
+
+	if _, err := ParseBytes(input); err != nil {
+		t.Fatalf("expected lenient parsing to ignore unknown fields, got %v", err)
+	}
+
+	_, err := ParseBytes(input, Strict())
+	if err == nil {
+		t.Fatal("expected an error for unknown fields")
+	}
+	for _, want := range []string{
+		`line 9: unknown field "spec.maxConcurency"`,
+		`line 12: unknown field "spec.template.spec.modle"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "anything") || strings.Contains(err.Error(), "createdAt") {
+		t.Errorf("labels and timestamps are not unknown fields: %v", err)
+	}
+}
+
+func TestParseStrictJSON(t *testing.T) {
+	input := []byte(`{"kind": "Project", "metadata": {"name": "web"}, "spec": {"descripton": "typo"}}`)
+	if _, err := ParseBytes(input, Strict()); err == nil || !strings.Contains(err.Error(), "descripton") {
+		t.Errorf("expected an unknown field error, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// httpClient downloads the manifests of ParseURL.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxManifestSize is the most ParseURL downloads of a manifest.
+const maxManifestSize = 8 << 20
+
+// IsURL reports whether path names a manifest ParseURL reads rather than a
+// local file.
+func IsURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// ParseURL downloads the manifest at rawURL, an http or https URL such as a
+// registry or Git raw URL, and parses it as ParseBytes does.
+//
+// A fragment of the form #sha256:<hex digest> pins the manifest: the
+// download must have that SHA-256 checksum, so that a shared definition
+// cannot change under those who apply it. The fragment is not sent to the
+// server.
+//
+//	https://example.com/agents/reviewers-v2.yaml#sha256:9f86d0...
+func ParseURL(rawURL string, opts ...Option) ([]interface{}, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid manifest URL %s: scheme must be http or https", rawURL)
+	}
+
+	var pin string
+	if u.Fragment != "" {
+		if pin, err = parseChecksum(u.Fragment); err != nil {
+			return nil, fmt.Errorf("invalid manifest URL %s: %w", rawURL, err)
+		}
+		u.Fragment = ""
+	}
+
+	data, err := fetch(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest %s: %w", u, err)
+	}
+	if pin != "" {
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); got != pin {
+			return nil, fmt.Errorf("manifest %s: checksum mismatch: got sha256:%s, want sha256:%s", u, got, pin)
+		}
+	}
+	resources, err := ParseBytes(data, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", u, err)
+	}
+	return resources, nil
+}
+
+// parseChecksum returns the lowercase hex digest of a sha256:<hex> pin.
+func parseChecksum(s string) (string, error) {
+	digest, ok := strings.CutPrefix(s, "sha256:")
+	if !ok {
+		return "", fmt.Errorf("unsupported checksum %q, want sha256:<hex digest>", s)
+	}
+	if b, err := hex.DecodeString(digest); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("invalid sha256 digest %q: want %d hex digits", digest, 2*sha256.Size)
+	}
+	return strings.ToLower(digest), nil
+}
+
+// fetch downloads url, which must answer 200 OK with at most
+// maxManifestSize bytes.
+func fetch(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("manifest is larger than %d MiB", maxManifestSize>>20)
+	}
+	return data, nil
+}
@@ -0,0 +1,68 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestParseURL(t *testing.T) {
+	const pool = `
+kind: AgentPool
+metadata:
+  name: reviewers
+  project: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      model: claude-sonnet
+`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pool.yaml":
+			w.Write([]byte(pool))
+		case "/huge.yaml":
+			w.Write([]byte(strings.Repeat("#", maxManifestSize+1)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	sum := sha256.Sum256([]byte(pool))
+	digest := hex.EncodeToString(sum[:])
+
+	for _, u := range []string{
+		srv.URL + "/pool.yaml",
+		srv.URL + "/pool.yaml#sha256:" + digest,
+		srv.URL + "/pool.yaml#sha256:" + strings.ToUpper(digest),
+	} {
+		resources, err := ParseURL(u)
+		if err != nil {
+			t.Fatalf("%s: %v", u, err)
+		}
+		if p, ok := resources[0].(*v1alpha1.AgentPool); !ok || p.Spec.Replicas != 2 {
+			t.Errorf("%s: got %#v", u, resources[0])
+		}
+	}
+
+	wrong := strings.Repeat("0", 64)
+	for u, want := range map[string]string{
+		srv.URL + "/pool.yaml#sha256:" + wrong: "checksum mismatch",
+		srv.URL + "/pool.yaml#sha256:abc":      "invalid sha256 digest",
+		srv.URL + "/pool.yaml#md5:" + digest:   "unsupported checksum",
+		srv.URL + "/missing.yaml":              "404",
+		srv.URL + "/huge.yaml":                 "larger than 8 MiB",
+		"ftp://example.com/pool.yaml":          "scheme must be http or https",
+	} {
+		if _, err := ParseURL(u); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected error containing %q, got %v", u, want, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/klubi/orca/pkg/validation"
+)
+
+// ValidationError reports every schema violation of one manifest document.
+type ValidationError struct {
+	// Document is the 1-based position of the document in its input: its
+	// index among the ----separated YAML documents or the JSON objects. It
+	// is 0 for resources that were not parsed from a document, such as the
+	// result of an overlay patch.
+	Document int
+	Kind     string
+	Name     string
+	Fields   []FieldError
+}
+
+// FieldError is a single schema violation.
+type FieldError struct {
+	// Field is the dotted path of the field, e.g. spec.template.spec.maxTurns.
+	Field string
+	// Line is where the field, or the closest enclosing field present, is
+	// in a YAML document; 0 when unknown, as for JSON.
+	Line    int
+	Message string
+}
+
+func (e FieldError) String() string {
+	s := e.Field + ": " + e.Message
+	if e.Line > 0 {
+		s = fmt.Sprintf("line %d: %s", e.Line, s)
+	}
+	return s
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("validation failed: ")
+	if e.Document > 0 {
+		fmt.Fprintf(&b, "document %d ", e.Document)
+	}
+	b.WriteString("(" + e.Kind)
+	if e.Name != "" {
+		b.WriteString(" " + e.Name)
+	}
+	b.WriteString(")")
+
+	if len(e.Fields) == 1 {
+		b.WriteString(": " + e.Fields[0].String())
+		return b.String()
+	}
+	b.WriteString(":")
+	for _, f := range e.Fields {
+		b.WriteString("\n  " + f.String())
+	}
+	return b.String()
+}
+
+// locate sets the document index of e and the line of each field from
+// node, the document the resource was decoded from, which may be nil.
+func (e *ValidationError) locate(document int, node *yaml.Node) {
+	e.Document = document
+	if node == nil {
+		return
+	}
+	for i := range e.Fields {
+		e.Fields[i].Line = fieldLine(node, e.Fields[i].Field)
+	}
+}
+
+// fieldLine returns the line of the field at path in node, or of the
+// deepest enclosing field present when it is missing.
+func fieldLine(node *yaml.Node, path string) int {
+	for node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+	line := node.Line
+	for _, seg := range splitFieldPath(path) {
+		var next *yaml.Node
+		switch {
+		case node.Kind == yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == seg {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case node.Kind == yaml.SequenceNode:
+			if i, err := strconv.Atoi(seg); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			return line
+		}
+		node = next
+	}
+	return line
+}
+
+// splitFieldPath splits "spec.env[1].name" into spec, env, 1 and name.
+func splitFieldPath(path string) []string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	return strings.Split(path, ".")
+}
+
+// validateResource checks a parsed resource against the schema of its kind
+// with the validation package and returns a *ValidationError listing every
+// violation.
+func validateResource(resource interface{}) error {
+	var verr *validation.Error
+	if err := validation.Validate(resource); !errors.As(err, &verr) {
+		return err
+	}
+	fields := make([]FieldError, len(verr.Fields))
+	for i, f := range verr.Fields {
+		fields[i] = FieldError{Field: f.Field, Message: f.Message}
+	}
+	return &ValidationError{Kind: verr.Kind, Name: verr.Name, Fields: fields}
+}
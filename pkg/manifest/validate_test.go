@@ -0,0 +1,73 @@
+package manifest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidationErrorPositions(t *testing.T) {
+	input := []byte(`kind: Project
+metadata:
+  name: web
+---
+kind: AgentPool
+metadata:
+  name: reviewers
+spec:
+  replicas: -1
+  template:
+    spec:
+      restartPolicy: Sometimes
+      env:
+        - name: TOKEN
+          value: abc
+          valueFrom:
+            secretKeyRef: {name: creds, key: token}
+`)
+	_, err := ParseBytes(input)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	if verr.Document != 2 || verr.Kind != "AgentPool" || verr.Name != "reviewers" {
+		t.Errorf("expected document 2, AgentPool reviewers; got %d, %s %s", verr.Document, verr.Kind, verr.Name)
+	}
+
+	want := map[string]int{
+		"spec.replicas":                    9,
+		"spec.template.spec.restartPolicy": 12,
+		"spec.template.spec.env[0]":        14,
+	}
+	if len(verr.Fields) != len(want) {
+		t.Fatalf("expected %d field errors, got %v", len(want), verr.Fields)
+	}
+	for _, f := range verr.Fields {
+		if line, ok := want[f.Field]; !ok || f.Line != line {
+			t.Errorf("unexpected field error %s (want line %d)", f, want[f.Field])
+		}
+	}
+	if !strings.Contains(err.Error(), `line 12: spec.template.spec.restartPolicy: unknown value "Sometimes"`) {
+		t.Errorf("unexpected message:\n%v", err)
+	}
+}
+
+func TestValidationMissingField(t *testing.T) {
+	input := []byte(`kind: AgentPoolAutoscaler
+metadata:
+  name: reviewers
+spec:
+  maxReplicas: 3
+`)
+	_, err := ParseBytes(input)
+	want := "validation failed: document 1 (AgentPoolAutoscaler reviewers): line 4: spec.pool: must not be empty"
+	if err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+
+	_, err = ParseBytes([]byte(`[{"kind": "Project", "metadata": {"name": "a"}}, {"kind": "Project", "metadata": {"name": "b/c"}}]`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Document != 2 || verr.Fields[0].Line != 0 {
+		t.Errorf("expected a JSON error for document 2 without a line, got %v", err)
+	}
+}
@@ -0,0 +1,420 @@
+// Package validation checks resources against the schema of their kind.
+// The manifest parser and the API server both use it, so that a resource is
+// rejected up front whether it comes from a file or over the API, rather
+// than failing later in a controller.
+package validation
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+// Error reports every schema violation of one resource.
+type Error struct {
+	Kind   string
+	Name   string
+	Fields []FieldError
+}
+
+// FieldError is a single schema violation.
+type FieldError struct {
+	// Field is the dotted path of the field, e.g. spec.template.spec.maxTurns.
+	Field   string
+	Message string
+}
+
+func (e FieldError) String() string {
+	return e.Field + ": " + e.Message
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("validation failed (" + e.Kind)
+	if e.Name != "" {
+		b.WriteString(" " + e.Name)
+	}
+	b.WriteString(")")
+
+	if len(e.Fields) == 1 {
+		b.WriteString(": " + e.Fields[0].String())
+		return b.String()
+	}
+	b.WriteString(":")
+	for _, f := range e.Fields {
+		b.WriteString("\n  " + f.String())
+	}
+	return b.String()
+}
+
+// validator collects the field errors of one resource.
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) errorf(field, format string, args ...interface{}) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(field, value string) {
+	if value == "" {
+		v.errorf(field, "must not be empty")
+	}
+}
+
+func (v *validator) nonNegative(field string, value int) {
+	if value < 0 {
+		v.errorf(field, "must be >= 0, got %d", value)
+	}
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.errorf(field, "unknown value %q, want one of %s", value, strings.Join(allowed, ", "))
+}
+
+// name checks the name of a resource, which becomes part of store keys,
+// file paths and labels.
+func (v *validator) name(field, value string) {
+	if value != "" && !IsDNSName(value) {
+		v.errorf(field, "%q must consist of lowercase alphanumerics, '-' or '.', start and end with an alphanumeric and be at most %d characters", value, maxNameLength)
+	}
+}
+
+// maxNameLength is the longest name IsDNSName accepts.
+const maxNameLength = 253
+
+// IsDNSName reports whether name is a DNS subdomain (RFC 1123), as resource
+// names must be: lowercase alphanumerics, '-' and '.', starting and ending
+// with an alphanumeric.
+func IsDNSName(name string) bool {
+	if name == "" || len(name) > maxNameLength {
+		return false
+	}
+	for i, c := range name {
+		alnum := c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+		switch {
+		case alnum:
+		case (c == '-' || c == '.') && i > 0 && i < len(name)-1:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// claudeModelAliases are the model names the claude CLI takes besides the
+// full claude-* ones.
+var claudeModelAliases = []string{"sonnet", "opus", "haiku"}
+
+// IsClaudeModel reports whether model is a model name the claude CLI knows:
+// a claude-* name, such as claude-sonnet-4-20250514 or the claude-sonnet
+// shortname, or one of its sonnet, opus and haiku aliases.
+func IsClaudeModel(model string) bool {
+	if strings.HasPrefix(model, "claude-") && len(model) > len("claude-") {
+		return true
+	}
+	for _, a := range claudeModelAliases {
+		if model == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) meta(typeMeta v1alpha1.TypeMeta, meta v1alpha1.ObjectMeta) {
+	v.oneOf("apiVersion", typeMeta.APIVersion, v1alpha1.APIVersion)
+	v.required("metadata.name", meta.Name)
+	v.name("metadata.name", meta.Name)
+	v.name("metadata.project", meta.Project)
+	for k := range meta.Labels {
+		if k == "" {
+			v.errorf("metadata.labels", "label keys must not be empty")
+		}
+	}
+	for k := range meta.Annotations {
+		if k == "" {
+			v.errorf("metadata.annotations", "annotation keys must not be empty")
+		}
+	}
+}
+
+// Validate checks obj, a typed resource, against the schema of its kind and
+// returns an *Error listing every violation. Resources of other types are
+// not checked.
+func Validate(obj interface{}) error {
+	var (
+		v          validator
+		kind, name string
+	)
+	if o, ok := obj.(v1alpha1.Object); ok {
+		name = o.GetObjectMeta().Name
+	}
+
+	switch r := obj.(type) {
+	case *v1alpha1.Project:
+		kind = v1alpha1.KindProject
+		v.meta(r.TypeMeta, r.Metadata)
+		v.nonNegative("spec.defaults.maxTokens", r.Spec.Defaults.MaxTokens)
+		v.nonNegative("spec.policy.tokenBudget", r.Spec.Policy.TokenBudget)
+		if r.Spec.Policy.CostBudgetUSD < 0 {
+			v.errorf("spec.policy.costBudgetUSD", "must be >= 0, got %g", r.Spec.Policy.CostBudgetUSD)
+		}
+		if m := r.Spec.Defaults.Model; m != "" && !r.Spec.Policy.AllowsModel(m) {
+			v.errorf("spec.defaults.model", "%q is not one of the allowed models", m)
+		}
+	case *v1alpha1.AgentPod:
+		kind = v1alpha1.KindAgentPod
+		v.meta(r.TypeMeta, r.Metadata)
+		v.podSpec("spec", &r.Spec)
+		v.oneOf("status.phase", string(r.Status.Phase),
+			string(v1alpha1.PodPending), string(v1alpha1.PodStarting), string(v1alpha1.PodReady),
+			string(v1alpha1.PodBusy), string(v1alpha1.PodFailed), string(v1alpha1.PodTerminating),
+			string(v1alpha1.PodTerminated))
+	case *v1alpha1.AgentPool:
+		kind = v1alpha1.KindAgentPool
+		v.meta(r.TypeMeta, r.Metadata)
+		v.nonNegative("spec.replicas", r.Spec.Replicas)
+		v.podSpec("spec.template.spec", &r.Spec.Template.Spec)
+	case *v1alpha1.DevTask:
+		kind = v1alpha1.KindDevTask
+		v.meta(r.TypeMeta, r.Metadata)
+		if r.Spec.Prompt == "" && r.Spec.PromptTemplate == "" {
+			v.errorf("spec.prompt", "must not be empty unless spec.promptTemplate is set")
+		}
+		v.nonNegative("spec.maxRetries", r.Spec.MaxRetries)
+		v.nonNegative("spec.timeoutSeconds", r.Spec.TimeoutSeconds)
+		if r.Spec.PromptTemplate != "" {
+			if _, err := template.New(r.Metadata.Name).Parse(r.Spec.PromptTemplate); err != nil {
+				v.errorf("spec.promptTemplate", "invalid template: %v", err)
+			}
+		}
+		for i, dep := range r.Spec.DependsOn {
+			if dep == r.Metadata.Name {
+				v.errorf(fmt.Sprintf("spec.dependsOn[%d]", i), "a task cannot depend on itself")
+			}
+		}
+		v.oneOf("status.phase", string(r.Status.Phase),
+			string(v1alpha1.TaskPending), string(v1alpha1.TaskScheduled), string(v1alpha1.TaskRunning),
+			string(v1alpha1.TaskSucceeded), string(v1alpha1.TaskFailed))
+	case *v1alpha1.Secret:
+		kind = v1alpha1.KindSecret
+		v.meta(r.TypeMeta, r.Metadata)
+		for k, val := range r.Data {
+			if _, err := base64.StdEncoding.DecodeString(val); err != nil {
+				v.errorf("data."+k, "must be base64 encoded; use stringData for plain text")
+			}
+		}
+		v.dataKeys("data", r.Data)
+		v.dataKeys("stringData", r.StringData)
+	case *v1alpha1.ConfigMap:
+		kind = v1alpha1.KindConfigMap
+		v.meta(r.TypeMeta, r.Metadata)
+		v.dataKeys("data", r.Data)
+	case *v1alpha1.ModelProvider:
+		kind = v1alpha1.KindModelProvider
+		v.meta(r.TypeMeta, r.Metadata)
+		v.providerSpec("spec", &r.Spec)
+	case *v1alpha1.ToolDefinition:
+		kind = v1alpha1.KindToolDefinition
+		v.meta(r.TypeMeta, r.Metadata)
+		v.toolSpec("spec", &r.Spec)
+	case *v1alpha1.AgentPoolAutoscaler:
+		kind = v1alpha1.KindAgentPoolAutoscaler
+		v.meta(r.TypeMeta, r.Metadata)
+		s := r.Spec
+		v.required("spec.pool", s.Pool)
+		v.nonNegative("spec.minReplicas", s.MinReplicas)
+		if s.MaxReplicas < 1 {
+			v.errorf("spec.maxReplicas", "must be >= 1, got %d", s.MaxReplicas)
+		}
+		if s.MinReplicas > s.MaxReplicas && s.MaxReplicas >= 1 {
+			v.errorf("spec.minReplicas", "must not exceed maxReplicas (%d), got %d", s.MaxReplicas, s.MinReplicas)
+		}
+		v.nonNegative("spec.scaleDownDelaySeconds", s.ScaleDownDelaySeconds)
+	}
+
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &Error{Kind: kind, Name: name, Fields: v.fields}
+}
+
+// restartPolicies are the values of AgentPodSpec.RestartPolicy.
+var restartPolicies = []string{"Always", "OnFailure", "Never"}
+
+// permissionModes are the permission modes the Claude CLI knows.
+var permissionModes = []string{
+	string(v1alpha1.PermissionDefault), string(v1alpha1.PermissionAcceptEdits),
+	string(v1alpha1.PermissionPlan), string(v1alpha1.PermissionBypass),
+}
+
+// podSpec checks an AgentPodSpec found at path.
+func (v *validator) podSpec(path string, s *v1alpha1.AgentPodSpec) {
+	v.oneOf(path+".restartPolicy", s.RestartPolicy, restartPolicies...)
+	v.oneOf(path+".permissionMode", string(s.PermissionMode), permissionModes...)
+	v.nonNegative(path+".maxConcurrency", s.MaxConcurrency)
+	v.nonNegative(path+".maxTokens", s.MaxTokens)
+	v.nonNegative(path+".maxTurns", s.MaxTurns)
+	v.nonNegative(path+".warmSessions", s.WarmSessions)
+	v.nonNegative(path+".maxRequestsPerMinute", s.MaxRequestsPerMinute)
+	v.name(path+".provider", s.Provider)
+	// Other providers serve models of any name.
+	if s.Provider == "" && s.Model != "" && !IsClaudeModel(s.Model) {
+		v.errorf(path+".model", "unknown model %q; the claude CLI takes claude-* names and the %s aliases (set provider to use other models)",
+			s.Model, strings.Join(claudeModelAliases, ", "))
+	}
+
+	for i, env := range s.Env {
+		field := fmt.Sprintf("%s.env[%d]", path, i)
+		v.required(field+".name", env.Name)
+		switch {
+		case env.Value != "" && env.ValueFrom != nil:
+			v.errorf(field, "value and valueFrom are mutually exclusive")
+		case env.ValueFrom == nil:
+		case (env.ValueFrom.SecretKeyRef == nil) == (env.ValueFrom.ConfigMapKeyRef == nil):
+			v.errorf(field+".valueFrom", "exactly one of secretKeyRef and configMapKeyRef must be set")
+		case env.ValueFrom.SecretKeyRef != nil:
+			v.required(field+".valueFrom.secretKeyRef.name", env.ValueFrom.SecretKeyRef.Name)
+			v.required(field+".valueFrom.secretKeyRef.key", env.ValueFrom.SecretKeyRef.Key)
+		default:
+			v.required(field+".valueFrom.configMapKeyRef.name", env.ValueFrom.ConfigMapKeyRef.Name)
+			v.required(field+".valueFrom.configMapKeyRef.key", env.ValueFrom.ConfigMapKeyRef.Key)
+		}
+	}
+	for i, from := range s.EnvFrom {
+		field := fmt.Sprintf("%s.envFrom[%d]", path, i)
+		switch {
+		case (from.SecretRef == nil) == (from.ConfigMapRef == nil):
+			v.errorf(field, "exactly one of secretRef and configMapRef must be set")
+		case from.SecretRef != nil:
+			v.required(field+".secretRef.name", from.SecretRef.Name)
+		default:
+			v.required(field+".configMapRef.name", from.ConfigMapRef.Name)
+		}
+	}
+	volumes := make(map[string]bool, len(s.Volumes))
+	for i, vol := range s.Volumes {
+		field := fmt.Sprintf("%s.volumes[%d]", path, i)
+		switch {
+		case vol.Name == "":
+			v.errorf(field+".name", "must not be empty")
+		case !validKey(vol.Name):
+			v.errorf(field+".name", "must consist of alphanumerics, '-', '_' or '.', got %q", vol.Name)
+		case volumes[vol.Name]:
+			v.errorf(field+".name", "duplicate volume %q", vol.Name)
+		}
+		volumes[vol.Name] = true
+		switch {
+		case (vol.Secret == nil) == (vol.ConfigMap == nil):
+			v.errorf(field, "exactly one of secret and configMap must be set")
+		case vol.Secret != nil:
+			v.required(field+".secret.name", vol.Secret.Name)
+		default:
+			v.required(field+".configMap.name", vol.ConfigMap.Name)
+		}
+	}
+}
+
+// providerTypes are the values of ModelProviderSpec.Type.
+var providerTypes = []string{
+	string(v1alpha1.ProviderClaudeCLI), string(v1alpha1.ProviderAnthropicAPI),
+	string(v1alpha1.ProviderOpenAI), string(v1alpha1.ProviderOllama),
+}
+
+// providerSpec checks a ModelProviderSpec found at path.
+func (v *validator) providerSpec(path string, s *v1alpha1.ModelProviderSpec) {
+	v.required(path+".type", string(s.Type))
+	v.oneOf(path+".type", string(s.Type), providerTypes...)
+	if s.Endpoint != "" {
+		if u, err := url.Parse(s.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			v.errorf(path+".endpoint", "must be an absolute URL, got %q", s.Endpoint)
+		}
+	}
+	if ref := s.CredentialsSecret; ref != nil {
+		v.required(path+".credentialsSecret.name", ref.Name)
+		v.required(path+".credentialsSecret.key", ref.Key)
+	}
+	models := make(map[string]bool, len(s.Costs))
+	for i, c := range s.Costs {
+		field := fmt.Sprintf("%s.costs[%d]", path, i)
+		v.required(field+".model", c.Model)
+		if models[c.Model] && c.Model != "" {
+			v.errorf(field+".model", "duplicate model %q", c.Model)
+		}
+		models[c.Model] = true
+		if c.InputPerMTok < 0 || c.OutputPerMTok < 0 {
+			v.errorf(field, "prices must be >= 0")
+		}
+	}
+	v.nonNegative(path+".maxRequestsPerMinute", s.MaxRequestsPerMinute)
+}
+
+// toolSpec checks a ToolDefinitionSpec found at path.
+func (v *validator) toolSpec(path string, s *v1alpha1.ToolDefinitionSpec) {
+	bindings := 0
+	for _, set := range []bool{len(s.CLITools) > 0, s.Command != "", s.MCP != nil} {
+		if set {
+			bindings++
+		}
+	}
+	if bindings != 1 {
+		v.errorf(path, "exactly one of cliTools, command and mcp must be set")
+	}
+	for i, t := range s.CLITools {
+		v.required(fmt.Sprintf("%s.cliTools[%d]", path, i), t)
+	}
+	if m := s.MCP; m != nil {
+		switch {
+		case (m.Command == "") == (m.URL == ""):
+			v.errorf(path+".mcp", "exactly one of command and url must be set")
+		case m.URL != "":
+			if u, err := url.Parse(m.URL); err != nil || u.Scheme == "" || u.Host == "" {
+				v.errorf(path+".mcp.url", "must be an absolute URL, got %q", m.URL)
+			}
+		}
+	}
+	if s.Sandbox != nil {
+		for i, p := range s.Sandbox.Paths {
+			if !filepath.IsAbs(p) {
+				v.errorf(fmt.Sprintf("%s.sandbox.paths[%d]", path, i), "must be an absolute path, got %q", p)
+			}
+		}
+	}
+}
+
+// dataKeys checks the keys of a Secret's or ConfigMap's data, which become
+// environment variable and file names.
+func (v *validator) dataKeys(path string, data map[string]string) {
+	for k := range data {
+		if !validKey(k) {
+			v.errorf(path+"."+k, "key must consist of alphanumerics, '-', '_' or '.'")
+		}
+	}
+}
+
+// validKey reports whether k is a valid data key or volume name: it must be
+// a usable file name.
+func validKey(k string) bool {
+	if k == "" || k == "." || k == ".." {
+		return false
+	}
+	for _, c := range k {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
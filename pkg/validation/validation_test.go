@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/klubi/orca/pkg/apis/v1alpha1"
+)
+
+func TestIsDNSName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"web":                    true,
+		"run-1a2b":               true,
+		"api.v2":                 true,
+		"a":                      true,
+		"":                       false,
+		"Web":                    false,
+		"-web":                   false,
+		"web-":                   false,
+		"web_app":                false,
+		"a/b":                    false,
+		"has space":              false,
+		strings.Repeat("a", 254): false,
+	} {
+		if got := IsDNSName(name); got != want {
+			t.Errorf("IsDNSName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestIsClaudeModel(t *testing.T) {
+	for model, want := range map[string]bool{
+		"claude-sonnet-4-20250514": true,
+		"claude-haiku":             true,
+		"opus":                     true,
+		"claude-":                  false,
+		"gpt-4o":                   false,
+	} {
+		if got := IsClaudeModel(model); got != want {
+			t.Errorf("IsClaudeModel(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	pod := func(name, model, provider string) *v1alpha1.AgentPod {
+		return &v1alpha1.AgentPod{
+			Metadata: v1alpha1.ObjectMeta{Name: name, Project: "web"},
+			Spec:     v1alpha1.AgentPodSpec{Model: model, Provider: provider},
+		}
+	}
+	tests := []struct {
+		name   string
+		obj    interface{}
+		fields []string
+	}{
+		{"valid pod", pod("worker", "claude-sonnet", ""), nil},
+		{"other provider's model", pod("worker", "llama3", "ollama"), nil},
+		{"unknown claude model", pod("worker", "llama3", ""), []string{"spec.model"}},
+		{"unsafe name", pod("Worker_1", "sonnet", ""), []string{"metadata.name"}},
+		{"task without prompt", &v1alpha1.DevTask{Metadata: v1alpha1.ObjectMeta{Name: "t"}}, []string{"spec.prompt"}},
+		{"task with template", &v1alpha1.DevTask{
+			Metadata: v1alpha1.ObjectMeta{Name: "t"},
+			Spec:     v1alpha1.DevTaskSpec{PromptTemplate: "Review {{.file}}"},
+		}, nil},
+		{"negative replicas", &v1alpha1.AgentPool{
+			Metadata: v1alpha1.ObjectMeta{Name: "p"},
+			Spec:     v1alpha1.AgentPoolSpec{Replicas: -1, Template: v1alpha1.AgentPodTemplate{Spec: v1alpha1.AgentPodSpec{RestartPolicy: "Sometimes"}}},
+		}, []string{"spec.replicas", "spec.template.spec.restartPolicy"}},
+		{"other type", &v1alpha1.AgentNode{Metadata: v1alpha1.ObjectMeta{Name: "Node_1"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.obj)
+			if tt.fields == nil {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var verr *Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() = %v, want an *Error", err)
+			}
+			if len(verr.Fields) != len(tt.fields) {
+				t.Fatalf("field errors = %v, want %v", verr.Fields, tt.fields)
+			}
+			for i, f := range verr.Fields {
+				if f.Field != tt.fields[i] {
+					t.Errorf("field error %d is for %s, want %s", i, f.Field, tt.fields[i])
+				}
+			}
+		})
+	}
+}